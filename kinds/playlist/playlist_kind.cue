@@ -44,4 +44,89 @@ lineage: schemas: [{
 			title?: string
 		} @cuetsy(kind="interface")
 	}
+}, {
+	version: [0, 1]
+	schema: {
+		spec: {
+			// Unique playlist identifier. Generated on creation, either by the
+			// creator of the playlist of by the application.
+			uid: string
+
+			// Name of the playlist.
+			name: string
+
+			// Interval sets the time between switching views in a playlist.
+			// FIXME: Is this based on a standardized format or what options are available? Can datemath be used?
+			interval: string | *"5m"
+
+			// The ordered list of items that the playlist will iterate over.
+			// FIXME! This should not be optional, but changing it makes the godegen awkward
+			items?: [...#PlaylistItem]
+		} @cuetsy(kind="interface")
+
+		///////////////////////////////////////
+		// Definitions (referenced above) are declared below
+
+		#PlaylistItem: {
+			// Type of the item.
+			type: "dashboard_by_uid" | "dashboard_by_id" | "dashboard_by_tag" | "dashboard_by_search"
+			// Value depends on type and describes the playlist item.
+			//
+			//  - dashboard_by_id: The value is an internal numerical identifier set by Grafana. This
+			//  is not portable as the numerical identifier is non-deterministic between different instances.
+			//  Will be replaced by dashboard_by_uid in the future. (deprecated)
+			//  - dashboard_by_tag: The value is a tag which is set on any number of dashboards. All
+			//  dashboards behind the tag will be added to the playlist.
+			//  - dashboard_by_uid: The value is the dashboard UID
+			//  - dashboard_by_search: The value is a search query with the folderUIDs, tag, starred
+			//  and query parameters of the search API, e.g. folderUIDs=abc&tag=prod&starred=true.
+			//  The dashboards matching the search are resolved when the playlist is played.
+			value: string
+
+			// Title is an unused property -- it will be removed in the future
+			title?: string
+
+			// Interval overrides the interval of the playlist for the dashboards of the item.
+			interval?: string
+
+			// TimeFrom overrides the start of the time range of the dashboards, e.g. now-6h.
+			timeFrom?: string
+
+			// TimeTo overrides the end of the time range of the dashboards, e.g. now.
+			timeTo?: string
+
+			// Variables overrides the values of the template variables of the dashboards.
+			variables?: [string]: string
+		} @cuetsy(kind="interface")
+	}
+}]
+
+lineage: lenses: [{
+	to: [0, 0]
+	from: [0, 1]
+	input: _
+	result: {
+		if input.metadata != _|_ {
+			metadata: input.metadata
+		}
+		if input.status != _|_ {
+			status: input.status
+		}
+		spec: {
+			uid:      input.spec.uid
+			name:     input.spec.name
+			interval: input.spec.interval
+			if input.spec.items != _|_ {
+				// dashboard_by_search items cannot be played by the previous version, and the
+				// overrides of the items are dropped
+				items: [ for item in input.spec.items if item.type != "dashboard_by_search" {
+					type:  item.type
+					value: item.value
+					if item.title != _|_ {
+						title: item.title
+					}
+				}]
+			}
+		}
+	}
 }]
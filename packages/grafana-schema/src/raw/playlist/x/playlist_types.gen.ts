@@ -9,6 +9,18 @@
 // Run 'make gen-cue' from repository root to regenerate.
 
 export interface PlaylistItem {
+  /**
+   * Interval overrides the interval of the playlist for the dashboards of the item.
+   */
+  interval?: string;
+  /**
+   * TimeFrom overrides the start of the time range of the dashboards, e.g. now-6h.
+   */
+  timeFrom?: string;
+  /**
+   * TimeTo overrides the end of the time range of the dashboards, e.g. now.
+   */
+  timeTo?: string;
   /**
    * Title is an unused property -- it will be removed in the future
    */
@@ -16,7 +28,7 @@ export interface PlaylistItem {
   /**
    * Type of the item.
    */
-  type: ('dashboard_by_uid' | 'dashboard_by_id' | 'dashboard_by_tag');
+  type: ('dashboard_by_uid' | 'dashboard_by_id' | 'dashboard_by_tag' | 'dashboard_by_search');
   /**
    * Value depends on type and describes the playlist item.
    * 
@@ -26,8 +38,15 @@ export interface PlaylistItem {
    *  - dashboard_by_tag: The value is a tag which is set on any number of dashboards. All
    *  dashboards behind the tag will be added to the playlist.
    *  - dashboard_by_uid: The value is the dashboard UID
+   *  - dashboard_by_search: The value is a search query with the folderUIDs, tag, starred
+   *  and query parameters of the search API, e.g. folderUIDs=abc&tag=prod&starred=true.
+   *  The dashboards matching the search are resolved when the playlist is played.
    */
   value: string;
+  /**
+   * Variables overrides the values of the template variables of the dashboards.
+   */
+  variables?: Record<string, string>;
 }
 
 export interface Playlist {
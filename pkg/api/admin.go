@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
@@ -67,6 +68,29 @@ func (hs *HTTPServer) AdminGetStats(c *contextmodel.ReqContext) response.Respons
 	return response.JSON(http.StatusOK, adminStats)
 }
 
+// swagger:route GET /admin/locks admin adminGetLocks
+//
+// Fetch the locks held by the servers.
+//
+// Lists the leases currently held by the Grafana servers sharing the database, with their holder and fencing token.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminGetLocksResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminGetLocks(c *contextmodel.ReqContext) response.Response {
+	leases, err := hs.serverLockService.ListLeases(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get locks from database", err)
+	}
+
+	return response.JSON(http.StatusOK, leases)
+}
+
 func (hs *HTTPServer) getAuthorizedSettings(ctx context.Context, user identity.Requester, bag setting.SettingsBag) (setting.SettingsBag, error) {
 	eval := func(scope string) (bool, error) {
 		return hs.AccessControl.Evaluate(ctx, user, ac.EvalPermission(ac.ActionSettingsRead, scope))
@@ -164,3 +188,9 @@ type GetStatsResponse struct {
 	// in:body
 	Body stats.AdminStats `json:"body"`
 }
+
+// swagger:response adminGetLocksResponse
+type GetLocksResponse struct {
+	// in:body
+	Body []serverlock.LeaseInfo `json:"body"`
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
 )
 
 // swagger:route POST /admin/provisioning/dashboards/reload admin_provisioning adminProvisioningReloadDashboards
@@ -54,6 +55,29 @@ func (hs *HTTPServer) AdminProvisioningReloadDatasources(c *contextmodel.ReqCont
 	return response.Success("Datasources config reloaded")
 }
 
+// swagger:route GET /admin/provisioning/datasources/plan admin_provisioning adminProvisioningPlanDatasources
+//
+// Preview datasource provisioning changes.
+//
+// Compares the datasource provisioning config files with the datasources stored in the database and returns the additions, updates and deletions a reload would apply. Nothing is changed.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `provisioning:reload` and scope `provisioners:datasources`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: adminProvisioningPlanDatasourcesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminProvisioningPlanDatasources(c *contextmodel.ReqContext) response.Response {
+	plan, err := hs.ProvisioningService.PlanDatasources(c.Req.Context())
+	if err != nil {
+		return response.Error(500, "Failed to plan datasource provisioning", err)
+	}
+	return response.JSON(200, plan)
+}
+
 // swagger:route POST /admin/provisioning/plugins/reload admin_provisioning adminProvisioningReloadPlugins
 //
 // Reload plugin provisioning configurations.
@@ -107,3 +131,9 @@ func (hs *HTTPServer) AdminProvisioningReloadAlerting(c *contextmodel.ReqContext
 	}
 	return response.Success("Alerting config reloaded")
 }
+
+// swagger:response adminProvisioningPlanDatasourcesResponse
+type AdminProvisioningPlanDatasourcesResponse struct {
+	// in:body
+	Body datasources.Plan `json:"body"`
+}
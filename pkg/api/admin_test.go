@@ -1,16 +1,23 @@
 package api
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/db/dbtest"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/stats/statstest"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web/webtest"
 )
@@ -164,3 +171,33 @@ func TestAdmin_AccessControl(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_AdminGetLocks(t *testing.T) {
+	lockService := serverlock.ProvideService(db.InitTestDB(t), tracing.InitializeTracerForTest())
+	_, err := lockService.AcquireLease(context.Background(), "send dashboard reports", time.Hour)
+	require.NoError(t, err)
+
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.serverLockService = lockService
+	})
+
+	t.Run("should list the held locks", func(t *testing.T) {
+		admin := &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleAdmin, IsGrafanaAdmin: true}
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/locks"), admin))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `"name":"send dashboard reports"`)
+		assert.Contains(t, string(body), `"token":1`)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should require a server admin", func(t *testing.T) {
+		orgAdmin := &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleAdmin}
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/locks"), orgAdmin))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
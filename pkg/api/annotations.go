@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -30,6 +32,93 @@ import (
 // 401: unauthorisedError
 // 500: internalServerError
 func (hs *HTTPServer) GetAnnotations(c *contextmodel.ReqContext) response.Response {
+	query, errResp := hs.annotationsQuery(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	items, err := hs.annotationsRepo.Find(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(500, "Failed to get annotations", err)
+	}
+
+	// since there are several annotations per dashboard, we can cache dashboard uid
+	dashboardCache := make(map[int64]*string)
+	for _, item := range items {
+		if item.Email != "" {
+			item.AvatarURL = dtos.GetGravatarUrl(item.Email)
+		}
+
+		if item.DashboardID != 0 {
+			item.DashboardUID = hs.dashboardUID(c, item.DashboardID, dashboardCache)
+		}
+	}
+
+	return response.JSON(http.StatusOK, items)
+}
+
+// swagger:route GET /annotations/aggregate annotations getAggregatedAnnotations
+//
+// Aggregate Annotations.
+//
+// Groups the annotations with the same dashboard, panel, alert, state and tags by time interval, and returns the
+// number of annotations of each group with the text of the most recent one. Overlapping regions can be merged.
+// The filters are the same as for finding annotations, and the limit applies to the aggregated annotations.
+// The most recent annotations are aggregated, and the response is flagged as truncated when older ones matched.
+//
+// Responses:
+// 200: getAggregatedAnnotationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (hs *HTTPServer) GetAggregatedAnnotations(c *contextmodel.ReqContext) response.Response {
+	itemQuery, errResp := hs.annotationsQuery(c)
+	if errResp != nil {
+		return errResp
+	}
+	query := &annotations.AggregateQuery{
+		ItemQuery:    *itemQuery,
+		MergeRegions: c.QueryBool("mergeRegions"),
+	}
+
+	if interval := c.Query("interval"); interval != "" {
+		if ms, err := strconv.ParseInt(interval, 10, 64); err == nil {
+			query.Interval = ms
+		} else {
+			d, err := gtime.ParseDuration(interval)
+			if err != nil {
+				return response.Error(http.StatusBadRequest, "Invalid interval", err)
+			}
+			query.Interval = d.Milliseconds()
+		}
+	} else if query.From > 0 && query.To > query.From {
+		query.Interval = (query.To - query.From) / defaultAggregateBuckets
+	}
+	if query.Interval < 0 {
+		return response.Error(http.StatusBadRequest, "Invalid interval", nil)
+	}
+
+	result, err := hs.annotationsRepo.Aggregate(c.Req.Context(), query)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to aggregate annotations", err)
+	}
+
+	// since there are several annotations per dashboard, we can cache dashboard uid
+	dashboardCache := make(map[int64]*string)
+	for _, item := range result.Items {
+		if item.DashboardID != 0 {
+			item.DashboardUID = hs.dashboardUID(c, item.DashboardID, dashboardCache)
+		}
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// defaultAggregateBuckets is the number of time buckets of an aggregation without interval
+const defaultAggregateBuckets = 100
+
+// annotationsQuery returns the query of the annotations filters of the request.
+func (hs *HTTPServer) annotationsQuery(c *contextmodel.ReqContext) (*annotations.ItemQuery, response.Response) {
 	query := &annotations.ItemQuery{
 		From:         c.QueryInt64("from"),
 		To:           c.QueryInt64("to"),
@@ -51,39 +140,27 @@ func (hs *HTTPServer) GetAnnotations(c *contextmodel.ReqContext) response.Respon
 		dq := dashboards.GetDashboardQuery{UID: query.DashboardUID, OrgID: c.OrgID}
 		dqResult, err := hs.DashboardService.GetDashboard(c.Req.Context(), &dq)
 		if err != nil {
-			return response.Error(http.StatusBadRequest, "Invalid dashboard UID in annotation request", err)
+			return nil, response.Error(http.StatusBadRequest, "Invalid dashboard UID in annotation request", err)
 		} else {
 			query.DashboardID = dqResult.ID
 		}
 	}
 
-	items, err := hs.annotationsRepo.Find(c.Req.Context(), query)
-	if err != nil {
-		return response.Error(500, "Failed to get annotations", err)
-	}
-
-	// since there are several annotations per dashboard, we can cache dashboard uid
-	dashboardCache := make(map[int64]*string)
-	for _, item := range items {
-		if item.Email != "" {
-			item.AvatarURL = dtos.GetGravatarUrl(item.Email)
-		}
+	return query, nil
+}
 
-		if item.DashboardID != 0 {
-			if val, ok := dashboardCache[item.DashboardID]; ok {
-				item.DashboardUID = val
-			} else {
-				query := dashboards.GetDashboardQuery{ID: item.DashboardID, OrgID: c.OrgID}
-				queryResult, err := hs.DashboardService.GetDashboard(c.Req.Context(), &query)
-				if err == nil && queryResult != nil {
-					item.DashboardUID = &queryResult.UID
-					dashboardCache[item.DashboardID] = &queryResult.UID
-				}
-			}
-		}
+// dashboardUID returns the UID of a dashboard, or nil when it cannot be found.
+func (hs *HTTPServer) dashboardUID(c *contextmodel.ReqContext, dashboardID int64, cache map[int64]*string) *string {
+	if uid, ok := cache[dashboardID]; ok {
+		return uid
 	}
-
-	return response.JSON(http.StatusOK, items)
+	query := dashboards.GetDashboardQuery{ID: dashboardID, OrgID: c.OrgID}
+	queryResult, err := hs.DashboardService.GetDashboard(c.Req.Context(), &query)
+	if err != nil || queryResult == nil {
+		return nil
+	}
+	cache[dashboardID] = &queryResult.UID
+	return &queryResult.UID
 }
 
 type AnnotationError struct {
@@ -696,6 +773,19 @@ type GetAnnotationsParams struct {
 	MatchAny bool `json:"matchAny"`
 }
 
+// swagger:parameters getAggregatedAnnotations
+type GetAggregatedAnnotationsParams struct {
+	GetAnnotationsParams
+	// Size of the time buckets, in milliseconds or as a duration such as 1h. Defaults to a hundredth of the time range.
+	// in:query
+	// required:false
+	Interval string `json:"interval"`
+	// Merge the overlapping regions of a group into a single region instead of grouping them by start time.
+	// in:query
+	// required:false
+	MergeRegions bool `json:"mergeRegions"`
+}
+
 // swagger:parameters getAnnotationTags
 type GetAnnotationTagsParams struct {
 	// Tag is a string that you can use to filter tags.
@@ -757,6 +847,13 @@ type GetAnnotationsResponse struct {
 	Body []*annotations.ItemDTO `json:"body"`
 }
 
+// swagger:response getAggregatedAnnotationsResponse
+type GetAggregatedAnnotationsResponse struct {
+	// The response message
+	// in: body
+	Body *annotations.AggregateResult `json:"body"`
+}
+
 // swagger:response getAnnotationByIDResponse
 type GetAnnotationByIDResponse struct {
 	// The response message
@@ -72,6 +72,27 @@ func TestAPI_Annotations(t *testing.T) {
 			expectedCode: http.StatusForbidden,
 			permissions:  []accesscontrol.Permission{},
 		},
+		{
+			desc:         "should be able to aggregate annotations with correct permission",
+			path:         "/api/annotations/aggregate?from=0&to=3600000&interval=1m&mergeRegions=true",
+			method:       http.MethodGet,
+			expectedCode: http.StatusOK,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsRead, Scope: accesscontrol.ScopeAnnotationsAll}},
+		},
+		{
+			desc:         "should not be able to aggregate annotations without correct permission",
+			path:         "/api/annotations/aggregate",
+			method:       http.MethodGet,
+			expectedCode: http.StatusForbidden,
+			permissions:  []accesscontrol.Permission{},
+		},
+		{
+			desc:         "should not be able to aggregate annotations with invalid interval",
+			path:         "/api/annotations/aggregate?interval=often",
+			method:       http.MethodGet,
+			expectedCode: http.StatusBadRequest,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionAnnotationsRead, Scope: accesscontrol.ScopeAnnotationsAll}},
+		},
 		{
 			desc:         "should be able to update dashboard annotation with correct permission",
 			path:         "/api/annotations/2",
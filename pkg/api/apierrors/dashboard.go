@@ -8,6 +8,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/util"
@@ -30,6 +31,11 @@ func ToDashboardErrorResponse(ctx context.Context, pluginStore pluginstore.Store
 		return response.Error(http.StatusBadRequest, err.Error(), nil)
 	}
 
+	var lintErr dashboardlint.BlockedError
+	if ok := errors.As(err, &lintErr); ok {
+		return response.JSON(http.StatusUnprocessableEntity, util.DynMap{"status": "lint-failed", "message": lintErr.Error(), "problems": lintErr.Problems})
+	}
+
 	var validationErr alerting.ValidationError
 	if ok := errors.As(err, &validationErr); ok {
 		return response.Error(http.StatusUnprocessableEntity, validationErr.Error(), err)
@@ -515,14 +515,20 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	}
 
 	c.TimeRequest(metrics.MApiDashboardSave)
-	return response.JSON(http.StatusOK, util.DynMap{
+	result := util.DynMap{
 		"status":  "success",
 		"slug":    dashboard.Slug,
 		"version": dashboard.Version,
 		"id":      dashboard.ID,
 		"uid":     dashboard.UID,
 		"url":     dashboard.GetURL(),
-	})
+	}
+	if hs.dashboardLintService != nil && !dashboard.IsFolder {
+		if warnings := hs.dashboardLintService.Warnings(ctx, dashboard); len(warnings) > 0 {
+			result["lintWarnings"] = warnings
+		}
+	}
+	return response.JSON(http.StatusOK, result)
 }
 
 // swagger:route GET /dashboards/home dashboards getHomeDashboard
@@ -826,6 +832,85 @@ func (hs *HTTPServer) GetDashboardVersion(c *contextmodel.ReqContext) response.R
 	return response.JSON(http.StatusOK, dashVersionMeta)
 }
 
+// swagger:route GET /dashboards/uid/{uid}/versions/blame dashboard_versions getDashboardBlameByUID
+//
+// Get the version that last changed each panel of a dashboard.
+//
+// The panels of the current version are attributed to the version that last changed them, including their
+// position. Panels that didn't change since the oldest kept version are attributed to the oldest kept version.
+//
+// Responses:
+// 200: dashboardBlameResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardBlame(c *contextmodel.ReqContext) response.Response {
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.SignedInUser.GetOrgID(), 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian, err := guardian.NewByDashboard(c.Req.Context(), dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	query := dashver.ListDashboardVersionsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardID:  dash.ID,
+		DashboardUID: dash.UID,
+	}
+	versions, err := hs.dashboardVersionService.List(c.Req.Context(), &query)
+	if err != nil {
+		return response.Error(http.StatusNotFound, fmt.Sprintf("No versions found for dashboardId %d", dash.ID), err)
+	}
+
+	blameVersions := make([]dashdiffs.BlameVersion, 0, len(versions))
+	for _, version := range versions {
+		blameVersions = append(blameVersions, dashdiffs.BlameVersion{
+			Version:   version.Version,
+			Created:   version.Created,
+			CreatedBy: version.CreatedBy,
+			Message:   version.Message,
+			Data:      version.Data,
+		})
+	}
+	blame, err := dashdiffs.Blame(blameVersions)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Unable to compute blame", err)
+	}
+
+	loginMem := make(map[int64]string)
+	res := make([]dtos.DashboardPanelBlame, 0, len(blame))
+	for _, panel := range blame {
+		creator := anonString
+		if panel.CreatedBy > 0 {
+			login, found := loginMem[panel.CreatedBy]
+			if !found {
+				login = hs.getUserLogin(c.Req.Context(), panel.CreatedBy)
+				loginMem[panel.CreatedBy] = login
+			}
+			creator = login
+		}
+
+		res = append(res, dtos.DashboardPanelBlame{
+			ID:        panel.ID,
+			Title:     panel.Title,
+			Type:      panel.Type,
+			Version:   panel.Version,
+			Created:   panel.Created,
+			CreatedBy: creator,
+			Message:   panel.Message,
+		})
+	}
+
+	return response.JSON(http.StatusOK, res)
+}
+
 // swagger:route POST /dashboards/validate dashboards alpha validateDashboard
 //
 // Validates a dashboard JSON against the schema.
@@ -896,6 +981,9 @@ func (hs *HTTPServer) ValidateDashboard(c *contextmodel.ReqContext) response.Res
 //
 // Perform diff on two dashboards.
 //
+// The diff type is `basic` or `json` for an HTML diff, `delta` for a JSON delta, or `structural` for the panels
+// added, removed, moved or modified and the queries changed in each panel.
+//
 // Produces:
 // - application/json
 // - text/html
@@ -985,7 +1073,7 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *contextmodel.ReqContext) respons
 		return response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
 	}
 
-	if options.DiffType == dashdiffs.DiffDelta {
+	if options.DiffType == dashdiffs.DiffDelta || options.DiffType == dashdiffs.DiffStructural {
 		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
 	}
 
@@ -1173,6 +1261,13 @@ type GetDashboardVersionByUIDParams struct {
 	UID string `json:"uid"`
 }
 
+// swagger:parameters getDashboardBlameByUID
+type GetDashboardBlameByUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
 // swagger:parameters getDashboardVersions getDashboardVersionsByUID
 type GetDashboardVersionsParams struct {
 	// Maximum number of results to return
@@ -1339,6 +1434,12 @@ type DashboardVersionsResponse struct {
 	Body []dashver.DashboardVersionMeta `json:"body"`
 }
 
+// swagger:response dashboardBlameResponse
+type DashboardBlameResponse struct {
+	// in: body
+	Body []dtos.DashboardPanelBlame `json:"body"`
+}
+
 // swagger:response dashboardVersionResponse
 type DashboardVersionResponse struct {
 	// in: body
@@ -119,25 +119,101 @@ func (hs *HTTPServer) CreateDashboardSnapshot(c *contextmodel.ReqContext) respon
 		cmd.Name = "Unnamed snapshot"
 	}
 
+	result, rsp := hs.createDashboardSnapshot(c, cmd)
+	if rsp != nil {
+		return rsp
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /dashboards/uid/{uid}/snapshots snapshots createDashboardSnapshotFromDashboard
+//
+// Create a snapshot of a saved dashboard.
+//
+// The queries of every panel are executed by the server for the given time range and variables, and their results are stored in the snapshot.
+// The response contains the errors of the panels that failed to query.
+//
+// Responses:
+// 200: createDashboardSnapshotResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) CreateDashboardSnapshotFromDashboard(c *contextmodel.ReqContext) response.Response {
+	if !hs.Cfg.SnapshotEnabled {
+		return response.Error(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+	}
+
+	cmd := dashboardsnapshots.CreateDashboardSnapshotFromDashboardCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.SignedInUser.GetOrgID(), 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian, err := guardian.NewByDashboard(c.Req.Context(), dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+	if canView, err := guardian.CanView(); err != nil || !canView {
+		return dashboardGuardianResponse(err)
+	}
+
+	captured, err := hs.dashboardsnapshotsService.CaptureDashboardSnapshot(c.Req.Context(), &dashboardsnapshots.CaptureDashboardSnapshotCommand{
+		Dashboard: dash.Data,
+		User:      c.SignedInUser,
+		From:      cmd.From,
+		To:        cmd.To,
+		Variables: cmd.Variables,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to query dashboard", err)
+	}
+
+	createCmd := dashboardsnapshots.CreateDashboardSnapshotCommand{
+		Dashboard: captured.Dashboard,
+		Name:      cmd.Name,
+		Expires:   cmd.Expires,
+		External:  cmd.External,
+	}
+	if createCmd.Name == "" {
+		createCmd.Name = dash.Title
+	}
+
+	result, rsp := hs.createDashboardSnapshot(c, createCmd)
+	if rsp != nil {
+		return rsp
+	}
+	result["panelErrors"] = captured.PanelErrors
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// createDashboardSnapshot stores the snapshot locally, or on the external snapshot server, and
+// returns the keys and URLs of the created snapshot.
+func (hs *HTTPServer) createDashboardSnapshot(c *contextmodel.ReqContext, cmd dashboardsnapshots.CreateDashboardSnapshotCommand) (util.DynMap, response.Response) {
 	var snapshotUrl string
 	cmd.ExternalURL = ""
 	cmd.OrgID = c.OrgID
 	cmd.UserID = c.UserID
 	originalDashboardURL, err := createOriginalDashboardURL(&cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Invalid app URL", err)
+		return nil, response.Error(http.StatusInternalServerError, "Invalid app URL", err)
 	}
 
 	if cmd.External {
 		if !hs.Cfg.ExternalEnabled {
-			c.JsonApiErr(http.StatusForbidden, "External dashboard creation is disabled", nil)
-			return nil
+			return nil, response.Error(http.StatusForbidden, "External dashboard creation is disabled", nil)
 		}
 
 		resp, err := createExternalDashboardSnapshot(cmd, hs.Cfg.ExternalSnapshotUrl)
 		if err != nil {
-			c.JsonApiErr(http.StatusInternalServerError, "Failed to create external snapshot", err)
-			return nil
+			return nil, response.Error(http.StatusInternalServerError, "Failed to create external snapshot", err)
 		}
 
 		snapshotUrl = resp.Url
@@ -155,8 +231,7 @@ func (hs *HTTPServer) CreateDashboardSnapshot(c *contextmodel.ReqContext) respon
 			var err error
 			cmd.Key, err = util.GetRandomString(32)
 			if err != nil {
-				c.JsonApiErr(http.StatusInternalServerError, "Could not generate random string", err)
-				return nil
+				return nil, response.Error(http.StatusInternalServerError, "Could not generate random string", err)
 			}
 		}
 
@@ -164,8 +239,7 @@ func (hs *HTTPServer) CreateDashboardSnapshot(c *contextmodel.ReqContext) respon
 			var err error
 			cmd.DeleteKey, err = util.GetRandomString(32)
 			if err != nil {
-				c.JsonApiErr(http.StatusInternalServerError, "Could not generate random string", err)
-				return nil
+				return nil, response.Error(http.StatusInternalServerError, "Could not generate random string", err)
 			}
 		}
 
@@ -176,18 +250,16 @@ func (hs *HTTPServer) CreateDashboardSnapshot(c *contextmodel.ReqContext) respon
 
 	result, err := hs.dashboardsnapshotsService.CreateDashboardSnapshot(c.Req.Context(), &cmd)
 	if err != nil {
-		c.JsonApiErr(http.StatusInternalServerError, "Failed to create snapshot", err)
-		return nil
+		return nil, response.Error(http.StatusInternalServerError, "Failed to create snapshot", err)
 	}
 
-	c.JSON(http.StatusOK, util.DynMap{
+	return util.DynMap{
 		"key":       cmd.Key,
 		"deleteKey": cmd.DeleteKey,
 		"url":       snapshotUrl,
 		"deleteUrl": setting.ToAbsUrl("api/snapshots-delete/" + cmd.DeleteKey),
 		"id":        result.ID,
-	})
-	return nil
+	}, nil
 }
 
 // GET /api/snapshots/:key
@@ -452,6 +524,16 @@ type CreateSnapshotParams struct {
 	Body dashboardsnapshots.CreateDashboardSnapshotCommand `json:"body"`
 }
 
+// swagger:parameters createDashboardSnapshotFromDashboard
+type CreateSnapshotFromDashboardParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body dashboardsnapshots.CreateDashboardSnapshotFromDashboardCommand `json:"body"`
+}
+
 // swagger:parameters searchDashboardSnapshots
 type GetSnapshotsParams struct {
 	// Search Query
@@ -493,6 +575,8 @@ type CreateSnapshotResponse struct {
 		DeleteUrl string `json:"deleteUrl"`
 		// Snapshot id
 		ID int64 `json:"id"`
+		// Errors of the panels that failed to query, by panel id. Only returned for snapshots created from a saved dashboard.
+		PanelErrors map[int64]string `json:"panelErrors,omitempty"`
 	} `json:"body"`
 }
 
@@ -408,3 +408,91 @@ func setUpSnapshotTest(t *testing.T, userId int64, deleteUrl string) dashboardsn
 	dashSnapSvc.On("DeleteDashboardSnapshot", mock.Anything, mock.AnythingOfType("*dashboardsnapshots.DeleteDashboardSnapshotCommand")).Return(nil).Maybe()
 	return dashSnapSvc
 }
+
+func TestHTTPServer_CreateDashboardSnapshotFromDashboard(t *testing.T) {
+	setup := func(t *testing.T, snapshotSvc dashboardsnapshots.Service) *webtest.Server {
+		t.Helper()
+
+		dashSvc := dashboards.NewFakeDashboardService(t)
+		dashSvc.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{
+			UID:   "dash",
+			Title: "Incident",
+			OrgID: 1,
+			Data:  simplejson.NewFromAny(map[string]any{"uid": "dash"}),
+		}, nil).Maybe()
+
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			cfg := setting.NewCfg()
+			cfg.SnapshotEnabled = true
+			hs.Cfg = cfg
+			hs.dashboardsnapshotsService = snapshotSvc
+			hs.DashboardService = dashSvc
+
+			hs.AccessControl = acimpl.ProvideAccessControl(hs.Cfg)
+			guardian.InitAccessControlGuardian(hs.Cfg, hs.AccessControl, hs.DashboardService)
+		})
+	}
+
+	allowedUser := userWithPermissions(1, []accesscontrol.Permission{
+		{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:dash"},
+	})
+
+	t.Run("should capture the dashboard and store the snapshot", func(t *testing.T) {
+		snapshotSvc := dashboardsnapshots.NewMockService(t)
+		snapshotSvc.On("CaptureDashboardSnapshot", mock.Anything, mock.MatchedBy(func(cmd *dashboardsnapshots.CaptureDashboardSnapshotCommand) bool {
+			return cmd.From == "now-1h" && cmd.To == "now" && len(cmd.Variables["host"]) == 2
+		})).Return(&dashboardsnapshots.CaptureDashboardSnapshotResult{
+			Dashboard:   simplejson.NewFromAny(map[string]any{"uid": "dash"}),
+			PanelErrors: map[int64]string{2: "bad query"},
+		}, nil)
+		snapshotSvc.On("CreateDashboardSnapshot", mock.Anything, mock.MatchedBy(func(cmd *dashboardsnapshots.CreateDashboardSnapshotCommand) bool {
+			return cmd.Name == "Incident" && cmd.Expires == 3600 && cmd.Key != "" && cmd.DeleteKey != "" &&
+				cmd.Dashboard.GetPath("snapshot", "originalUrl").MustString() == "/d/dash"
+		})).Return(&dashboardsnapshots.DashboardSnapshot{ID: 7}, nil)
+
+		server := setup(t, snapshotSvc)
+		req := server.NewRequest(http.MethodPost, "/api/dashboards/uid/dash/snapshots", strings.NewReader(`{"expires": 3600, "from": "now-1h", "to": "now", "variables": {"host": ["a", "b"]}}`))
+		req.Header.Set("Content-Type", "application/json")
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, allowedUser))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		body, err := simplejson.NewFromReader(res.Body)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, int64(7), body.Get("id").MustInt64())
+		assert.Equal(t, "bad query", body.GetPath("panelErrors", "2").MustString())
+	})
+
+	t.Run("should return the status of the capture errors", func(t *testing.T) {
+		for _, tc := range []struct {
+			err    error
+			status int
+		}{
+			{err: dashboardsnapshots.ErrInvalidTimeRange.Errorf("invalid time range"), status: http.StatusBadRequest},
+			{err: errors.New("encode failed"), status: http.StatusInternalServerError},
+		} {
+			snapshotSvc := dashboardsnapshots.NewMockService(t)
+			snapshotSvc.On("CaptureDashboardSnapshot", mock.Anything, mock.Anything).Return(nil, tc.err)
+
+			server := setup(t, snapshotSvc)
+			req := server.NewRequest(http.MethodPost, "/api/dashboards/uid/dash/snapshots", strings.NewReader(`{}`))
+			req.Header.Set("Content-Type", "application/json")
+			res, err := server.Send(webtest.RequestWithSignedInUser(req, allowedUser))
+			require.NoError(t, err)
+			assert.Equal(t, tc.status, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		}
+	})
+
+	t.Run("should not capture the dashboard without permissions", func(t *testing.T) {
+		server := setup(t, dashboardsnapshots.NewMockService(t))
+		res, err := server.Send(webtest.RequestWithSignedInUser(
+			server.NewRequest(http.MethodPost, "/api/dashboards/uid/dash/snapshots", strings.NewReader(`{}`)),
+			&user.SignedInUser{UserID: 1, OrgID: 1},
+		))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
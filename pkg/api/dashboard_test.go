@@ -339,6 +339,10 @@ func TestHTTPServer_GetDashboardVersions_AccessControl(t *testing.T) {
 		return server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/versions"), userWithPermissions(1, permissions)))
 	}
 
+	getBlame := func(server *webtest.Server, permissions []accesscontrol.Permission) (*http.Response, error) {
+		return server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/dashboards/uid/1/versions/blame"), userWithPermissions(1, permissions)))
+	}
+
 	t.Run("Should not be able to list dashboard versions without correct permission", func(t *testing.T) {
 		server := setup()
 
@@ -350,6 +354,11 @@ func TestHTTPServer_GetDashboardVersions_AccessControl(t *testing.T) {
 		res, err = getVersion(server, []accesscontrol.Permission{})
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		res, err = getBlame(server, []accesscontrol.Permission{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
 
 		require.NoError(t, res.Body.Close())
 	})
@@ -370,6 +379,11 @@ func TestHTTPServer_GetDashboardVersions_AccessControl(t *testing.T) {
 		res, err = getVersion(server, permissions)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		res, err = getBlame(server, permissions)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
 
 		require.NoError(t, res.Body.Close())
 	})
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /dashboards/version-retention dashboard_versions getDashboardVersionRetentionPolicies
+//
+// Get the retention policies of the dashboard versions of the organization.
+//
+// The policy with an empty folder UID is the default policy of the organization. The dashboards of folders
+// without policy use the default policy, and without default policy they keep the configured number of versions.
+//
+// Responses:
+// 200: dashboardVersionRetentionPoliciesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) GetDashboardVersionRetentionPolicies(c *contextmodel.ReqContext) response.Response {
+	policies, err := hs.dashboardVersionService.ListRetentionPolicies(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get retention policies", err)
+	}
+	return response.JSON(http.StatusOK, policies)
+}
+
+// swagger:route PUT /dashboards/version-retention dashboard_versions setDashboardVersionRetentionPolicy
+//
+// Set the retention policy of the dashboard versions of a folder, or the default policy of the organization.
+//
+// The policy keeps the given number of most recent versions of each dashboard, and the last version of each day
+// for the given number of days.
+//
+// Responses:
+// 200: dashboardVersionRetentionPolicyResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) SetDashboardVersionRetentionPolicy(c *contextmodel.ReqContext) response.Response {
+	cmd := dashver.SetRetentionPolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+
+	if cmd.FolderUID != "" {
+		if _, err := hs.folderService.Get(c.Req.Context(), &folder.GetFolderQuery{OrgID: cmd.OrgID, UID: &cmd.FolderUID, SignedInUser: c.SignedInUser}); err != nil {
+			return apierrors.ToFolderErrorResponse(err)
+		}
+	}
+
+	policy, err := hs.dashboardVersionService.SetRetentionPolicy(c.Req.Context(), &cmd)
+	if err != nil {
+		if errors.Is(err, dashver.ErrInvalidVersionsToKeep) || errors.Is(err, dashver.ErrInvalidDailyVersionsToKeep) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to set retention policy", err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+// swagger:route DELETE /dashboards/version-retention dashboard_versions deleteDashboardVersionRetentionPolicy
+//
+// Delete the retention policy of the dashboard versions of a folder, or the default policy of the organization.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteDashboardVersionRetentionPolicy(c *contextmodel.ReqContext) response.Response {
+	cmd := dashver.DeleteRetentionPolicyCommand{
+		OrgID:     c.SignedInUser.GetOrgID(),
+		FolderUID: c.Query("folderUid"),
+	}
+	if err := hs.dashboardVersionService.DeleteRetentionPolicy(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, dashver.ErrRetentionPolicyNotFound) {
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete retention policy", err)
+	}
+	return response.Success("Retention policy deleted")
+}
+
+// swagger:parameters setDashboardVersionRetentionPolicy
+type SetDashboardVersionRetentionPolicyParams struct {
+	// in:body
+	// required:true
+	Body dashver.SetRetentionPolicyCommand
+}
+
+// swagger:parameters deleteDashboardVersionRetentionPolicy
+type DeleteDashboardVersionRetentionPolicyParams struct {
+	// UID of the folder of the policy, empty for the default policy of the organization
+	// in:query
+	// required:false
+	FolderUID string `json:"folderUid"`
+}
+
+// swagger:response dashboardVersionRetentionPoliciesResponse
+type DashboardVersionRetentionPoliciesResponse struct {
+	// in: body
+	Body []*dashver.RetentionPolicy `json:"body"`
+}
+
+// swagger:response dashboardVersionRetentionPolicyResponse
+type DashboardVersionRetentionPolicyResponse struct {
+	// in: body
+	Body *dashver.RetentionPolicy `json:"body"`
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/dashboardversion/dashvertest"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestDashboardVersionRetentionAPI(t *testing.T) {
+	const url = "/api/dashboards/version-retention"
+
+	setup := func(folderSvc *foldertest.FakeService) (*webtest.Server, *dashvertest.FakeDashboardVersionService) {
+		dashVerSvc := dashvertest.NewDashboardVersionServiceFake()
+		dashVerSvc.ExpectedRetentionPolicy = &dashver.RetentionPolicy{FolderUID: "folder", VersionsToKeep: 5}
+		server := SetupAPITestServer(t, func(hs *HTTPServer) {
+			hs.dashboardVersionService = dashVerSvc
+			hs.folderService = folderSvc
+		})
+		return server, dashVerSvc
+	}
+
+	send := func(server *webtest.Server, method, url, body string, role org.RoleType) *http.Response {
+		req := webtest.RequestWithSignedInUser(server.NewRequest(method, url, strings.NewReader(body)), &user.SignedInUser{OrgID: 1, OrgRole: role})
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res
+	}
+
+	t.Run("Should require the org admin role", func(t *testing.T) {
+		server, _ := setup(foldertest.NewFakeService())
+		assert.Equal(t, http.StatusForbidden, send(server, http.MethodGet, url, "", org.RoleEditor).StatusCode)
+		assert.Equal(t, http.StatusForbidden, send(server, http.MethodPut, url, `{"versionsToKeep": 5}`, org.RoleEditor).StatusCode)
+		assert.Equal(t, http.StatusForbidden, send(server, http.MethodDelete, url, "", org.RoleEditor).StatusCode)
+	})
+
+	t.Run("Should list, set and delete policies as org admin", func(t *testing.T) {
+		server, _ := setup(&foldertest.FakeService{ExpectedFolder: &folder.Folder{UID: "folder"}})
+		assert.Equal(t, http.StatusOK, send(server, http.MethodGet, url, "", org.RoleAdmin).StatusCode)
+		assert.Equal(t, http.StatusOK, send(server, http.MethodPut, url, `{"folderUid": "folder", "versionsToKeep": 5}`, org.RoleAdmin).StatusCode)
+		assert.Equal(t, http.StatusOK, send(server, http.MethodDelete, url+"?folderUid=folder", "", org.RoleAdmin).StatusCode)
+	})
+
+	t.Run("Should not set the policy of a missing folder", func(t *testing.T) {
+		server, _ := setup(&foldertest.FakeService{ExpectedError: dashboards.ErrFolderNotFound})
+		assert.Equal(t, http.StatusNotFound, send(server, http.MethodPut, url, `{"folderUid": "missing", "versionsToKeep": 5}`, org.RoleAdmin).StatusCode)
+	})
+
+	t.Run("Should reject invalid policies", func(t *testing.T) {
+		server, dashVerSvc := setup(foldertest.NewFakeService())
+		dashVerSvc.ExpectedError = dashver.ErrInvalidVersionsToKeep
+		assert.Equal(t, http.StatusBadRequest, send(server, http.MethodPut, url, `{"versionsToKeep": 0}`, org.RoleAdmin).StatusCode)
+	})
+
+	t.Run("Should return not found when deleting a missing policy", func(t *testing.T) {
+		server, dashVerSvc := setup(foldertest.NewFakeService())
+		dashVerSvc.ExpectedError = dashver.ErrRetentionPolicyNotFound
+		assert.Equal(t, http.StatusNotFound, send(server, http.MethodDelete, url, "", org.RoleAdmin).StatusCode)
+	})
+}
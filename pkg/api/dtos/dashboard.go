@@ -72,6 +72,17 @@ type CalculateDiffTarget struct {
 	UnsavedDashboard *simplejson.Json `json:"unsavedDashboard"`
 }
 
+// DashboardPanelBlame is the version that last changed a panel of a dashboard.
+type DashboardPanelBlame struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	Type      string    `json:"type"`
+	Version   int       `json:"version"`
+	Created   time.Time `json:"created"`
+	CreatedBy string    `json:"createdBy"`
+	Message   string    `json:"message"`
+}
+
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
 }
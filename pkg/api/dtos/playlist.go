@@ -22,3 +22,24 @@ func (slice PlaylistDashboardsSlice) Less(i, j int) bool {
 func (slice PlaylistDashboardsSlice) Swap(i, j int) {
 	slice[i], slice[j] = slice[j], slice[i]
 }
+
+// PlaylistPlay is a playlist with its items resolved to the sequence of dashboards to display.
+type PlaylistPlay struct {
+	Uid      string             `json:"uid"`
+	Name     string             `json:"name"`
+	Interval string             `json:"interval"`
+	Items    []PlaylistPlayItem `json:"items"`
+}
+
+// PlaylistPlayItem is a dashboard of a playlist, with the display interval, time range and variables of its item.
+type PlaylistPlayItem struct {
+	DashboardUid string            `json:"dashboardUid"`
+	Title        string            `json:"title"`
+	Url          string            `json:"url"`
+	Interval     string            `json:"interval"`
+	TimeFrom     string            `json:"timeFrom,omitempty"`
+	TimeTo       string            `json:"timeTo,omitempty"`
+	Variables    map[string]string `json:"variables,omitempty"`
+	// Order is the index of the playlist item the dashboard was resolved from.
+	Order int `json:"order"`
+}
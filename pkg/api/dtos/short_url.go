@@ -1,5 +1,7 @@
 package dtos
 
+import "time"
+
 type ShortURL struct {
 	UID string `json:"uid"`
 	URL string `json:"url"`
@@ -7,4 +9,18 @@ type ShortURL struct {
 
 type CreateShortURLCmd struct {
 	Path string `json:"path"`
+	// UID is an optional custom slug.
+	UID string `json:"uid"`
+	// ExpiresAt is the optional time after which the short URL no longer redirects.
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type CleanUpShortURLsCmd struct {
+	// UnusedFor is the duration, such as 90d, since the short URLs were last followed or created.
+	UnusedFor string `json:"unusedFor"`
+}
+
+type CleanUpShortURLsResult struct {
+	Message    string `json:"message"`
+	NumDeleted int64  `json:"numDeleted"`
 }
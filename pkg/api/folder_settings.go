@@ -0,0 +1,166 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/response"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/foldersettings"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /folders/{folder_uid}/settings folders getFolderSettings
+//
+// Get the settings set on a folder.
+//
+// Responses:
+// 200: folderSettingsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetFolderSettings(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if resp := hs.checkSettingsFolder(c, uid); resp != nil {
+		return resp
+	}
+
+	settings, err := hs.folderSettingsService.Get(c.Req.Context(), &foldersettings.GetFolderSettingsQuery{OrgID: c.SignedInUser.GetOrgID(), FolderUID: uid})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get folder settings", err)
+	}
+	return response.JSON(http.StatusOK, settings)
+}
+
+// swagger:route GET /folders/{folder_uid}/settings/resolved folders getResolvedFolderSettings
+//
+// Get the settings of a folder, including the settings inherited from its parent folders.
+//
+// These settings are applied to the dashboards created in the folder.
+//
+// Responses:
+// 200: resolvedFolderSettingsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetResolvedFolderSettings(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if resp := hs.checkSettingsFolder(c, uid); resp != nil {
+		return resp
+	}
+
+	resolved, err := hs.folderSettingsService.GetResolved(c.Req.Context(), &foldersettings.GetFolderSettingsQuery{OrgID: c.SignedInUser.GetOrgID(), FolderUID: uid})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get folder settings", err)
+	}
+	return response.JSON(http.StatusOK, resolved)
+}
+
+// swagger:route PUT /folders/{folder_uid}/settings folders updateFolderSettings
+//
+// Replace the settings of a folder.
+//
+// Responses:
+// 200: folderSettingsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) UpdateFolderSettings(c *contextmodel.ReqContext) response.Response {
+	cmd := foldersettings.SaveFolderSettingsCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.FolderUID = web.Params(c.Req)[":uid"]
+
+	if resp := hs.checkSettingsFolder(c, cmd.FolderUID); resp != nil {
+		return resp
+	}
+
+	// the permission template grants permissions on the dashboards created in the folder
+	if len(cmd.PermissionTemplate) > 0 {
+		evaluator := ac.EvalPermission(dashboards.ActionFoldersPermissionsWrite, dashboards.ScopeFoldersProvider.GetResourceScopeUID(cmd.FolderUID))
+		if canSetPermissions, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator); err != nil || !canSetPermissions {
+			if err != nil {
+				return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+			}
+			return response.Error(http.StatusForbidden, "Permission denied to set the permission template of the folder", nil)
+		}
+	}
+
+	settings, err := hs.folderSettingsService.Save(c.Req.Context(), &cmd)
+	if err != nil {
+		if errors.Is(err, foldersettings.ErrInvalidRefresh) || errors.Is(err, foldersettings.ErrInvalidTimezone) ||
+			errors.Is(err, foldersettings.ErrInvalidPermissionTemplate) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to save folder settings", err)
+	}
+	return response.JSON(http.StatusOK, settings)
+}
+
+// swagger:route DELETE /folders/{folder_uid}/settings folders deleteFolderSettings
+//
+// Delete the settings of a folder, so that it inherits all settings from its parent folders.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteFolderSettings(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if resp := hs.checkSettingsFolder(c, uid); resp != nil {
+		return resp
+	}
+
+	if err := hs.folderSettingsService.Delete(c.Req.Context(), &foldersettings.DeleteFolderSettingsCommand{OrgID: c.SignedInUser.GetOrgID(), FolderUID: uid}); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to delete folder settings", err)
+	}
+	return response.Success("Folder settings deleted")
+}
+
+func (hs *HTTPServer) checkSettingsFolder(c *contextmodel.ReqContext, uid string) response.Response {
+	if _, err := hs.folderService.Get(c.Req.Context(), &folder.GetFolderQuery{OrgID: c.SignedInUser.GetOrgID(), UID: &uid, SignedInUser: c.SignedInUser}); err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+	return nil
+}
+
+// swagger:parameters getFolderSettings getResolvedFolderSettings deleteFolderSettings
+type FolderSettingsParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+}
+
+// swagger:parameters updateFolderSettings
+type UpdateFolderSettingsParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+	// in:body
+	// required:true
+	Body foldersettings.SaveFolderSettingsCommand
+}
+
+// swagger:response folderSettingsResponse
+type FolderSettingsResponse struct {
+	// in: body
+	Body *foldersettings.FolderSettings `json:"body"`
+}
+
+// swagger:response resolvedFolderSettingsResponse
+type ResolvedFolderSettingsResponse struct {
+	// in: body
+	Body *foldersettings.ResolvedFolderSettings `json:"body"`
+}
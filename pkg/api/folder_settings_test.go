@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/services/foldersettings"
+	"github.com/grafana/grafana/pkg/services/foldersettings/foldersettingstest"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestFolderSettingsAPIEndpoints(t *testing.T) {
+	folderService := &foldertest.FakeService{ExpectedFolder: &folder.Folder{ID: 1, UID: "ops", Title: "Ops"}}
+	folderSettingsService := foldersettingstest.NewFakeService()
+	folderSettingsService.ExpectedResolvedSettings = &foldersettings.ResolvedFolderSettings{
+		FolderUID:      "ops",
+		DefaultRefresh: "1m",
+		Sources:        map[string]string{"defaultRefresh": "root"},
+	}
+
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.folderService = folderService
+		hs.folderSettingsService = folderSettingsService
+	})
+
+	readPermissions := []accesscontrol.Permission{{Action: dashboards.ActionFoldersRead, Scope: dashboards.ScopeFoldersProvider.GetResourceScopeUID("ops")}}
+	writePermissions := []accesscontrol.Permission{{Action: dashboards.ActionFoldersWrite, Scope: dashboards.ScopeFoldersProvider.GetResourceScopeUID("ops")}}
+
+	t.Run("Can get the resolved settings of a folder", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest("/api/folders/ops/settings/resolved"), userWithPermissions(1, readPermissions))
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, res.Body.Close()) }()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		resolved := foldersettings.ResolvedFolderSettings{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&resolved))
+		require.Equal(t, "1m", resolved.DefaultRefresh)
+		require.Equal(t, "root", resolved.Sources["defaultRefresh"])
+	})
+
+	t.Run("Can not update the settings of a folder without write permission", func(t *testing.T) {
+		req := server.NewRequest(http.MethodPut, "/api/folders/ops/settings", strings.NewReader(`{"defaultRefresh": "1m"}`))
+		req = webtest.RequestWithSignedInUser(req, userWithPermissions(1, readPermissions))
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+	})
+
+	t.Run("Can update the settings of a folder", func(t *testing.T) {
+		req := server.NewRequest(http.MethodPut, "/api/folders/ops/settings", strings.NewReader(`{"defaultRefresh": "1m", "requiredTags": ["ops"]}`))
+		req = webtest.RequestWithSignedInUser(req, userWithPermissions(1, writePermissions))
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		require.Len(t, folderSettingsService.SavedCommands, 1)
+		saved := folderSettingsService.SavedCommands[0]
+		require.Equal(t, int64(1), saved.OrgID)
+		require.Equal(t, "ops", saved.FolderUID)
+		require.Equal(t, []string{"ops"}, saved.RequiredTags)
+	})
+
+	t.Run("Can not set the permission template of a folder without permissions write permission", func(t *testing.T) {
+		body := `{"permissionTemplate": [{"builtinRole": "Viewer", "permission": "View"}]}`
+		req := server.NewRequest(http.MethodPut, "/api/folders/ops/settings", strings.NewReader(body))
+		req = webtest.RequestWithSignedInUser(req, userWithPermissions(1, writePermissions))
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusForbidden, res.StatusCode)
+
+		permissions := append([]accesscontrol.Permission{
+			{Action: dashboards.ActionFoldersPermissionsWrite, Scope: dashboards.ScopeFoldersProvider.GetResourceScopeUID("ops")},
+		}, writePermissions...)
+		req = server.NewRequest(http.MethodPut, "/api/folders/ops/settings", strings.NewReader(body))
+		req = webtest.RequestWithSignedInUser(req, userWithPermissions(1, permissions))
+		res, err = server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("Invalid settings return bad request", func(t *testing.T) {
+		folderSettingsService.ExpectedError = foldersettings.ErrInvalidTimezone
+		t.Cleanup(func() { folderSettingsService.ExpectedError = nil })
+
+		req := server.NewRequest(http.MethodPut, "/api/folders/ops/settings", strings.NewReader(`{"timezone": "Mars/Olympus_Mons"}`))
+		req = webtest.RequestWithSignedInUser(req, userWithPermissions(1, writePermissions))
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("Settings of a missing folder return not found", func(t *testing.T) {
+		folderService.ExpectedError = dashboards.ErrFolderNotFound
+		t.Cleanup(func() { folderService.ExpectedError = nil })
+
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest("/api/folders/ops/settings"), userWithPermissions(1, readPermissions))
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -125,6 +126,33 @@ func (hs *HTTPServer) GetPlaylistDashboards(c *contextmodel.ReqContext) response
 	return response.JSON(http.StatusOK, playlists)
 }
 
+// swagger:route GET /playlists/{uid}/play playlists getPlaylistPlay
+//
+// Get the resolved sequence of dashboards of a playlist.
+//
+// The items are resolved to the dashboards the user can access, with the display interval, time range and variables
+// of each item, so that the playlist can be played without client logic.
+//
+// Responses:
+// 200: getPlaylistPlayResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetPlaylistPlay(c *contextmodel.ReqContext) response.Response {
+	playlistUID := web.Params(c.Req)[":uid"]
+
+	play, err := hs.LoadPlaylistPlay(c.Req.Context(), c.SignedInUser, playlistUID)
+	if err != nil {
+		if errors.Is(err, playlist.ErrPlaylistNotFound) {
+			return response.Error(http.StatusNotFound, "Playlist not found", err)
+		}
+		return response.Error(500, "Could not load playlist", err)
+	}
+
+	return response.JSON(http.StatusOK, play)
+}
+
 // swagger:route DELETE /playlists/{uid} playlists deletePlaylist
 //
 // Delete playlist.
@@ -152,6 +180,7 @@ func (hs *HTTPServer) DeletePlaylist(c *contextmodel.ReqContext) response.Respon
 //
 // Responses:
 // 200: createPlaylistResponse
+// 400: badRequestError
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
@@ -165,6 +194,9 @@ func (hs *HTTPServer) CreatePlaylist(c *contextmodel.ReqContext) response.Respon
 
 	p, err := hs.playlistService.Create(c.Req.Context(), &cmd)
 	if err != nil {
+		if isInvalidPlaylistItemError(err) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
 		return response.Error(500, "Failed to create playlist", err)
 	}
 
@@ -177,6 +209,7 @@ func (hs *HTTPServer) CreatePlaylist(c *contextmodel.ReqContext) response.Respon
 //
 // Responses:
 // 200: updatePlaylistResponse
+// 400: badRequestError
 // 401: unauthorisedError
 // 403: forbiddenError
 // 404: notFoundError
@@ -191,6 +224,9 @@ func (hs *HTTPServer) UpdatePlaylist(c *contextmodel.ReqContext) response.Respon
 
 	_, err := hs.playlistService.Update(c.Req.Context(), &cmd)
 	if err != nil {
+		if isInvalidPlaylistItemError(err) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
 		return response.Error(500, "Failed to save playlist", err)
 	}
 
@@ -204,6 +240,10 @@ func (hs *HTTPServer) UpdatePlaylist(c *contextmodel.ReqContext) response.Respon
 	return response.JSON(http.StatusOK, dto)
 }
 
+func isInvalidPlaylistItemError(err error) bool {
+	return errors.Is(err, playlist.ErrInvalidItemInterval) || errors.Is(err, playlist.ErrInvalidItemSearch)
+}
+
 // swagger:parameters searchPlaylists
 type SearchPlaylistsParams struct {
 	// in:query
@@ -235,6 +275,13 @@ type GetPlaylistDashboardsParams struct {
 	UID string `json:"uid"`
 }
 
+// swagger:parameters getPlaylistPlay
+type GetPlaylistPlayParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
 // swagger:parameters deletePlaylist
 type DeletePlaylistParams struct {
 	// in:path
@@ -287,6 +334,13 @@ type GetPlaylistDashboardsResponse struct {
 	Body dtos.PlaylistDashboardsSlice `json:"body"`
 }
 
+// swagger:response getPlaylistPlayResponse
+type GetPlaylistPlayResponse struct {
+	// The response message
+	// in: body
+	Body dtos.PlaylistPlay `json:"body"`
+}
+
 // swagger:response updatePlaylistResponse
 type UpdatePlaylistResponse struct {
 	// The response message
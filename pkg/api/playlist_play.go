@@ -3,6 +3,8 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
 	"sort"
 	"strconv"
 
@@ -11,9 +13,13 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/playlist"
 	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/search/model"
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
+// playlistSearchLimit is the maximum number of dashboards a playlist item resolves to.
+const playlistSearchLimit = 100
+
 func (hs *HTTPServer) populateDashboardsByID(ctx context.Context, dashboardByIDs []int64, dashboardIDOrder map[int64]int) (dtos.PlaylistDashboardsSlice, error) {
 	result := make(dtos.PlaylistDashboardsSlice, 0)
 
@@ -108,3 +114,103 @@ func (hs *HTTPServer) LoadPlaylistDashboards(ctx context.Context, orgID int64, s
 	sort.Sort(result)
 	return result, nil
 }
+
+// LoadPlaylistPlay resolves the items of a playlist to the sequence of dashboards to display, so that kiosks can play
+// the playlist without client logic. The tag and search items are resolved with the dashboards the user can see at
+// the time of the request, and the dashboards that are not found or not accessible are skipped.
+func (hs *HTTPServer) LoadPlaylistPlay(ctx context.Context, signedInUser *user.SignedInUser, playlistUID string) (*dtos.PlaylistPlay, error) {
+	dto, err := hs.playlistService.Get(ctx, &playlist.GetPlaylistByUidQuery{UID: playlistUID, OrgId: signedInUser.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	play := &dtos.PlaylistPlay{
+		Uid:      dto.Uid,
+		Name:     dto.Name,
+		Interval: dto.Interval,
+		Items:    make([]dtos.PlaylistPlayItem, 0),
+	}
+
+	for i, item := range dto.Items {
+		query, err := playlistItemSearchQuery(item)
+		if err != nil {
+			return nil, err
+		}
+		query.SignedInUser = signedInUser
+		query.OrgId = signedInUser.OrgID
+		query.Type = string(model.DashHitDB)
+		query.Limit = playlistSearchLimit
+
+		hits, err := hs.SearchService.SearchHandler(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, hit := range hits {
+			play.Items = append(play.Items, playlistPlayItem(dto, item, hit, i))
+		}
+	}
+
+	return play, nil
+}
+
+// playlistItemSearchQuery returns the search query of the dashboards of a playlist item.
+func playlistItemSearchQuery(item playlist.PlaylistItemDTO) (*search.Query, error) {
+	switch item.Type {
+	case playlist.ItemTypeDashboardByUID:
+		return &search.Query{DashboardUIDs: []string{item.Value}}, nil
+	case playlist.ItemTypeDashboardByID:
+		dashboardID, err := strconv.ParseInt(item.Value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dashboard id %q: %w", item.Value, err)
+		}
+		return &search.Query{DashboardIds: []int64{dashboardID}}, nil
+	case playlist.ItemTypeDashboardByTag:
+		return &search.Query{Tags: []string{item.Value}}, nil
+	case playlist.ItemTypeDashboardBySearch:
+		itemSearch, err := playlist.ParseItemSearch(item.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &search.Query{
+			Title:      itemSearch.Query,
+			Tags:       itemSearch.Tags,
+			FolderUIDs: itemSearch.FolderUIDs,
+			IsStarred:  itemSearch.Starred,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported playlist item type %q", item.Type)
+	}
+}
+
+func playlistPlayItem(dto *playlist.PlaylistDTO, item playlist.PlaylistItemDTO, hit *model.Hit, order int) dtos.PlaylistPlayItem {
+	playItem := dtos.PlaylistPlayItem{
+		DashboardUid: hit.UID,
+		Title:        hit.Title,
+		Url:          hit.URL,
+		Interval:     dto.Interval,
+		Variables:    item.Variables,
+		Order:        order,
+	}
+	if item.Interval != nil && *item.Interval != "" {
+		playItem.Interval = *item.Interval
+	}
+
+	params := url.Values{}
+	if item.TimeFrom != nil && *item.TimeFrom != "" {
+		playItem.TimeFrom = *item.TimeFrom
+		params.Set("from", playItem.TimeFrom)
+	}
+	if item.TimeTo != nil && *item.TimeTo != "" {
+		playItem.TimeTo = *item.TimeTo
+		params.Set("to", playItem.TimeTo)
+	}
+	for name, value := range item.Variables {
+		params.Set("var-"+name, value)
+	}
+	if len(params) > 0 {
+		playItem.Url += "?" + params.Encode()
+	}
+
+	return playItem
+}
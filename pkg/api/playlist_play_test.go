@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/services/playlist/playlisttest"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+type playlistSearchService struct {
+	mockSearchService
+	queries []search.Query
+	hits    map[string]model.HitList
+}
+
+func (s *playlistSearchService) SearchHandler(_ context.Context, q *search.Query) (model.HitList, error) {
+	s.queries = append(s.queries, *q)
+	switch {
+	case len(q.DashboardUIDs) > 0:
+		return s.hits[q.DashboardUIDs[0]], nil
+	case len(q.Tags) > 0:
+		return s.hits[q.Tags[0]], nil
+	}
+	return nil, nil
+}
+
+func TestGetPlaylistPlay(t *testing.T) {
+	playlistService := playlisttest.NewPlaylistServiveFake()
+	playlistService.ExpectedPlaylist = &playlist.Playlist{UID: "tv", OrgId: 1}
+	playlistService.ExpectedPlaylistDTO = &playlist.PlaylistDTO{
+		Uid:      "tv",
+		Name:     "TV",
+		Interval: "5m",
+		Items: []playlist.PlaylistItemDTO{
+			{Type: playlist.ItemTypeDashboardByUID, Value: "home", TimeFrom: util.Pointer("now-6h"), TimeTo: util.Pointer("now"),
+				Variables: map[string]string{"env": "prod"}},
+			{Type: playlist.ItemTypeDashboardByUID, Value: "missing"},
+			{Type: playlist.ItemTypeDashboardBySearch, Value: "folderUIDs=ops&tag=oncall&starred=true", Interval: util.Pointer("30s")},
+		},
+	}
+	searchService := &playlistSearchService{hits: map[string]model.HitList{
+		"home":   {{UID: "home", Title: "Home", URL: "/d/home/home"}},
+		"oncall": {{UID: "a", Title: "A", URL: "/d/a/a"}, {UID: "b", Title: "B", URL: "/d/b/b"}},
+	}}
+
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.playlistService = playlistService
+		hs.SearchService = searchService
+	})
+
+	req := webtest.RequestWithSignedInUser(server.NewGetRequest("/api/playlists/tv/play"), &user.SignedInUser{UserID: 1, OrgID: 1})
+	res, err := server.Send(req)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, res.Body.Close()) }()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	play := dtos.PlaylistPlay{}
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&play))
+	require.Equal(t, dtos.PlaylistPlay{
+		Uid:      "tv",
+		Name:     "TV",
+		Interval: "5m",
+		Items: []dtos.PlaylistPlayItem{
+			{DashboardUid: "home", Title: "Home", Url: "/d/home/home?from=now-6h&to=now&var-env=prod", Interval: "5m",
+				TimeFrom: "now-6h", TimeTo: "now", Variables: map[string]string{"env": "prod"}, Order: 0},
+			{DashboardUid: "a", Title: "A", Url: "/d/a/a", Interval: "30s", Order: 2},
+			{DashboardUid: "b", Title: "B", Url: "/d/b/b", Interval: "30s", Order: 2},
+		},
+	}, play)
+
+	require.Len(t, searchService.queries, 3)
+	searchQuery := searchService.queries[2]
+	require.Equal(t, []string{"ops"}, searchQuery.FolderUIDs)
+	require.Equal(t, []string{"oncall"}, searchQuery.Tags)
+	require.True(t, searchQuery.IsStarred)
+	require.Equal(t, string(model.DashHitDB), searchQuery.Type)
+	require.Equal(t, int64(1), searchQuery.OrgId)
+}
+
+func TestGetPlaylistPlay_notFound(t *testing.T) {
+	playlistService := playlisttest.NewPlaylistServiveFake()
+	playlistService.ExpectedError = playlist.ErrPlaylistNotFound
+
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.playlistService = playlistService
+		hs.SearchService = &playlistSearchService{}
+	})
+
+	req := webtest.RequestWithSignedInUser(server.NewGetRequest("/api/playlists/missing/play"), &user.SignedInUser{UserID: 1, OrgID: 1})
+	res, err := server.Send(req)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
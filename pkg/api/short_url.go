@@ -3,11 +3,17 @@ package api
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -21,12 +27,20 @@ func (hs *HTTPServer) createShortURL(c *contextmodel.ReqContext) response.Respon
 		return response.Err(shorturls.ErrShortURLBadRequest.Errorf("bad request data: %w", err))
 	}
 	hs.log.Debug("Received request to create short URL", "path", cmd.Path)
-	shortURL, err := hs.ShortURLService.CreateShortURL(c.Req.Context(), c.SignedInUser, cmd.Path)
+
+	createCmd := &shorturls.CreateShortURLCommand{Path: cmd.Path, UID: cmd.UID}
+	if cmd.ExpiresAt != nil {
+		createCmd.ExpiresAt = *cmd.ExpiresAt
+	}
+	shortURL, err := hs.ShortURLService.CreateShortURL(c.Req.Context(), c.SignedInUser, createCmd)
 	if err != nil {
+		if shorturls.ErrShortURLExists.Is(err) {
+			return response.Error(http.StatusConflict, "A short URL with the same slug already exists", err)
+		}
 		return response.Err(err)
 	}
 
-	url := fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(setting.AppUrl, "/"), shortURL.Uid, c.OrgID)
+	url := shortURLLink(shortURL.Uid, c.OrgID)
 	c.Logger.Debug("Created short URL", "url", url)
 
 	dto := dtos.ShortURL{
@@ -37,6 +51,81 @@ func (hs *HTTPServer) createShortURL(c *contextmodel.ReqContext) response.Respon
 	return response.JSON(http.StatusOK, dto)
 }
 
+// searchShortURLs handles requests to list the short URLs of the org. Org admins see the short URLs of all users,
+// other users only see their own short URLs.
+func (hs *HTTPServer) searchShortURLs(c *contextmodel.ReqContext) response.Response {
+	query := &shorturls.SearchShortURLsQuery{
+		OrgID:     c.OrgID,
+		CreatedBy: c.QueryInt64("createdBy"),
+		Query:     c.Query("query"),
+		Sort:      c.Query("sort"),
+		Page:      c.QueryInt("page"),
+		Limit:     c.QueryInt("perpage"),
+	}
+	if !c.SignedInUser.HasRole(org.RoleAdmin) {
+		query.CreatedBy = c.SignedInUser.UserID
+	}
+
+	result, err := hs.ShortURLService.SearchShortURLs(c.Req.Context(), query)
+	if err != nil {
+		return response.Err(err)
+	}
+	for _, s := range result.ShortURLs {
+		s.URL = shortURLLink(s.UID, c.OrgID)
+	}
+
+	return response.JSON(http.StatusOK, result)
+}
+
+// deleteShortURL handles requests to delete a short URL. Org admins can delete any short URL of the org, other users
+// can only delete their own short URLs.
+func (hs *HTTPServer) deleteShortURL(c *contextmodel.ReqContext) response.Response {
+	cmd := &shorturls.DeleteShortURLByUIDCommand{
+		OrgID: c.OrgID,
+		UID:   web.Params(c.Req)[":uid"],
+	}
+	if !c.SignedInUser.HasRole(org.RoleAdmin) {
+		cmd.CreatedBy = c.SignedInUser.UserID
+	}
+
+	if err := hs.ShortURLService.DeleteShortURL(c.Req.Context(), cmd); err != nil {
+		return response.Err(err)
+	}
+
+	return response.Success("Short URL deleted")
+}
+
+// cleanUpShortURLs handles requests to delete the short URLs that have not been followed for a given duration, and
+// the expired short URLs. Only the short URLs of the user are deleted, unless the user is an org admin.
+func (hs *HTTPServer) cleanUpShortURLs(c *contextmodel.ReqContext) response.Response {
+	cmd := dtos.CleanUpShortURLsCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Err(shorturls.ErrShortURLBadRequest.Errorf("bad request data: %w", err))
+	}
+
+	unusedFor, err := gtime.ParseDuration(cmd.UnusedFor)
+	if err != nil || unusedFor <= 0 {
+		return response.Err(shorturls.ErrShortURLBadRequest.Errorf("invalid unusedFor duration: %s", cmd.UnusedFor))
+	}
+
+	deleteCmd := &shorturls.DeleteShortURLsCommand{
+		OrgID:        c.OrgID,
+		NotSeenSince: time.Now().Add(-unusedFor),
+	}
+	if !c.SignedInUser.HasRole(org.RoleAdmin) {
+		deleteCmd.CreatedBy = c.SignedInUser.UserID
+	}
+
+	if err := hs.ShortURLService.DeleteShortURLs(c.Req.Context(), deleteCmd); err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, dtos.CleanUpShortURLsResult{
+		Message:    "Short URLs deleted",
+		NumDeleted: deleteCmd.NumDeleted,
+	})
+}
+
 func (hs *HTTPServer) redirectFromShortURL(c *contextmodel.ReqContext) {
 	shortURLUID := web.Params(c.Req)[":uid"]
 
@@ -46,8 +135,8 @@ func (hs *HTTPServer) redirectFromShortURL(c *contextmodel.ReqContext) {
 
 	shortURL, err := hs.ShortURLService.GetShortURLByUID(c.Req.Context(), c.SignedInUser, shortURLUID)
 	if err != nil {
-		if shorturls.ErrShortURLNotFound.Is(err) {
-			hs.log.Debug("Not redirecting short URL since not found")
+		if shorturls.ErrShortURLNotFound.Is(err) || shorturls.ErrShortURLExpired.Is(err) {
+			hs.log.Debug("Not redirecting short URL since not found or expired")
 			return
 		}
 
@@ -55,6 +144,11 @@ func (hs *HTTPServer) redirectFromShortURL(c *contextmodel.ReqContext) {
 		return
 	}
 
+	if err := hs.canViewShortURLTarget(c, shortURL); err != nil {
+		hs.log.Debug("Not redirecting short URL", "uid", shortURL.Uid, "error", err)
+		return
+	}
+
 	// Failure to update LastSeenAt should still allow to redirect
 	if err := hs.ShortURLService.UpdateLastSeenAt(c.Req.Context(), shortURL); err != nil {
 		hs.log.Error("Failed to update short URL last seen at", "error", err)
@@ -63,3 +157,52 @@ func (hs *HTTPServer) redirectFromShortURL(c *contextmodel.ReqContext) {
 	hs.log.Debug("Redirecting short URL", "path", shortURL.Path)
 	c.Redirect(setting.ToAbsUrl(shortURL.Path), 302)
 }
+
+// canViewShortURLTarget checks that the user can view the dashboard the short URL points to, if any.
+func (hs *HTTPServer) canViewShortURLTarget(c *contextmodel.ReqContext, shortURL *shorturls.ShortUrl) error {
+	dashboardUID := shortURLDashboardUID(shortURL.Path)
+	if dashboardUID == "" {
+		return nil
+	}
+
+	g, err := guardian.NewByUID(c.Req.Context(), dashboardUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		// the dashboard page handles missing dashboards
+		if guardian.ErrGuardianDashboardNotFound.Is(err) {
+			return nil
+		}
+		return err
+	}
+
+	canView, err := g.CanView()
+	if err != nil {
+		return err
+	}
+	if !canView {
+		return shorturls.ErrShortURLAccessDenied.Errorf("user cannot view dashboard %s", dashboardUID)
+	}
+	return nil
+}
+
+// shortURLDashboardUID returns the UID of the dashboard of a short URL path, or an empty string if the path is not a
+// dashboard path.
+func shortURLDashboardUID(path string) string {
+	u, err := url.Parse(path)
+	if err != nil {
+		return ""
+	}
+
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	switch parts[0] {
+	case "d", "d-solo":
+		return parts[1]
+	}
+	return ""
+}
+
+func shortURLLink(uid string, orgID int64) string {
+	return fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(setting.AppUrl, "/"), uid, orgID)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -13,9 +14,12 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/log"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
 )
 
 func TestShortURLAPIEndpoint(t *testing.T) {
@@ -31,7 +35,7 @@ func TestShortURLAPIEndpoint(t *testing.T) {
 			Path:  cmd.Path,
 		}
 		service := &fakeShortURLService{
-			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, path string) (*shorturls.ShortUrl, error) {
+			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
 				return createResp, nil
 			},
 		}
@@ -47,6 +51,109 @@ func TestShortURLAPIEndpoint(t *testing.T) {
 				require.Equal(t, fmt.Sprintf("/goto/%s?orgId=%d", createResp.Uid, createResp.OrgId), shortUrl.URL)
 			})
 	})
+
+	t.Run("Given a request for creating a shortUrl with a slug already used", func(t *testing.T) {
+		cmd := dtos.CreateShortURLCmd{Path: "d/TxKARsmGz/new-dashboard", UID: "ops-board"}
+		service := &fakeShortURLService{
+			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
+				return nil, shorturls.ErrShortURLExists.Errorf("short URL %s already exists", cmd.UID)
+			},
+		}
+
+		createShortURLScenario(t, "When calling POST on", "/api/short-urls", "/api/short-urls", cmd, service,
+			func(sc *scenarioContext) {
+				callCreateShortURL(sc)
+				require.Equal(t, http.StatusConflict, sc.resp.Code)
+			})
+	})
+}
+
+func TestShortURLSearchAndDeleteAPIEndpoints(t *testing.T) {
+	service := &fakeShortURLService{}
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.ShortURLService = service
+	})
+
+	viewer := &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleViewer}
+	admin := &user.SignedInUser{UserID: 3, OrgID: 1, OrgRole: org.RoleAdmin}
+
+	t.Run("Users can only search their own short URLs", func(t *testing.T) {
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/short-urls?createdBy=5&sort=clicks-desc"), viewer))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, int64(2), service.searchQueries[len(service.searchQueries)-1].CreatedBy)
+		require.Equal(t, "clicks-desc", service.searchQueries[len(service.searchQueries)-1].Sort)
+	})
+
+	t.Run("Admins can search the short URLs of any user", func(t *testing.T) {
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/short-urls?createdBy=5"), admin))
+		require.NoError(t, err)
+		defer func() { require.NoError(t, res.Body.Close()) }()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, int64(5), service.searchQueries[len(service.searchQueries)-1].CreatedBy)
+
+		result := shorturls.SearchShortURLsResult{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		require.Equal(t, "/goto/N1u6L4eGz?orgId=1", result.ShortURLs[0].URL)
+	})
+
+	t.Run("Users can only delete their own short URLs", func(t *testing.T) {
+		req := server.NewRequest(http.MethodDelete, "/api/short-urls/N1u6L4eGz", nil)
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, viewer))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, &shorturls.DeleteShortURLByUIDCommand{OrgID: 1, UID: "N1u6L4eGz", CreatedBy: 2}, service.deleteCommands[0])
+	})
+}
+
+func TestShortURLRedirect(t *testing.T) {
+	service := &fakeShortURLService{
+		getShortURLFunc: func(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error) {
+			return &shorturls.ShortUrl{Id: 1, OrgId: testOrgID, Uid: uid, Path: "d/TxKARsmGz/new-dashboard?orgId=1"}, nil
+		},
+	}
+	hs := HTTPServer{
+		Cfg:             setting.NewCfg(),
+		ShortURLService: service,
+		log:             log.New("test"),
+	}
+
+	redirect := func(t *testing.T) *scenarioContext {
+		sc := setupScenarioContext(t, "/goto/N1u6L4eGz")
+		sc.m.Get("/goto/:uid", routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			c.SignedInUser = &user.SignedInUser{OrgID: testOrgID, UserID: testUserID}
+			hs.redirectFromShortURL(c)
+			return nil
+		}))
+		sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+		return sc
+	}
+
+	t.Run("Redirects when the user can view the dashboard", func(t *testing.T) {
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: true})
+
+		sc := redirect(t)
+		require.Equal(t, http.StatusFound, sc.resp.Code)
+		require.Contains(t, sc.resp.Header().Get("Location"), "d/TxKARsmGz/new-dashboard?orgId=1")
+		require.Equal(t, 1, service.lastSeenUpdates)
+	})
+
+	t.Run("Does not redirect when the user cannot view the dashboard", func(t *testing.T) {
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanViewValue: false})
+
+		sc := redirect(t)
+		require.NotEqual(t, http.StatusFound, sc.resp.Code)
+		require.Equal(t, 1, service.lastSeenUpdates)
+	})
+}
+
+func TestShortURLDashboardUID(t *testing.T) {
+	require.Equal(t, "TxKARsmGz", shortURLDashboardUID("d/TxKARsmGz/new-dashboard?orgId=1"))
+	require.Equal(t, "TxKARsmGz", shortURLDashboardUID("d-solo/TxKARsmGz/new-dashboard?panelId=2"))
+	require.Equal(t, "", shortURLDashboardUID("explore?left=%7B%7D"))
+	require.Equal(t, "", shortURLDashboardUID("d"))
 }
 
 func callCreateShortURL(sc *scenarioContext) {
@@ -78,22 +185,45 @@ func createShortURLScenario(t *testing.T, desc string, url string, routePattern
 }
 
 type fakeShortURLService struct {
-	createShortURLFunc func(ctx context.Context, user *user.SignedInUser, path string) (*shorturls.ShortUrl, error)
+	createShortURLFunc func(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error)
+	getShortURLFunc    func(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error)
+	searchQueries      []*shorturls.SearchShortURLsQuery
+	deleteCommands     []*shorturls.DeleteShortURLByUIDCommand
+	lastSeenUpdates    int
 }
 
 func (s *fakeShortURLService) GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error) {
+	if s.getShortURLFunc != nil {
+		return s.getShortURLFunc(ctx, user, uid)
+	}
+
 	return nil, nil
 }
 
-func (s *fakeShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, path string) (*shorturls.ShortUrl, error) {
+func (s *fakeShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
 	if s.createShortURLFunc != nil {
-		return s.createShortURLFunc(ctx, user, path)
+		return s.createShortURLFunc(ctx, user, cmd)
 	}
 
 	return nil, nil
 }
 
 func (s *fakeShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *shorturls.ShortUrl) error {
+	s.lastSeenUpdates++
+	return nil
+}
+
+func (s *fakeShortURLService) SearchShortURLs(ctx context.Context, query *shorturls.SearchShortURLsQuery) (*shorturls.SearchShortURLsResult, error) {
+	s.searchQueries = append(s.searchQueries, query)
+	return &shorturls.SearchShortURLsResult{ShortURLs: []*shorturls.ShortURLDTO{{UID: "N1u6L4eGz"}}}, nil
+}
+
+func (s *fakeShortURLService) DeleteShortURL(ctx context.Context, cmd *shorturls.DeleteShortURLByUIDCommand) error {
+	s.deleteCommands = append(s.deleteCommands, cmd)
+	return nil
+}
+
+func (s *fakeShortURLService) DeleteShortURLs(ctx context.Context, cmd *shorturls.DeleteShortURLsCommand) error {
 	return nil
 }
 
@@ -0,0 +1,32 @@
+package v0alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const GroupName = "dashboard.grafana.app"
+
+// SchemeGroupVersion is group version used to register these objects
+var SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: "v0alpha1"}
+
+// Resource takes an unqualified resource and returns back a Group qualified GroupResource
+func Resource(resource string) schema.GroupResource {
+	return SchemeGroupVersion.WithResource(resource).GroupResource()
+}
+
+// AddToScheme registers the types in the group version, and in the internal version used by the apiserver
+func AddToScheme(scheme *runtime.Scheme) error {
+	addKnownTypes(scheme, SchemeGroupVersion)
+	addKnownTypes(scheme, schema.GroupVersion{Group: GroupName, Version: runtime.APIVersionInternal})
+	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
+	return scheme.SetVersionPriority(SchemeGroupVersion)
+}
+
+func addKnownTypes(scheme *runtime.Scheme, gv schema.GroupVersion) {
+	scheme.AddKnownTypes(gv,
+		&Dashboard{},
+		&DashboardList{},
+	)
+}
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/datamigrations"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// copyDatabaseCommand copies the configured database to the database of the target URL, after running the
+// migrations on it.
+func copyDatabaseCommand(c utils.CommandLine, runner server.Runner) error {
+	targetURL := c.String("target-url")
+	if targetURL == "" {
+		return errors.New("the target database URL is required")
+	}
+	u, err := url.Parse(targetURL)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "invalid target database URL", err)
+	}
+	if u.Scheme != migrator.Postgres && u.Scheme != migrator.MySQL {
+		return fmt.Errorf("unsupported target database type %q, expected %s or %s", u.Scheme, migrator.Postgres, migrator.MySQL)
+	}
+
+	source, ok := runner.SQLStore.(*sqlstore.SQLStore)
+	if !ok {
+		return errors.New("the source database does not support copying")
+	}
+
+	targetCfg, err := targetDatabaseConfig(runner.Cfg, targetURL)
+	if err != nil {
+		return err
+	}
+	tracer, err := tracing.ProvideService(targetCfg)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Running the migrations on the %s database\n", u.Scheme)
+	target, err := sqlstore.ProvideServiceWithoutDefaults(targetCfg, migrations.ProvideOSSMigrations(), tracer)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "failed to prepare the target database", err)
+	}
+
+	ctx := context.Background()
+	opts := datamigrations.CopyDatabaseOptions{
+		BatchSize: c.Int("batch-size"),
+		Resume:    c.Bool("resume"),
+	}
+	if err := datamigrations.CopyDatabase(ctx, source.GetEngine(), target.GetEngine(), opts); err != nil {
+		return fmt.Errorf("%v: %w", "failed to copy the database, run the command again with --resume to continue", err)
+	}
+
+	if c.Bool("skip-verify") {
+		logger.Infof("%s Copied the database without verifying it\n", color.GreenString("✔"))
+		return nil
+	}
+	return datamigrations.VerifyDatabaseCopy(ctx, source.GetEngine(), target.GetEngine())
+}
+
+// targetDatabaseConfig returns a copy of the configuration where the database section only holds the URL of the
+// target database.
+func targetDatabaseConfig(cfg *setting.Cfg, targetURL string) (*setting.Cfg, error) {
+	raw := ini.Empty()
+	for _, sec := range cfg.Raw.Sections() {
+		if sec.Name() == "database" {
+			continue
+		}
+		targetSec, err := raw.NewSection(sec.Name())
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range sec.Keys() {
+			if _, err := targetSec.NewKey(key.Name(), key.Value()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sec, err := raw.NewSection("database")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := sec.NewKey("url", targetURL); err != nil {
+		return nil, err
+	}
+
+	targetCfg := *cfg
+	targetCfg.Raw = raw
+	return &targetCfg, nil
+}
@@ -0,0 +1,415 @@
+package datamigrations
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"xorm.io/core"
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+const defaultCopyBatchSize = 1000
+
+// CopyDatabaseOptions configures how CopyDatabase copies the rows.
+type CopyDatabaseOptions struct {
+	// BatchSize is the number of rows written in a single transaction.
+	BatchSize int
+	// Resume continues an interrupted copy instead of clearing the target tables first. Tables that have been
+	// copied completely are skipped, tables with an integer primary key continue after the last copied row, and the
+	// other tables are copied again.
+	Resume bool
+}
+
+// copyTable describes a table that exists in both the source and the target database.
+type copyTable struct {
+	name string
+	// columns are the copied columns, in the order of the source table.
+	columns       []string
+	sourceColumns []*core.Column
+	targetColumns []*core.Column
+	// key is the integer primary key the rows are read by in batches, if the table has one.
+	key    string
+	keyIdx int
+	// orderBy are the columns the rows are sorted by when the table has no integer primary key.
+	orderBy []string
+	// autoIncrement is the auto increment column of the target table, if any.
+	autoIncrement string
+}
+
+type databaseCopier struct {
+	source        *xorm.Engine
+	target        *xorm.Engine
+	sourceDialect migrator.Dialect
+	targetDialect migrator.Dialect
+	batchSize     int
+}
+
+func newDatabaseCopier(source, target *xorm.Engine, batchSize int) *databaseCopier {
+	if batchSize <= 0 {
+		batchSize = defaultCopyBatchSize
+	}
+	return &databaseCopier{
+		source:        source,
+		target:        target,
+		sourceDialect: migrator.NewDialect(source.DriverName()),
+		targetDialect: migrator.NewDialect(target.DriverName()),
+		batchSize:     batchSize,
+	}
+}
+
+// CopyDatabase copies the rows of every table of the source database to the table with the same name in the target
+// database, which must already have the schema. The primary keys are kept and the sequences of the target database
+// are moved past them.
+func CopyDatabase(ctx context.Context, source, target *xorm.Engine, opts CopyDatabaseOptions) error {
+	c := newDatabaseCopier(source, target, opts.BatchSize)
+
+	tables, skipped, err := c.tables()
+	if err != nil {
+		return err
+	}
+	for _, name := range skipped {
+		logger.Warnf("Table %s does not exist in the target database and is not copied\n", name)
+	}
+
+	if !opts.Resume {
+		for _, t := range tables {
+			if _, err := c.target.Exec("DELETE FROM " + c.targetDialect.Quote(t.name)); err != nil {
+				return fmt.Errorf("failed to clear table %s in the target database: %w", t.name, err)
+			}
+		}
+	}
+
+	for _, t := range tables {
+		if err := c.copyTable(ctx, t, opts.Resume); err != nil {
+			return fmt.Errorf("failed to copy table %s: %w", t.name, err)
+		}
+	}
+	return nil
+}
+
+// VerifyDatabaseCopy compares the row count and the checksum of the rows of every table copied by CopyDatabase.
+func VerifyDatabaseCopy(ctx context.Context, source, target *xorm.Engine) error {
+	c := newDatabaseCopier(source, target, 0)
+
+	tables, _, err := c.tables()
+	if err != nil {
+		return err
+	}
+
+	var mismatched []string
+	for _, t := range tables {
+		sourceCount, sourceSum, err := tableChecksum(ctx, c.source, c.sourceDialect, t, t.sourceColumns)
+		if err != nil {
+			return fmt.Errorf("failed to compute the checksum of table %s in the source database: %w", t.name, err)
+		}
+		targetCount, targetSum, err := tableChecksum(ctx, c.target, c.targetDialect, t, t.targetColumns)
+		if err != nil {
+			return fmt.Errorf("failed to compute the checksum of table %s in the target database: %w", t.name, err)
+		}
+
+		switch {
+		case sourceCount != targetCount:
+			logger.Errorf("%s Table %s has %d rows in the source database and %d in the target database\n",
+				color.RedString("✗"), t.name, sourceCount, targetCount)
+			mismatched = append(mismatched, t.name)
+		case sourceSum != targetSum:
+			logger.Errorf("%s Table %s has different rows in the source and target databases\n", color.RedString("✗"), t.name)
+			mismatched = append(mismatched, t.name)
+		default:
+			logger.Debugf("Table %s matches (%d rows)\n", t.name, sourceCount)
+		}
+	}
+
+	if len(mismatched) > 0 {
+		return fmt.Errorf("%d tables differ between the source and target databases: %s", len(mismatched), strings.Join(mismatched, ", "))
+	}
+	logger.Infof("%s Verified %d tables\n", color.GreenString("✔"), len(tables))
+	return nil
+}
+
+// tables returns the tables of the source database sorted by name, and the names of the ones missing in the target
+// database.
+func (c *databaseCopier) tables() ([]*copyTable, []string, error) {
+	sourceTables, err := c.source.DBMetas()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the tables of the source database: %w", err)
+	}
+	targetTables, err := c.target.DBMetas()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the tables of the target database: %w", err)
+	}
+
+	byName := make(map[string]*core.Table, len(targetTables))
+	for _, t := range targetTables {
+		byName[t.Name] = t
+	}
+
+	sort.Slice(sourceTables, func(i, j int) bool {
+		return sourceTables[i].Name < sourceTables[j].Name
+	})
+
+	tables := make([]*copyTable, 0, len(sourceTables))
+	var skipped []string
+	for _, st := range sourceTables {
+		if st.Name == "" {
+			continue
+		}
+		tt, ok := byName[st.Name]
+		if !ok {
+			skipped = append(skipped, st.Name)
+			continue
+		}
+
+		t := &copyTable{name: st.Name, keyIdx: -1, autoIncrement: tt.AutoIncrement}
+		for _, col := range st.Columns() {
+			targetCol := tt.GetColumn(col.Name)
+			if targetCol == nil {
+				return nil, nil, fmt.Errorf("column %s.%s does not exist in the target database", st.Name, col.Name)
+			}
+			t.columns = append(t.columns, col.Name)
+			t.sourceColumns = append(t.sourceColumns, col)
+			t.targetColumns = append(t.targetColumns, targetCol)
+		}
+
+		t.orderBy = st.PrimaryKeys
+		if len(t.orderBy) == 0 {
+			t.orderBy = t.columns
+		}
+		if len(st.PrimaryKeys) == 1 {
+			if col := st.GetColumn(st.PrimaryKeys[0]); col != nil && col.SQLType.IsNumeric() {
+				t.key = col.Name
+				for i, name := range t.columns {
+					if name == col.Name {
+						t.keyIdx = i
+					}
+				}
+			}
+		}
+		tables = append(tables, t)
+	}
+	return tables, skipped, nil
+}
+
+func (c *databaseCopier) copyTable(ctx context.Context, t *copyTable, resume bool) error {
+	sourceCount, err := countRows(c.source, c.sourceDialect, t.name)
+	if err != nil {
+		return err
+	}
+
+	var after any
+	if resume {
+		targetCount, err := countRows(c.target, c.targetDialect, t.name)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case targetCount == sourceCount:
+			logger.Infof("%s Table %s was already copied (%d rows)\n", color.GreenString("✔"), t.name, sourceCount)
+			return nil
+		case targetCount > 0 && t.key != "":
+			var last int64
+			if _, err := c.target.SQL(fmt.Sprintf("SELECT MAX(%s) FROM %s", c.targetDialect.Quote(t.key), c.targetDialect.Quote(t.name))).Get(&last); err != nil {
+				return err
+			}
+			after = last
+		case targetCount > 0:
+			if _, err := c.target.Exec("DELETE FROM " + c.targetDialect.Quote(t.name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	var copied, offset int64
+	for {
+		rows, err := c.readBatch(ctx, t, after, offset)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		if err := c.writeBatch(t, rows); err != nil {
+			return err
+		}
+
+		copied += int64(len(rows))
+		offset += int64(len(rows))
+		if t.key != "" {
+			after = rows[len(rows)-1][t.keyIdx]
+		}
+		if len(rows) < c.batchSize {
+			break
+		}
+	}
+
+	if t.autoIncrement != "" {
+		if sql := c.targetDialect.ResetSequenceSQL(t.name, t.autoIncrement); sql != "" {
+			if _, err := c.target.Exec(sql); err != nil {
+				return fmt.Errorf("failed to reset the sequence of column %s: %w", t.autoIncrement, err)
+			}
+		}
+	}
+
+	logger.Infof("%s Copied %d rows of table %s\n", color.GreenString("✔"), copied, t.name)
+	return nil
+}
+
+// readBatch reads the next rows of the source table, either after the given key or at the given offset.
+func (c *databaseCopier) readBatch(ctx context.Context, t *copyTable, after any, offset int64) ([][]any, error) {
+	var sql strings.Builder
+	var args []any
+	sql.WriteString("SELECT " + quoteColumns(c.sourceDialect, t.columns) + " FROM " + c.sourceDialect.Quote(t.name))
+	if t.key != "" {
+		if after != nil {
+			sql.WriteString(" WHERE " + c.sourceDialect.Quote(t.key) + " > ?")
+			args = append(args, after)
+		}
+		sql.WriteString(" ORDER BY " + c.sourceDialect.Quote(t.key) + c.sourceDialect.Limit(int64(c.batchSize)))
+	} else {
+		sql.WriteString(" ORDER BY " + quoteColumns(c.sourceDialect, t.orderBy) + c.sourceDialect.LimitOffset(int64(c.batchSize), offset))
+	}
+
+	rows, err := c.source.DB().QueryContext(ctx, sql.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var batch [][]any
+	for rows.Next() {
+		values := make([]any, len(t.columns))
+		pointers := make([]any, len(t.columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		batch = append(batch, values)
+	}
+	return batch, rows.Err()
+}
+
+// writeBatch inserts rows into the target table in a single transaction.
+func (c *databaseCopier) writeBatch(t *copyTable, rows [][]any) error {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(t.columns)), ", ")
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", c.targetDialect.Quote(t.name), quoteColumns(c.targetDialect, t.columns), placeholders)
+
+	sess := c.target.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		args := make([]any, 0, len(row)+1)
+		args = append(args, insert)
+		for i, value := range row {
+			args = append(args, c.targetDialect.ConvertValue(t.targetColumns[i], value))
+		}
+		if _, err := sess.Exec(args...); err != nil {
+			_ = sess.Rollback()
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+func countRows(engine *xorm.Engine, dialect migrator.Dialect, table string) (int64, error) {
+	var count int64
+	if _, err := engine.SQL("SELECT COUNT(*) FROM " + dialect.Quote(table)).Get(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// tableChecksum returns the number of rows of a table and a checksum of their values that doesn't depend on the
+// order of the rows or on how the database stores the values.
+func tableChecksum(ctx context.Context, engine *xorm.Engine, dialect migrator.Dialect, t *copyTable, columns []*core.Column) (int64, uint64, error) {
+	rows, err := engine.DB().QueryContext(ctx, "SELECT "+quoteColumns(dialect, t.columns)+" FROM "+dialect.Quote(t.name))
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var count int64
+	var sum uint64
+	values := make([]any, len(t.columns))
+	pointers := make([]any, len(t.columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return 0, 0, err
+		}
+		h := fnv.New64a()
+		for i, value := range values {
+			_, _ = h.Write([]byte(normalizeValue(columns[i], value)))
+			_, _ = h.Write([]byte{0})
+		}
+		count++
+		sum += h.Sum64()
+	}
+	return count, sum, rows.Err()
+}
+
+// normalizeValue returns the string representation of a value used for checksums, which is the same whatever the
+// database it was read from.
+func normalizeValue(col *core.Column, value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "\x00NULL"
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case []byte:
+		return normalizeString(col, string(v))
+	case string:
+		return normalizeString(col, v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func normalizeString(col *core.Column, value string) string {
+	if col.SQLType.IsTime() {
+		for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339Nano} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t.UTC().Format(time.RFC3339)
+			}
+		}
+	}
+	if col.SQLType.Name == core.Bool || col.SQLType.Name == core.Boolean {
+		if value == "1" || strings.EqualFold(value, "true") {
+			return "1"
+		}
+		return "0"
+	}
+	return value
+}
+
+func quoteColumns(dialect migrator.Dialect, columns []string) string {
+	quoted := make([]string, 0, len(columns))
+	for _, col := range columns {
+		quoted = append(quoted, dialect.Quote(col))
+	}
+	return strings.Join(quoted, ", ")
+}
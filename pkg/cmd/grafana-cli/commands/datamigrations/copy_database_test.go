@@ -0,0 +1,112 @@
+package datamigrations
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type cacheData struct {
+	CacheKey  string `xorm:"pk 'cache_key'"`
+	Data      []byte
+	Expires   int64
+	CreatedAt int64
+}
+
+func (cacheData) TableName() string {
+	return "cache_data"
+}
+
+func TestIntegrationCopyDatabase(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	source := db.InitTestDB(t).GetEngine()
+	now := time.Now().UTC().Truncate(time.Second)
+	for i := 0; i < 5; i++ {
+		_, err := source.Insert(&user.User{
+			Email:   fmt.Sprintf("user%d@example.com", i),
+			Login:   fmt.Sprintf("user%d", i),
+			OrgID:   1,
+			IsAdmin: i == 0,
+			Created: now,
+			Updated: now,
+		})
+		require.NoError(t, err)
+	}
+	_, err := source.Insert(&cacheData{CacheKey: "key", Data: []byte(`{"value":1}`), Expires: 60, CreatedAt: now.Unix()})
+	require.NoError(t, err)
+
+	target := newCopyTarget(t)
+
+	t.Run("copies every row and keeps the ids", func(t *testing.T) {
+		require.NoError(t, CopyDatabase(ctx, source, target, CopyDatabaseOptions{BatchSize: 2}))
+		require.NoError(t, VerifyDatabaseCopy(ctx, source, target))
+
+		var sourceUsers, targetUsers []user.User
+		require.NoError(t, source.OrderBy("id").Find(&sourceUsers))
+		require.NoError(t, target.OrderBy("id").Find(&targetUsers))
+		require.Len(t, targetUsers, len(sourceUsers))
+		for i := range sourceUsers {
+			assert.Equal(t, sourceUsers[i].ID, targetUsers[i].ID)
+			assert.Equal(t, sourceUsers[i].Login, targetUsers[i].Login)
+			assert.Equal(t, sourceUsers[i].IsAdmin, targetUsers[i].IsAdmin)
+		}
+
+		var cached cacheData
+		found, err := target.Where("cache_key = ?", "key").Get(&cached)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, `{"value":1}`, string(cached.Data))
+	})
+
+	t.Run("resumes an interrupted copy", func(t *testing.T) {
+		var first user.User
+		_, err := target.OrderBy("id").Get(&first)
+		require.NoError(t, err)
+		_, err = target.Exec("DELETE FROM "+migrator.NewDialect(target.DriverName()).Quote("user")+" WHERE id > ?", first.ID+1)
+		require.NoError(t, err)
+		_, err = target.Exec("DELETE FROM cache_data")
+		require.NoError(t, err)
+
+		require.NoError(t, CopyDatabase(ctx, source, target, CopyDatabaseOptions{BatchSize: 2, Resume: true}))
+		require.NoError(t, VerifyDatabaseCopy(ctx, source, target))
+	})
+
+	t.Run("fails verification when rows differ", func(t *testing.T) {
+		_, err := target.Exec("UPDATE "+migrator.NewDialect(target.DriverName()).Quote("user")+" SET login = ? WHERE login = ?", "changed", "user1")
+		require.NoError(t, err)
+
+		err = VerifyDatabaseCopy(ctx, source, target)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "user")
+	})
+}
+
+func newCopyTarget(t *testing.T) *xorm.Engine {
+	t.Helper()
+
+	engine, err := xorm.NewEngine(migrator.SQLite, "file:"+filepath.Join(t.TempDir(), "target.db")+"?cache=private&mode=rwc")
+	require.NoError(t, err)
+	engine.SetLogger(&xorm.DiscardLogger{})
+	t.Cleanup(func() { _ = engine.Close() })
+
+	mg := migrator.NewMigrator(engine, setting.NewCfg())
+	migrations.ProvideOSSMigrations().AddMigration(mg)
+	require.NoError(t, mg.Start(false, 0))
+	return engine
+}
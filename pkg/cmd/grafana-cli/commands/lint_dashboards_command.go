@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+)
+
+var (
+	errMissingDashboardPath = errors.New("missing path of dashboard files")
+	errDashboardLintFailed  = errors.New("dashboards have problems of rules in block mode")
+)
+
+// lintedFile is the lint result of a dashboard file.
+type lintedFile struct {
+	Path     string                  `json:"path"`
+	Problems []dashboardlint.Problem `json:"problems"`
+}
+
+// lintDashboardsCommand lints dashboard files with the built-in rules, without a Grafana server, so that dashboards
+// can be checked in CI before they are provisioned.
+func lintDashboardsCommand(c utils.CommandLine) error {
+	paths := c.Args().Slice()
+	if len(paths) == 0 {
+		return errMissingDashboardPath
+	}
+
+	linter := dashboardlint.NewLinter(dashboardlint.DefaultRules()...)
+	settings := dashboardlint.Settings{}
+	if path := c.String("settings"); path != "" {
+		// nolint:gosec
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("%v: %w", "failed to read lint settings", err)
+		}
+		if err := json.Unmarshal(b, &settings); err != nil {
+			return fmt.Errorf("%v: %w", "failed to parse lint settings", err)
+		}
+	}
+	if c.Bool("warnings-as-errors") {
+		settings = blockWarnings(linter, settings)
+	}
+
+	linted, err := lintDashboardFiles(linter, settings, paths)
+	if err != nil {
+		return err
+	}
+
+	blocked := false
+	for _, f := range linted {
+		for _, p := range f.Problems {
+			blocked = blocked || p.Severity == dashboardlint.SeverityError
+		}
+	}
+
+	if c.Bool("json") {
+		b, err := json.MarshalIndent(linted, "", "  ")
+		if err != nil {
+			return err
+		}
+		logger.Info(string(b) + "\n")
+	} else {
+		printLintedFiles(linted)
+	}
+
+	if blocked {
+		return errDashboardLintFailed
+	}
+	return nil
+}
+
+// lintDashboardFiles lints the dashboard files, and the JSON files of the directories. The files can contain the
+// dashboard JSON, or an object with the dashboard in the dashboard field as returned by the dashboard API.
+func lintDashboardFiles(linter *dashboardlint.Linter, settings dashboardlint.Settings, paths []string) ([]lintedFile, error) {
+	if err := linter.Validate(settings); err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && (p == path || strings.EqualFold(filepath.Ext(p), ".json")) {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	linted := make([]lintedFile, 0, len(files))
+	for _, file := range files {
+		// nolint:gosec
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		data, err := simplejson.NewJson(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		if dashboard, ok := data.CheckGet("dashboard"); ok && len(dashboard.MustMap()) > 0 {
+			data = dashboard
+		}
+
+		result := linter.Lint(data, settings)
+		linted = append(linted, lintedFile{Path: file, Problems: result.Problems})
+	}
+	return linted, nil
+}
+
+// blockWarnings returns the settings with the rules in warn mode in block mode.
+func blockWarnings(linter *dashboardlint.Linter, settings dashboardlint.Settings) dashboardlint.Settings {
+	rules := make(map[string]dashboardlint.RuleSettings, len(settings.Rules))
+	for _, rule := range linter.Rules() {
+		rs := settings.Rules[rule.Name()]
+		if linter.Mode(rule, settings) == dashboardlint.ModeWarn {
+			rs.Mode = dashboardlint.ModeBlock
+		}
+		rules[rule.Name()] = rs
+	}
+	return dashboardlint.Settings{Rules: rules}
+}
+
+func printLintedFiles(linted []lintedFile) {
+	total := 0
+	for _, f := range linted {
+		if len(f.Problems) == 0 {
+			logger.Infof("%s %s\n", color.GreenString("✔"), f.Path)
+			continue
+		}
+		logger.Infof("%s %s\n", color.RedString("✘"), f.Path)
+		for _, p := range f.Problems {
+			severity := color.YellowString(string(p.Severity))
+			if p.Severity == dashboardlint.SeverityError {
+				severity = color.RedString(string(p.Severity))
+			}
+			logger.Infof("    %s %s (%s)\n", severity, p.Message, p.Rule)
+		}
+		total += len(f.Problems)
+	}
+	logger.Infof("\n%d files, %d problems\n", len(linted), total)
+}
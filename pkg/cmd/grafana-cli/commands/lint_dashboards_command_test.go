@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+)
+
+func TestLintDashboardFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"valid.json":     `{"title": "Valid", "panels": [{"id": 1, "type": "stat", "title": "Stat"}]}`,
+		"exported.json":  `{"meta": {}, "dashboard": {"title": "Exported", "panels": [{"id": 1, "type": "stat"}]}}`,
+		"readme.md":      `not a dashboard`,
+		"nested/x.json":  `{"title": "Nested", "panels": [{"id": 1, "type": "singlestat", "title": "Old"}]}`,
+		"nested/y.JSON":  `{"title": "Upper case extension"}`,
+		"nested/ignored": `{}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+
+	linter := dashboardlint.NewLinter(dashboardlint.DefaultRules()...)
+
+	t.Run("Should lint the JSON files of directories", func(t *testing.T) {
+		linted, err := lintDashboardFiles(linter, dashboardlint.Settings{}, []string{dir})
+		require.NoError(t, err)
+		require.Len(t, linted, 4)
+
+		problems := map[string][]string{}
+		for _, f := range linted {
+			rel, err := filepath.Rel(dir, f.Path)
+			require.NoError(t, err)
+			for _, p := range f.Problems {
+				problems[rel] = append(problems[rel], p.Rule)
+			}
+		}
+		require.Equal(t, map[string][]string{
+			"exported.json":                   {"panel-title"},
+			filepath.Join("nested", "x.json"): {"deprecated-panel"},
+		}, problems)
+	})
+
+	t.Run("Should block the warnings as errors", func(t *testing.T) {
+		settings := blockWarnings(linter, dashboardlint.Settings{Rules: map[string]dashboardlint.RuleSettings{
+			"deprecated-panel": {Mode: dashboardlint.ModeOff},
+		}})
+		linted, err := lintDashboardFiles(linter, settings, []string{filepath.Join(dir, "nested", "x.json"), filepath.Join(dir, "exported.json")})
+		require.NoError(t, err)
+		require.Empty(t, linted[0].Problems)
+		require.Equal(t, dashboardlint.SeverityError, linted[1].Problems[0].Severity)
+	})
+
+	t.Run("Should fail with invalid settings", func(t *testing.T) {
+		_, err := lintDashboardFiles(linter, dashboardlint.Settings{Rules: map[string]dashboardlint.RuleSettings{"unknown": {}}}, []string{dir})
+		require.ErrorIs(t, err, dashboardlint.ErrUnknownRule)
+	})
+}
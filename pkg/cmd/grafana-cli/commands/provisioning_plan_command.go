@@ -0,0 +1,180 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/infra/usagestats"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	dsservice "github.com/grafana/grafana/pkg/services/datasources/service"
+	encryptionprovider "github.com/grafana/grafana/pkg/services/encryption/provider"
+	encryptionservice "github.com/grafana/grafana/pkg/services/encryption/service"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/kmsproviders/osskmsproviders"
+	"github.com/grafana/grafana/pkg/services/org/orgimpl"
+	provdatasources "github.com/grafana/grafana/pkg/services/provisioning/datasources"
+	"github.com/grafana/grafana/pkg/services/quota/quotaimpl"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	secretsdatabase "github.com/grafana/grafana/pkg/services/secrets/database"
+	"github.com/grafana/grafana/pkg/services/secrets/kvstore"
+	secretsmanager "github.com/grafana/grafana/pkg/services/secrets/manager"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var errProvisioningPlanHasChanges = errors.New("datasource provisioning would change the database")
+
+// runPlanCommand runs a command with the configured database and its secrets. Like runMigratorCommand, it doesn't
+// initialize the services, which would run the migrations, so that planning never changes the database.
+func runPlanCommand(command func(commandLine utils.CommandLine, cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, secretsService secrets.Service) error) func(context *cli.Context) error {
+	return func(context *cli.Context) error {
+		cmd := &utils.ContextCommandLine{Context: context}
+		// keep the output free of the server logs, so it can be reviewed or parsed
+		cfg, err := initializeConfig(cmd, "cfg:log.level=error")
+		if err != nil {
+			return fmt.Errorf("%v: %w", "failed to load configuration", err)
+		}
+
+		tracer, err := tracing.ProvideService(cfg)
+		if err != nil {
+			return fmt.Errorf("%v: %w", "failed to initialize tracer service", err)
+		}
+		sqlStore, err := sqlstore.ProvideServiceWithoutMigrations(cfg, migrations.ProvideOSSMigrations(), tracer)
+		if err != nil {
+			return err
+		}
+
+		features, err := featuremgmt.ProvideManagerService(cfg, nil)
+		if err != nil {
+			return fmt.Errorf("%v: %w", "failed to get feature management service", err)
+		}
+		usageStats := &usagestats.UsageStatsMock{}
+		enc, err := encryptionservice.ProvideEncryptionService(encryptionprovider.ProvideEncryptionProvider(), usageStats, cfg)
+		if err != nil {
+			return fmt.Errorf("%v: %w", "failed to initialize encryption service", err)
+		}
+		secretsService, err := secretsmanager.ProvideSecretsService(secretsdatabase.ProvideSecretsStore(sqlStore),
+			osskmsproviders.ProvideService(enc, cfg, features), enc, cfg, features, usageStats)
+		if err != nil {
+			return fmt.Errorf("%v: %w", "failed to initialize secrets service", err)
+		}
+
+		return command(cmd, cfg, sqlStore, secretsService)
+	}
+}
+
+func planDatasourcesCommand(c utils.CommandLine, cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, secretsService secrets.Service) error {
+	ctx := context.Background()
+
+	quotaService := quotaimpl.ProvideService(sqlStore, cfg)
+	orgService, err := orgimpl.ProvideService(sqlStore, cfg, quotaService)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "failed to initialize org service", err)
+	}
+
+	store := dsservice.CreateStore(sqlStore, log.New("datasources"))
+	secretsReader := &datasourceSecretsReader{
+		store: kvstore.NewSQLSecretsKVStore(sqlStore, secretsService, log.New("secrets.kvstore")),
+	}
+
+	path := filepath.Join(cfg.ProvisioningPath, "datasources")
+	plan, err := provdatasources.BuildPlan(ctx, path, store, secretsReader, orgService)
+	if err != nil {
+		return fmt.Errorf("%v: %w", "failed to plan datasource provisioning", err)
+	}
+
+	if c.Bool("json") {
+		b, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		logger.Info(string(b) + "\n")
+	} else {
+		printDatasourcesPlan(plan)
+	}
+
+	if c.Bool("fail-on-changes") && plan.HasChanges() {
+		return errProvisioningPlanHasChanges
+	}
+	return nil
+}
+
+func printDatasourcesPlan(plan *provdatasources.Plan) {
+	if !plan.HasChanges() {
+		logger.Infof("%s Datasources match the provisioning files (%d unchanged)\n", color.GreenString("✔"), plan.Unchanged)
+		return
+	}
+
+	for _, change := range plan.Changes {
+		switch change.Type {
+		case provdatasources.ChangeTypeAdd:
+			logger.Infof("%s %s (org %d, uid %s)\n", color.GreenString("+"), change.Name, change.OrgID, change.UID)
+		case provdatasources.ChangeTypeDelete:
+			logger.Infof("%s %s (org %d, uid %s, version %d)\n", color.RedString("-"), change.Name, change.OrgID, change.UID, change.Version)
+		case provdatasources.ChangeTypeUpdate:
+			logger.Infof("%s %s (org %d, uid %s, version %d)\n", color.YellowString("~"), change.Name, change.OrgID, change.UID, change.Version)
+			for _, d := range change.Diff {
+				logger.Infof("    %s: %v -> %v\n", d.Field, formatPlanValue(d.Current), formatPlanValue(d.Desired))
+			}
+		}
+	}
+
+	logger.Infof("\n%d to add, %d to update, %d to delete, %d unchanged\n",
+		plan.Count(provdatasources.ChangeTypeAdd), plan.Count(provdatasources.ChangeTypeUpdate),
+		plan.Count(provdatasources.ChangeTypeDelete), plan.Unchanged)
+}
+
+func formatPlanValue(v any) string {
+	switch val := v.(type) {
+	case []string:
+		return "[" + strings.Join(val, ", ") + "]"
+	case map[string]any:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val)
+		}
+		return string(b)
+	case string:
+		return fmt.Sprintf("%q", val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// datasourceSecretsReader reads data source secrets the same way the data source
+// service does, without the dependencies needed to run the full service.
+type datasourceSecretsReader struct {
+	store kvstore.SecretsKVStore
+}
+
+func (r *datasourceSecretsReader) DecryptedValues(ctx context.Context, ds *datasources.DataSource) (map[string]string, error) {
+	values := map[string]string{}
+	secret, exist, err := r.store.Get(ctx, ds.OrgID, ds.Name, kvstore.DataSourceSecretType)
+	if err != nil {
+		return nil, err
+	}
+
+	if exist {
+		if err := json.Unmarshal([]byte(secret), &values); err == nil {
+			return values, nil
+		}
+	}
+
+	// Only the keys are needed for the plan, so legacy secrets are not decrypted.
+	for k := range ds.SecureJsonData {
+		values[k] = ""
+	}
+	return values, nil
+}
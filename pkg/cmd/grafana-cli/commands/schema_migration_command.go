@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+var errSchemaDrift = errors.New("the database schema differs from the schema the migrations create")
+
+// runMigratorCommand runs a command with a migrator for the configured database. Unlike the other admin commands,
+// it doesn't initialize the services, which would run the migrations.
+func runMigratorCommand(command func(commandLine utils.CommandLine, mg *migrator.Migrator) error) func(context *cli.Context) error {
+	return func(context *cli.Context) error {
+		cmd := &utils.ContextCommandLine{Context: context}
+		// keep the output free of the server logs, so it can be reviewed or parsed
+		cfg, err := initializeConfig(cmd, "cfg:log.level=error")
+		if err != nil {
+			return fmt.Errorf("%v: %w", "failed to load configuration", err)
+		}
+
+		tracer, err := tracing.ProvideService(cfg)
+		if err != nil {
+			return fmt.Errorf("%v: %w", "failed to initialize tracer service", err)
+		}
+		ossMigrations := migrations.ProvideOSSMigrations()
+		store, err := sqlstore.ProvideServiceWithoutMigrations(cfg, ossMigrations, tracer)
+		if err != nil {
+			return err
+		}
+
+		mg := migrator.NewMigrator(store.GetEngine(), cfg)
+		ossMigrations.AddMigration(mg)
+		return command(cmd, mg)
+	}
+}
+
+func planSchemaMigrationsCommand(c utils.CommandLine, mg *migrator.Migrator) error {
+	plan, err := mg.Plan()
+	if err != nil {
+		return fmt.Errorf("%v: %w", "failed to plan the migrations", err)
+	}
+
+	if c.Bool("json") {
+		b, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		logger.Info(string(b) + "\n")
+		return nil
+	}
+
+	if len(plan) == 0 {
+		logger.Infof("%s No pending migrations for the %s database\n", color.GreenString("✔"), mg.Dialect.DriverName())
+		return nil
+	}
+
+	logger.Infof("-- %d pending migrations for the %s database\n", len(plan), mg.Dialect.DriverName())
+	for _, m := range plan {
+		logger.Infof("\n-- Migration: %s\n", m.ID)
+		if m.Condition != "" {
+			logger.Infof("-- Condition: %s %v\n", m.Condition, m.ConditionArgs)
+		}
+		if m.Code {
+			logger.Info("-- Code migration, the statements it runs depend on the data\n")
+			continue
+		}
+		logger.Info(strings.TrimSpace(m.SQL) + "\n")
+	}
+	return nil
+}
+
+func verifySchemaCommand(c utils.CommandLine, mg *migrator.Migrator) error {
+	drift, err := mg.VerifySchema()
+	if err != nil {
+		return fmt.Errorf("%v: %w", "failed to verify the schema", err)
+	}
+
+	if c.Bool("json") {
+		b, err := json.MarshalIndent(drift, "", "  ")
+		if err != nil {
+			return err
+		}
+		logger.Info(string(b) + "\n")
+	} else if len(drift) == 0 {
+		logger.Infof("%s The database schema matches the migration log\n", color.GreenString("✔"))
+	} else {
+		for _, d := range drift {
+			logger.Infof("%s %s\n", color.RedString("✗"), d)
+		}
+	}
+
+	if len(drift) > 0 {
+		return errSchemaDrift
+	}
+	return nil
+}
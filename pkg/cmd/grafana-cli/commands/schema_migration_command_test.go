@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/commandstest"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSchemaMigrationCommands(t *testing.T) {
+	engine, err := xorm.NewEngine(migrator.SQLite, filepath.Join(t.TempDir(), "grafana.db"))
+	require.NoError(t, err)
+	engine.SetLogger(&xorm.DiscardLogger{})
+
+	mg := migrator.NewMigrator(engine, setting.NewCfg())
+	migrations.ProvideOSSMigrations().AddMigration(mg)
+
+	c, err := commandstest.NewCliContext(map[string]string{})
+	require.NoError(t, err)
+	jsonContext, err := commandstest.NewCliContext(map[string]string{"json": "true"})
+	require.NoError(t, err)
+
+	require.NoError(t, planSchemaMigrationsCommand(c, mg))
+	require.NoError(t, planSchemaMigrationsCommand(jsonContext, mg))
+	exists, err := engine.IsTableExist("migration_log")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.NoError(t, mg.Start(false, 0))
+	require.NoError(t, verifySchemaCommand(c, mg))
+
+	_, err = engine.Exec("CREATE TABLE not_migrated (id INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+	require.ErrorIs(t, verifySchemaCommand(c, mg), errSchemaDrift)
+	require.ErrorIs(t, verifySchemaCommand(jsonContext, mg), errSchemaDrift)
+}
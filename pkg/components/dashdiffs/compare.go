@@ -23,6 +23,8 @@ const (
 	DiffJSON DiffType = iota
 	DiffBasic
 	DiffDelta
+	// DiffStructural reports the changes of the panels and queries, see CalculateStructuralDiff.
+	DiffStructural
 )
 
 type Options struct {
@@ -50,6 +52,8 @@ func ParseDiffType(diff string) DiffType {
 		return DiffBasic
 	case "delta":
 		return DiffDelta
+	case "structural":
+		return DiffStructural
 	}
 	return DiffBasic
 }
@@ -80,6 +84,16 @@ func CalculateDiff(ctx context.Context, options *Options, baseData, newData *sim
 		}
 		result.Delta = []byte(jsonOutput)
 
+	case DiffStructural:
+		structuralDiff, err := CalculateStructuralDiff(baseData, newData)
+		if err != nil {
+			return nil, err
+		}
+		result.Delta, err = json.Marshal(structuralDiff)
+		if err != nil {
+			return nil, err
+		}
+
 	case DiffBasic:
 		basicOutput, err := NewBasicFormatter(left).Format(jsonDiff)
 		if err != nil {
@@ -0,0 +1,338 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// StructuralChange is the change of a panel or of a query.
+type StructuralChange string
+
+const (
+	StructuralAdded    StructuralChange = "added"
+	StructuralRemoved  StructuralChange = "removed"
+	StructuralModified StructuralChange = "modified"
+)
+
+// StructuralDiff is the diff of two dashboards at the level of their panels and queries.
+type StructuralDiff struct {
+	// Dashboard lists the changed fields of the dashboard other than its panels, such as title or templating.
+	Dashboard []string `json:"dashboard"`
+	// Panels lists the added, removed and modified panels, in the order of the new dashboard followed by the
+	// removed panels.
+	Panels []PanelDiff `json:"panels"`
+}
+
+type PanelDiff struct {
+	ID     int64            `json:"id"`
+	Title  string           `json:"title"`
+	Type   string           `json:"type"`
+	Change StructuralChange `json:"change"`
+	// Moved is true when the position, the size or the row of the panel changed.
+	Moved bool `json:"moved,omitempty"`
+	// Fields lists the changed fields of the panel other than its position and queries, such as options.
+	Fields  []string    `json:"fields,omitempty"`
+	Queries []QueryDiff `json:"queries,omitempty"`
+}
+
+type QueryDiff struct {
+	RefID  string           `json:"refId"`
+	Change StructuralChange `json:"change"`
+}
+
+// panel is a panel of a dashboard, with the panels of collapsed rows flattened.
+type panel struct {
+	id   int64
+	row  int64
+	data map[string]any
+}
+
+func (p panel) title() string {
+	title, _ := p.data["title"].(string)
+	return title
+}
+
+func (p panel) panelType() string {
+	panelType, _ := p.data["type"].(string)
+	return panelType
+}
+
+// ignoredPanelFields are the fields compared separately from the other fields of the panels
+var ignoredPanelFields = map[string]bool{"id": true, "gridPos": true, "targets": true, "panels": true}
+
+// ignoredDashboardFields are the fields that change with every version
+var ignoredDashboardFields = map[string]bool{"id": true, "version": true, "panels": true}
+
+// CalculateStructuralDiff returns the panels added, removed, moved or modified between two dashboards, and the
+// queries changed in each panel.
+func CalculateStructuralDiff(baseData, newData *simplejson.Json) (*StructuralDiff, error) {
+	base, err := toMap(baseData)
+	if err != nil {
+		return nil, err
+	}
+	next, err := toMap(newData)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &StructuralDiff{
+		Dashboard: changedFields(base, next, ignoredDashboardFields),
+		Panels:    make([]PanelDiff, 0),
+	}
+
+	basePanels := panelsOf(base)
+	baseByID := make(map[int64]panel, len(basePanels))
+	for _, p := range basePanels {
+		baseByID[p.id] = p
+	}
+	nextIDs := make(map[int64]bool)
+
+	for _, p := range panelsOf(next) {
+		nextIDs[p.id] = true
+		old, ok := baseByID[p.id]
+		if !ok {
+			result.Panels = append(result.Panels, PanelDiff{ID: p.id, Title: p.title(), Type: p.panelType(), Change: StructuralAdded})
+			continue
+		}
+
+		d := PanelDiff{
+			ID:      p.id,
+			Title:   p.title(),
+			Type:    p.panelType(),
+			Change:  StructuralModified,
+			Moved:   old.row != p.row || !reflect.DeepEqual(old.data["gridPos"], p.data["gridPos"]),
+			Fields:  changedFields(old.data, p.data, ignoredPanelFields),
+			Queries: queryDiffs(old.data["targets"], p.data["targets"]),
+		}
+		if d.Moved || len(d.Fields) > 0 || len(d.Queries) > 0 {
+			result.Panels = append(result.Panels, d)
+		}
+	}
+
+	for _, p := range basePanels {
+		if !nextIDs[p.id] {
+			result.Panels = append(result.Panels, PanelDiff{ID: p.id, Title: p.title(), Type: p.panelType(), Change: StructuralRemoved})
+		}
+	}
+
+	return result, nil
+}
+
+// BlameVersion is a version of a dashboard, for computing the blame of its panels.
+type BlameVersion struct {
+	Version   int
+	Created   time.Time
+	CreatedBy int64
+	Message   string
+	Data      *simplejson.Json
+}
+
+// PanelBlame attributes the current state of a panel to the version that last changed it.
+type PanelBlame struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	Type      string    `json:"type"`
+	Version   int       `json:"version"`
+	Created   time.Time `json:"created"`
+	CreatedBy int64     `json:"createdBy"`
+	Message   string    `json:"message"`
+}
+
+// Blame returns, for each panel of the most recent version, the version that last changed the panel, including
+// its position. Panels that didn't change since the oldest version are attributed to the oldest version.
+func Blame(versions []BlameVersion) ([]PanelBlame, error) {
+	sorted := make([]BlameVersion, len(versions))
+	copy(sorted, versions)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	type state struct {
+		fingerprint string
+		version     BlameVersion
+	}
+	states := make(map[int64]state)
+	var latest []panel
+
+	for _, version := range sorted {
+		data, err := toMap(version.Data)
+		if err != nil {
+			return nil, err
+		}
+
+		latest = panelsOf(data)
+		current := make(map[int64]state, len(latest))
+		for _, p := range latest {
+			fingerprint, err := p.fingerprint()
+			if err != nil {
+				return nil, err
+			}
+			if previous, ok := states[p.id]; ok && previous.fingerprint == fingerprint {
+				current[p.id] = previous
+				continue
+			}
+			current[p.id] = state{fingerprint: fingerprint, version: version}
+		}
+		// removed panels are attributed to the version adding them again
+		states = current
+	}
+
+	result := make([]PanelBlame, 0, len(latest))
+	for _, p := range latest {
+		v := states[p.id].version
+		result = append(result, PanelBlame{
+			ID:        p.id,
+			Title:     p.title(),
+			Type:      p.panelType(),
+			Version:   v.Version,
+			Created:   v.Created,
+			CreatedBy: v.CreatedBy,
+			Message:   v.Message,
+		})
+	}
+	return result, nil
+}
+
+// fingerprint returns the state of a panel as a string, without the panels of collapsed rows that have their own.
+func (p panel) fingerprint() (string, error) {
+	data := make(map[string]any, len(p.data))
+	for k, v := range p.data {
+		if k != "panels" {
+			data[k] = v
+		}
+	}
+	// maps are encoded with sorted keys
+	b, err := json.Marshal(struct {
+		Row  int64          `json:"row"`
+		Data map[string]any `json:"data"`
+	}{p.row, data})
+	return string(b), err
+}
+
+// panelsOf returns the panels of a dashboard. Panels following a row, or in a collapsed row, belong to the row.
+func panelsOf(dashboard map[string]any) []panel {
+	list, _ := dashboard["panels"].([]any)
+	panels := make([]panel, 0, len(list))
+
+	var row int64
+	for _, item := range list {
+		data, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		id, ok := panelID(data)
+		if !ok {
+			continue
+		}
+
+		if data["type"] == "row" {
+			panels = append(panels, panel{id: id, data: data})
+			row = id
+
+			nested, _ := data["panels"].([]any)
+			for _, child := range nested {
+				childData, ok := child.(map[string]any)
+				if !ok {
+					continue
+				}
+				if childID, ok := panelID(childData); ok {
+					panels = append(panels, panel{id: childID, row: id, data: childData})
+				}
+			}
+			continue
+		}
+		panels = append(panels, panel{id: id, row: row, data: data})
+	}
+	return panels
+}
+
+func panelID(data map[string]any) (int64, bool) {
+	switch id := data["id"].(type) {
+	case float64:
+		return int64(id), true
+	case json.Number:
+		n, err := id.Int64()
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// queryDiffs compares the targets of two panels by refId.
+func queryDiffs(base, next any) []QueryDiff {
+	baseQueries := queriesByRefID(base)
+	nextQueries := queriesByRefID(next)
+
+	diffs := make([]QueryDiff, 0)
+	for _, refID := range sortedKeys(nextQueries) {
+		old, ok := baseQueries[refID]
+		switch {
+		case !ok:
+			diffs = append(diffs, QueryDiff{RefID: refID, Change: StructuralAdded})
+		case !reflect.DeepEqual(old, nextQueries[refID]):
+			diffs = append(diffs, QueryDiff{RefID: refID, Change: StructuralModified})
+		}
+	}
+	for _, refID := range sortedKeys(baseQueries) {
+		if _, ok := nextQueries[refID]; !ok {
+			diffs = append(diffs, QueryDiff{RefID: refID, Change: StructuralRemoved})
+		}
+	}
+	return diffs
+}
+
+func queriesByRefID(targets any) map[string]any {
+	list, _ := targets.([]any)
+	queries := make(map[string]any, len(list))
+	for _, target := range list {
+		query, ok := target.(map[string]any)
+		if !ok {
+			continue
+		}
+		refID, _ := query["refId"].(string)
+		queries[refID] = query
+	}
+	return queries
+}
+
+// changedFields returns the sorted keys whose values differ between two objects.
+func changedFields(base, next map[string]any, ignored map[string]bool) []string {
+	keys := make(map[string]any, len(base)+len(next))
+	for k := range base {
+		keys[k] = nil
+	}
+	for k := range next {
+		keys[k] = nil
+	}
+
+	fields := make([]string, 0)
+	for _, k := range sortedKeys(keys) {
+		if !ignored[k] && !reflect.DeepEqual(base[k], next[k]) {
+			fields = append(fields, k)
+		}
+	}
+	return fields
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toMap converts a dashboard to plain JSON values, so that equal values compare equal regardless of their Go types.
+func toMap(data *simplejson.Json) (map[string]any, error) {
+	b, err := data.Encode()
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]any)
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
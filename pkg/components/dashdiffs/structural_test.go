@@ -0,0 +1,91 @@
+package dashdiffs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestCalculateStructuralDiff(t *testing.T) {
+	const (
+		baseJSON = `{
+			"id": 1,
+			"version": 3,
+			"title": "Dashboard",
+			"panels": [
+				{"id": 1, "type": "timeseries", "title": "Same", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8},
+					"targets": [{"refId": "A", "expr": "up"}]},
+				{"id": 2, "type": "timeseries", "title": "Queries", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8},
+					"targets": [{"refId": "A", "expr": "up"}, {"refId": "B", "expr": "down"}]},
+				{"id": 3, "type": "stat", "title": "Moved", "gridPos": {"x": 0, "y": 8, "w": 12, "h": 8}},
+				{"id": 4, "type": "text", "title": "Removed", "gridPos": {"x": 12, "y": 8, "w": 12, "h": 8}},
+				{"id": 5, "type": "row", "title": "Row", "collapsed": true, "gridPos": {"x": 0, "y": 16, "w": 24, "h": 1},
+					"panels": [{"id": 6, "type": "gauge", "title": "In row", "gridPos": {"x": 0, "y": 17, "w": 12, "h": 8}}]}
+			]
+		}`
+
+		newJSON = `{
+			"id": 1,
+			"version": 4,
+			"title": "Renamed dashboard",
+			"panels": [
+				{"id": 1, "type": "timeseries", "title": "Same", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8},
+					"targets": [{"refId": "A", "expr": "up"}]},
+				{"id": 2, "type": "timeseries", "title": "Queries", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8},
+					"targets": [{"refId": "A", "expr": "rate(up[5m])"}, {"refId": "C", "expr": "down"}]},
+				{"id": 3, "type": "stat", "title": "Moved", "gridPos": {"x": 12, "y": 8, "w": 12, "h": 8}},
+				{"id": 7, "type": "table", "title": "Added", "gridPos": {"x": 0, "y": 8, "w": 12, "h": 8}},
+				{"id": 5, "type": "row", "title": "Row", "collapsed": true, "gridPos": {"x": 0, "y": 16, "w": 24, "h": 1},
+					"panels": [{"id": 6, "type": "gauge", "title": "In row", "gridPos": {"x": 0, "y": 17, "w": 12, "h": 8},
+						"options": {"showThresholdMarkers": false}}]}
+			]
+		}`
+	)
+
+	base, err := simplejson.NewJson([]byte(baseJSON))
+	require.NoError(t, err)
+	next, err := simplejson.NewJson([]byte(newJSON))
+	require.NoError(t, err)
+
+	diff, err := CalculateStructuralDiff(base, next)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"title"}, diff.Dashboard)
+	require.Equal(t, []PanelDiff{
+		{ID: 2, Title: "Queries", Type: "timeseries", Change: StructuralModified, Fields: []string{}, Queries: []QueryDiff{
+			{RefID: "A", Change: StructuralModified},
+			{RefID: "C", Change: StructuralAdded},
+			{RefID: "B", Change: StructuralRemoved},
+		}},
+		{ID: 3, Title: "Moved", Type: "stat", Change: StructuralModified, Moved: true, Fields: []string{}, Queries: []QueryDiff{}},
+		{ID: 7, Title: "Added", Type: "table", Change: StructuralAdded},
+		{ID: 6, Title: "In row", Type: "gauge", Change: StructuralModified, Fields: []string{"options"}, Queries: []QueryDiff{}},
+		{ID: 4, Title: "Removed", Type: "text", Change: StructuralRemoved},
+	}, diff.Panels)
+}
+
+func TestBlame(t *testing.T) {
+	version := func(v int, createdBy int64, panels string) BlameVersion {
+		data, err := simplejson.NewJson([]byte(`{"panels": ` + panels + `}`))
+		require.NoError(t, err)
+		return BlameVersion{Version: v, Created: time.Unix(int64(v), 0), CreatedBy: createdBy, Message: "v", Data: data}
+	}
+
+	versions := []BlameVersion{
+		// versions are sorted by the blame
+		version(3, 30, `[{"id": 1, "title": "A"}, {"id": 2, "title": "B2"}, {"id": 3, "title": "C"}]`),
+		version(1, 10, `[{"id": 1, "title": "A"}, {"id": 2, "title": "B"}]`),
+		version(2, 20, `[{"id": 1, "title": "A"}, {"id": 2, "title": "B2"}]`),
+	}
+
+	blame, err := Blame(versions)
+	require.NoError(t, err)
+	require.Equal(t, []PanelBlame{
+		{ID: 1, Title: "A", Version: 1, Created: time.Unix(1, 0), CreatedBy: 10, Message: "v"},
+		{ID: 2, Title: "B2", Version: 2, Created: time.Unix(2, 0), CreatedBy: 20, Message: "v"},
+		{ID: 3, Title: "C", Version: 3, Created: time.Unix(3, 0), CreatedBy: 30, Message: "v"},
+	}, blame)
+}
@@ -2,6 +2,8 @@ package kvstore
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -14,8 +16,9 @@ const (
 
 func ProvideService(sqlStore db.DB) KVStore {
 	return &kvStoreSQL{
-		sqlStore: sqlStore,
-		log:      log.New("infra.kvstore.sql"),
+		sqlStore:          sqlStore,
+		log:               log.New("infra.kvstore.sql"),
+		watchPollInterval: defaultWatchPollInterval,
 	}
 }
 
@@ -26,6 +29,33 @@ type KVStore interface {
 	Del(ctx context.Context, orgId int64, namespace string, key string) error
 	Keys(ctx context.Context, orgId int64, namespace string, keyPrefix string) ([]Key, error)
 	GetAll(ctx context.Context, orgId int64, namespace string) (map[int64]map[string]string, error)
+	// SetWithTTL sets an item that expires after the ttl. Expired items are not returned, and they are
+	// deleted by the cleanup service.
+	SetWithTTL(ctx context.Context, orgId int64, namespace string, key string, value string, ttl time.Duration) error
+	// GetWithVersion returns the value and the version of an item, to update it with CompareAndSwap.
+	GetWithVersion(ctx context.Context, orgId int64, namespace string, key string) (string, int64, bool, error)
+	// CompareAndSwap sets the item only if its version is still the given version, or if the item does not
+	// exist and the version is 0. It returns the new version, or ErrVersionMismatch. A ttl of 0 never expires.
+	CompareAndSwap(ctx context.Context, orgId int64, namespace string, key string, version int64, value string, ttl time.Duration) (int64, error)
+	// Watch sends the changes of the namespace items with the key prefix, made on any instance after the
+	// watch started. The channel is closed when the context is done. To watch all organizations the
+	// constant 'kvstore.AllOrganizations' can be passed as orgId. The watch must be enabled for the
+	// namespace with EnableWatch.
+	Watch(ctx context.Context, orgId int64, namespace string, keyPrefix string) (<-chan Change, error)
+}
+
+var watchedNamespaces sync.Map
+
+// EnableWatch logs the changes of the namespace, so that they can be watched. The other namespaces have no
+// change log. It must be called before the items of the namespace are changed, by the package of the namespace
+// (in an init function), so that every instance logs the changes.
+func EnableWatch(namespace string) {
+	watchedNamespaces.Store(namespace, true)
+}
+
+func isWatchEnabled(namespace string) bool {
+	_, ok := watchedNamespaces.Load(namespace)
+	return ok
 }
 
 // WithNamespace returns a kvstore wrapper with fixed orgId and namespace.
@@ -64,3 +94,19 @@ func (kv *NamespacedKVStore) Keys(ctx context.Context, keyPrefix string) ([]Key,
 func (kv *NamespacedKVStore) GetAll(ctx context.Context) (map[int64]map[string]string, error) {
 	return kv.kvStore.GetAll(ctx, kv.orgId, kv.namespace)
 }
+
+func (kv *NamespacedKVStore) SetWithTTL(ctx context.Context, key string, value string, ttl time.Duration) error {
+	return kv.kvStore.SetWithTTL(ctx, kv.orgId, kv.namespace, key, value, ttl)
+}
+
+func (kv *NamespacedKVStore) GetWithVersion(ctx context.Context, key string) (string, int64, bool, error) {
+	return kv.kvStore.GetWithVersion(ctx, kv.orgId, kv.namespace, key)
+}
+
+func (kv *NamespacedKVStore) CompareAndSwap(ctx context.Context, key string, version int64, value string, ttl time.Duration) (int64, error) {
+	return kv.kvStore.CompareAndSwap(ctx, kv.orgId, kv.namespace, key, version, value, ttl)
+}
+
+func (kv *NamespacedKVStore) Watch(ctx context.Context, keyPrefix string) (<-chan Change, error) {
+	return kv.kvStore.Watch(ctx, kv.orgId, kv.namespace, keyPrefix)
+}
@@ -2,8 +2,10 @@ package kvstore
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,8 +20,9 @@ func createTestableKVStore(t *testing.T) KVStore {
 	sqlStore := db.InitTestDB(t)
 
 	kv := &kvStoreSQL{
-		sqlStore: sqlStore,
-		log:      log.New("infra.kvstore.sql"),
+		sqlStore:          sqlStore,
+		log:               log.New("infra.kvstore.sql"),
+		watchPollInterval: 10 * time.Millisecond,
 	}
 
 	return kv
@@ -287,3 +290,207 @@ func TestGetItems(t *testing.T) {
 		}
 	})
 }
+
+func TestIntegrationKVStoreTTL(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	kv := createTestableKVStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, kv.SetWithTTL(ctx, 1, "ttl", "short", "value", time.Millisecond))
+	require.NoError(t, kv.SetWithTTL(ctx, 1, "ttl", "long", "value", time.Hour))
+	require.NoError(t, kv.Set(ctx, 1, "ttl", "forever", "value"))
+	time.Sleep(10 * time.Millisecond)
+
+	t.Run("expired items are not returned", func(t *testing.T) {
+		_, ok, err := kv.Get(ctx, 1, "ttl", "short")
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		value, ok, err := kv.Get(ctx, 1, "ttl", "long")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "value", value)
+
+		keys, err := kv.Keys(ctx, 1, "ttl", "")
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+
+		items, err := kv.GetAll(ctx, 1, "ttl")
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"long": "value", "forever": "value"}, items[1])
+	})
+
+	t.Run("setting an item without ttl removes the expiry", func(t *testing.T) {
+		require.NoError(t, kv.SetWithTTL(ctx, 1, "ttl", "persisted", "value", time.Millisecond))
+		require.NoError(t, kv.Set(ctx, 1, "ttl", "persisted", "value"))
+		time.Sleep(10 * time.Millisecond)
+
+		_, ok, err := kv.Get(ctx, 1, "ttl", "persisted")
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("expired items are deleted", func(t *testing.T) {
+		deleted, err := kv.(*kvStoreSQL).deleteExpired(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), deleted)
+
+		deleted, err = kv.(*kvStoreSQL).deleteExpired(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), deleted)
+	})
+}
+
+func TestIntegrationKVStoreCompareAndSwap(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	kv := createTestableKVStore(t)
+	ctx := context.Background()
+
+	t.Run("version 0 creates the item", func(t *testing.T) {
+		version, err := kv.CompareAndSwap(ctx, 1, "cas", "key", 0, "a", 0)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), version)
+
+		_, err = kv.CompareAndSwap(ctx, 1, "cas", "key", 0, "b", 0)
+		require.ErrorIs(t, err, ErrVersionMismatch)
+	})
+
+	t.Run("the item is swapped only from the current version", func(t *testing.T) {
+		value, version, ok, err := kv.GetWithVersion(ctx, 1, "cas", "key")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "a", value)
+
+		newVersion, err := kv.CompareAndSwap(ctx, 1, "cas", "key", version, "b", 0)
+		require.NoError(t, err)
+		require.Equal(t, version+1, newVersion)
+
+		_, err = kv.CompareAndSwap(ctx, 1, "cas", "key", version, "c", 0)
+		require.ErrorIs(t, err, ErrVersionMismatch)
+
+		value, _, err = kv.Get(ctx, 1, "cas", "key")
+		require.NoError(t, err)
+		require.Equal(t, "b", value)
+	})
+
+	t.Run("set increments the version", func(t *testing.T) {
+		_, version, _, err := kv.GetWithVersion(ctx, 1, "cas", "key")
+		require.NoError(t, err)
+		require.NoError(t, kv.Set(ctx, 1, "cas", "key", "c"))
+
+		_, err = kv.CompareAndSwap(ctx, 1, "cas", "key", version, "d", 0)
+		require.ErrorIs(t, err, ErrVersionMismatch)
+	})
+
+	t.Run("an expired item can be created again", func(t *testing.T) {
+		_, err := kv.CompareAndSwap(ctx, 1, "cas", "lease", 0, "node-1", time.Millisecond)
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+
+		version, err := kv.CompareAndSwap(ctx, 1, "cas", "lease", 0, "node-2", time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), version)
+
+		value, ok, err := kv.Get(ctx, 1, "cas", "lease")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "node-2", value)
+	})
+
+	t.Run("only one concurrent swap succeeds", func(t *testing.T) {
+		_, version, _, err := kv.GetWithVersion(ctx, 1, "cas", "key")
+		require.NoError(t, err)
+
+		errs := make(chan error, 5)
+		for i := 0; i < 5; i++ {
+			go func(i int) {
+				_, err := kv.CompareAndSwap(ctx, 1, "cas", "key", version, fmt.Sprintf("swap-%d", i), 0)
+				errs <- err
+			}(i)
+		}
+
+		succeeded := 0
+		for i := 0; i < 5; i++ {
+			err := <-errs
+			if err == nil {
+				succeeded++
+			} else if !errors.Is(err, ErrVersionMismatch) {
+				// sqlite may report the concurrent writes as locked, it is not a lost update
+				t.Log("concurrent swap failed", err)
+			}
+		}
+		require.Equal(t, 1, succeeded)
+	})
+}
+
+func TestIntegrationKVStoreWatch(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	kv := createTestableKVStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := kv.Watch(ctx, 1, "watch", "")
+	require.ErrorIs(t, err, ErrWatchNotEnabled)
+	EnableWatch("watch")
+
+	require.NoError(t, kv.Set(ctx, 1, "watch", "before", "value"))
+
+	changes, err := kv.Watch(ctx, 1, "watch", "item")
+	require.NoError(t, err)
+	all, err := kv.Watch(ctx, AllOrganizations, "watch", "")
+	require.NoError(t, err)
+
+	require.NoError(t, kv.Set(ctx, 1, "watch", "item-1", "a"))
+	require.NoError(t, kv.Set(ctx, 1, "other", "item-1", "a"))
+	require.NoError(t, kv.Set(ctx, 2, "watch", "item-1", "a"))
+	require.NoError(t, kv.Set(ctx, 1, "watch", "other", "a"))
+	_, err = kv.CompareAndSwap(ctx, 1, "watch", "item-1", 1, "b", 0)
+	require.NoError(t, err)
+	require.NoError(t, kv.SetWithTTL(ctx, 1, "watch", "item-2", "a", time.Millisecond))
+	require.NoError(t, kv.Del(ctx, 1, "watch", "item-1"))
+	time.Sleep(10 * time.Millisecond)
+	_, err = kv.(*kvStoreSQL).deleteExpired(ctx)
+	require.NoError(t, err)
+
+	require.Equal(t, []Change{
+		{Type: ChangeTypeSet, OrgId: 1, Namespace: "watch", Key: "item-1", Version: 1},
+		{Type: ChangeTypeSet, OrgId: 1, Namespace: "watch", Key: "item-1", Version: 2},
+		{Type: ChangeTypeSet, OrgId: 1, Namespace: "watch", Key: "item-2", Version: 1},
+		{Type: ChangeTypeDeleted, OrgId: 1, Namespace: "watch", Key: "item-1", Version: 2},
+		{Type: ChangeTypeExpired, OrgId: 1, Namespace: "watch", Key: "item-2", Version: 1},
+	}, nextChanges(t, changes, 5))
+
+	received := nextChanges(t, all, 7)
+	require.Equal(t, int64(2), received[1].OrgId)
+	require.Equal(t, "other", received[2].Key)
+
+	// the changes of the namespaces without watch are not logged
+	var count int64
+	err = kv.(*kvStoreSQL).sqlStore.GetSqlxSession().Get(ctx, &count, "SELECT COUNT(*) FROM kv_store_change WHERE namespace = ?", "other")
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	cancel()
+	for range changes {
+	}
+}
+
+func nextChanges(t *testing.T, changes <-chan Change, count int) []Change {
+	t.Helper()
+	var received []Change
+	for len(received) < count {
+		select {
+		case c := <-changes:
+			received = append(received, c)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timeout waiting for changes", "received %d of %d", len(received), count)
+		}
+	}
+	return received
+}
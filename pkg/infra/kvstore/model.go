@@ -1,9 +1,17 @@
 package kvstore
 
 import (
+	"errors"
 	"time"
 )
 
+var (
+	// ErrVersionMismatch is returned by CompareAndSwap when the item has been changed since it was read.
+	ErrVersionMismatch = errors.New("kvstore item version mismatch")
+	// ErrWatchNotEnabled is returned by Watch when the changes of the namespace are not logged (see EnableWatch).
+	ErrWatchNotEnabled = errors.New("kvstore watch not enabled for namespace")
+)
+
 // Item stored in k/v store.
 type Item struct {
 	Id        int64
@@ -12,6 +20,11 @@ type Item struct {
 	Key       *string
 	Value     string
 
+	// Version is incremented every time the value is set
+	Version int64
+	// Expires is the unix time in milliseconds after which the item is expired, 0 means it never expires
+	Expires int64
+
 	Created time.Time
 	Updated time.Time
 }
@@ -20,6 +33,10 @@ func (i *Item) TableName() string {
 	return "kv_store"
 }
 
+func (i *Item) expired(now time.Time) bool {
+	return i.Expires > 0 && i.Expires <= now.UnixMilli()
+}
+
 type Key struct {
 	OrgId     int64
 	Namespace string
@@ -29,3 +46,36 @@ type Key struct {
 func (i *Key) TableName() string {
 	return "kv_store"
 }
+
+type ChangeType string
+
+const (
+	ChangeTypeSet     ChangeType = "set"
+	ChangeTypeDeleted ChangeType = "deleted"
+	ChangeTypeExpired ChangeType = "expired"
+)
+
+// Change is sent to the watchers when an item is set, deleted or expires.
+// The value is not included, it can be read with Get.
+type Change struct {
+	Type      ChangeType
+	OrgId     int64
+	Namespace string
+	Key       string
+	Version   int64
+}
+
+// changeLogItem is a row of the change log read by the watchers
+type changeLogItem struct {
+	Id        int64
+	OrgId     int64
+	Namespace string
+	Key       string
+	Action    ChangeType
+	Version   int64
+	Created   int64
+}
+
+func (i *changeLogItem) TableName() string {
+	return "kv_store_change"
+}
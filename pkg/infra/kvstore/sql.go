@@ -3,20 +3,42 @@ package kvstore
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/util/sequence"
+)
+
+const (
+	defaultWatchPollInterval = time.Second
+
+	// The change log is read in batches of this size
+	watchBatchSize = 100
+
+	// How long a missing change id is waited for (see sequence.Gaps)
+	watchGapTimeout = 10 * time.Second
+
+	// The changes are kept in the change log for this long
+	changeLogRetention = time.Hour
 )
 
 // kvStoreSQL provides a key/value store backed by the Grafana database
 type kvStoreSQL struct {
-	log      log.Logger
-	sqlStore db.DB
+	log               log.Logger
+	sqlStore          db.DB
+	watchPollInterval time.Duration
 }
 
 // Get an item from the store
 func (kv *kvStoreSQL) Get(ctx context.Context, orgId int64, namespace string, key string) (string, bool, error) {
+	value, _, itemFound, err := kv.GetWithVersion(ctx, orgId, namespace, key)
+	return value, itemFound, err
+}
+
+// GetWithVersion gets an item and its version from the store
+func (kv *kvStoreSQL) GetWithVersion(ctx context.Context, orgId int64, namespace string, key string) (string, int64, bool, error) {
 	item := Item{
 		OrgId:     &orgId,
 		Namespace: &namespace,
@@ -34,16 +56,32 @@ func (kv *kvStoreSQL) Get(ctx context.Context, orgId int64, namespace string, ke
 			kv.log.Debug("kvstore value not found", "orgId", orgId, "namespace", namespace, "key", key)
 			return nil
 		}
+		if item.expired(time.Now()) {
+			kv.log.Debug("kvstore value expired", "orgId", orgId, "namespace", namespace, "key", key)
+			return nil
+		}
 		itemFound = true
 		kv.log.Debug("got kvstore value", "orgId", orgId, "namespace", namespace, "key", key, "value", item.Value)
 		return nil
 	})
 
-	return item.Value, itemFound, err
+	if !itemFound {
+		return "", 0, false, err
+	}
+	return item.Value, item.Version, itemFound, err
 }
 
 // Set an item in the store
 func (kv *kvStoreSQL) Set(ctx context.Context, orgId int64, namespace string, key string, value string) error {
+	return kv.set(ctx, orgId, namespace, key, value, 0)
+}
+
+// SetWithTTL sets an item in the store that expires after the ttl
+func (kv *kvStoreSQL) SetWithTTL(ctx context.Context, orgId int64, namespace string, key string, value string, ttl time.Duration) error {
+	return kv.set(ctx, orgId, namespace, key, value, ttl)
+}
+
+func (kv *kvStoreSQL) set(ctx context.Context, orgId int64, namespace string, key string, value string, ttl time.Duration) error {
 	return kv.sqlStore.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
 		item := Item{
 			OrgId:     &orgId,
@@ -57,16 +95,25 @@ func (kv *kvStoreSQL) Set(ctx context.Context, orgId int64, namespace string, ke
 			return err
 		}
 
-		if has && item.Value == value {
+		now := time.Now()
+		if has && item.Value == value && item.Expires == 0 && ttl == 0 {
 			kv.log.Debug("kvstore value not changed", "orgId", orgId, "namespace", namespace, "key", key, "value", value)
 			return nil
 		}
 
 		item.Value = value
-		item.Updated = time.Now()
+		item.Expires = expiresAt(now, ttl)
+		item.Updated = now
 
 		if has {
-			_, err = dbSession.Exec("UPDATE kv_store SET value = ?, updated = ? WHERE id = ?", item.Value, item.Updated, item.Id)
+			_, err = dbSession.Exec("UPDATE kv_store SET value = ?, version = version + 1, expires = ?, updated = ? WHERE id = ?", item.Value, item.Expires, item.Updated, item.Id)
+			if err == nil {
+				// read the version in the transaction, other updates are waiting for the row lock
+				_, err = dbSession.SQL("SELECT version FROM kv_store WHERE id = ?", item.Id).Get(&item.Version)
+			}
+			if err == nil {
+				err = kv.writeChange(dbSession, orgId, namespace, key, ChangeTypeSet, item.Version, now)
+			}
 			if err != nil {
 				kv.log.Debug("error updating kvstore value", "orgId", orgId, "namespace", namespace, "key", key, "value", value, "err", err)
 			} else {
@@ -75,8 +122,12 @@ func (kv *kvStoreSQL) Set(ctx context.Context, orgId int64, namespace string, ke
 			return err
 		}
 
+		item.Version = 1
 		item.Created = item.Updated
 		_, err = dbSession.Insert(&item)
+		if err == nil {
+			err = kv.writeChange(dbSession, orgId, namespace, key, ChangeTypeSet, item.Version, now)
+		}
 		if err != nil {
 			kv.log.Debug("error inserting kvstore value", "orgId", orgId, "namespace", namespace, "key", key, "value", value, "err", err)
 		} else {
@@ -86,12 +137,90 @@ func (kv *kvStoreSQL) Set(ctx context.Context, orgId int64, namespace string, ke
 	})
 }
 
+// CompareAndSwap sets an item in the store if it was not changed since the version was read
+func (kv *kvStoreSQL) CompareAndSwap(ctx context.Context, orgId int64, namespace string, key string, version int64, value string, ttl time.Duration) (int64, error) {
+	var newVersion int64
+	err := kv.sqlStore.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
+		item := Item{
+			OrgId:     &orgId,
+			Namespace: &namespace,
+			Key:       &key,
+		}
+
+		has, err := dbSession.Get(&item)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		current := item.Version
+		if !has || item.expired(now) {
+			current = 0
+		}
+		if current != version {
+			kv.log.Debug("kvstore version mismatch", "orgId", orgId, "namespace", namespace, "key", key, "version", version, "current", current)
+			return ErrVersionMismatch
+		}
+
+		item.Value = value
+		item.Expires = expiresAt(now, ttl)
+		item.Updated = now
+
+		if has {
+			// an expired item is replaced, and its version keeps increasing
+			res, err := dbSession.Exec("UPDATE kv_store SET value = ?, version = ?, expires = ?, updated = ? WHERE id = ? AND version = ?",
+				item.Value, item.Version+1, item.Expires, item.Updated, item.Id, item.Version)
+			if err != nil {
+				return err
+			}
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if affected == 0 {
+				return ErrVersionMismatch
+			}
+			newVersion = item.Version + 1
+		} else {
+			item.Version = 1
+			item.Created = item.Updated
+			if _, err := dbSession.Insert(&item); err != nil {
+				if kv.sqlStore.GetDialect().IsUniqueConstraintViolation(err) {
+					return ErrVersionMismatch // inserted concurrently
+				}
+				return err
+			}
+			newVersion = item.Version
+		}
+
+		kv.log.Debug("kvstore value swapped", "orgId", orgId, "namespace", namespace, "key", key, "version", newVersion)
+		return kv.writeChange(dbSession, orgId, namespace, key, ChangeTypeSet, newVersion, now)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return newVersion, nil
+}
+
 // Del deletes an item from the store.
 func (kv *kvStoreSQL) Del(ctx context.Context, orgId int64, namespace string, key string) error {
-	err := kv.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+	err := kv.sqlStore.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
+		item := Item{
+			OrgId:     &orgId,
+			Namespace: &namespace,
+			Key:       &key,
+		}
+
+		has, err := dbSession.Get(&item)
+		if err != nil || !has {
+			return err
+		}
+
 		query := fmt.Sprintf("DELETE FROM kv_store WHERE org_id=? and namespace=? and %s=?", kv.sqlStore.GetDialect().Quote("key"))
-		_, err := dbSession.Exec(query, orgId, namespace, key)
-		return err
+		if _, err := dbSession.Exec(query, orgId, namespace, key); err != nil {
+			return err
+		}
+		return kv.writeChange(dbSession, orgId, namespace, key, ChangeTypeDeleted, item.Version, time.Now())
 	})
 	return err
 }
@@ -105,6 +234,7 @@ func (kv *kvStoreSQL) Keys(ctx context.Context, orgId int64, namespace string, k
 		if orgId != AllOrganizations {
 			query.And("org_id = ?", orgId)
 		}
+		query.And("(expires = 0 OR expires > ?)", time.Now().UnixMilli())
 		return query.Find(&keys)
 	})
 	return keys, err
@@ -120,6 +250,7 @@ func (kv *kvStoreSQL) GetAll(ctx context.Context, orgId int64, namespace string)
 		if orgId != AllOrganizations {
 			query.And("org_id = ?", orgId)
 		}
+		query.And("(expires = 0 OR expires > ?)", time.Now().UnixMilli())
 
 		return query.Find(&results)
 	})
@@ -134,3 +265,181 @@ func (kv *kvStoreSQL) GetAll(ctx context.Context, orgId int64, namespace string)
 
 	return items, err
 }
+
+// Watch polls the change log for the changes of the namespace
+func (kv *kvStoreSQL) Watch(ctx context.Context, orgId int64, namespace string, keyPrefix string) (<-chan Change, error) {
+	if !isWatchEnabled(namespace) {
+		return nil, fmt.Errorf("%w: %s", ErrWatchNotEnabled, namespace)
+	}
+
+	w := &changeWatcher{
+		kv:        kv,
+		orgId:     orgId,
+		namespace: namespace,
+		keyPrefix: keyPrefix,
+		changes:   make(chan Change, watchBatchSize),
+		gaps:      sequence.Gaps{Timeout: watchGapTimeout},
+	}
+
+	var err error
+	w.lastId, err = kv.lastChangeId(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	go w.run(ctx)
+	return w.changes, nil
+}
+
+// writeChange inserts the change in the change log, if the namespace can be watched
+func (kv *kvStoreSQL) writeChange(dbSession *db.Session, orgId int64, namespace string, key string, action ChangeType, version int64, now time.Time) error {
+	if !isWatchEnabled(namespace) {
+		return nil
+	}
+	_, err := dbSession.Insert(&changeLogItem{
+		OrgId:     orgId,
+		Namespace: namespace,
+		Key:       key,
+		Action:    action,
+		Version:   version,
+		Created:   now.UnixMilli(),
+	})
+	return err
+}
+
+func (kv *kvStoreSQL) lastChangeId(ctx context.Context) (int64, error) {
+	var lastId int64
+	err := kv.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		_, err := dbSession.SQL("SELECT COALESCE(MAX(id), 0) FROM kv_store_change").Get(&lastId)
+		return err
+	})
+	return lastId, err
+}
+
+// deleteExpired deletes the expired items, and the changes older than the change log retention
+func (kv *kvStoreSQL) deleteExpired(ctx context.Context) (int64, error) {
+	var expired []Item
+	err := kv.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		return dbSession.Where("expires > 0 AND expires <= ?", time.Now().UnixMilli()).Find(&expired)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, item := range expired {
+		err := kv.sqlStore.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
+			// the item may have been set again, or deleted by another instance
+			res, err := dbSession.Exec("DELETE FROM kv_store WHERE id = ? AND version = ?", item.Id, item.Version)
+			if err != nil {
+				return err
+			}
+			affected, err := res.RowsAffected()
+			if err != nil || affected == 0 {
+				return err
+			}
+			deleted++
+			return kv.writeChange(dbSession, *item.OrgId, *item.Namespace, *item.Key, ChangeTypeExpired, item.Version, time.Now())
+		})
+		if err != nil {
+			return deleted, err
+		}
+	}
+
+	// the last change is kept, the watchers start after its id
+	lastId, err := kv.lastChangeId(ctx)
+	if err != nil {
+		return deleted, err
+	}
+	err = kv.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		_, err := dbSession.Exec("DELETE FROM kv_store_change WHERE created < ? AND id < ?",
+			time.Now().Add(-changeLogRetention).UnixMilli(), lastId)
+		return err
+	})
+	return deleted, err
+}
+
+// DeleteExpired deletes the expired items of the k/v store. It is called periodically by the cleanup service.
+func DeleteExpired(ctx context.Context, sqlStore db.DB) (int64, error) {
+	kv := &kvStoreSQL{
+		sqlStore: sqlStore,
+		log:      log.New("infra.kvstore.sql"),
+	}
+	return kv.deleteExpired(ctx)
+}
+
+type changeWatcher struct {
+	kv        *kvStoreSQL
+	orgId     int64
+	namespace string
+	keyPrefix string
+	changes   chan Change
+
+	// the last change id read
+	lastId int64
+	gaps   sequence.Gaps
+}
+
+func (w *changeWatcher) run(ctx context.Context) {
+	defer close(w.changes)
+
+	ticker := time.NewTicker(w.kv.watchPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.poll(ctx); err != nil && ctx.Err() == nil {
+				w.kv.log.Warn("error reading the kvstore changes", "namespace", w.namespace, "err", err)
+			}
+		}
+	}
+}
+
+// poll sends the committed changes after the last id
+func (w *changeWatcher) poll(ctx context.Context) error {
+	for {
+		var changes []changeLogItem
+		err := w.kv.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+			return dbSession.Where("id > ?", w.lastId).OrderBy("id").Limit(watchBatchSize).Find(&changes)
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, c := range changes {
+			if !w.gaps.Next(w.lastId, c.Id) {
+				return nil // wait for the missing changes
+			}
+			w.lastId = c.Id
+
+			if !w.matches(c) {
+				continue
+			}
+			select {
+			case w.changes <- Change{Type: c.Action, OrgId: c.OrgId, Namespace: c.Namespace, Key: c.Key, Version: c.Version}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if len(changes) < watchBatchSize {
+			return nil
+		}
+	}
+}
+
+func (w *changeWatcher) matches(c changeLogItem) bool {
+	return c.Namespace == w.namespace &&
+		(w.orgId == AllOrganizations || c.OrgId == w.orgId) &&
+		strings.HasPrefix(c.Key, w.keyPrefix)
+}
+
+func expiresAt(now time.Time, ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return now.Add(ttl).UnixMilli()
+}
@@ -4,16 +4,34 @@ import (
 	"context"
 	"errors"
 	"strings"
+	"sync"
+	"time"
 )
 
 // In memory kv store used for testing
 type FakeKVStore struct {
+	mu       sync.Mutex
 	store    map[Key]string
+	versions map[Key]int64
+	expires  map[Key]time.Time
+	watchers []*fakeWatcher
 	delError bool
 }
 
+type fakeWatcher struct {
+	ctx       context.Context
+	orgId     int64
+	namespace string
+	keyPrefix string
+	changes   chan Change
+}
+
 func NewFakeKVStore() *FakeKVStore {
-	return &FakeKVStore{store: make(map[Key]string)}
+	return &FakeKVStore{
+		store:    make(map[Key]string),
+		versions: make(map[Key]int64),
+		expires:  make(map[Key]time.Time),
+	}
 }
 
 func (f *FakeKVStore) DeletionError(shouldErr bool) {
@@ -21,28 +39,71 @@ func (f *FakeKVStore) DeletionError(shouldErr bool) {
 }
 
 func (f *FakeKVStore) Get(ctx context.Context, orgId int64, namespace string, key string) (string, bool, error) {
-	value := f.store[buildKey(orgId, namespace, key)]
+	value, _, found, err := f.GetWithVersion(ctx, orgId, namespace, key)
+	return value, found, err
+}
+
+func (f *FakeKVStore) GetWithVersion(ctx context.Context, orgId int64, namespace string, key string) (string, int64, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	k := buildKey(orgId, namespace, key)
+	if f.expired(k) {
+		return "", 0, false, nil
+	}
+	value := f.store[k]
 	found := value != ""
-	return value, found, nil
+	return value, f.versions[k], found, nil
 }
 
 func (f *FakeKVStore) Set(ctx context.Context, orgId int64, namespace string, key string, value string) error {
-	f.store[buildKey(orgId, namespace, key)] = value
+	return f.SetWithTTL(ctx, orgId, namespace, key, value, 0)
+}
+
+func (f *FakeKVStore) SetWithTTL(ctx context.Context, orgId int64, namespace string, key string, value string, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.set(buildKey(orgId, namespace, key), value, ttl)
 	return nil
 }
 
+func (f *FakeKVStore) CompareAndSwap(ctx context.Context, orgId int64, namespace string, key string, version int64, value string, ttl time.Duration) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	k := buildKey(orgId, namespace, key)
+	current := f.versions[k]
+	if _, ok := f.store[k]; !ok || f.expired(k) {
+		current = 0
+	}
+	if current != version {
+		return 0, ErrVersionMismatch
+	}
+	return f.set(k, value, ttl), nil
+}
+
 func (f *FakeKVStore) Del(ctx context.Context, orgId int64, namespace string, key string) error {
 	if f.delError {
 		return errors.New("mocked del error")
 	}
-	delete(f.store, buildKey(orgId, namespace, key))
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	k := buildKey(orgId, namespace, key)
+	if _, ok := f.store[k]; ok {
+		f.notify(k, ChangeTypeDeleted)
+	}
+	delete(f.store, k)
+	delete(f.expires, k)
 	return nil
 }
 
 // List all keys with an optional filter. If default values are provided, filter is not applied.
 func (f *FakeKVStore) Keys(ctx context.Context, orgId int64, namespace string, keyPrefix string) ([]Key, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	res := make([]Key, 0)
 	for k := range f.store {
+		if f.expired(k) {
+			continue
+		}
 		if orgId == AllOrganizations && namespace == "" && keyPrefix == "" {
 			res = append(res, k)
 		} else if k.OrgId == orgId && k.Namespace == namespace && strings.HasPrefix(k.Key, keyPrefix) {
@@ -53,8 +114,13 @@ func (f *FakeKVStore) Keys(ctx context.Context, orgId int64, namespace string, k
 }
 
 func (f *FakeKVStore) GetAll(ctx context.Context, orgId int64, namespace string) (map[int64]map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	items := make(map[int64]map[string]string)
 	for k := range f.store {
+		if f.expired(k) {
+			continue
+		}
 		orgId := k.OrgId
 		namespace := k.Namespace
 
@@ -68,6 +134,59 @@ func (f *FakeKVStore) GetAll(ctx context.Context, orgId int64, namespace string)
 	return items, nil
 }
 
+// Watch sends the changes made through the fake store, the items do not expire in the background
+func (f *FakeKVStore) Watch(ctx context.Context, orgId int64, namespace string, keyPrefix string) (<-chan Change, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w := &fakeWatcher{ctx: ctx, orgId: orgId, namespace: namespace, keyPrefix: keyPrefix, changes: make(chan Change, 100)}
+	f.watchers = append(f.watchers, w)
+	go func() {
+		<-ctx.Done()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for i, other := range f.watchers {
+			if other == w {
+				f.watchers = append(f.watchers[:i], f.watchers[i+1:]...)
+				break
+			}
+		}
+		close(w.changes)
+	}()
+	return w.changes, nil
+}
+
+func (f *FakeKVStore) set(k Key, value string, ttl time.Duration) int64 {
+	f.store[k] = value
+	f.versions[k]++
+	if ttl > 0 {
+		f.expires[k] = time.Now().Add(ttl)
+	} else {
+		delete(f.expires, k)
+	}
+	f.notify(k, ChangeTypeSet)
+	return f.versions[k]
+}
+
+func (f *FakeKVStore) expired(k Key) bool {
+	expires, ok := f.expires[k]
+	return ok && !expires.After(time.Now())
+}
+
+func (f *FakeKVStore) notify(k Key, changeType ChangeType) {
+	for _, w := range f.watchers {
+		if w.ctx.Err() != nil || w.namespace != k.Namespace || !strings.HasPrefix(k.Key, w.keyPrefix) {
+			continue
+		}
+		if w.orgId != AllOrganizations && w.orgId != k.OrgId {
+			continue
+		}
+		select {
+		case w.changes <- Change{Type: changeType, OrgId: k.OrgId, Namespace: k.Namespace, Key: k.Key, Version: f.versions[k]}:
+		default: // the watcher is too slow, the change is dropped
+		}
+	}
+}
+
 func buildKey(orgId int64, namespace string, key string) Key {
 	return Key{
 		OrgId:     orgId,
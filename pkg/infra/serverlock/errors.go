@@ -1,5 +1,7 @@
 package serverlock
 
+import "errors"
+
 type ServerLockExistsError struct {
 	actionName string
 }
@@ -7,3 +9,22 @@ type ServerLockExistsError struct {
 func (e *ServerLockExistsError) Error() string {
 	return "there is already a lock for this actionName: " + e.actionName
 }
+
+var (
+	// ErrLeaseLost is returned when a lease expired and was acquired by another holder
+	ErrLeaseLost = errors.New("the lease is held by another holder")
+	// ErrStaleFencingToken is returned when a fencing token is not the token of the current lease
+	ErrStaleFencingToken = errors.New("stale fencing token")
+)
+
+type LeaseHeldError struct {
+	name   string
+	holder string
+}
+
+func (e *LeaseHeldError) Error() string {
+	if e.holder == "" {
+		return "the lease is already held: " + e.name
+	}
+	return "the lease " + e.name + " is held by " + e.holder
+}
@@ -0,0 +1,272 @@
+package serverlock
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// Lease is a lock held by this server until it expires or is released. Its fencing token is higher than the
+// tokens of all the previous leases of the same name, so the writes of a previous holder that did not notice
+// its lease expired can be rejected with ValidateFencingToken.
+type Lease struct {
+	sl    *ServerLockService
+	id    int64
+	name  string
+	token int64
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	expires time.Time
+}
+
+// Name of the lock
+func (l *Lease) Name() string {
+	return l.name
+}
+
+// Token is the fencing token of the lease
+func (l *Lease) Token() int64 {
+	return l.token
+}
+
+// Expires returns when the lease expires if it is not renewed
+func (l *Lease) Expires() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.expires
+}
+
+// Renew extends the lease by its ttl. It returns ErrLeaseLost if the lease expired and was acquired by another holder.
+func (l *Lease) Renew(ctx context.Context) error {
+	ctx, span := l.sl.tracer.Start(ctx, "ServerLockService.renewLease")
+	defer span.End()
+
+	expires := time.Now().Add(l.ttl)
+	err := l.sl.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		res, err := dbSession.Exec("UPDATE server_lock_lease SET expires = ? WHERE id = ? AND holder = ? AND token = ?",
+			expires.UnixMilli(), l.id, l.sl.holder, l.token)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrLeaseLost
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	l.mu.Lock()
+	l.expires = expires
+	l.mu.Unlock()
+	return nil
+}
+
+// Release releases the lease, so that it can be acquired before it expires
+func (l *Lease) Release(ctx context.Context) error {
+	ctx, span := l.sl.tracer.Start(ctx, "ServerLockService.releaseLease")
+	defer span.End()
+
+	return l.sl.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		res, err := dbSession.Exec("UPDATE server_lock_lease SET holder = '', expires = 0 WHERE id = ? AND holder = ? AND token = ?",
+			l.id, l.sl.holder, l.token)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrLeaseLost
+		}
+		return nil
+	})
+}
+
+// AcquireLease acquires the lease of the given name for the ttl. It returns a LeaseHeldError if the
+// lease is held by another holder, or by this server.
+func (sl *ServerLockService) AcquireLease(ctx context.Context, name string, ttl time.Duration) (*Lease, error) {
+	ctx, span := sl.tracer.Start(ctx, "ServerLockService.AcquireLease")
+	span.SetAttributes("serverlock.leaseName", name, attribute.Key("serverlock.leaseName").String(name))
+	defer span.End()
+
+	now := time.Now()
+	lease := &Lease{sl: sl, name: name, ttl: ttl, expires: now.Add(ttl)}
+
+	err := sl.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
+		rows := []*serverLockLease{}
+		if err := dbSession.Where("name = ?", name).Find(&rows); err != nil {
+			return err
+		}
+
+		if len(rows) == 0 {
+			row := &serverLockLease{
+				Name:     name,
+				Holder:   sl.holder,
+				Token:    1,
+				Acquired: now.UnixMilli(),
+				Expires:  lease.expires.UnixMilli(),
+			}
+			if _, err := dbSession.Insert(row); err != nil {
+				if sl.SQLStore.GetDialect().IsUniqueConstraintViolation(err) {
+					return &LeaseHeldError{name: name} // acquired concurrently
+				}
+				return err
+			}
+			lease.id = row.Id
+			lease.token = row.Token
+			return nil
+		}
+
+		row := rows[0]
+		if row.Holder != "" && row.Expires > now.UnixMilli() {
+			return &LeaseHeldError{name: name, holder: row.Holder}
+		}
+
+		res, err := dbSession.Exec("UPDATE server_lock_lease SET holder = ?, token = ?, acquired = ?, expires = ? WHERE id = ? AND token = ?",
+			sl.holder, row.Token+1, now.UnixMilli(), lease.expires.UnixMilli(), row.Id, row.Token)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return &LeaseHeldError{name: name} // acquired concurrently
+		}
+		lease.id = row.Id
+		lease.token = row.Token + 1
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	sl.log.FromContext(ctx).Debug("Acquired lease", "name", name, "token", lease.token, "expires", lease.Expires())
+	return lease, nil
+}
+
+// LeaseExecuteAndRelease acquires the lease, executes fn and releases the lease. The lease is renewed every third of
+// the ttl while fn runs, and the context of fn is cancelled if the lease is lost. It returns a LeaseHeldError if the
+// lease is held by another server.
+func (sl *ServerLockService) LeaseExecuteAndRelease(ctx context.Context, name string, ttl time.Duration, fn func(ctx context.Context, lease *Lease)) error {
+	lease, err := sl.AcquireLease(ctx, name, ttl)
+	if err != nil {
+		return err
+	}
+
+	ctxLogger := sl.log.FromContext(ctx)
+	fnCtx, cancel := context.WithCancel(ctx)
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		sl.renewUntilDone(fnCtx, cancel, lease)
+	}()
+
+	sl.executeFunc(fnCtx, name, func(ctx context.Context) { fn(ctx, lease) })
+	cancel()
+	<-renewed
+
+	// the lease is released even when the job was cancelled
+	if err := lease.Release(context.Background()); err != nil {
+		ctxLogger.Error("Failed to release the lease", "name", name, "token", lease.token, "error", err)
+	}
+	return nil
+}
+
+// renewUntilDone renews the lease until the context is done, and cancels it when the lease is lost
+func (sl *ServerLockService) renewUntilDone(ctx context.Context, cancel context.CancelFunc, lease *Lease) {
+	ticker := time.NewTicker(lease.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := lease.Renew(ctx)
+		if err == nil || ctx.Err() != nil {
+			continue
+		}
+		if errors.Is(err, ErrLeaseLost) || time.Now().After(lease.Expires()) {
+			sl.log.Error("Lost the lease, cancelling the job", "name", lease.name, "token", lease.token, "error", err)
+			cancel()
+			return
+		}
+		// retried on the next tick, the lease is still valid
+		sl.log.Warn("Failed to renew the lease", "name", lease.name, "token", lease.token, "error", err)
+	}
+}
+
+// ValidateFencingToken returns ErrStaleFencingToken unless the token is the token of the current lease of that name
+func (sl *ServerLockService) ValidateFencingToken(ctx context.Context, name string, token int64) error {
+	return sl.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		return ValidateFencingTokenInSession(dbSession, name, token)
+	})
+}
+
+// ValidateFencingTokenInSession is ValidateFencingToken in the given session. Called in the transaction of
+// the writes guarded by the lease, a holder whose lease expired cannot write after the next holder: the lease
+// row is locked until the transaction ends, so the next holder acquires it after the writes are committed.
+func ValidateFencingTokenInSession(dbSession *db.Session, name string, token int64) error {
+	var rows []*serverLockLease
+	// SQLite has no SELECT ... FOR UPDATE, its writers are serialized
+	if err := dbSession.Where("name = ?", name).ForUpdate().Find(&rows); err != nil {
+		return err
+	}
+	if len(rows) == 0 || rows[0].Token != token || rows[0].Holder == "" || rows[0].Expires <= time.Now().UnixMilli() {
+		return ErrStaleFencingToken
+	}
+	return nil
+}
+
+// ListLeases returns the leases currently held, by all the servers
+func (sl *ServerLockService) ListLeases(ctx context.Context) ([]LeaseInfo, error) {
+	var rows []*serverLockLease
+	err := sl.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		return dbSession.Where("holder <> '' AND expires > ?", time.Now().UnixMilli()).OrderBy("name").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	leases := make([]LeaseInfo, 0, len(rows))
+	for _, row := range rows {
+		leases = append(leases, LeaseInfo{
+			Name:     row.Name,
+			Holder:   row.Holder,
+			Token:    row.Token,
+			Acquired: time.UnixMilli(row.Acquired),
+			Expires:  time.UnixMilli(row.Expires),
+		})
+	}
+	return leases, nil
+}
+
+// newHolderID identifies a server process, the hostname is included to find the server holding a lease
+func newHolderID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return hostname + "-" + util.GenerateShortUID()
+}
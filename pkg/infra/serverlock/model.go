@@ -1,5 +1,7 @@
 package serverlock
 
+import "time"
+
 type serverLock struct {
 	// nolint:stylecheck
 	Id            int64
@@ -7,3 +9,23 @@ type serverLock struct {
 	LastExecution int64
 	Version       int64
 }
+
+// serverLockLease is never deleted, so that the fencing token of a lock keeps increasing
+type serverLockLease struct {
+	// nolint:stylecheck
+	Id       int64
+	Name     string
+	Holder   string
+	Token    int64
+	Acquired int64
+	Expires  int64
+}
+
+// LeaseInfo describes a lease currently held by a server
+type LeaseInfo struct {
+	Name     string    `json:"name"`
+	Holder   string    `json:"holder"`
+	Token    int64     `json:"token"`
+	Acquired time.Time `json:"acquired"`
+	Expires  time.Time `json:"expires"`
+}
@@ -16,16 +16,20 @@ func ProvideService(sqlStore db.DB, tracer tracing.Tracer) *ServerLockService {
 		SQLStore: sqlStore,
 		tracer:   tracer,
 		log:      log.New("infra.lockservice"),
+		holder:   newHolderID(),
 	}
 }
 
 // ServerLockService allows servers in HA mode to claim a lock and execute a function if the server was granted the lock
 // It exposes 2 services LockAndExecute and LockExecuteAndRelease, which are intended to be used independently, don't mix
 // them up (ie, use the same actionName for both of them).
+// Long-running jobs should use leases instead, see AcquireLease and LeaseExecuteAndRelease.
 type ServerLockService struct {
 	SQLStore db.DB
 	tracer   tracing.Tracer
 	log      log.Logger
+	// identifies this server as the holder of the leases
+	holder string
 }
 
 // LockAndExecute try to create a lock for this server and only executes the
@@ -2,10 +2,13 @@ package serverlock
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
 )
 
 func TestIntegrationServerLock_LockAndExecute(t *testing.T) {
@@ -62,3 +65,166 @@ func TestIntegrationServerLock_LockExecuteAndRelease(t *testing.T) {
 
 	require.Equal(t, 4, counter)
 }
+
+func TestIntegrationServerLock_Lease(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sl := createTestableServerLock(t)
+	other := &ServerLockService{SQLStore: sl.SQLStore, tracer: sl.tracer, log: sl.log, holder: "other-holder"}
+	ctx := context.Background()
+
+	lease, err := sl.AcquireLease(ctx, "test-lease", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), lease.Token())
+
+	t.Run("a held lease can not be acquired", func(t *testing.T) {
+		_, err := other.AcquireLease(ctx, "test-lease", time.Hour)
+		var heldErr *LeaseHeldError
+		require.ErrorAs(t, err, &heldErr)
+		require.Equal(t, "test-holder", heldErr.holder)
+
+		leases, err := sl.ListLeases(ctx)
+		require.NoError(t, err)
+		require.Len(t, leases, 1)
+		require.Equal(t, "test-lease", leases[0].Name)
+		require.Equal(t, "test-holder", leases[0].Holder)
+		require.Equal(t, int64(1), leases[0].Token)
+	})
+
+	t.Run("a released lease is acquired with a higher token", func(t *testing.T) {
+		require.NoError(t, lease.Release(ctx))
+		require.ErrorIs(t, sl.ValidateFencingToken(ctx, "test-lease", 1), ErrStaleFencingToken)
+
+		otherLease, err := other.AcquireLease(ctx, "test-lease", time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), otherLease.Token())
+		require.NoError(t, sl.ValidateFencingToken(ctx, "test-lease", 2))
+
+		require.ErrorIs(t, lease.Renew(ctx), ErrLeaseLost)
+		require.NoError(t, otherLease.Release(ctx))
+
+		leases, err := sl.ListLeases(ctx)
+		require.NoError(t, err)
+		require.Empty(t, leases)
+	})
+
+	t.Run("an expired lease can be acquired by another holder", func(t *testing.T) {
+		expired, err := sl.AcquireLease(ctx, "test-lease", time.Millisecond)
+		require.NoError(t, err)
+		time.Sleep(10 * time.Millisecond)
+		require.ErrorIs(t, sl.ValidateFencingToken(ctx, "test-lease", expired.Token()), ErrStaleFencingToken)
+
+		otherLease, err := other.AcquireLease(ctx, "test-lease", time.Hour)
+		require.NoError(t, err)
+		require.Greater(t, otherLease.Token(), expired.Token())
+		require.ErrorIs(t, expired.Renew(ctx), ErrLeaseLost)
+		require.ErrorIs(t, expired.Release(ctx), ErrLeaseLost)
+		require.NoError(t, otherLease.Release(ctx))
+	})
+}
+
+func TestIntegrationServerLock_ValidateFencingTokenInSession(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sl := createTestableServerLock(t)
+	other := &ServerLockService{SQLStore: sl.SQLStore, tracer: sl.tracer, log: sl.log, holder: "other-holder"}
+	ctx := context.Background()
+
+	lease, err := sl.AcquireLease(ctx, "test-lease", 200*time.Millisecond)
+	require.NoError(t, err)
+
+	type acquireResult struct {
+		lease *Lease
+		err   error
+	}
+	acquired := make(chan acquireResult, 1)
+
+	err = sl.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
+		if err := ValidateFencingTokenInSession(dbSession, "test-lease", lease.Token()); err != nil {
+			return err
+		}
+		if _, err := dbSession.Insert(&serverLock{OperationUID: "fenced-write", Version: 1}); err != nil {
+			return err
+		}
+
+		// another server takes over the expired lease while the fenced writes are not committed yet
+		time.Sleep(time.Until(lease.Expires()) + 10*time.Millisecond)
+		go func() {
+			otherLease, err := other.AcquireLease(ctx, "test-lease", time.Hour)
+			acquired <- acquireResult{lease: otherLease, err: err}
+		}()
+
+		select {
+		case res := <-acquired:
+			// SQLite gives up on the locked database, the other databases wait for the commit
+			require.Error(t, res.err)
+			acquired <- res
+		case <-time.After(200 * time.Millisecond):
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	res := <-acquired
+	if res.err != nil {
+		// acquired again once the fenced writes are committed
+		res.lease, res.err = other.AcquireLease(ctx, "test-lease", time.Hour)
+	}
+	require.NoError(t, res.err)
+	require.Equal(t, lease.Token()+1, res.lease.Token())
+
+	require.ErrorIs(t, sl.ValidateFencingToken(ctx, "test-lease", lease.Token()), ErrStaleFencingToken)
+	require.NoError(t, sl.ValidateFencingToken(ctx, "test-lease", res.lease.Token()))
+}
+
+func TestIntegrationServerLock_LeaseExecuteAndRelease(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sl := createTestableServerLock(t)
+	other := &ServerLockService{SQLStore: sl.SQLStore, tracer: sl.tracer, log: sl.log, holder: "other-holder"}
+	ctx := context.Background()
+
+	t.Run("the lease is renewed while the function runs", func(t *testing.T) {
+		var token int64
+		err := sl.LeaseExecuteAndRelease(ctx, "test-job", 30*time.Millisecond, func(ctx context.Context, lease *Lease) {
+			token = lease.Token()
+			// longer than the ttl
+			time.Sleep(100 * time.Millisecond)
+			require.NoError(t, ctx.Err())
+
+			err := other.LeaseExecuteAndRelease(ctx, "test-job", time.Hour, func(context.Context, *Lease) {
+				require.Fail(t, "the lease is held")
+			})
+			var heldErr *LeaseHeldError
+			require.True(t, errors.As(err, &heldErr))
+		})
+		require.NoError(t, err)
+
+		// released
+		lease, err := other.AcquireLease(ctx, "test-job", time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, token+1, lease.Token())
+		require.NoError(t, lease.Release(ctx))
+	})
+
+	t.Run("the function is cancelled when the lease is lost", func(t *testing.T) {
+		err := sl.LeaseExecuteAndRelease(ctx, "test-job", 30*time.Millisecond, func(ctx context.Context, lease *Lease) {
+			// another server takes over the lease
+			err := sl.SQLStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+				_, err := dbSession.Exec("UPDATE server_lock_lease SET holder = ?, token = token + 1 WHERE name = ?", "other-holder", "test-job")
+				return err
+			})
+			require.NoError(t, err)
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+				require.Fail(t, "the context was not cancelled")
+			}
+		})
+		require.NoError(t, err)
+	})
+}
@@ -22,6 +22,7 @@ func createTestableServerLock(t *testing.T) *ServerLockService {
 		SQLStore: store,
 		tracer:   tracing.InitializeTracerForTest(),
 		log:      log.New("test-logger"),
+		holder:   "test-holder",
 	}
 }
 
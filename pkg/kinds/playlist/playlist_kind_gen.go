@@ -62,7 +62,7 @@ func NewKind(rt *thema.Runtime, opts ...thema.BindOption) (*Kind, error) {
 }
 
 // ConvergentLineage returns the same [thema.Lineage] as Lineage, but bound (see [thema.BindType])
-// to the the Playlist [Resource] type generated from the current schema, v0.0.
+// to the the Playlist [Resource] type generated from the current schema, v0.1.
 func (k *Kind) ConvergentLineage() thema.ConvergentLineage[*Resource] {
 	return k.lin
 }
@@ -11,13 +11,23 @@ package playlist
 
 // Defines values for ItemType.
 const (
-	ItemTypeDashboardById  ItemType = "dashboard_by_id"
-	ItemTypeDashboardByTag ItemType = "dashboard_by_tag"
-	ItemTypeDashboardByUid ItemType = "dashboard_by_uid"
+	ItemTypeDashboardById     ItemType = "dashboard_by_id"
+	ItemTypeDashboardBySearch ItemType = "dashboard_by_search"
+	ItemTypeDashboardByTag    ItemType = "dashboard_by_tag"
+	ItemTypeDashboardByUid    ItemType = "dashboard_by_uid"
 )
 
 // Item defines model for Item.
 type Item struct {
+	// Interval overrides the interval of the playlist for the dashboards of the item.
+	Interval *string `json:"interval,omitempty"`
+
+	// TimeFrom overrides the start of the time range of the dashboards, e.g. now-6h.
+	TimeFrom *string `json:"timeFrom,omitempty"`
+
+	// TimeTo overrides the end of the time range of the dashboards, e.g. now.
+	TimeTo *string `json:"timeTo,omitempty"`
+
 	// Title is an unused property -- it will be removed in the future
 	Title *string `json:"title,omitempty"`
 
@@ -32,7 +42,13 @@ type Item struct {
 	//  - dashboard_by_tag: The value is a tag which is set on any number of dashboards. All
 	//  dashboards behind the tag will be added to the playlist.
 	//  - dashboard_by_uid: The value is the dashboard UID
+	//  - dashboard_by_search: The value is a search query with the folderUIDs, tag, starred
+	//  and query parameters of the search API, e.g. folderUIDs=abc&tag=prod&starred=true.
+	//  The dashboards matching the search are resolved when the playlist is played.
 	Value string `json:"value"`
+
+	// Variables overrides the values of the template variables of the dashboards.
+	Variables map[string]string `json:"variables,omitempty"`
 }
 
 // Type of the item.
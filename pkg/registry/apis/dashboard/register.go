@@ -0,0 +1,105 @@
+package dashboard
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apiserver/pkg/registry/rest"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/kube-openapi/pkg/common"
+
+	"github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
+	grafanaapiserver "github.com/grafana/grafana/pkg/services/grafana-apiserver"
+	"github.com/grafana/grafana/pkg/services/grafana-apiserver/entitystorage"
+	"github.com/grafana/grafana/pkg/services/store/entity"
+)
+
+var _ grafanaapiserver.APIGroupBuilder = (*DashboardsAPIBuilder)(nil)
+
+// DashboardsAPIBuilder serves the dashboards of the entity store
+type DashboardsAPIBuilder struct {
+	store         entity.EntityStoreServer
+	accessControl accesscontrol.Service
+	folders       folder.Service
+}
+
+func RegisterAPIService(features featuremgmt.FeatureToggles, apiregistration grafanaapiserver.APIRegistrar, store entity.EntityStoreServer,
+	accessControl accesscontrol.Service, folders folder.Service) *DashboardsAPIBuilder {
+	if !features.IsEnabled(featuremgmt.FlagGrafanaAPIServer) || !features.IsEnabled(featuremgmt.FlagEntityStore) {
+		return nil // skip registration unless the apiserver and the entity store are enabled
+	}
+	builder := &DashboardsAPIBuilder{store: store, accessControl: accessControl, folders: folders}
+	apiregistration.RegisterAPI(builder)
+	return builder
+}
+
+func (b *DashboardsAPIBuilder) GetGroupVersion() schema.GroupVersion {
+	return v0alpha1.SchemeGroupVersion
+}
+
+func (b *DashboardsAPIBuilder) InstallSchema(scheme *runtime.Scheme) error {
+	return v0alpha1.AddToScheme(scheme)
+}
+
+func (b *DashboardsAPIBuilder) GetAPIGroupInfo(scheme *runtime.Scheme, codecs serializer.CodecFactory) (*genericapiserver.APIGroupInfo, error) {
+	apiGroupInfo := genericapiserver.NewDefaultAPIGroupInfo(v0alpha1.GroupName, scheme, metav1.ParameterCodec, codecs)
+
+	resource := v0alpha1.Resource("dashboards")
+	storage := map[string]rest.Storage{}
+	storage[resource.Resource] = &entitystorage.Storage{
+		Store:                     b.store,
+		Kind:                      entity.StandardKindDashboard,
+		NewFunc:                   func() runtime.Object { return &v0alpha1.Dashboard{} },
+		NewListFunc:               func() runtime.Object { return &v0alpha1.DashboardList{} },
+		DefaultQualifiedResource:  resource,
+		SingularQualifiedResource: v0alpha1.Resource("dashboard"),
+		ToObject: func(body []byte) (runtime.Object, error) {
+			return &v0alpha1.Dashboard{Spec: body}, nil
+		},
+		ToBody: func(obj runtime.Object) ([]byte, error) {
+			dash, ok := obj.(*v0alpha1.Dashboard)
+			if !ok {
+				return nil, fmt.Errorf("expected dashboard, found %T", obj)
+			}
+			if len(dash.Spec) == 0 {
+				return nil, fmt.Errorf("missing dashboard spec")
+			}
+			return dash.Spec, nil
+		},
+		TableConvertor: rest.NewDefaultTableConvertor(resource),
+		Access: &entitystorage.ResourceAccess{
+			AccessControl: b.accessControl,
+			Folders:       b.folders,
+			Evaluator:     evaluator,
+		},
+	}
+	apiGroupInfo.VersionedResourcesStorageMap[v0alpha1.SchemeGroupVersion.Version] = storage
+	return &apiGroupInfo, nil
+}
+
+func (b *DashboardsAPIBuilder) GetOpenAPIDefinitions() common.GetOpenAPIDefinitions {
+	return v0alpha1.GetOpenAPIDefinitions
+}
+
+// evaluator returns the permission of a verb on a dashboard, the dashboards are created with the permission of
+// their folder
+func evaluator(verb entitystorage.Verb, name string, folderScopes []string) accesscontrol.Evaluator {
+	scopes := append([]string{dashboards.ScopeDashboardsProvider.GetResourceScopeUID(name)}, folderScopes...)
+	switch verb {
+	case entitystorage.VerbCreate:
+		return accesscontrol.EvalPermission(dashboards.ActionDashboardsCreate, folderScopes...)
+	case entitystorage.VerbUpdate:
+		return accesscontrol.EvalPermission(dashboards.ActionDashboardsWrite, scopes...)
+	case entitystorage.VerbDelete:
+		return accesscontrol.EvalPermission(dashboards.ActionDashboardsDelete, scopes...)
+	default:
+		return accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, scopes...)
+	}
+}
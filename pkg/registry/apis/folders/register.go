@@ -0,0 +1,120 @@
+package folders
+
+import (
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apiserver/pkg/registry/rest"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/kube-openapi/pkg/common"
+
+	"github.com/grafana/grafana/pkg/apis/folder/v0alpha1"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	folderservice "github.com/grafana/grafana/pkg/services/folder"
+	grafanaapiserver "github.com/grafana/grafana/pkg/services/grafana-apiserver"
+	"github.com/grafana/grafana/pkg/services/grafana-apiserver/entitystorage"
+	"github.com/grafana/grafana/pkg/services/store/entity"
+	"github.com/grafana/grafana/pkg/services/store/kind/folder"
+)
+
+var _ grafanaapiserver.APIGroupBuilder = (*FoldersAPIBuilder)(nil)
+
+// FoldersAPIBuilder serves the folders of the entity store
+type FoldersAPIBuilder struct {
+	store         entity.EntityStoreServer
+	accessControl accesscontrol.Service
+	folders       folderservice.Service
+}
+
+func RegisterAPIService(features featuremgmt.FeatureToggles, apiregistration grafanaapiserver.APIRegistrar, store entity.EntityStoreServer,
+	accessControl accesscontrol.Service, folders folderservice.Service) *FoldersAPIBuilder {
+	if !features.IsEnabled(featuremgmt.FlagGrafanaAPIServer) || !features.IsEnabled(featuremgmt.FlagEntityStore) {
+		return nil // skip registration unless the apiserver and the entity store are enabled
+	}
+	builder := &FoldersAPIBuilder{store: store, accessControl: accessControl, folders: folders}
+	apiregistration.RegisterAPI(builder)
+	return builder
+}
+
+func (b *FoldersAPIBuilder) GetGroupVersion() schema.GroupVersion {
+	return v0alpha1.SchemeGroupVersion
+}
+
+func (b *FoldersAPIBuilder) InstallSchema(scheme *runtime.Scheme) error {
+	return v0alpha1.AddToScheme(scheme)
+}
+
+func (b *FoldersAPIBuilder) GetAPIGroupInfo(scheme *runtime.Scheme, codecs serializer.CodecFactory) (*genericapiserver.APIGroupInfo, error) {
+	apiGroupInfo := genericapiserver.NewDefaultAPIGroupInfo(v0alpha1.GroupName, scheme, metav1.ParameterCodec, codecs)
+
+	resource := v0alpha1.Resource("folders")
+	storage := map[string]rest.Storage{}
+	storage[resource.Resource] = &entitystorage.Storage{
+		Store:                     b.store,
+		Kind:                      entity.StandardKindFolder,
+		NewFunc:                   func() runtime.Object { return &v0alpha1.Folder{} },
+		NewListFunc:               func() runtime.Object { return &v0alpha1.FolderList{} },
+		DefaultQualifiedResource:  resource,
+		SingularQualifiedResource: v0alpha1.Resource("folder"),
+		ToObject:                  toFolder,
+		ToBody:                    toModel,
+		TableConvertor:            rest.NewDefaultTableConvertor(resource),
+		Access: &entitystorage.ResourceAccess{
+			AccessControl: b.accessControl,
+			Folders:       b.folders,
+			Evaluator:     evaluator,
+		},
+	}
+	apiGroupInfo.VersionedResourcesStorageMap[v0alpha1.SchemeGroupVersion.Version] = storage
+	return &apiGroupInfo, nil
+}
+
+func (b *FoldersAPIBuilder) GetOpenAPIDefinitions() common.GetOpenAPIDefinitions {
+	return v0alpha1.GetOpenAPIDefinitions
+}
+
+// evaluator returns the permission of a verb on a folder, the folder scopes are the scopes of its parents
+func evaluator(verb entitystorage.Verb, name string, folderScopes []string) accesscontrol.Evaluator {
+	scopes := append([]string{dashboards.ScopeFoldersProvider.GetResourceScopeUID(name)}, folderScopes...)
+	switch verb {
+	case entitystorage.VerbCreate:
+		return accesscontrol.EvalPermission(dashboards.ActionFoldersCreate)
+	case entitystorage.VerbUpdate:
+		return accesscontrol.EvalPermission(dashboards.ActionFoldersWrite, scopes...)
+	case entitystorage.VerbDelete:
+		return accesscontrol.EvalPermission(dashboards.ActionFoldersDelete, scopes...)
+	default:
+		return accesscontrol.EvalPermission(dashboards.ActionFoldersRead, scopes...)
+	}
+}
+
+// The folder entities use the folder kind model, with the title as name
+func toFolder(body []byte) (runtime.Object, error) {
+	model := &folder.Model{}
+	if err := json.Unmarshal(body, model); err != nil {
+		return nil, err
+	}
+	return &v0alpha1.Folder{
+		Spec: v0alpha1.Spec{
+			Title:       model.Name,
+			Description: model.Description,
+		},
+	}, nil
+}
+
+func toModel(obj runtime.Object) ([]byte, error) {
+	f, ok := obj.(*v0alpha1.Folder)
+	if !ok {
+		return nil, fmt.Errorf("expected folder, found %T", obj)
+	}
+	return json.Marshal(&folder.Model{
+		Name:        f.Spec.Title,
+		Description: f.Spec.Description,
+	})
+}
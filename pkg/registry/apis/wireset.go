@@ -0,0 +1,16 @@
+package apiregistry
+
+import (
+	"github.com/google/wire"
+
+	"github.com/grafana/grafana/pkg/registry/apis/dashboard"
+	"github.com/grafana/grafana/pkg/registry/apis/folders"
+)
+
+var WireSet = wire.NewSet(
+	ProvideRegistryServiceSink,
+
+	// Each must be added here *and* in the ServiceSink above
+	dashboard.RegisterAPIService,
+	folders.RegisterAPIService,
+)
@@ -0,0 +1,83 @@
+package annotations
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AggregateItems groups the annotations with the same dashboard, panel, alert, state and tags. Annotations are
+// grouped by buckets of the interval using their start time, except regions when mergeRegions is set: the
+// overlapping regions of a group are then merged into a single region. The aggregated items are sorted by time,
+// most recent first.
+func AggregateItems(items []*ItemDTO, interval int64, mergeRegions bool) []*AggregatedItemDTO {
+	sorted := make([]*ItemDTO, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Time != sorted[j].Time {
+			return sorted[i].Time < sorted[j].Time
+		}
+		return sorted[i].TimeEnd < sorted[j].TimeEnd
+	})
+
+	result := make([]*AggregatedItemDTO, 0)
+	buckets := make(map[string]*AggregatedItemDTO)
+	// the last merged region of each group, the only one the next regions can overlap since they are sorted
+	regions := make(map[string]*AggregatedItemDTO)
+
+	for _, item := range sorted {
+		tags := make([]string, len(item.Tags))
+		copy(tags, item.Tags)
+		sort.Strings(tags)
+		key := fmt.Sprintf("%d/%d/%d/%s/%s", item.DashboardID, item.PanelID, item.AlertID, item.NewState, strings.Join(tags, "\x00"))
+
+		timeEnd := item.TimeEnd
+		if timeEnd < item.Time {
+			timeEnd = item.Time
+		}
+
+		var group *AggregatedItemDTO
+		if mergeRegions && timeEnd > item.Time {
+			if region, ok := regions[key]; ok && item.Time <= region.TimeEnd {
+				group = region
+			}
+		} else {
+			bucket := item.Time
+			if interval > 0 {
+				bucket -= item.Time % interval
+			}
+			key = fmt.Sprintf("%s/%d", key, bucket)
+			group = buckets[key]
+		}
+
+		if group == nil {
+			group = &AggregatedItemDTO{
+				Time:         item.Time,
+				Tags:         tags,
+				AlertID:      item.AlertID,
+				AlertName:    item.AlertName,
+				NewState:     item.NewState,
+				DashboardID:  item.DashboardID,
+				DashboardUID: item.DashboardUID,
+				PanelID:      item.PanelID,
+			}
+			if mergeRegions && timeEnd > item.Time {
+				regions[key] = group
+			} else {
+				buckets[key] = group
+			}
+			result = append(result, group)
+		}
+
+		group.Count++
+		group.Text = item.Text
+		if timeEnd > group.TimeEnd {
+			group.TimeEnd = timeEnd
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time > result[j].Time
+	})
+	return result
+}
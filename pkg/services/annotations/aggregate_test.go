@@ -0,0 +1,93 @@
+package annotations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregateItems(t *testing.T) {
+	const minute = int64(60 * 1000)
+
+	t.Run("groups points by interval and tags", func(t *testing.T) {
+		items := []*ItemDTO{
+			{Time: 1 * minute, Text: "deploy 1", Tags: []string{"deploy", "api"}},
+			{Time: 3 * minute, Text: "deploy 2", Tags: []string{"api", "deploy"}},
+			{Time: 4 * minute, Text: "restart", Tags: []string{"restart"}},
+			{Time: 12 * minute, Text: "deploy 3", Tags: []string{"deploy", "api"}},
+		}
+
+		aggregated := AggregateItems(items, 10*minute, false)
+		require.Len(t, aggregated, 3)
+
+		require.Equal(t, &AggregatedItemDTO{Time: 12 * minute, TimeEnd: 12 * minute, Count: 1, Text: "deploy 3", Tags: []string{"api", "deploy"}}, aggregated[0])
+		// the text is the text of the most recent annotation of the group
+		require.Equal(t, &AggregatedItemDTO{Time: 1 * minute, TimeEnd: 3 * minute, Count: 2, Text: "deploy 2", Tags: []string{"api", "deploy"}}, aggregated[2])
+		require.Equal(t, "restart", aggregated[1].Text)
+	})
+
+	t.Run("groups alert annotations by alert and state", func(t *testing.T) {
+		items := make([]*ItemDTO, 0)
+		for i := int64(0); i < 10; i++ {
+			state := "Alerting"
+			if i%2 == 1 {
+				state = "Normal"
+			}
+			items = append(items, &ItemDTO{Time: i * minute, AlertID: 1, NewState: state, Text: "High latency"})
+		}
+		items = append(items, &ItemDTO{Time: 5 * minute, AlertID: 2, NewState: "Alerting", Text: "Disk full"})
+
+		aggregated := AggregateItems(items, 60*minute, false)
+		require.Len(t, aggregated, 3)
+		counts := map[string]int64{}
+		for _, item := range aggregated {
+			counts[item.Text+"/"+item.NewState] = item.Count
+		}
+		require.Equal(t, map[string]int64{"High latency/Alerting": 5, "High latency/Normal": 5, "Disk full/Alerting": 1}, counts)
+	})
+
+	t.Run("keeps dashboards and panels apart", func(t *testing.T) {
+		items := []*ItemDTO{
+			{Time: minute, DashboardID: 1, PanelID: 1},
+			{Time: minute, DashboardID: 1, PanelID: 2},
+			{Time: minute, DashboardID: 2, PanelID: 1},
+			{Time: minute, DashboardID: 2, PanelID: 1},
+		}
+		require.Len(t, AggregateItems(items, 10*minute, false), 3)
+	})
+
+	t.Run("merges overlapping regions", func(t *testing.T) {
+		items := []*ItemDTO{
+			{Time: 10 * minute, TimeEnd: 20 * minute, Text: "maintenance 2", Tags: []string{"maintenance"}},
+			{Time: 0, TimeEnd: 15 * minute, Text: "maintenance 1", Tags: []string{"maintenance"}},
+			{Time: 20 * minute, TimeEnd: 25 * minute, Text: "maintenance 3", Tags: []string{"maintenance"}},
+			{Time: 30 * minute, TimeEnd: 40 * minute, Text: "maintenance 4", Tags: []string{"maintenance"}},
+			{Time: 5 * minute, TimeEnd: 8 * minute, Text: "outage", Tags: []string{"outage"}},
+			{Time: 12 * minute, Text: "point", Tags: []string{"maintenance"}},
+		}
+
+		aggregated := AggregateItems(items, 60*minute, true)
+		require.Len(t, aggregated, 4)
+		require.Equal(t, &AggregatedItemDTO{Time: 30 * minute, TimeEnd: 40 * minute, Count: 1, Text: "maintenance 4", Tags: []string{"maintenance"}}, aggregated[0])
+		// points are still bucketed by interval
+		require.Equal(t, &AggregatedItemDTO{Time: 12 * minute, TimeEnd: 12 * minute, Count: 1, Text: "point", Tags: []string{"maintenance"}}, aggregated[1])
+		require.Equal(t, &AggregatedItemDTO{Time: 5 * minute, TimeEnd: 8 * minute, Count: 1, Text: "outage", Tags: []string{"outage"}}, aggregated[2])
+		require.Equal(t, &AggregatedItemDTO{Time: 0, TimeEnd: 25 * minute, Count: 3, Text: "maintenance 3", Tags: []string{"maintenance"}}, aggregated[3])
+	})
+
+	t.Run("buckets regions by start time without merging", func(t *testing.T) {
+		items := []*ItemDTO{
+			{Time: 0, TimeEnd: 15 * minute},
+			{Time: 10 * minute, TimeEnd: 20 * minute},
+		}
+		aggregated := AggregateItems(items, 60*minute, false)
+		require.Len(t, aggregated, 1)
+		require.Equal(t, int64(2), aggregated[0].Count)
+		require.Equal(t, 20*minute, aggregated[0].TimeEnd)
+	})
+
+	t.Run("no interval groups identical times", func(t *testing.T) {
+		items := []*ItemDTO{{Time: minute}, {Time: minute}, {Time: 2 * minute}}
+		require.Len(t, AggregateItems(items, 0, false), 2)
+	})
+}
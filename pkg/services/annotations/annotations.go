@@ -21,6 +21,7 @@ type Repository interface {
 	Find(ctx context.Context, query *ItemQuery) ([]*ItemDTO, error)
 	Delete(ctx context.Context, params *DeleteParams) error
 	FindTags(ctx context.Context, query *TagsQuery) (FindTagsResult, error)
+	Aggregate(ctx context.Context, query *AggregateQuery) (*AggregateResult, error)
 }
 
 // Cleaner is responsible for cleaning up old annotations
@@ -11,8 +11,12 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+// defaultAggregateScanLimit is the maximum number of annotations that are aggregated, the most recent ones
+const defaultAggregateScanLimit = 10000
+
 type RepositoryImpl struct {
-	store store
+	store              store
+	aggregateScanLimit int64
 }
 
 func ProvideService(db db.DB, cfg *setting.Cfg, features featuremgmt.FeatureToggles, tagService tag.Service) *RepositoryImpl {
@@ -25,6 +29,7 @@ func ProvideService(db db.DB, cfg *setting.Cfg, features featuremgmt.FeatureTogg
 			tagService:        tagService,
 			maximumTagsLength: cfg.AnnotationMaximumTagsLength,
 		},
+		aggregateScanLimit: defaultAggregateScanLimit,
 	}
 }
 
@@ -53,3 +58,35 @@ func (r *RepositoryImpl) Delete(ctx context.Context, params *annotations.DeleteP
 func (r *RepositoryImpl) FindTags(ctx context.Context, query *annotations.TagsQuery) (annotations.FindTagsResult, error) {
 	return r.store.GetTags(ctx, query)
 }
+
+// Aggregate groups the annotations matching the query. Unlike Find, the limit of the query applies to the aggregated
+// items, so that dense annotations, such as the state changes of alert rules, are summarized rather than cut.
+// Only the most recent annotations are aggregated, the result is flagged as truncated when older ones matched.
+func (r *RepositoryImpl) Aggregate(ctx context.Context, query *annotations.AggregateQuery) (*annotations.AggregateResult, error) {
+	itemQuery := query.ItemQuery
+	// one more annotation is read to tell whether the scan limit was reached
+	itemQuery.Limit = r.aggregateScanLimit + 1
+	items, err := r.store.Get(ctx, &itemQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	truncated := int64(len(items)) > r.aggregateScanLimit
+	if truncated {
+		items = items[:r.aggregateScanLimit]
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = 100
+	}
+	aggregated := annotations.AggregateItems(items, query.Interval, query.MergeRegions)
+	if int64(len(aggregated)) > limit {
+		aggregated = aggregated[:limit]
+	}
+	return &annotations.AggregateResult{
+		Items:     aggregated,
+		Scanned:   int64(len(items)),
+		Truncated: truncated,
+	}, nil
+}
@@ -0,0 +1,101 @@
+package annotationsimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestIntegrationAggregate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sql := db.InitTestDB(t)
+	sql.Cfg.AnnotationMaximumTagsLength = 60
+	repo := ProvideService(sql, sql.Cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sql, sql.Cfg))
+	ctx := context.Background()
+
+	const minute = int64(60 * 1000)
+	items := make([]annotations.Item, 0)
+	// state changes of an alert rule, as recorded by the annotation state history backend
+	for i := int64(1); i <= 150; i++ {
+		state := "Alerting"
+		if i%2 == 1 {
+			state = "Normal"
+		}
+		items = append(items, annotations.Item{OrgID: 1, AlertID: 7, NewState: state, Text: "High latency {instance=a} - B=1", Epoch: i * minute, EpochEnd: i * minute})
+	}
+	require.NoError(t, repo.SaveMany(ctx, items))
+	require.NoError(t, repo.Save(ctx, &annotations.Item{OrgID: 1, Text: "Maintenance", Tags: []string{"maintenance"}, Epoch: 10 * minute, EpochEnd: 40 * minute}))
+	require.NoError(t, repo.Save(ctx, &annotations.Item{OrgID: 1, Text: "Extended maintenance", Tags: []string{"maintenance"}, Epoch: 30 * minute, EpochEnd: 60 * minute}))
+
+	signedInUser := &user.SignedInUser{
+		OrgID: 1,
+		Permissions: map[int64]map[string][]string{
+			1: {accesscontrol.ActionAnnotationsRead: []string{accesscontrol.ScopeAnnotationsAll}},
+		},
+	}
+	query := &annotations.AggregateQuery{
+		ItemQuery: annotations.ItemQuery{OrgID: 1, From: 0, To: 200 * minute, SignedInUser: signedInUser},
+		Interval:  200 * minute,
+	}
+
+	found, err := repo.Find(ctx, &query.ItemQuery)
+	require.NoError(t, err)
+	require.Len(t, found, 100)
+
+	result, err := repo.Aggregate(ctx, query)
+	require.NoError(t, err)
+	require.Len(t, result.Items, 3)
+	require.Equal(t, int64(152), result.Scanned)
+	require.False(t, result.Truncated)
+	counts := map[string]int64{}
+	for _, item := range result.Items {
+		counts[item.NewState+"/"+item.Text] = item.Count
+	}
+	require.Equal(t, map[string]int64{
+		"Alerting/High latency {instance=a} - B=1": 75,
+		"Normal/High latency {instance=a} - B=1":   75,
+		"/Extended maintenance":                    2,
+	}, counts)
+
+	t.Run("merges regions", func(t *testing.T) {
+		query := *query
+		query.Type = "annotation"
+		query.MergeRegions = true
+
+		result, err := repo.Aggregate(ctx, &query)
+		require.NoError(t, err)
+		require.Len(t, result.Items, 1)
+		require.Equal(t, 10*minute, result.Items[0].Time)
+		require.Equal(t, 60*minute, result.Items[0].TimeEnd)
+		require.Equal(t, []string{"maintenance"}, result.Items[0].Tags)
+	})
+
+	t.Run("limits the aggregated items", func(t *testing.T) {
+		query := *query
+		query.Limit = 1
+
+		result, err := repo.Aggregate(ctx, &query)
+		require.NoError(t, err)
+		require.Len(t, result.Items, 1)
+	})
+
+	t.Run("flags the result as truncated when the scan limit is reached", func(t *testing.T) {
+		repo.aggregateScanLimit = 100
+		t.Cleanup(func() { repo.aggregateScanLimit = defaultAggregateScanLimit })
+
+		result, err := repo.Aggregate(ctx, query)
+		require.NoError(t, err)
+		require.Equal(t, int64(100), result.Scanned)
+		require.True(t, result.Truncated)
+	})
+}
@@ -83,6 +83,17 @@ func (repo *fakeAnnotationsRepo) FindTags(_ context.Context, query *annotations.
 	return result, nil
 }
 
+func (repo *fakeAnnotationsRepo) Aggregate(ctx context.Context, query *annotations.AggregateQuery) (*annotations.AggregateResult, error) {
+	items, err := repo.Find(ctx, &query.ItemQuery)
+	if err != nil {
+		return nil, err
+	}
+	return &annotations.AggregateResult{
+		Items:   annotations.AggregateItems(items, query.Interval, query.MergeRegions),
+		Scanned: int64(len(items)),
+	}, nil
+}
+
 func (repo *fakeAnnotationsRepo) Len() int {
 	repo.mtx.Lock()
 	defer repo.mtx.Unlock()
@@ -23,6 +23,17 @@ type ItemQuery struct {
 	Limit int64 `json:"limit"`
 }
 
+// AggregateQuery is the query for an aggregation of annotations. The annotations matching the ItemQuery are
+// grouped by dashboard, panel, alert, state and tags, and by time interval. The limit of the ItemQuery is the
+// maximum number of aggregated items.
+type AggregateQuery struct {
+	ItemQuery
+	// Interval is the size of the time buckets in milliseconds.
+	Interval int64 `json:"interval"`
+	// MergeRegions merges the overlapping regions of a group instead of bucketing them by start time.
+	MergeRegions bool `json:"mergeRegions"`
+}
+
 // TagsQuery is the query for a tags search.
 type TagsQuery struct {
 	OrgID int64  `json:"orgId"`
@@ -109,6 +120,35 @@ type ItemDTO struct {
 	Data         *simplejson.Json `json:"data"`
 }
 
+// AggregatedItemDTO is a group of annotations with the same dashboard, panel, alert, state and tags.
+type AggregatedItemDTO struct {
+	// Time is the time of the first annotation of the group.
+	Time int64 `json:"time"`
+	// TimeEnd is the end time of the last annotation of the group.
+	TimeEnd int64 `json:"timeEnd"`
+	// Count is the number of annotations of the group.
+	Count int64 `json:"count"`
+	// Text is the text of the most recent annotation of the group.
+	Text         string   `json:"text"`
+	Tags         []string `json:"tags"`
+	AlertID      int64    `json:"alertId"`
+	AlertName    string   `json:"alertName"`
+	NewState     string   `json:"newState"`
+	DashboardID  int64    `json:"dashboardId"`
+	DashboardUID *string  `json:"dashboardUID"`
+	PanelID      int64    `json:"panelId"`
+}
+
+// AggregateResult is the result of an aggregation of annotations.
+type AggregateResult struct {
+	Items []*AggregatedItemDTO `json:"items"`
+	// Scanned is the number of annotations that were aggregated.
+	Scanned int64 `json:"scanned"`
+	// Truncated is true when more annotations matched the query than could be aggregated, in which case the oldest
+	// annotations are not included.
+	Truncated bool `json:"truncated"`
+}
+
 type annotationType int
 
 const (
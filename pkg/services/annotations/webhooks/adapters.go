@@ -0,0 +1,210 @@
+package webhooks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// event is an event of a payload, before the mapping of the webhook is applied.
+type event struct {
+	// data is the JSON value the paths of the mapping are evaluated against
+	data    any
+	text    string
+	time    time.Time
+	timeEnd time.Time
+	tags    []string
+}
+
+// adapter extracts the events of a payload.
+type adapter func(payload any, mapping Mapping) ([]event, error)
+
+var adapters = map[string]adapter{
+	SourceGeneric:      genericEvents,
+	SourceAlertmanager: alertmanagerEvents,
+	SourceGit:          gitEvents,
+}
+
+// genericEvents returns an event per item of the payload, or the whole payload as a single event.
+// Everything else comes from the mapping.
+func genericEvents(payload any, mapping Mapping) ([]event, error) {
+	if mapping.Items == "" {
+		return []event{{data: payload}}, nil
+	}
+
+	items, err := selectPath(payload, mapping.Items)
+	if err != nil {
+		return nil, err
+	}
+	// a path selecting the list itself rather than its elements
+	if len(items) == 1 {
+		if list, ok := items[0].([]any); ok {
+			items = list
+		}
+	}
+
+	events := make([]event, 0, len(items))
+	for _, item := range items {
+		events = append(events, event{data: item})
+	}
+	return events, nil
+}
+
+// alertmanagerEvents returns an event per alert of an Alertmanager notification. Firing alerts are annotated
+// at their start time, and resolved alerts with a region from their start to their end.
+func alertmanagerEvents(payload any, _ Mapping) ([]event, error) {
+	alerts, ok := field(payload, "alerts").([]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: no alerts in alertmanager notification", ErrInvalidPayload)
+	}
+
+	events := make([]event, 0, len(alerts))
+	for _, alert := range alerts {
+		status := str(alert, "$.status")
+		name := str(alert, "$.labels.alertname")
+
+		text := fmt.Sprintf("[%s] %s", strings.ToUpper(status), name)
+		summary := str(alert, "$.annotations.summary")
+		if summary == "" {
+			summary = str(alert, "$.annotations.description")
+		}
+		if summary != "" {
+			text += ": " + summary
+		}
+
+		e := event{
+			data: alert,
+			text: text,
+			tags: []string{"alertmanager", "alertname:" + name, "status:" + status},
+		}
+		if severity := str(alert, "$.labels.severity"); severity != "" {
+			e.tags = append(e.tags, "severity:"+severity)
+		}
+
+		if startsAt, err := time.Parse(time.RFC3339Nano, str(alert, "$.startsAt")); err == nil {
+			e.time = startsAt
+		}
+		// firing alerts have a zero end time
+		if endsAt, err := time.Parse(time.RFC3339Nano, str(alert, "$.endsAt")); err == nil && status == "resolved" && endsAt.After(e.time) {
+			e.timeEnd = endsAt
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// gitEvents returns an event for the push and deployment events of GitHub and GitLab. Other events, such
+// as the ping events sent when a webhook is configured, are ignored.
+func gitEvents(payload any, _ Mapping) ([]event, error) {
+	if _, ok := payload.(map[string]any); !ok {
+		return nil, fmt.Errorf("%w: git event is not an object", ErrInvalidPayload)
+	}
+
+	switch {
+	case field(payload, "deployment") != nil:
+		return []event{githubDeployment(payload)}, nil
+	case str(payload, "$.object_kind") == "deployment":
+		return []event{gitlabDeployment(payload)}, nil
+	case str(payload, "$.ref") != "" && field(payload, "commits") != nil:
+		if deleted, _ := field(payload, "deleted").(bool); deleted {
+			return nil, nil
+		}
+		return []event{gitPush(payload)}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func githubDeployment(payload any) event {
+	environment := str(payload, "$.deployment.environment")
+	text := fmt.Sprintf("Deployment of %s to %s", str(payload, "$.deployment.ref"), environment)
+	if state := str(payload, "$.deployment_status.state"); state != "" {
+		text += " (" + state + ")"
+	}
+
+	e := event{
+		data: payload,
+		text: text,
+		tags: []string{"git", "deploy", "repo:" + str(payload, "$.repository.full_name"), "environment:" + environment},
+	}
+	for _, path := range []string{"$.deployment_status.updated_at", "$.deployment.created_at"} {
+		if t, err := time.Parse(time.RFC3339, str(payload, path)); err == nil {
+			e.time = t
+			break
+		}
+	}
+	return e
+}
+
+func gitlabDeployment(payload any) event {
+	environment := str(payload, "$.environment")
+	text := fmt.Sprintf("Deployment of %s to %s", str(payload, "$.ref"), environment)
+	if status := str(payload, "$.status"); status != "" {
+		text += " (" + status + ")"
+	}
+
+	e := event{
+		data: payload,
+		text: text,
+		tags: []string{"git", "deploy", "repo:" + str(payload, "$.project.path_with_namespace"), "environment:" + environment},
+	}
+	if t, err := time.Parse("2006-01-02 15:04:05 -0700", str(payload, "$.status_changed_at")); err == nil {
+		e.time = t
+	}
+	return e
+}
+
+// gitPush handles the push events of GitHub, which have a head commit, and GitLab, where the head commit is the
+// last commit.
+func gitPush(payload any) event {
+	repo := str(payload, "$.repository.full_name")
+	if repo == "" {
+		repo = str(payload, "$.project.path_with_namespace")
+	}
+	pusher := str(payload, "$.pusher.name")
+	if pusher == "" {
+		pusher = str(payload, "$.user_name")
+	}
+
+	ref := str(payload, "$.ref")
+	refTag := "branch:" + strings.TrimPrefix(ref, "refs/heads/")
+	if strings.HasPrefix(ref, "refs/tags/") {
+		refTag = "tag:" + strings.TrimPrefix(ref, "refs/tags/")
+	}
+
+	headCommit := field(payload, "head_commit")
+	if headCommit == nil {
+		if commits, ok := field(payload, "commits").([]any); ok && len(commits) > 0 {
+			headCommit = commits[len(commits)-1]
+		}
+	}
+
+	text := fmt.Sprintf("Push to %s %s by %s", repo, strings.SplitN(refTag, ":", 2)[1], pusher)
+	if message := strings.SplitN(str(headCommit, "$.message"), "\n", 2)[0]; message != "" {
+		text += ": " + message
+	}
+
+	e := event{
+		data: payload,
+		text: text,
+		tags: []string{"git", "push", "repo:" + repo, refTag},
+	}
+	if t, err := time.Parse(time.RFC3339, str(headCommit, "$.timestamp")); err == nil {
+		e.time = t
+	}
+	return e
+}
+
+// field returns the value of a key of an object, or nil.
+func field(data any, key string) any {
+	if obj, ok := data.(map[string]any); ok {
+		return obj[key]
+	}
+	return nil
+}
+
+// str returns the value selected by a valid path as a string.
+func str(data any, path string) string {
+	s, _ := selectString(data, path)
+	return s
+}
@@ -0,0 +1,141 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenericEvents(t *testing.T) {
+	payload := decode(t, `{"events": [{"msg": "a"}, {"msg": "b"}]}`)
+
+	events, err := genericEvents(payload, Mapping{})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, payload, events[0].data)
+
+	// the path can select either the list or its elements
+	for _, items := range []string{"$.events", "$.events[*]"} {
+		events, err = genericEvents(payload, Mapping{Items: items})
+		require.NoError(t, err)
+		require.Len(t, events, 2, items)
+		require.Equal(t, map[string]any{"msg": "b"}, events[1].data)
+	}
+}
+
+func TestAlertmanagerEvents(t *testing.T) {
+	payload := decode(t, `{
+		"status": "resolved",
+		"alerts": [
+			{
+				"status": "firing",
+				"labels": {"alertname": "HighLatency", "severity": "critical"},
+				"annotations": {"summary": "p99 above 1s"},
+				"startsAt": "2023-06-05T10:00:00Z",
+				"endsAt": "0001-01-01T00:00:00Z"
+			},
+			{
+				"status": "resolved",
+				"labels": {"alertname": "DiskFull"},
+				"annotations": {"description": "disk usage above 90%"},
+				"startsAt": "2023-06-05T09:00:00Z",
+				"endsAt": "2023-06-05T09:30:00Z"
+			}
+		]
+	}`)
+
+	events, err := alertmanagerEvents(payload, Mapping{})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+
+	require.Equal(t, "[FIRING] HighLatency: p99 above 1s", events[0].text)
+	require.Equal(t, []string{"alertmanager", "alertname:HighLatency", "status:firing", "severity:critical"}, events[0].tags)
+	require.Equal(t, time.Date(2023, 6, 5, 10, 0, 0, 0, time.UTC), events[0].time)
+	require.True(t, events[0].timeEnd.IsZero())
+
+	require.Equal(t, "[RESOLVED] DiskFull: disk usage above 90%", events[1].text)
+	require.Equal(t, time.Date(2023, 6, 5, 9, 30, 0, 0, time.UTC), events[1].timeEnd)
+
+	_, err = alertmanagerEvents(decode(t, `{"status": "firing"}`), Mapping{})
+	require.ErrorIs(t, err, ErrInvalidPayload)
+}
+
+func TestGitEvents(t *testing.T) {
+	t.Run("github push", func(t *testing.T) {
+		events, err := gitEvents(decode(t, `{
+			"ref": "refs/heads/main",
+			"repository": {"full_name": "grafana/app"},
+			"pusher": {"name": "octocat"},
+			"commits": [{"message": "first"}],
+			"head_commit": {"message": "Fix login\n\nLonger description", "timestamp": "2023-06-05T10:00:00Z"}
+		}`), Mapping{})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, "Push to grafana/app main by octocat: Fix login", events[0].text)
+		require.Equal(t, []string{"git", "push", "repo:grafana/app", "branch:main"}, events[0].tags)
+		require.Equal(t, time.Date(2023, 6, 5, 10, 0, 0, 0, time.UTC), events[0].time.UTC())
+	})
+
+	t.Run("gitlab tag push", func(t *testing.T) {
+		events, err := gitEvents(decode(t, `{
+			"object_kind": "tag_push",
+			"ref": "refs/tags/v1.0.0",
+			"user_name": "jdoe",
+			"project": {"path_with_namespace": "group/app"},
+			"commits": [{"message": "first"}, {"message": "Release v1.0.0"}]
+		}`), Mapping{})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, "Push to group/app v1.0.0 by jdoe: Release v1.0.0", events[0].text)
+		require.Contains(t, events[0].tags, "tag:v1.0.0")
+	})
+
+	t.Run("github deployment status", func(t *testing.T) {
+		events, err := gitEvents(decode(t, `{
+			"deployment": {"ref": "v1.0.0", "environment": "production", "created_at": "2023-06-05T09:00:00Z"},
+			"deployment_status": {"state": "success", "updated_at": "2023-06-05T10:00:00Z"},
+			"repository": {"full_name": "grafana/app"}
+		}`), Mapping{})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, "Deployment of v1.0.0 to production (success)", events[0].text)
+		require.Equal(t, []string{"git", "deploy", "repo:grafana/app", "environment:production"}, events[0].tags)
+		require.Equal(t, time.Date(2023, 6, 5, 10, 0, 0, 0, time.UTC), events[0].time.UTC())
+	})
+
+	t.Run("gitlab deployment", func(t *testing.T) {
+		events, err := gitEvents(decode(t, `{
+			"object_kind": "deployment",
+			"status": "success",
+			"ref": "main",
+			"environment": "staging",
+			"status_changed_at": "2023-06-05 10:00:00 +0000",
+			"project": {"path_with_namespace": "group/app"}
+		}`), Mapping{})
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		require.Equal(t, "Deployment of main to staging (success)", events[0].text)
+		require.Equal(t, time.Date(2023, 6, 5, 10, 0, 0, 0, time.UTC), events[0].time.UTC())
+	})
+
+	t.Run("ignored events", func(t *testing.T) {
+		for _, payload := range []string{
+			`{"zen": "Keep it logically awesome.", "hook_id": 1}`,
+			`{"ref": "refs/heads/old", "deleted": true, "commits": []}`,
+		} {
+			events, err := gitEvents(decode(t, payload), Mapping{})
+			require.NoError(t, err)
+			require.Empty(t, events)
+		}
+	})
+}
+
+func decode(t *testing.T, payload string) any {
+	t.Helper()
+
+	var data any
+	require.NoError(t, json.Unmarshal([]byte(payload), &data))
+	return data
+}
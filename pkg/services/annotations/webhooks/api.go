@@ -0,0 +1,308 @@
+package webhooks
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	uidScope := ScopeProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+	authorize := ac.Middleware(s.accessControl)
+
+	s.routeRegister.Group("/api/annotation-webhooks", func(webhooks routing.RouteRegister) {
+		webhooks.Get("/", authorize(ac.EvalPermission(ActionRead)), routing.Wrap(s.listHandler))
+		webhooks.Post("/", authorize(ac.EvalPermission(ActionCreate)), routing.Wrap(s.createHandler))
+		webhooks.Get("/:uid", authorize(ac.EvalPermission(ActionRead, uidScope)), routing.Wrap(s.getHandler))
+		webhooks.Put("/:uid", authorize(ac.EvalPermission(ActionWrite, uidScope)), routing.Wrap(s.updateHandler))
+		webhooks.Delete("/:uid", authorize(ac.EvalPermission(ActionDelete, uidScope)), routing.Wrap(s.deleteHandler))
+	}, middleware.ReqSignedIn)
+
+	// events are authenticated either with a service account token or with the secret of the webhook
+	s.routeRegister.Post("/api/annotation-webhooks/:uid/events", routing.Wrap(s.eventsHandler))
+}
+
+// swagger:route GET /annotation-webhooks annotation_webhooks listAnnotationWebhooks
+//
+// List the annotation webhooks of the organization.
+//
+// Responses:
+// 200: listAnnotationWebhooksResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) listHandler(c *contextmodel.ReqContext) response.Response {
+	webhooks, err := s.List(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list webhooks", err)
+	}
+
+	filtered := make([]WebhookDTO, 0, len(webhooks))
+	for _, w := range webhooks {
+		ok, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(ActionRead, ScopeProvider.GetResourceScopeUID(w.UID)))
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+		}
+		if ok {
+			filtered = append(filtered, w)
+		}
+	}
+
+	return response.JSON(http.StatusOK, filtered)
+}
+
+// swagger:route POST /annotation-webhooks annotation_webhooks createAnnotationWebhook
+//
+// Create an annotation webhook.
+//
+// The user creating the webhook needs to be able to add the annotations of its mapping.
+//
+// Responses:
+// 200: annotationWebhookResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateWebhookCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.CreatedBy = c.SignedInUser.UserID
+
+	if resp := s.checkAnnotationsAccess(c, cmd.Mapping); resp != nil {
+		return resp
+	}
+
+	webhook, err := s.Create(c.Req.Context(), cmd)
+	if err != nil {
+		return errorResponse("Failed to create webhook", err)
+	}
+
+	return response.JSON(http.StatusOK, webhook)
+}
+
+// swagger:route GET /annotation-webhooks/{uid} annotation_webhooks getAnnotationWebhook
+//
+// Get an annotation webhook.
+//
+// Responses:
+// 200: annotationWebhookResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getHandler(c *contextmodel.ReqContext) response.Response {
+	webhook, err := s.Get(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse("Failed to get webhook", err)
+	}
+
+	return response.JSON(http.StatusOK, webhook)
+}
+
+// swagger:route PUT /annotation-webhooks/{uid} annotation_webhooks updateAnnotationWebhook
+//
+// Update an annotation webhook.
+//
+// The secret is kept when the secret of the request is empty, unless removeSecret is set.
+//
+// Responses:
+// 200: annotationWebhookResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := UpdateWebhookCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UID = web.Params(c.Req)[":uid"]
+
+	if resp := s.checkAnnotationsAccess(c, cmd.Mapping); resp != nil {
+		return resp
+	}
+
+	webhook, err := s.Update(c.Req.Context(), cmd)
+	if err != nil {
+		return errorResponse("Failed to update webhook", err)
+	}
+
+	return response.JSON(http.StatusOK, webhook)
+}
+
+// swagger:route DELETE /annotation-webhooks/{uid} annotation_webhooks deleteAnnotationWebhook
+//
+// Delete an annotation webhook. The annotations it added are kept.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.Delete(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"]); err != nil {
+		return errorResponse("Failed to delete webhook", err)
+	}
+
+	return response.Success("Webhook deleted")
+}
+
+// swagger:route POST /annotation-webhooks/{uid}/events annotation_webhooks postAnnotationWebhookEvents
+//
+// Post events to an annotation webhook.
+//
+// The events are converted into annotations by the adapter of the source of the webhook and its mapping.
+// Requests are authenticated with the token of a service account that can add the annotations, or with the
+// secret of the webhook: with the HMAC-SHA256 signature of the body in the X-Grafana-Signature or
+// X-Hub-Signature-256 header, or with the secret in the X-Gitlab-Token header.
+//
+// The unknown webhooks are reported as unauthorized, unless the request is authenticated with a service account token.
+//
+// Responses:
+// 200: postAnnotationWebhookEventsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) eventsHandler(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+
+	body, err := io.ReadAll(io.LimitReader(c.Req.Body, maxPayloadSize+1))
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Failed to read payload", err)
+	}
+	if len(body) > maxPayloadSize {
+		return response.Error(http.StatusRequestEntityTooLarge, ErrPayloadTooLarge.Error(), nil)
+	}
+
+	webhook, err := s.store.GetByUID(ctx, web.Params(c.Req)[":uid"])
+	if errors.Is(err, ErrWebhookNotFound) {
+		// an unknown webhook is answered like a webhook without secret of another org, so that the callers that
+		// are not authenticated get the same 401 and cannot find the UIDs of the webhooks
+		webhook = &Webhook{}
+	} else if err != nil {
+		return errorResponse("Failed to get webhook", err)
+	}
+
+	var userID int64
+	signed, err := s.verifySecret(ctx, webhook, c.Req.Header, body)
+	switch {
+	case errors.Is(err, ErrInvalidSignature):
+		return response.Error(http.StatusUnauthorized, ErrInvalidSignature.Error(), nil)
+	case err != nil:
+		return response.Error(http.StatusInternalServerError, "Failed to verify signature", err)
+	case !signed:
+		if !c.IsSignedIn || !c.SignedInUser.IsServiceAccountUser() {
+			return response.Error(http.StatusUnauthorized, "A service account token or a signature is required", nil)
+		}
+		if c.SignedInUser.GetOrgID() != webhook.OrgID {
+			return response.Error(http.StatusNotFound, ErrWebhookNotFound.Error(), nil)
+		}
+		ok, err := s.canCreateAnnotations(ctx, c.SignedInUser, webhook.Mapping)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+		}
+		if !ok {
+			return response.Error(http.StatusForbidden, ErrDashboardNotAllowed.Error(), nil)
+		}
+		userID = c.SignedInUser.UserID
+	}
+
+	count, err := s.Ingest(ctx, webhook, userID, body)
+	if err != nil {
+		s.log.Warn("Failed to ingest webhook events", "webhook", webhook.UID, "orgId", webhook.OrgID, "error", err)
+		return errorResponse("Failed to add annotations", err)
+	}
+
+	return response.JSON(http.StatusOK, IngestResult{Message: "Annotations added", Count: count})
+}
+
+// checkAnnotationsAccess returns an error response when the signed in user cannot add the annotations of the
+// mapping, so that webhooks cannot be used to add annotations to dashboards their creator cannot edit.
+func (s *Service) checkAnnotationsAccess(c *contextmodel.ReqContext, mapping Mapping) response.Response {
+	ok, err := s.canCreateAnnotations(c.Req.Context(), c.SignedInUser, mapping)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+	}
+	if !ok {
+		return response.Error(http.StatusForbidden, ErrDashboardNotAllowed.Error(), nil)
+	}
+	return nil
+}
+
+func errorResponse(message string, err error) response.Response {
+	switch {
+	case errors.Is(err, ErrWebhookNotFound):
+		return response.Error(http.StatusNotFound, ErrWebhookNotFound.Error(), err)
+	case errors.Is(err, dashboards.ErrDashboardNotFound):
+		return response.Error(http.StatusNotFound, dashboards.ErrDashboardNotFound.Error(), err)
+	case errors.Is(err, ErrMissingName), errors.Is(err, ErrInvalidSource), errors.Is(err, ErrMissingText),
+		errors.Is(err, ErrInvalidDuration), errors.Is(err, ErrInvalidMapping), errors.Is(err, ErrInvalidPayload),
+		errors.Is(err, ErrTooManyEvents):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, message, err)
+}
+
+// swagger:parameters getAnnotationWebhook updateAnnotationWebhook deleteAnnotationWebhook postAnnotationWebhookEvents
+type AnnotationWebhookUIDParam struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters createAnnotationWebhook
+type CreateAnnotationWebhookParams struct {
+	// in:body
+	// required:true
+	Body WebhookSpec `json:"body"`
+}
+
+// swagger:parameters updateAnnotationWebhook
+type UpdateAnnotationWebhookParams struct {
+	// in:body
+	// required:true
+	Body UpdateWebhookCommand `json:"body"`
+}
+
+// swagger:parameters postAnnotationWebhookEvents
+type PostAnnotationWebhookEventsParams struct {
+	// Payload of the source of the webhook.
+	// in:body
+	// required:true
+	Body any `json:"body"`
+}
+
+// swagger:response listAnnotationWebhooksResponse
+type ListAnnotationWebhooksResponse struct {
+	// in:body
+	Body []WebhookDTO `json:"body"`
+}
+
+// swagger:response annotationWebhookResponse
+type AnnotationWebhookResponse struct {
+	// in:body
+	Body WebhookDTO `json:"body"`
+}
+
+// swagger:response postAnnotationWebhookEventsResponse
+type PostAnnotationWebhookEventsResponse struct {
+	// in:body
+	Body IngestResult `json:"body"`
+}
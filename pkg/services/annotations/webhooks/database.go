@@ -0,0 +1,88 @@
+package webhooks
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type store interface {
+	Get(ctx context.Context, orgID int64, uid string) (*Webhook, error)
+	// GetByUID returns a webhook of any organization, for the events that are authenticated with a signature.
+	GetByUID(ctx context.Context, uid string) (*Webhook, error)
+	List(ctx context.Context, orgID int64) ([]*Webhook, error)
+	Insert(ctx context.Context, webhook *Webhook) error
+	Update(ctx context.Context, webhook *Webhook) error
+	Delete(ctx context.Context, orgID int64, uid string) error
+}
+
+type dbStore struct {
+	db db.DB
+}
+
+func (s *dbStore) Get(ctx context.Context, orgID int64, uid string) (*Webhook, error) {
+	return s.get(ctx, "org_id = ? AND uid = ?", orgID, uid)
+}
+
+func (s *dbStore) GetByUID(ctx context.Context, uid string) (*Webhook, error) {
+	return s.get(ctx, "uid = ?", uid)
+}
+
+func (s *dbStore) get(ctx context.Context, query string, args ...any) (*Webhook, error) {
+	webhook := &Webhook{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where(query, args...).Get(webhook)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrWebhookNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (s *dbStore) List(ctx context.Context, orgID int64) ([]*Webhook, error) {
+	webhooks := make([]*Webhook, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("name").Find(&webhooks)
+	})
+	return webhooks, err
+}
+
+func (s *dbStore) Insert(ctx context.Context, webhook *Webhook) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(webhook)
+		return err
+	})
+}
+
+func (s *dbStore) Update(ctx context.Context, webhook *Webhook) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.ID(webhook.ID).AllCols().Update(webhook)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrWebhookNotFound
+		}
+		return nil
+	})
+}
+
+func (s *dbStore) Delete(ctx context.Context, orgID int64, uid string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&Webhook{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrWebhookNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,121 @@
+package webhooks
+
+import (
+	"errors"
+)
+
+// Sources of the events a webhook accepts. The source selects the adapter converting the payloads into annotations.
+const (
+	// SourceGeneric accepts any JSON payload, converted with the paths of the mapping.
+	SourceGeneric = "generic"
+	// SourceAlertmanager accepts the notifications of the Alertmanager webhook receiver.
+	SourceAlertmanager = "alertmanager"
+	// SourceGit accepts the push and deployment events of GitHub and GitLab.
+	SourceGit = "git"
+)
+
+var (
+	ErrWebhookNotFound     = errors.New("webhook not found")
+	ErrMissingName         = errors.New("webhook name is required")
+	ErrInvalidSource       = errors.New("invalid source, must be generic, alertmanager or git")
+	ErrMissingText         = errors.New("the mapping of a generic webhook requires a text")
+	ErrInvalidDuration     = errors.New("invalid duration")
+	ErrInvalidMapping      = errors.New("invalid mapping")
+	ErrInvalidSignature    = errors.New("invalid signature")
+	ErrInvalidPayload      = errors.New("invalid payload")
+	ErrPayloadTooLarge     = errors.New("payload too large")
+	ErrTooManyEvents       = errors.New("too many events in payload")
+	ErrDashboardNotAllowed = errors.New("not allowed to add annotations to the dashboard")
+)
+
+// Webhook receives events from an external system and adds them as annotations of an organization.
+type Webhook struct {
+	ID      int64   `xorm:"pk autoincr 'id'"`
+	UID     string  `xorm:"uid"`
+	OrgID   int64   `xorm:"org_id"`
+	Name    string  `xorm:"name"`
+	Source  string  `xorm:"source"`
+	Mapping Mapping `xorm:"jsonb mapping"`
+	// EncryptedSecret is the secret the payloads are signed with, encrypted with the secrets service.
+	EncryptedSecret []byte `xorm:"encrypted_secret"`
+	CreatedBy       int64  `xorm:"created_by"`
+	Created         int64  `xorm:"'created'"`
+	Updated         int64  `xorm:"'updated'"`
+}
+
+func (Webhook) TableName() string {
+	return "annotation_webhook"
+}
+
+// Mapping configures how the events of a payload are converted into annotations.
+//
+// Paths select values of the payload with a JSONPath-like syntax, such as `$.deploy.service`, `$.items[0]`,
+// `$.items[*].name` or `$['service name']`. Templates are strings where `{{ path }}` is replaced by the selected value.
+type Mapping struct {
+	// Items is the path of the list of events, for generic payloads with several events. The whole payload is
+	// a single event when empty.
+	Items string `json:"items,omitempty"`
+	// Text is a template of the annotation text. Defaults to a text built by the adapter for alertmanager and git webhooks.
+	Text string `json:"text,omitempty"`
+	// Time is the path of the annotation time, in epoch seconds, epoch milliseconds or RFC 3339. Defaults to the time
+	// of the event for alertmanager and git webhooks, and to the reception time otherwise.
+	Time string `json:"time,omitempty"`
+	// TimeEnd is the path of the end time, for region annotations.
+	TimeEnd string `json:"timeEnd,omitempty"`
+	// Duration makes region annotations of a fixed duration, such as `10m`, when no end time is selected.
+	Duration string `json:"duration,omitempty"`
+	// Tags are templates of tags added to the tags of the adapter. A tag that is only a path adds one tag
+	// per selected value.
+	Tags []string `json:"tags,omitempty"`
+	// DashboardUID scopes the annotations to a dashboard. The annotations are organization annotations when empty.
+	DashboardUID string `json:"dashboardUid,omitempty"`
+	// PanelID scopes the annotations to a panel of the dashboard.
+	PanelID int64 `json:"panelId,omitempty"`
+}
+
+// WebhookDTO is the API representation of a webhook. The secret is never returned.
+type WebhookDTO struct {
+	UID     string  `json:"uid"`
+	Name    string  `json:"name"`
+	Source  string  `json:"source"`
+	Mapping Mapping `json:"mapping"`
+	// HasSecret is true when the payloads can be signed with a secret instead of using a service account token.
+	HasSecret bool `json:"hasSecret"`
+	// URL is the URL the events are posted to.
+	URL       string `json:"url"`
+	CreatedBy int64  `json:"createdBy"`
+	Created   int64  `json:"created"`
+	Updated   int64  `json:"updated"`
+}
+
+// WebhookSpec contains the fields of a webhook that can be set by users.
+type WebhookSpec struct {
+	Name string `json:"name"`
+	// Source of the events: generic, alertmanager or git.
+	Source  string  `json:"source"`
+	Mapping Mapping `json:"mapping"`
+	// Secret the payloads are signed with using HMAC-SHA256. Payloads without signature must be posted with the
+	// token of a service account.
+	Secret string `json:"secret,omitempty"`
+}
+
+type CreateWebhookCommand struct {
+	WebhookSpec
+	OrgID     int64 `json:"-"`
+	CreatedBy int64 `json:"-"`
+}
+
+type UpdateWebhookCommand struct {
+	WebhookSpec
+	// RemoveSecret removes the secret of the webhook. The secret is kept when the secret of the command is empty.
+	RemoveSecret bool   `json:"removeSecret,omitempty"`
+	UID          string `json:"-"`
+	OrgID        int64  `json:"-"`
+}
+
+// IngestResult is the result of posting events to a webhook.
+type IngestResult struct {
+	Message string `json:"message"`
+	// Count is the number of annotations added.
+	Count int `json:"count"`
+}
@@ -0,0 +1,209 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// templateRegex matches the {{ path }} placeholders of templates
+var templateRegex = regexp.MustCompile(`\{\{\s*(\$[^}]*?)\s*\}\}`)
+
+// pathStep selects a key of an object, an index of an array, or all the elements of an array or object.
+type pathStep struct {
+	key   string
+	index int
+	all   bool
+}
+
+// parsePath parses a JSONPath-like expression such as $.a.b, $.a[0], $.a[*].b or $['a b'].
+func parsePath(path string) ([]pathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("path %q must start with $", path)
+	}
+
+	steps := make([]pathStep, 0)
+	rest := path[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".*"):
+			steps = append(steps, pathStep{all: true})
+			rest = rest[2:]
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("path %q has an empty key", path)
+			}
+			steps = append(steps, pathStep{key: key})
+			rest = rest[end+1:]
+		case rest[0] == '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("path %q has an unclosed bracket", path)
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+
+			if selector == "*" {
+				steps = append(steps, pathStep{all: true})
+				continue
+			}
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+				steps = append(steps, pathStep{key: selector[1 : len(selector)-1]})
+				continue
+			}
+			index, err := strconv.Atoi(selector)
+			if err != nil {
+				return nil, fmt.Errorf("path %q has an invalid selector %q", path, selector)
+			}
+			steps = append(steps, pathStep{key: "", index: index})
+		default:
+			return nil, fmt.Errorf("path %q is invalid at %q", path, rest)
+		}
+	}
+	return steps, nil
+}
+
+// selectPath returns the values of the data selected by the path. Missing values are skipped.
+func selectPath(data any, path string) ([]any, error) {
+	steps, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []any{data}
+	for _, step := range steps {
+		next := make([]any, 0, len(values))
+		for _, value := range values {
+			switch v := value.(type) {
+			case map[string]any:
+				if step.all {
+					for _, item := range v {
+						next = append(next, item)
+					}
+				} else if item, ok := v[step.key]; ok && step.key != "" {
+					next = append(next, item)
+				}
+			case []any:
+				if step.all {
+					next = append(next, v...)
+					continue
+				}
+				index := step.index
+				if index < 0 {
+					index += len(v)
+				}
+				if step.key == "" && index >= 0 && index < len(v) {
+					next = append(next, v[index])
+				}
+			}
+		}
+		values = next
+	}
+	return values, nil
+}
+
+// selectString returns the first value selected by the path as a string.
+func selectString(data any, path string) (string, error) {
+	values, err := selectPath(data, path)
+	if err != nil || len(values) == 0 {
+		return "", err
+	}
+	return valueString(values[0]), nil
+}
+
+// renderTemplate replaces the placeholders of the template with the selected values, or returns the selected values
+// when the template is only a path. Several values are separated by commas.
+func renderTemplate(data any, template string) (string, error) {
+	if strings.HasPrefix(template, "$") {
+		return joinSelected(data, template)
+	}
+
+	var renderErr error
+	rendered := templateRegex.ReplaceAllStringFunc(template, func(match string) string {
+		joined, err := joinSelected(data, templateRegex.FindStringSubmatch(match)[1])
+		if err != nil {
+			renderErr = err
+		}
+		return joined
+	})
+	return rendered, renderErr
+}
+
+func joinSelected(data any, path string) (string, error) {
+	values, err := selectPath(data, path)
+	if err != nil {
+		return "", err
+	}
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		parts = append(parts, valueString(v))
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// validateTemplate checks the paths of a template.
+func validateTemplate(template string) error {
+	if strings.HasPrefix(template, "$") {
+		_, err := parsePath(template)
+		return err
+	}
+	for _, match := range templateRegex.FindAllStringSubmatch(template, -1) {
+		if _, err := parsePath(match[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func valueString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
+
+// parseTime parses a time in epoch seconds, epoch milliseconds or RFC 3339. Epoch values below 1e11 are seconds.
+func parseTime(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case float64:
+		return epochTime(v), nil
+	case string:
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return epochTime(n), nil
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q", v)
+		}
+		return t, nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid time %v", value)
+	}
+}
+
+func epochTime(v float64) time.Time {
+	if v < 1e11 {
+		return time.UnixMilli(int64(v * 1000))
+	}
+	return time.UnixMilli(int64(v))
+}
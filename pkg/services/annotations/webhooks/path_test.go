@@ -0,0 +1,90 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectPath(t *testing.T) {
+	var data any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"service": "api",
+		"deploy": {"version": "1.2.3", "replicas": 3},
+		"items": [{"name": "a"}, {"name": "b"}],
+		"service name": "checkout"
+	}`), &data))
+
+	tests := map[string]struct {
+		path     string
+		expected []any
+	}{
+		"root key":        {"$.service", []any{"api"}},
+		"nested key":      {"$.deploy.version", []any{"1.2.3"}},
+		"number":          {"$.deploy.replicas", []any{float64(3)}},
+		"index":           {"$.items[1].name", []any{"b"}},
+		"negative index":  {"$.items[-1].name", []any{"b"}},
+		"wildcard":        {"$.items[*].name", []any{"a", "b"}},
+		"quoted key":      {"$['service name']", []any{"checkout"}},
+		"missing key":     {"$.missing.key", []any{}},
+		"index of object": {"$.deploy[0]", []any{}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			values, err := selectPath(data, tt.path)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, values)
+		})
+	}
+
+	for _, path := range []string{"service", "$.", "$.items[0", "$.items[first]", "$service"} {
+		_, err := parsePath(path)
+		require.Error(t, err, path)
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	data := map[string]any{
+		"service": "api",
+		"version": float64(12),
+		"hosts":   []any{"a", "b"},
+	}
+
+	rendered, err := renderTemplate(data, "Deployed {{ $.service }} v{{$.version}} to {{ $.hosts[*] }}")
+	require.NoError(t, err)
+	require.Equal(t, "Deployed api v12 to a, b", rendered)
+
+	rendered, err = renderTemplate(data, "$.service")
+	require.NoError(t, err)
+	require.Equal(t, "api", rendered)
+
+	rendered, err = renderTemplate(data, "no placeholders")
+	require.NoError(t, err)
+	require.Equal(t, "no placeholders", rendered)
+
+	require.NoError(t, validateTemplate("{{ $.a }} and {{ $['b c'] }}"))
+	require.Error(t, validateTemplate("{{ $.a[ }}"))
+}
+
+func TestParseTime(t *testing.T) {
+	expected := time.Date(2023, 6, 5, 10, 0, 0, 0, time.UTC)
+
+	for _, value := range []any{
+		float64(expected.Unix()),
+		float64(expected.UnixMilli()),
+		"1685959200",
+		"2023-06-05T10:00:00Z",
+		"2023-06-05T12:00:00+02:00",
+	} {
+		parsed, err := parseTime(value)
+		require.NoError(t, err, value)
+		require.Equal(t, expected.UnixMilli(), parsed.UnixMilli(), value)
+	}
+
+	_, err := parseTime("yesterday")
+	require.Error(t, err)
+	_, err = parseTime(true)
+	require.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package webhooks
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	ActionRead   = "annotations.webhooks:read"
+	ActionCreate = "annotations.webhooks:create"
+	ActionWrite  = "annotations.webhooks:write"
+	ActionDelete = "annotations.webhooks:delete"
+)
+
+var (
+	ScopeProvider = accesscontrol.NewScopeProvider("annotations.webhooks")
+	ScopeAll      = ScopeProvider.GetResourceAllScope()
+)
+
+var (
+	webhookReaderRole = accesscontrol.RoleDTO{
+		Name:        "fixed:annotations.webhooks:reader",
+		DisplayName: "Annotation webhook reader",
+		Description: "List annotation webhooks",
+		Group:       "Annotations",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionRead, Scope: ScopeAll},
+		},
+	}
+
+	webhookWriterRole = accesscontrol.RoleDTO{
+		Name:        "fixed:annotations.webhooks:writer",
+		DisplayName: "Annotation webhook writer",
+		Description: "Create, update and delete annotation webhooks",
+		Group:       "Annotations",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionRead, Scope: ScopeAll},
+			{Action: ActionCreate},
+			{Action: ActionWrite, Scope: ScopeAll},
+			{Action: ActionDelete, Scope: ScopeAll},
+		},
+	}
+)
+
+func declareFixedRoles(service accesscontrol.Service) error {
+	webhookReader := accesscontrol.RoleRegistration{
+		Role:   webhookReaderRole,
+		Grants: []string{string(org.RoleAdmin)},
+	}
+	webhookWriter := accesscontrol.RoleRegistration{
+		Role:   webhookWriterRole,
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	return service.DeclareFixedRoles(webhookReader, webhookWriter)
+}
@@ -0,0 +1,420 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// maxPayloadSize is the maximum size of the payloads posted to a webhook
+	maxPayloadSize = 1 << 20
+	// maxEvents is the maximum number of annotations added from a single payload
+	maxEvents = 1000
+)
+
+type Service struct {
+	cfg              *setting.Cfg
+	store            store
+	routeRegister    routing.RouteRegister
+	accessControl    accesscontrol.AccessControl
+	annotationsRepo  annotations.Repository
+	dashboardService dashboards.DashboardService
+	secretsService   secrets.Service
+	log              log.Logger
+	now              func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, ac accesscontrol.AccessControl,
+	acService accesscontrol.Service, annotationsRepo annotations.Repository, dashboardService dashboards.DashboardService,
+	secretsService secrets.Service,
+) (*Service, error) {
+	s := &Service{
+		cfg:              cfg,
+		store:            &dbStore{db: sqlStore},
+		routeRegister:    routeRegister,
+		accessControl:    ac,
+		annotationsRepo:  annotationsRepo,
+		dashboardService: dashboardService,
+		secretsService:   secretsService,
+		log:              log.New("annotations.webhooks"),
+		now:              time.Now,
+	}
+
+	if err := declareFixedRoles(acService); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints()
+
+	return s, nil
+}
+
+func (s *Service) Get(ctx context.Context, orgID int64, uid string) (WebhookDTO, error) {
+	webhook, err := s.store.Get(ctx, orgID, uid)
+	if err != nil {
+		return WebhookDTO{}, err
+	}
+	return s.toDTO(webhook), nil
+}
+
+func (s *Service) List(ctx context.Context, orgID int64) ([]WebhookDTO, error) {
+	webhooks, err := s.store.List(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	dtos := make([]WebhookDTO, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		dtos = append(dtos, s.toDTO(webhook))
+	}
+	return dtos, nil
+}
+
+func (s *Service) Create(ctx context.Context, cmd CreateWebhookCommand) (WebhookDTO, error) {
+	if err := s.validate(ctx, cmd.OrgID, &cmd.WebhookSpec); err != nil {
+		return WebhookDTO{}, err
+	}
+
+	now := s.now().UnixMilli()
+	webhook := &Webhook{
+		UID:       util.GenerateShortUID(),
+		OrgID:     cmd.OrgID,
+		Name:      cmd.Name,
+		Source:    cmd.Source,
+		Mapping:   cmd.Mapping,
+		CreatedBy: cmd.CreatedBy,
+		Created:   now,
+		Updated:   now,
+	}
+	if err := s.setSecret(ctx, webhook, cmd.Secret); err != nil {
+		return WebhookDTO{}, err
+	}
+
+	if err := s.store.Insert(ctx, webhook); err != nil {
+		return WebhookDTO{}, err
+	}
+	return s.toDTO(webhook), nil
+}
+
+func (s *Service) Update(ctx context.Context, cmd UpdateWebhookCommand) (WebhookDTO, error) {
+	webhook, err := s.store.Get(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return WebhookDTO{}, err
+	}
+	if err := s.validate(ctx, cmd.OrgID, &cmd.WebhookSpec); err != nil {
+		return WebhookDTO{}, err
+	}
+
+	webhook.Name = cmd.Name
+	webhook.Source = cmd.Source
+	webhook.Mapping = cmd.Mapping
+	webhook.Updated = s.now().UnixMilli()
+	if cmd.RemoveSecret {
+		webhook.EncryptedSecret = nil
+	}
+	if err := s.setSecret(ctx, webhook, cmd.Secret); err != nil {
+		return WebhookDTO{}, err
+	}
+
+	if err := s.store.Update(ctx, webhook); err != nil {
+		return WebhookDTO{}, err
+	}
+	return s.toDTO(webhook), nil
+}
+
+func (s *Service) Delete(ctx context.Context, orgID int64, uid string) error {
+	return s.store.Delete(ctx, orgID, uid)
+}
+
+// Ingest converts the events of a payload into annotations and adds them. The annotations are added as the
+// given user, or without user when the payload is authenticated with a signature.
+func (s *Service) Ingest(ctx context.Context, webhook *Webhook, userID int64, body []byte) (int, error) {
+	var payload any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidPayload, err)
+	}
+
+	items, err := s.convert(ctx, webhook, payload)
+	if err != nil {
+		return 0, err
+	}
+	for i := range items {
+		items[i].UserID = userID
+	}
+
+	if err := s.annotationsRepo.SaveMany(ctx, items); err != nil {
+		return 0, err
+	}
+	return len(items), nil
+}
+
+// convert applies the adapter of the source and the mapping of the webhook to a payload.
+func (s *Service) convert(ctx context.Context, webhook *Webhook, payload any) ([]annotations.Item, error) {
+	mapping := webhook.Mapping
+	events, err := adapters[webhook.Source](payload, mapping)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > maxEvents {
+		return nil, fmt.Errorf("%w: %d events, the maximum is %d", ErrTooManyEvents, len(events), maxEvents)
+	}
+	if len(events) == 0 {
+		return []annotations.Item{}, nil
+	}
+
+	var dashboardID int64
+	if mapping.DashboardUID != "" {
+		dashboard, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: webhook.OrgID, UID: mapping.DashboardUID})
+		if err != nil {
+			return nil, err
+		}
+		dashboardID = dashboard.ID
+	}
+
+	var duration time.Duration
+	if mapping.Duration != "" {
+		// the duration is validated when saving the webhook
+		duration, _ = time.ParseDuration(mapping.Duration)
+	}
+
+	now := s.now()
+	items := make([]annotations.Item, 0, len(events))
+	for i, e := range events {
+		if err := applyMapping(&e, mapping); err != nil {
+			return nil, fmt.Errorf("%w: event %d: %s", ErrInvalidPayload, i, err)
+		}
+		if e.text == "" {
+			return nil, fmt.Errorf("%w: event %d has no text", ErrInvalidPayload, i)
+		}
+
+		if e.time.IsZero() {
+			e.time = now
+		}
+		if e.timeEnd.IsZero() && duration > 0 {
+			e.timeEnd = e.time.Add(duration)
+		}
+
+		item := annotations.Item{
+			OrgID:       webhook.OrgID,
+			DashboardID: dashboardID,
+			PanelID:     mapping.PanelID,
+			Text:        e.text,
+			Epoch:       e.time.UnixMilli(),
+			Tags:        e.tags,
+		}
+		if !e.timeEnd.IsZero() {
+			item.EpochEnd = e.timeEnd.UnixMilli()
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// applyMapping overrides the text and times of the event with the values selected by the mapping, and adds its tags.
+func applyMapping(e *event, mapping Mapping) error {
+	if mapping.Text != "" {
+		text, err := renderTemplate(e.data, mapping.Text)
+		if err != nil {
+			return err
+		}
+		e.text = text
+	}
+
+	for _, t := range []struct {
+		path   string
+		target *time.Time
+	}{{mapping.Time, &e.time}, {mapping.TimeEnd, &e.timeEnd}} {
+		if t.path == "" {
+			continue
+		}
+		values, err := selectPath(e.data, t.path)
+		if err != nil {
+			return err
+		}
+		if len(values) == 0 {
+			continue
+		}
+		parsed, err := parseTime(values[0])
+		if err != nil {
+			return err
+		}
+		*t.target = parsed
+	}
+
+	seen := make(map[string]bool, len(e.tags))
+	tags := make([]string, 0, len(e.tags)+len(mapping.Tags))
+	add := func(tag string) {
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	for _, tag := range e.tags {
+		add(tag)
+	}
+	for _, tag := range mapping.Tags {
+		// a tag that is only a path adds a tag per selected value
+		if strings.HasPrefix(tag, "$") {
+			values, err := selectPath(e.data, tag)
+			if err != nil {
+				return err
+			}
+			for _, v := range values {
+				if list, ok := v.([]any); ok {
+					for _, item := range list {
+						add(valueString(item))
+					}
+					continue
+				}
+				add(valueString(v))
+			}
+			continue
+		}
+
+		rendered, err := renderTemplate(e.data, tag)
+		if err != nil {
+			return err
+		}
+		add(rendered)
+	}
+	e.tags = tags
+	return nil
+}
+
+// verifySecret checks that a request is authenticated with the secret of the webhook: with the HMAC-SHA256 signature
+// of the payload in the X-Grafana-Signature or X-Hub-Signature-256 header, or with the secret itself in the
+// X-Gitlab-Token header since GitLab doesn't sign payloads. It returns false when the request has none of these headers.
+func (s *Service) verifySecret(ctx context.Context, webhook *Webhook, header http.Header, body []byte) (bool, error) {
+	signature := header.Get("X-Grafana-Signature")
+	if signature == "" {
+		signature = header.Get("X-Hub-Signature-256")
+	}
+	token := header.Get("X-Gitlab-Token")
+	if signature == "" && token == "" {
+		return false, nil
+	}
+
+	if len(webhook.EncryptedSecret) == 0 {
+		return true, ErrInvalidSignature
+	}
+	secret, err := s.secretsService.Decrypt(ctx, webhook.EncryptedSecret)
+	if err != nil {
+		return true, err
+	}
+
+	if signature == "" {
+		if subtle.ConstantTimeCompare([]byte(token), secret) != 1 {
+			return true, ErrInvalidSignature
+		}
+		return true, nil
+	}
+
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return true, ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return true, ErrInvalidSignature
+	}
+	return true, nil
+}
+
+// canCreateAnnotations checks that a user can add the annotations of a mapping, which requires to be able to
+// write the dashboard for dashboard annotations.
+func (s *Service) canCreateAnnotations(ctx context.Context, user identity.Requester, mapping Mapping) (bool, error) {
+	if mapping.DashboardUID == "" {
+		return s.accessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(accesscontrol.ActionAnnotationsCreate, accesscontrol.ScopeAnnotationsTypeOrganization))
+	}
+
+	return s.accessControl.Evaluate(ctx, user, accesscontrol.EvalAll(
+		accesscontrol.EvalPermission(accesscontrol.ActionAnnotationsCreate, accesscontrol.ScopeAnnotationsTypeDashboard),
+		accesscontrol.EvalPermission(dashboards.ActionDashboardsWrite, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(mapping.DashboardUID)),
+	))
+}
+
+func (s *Service) validate(ctx context.Context, orgID int64, spec *WebhookSpec) error {
+	if spec.Name == "" {
+		return ErrMissingName
+	}
+	if _, ok := adapters[spec.Source]; !ok {
+		return ErrInvalidSource
+	}
+
+	mapping := spec.Mapping
+	if spec.Source == SourceGeneric && mapping.Text == "" {
+		return ErrMissingText
+	}
+	if mapping.Duration != "" {
+		if d, err := time.ParseDuration(mapping.Duration); err != nil || d <= 0 {
+			return ErrInvalidDuration
+		}
+	}
+
+	for _, path := range []string{mapping.Items, mapping.Time, mapping.TimeEnd} {
+		if path == "" {
+			continue
+		}
+		if _, err := parsePath(path); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidMapping, err)
+		}
+	}
+	for _, template := range append([]string{mapping.Text}, mapping.Tags...) {
+		if err := validateTemplate(template); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidMapping, err)
+		}
+	}
+
+	if mapping.DashboardUID != "" {
+		if _, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: orgID, UID: mapping.DashboardUID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) setSecret(ctx context.Context, webhook *Webhook, secret string) error {
+	if secret == "" {
+		return nil
+	}
+
+	encrypted, err := s.secretsService.Encrypt(ctx, []byte(secret), secrets.WithoutScope())
+	if err != nil {
+		return err
+	}
+	webhook.EncryptedSecret = encrypted
+	return nil
+}
+
+func (s *Service) toDTO(webhook *Webhook) WebhookDTO {
+	return WebhookDTO{
+		UID:       webhook.UID,
+		Name:      webhook.Name,
+		Source:    webhook.Source,
+		Mapping:   webhook.Mapping,
+		HasSecret: len(webhook.EncryptedSecret) > 0,
+		URL:       fmt.Sprintf("%sapi/annotation-webhooks/%s/events", s.cfg.AppURL, webhook.UID),
+		CreatedBy: webhook.CreatedBy,
+		Created:   webhook.Created,
+		Updated:   webhook.Updated,
+	}
+}
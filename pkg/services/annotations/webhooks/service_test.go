@@ -0,0 +1,374 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestService_Create(t *testing.T) {
+	valid := WebhookSpec{
+		Name:    "Deployments",
+		Source:  SourceGeneric,
+		Mapping: Mapping{Text: "Deployed {{ $.service }}"},
+		Secret:  "s3cr3t",
+	}
+
+	t.Run("creates webhook", func(t *testing.T) {
+		s, st, _ := setupTestService(t)
+
+		webhook, err := s.Create(context.Background(), CreateWebhookCommand{WebhookSpec: valid, OrgID: 1, CreatedBy: 2})
+		require.NoError(t, err)
+		require.NotEmpty(t, webhook.UID)
+		require.True(t, webhook.HasSecret)
+		require.Equal(t, "http://localhost:3000/api/annotation-webhooks/"+webhook.UID+"/events", webhook.URL)
+		require.Equal(t, []byte("s3cr3t"), st.webhooks[webhook.UID].EncryptedSecret)
+	})
+
+	invalid := map[string]struct {
+		modify func(*WebhookSpec)
+		err    error
+	}{
+		"missing name":        {func(s *WebhookSpec) { s.Name = "" }, ErrMissingName},
+		"invalid source":      {func(s *WebhookSpec) { s.Source = "jenkins" }, ErrInvalidSource},
+		"missing text":        {func(s *WebhookSpec) { s.Mapping.Text = "" }, ErrMissingText},
+		"invalid duration":    {func(s *WebhookSpec) { s.Mapping.Duration = "-5m" }, ErrInvalidDuration},
+		"invalid path":        {func(s *WebhookSpec) { s.Mapping.Time = "time" }, ErrInvalidMapping},
+		"invalid tag":         {func(s *WebhookSpec) { s.Mapping.Tags = []string{"{{ $.a[ }}"} }, ErrInvalidMapping},
+		"dashboard not found": {func(s *WebhookSpec) { s.Mapping.DashboardUID = "missing" }, dashboards.ErrDashboardNotFound},
+	}
+	for name, tt := range invalid {
+		t.Run(name, func(t *testing.T) {
+			s, _, _ := setupTestService(t)
+
+			spec := valid
+			tt.modify(&spec)
+			_, err := s.Create(context.Background(), CreateWebhookCommand{WebhookSpec: spec, OrgID: 1})
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	t.Run("alertmanager and git webhooks don't require a text", func(t *testing.T) {
+		s, _, _ := setupTestService(t)
+
+		for _, source := range []string{SourceAlertmanager, SourceGit} {
+			_, err := s.Create(context.Background(), CreateWebhookCommand{WebhookSpec: WebhookSpec{Name: source, Source: source}, OrgID: 1})
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestService_Update(t *testing.T) {
+	s, st, _ := setupTestService(t)
+	ctx := context.Background()
+
+	created, err := s.Create(ctx, CreateWebhookCommand{WebhookSpec: WebhookSpec{Name: "Alerts", Source: SourceAlertmanager, Secret: "s3cr3t"}, OrgID: 1})
+	require.NoError(t, err)
+
+	// the secret is kept when not set
+	updated, err := s.Update(ctx, UpdateWebhookCommand{WebhookSpec: WebhookSpec{Name: "Production alerts", Source: SourceAlertmanager}, UID: created.UID, OrgID: 1})
+	require.NoError(t, err)
+	require.Equal(t, "Production alerts", updated.Name)
+	require.True(t, updated.HasSecret)
+
+	updated, err = s.Update(ctx, UpdateWebhookCommand{WebhookSpec: WebhookSpec{Name: "Alerts", Source: SourceAlertmanager}, RemoveSecret: true, UID: created.UID, OrgID: 1})
+	require.NoError(t, err)
+	require.False(t, updated.HasSecret)
+	require.Empty(t, st.webhooks[created.UID].EncryptedSecret)
+
+	_, err = s.Update(ctx, UpdateWebhookCommand{WebhookSpec: WebhookSpec{Name: "Alerts", Source: SourceAlertmanager}, UID: created.UID, OrgID: 2})
+	require.ErrorIs(t, err, ErrWebhookNotFound)
+}
+
+func TestService_Ingest(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("applies the mapping", func(t *testing.T) {
+		s, _, repo := setupTestService(t)
+		webhook := &Webhook{OrgID: 1, Source: SourceGeneric, Mapping: Mapping{
+			Items:        "$.deploys",
+			Text:         "Deployed {{ $.service }} {{ $.version }}",
+			Time:         "$.at",
+			Duration:     "10m",
+			Tags:         []string{"deploy", "$.labels", "env:{{ $.env }}"},
+			DashboardUID: "dash",
+			PanelID:      4,
+		}}
+
+		count, err := s.Ingest(ctx, webhook, 5, []byte(`{"deploys": [
+			{"service": "api", "version": "1.2.3", "at": 1685959200, "env": "prod", "labels": ["backend", "deploy"]},
+			{"service": "web", "version": "2.0.0", "env": "prod"}
+		]}`))
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+
+		items := sortedItems(repo)
+		require.Len(t, items, 2)
+		require.Equal(t, "Deployed api 1.2.3", items[0].Text)
+		require.Equal(t, int64(1685959200000), items[0].Epoch)
+		require.Equal(t, int64(1685959200000+10*60*1000), items[0].EpochEnd)
+		require.Equal(t, []string{"deploy", "backend", "env:prod"}, items[0].Tags)
+		require.Equal(t, int64(10), items[0].DashboardID)
+		require.Equal(t, int64(4), items[0].PanelID)
+		require.Equal(t, int64(5), items[0].UserID)
+
+		// events without time are annotated at the reception time
+		require.Equal(t, s.now().UnixMilli(), items[1].Epoch)
+	})
+
+	t.Run("mapping overrides the adapter", func(t *testing.T) {
+		s, _, repo := setupTestService(t)
+		webhook := &Webhook{OrgID: 1, Source: SourceAlertmanager, Mapping: Mapping{
+			Text: "{{ $.labels.alertname }} on {{ $.labels.instance }}",
+			Tags: []string{"team:{{ $.labels.team }}"},
+		}}
+
+		_, err := s.Ingest(ctx, webhook, 0, []byte(`{"alerts": [{
+			"status": "firing",
+			"labels": {"alertname": "HighLatency", "instance": "api-1", "team": "core"},
+			"startsAt": "2023-06-05T10:00:00Z"
+		}]}`))
+		require.NoError(t, err)
+
+		items := sortedItems(repo)
+		require.Len(t, items, 1)
+		require.Equal(t, "HighLatency on api-1", items[0].Text)
+		require.Equal(t, []string{"alertmanager", "alertname:HighLatency", "status:firing", "team:core"}, items[0].Tags)
+	})
+
+	t.Run("invalid payloads", func(t *testing.T) {
+		s, _, repo := setupTestService(t)
+		webhook := &Webhook{OrgID: 1, Source: SourceGeneric, Mapping: Mapping{Text: "$.message"}}
+
+		for _, body := range []string{`not json`, `{"other": "field"}`} {
+			_, err := s.Ingest(ctx, webhook, 0, []byte(body))
+			require.ErrorIs(t, err, ErrInvalidPayload, body)
+		}
+
+		webhook.Mapping = Mapping{Items: "$[*]", Text: "$.message"}
+		events := make([]map[string]string, maxEvents+1)
+		for i := range events {
+			events[i] = map[string]string{"message": "m"}
+		}
+		body, err := json.Marshal(events)
+		require.NoError(t, err)
+		_, err = s.Ingest(ctx, webhook, 0, body)
+		require.ErrorIs(t, err, ErrTooManyEvents)
+		require.Equal(t, 0, repo.Len())
+	})
+}
+
+func TestService_verifySecret(t *testing.T) {
+	s, _, _ := setupTestService(t)
+	ctx := context.Background()
+	webhook := &Webhook{EncryptedSecret: []byte("s3cr3t")}
+	body := []byte(`{"message": "hello"}`)
+
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write(body)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	tests := map[string]struct {
+		header http.Header
+		signed bool
+		err    error
+	}{
+		"no header":              {http.Header{}, false, nil},
+		"grafana signature":      {http.Header{"X-Grafana-Signature": {signature}}, true, nil},
+		"github signature":       {http.Header{"X-Hub-Signature-256": {"sha256=" + signature}}, true, nil},
+		"gitlab token":           {http.Header{"X-Gitlab-Token": {"s3cr3t"}}, true, nil},
+		"invalid signature":      {http.Header{"X-Grafana-Signature": {hex.EncodeToString([]byte("invalid"))}}, true, ErrInvalidSignature},
+		"malformed signature":    {http.Header{"X-Hub-Signature-256": {"sha256=zz"}}, true, ErrInvalidSignature},
+		"invalid gitlab token":   {http.Header{"X-Gitlab-Token": {"guess"}}, true, ErrInvalidSignature},
+		"webhook without secret": {http.Header{"X-Gitlab-Token": {""}}, false, nil},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			signed, err := s.verifySecret(ctx, webhook, tt.header, body)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.signed, signed)
+		})
+	}
+
+	_, err := s.verifySecret(ctx, &Webhook{}, http.Header{"X-Grafana-Signature": {signature}}, body)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestService_eventsHandler(t *testing.T) {
+	s, _, _ := setupTestService(t)
+	webhook, err := s.Create(context.Background(), CreateWebhookCommand{
+		WebhookSpec: WebhookSpec{Name: "Deployments", Source: SourceGeneric, Mapping: Mapping{Text: "Deployed"}, Secret: "s3cr3t"},
+		OrgID:       1,
+	})
+	require.NoError(t, err)
+
+	post := func(uid string, header http.Header) response.Response {
+		req := httptest.NewRequest(http.MethodPost, "/api/annotation-webhooks/"+uid+"/events", strings.NewReader(`{}`))
+		req.Header = header
+		req = web.SetURLParams(req, map[string]string{":uid": uid})
+		return s.eventsHandler(&contextmodel.ReqContext{Context: &web.Context{Req: req}, SignedInUser: &user.SignedInUser{}})
+	}
+
+	for name, header := range map[string]http.Header{
+		"without signature":  {},
+		"with bad signature": {"X-Grafana-Signature": {hex.EncodeToString([]byte("invalid"))}},
+		"with bad git token": {"X-Gitlab-Token": {"guess"}},
+	} {
+		t.Run("unknown and known webhooks are unauthorized "+name, func(t *testing.T) {
+			known := post(webhook.UID, header)
+			unknown := post("unknown", header)
+			require.Equal(t, http.StatusUnauthorized, known.Status())
+			require.Equal(t, known.Status(), unknown.Status())
+			require.Equal(t, known.Body(), unknown.Body())
+		})
+	}
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	st := &dbStore{db: sqlStore}
+	ctx := context.Background()
+
+	webhook := &Webhook{UID: "deploys", OrgID: 1, Name: "Deploys", Source: SourceGeneric, Mapping: Mapping{Text: "$.message", Tags: []string{"deploy"}}}
+	require.NoError(t, st.Insert(ctx, webhook))
+	require.NoError(t, st.Insert(ctx, &Webhook{UID: "alerts", OrgID: 1, Name: "Alerts", Source: SourceAlertmanager}))
+
+	got, err := st.Get(ctx, 1, "deploys")
+	require.NoError(t, err)
+	require.Equal(t, []string{"deploy"}, got.Mapping.Tags)
+
+	_, err = st.Get(ctx, 2, "deploys")
+	require.ErrorIs(t, err, ErrWebhookNotFound)
+	got, err = st.GetByUID(ctx, "deploys")
+	require.NoError(t, err)
+	require.Equal(t, int64(1), got.OrgID)
+
+	list, err := st.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	require.Equal(t, "Alerts", list[0].Name)
+
+	got.EncryptedSecret = []byte("secret")
+	require.NoError(t, st.Update(ctx, got))
+	got, err = st.Get(ctx, 1, "deploys")
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret"), got.EncryptedSecret)
+
+	require.NoError(t, st.Delete(ctx, 1, "deploys"))
+	require.ErrorIs(t, st.Delete(ctx, 1, "deploys"), ErrWebhookNotFound)
+}
+
+func setupTestService(t *testing.T) (*Service, *fakeStore, annotationsRepo) {
+	t.Helper()
+
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool {
+		return q.UID == "dash"
+	})).Return(&dashboards.Dashboard{ID: 10, UID: "dash", OrgID: 1}, nil).Maybe()
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardNotFound).Maybe()
+
+	cfg := setting.NewCfg()
+	cfg.AppURL = "http://localhost:3000/"
+
+	st := &fakeStore{webhooks: map[string]*Webhook{}}
+	repo := annotationstest.NewFakeAnnotationsRepo()
+	now := time.Date(2023, 6, 5, 12, 0, 0, 0, time.UTC)
+	s := &Service{
+		cfg:              cfg,
+		store:            st,
+		accessControl:    actest.FakeAccessControl{ExpectedEvaluate: true},
+		annotationsRepo:  repo,
+		dashboardService: dashboardService,
+		secretsService:   fakes.NewFakeSecretsService(),
+		log:              log.NewNopLogger(),
+		now:              func() time.Time { return now },
+	}
+	return s, st, repo
+}
+
+// annotationsRepo is the fake annotations repository, which can list the saved annotations.
+type annotationsRepo interface {
+	annotations.Repository
+	Len() int
+	Items() map[int64]annotations.Item
+}
+
+func sortedItems(repo annotationsRepo) []annotations.Item {
+	items := make([]annotations.Item, 0, repo.Len())
+	for _, item := range repo.Items() {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items
+}
+
+type fakeStore struct {
+	webhooks map[string]*Webhook
+}
+
+func (f *fakeStore) Get(_ context.Context, orgID int64, uid string) (*Webhook, error) {
+	if w, ok := f.webhooks[uid]; ok && w.OrgID == orgID {
+		copied := *w
+		return &copied, nil
+	}
+	return nil, ErrWebhookNotFound
+}
+
+func (f *fakeStore) GetByUID(_ context.Context, uid string) (*Webhook, error) {
+	if w, ok := f.webhooks[uid]; ok {
+		copied := *w
+		return &copied, nil
+	}
+	return nil, ErrWebhookNotFound
+}
+
+func (f *fakeStore) List(_ context.Context, _ int64) ([]*Webhook, error) {
+	webhooks := make([]*Webhook, 0, len(f.webhooks))
+	for _, w := range f.webhooks {
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, nil
+}
+
+func (f *fakeStore) Insert(_ context.Context, webhook *Webhook) error {
+	f.webhooks[webhook.UID] = webhook
+	return nil
+}
+
+func (f *fakeStore) Update(_ context.Context, webhook *Webhook) error {
+	f.webhooks[webhook.UID] = webhook
+	return nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, _ int64, uid string) error {
+	delete(f.webhooks, uid)
+	return nil
+}
@@ -13,15 +13,18 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/shorturls"
+	"github.com/grafana/grafana/pkg/services/store/entity/sqlstash"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -29,7 +32,8 @@ import (
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
 	shortURLService shorturls.Service, sqlstore db.DB, queryHistoryService queryhistory.Service,
 	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service, deleteExpiredImageService *image.DeleteExpiredService,
-	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner) *CleanUpService {
+	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner,
+	features featuremgmt.FeatureToggles) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:                       cfg,
 		ServerLockService:         serverLockService,
@@ -43,6 +47,7 @@ func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockSe
 		tempUserService:           tempUserService,
 		tracer:                    tracer,
 		annotationCleaner:         annotationCleaner,
+		features:                  features,
 	}
 	return s
 }
@@ -60,6 +65,7 @@ type CleanUpService struct {
 	deleteExpiredImageService *image.DeleteExpiredService
 	tempUserService           tempuser.Service
 	annotationCleaner         annotations.Cleaner
+	features                  featuremgmt.FeatureToggles
 }
 
 type cleanUpJob struct {
@@ -102,6 +108,8 @@ func (srv *CleanUpService) clean(ctx context.Context) {
 		{"expire old user invites", srv.expireOldUserInvites},
 		{"delete stale short URLs", srv.deleteStaleShortURLs},
 		{"delete stale query history", srv.deleteStaleQueryHistory},
+		{"delete expired kv store items", srv.deleteExpiredKVStoreItems},
+		{"delete old entity changes", srv.deleteOldEntityChanges},
 	}
 
 	logger := srv.log.FromContext(ctx)
@@ -279,3 +287,27 @@ func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) {
 		logger.Debug("Enforced row limit for query_history_star", "rows affected", rowsCount)
 	}
 }
+
+func (srv *CleanUpService) deleteExpiredKVStoreItems(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	rowsCount, err := kvstore.DeleteExpired(ctx, srv.store)
+	if err != nil {
+		logger.Error("Problem deleting expired kv store items", "error", err.Error())
+	} else {
+		logger.Debug("Deleted expired kv store items", "rows affected", rowsCount)
+	}
+}
+
+func (srv *CleanUpService) deleteOldEntityChanges(ctx context.Context) {
+	// The tables of the entity store only exist with the feature
+	if !srv.features.IsEnabled(featuremgmt.FlagEntityStore) {
+		return
+	}
+	logger := srv.log.FromContext(ctx)
+	rowsCount, err := sqlstash.DeleteOldChanges(ctx, srv.store)
+	if err != nil {
+		logger.Error("Problem deleting old entity changes", "error", err.Error())
+	} else {
+		logger.Debug("Deleted old entity changes", "rows affected", rowsCount)
+	}
+}
@@ -0,0 +1,162 @@
+package dashboardlint
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	s.routeRegister.Group("/api/dashboards/lint", func(lint routing.RouteRegister) {
+		lint.Post("/", routing.Wrap(s.lintHandler))
+		lint.Get("/rules", routing.Wrap(s.listRulesHandler))
+		lint.Get("/settings", middleware.ReqOrgAdmin, routing.Wrap(s.getSettingsHandler))
+		lint.Put("/settings", middleware.ReqOrgAdmin, routing.Wrap(s.updateSettingsHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route POST /dashboards/lint dashboards lintDashboard
+//
+// Check a dashboard JSON against the lint rules of the organization.
+//
+// The dashboard is not saved. Problems of rules in block mode have the error severity, and would prevent saving the
+// dashboard.
+//
+// Responses:
+// 200: lintDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) lintHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := LintDashboardCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.Dashboard == nil {
+		return response.Error(http.StatusBadRequest, "dashboard is required", nil)
+	}
+
+	result, err := s.Lint(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd.Dashboard)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to lint dashboard", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route GET /dashboards/lint/rules dashboards listDashboardLintRules
+//
+// List the lint rules with their mode in the organization.
+//
+// Responses:
+// 200: listDashboardLintRulesResponse
+// 401: unauthorisedError
+// 500: internalServerError
+func (s *Service) listRulesHandler(c *contextmodel.ReqContext) response.Response {
+	settings, err := s.GetSettings(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get lint settings", err)
+	}
+
+	rules := make([]RuleDTO, 0)
+	for _, rule := range s.linter.Rules() {
+		rules = append(rules, RuleDTO{
+			Name:        rule.Name(),
+			Description: rule.Description(),
+			Mode:        s.linter.Mode(rule, settings),
+			Options:     settings.Rules[rule.Name()].Options,
+		})
+	}
+	return response.JSON(http.StatusOK, rules)
+}
+
+// swagger:route GET /dashboards/lint/settings dashboards getDashboardLintSettings
+//
+// Get the lint settings of the organization.
+//
+// Responses:
+// 200: dashboardLintSettingsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) getSettingsHandler(c *contextmodel.ReqContext) response.Response {
+	settings, err := s.GetSettings(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get lint settings", err)
+	}
+	return response.JSON(http.StatusOK, settings)
+}
+
+// swagger:route PUT /dashboards/lint/settings dashboards updateDashboardLintSettings
+//
+// Replace the lint settings of the organization.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) updateSettingsHandler(c *contextmodel.ReqContext) response.Response {
+	settings := Settings{}
+	if err := web.Bind(c.Req, &settings); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.SaveSettings(c.Req.Context(), c.SignedInUser.GetOrgID(), settings); err != nil {
+		if errors.Is(err, ErrUnknownRule) || errors.Is(err, ErrInvalidMode) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to save lint settings", err)
+	}
+	return response.Success("Lint settings updated")
+}
+
+type LintDashboardCommand struct {
+	Dashboard *simplejson.Json `json:"dashboard"`
+}
+
+// RuleDTO is a rule with its mode and options in an organization.
+type RuleDTO struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Mode        Mode    `json:"mode"`
+	Options     Options `json:"options,omitempty"`
+}
+
+// swagger:parameters lintDashboard
+type LintDashboardParams struct {
+	// in:body
+	// required:true
+	Body LintDashboardCommand
+}
+
+// swagger:parameters updateDashboardLintSettings
+type UpdateDashboardLintSettingsParams struct {
+	// in:body
+	// required:true
+	Body Settings
+}
+
+// swagger:response lintDashboardResponse
+type LintDashboardResponse struct {
+	// in: body
+	Body Result `json:"body"`
+}
+
+// swagger:response listDashboardLintRulesResponse
+type ListDashboardLintRulesResponse struct {
+	// in: body
+	Body []RuleDTO `json:"body"`
+}
+
+// swagger:response dashboardLintSettingsResponse
+type DashboardLintSettingsResponse struct {
+	// in: body
+	Body Settings `json:"body"`
+}
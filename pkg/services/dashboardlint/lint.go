@@ -0,0 +1,207 @@
+// Package dashboardlint checks dashboards against a set of rules when they are saved, imported or provisioned, and
+// on demand through the API and the CLI.
+package dashboardlint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+var (
+	ErrUnknownRule = errors.New("unknown lint rule")
+	ErrInvalidMode = errors.New("invalid lint rule mode")
+)
+
+// Mode is how the problems of a rule are reported.
+type Mode string
+
+const (
+	// ModeOff disables the rule
+	ModeOff Mode = "off"
+	// ModeWarn reports the problems of the rule as warnings
+	ModeWarn Mode = "warn"
+	// ModeBlock reports the problems of the rule as errors, that prevent saving the dashboard
+	ModeBlock Mode = "block"
+)
+
+func (m Mode) valid() bool {
+	return m == ModeOff || m == ModeWarn || m == ModeBlock
+}
+
+type Severity string
+
+const (
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Problem is a violation of a rule by a dashboard.
+type Problem struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// PanelID is the panel with the problem, if any.
+	PanelID int64 `json:"panelId,omitempty"`
+}
+
+// Rule checks a dashboard. Rules return their problems without rule and severity, which are set by the linter.
+type Rule interface {
+	// Name identifies the rule in the settings and in the problems.
+	Name() string
+	Description() string
+	// DefaultMode is the mode of the rule when the settings do not configure it.
+	DefaultMode() Mode
+	Check(dashboard *Dashboard, options Options) []Problem
+}
+
+// Options are the options of a rule, as configured in the settings.
+type Options map[string]any
+
+// Int returns an integer option, or the default value when the option is not set.
+func (o Options) Int(name string, defaultValue int) int {
+	switch v := o[name].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return defaultValue
+}
+
+// Settings configure the rules for an organization.
+type Settings struct {
+	// Rules overrides the mode and the options of the rules, by name.
+	Rules map[string]RuleSettings `json:"rules"`
+}
+
+type RuleSettings struct {
+	Mode    Mode    `json:"mode,omitempty"`
+	Options Options `json:"options,omitempty"`
+}
+
+// Dashboard is the dashboard checked by the rules.
+type Dashboard struct {
+	JSON *simplejson.Json
+	// Panels are the panels of the dashboard, including the rows and the panels of collapsed rows.
+	Panels []*simplejson.Json
+}
+
+func newDashboard(data *simplejson.Json) *Dashboard {
+	d := &Dashboard{JSON: data}
+	for _, item := range data.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(item)
+		d.Panels = append(d.Panels, panel)
+		for _, nested := range panel.Get("panels").MustArray() {
+			d.Panels = append(d.Panels, simplejson.NewFromAny(nested))
+		}
+	}
+	return d
+}
+
+// Result is the result of linting a dashboard.
+type Result struct {
+	Problems []Problem `json:"problems"`
+}
+
+// Blocked returns true when a problem prevents saving the dashboard.
+func (r *Result) Blocked() bool {
+	for _, p := range r.Problems {
+		if p.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// BlockedError is returned when saving a dashboard with problems of rules in block mode.
+type BlockedError struct {
+	Problems []Problem
+}
+
+func (e BlockedError) Error() string {
+	messages := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		if p.Severity == SeverityError {
+			messages = append(messages, p.Message)
+		}
+	}
+	return fmt.Sprintf("dashboard lint failed: %s", strings.Join(messages, "; "))
+}
+
+// Linter runs a set of rules.
+type Linter struct {
+	rules map[string]Rule
+}
+
+// NewLinter returns a linter with the given rules.
+func NewLinter(rules ...Rule) *Linter {
+	l := &Linter{rules: make(map[string]Rule, len(rules))}
+	for _, r := range rules {
+		l.Register(r)
+	}
+	return l
+}
+
+// Register adds a rule to the linter, replacing the rule with the same name.
+func (l *Linter) Register(rule Rule) {
+	l.rules[rule.Name()] = rule
+}
+
+// Rules returns the rules of the linter sorted by name.
+func (l *Linter) Rules() []Rule {
+	rules := make([]Rule, 0, len(l.rules))
+	for _, r := range l.rules {
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name() < rules[j].Name() })
+	return rules
+}
+
+// Validate checks that settings only configure known rules with valid modes.
+func (l *Linter) Validate(settings Settings) error {
+	for name, rs := range settings.Rules {
+		if _, ok := l.rules[name]; !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownRule, name)
+		}
+		if rs.Mode != "" && !rs.Mode.valid() {
+			return fmt.Errorf("%w: %s", ErrInvalidMode, rs.Mode)
+		}
+	}
+	return nil
+}
+
+// Mode returns the mode of a rule with the settings.
+func (l *Linter) Mode(rule Rule, settings Settings) Mode {
+	if rs, ok := settings.Rules[rule.Name()]; ok && rs.Mode != "" {
+		return rs.Mode
+	}
+	return rule.DefaultMode()
+}
+
+// Lint checks a dashboard against the rules that are not off.
+func (l *Linter) Lint(data *simplejson.Json, settings Settings) *Result {
+	dashboard := newDashboard(data)
+	result := &Result{Problems: make([]Problem, 0)}
+
+	for _, rule := range l.Rules() {
+		mode := l.Mode(rule, settings)
+		if mode == ModeOff {
+			continue
+		}
+		severity := SeverityWarning
+		if mode == ModeBlock {
+			severity = SeverityError
+		}
+
+		for _, p := range rule.Check(dashboard, settings.Rules[rule.Name()].Options) {
+			p.Rule = rule.Name()
+			p.Severity = severity
+			result.Problems = append(result.Problems, p)
+		}
+	}
+	return result
+}
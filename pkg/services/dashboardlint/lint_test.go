@@ -0,0 +1,87 @@
+package dashboardlint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const testDashboard = `{
+	"title": "Lint",
+	"panels": [
+		{"id": 1, "type": "graph", "title": "Requests", "datasource": "Prometheus",
+			"targets": [{"refId": "A", "expr": "http_requests_total{job=\"$job\"}"}, {"refId": "B", "expr": "rate(http_requests_total[5m])"}]},
+		{"id": 2, "type": "timeseries", "title": "", "datasource": {"type": "prometheus", "uid": "prom"},
+			"targets": [{"refId": "A", "expr": "up", "datasource": "${ds}"}]},
+		{"id": 3, "type": "row", "title": "", "collapsed": true, "panels": [
+			{"id": 4, "type": "stat", "title": "Logs", "datasource": {"type": "loki", "uid": "loki"},
+				"targets": [{"refId": "A", "expr": "count_over_time({app=\"x\"}[1m]) errors_total"}]}
+		]}
+	],
+	"templating": {"list": [
+		{"name": "ds", "type": "datasource"},
+		{"name": "job", "type": "query", "datasource": {"uid": "prom"}, "query": "label_values(up{instance=~\"$instance\"}, job)"},
+		{"name": "instance", "type": "query", "datasource": "Prometheus"},
+		{"name": "unused", "type": "custom"},
+		{"name": "filters", "type": "adhoc", "datasource": {"type": "prometheus", "uid": "prom"}}
+	]}
+}`
+
+func TestLinter(t *testing.T) {
+	dashboard, err := simplejson.NewJson([]byte(testDashboard))
+	require.NoError(t, err)
+	linter := NewLinter(DefaultRules()...)
+
+	t.Run("Should report the problems of the rules", func(t *testing.T) {
+		result := linter.Lint(dashboard, Settings{})
+		require.False(t, result.Blocked())
+		require.Equal(t, []Problem{
+			{Rule: "datasource-uid", Severity: SeverityWarning, Message: `Panel "Requests" references datasource "Prometheus" by name`, PanelID: 1},
+			{Rule: "datasource-uid", Severity: SeverityWarning, Message: `Variable "instance" references datasource "Prometheus" by name`},
+			{Rule: "deprecated-panel", Severity: SeverityWarning, Message: `Panel "Requests" uses the deprecated type graph, use timeseries instead`, PanelID: 1},
+			{Rule: "panel-title", Severity: SeverityWarning, Message: "Panel 2 has no title", PanelID: 2},
+			{Rule: "prometheus-rate", Severity: SeverityWarning, Message: `Query A of panel "Requests" uses the counter http_requests_total without rate()`, PanelID: 1},
+			{Rule: "unused-variable", Severity: SeverityWarning, Message: `Variable "unused" is not used`},
+		}, result.Problems)
+	})
+
+	t.Run("Should apply the mode and options of the settings", func(t *testing.T) {
+		result := linter.Lint(dashboard, Settings{Rules: map[string]RuleSettings{
+			"datasource-uid":   {Mode: ModeOff},
+			"deprecated-panel": {Mode: ModeOff},
+			"prometheus-rate":  {Mode: ModeOff},
+			"unused-variable":  {Mode: ModeOff},
+			"panel-title":      {Mode: ModeBlock},
+			"max-panels":       {Options: Options{"limit": float64(2)}},
+		}})
+		require.True(t, result.Blocked())
+		require.Equal(t, []Problem{
+			{Rule: "max-panels", Severity: SeverityWarning, Message: "Dashboard has 3 panels, more than the limit of 2"},
+			{Rule: "panel-title", Severity: SeverityError, Message: "Panel 2 has no title", PanelID: 2},
+		}, result.Problems)
+	})
+
+	t.Run("Should validate the settings", func(t *testing.T) {
+		require.NoError(t, linter.Validate(Settings{Rules: map[string]RuleSettings{"panel-title": {Mode: ModeBlock}}}))
+		require.ErrorIs(t, linter.Validate(Settings{Rules: map[string]RuleSettings{"unknown": {Mode: ModeBlock}}}), ErrUnknownRule)
+		require.ErrorIs(t, linter.Validate(Settings{Rules: map[string]RuleSettings{"panel-title": {Mode: "error"}}}), ErrInvalidMode)
+	})
+
+	t.Run("Should run registered rules", func(t *testing.T) {
+		l := NewLinter()
+		l.Register(testRule{})
+		result := l.Lint(dashboard, Settings{})
+		require.Equal(t, []Problem{{Rule: "test", Severity: SeverityError, Message: "Lint"}}, result.Problems)
+	})
+}
+
+type testRule struct{}
+
+func (testRule) Name() string        { return "test" }
+func (testRule) Description() string { return "Test rule" }
+func (testRule) DefaultMode() Mode   { return ModeBlock }
+func (testRule) Check(dashboard *Dashboard, _ Options) []Problem {
+	return []Problem{{Message: dashboard.JSON.Get("title").MustString()}}
+}
@@ -0,0 +1,271 @@
+package dashboardlint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards/variables"
+)
+
+// DefaultRules returns the built-in rules.
+func DefaultRules() []Rule {
+	return []Rule{
+		datasourceUIDRule{},
+		panelTitleRule{},
+		counterRateRule{},
+		maxPanelsRule{},
+		deprecatedPanelRule{},
+		unusedVariableRule{},
+	}
+}
+
+// datasourceUIDRule reports the datasources referenced by name, which break when the datasource is renamed.
+type datasourceUIDRule struct{}
+
+func (datasourceUIDRule) Name() string { return "datasource-uid" }
+
+func (datasourceUIDRule) Description() string {
+	return "Datasources should be referenced by UID instead of name"
+}
+
+func (datasourceUIDRule) DefaultMode() Mode { return ModeWarn }
+
+func (datasourceUIDRule) Check(dashboard *Dashboard, _ Options) []Problem {
+	var problems []Problem
+	for _, panel := range dashboard.Panels {
+		if name, ok := datasourceName(panel.Get("datasource")); ok {
+			problems = append(problems, Problem{
+				Message: fmt.Sprintf("Panel %q references datasource %q by name", panelTitle(panel), name),
+				PanelID: panel.Get("id").MustInt64(),
+			})
+		}
+		for _, target := range panel.Get("targets").MustArray() {
+			query := simplejson.NewFromAny(target)
+			if name, ok := datasourceName(query.Get("datasource")); ok {
+				problems = append(problems, Problem{
+					Message: fmt.Sprintf("Query %s of panel %q references datasource %q by name", query.Get("refId").MustString(), panelTitle(panel), name),
+					PanelID: panel.Get("id").MustInt64(),
+				})
+			}
+		}
+	}
+	for _, item := range dashboard.JSON.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(item)
+		if name, ok := datasourceName(variable.Get("datasource")); ok {
+			problems = append(problems, Problem{
+				Message: fmt.Sprintf("Variable %q references datasource %q by name", variable.Get("name").MustString(), name),
+			})
+		}
+	}
+	for _, item := range dashboard.JSON.GetPath("annotations", "list").MustArray() {
+		annotation := simplejson.NewFromAny(item)
+		if name, ok := datasourceName(annotation.Get("datasource")); ok {
+			problems = append(problems, Problem{
+				Message: fmt.Sprintf("Annotation %q references datasource %q by name", annotation.Get("name").MustString(), name),
+			})
+		}
+	}
+	return problems
+}
+
+// datasourceName returns the name of a datasource referenced by name rather than by an object with its UID. Variables
+// are not reported, since they are resolved to the selected datasource.
+func datasourceName(ref *simplejson.Json) (string, bool) {
+	name, err := ref.String()
+	if err != nil || name == "" || len(variables.References(name)) > 0 {
+		return "", false
+	}
+	return name, true
+}
+
+// panelTitleRule reports the panels without title.
+type panelTitleRule struct{}
+
+func (panelTitleRule) Name() string { return "panel-title" }
+
+func (panelTitleRule) Description() string { return "Panels should have a title" }
+
+func (panelTitleRule) DefaultMode() Mode { return ModeWarn }
+
+func (panelTitleRule) Check(dashboard *Dashboard, _ Options) []Problem {
+	var problems []Problem
+	for _, panel := range dashboard.Panels {
+		if panel.Get("type").MustString() == "row" {
+			continue
+		}
+		if strings.TrimSpace(panel.Get("title").MustString()) == "" {
+			id := panel.Get("id").MustInt64()
+			problems = append(problems, Problem{Message: fmt.Sprintf("Panel %d has no title", id), PanelID: id})
+		}
+	}
+	return problems
+}
+
+var (
+	// counterRegex matches the Prometheus metrics named as counters
+	counterRegex = regexp.MustCompile(`\b[a-zA-Z_:][a-zA-Z0-9_:]*_total\b`)
+	// rateRegex matches the Prometheus functions computing the rate of a counter
+	rateRegex = regexp.MustCompile(`\b(rate|irate|increase)\s*\(`)
+)
+
+// counterRateRule reports the Prometheus queries of counters without rate, whose values only grow.
+type counterRateRule struct{}
+
+func (counterRateRule) Name() string { return "prometheus-rate" }
+
+func (counterRateRule) Description() string {
+	return "Prometheus queries of counters should use rate(), irate() or increase()"
+}
+
+func (counterRateRule) DefaultMode() Mode { return ModeWarn }
+
+func (counterRateRule) Check(dashboard *Dashboard, _ Options) []Problem {
+	var problems []Problem
+	for _, panel := range dashboard.Panels {
+		panelType := panel.GetPath("datasource", "type").MustString()
+		for _, target := range panel.Get("targets").MustArray() {
+			query := simplejson.NewFromAny(target)
+			queryType := query.GetPath("datasource", "type").MustString(panelType)
+			if queryType != "" && queryType != "prometheus" {
+				continue
+			}
+
+			expr := query.Get("expr").MustString()
+			counter := counterRegex.FindString(expr)
+			if counter == "" || rateRegex.MatchString(expr) {
+				continue
+			}
+			problems = append(problems, Problem{
+				Message: fmt.Sprintf("Query %s of panel %q uses the counter %s without rate()", query.Get("refId").MustString(), panelTitle(panel), counter),
+				PanelID: panel.Get("id").MustInt64(),
+			})
+		}
+	}
+	return problems
+}
+
+const defaultMaxPanels = 50
+
+// maxPanelsRule reports the dashboards with too many panels, which are slow to load.
+type maxPanelsRule struct{}
+
+func (maxPanelsRule) Name() string { return "max-panels" }
+
+func (maxPanelsRule) Description() string {
+	return fmt.Sprintf("Dashboards should not have more panels than the limit option (default %d)", defaultMaxPanels)
+}
+
+func (maxPanelsRule) DefaultMode() Mode { return ModeWarn }
+
+func (maxPanelsRule) Check(dashboard *Dashboard, options Options) []Problem {
+	limit := options.Int("limit", defaultMaxPanels)
+	count := 0
+	for _, panel := range dashboard.Panels {
+		if panel.Get("type").MustString() != "row" {
+			count++
+		}
+	}
+	if count <= limit {
+		return nil
+	}
+	return []Problem{{Message: fmt.Sprintf("Dashboard has %d panels, more than the limit of %d", count, limit)}}
+}
+
+// deprecatedPanelTypes are the deprecated panel types and the types replacing them
+var deprecatedPanelTypes = map[string]string{
+	"graph":                    "timeseries",
+	"singlestat":               "stat",
+	"grafana-singlestat-panel": "stat",
+	"table-old":                "table",
+	"grafana-piechart-panel":   "piechart",
+	"grafana-worldmap-panel":   "geomap",
+}
+
+// deprecatedPanelRule reports the panels of deprecated types.
+type deprecatedPanelRule struct{}
+
+func (deprecatedPanelRule) Name() string { return "deprecated-panel" }
+
+func (deprecatedPanelRule) Description() string {
+	return "Panels should not use deprecated panel types"
+}
+
+func (deprecatedPanelRule) DefaultMode() Mode { return ModeWarn }
+
+func (deprecatedPanelRule) Check(dashboard *Dashboard, _ Options) []Problem {
+	var problems []Problem
+	for _, panel := range dashboard.Panels {
+		panelType := panel.Get("type").MustString()
+		if replacement, ok := deprecatedPanelTypes[panelType]; ok {
+			problems = append(problems, Problem{
+				Message: fmt.Sprintf("Panel %q uses the deprecated type %s, use %s instead", panelTitle(panel), panelType, replacement),
+				PanelID: panel.Get("id").MustInt64(),
+			})
+		}
+	}
+	return problems
+}
+
+// unusedVariableRule reports the template variables that are not used by the panels, the other variables, the
+// annotations or the links of the dashboard.
+type unusedVariableRule struct{}
+
+// implicitVariableTypes are the types of the variables that the data sources apply to the queries, without being
+// referenced by name
+var implicitVariableTypes = map[string]bool{"adhoc": true}
+
+func (unusedVariableRule) Name() string { return "unused-variable" }
+
+func (unusedVariableRule) Description() string { return "Template variables should be used" }
+
+func (unusedVariableRule) DefaultMode() Mode { return ModeWarn }
+
+func (unusedVariableRule) Check(dashboard *Dashboard, _ Options) []Problem {
+	list := dashboard.JSON.GetPath("templating", "list").MustArray()
+	if len(list) == 0 {
+		return nil
+	}
+
+	used := make(map[string]bool)
+	for k, v := range dashboard.JSON.MustMap() {
+		if k != "templating" {
+			for name := range variables.References(v) {
+				used[name] = true
+			}
+		}
+	}
+	// variables can be used by the other variables, but not by themselves
+	for i, item := range list {
+		for j, other := range list {
+			if i == j {
+				continue
+			}
+			name := simplejson.NewFromAny(item).Get("name").MustString()
+			if variables.References(other)[name] {
+				used[name] = true
+			}
+		}
+	}
+
+	var problems []Problem
+	for _, item := range list {
+		variable := simplejson.NewFromAny(item)
+		if implicitVariableTypes[variable.Get("type").MustString()] {
+			continue
+		}
+		name := variable.Get("name").MustString()
+		if !used[name] {
+			problems = append(problems, Problem{Message: fmt.Sprintf("Variable %q is not used", name)})
+		}
+	}
+	return problems
+}
+
+func panelTitle(panel *simplejson.Json) string {
+	if title := panel.Get("title").MustString(); title != "" {
+		return title
+	}
+	return fmt.Sprintf("%d", panel.Get("id").MustInt64())
+}
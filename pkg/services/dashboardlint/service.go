@@ -0,0 +1,117 @@
+package dashboardlint
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards/service"
+)
+
+const (
+	kvNamespace = "dashboard-lint"
+	settingsKey = "settings"
+)
+
+type Service struct {
+	kv            kvstore.KVStore
+	linter        *Linter
+	routeRegister routing.RouteRegister
+	log           log.Logger
+}
+
+func ProvideService(kv kvstore.KVStore, routeRegister routing.RouteRegister, dashboardService *dashboardservice.DashboardServiceImpl) *Service {
+	s := &Service{
+		kv:            kv,
+		linter:        NewLinter(DefaultRules()...),
+		routeRegister: routeRegister,
+		log:           log.New("dashboard.lint"),
+	}
+
+	dashboardService.RegisterSaveValidator(s)
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+// RegisterRule adds a rule to the built-in rules, replacing the rule with the same name.
+func (s *Service) RegisterRule(rule Rule) {
+	s.linter.Register(rule)
+}
+
+// GetSettings returns the settings of the rules for an organization.
+func (s *Service) GetSettings(ctx context.Context, orgID int64) (Settings, error) {
+	settings := Settings{Rules: make(map[string]RuleSettings)}
+	value, ok, err := s.kv.Get(ctx, orgID, kvNamespace, settingsKey)
+	if err != nil || !ok {
+		return settings, err
+	}
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return settings, err
+	}
+	if settings.Rules == nil {
+		settings.Rules = make(map[string]RuleSettings)
+	}
+	return settings, nil
+}
+
+// SaveSettings replaces the settings of the rules for an organization.
+func (s *Service) SaveSettings(ctx context.Context, orgID int64, settings Settings) error {
+	if err := s.linter.Validate(settings); err != nil {
+		return err
+	}
+	value, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, orgID, kvNamespace, settingsKey, string(value))
+}
+
+// Lint checks a dashboard with the settings of an organization.
+func (s *Service) Lint(ctx context.Context, orgID int64, dashboard *simplejson.Json) (*Result, error) {
+	settings, err := s.GetSettings(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return s.linter.Lint(dashboard, settings), nil
+}
+
+// ValidateSave prevents saving the dashboards with problems of rules in block mode. The problems of rules in warn
+// mode are logged, and returned by the API saving the dashboard.
+func (s *Service) ValidateSave(ctx context.Context, dashboard *dashboards.Dashboard) error {
+	result, err := s.Lint(ctx, dashboard.OrgID, dashboard.Data)
+	if err != nil {
+		return err
+	}
+	if result.Blocked() {
+		return BlockedError{Problems: result.Problems}
+	}
+	if len(result.Problems) > 0 {
+		rules := make([]string, 0, len(result.Problems))
+		for _, p := range result.Problems {
+			rules = append(rules, p.Rule)
+		}
+		s.log.FromContext(ctx).Warn("Saving dashboard with lint warnings", "orgId", dashboard.OrgID, "dashboardUid", dashboard.UID, "rules", rules)
+	}
+	return nil
+}
+
+// Warnings returns the problems of the rules in warn mode of a saved dashboard, or nil when it can't be linted.
+func (s *Service) Warnings(ctx context.Context, dashboard *dashboards.Dashboard) []Problem {
+	result, err := s.Lint(ctx, dashboard.OrgID, dashboard.Data)
+	if err != nil {
+		s.log.FromContext(ctx).Warn("Failed to lint the saved dashboard", "orgId", dashboard.OrgID, "dashboardUid", dashboard.UID, "error", err)
+		return nil
+	}
+	warnings := make([]Problem, 0, len(result.Problems))
+	for _, p := range result.Problems {
+		if p.Severity == SeverityWarning {
+			warnings = append(warnings, p)
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,63 @@
+package dashboardlint
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	s := &Service{kv: kvstore.NewFakeKVStore(), linter: NewLinter(DefaultRules()...), log: log.NewNopLogger()}
+
+	dashboard := dashboards.NewDashboardFromJson(simplejson.NewFromAny(map[string]any{
+		"title":  "Lint",
+		"panels": []any{map[string]any{"id": float64(1), "type": "timeseries"}},
+	}))
+	dashboard.OrgID = 1
+
+	t.Run("Should save dashboards with warnings", func(t *testing.T) {
+		settings, err := s.GetSettings(ctx, 1)
+		require.NoError(t, err)
+		require.Empty(t, settings.Rules)
+
+		require.NoError(t, s.ValidateSave(ctx, dashboard))
+
+		warnings := s.Warnings(ctx, dashboard)
+		require.Len(t, warnings, 1)
+		require.Equal(t, "panel-title", warnings[0].Rule)
+		require.Equal(t, SeverityWarning, warnings[0].Severity)
+	})
+
+	t.Run("Should not save dashboards with problems of rules in block mode", func(t *testing.T) {
+		err := s.SaveSettings(ctx, 1, Settings{Rules: map[string]RuleSettings{"panel-title": {Mode: ModeBlock}}})
+		require.NoError(t, err)
+
+		settings, err := s.GetSettings(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, ModeBlock, settings.Rules["panel-title"].Mode)
+
+		err = s.ValidateSave(ctx, dashboard)
+		var blocked BlockedError
+		require.ErrorAs(t, err, &blocked)
+		require.Equal(t, "panel-title", blocked.Problems[0].Rule)
+		require.Equal(t, "dashboard lint failed: Panel 1 has no title", err.Error())
+	})
+
+	t.Run("Should apply the settings of the organization", func(t *testing.T) {
+		other := dashboards.NewDashboardFromJson(dashboard.Data)
+		other.OrgID = 2
+		require.NoError(t, s.ValidateSave(ctx, other))
+	})
+
+	t.Run("Should not save invalid settings", func(t *testing.T) {
+		err := s.SaveSettings(ctx, 1, Settings{Rules: map[string]RuleSettings{"unknown": {Mode: ModeBlock}}})
+		require.ErrorIs(t, err, ErrUnknownRule)
+	})
+}
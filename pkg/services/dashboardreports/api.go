@@ -0,0 +1,295 @@
+package dashboardreports
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	uidScope := ScopeProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+	authorize := ac.Middleware(s.accessControl)
+
+	s.routeRegister.Group("/api/dashboard-reports", func(reports routing.RouteRegister) {
+		reports.Get("/", authorize(ac.EvalPermission(ActionRead)), routing.Wrap(s.listHandler))
+		reports.Post("/", authorize(ac.EvalPermission(ActionCreate)), routing.Wrap(s.createHandler))
+		reports.Get("/:uid", authorize(ac.EvalPermission(ActionRead, uidScope)), routing.Wrap(s.getHandler))
+		reports.Put("/:uid", authorize(ac.EvalPermission(ActionWrite, uidScope)), routing.Wrap(s.updateHandler))
+		reports.Delete("/:uid", authorize(ac.EvalPermission(ActionDelete, uidScope)), routing.Wrap(s.deleteHandler))
+		reports.Post("/:uid/send", authorize(ac.EvalPermission(ActionWrite, uidScope)), routing.Wrap(s.sendHandler))
+		reports.Get("/:uid/history", authorize(ac.EvalPermission(ActionRead, uidScope)), routing.Wrap(s.historyHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /dashboard-reports dashboard_reports listDashboardReports
+//
+// List the dashboard reports of the organization.
+//
+// Responses:
+// 200: listDashboardReportsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) listHandler(c *contextmodel.ReqContext) response.Response {
+	reports, err := s.List(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list reports", err)
+	}
+
+	filtered := make([]ReportDTO, 0, len(reports))
+	for _, r := range reports {
+		ok, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(ActionRead, ScopeProvider.GetResourceScopeUID(r.UID)))
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+		}
+		if ok {
+			filtered = append(filtered, r)
+		}
+	}
+
+	return response.JSON(http.StatusOK, filtered)
+}
+
+// swagger:route POST /dashboard-reports dashboard_reports createDashboardReport
+//
+// Create a dashboard report.
+//
+// The report is sent as the user creating it, who needs to be able to read the dashboard.
+//
+// Responses:
+// 200: dashboardReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.CreatedBy = c.SignedInUser.UserID
+
+	if resp := s.checkDashboardAccess(c, cmd.DashboardUID); resp != nil {
+		return resp
+	}
+
+	report, err := s.Create(c.Req.Context(), cmd)
+	if err != nil {
+		return errorResponse("Failed to create report", err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route GET /dashboard-reports/{uid} dashboard_reports getDashboardReport
+//
+// Get a dashboard report.
+//
+// Responses:
+// 200: dashboardReportResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getHandler(c *contextmodel.ReqContext) response.Response {
+	report, err := s.Get(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse("Failed to get report", err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route PUT /dashboard-reports/{uid} dashboard_reports updateDashboardReport
+//
+// Update a dashboard report.
+//
+// The report is then sent as the user updating it, who needs to be able to read the dashboard.
+//
+// Responses:
+// 200: dashboardReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := UpdateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.UpdatedBy = c.SignedInUser.UserID
+
+	if resp := s.checkDashboardAccess(c, cmd.DashboardUID); resp != nil {
+		return resp
+	}
+
+	report, err := s.Update(c.Req.Context(), cmd)
+	if err != nil {
+		return errorResponse("Failed to update report", err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route DELETE /dashboard-reports/{uid} dashboard_reports deleteDashboardReport
+//
+// Delete a dashboard report and its history.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	if err := s.Delete(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"]); err != nil {
+		return errorResponse("Failed to delete report", err)
+	}
+
+	return response.Success("Report deleted")
+}
+
+// swagger:route POST /dashboard-reports/{uid}/send dashboard_reports sendDashboardReport
+//
+// Send a dashboard report immediately.
+//
+// The schedule of the report is not changed. The result is recorded in the history of the report.
+// The user sending the report needs to be able to read the dashboard.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) sendHandler(c *contextmodel.ReqContext) response.Response {
+	report, err := s.Get(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return errorResponse("Failed to send report", err)
+	}
+
+	if resp := s.checkDashboardAccess(c, report.DashboardUID); resp != nil {
+		return resp
+	}
+
+	if err := s.Send(c.Req.Context(), c.SignedInUser.GetOrgID(), report.UID); err != nil {
+		return errorResponse("Failed to send report", err)
+	}
+
+	return response.Success("Report sent")
+}
+
+// swagger:route GET /dashboard-reports/{uid}/history dashboard_reports getDashboardReportHistory
+//
+// Get the history of a dashboard report.
+//
+// Returns the results of the sendings of the report, most recent first.
+//
+// Responses:
+// 200: getDashboardReportHistoryResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) historyHandler(c *contextmodel.ReqContext) response.Response {
+	history, err := s.GetHistory(c.Req.Context(), GetHistoryQuery{
+		OrgID:     c.SignedInUser.GetOrgID(),
+		ReportUID: web.Params(c.Req)[":uid"],
+		Limit:     c.QueryInt("limit"),
+	})
+	if err != nil {
+		return errorResponse("Failed to get report history", err)
+	}
+
+	return response.JSON(http.StatusOK, history)
+}
+
+// checkDashboardAccess returns an error response when the signed in user cannot read the dashboard,
+// as reports are rendered with the permissions of their creator.
+func (s *Service) checkDashboardAccess(c *contextmodel.ReqContext, dashboardUID string) response.Response {
+	if dashboardUID == "" {
+		return response.Error(http.StatusBadRequest, ErrMissingDashboardUID.Error(), nil)
+	}
+
+	ok, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboardUID)))
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+	}
+	if !ok {
+		return response.Error(http.StatusForbidden, "Access denied to the dashboard of the report", nil)
+	}
+	return nil
+}
+
+func errorResponse(message string, err error) response.Response {
+	switch {
+	case errors.Is(err, ErrReportNotFound):
+		return response.Error(http.StatusNotFound, ErrReportNotFound.Error(), err)
+	case errors.Is(err, dashboards.ErrDashboardNotFound):
+		return response.Error(http.StatusNotFound, dashboards.ErrDashboardNotFound.Error(), err)
+	case errors.Is(err, ErrMissingName), errors.Is(err, ErrMissingDashboardUID), errors.Is(err, ErrInvalidSchedule),
+		errors.Is(err, ErrInvalidTimezone), errors.Is(err, ErrInvalidRecipients), errors.Is(err, ErrInvalidFormat):
+		return response.Error(http.StatusBadRequest, err.Error(), err)
+	}
+	return response.Error(http.StatusInternalServerError, message, err)
+}
+
+// swagger:parameters getDashboardReport updateDashboardReport deleteDashboardReport sendDashboardReport getDashboardReportHistory
+type DashboardReportUIDParam struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters createDashboardReport
+type CreateDashboardReportParams struct {
+	// in:body
+	// required:true
+	Body ReportSpec `json:"body"`
+}
+
+// swagger:parameters updateDashboardReport
+type UpdateDashboardReportParams struct {
+	// in:body
+	// required:true
+	Body ReportSpec `json:"body"`
+}
+
+// swagger:parameters getDashboardReportHistory
+type GetDashboardReportHistoryParams struct {
+	// Maximum number of entries to return.
+	// in:query
+	// required:false
+	// default:100
+	Limit int `json:"limit"`
+}
+
+// swagger:response listDashboardReportsResponse
+type ListDashboardReportsResponse struct {
+	// in:body
+	Body []ReportDTO `json:"body"`
+}
+
+// swagger:response dashboardReportResponse
+type DashboardReportResponse struct {
+	// in:body
+	Body ReportDTO `json:"body"`
+}
+
+// swagger:response getDashboardReportHistoryResponse
+type GetDashboardReportHistoryResponse struct {
+	// in:body
+	Body []HistoryEntryDTO `json:"body"`
+}
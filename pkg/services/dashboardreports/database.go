@@ -0,0 +1,165 @@
+package dashboardreports
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+)
+
+const (
+	defaultHistoryLimit = 100
+	// noFencingToken records the history of the reports sent on demand, outside of the lease of the scheduler
+	noFencingToken int64 = -1
+)
+
+type store interface {
+	Get(ctx context.Context, orgID int64, uid string) (*Report, error)
+	List(ctx context.Context, orgID int64) ([]*Report, error)
+	Insert(ctx context.Context, report *Report) error
+	Update(ctx context.Context, report *Report) error
+	Delete(ctx context.Context, orgID int64, uid string) error
+	// ClaimDue returns the enabled reports due at the given time, after setting their next run
+	// with the given function, so that the same run of a report is never returned twice.
+	// ClaimDue and InsertHistory return serverlock.ErrStaleFencingToken unless the token is the token of
+	// the current lease to send the reports, InsertHistory does not check noFencingToken.
+	ClaimDue(ctx context.Context, token int64, now int64, nextRun func(*Report) int64) ([]*Report, error)
+	InsertHistory(ctx context.Context, token int64, entry *HistoryEntry) error
+	GetHistory(ctx context.Context, query GetHistoryQuery) ([]HistoryEntry, error)
+	DeleteHistoryOlderThan(ctx context.Context, created int64) (int64, error)
+}
+
+type dbStore struct {
+	db db.DB
+}
+
+func (s *dbStore) Get(ctx context.Context, orgID int64, uid string) (*Report, error) {
+	report := &Report{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(report)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *dbStore) List(ctx context.Context, orgID int64) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("name").Find(&reports)
+	})
+	return reports, err
+}
+
+func (s *dbStore) Insert(ctx context.Context, report *Report) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(report)
+		return err
+	})
+}
+
+func (s *dbStore) Update(ctx context.Context, report *Report) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.ID(report.ID).AllCols().Update(report)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrReportNotFound
+		}
+		return nil
+	})
+}
+
+func (s *dbStore) Delete(ctx context.Context, orgID int64, uid string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&Report{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrReportNotFound
+		}
+		_, err = sess.Exec("DELETE FROM dashboard_report_history WHERE org_id = ? AND report_uid = ?", orgID, uid)
+		return err
+	})
+}
+
+func (s *dbStore) ClaimDue(ctx context.Context, token int64, now int64, nextRun func(*Report) int64) ([]*Report, error) {
+	claimed := make([]*Report, 0)
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if err := serverlock.ValidateFencingTokenInSession(sess, lockActionName, token); err != nil {
+			return err
+		}
+
+		due := make([]*Report, 0)
+		if err := sess.Where("enabled = ? AND next_run <= ?", true, now).Find(&due); err != nil {
+			return err
+		}
+
+		for _, report := range due {
+			next := nextRun(report)
+			// only claim the report if no other server updated it in the meantime
+			affected, err := sess.Table(&Report{}).
+				Where("id = ? AND next_run = ?", report.ID, report.NextRun).
+				Cols("next_run").
+				Update(map[string]any{"next_run": next})
+			if err != nil {
+				return err
+			}
+			if affected == 1 {
+				report.NextRun = next
+				claimed = append(claimed, report)
+			}
+		}
+		return nil
+	})
+	return claimed, err
+}
+
+func (s *dbStore) InsertHistory(ctx context.Context, token int64, entry *HistoryEntry) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if token != noFencingToken {
+			if err := serverlock.ValidateFencingTokenInSession(sess, lockActionName, token); err != nil {
+				return err
+			}
+		}
+		_, err := sess.Insert(entry)
+		return err
+	})
+}
+
+// GetHistory returns the most recent entries first.
+func (s *dbStore) GetHistory(ctx context.Context, query GetHistoryQuery) ([]HistoryEntry, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultHistoryLimit
+	}
+
+	entries := make([]HistoryEntry, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND report_uid = ?", query.OrgID, query.ReportUID).
+			Desc("created").Limit(query.Limit).Find(&entries)
+	})
+	return entries, err
+}
+
+func (s *dbStore) DeleteHistoryOlderThan(ctx context.Context, created int64) (int64, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM dashboard_report_history WHERE created < ?", created)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,187 @@
+package dashboardreports
+
+import (
+	"errors"
+)
+
+const (
+	FormatPNG = "png"
+	FormatPDF = "pdf"
+
+	StatusSent   = "sent"
+	StatusFailed = "failed"
+)
+
+var (
+	ErrReportNotFound      = errors.New("report not found")
+	ErrInvalidSchedule     = errors.New("invalid schedule")
+	ErrInvalidTimezone     = errors.New("invalid timezone")
+	ErrInvalidRecipients   = errors.New("invalid recipients")
+	ErrInvalidFormat       = errors.New("invalid format, must be png or pdf")
+	ErrMissingName         = errors.New("report name is required")
+	ErrMissingDashboardUID = errors.New("dashboard uid is required")
+)
+
+// Report is a scheduled dashboard report.
+type Report struct {
+	ID           int64               `xorm:"pk autoincr 'id'"`
+	UID          string              `xorm:"uid"`
+	OrgID        int64               `xorm:"org_id"`
+	Name         string              `xorm:"name"`
+	DashboardUID string              `xorm:"dashboard_uid"`
+	PanelIDs     []int64             `xorm:"panel_ids"`
+	Schedule     string              `xorm:"schedule"`
+	Timezone     string              `xorm:"timezone"`
+	Recipients   []string            `xorm:"recipients"`
+	Message      string              `xorm:"message"`
+	TimeFrom     string              `xorm:"time_from"`
+	TimeTo       string              `xorm:"time_to"`
+	Variables    map[string][]string `xorm:"variables"`
+	Format       string              `xorm:"format"`
+	Enabled      bool                `xorm:"enabled"`
+	// CreatedBy is the user who created the report
+	CreatedBy int64 `xorm:"created_by"`
+	// RunAs is the user the dashboard is rendered as, the user who created or last updated the report
+	RunAs int64 `xorm:"run_as"`
+	// NextRun is the unix timestamp in milliseconds of the next time the report is sent
+	NextRun int64 `xorm:"next_run"`
+	Created int64 `xorm:"'created'"`
+	Updated int64 `xorm:"'updated'"`
+}
+
+func (Report) TableName() string {
+	return "dashboard_report"
+}
+
+// HistoryEntry records a single sending of a report.
+type HistoryEntry struct {
+	ID         int64    `xorm:"pk autoincr 'id'"`
+	OrgID      int64    `xorm:"org_id"`
+	ReportUID  string   `xorm:"report_uid"`
+	Status     string   `xorm:"status"`
+	Message    string   `xorm:"message"`
+	Recipients []string `xorm:"recipients"`
+	DurationMs int64    `xorm:"duration_ms"`
+	Created    int64    `xorm:"'created'"`
+}
+
+func (HistoryEntry) TableName() string {
+	return "dashboard_report_history"
+}
+
+// ReportDTO is the API representation of a report.
+type ReportDTO struct {
+	UID          string              `json:"uid"`
+	Name         string              `json:"name"`
+	DashboardUID string              `json:"dashboardUid"`
+	PanelIDs     []int64             `json:"panelIds"`
+	Schedule     string              `json:"schedule"`
+	Timezone     string              `json:"timezone"`
+	Recipients   []string            `json:"recipients"`
+	Message      string              `json:"message"`
+	TimeFrom     string              `json:"timeFrom"`
+	TimeTo       string              `json:"timeTo"`
+	Variables    map[string][]string `json:"variables"`
+	Format       string              `json:"format"`
+	Enabled      bool                `json:"enabled"`
+	// CreatedBy is the user who created the report.
+	CreatedBy int64 `json:"createdBy"`
+	// RunAs is the user the report is sent as, who created or last updated it.
+	RunAs int64 `json:"runAs"`
+	// NextRun is the unix timestamp in milliseconds of the next time the report is sent, zero when disabled.
+	NextRun int64 `json:"nextRun"`
+	Created int64 `json:"created"`
+	Updated int64 `json:"updated"`
+}
+
+// HistoryEntryDTO is the API representation of a sent report.
+type HistoryEntryDTO struct {
+	Status     string   `json:"status"`
+	Message    string   `json:"message"`
+	Recipients []string `json:"recipients"`
+	DurationMs int64    `json:"durationMs"`
+	// Time is the unix timestamp of the sending in milliseconds.
+	Time int64 `json:"time"`
+}
+
+// ReportSpec contains the fields of a report that can be set by users.
+type ReportSpec struct {
+	// Name of the report, used in the subject of the email.
+	Name string `json:"name"`
+	// UID of the dashboard to send.
+	DashboardUID string `json:"dashboardUid"`
+	// IDs of the panels to include. All panels are included when empty.
+	PanelIDs []int64 `json:"panelIds"`
+	// Cron expression of the schedule, for example `0 9 * * 1` for every Monday at 9am.
+	Schedule string `json:"schedule"`
+	// IANA time zone the schedule is evaluated in. Defaults to UTC.
+	Timezone string `json:"timezone"`
+	// Email addresses of the recipients.
+	Recipients []string `json:"recipients"`
+	// Message included in the body of the email.
+	Message string `json:"message"`
+	// Time range of the report, in epoch milliseconds or relative using Grafana time units. Defaults to the dashboard time range.
+	TimeFrom string `json:"timeFrom"`
+	TimeTo   string `json:"timeTo"`
+	// Values of the dashboard variables.
+	Variables map[string][]string `json:"variables"`
+	// Format of the attachments: png for an image per panel, or pdf for a document with a page per panel.
+	Format  string `json:"format"`
+	Enabled bool   `json:"enabled"`
+}
+
+type CreateReportCommand struct {
+	ReportSpec
+	OrgID     int64 `json:"-"`
+	CreatedBy int64 `json:"-"`
+}
+
+type UpdateReportCommand struct {
+	ReportSpec
+	UID       string `json:"-"`
+	OrgID     int64  `json:"-"`
+	UpdatedBy int64  `json:"-"`
+}
+
+// GetHistoryQuery selects the history of a single report.
+type GetHistoryQuery struct {
+	OrgID     int64
+	ReportUID string
+	Limit     int
+}
+
+func (r *Report) toDTO() ReportDTO {
+	dto := ReportDTO{
+		UID:          r.UID,
+		Name:         r.Name,
+		DashboardUID: r.DashboardUID,
+		PanelIDs:     r.PanelIDs,
+		Schedule:     r.Schedule,
+		Timezone:     r.Timezone,
+		Recipients:   r.Recipients,
+		Message:      r.Message,
+		TimeFrom:     r.TimeFrom,
+		TimeTo:       r.TimeTo,
+		Variables:    r.Variables,
+		Format:       r.Format,
+		Enabled:      r.Enabled,
+		CreatedBy:    r.CreatedBy,
+		RunAs:        r.RunAs,
+		Created:      r.Created,
+		Updated:      r.Updated,
+	}
+	if r.Enabled {
+		dto.NextRun = r.NextRun
+	}
+	return dto
+}
+
+func (h HistoryEntry) toDTO() HistoryEntryDTO {
+	return HistoryEntryDTO{
+		Status:     h.Status,
+		Message:    h.Message,
+		Recipients: h.Recipients,
+		DurationMs: h.DurationMs,
+		Time:       h.Created,
+	}
+}
@@ -0,0 +1,222 @@
+package dashboardreports
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	defaultTimeFrom = "now-6h"
+	defaultTimeTo   = "now"
+
+	emailTemplate = "report"
+)
+
+type panelImage struct {
+	ID    int64
+	Title string
+	Image []byte
+}
+
+// deliver renders the panels of the report as its RunAs user and emails them to the recipients.
+func (s *Service) deliver(ctx context.Context, report *Report) error {
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: report.OrgID, UID: report.DashboardUID})
+	if err != nil {
+		return fmt.Errorf("failed to get dashboard: %w", err)
+	}
+
+	usr, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: report.RunAs, OrgID: report.OrgID})
+	if err != nil {
+		return fmt.Errorf("failed to get the user of the report: %w", err)
+	}
+
+	if !s.renderService.IsAvailable(ctx) {
+		return rendering.ErrRenderUnavailable
+	}
+
+	from, to := report.TimeFrom, report.TimeTo
+	if from == "" {
+		from = dash.Data.GetPath("time", "from").MustString(defaultTimeFrom)
+	}
+	if to == "" {
+		to = dash.Data.GetPath("time", "to").MustString(defaultTimeTo)
+	}
+
+	params := url.Values{}
+	params.Set("orgId", strconv.FormatInt(report.OrgID, 10))
+	params.Set("from", from)
+	params.Set("to", to)
+	for name, values := range report.Variables {
+		for _, v := range values {
+			params.Add("var-"+name, v)
+		}
+	}
+
+	panels := reportPanels(dash.Data, report.PanelIDs)
+	if len(panels) == 0 {
+		return fmt.Errorf("no panels to render in dashboard %s", dash.UID)
+	}
+
+	images := make([]panelImage, 0, len(panels))
+	for _, panel := range panels {
+		image, err := s.renderPanel(ctx, usr, report, dash, panel.ID, params)
+		if err != nil {
+			return fmt.Errorf("failed to render panel %d: %w", panel.ID, err)
+		}
+		panel.Image = image
+		images = append(images, panel)
+	}
+
+	var attachments []*notifications.SendEmailAttachFile
+	switch report.Format {
+	case FormatPDF:
+		content, err := buildPDF(dash.Title, images)
+		if err != nil {
+			return fmt.Errorf("failed to build pdf: %w", err)
+		}
+		attachments = append(attachments, &notifications.SendEmailAttachFile{
+			Name:    dash.Slug + ".pdf",
+			Content: content,
+		})
+	default:
+		for _, image := range images {
+			attachments = append(attachments, &notifications.SendEmailAttachFile{
+				Name:    fmt.Sprintf("%s-panel-%d.png", dash.Slug, image.ID),
+				Content: image.Image,
+			})
+		}
+	}
+
+	return s.notificationService.SendEmailCommandHandlerSync(ctx, &notifications.SendEmailCommandSync{
+		SendEmailCommand: notifications.SendEmailCommand{
+			To:       report.Recipients,
+			Template: emailTemplate,
+			Data: map[string]any{
+				"Name":           report.Name,
+				"Message":        report.Message,
+				"DashboardTitle": dash.Title,
+				"TimeRange":      fmt.Sprintf("%s to %s", from, to),
+				"DashboardURL":   setting.ToAbsUrl(fmt.Sprintf("d/%s/%s?%s", dash.UID, dash.Slug, params.Encode())),
+			},
+			AttachedFiles: attachments,
+		},
+	})
+}
+
+func (s *Service) renderPanel(ctx context.Context, usr *user.SignedInUser, report *Report, dash *dashboards.Dashboard, panelID int64, params url.Values) ([]byte, error) {
+	panelParams := url.Values{}
+	for k, v := range params {
+		panelParams[k] = v
+	}
+	panelParams.Set("panelId", strconv.FormatInt(panelID, 10))
+
+	result, err := s.renderService.Render(ctx, rendering.Opts{
+		TimeoutOpts: rendering.TimeoutOpts{Timeout: s.cfg.RenderTimeout},
+		AuthOpts: rendering.AuthOpts{
+			OrgID:   report.OrgID,
+			UserID:  usr.UserID,
+			OrgRole: usr.OrgRole,
+		},
+		ErrorOpts: rendering.ErrorOpts{
+			ErrorConcurrentLimitReached: true,
+			ErrorRenderUnavailable:      true,
+		},
+		Width:           s.cfg.Width,
+		Height:          s.cfg.Height,
+		Path:            fmt.Sprintf("d-solo/%s/%s?%s", dash.UID, dash.Slug, panelParams.Encode()),
+		Timezone:        report.Timezone,
+		ConcurrentLimit: 1,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := os.Remove(result.FilePath); err != nil {
+			s.log.Warn("Failed to remove rendered image", "path", result.FilePath, "error", err)
+		}
+	}()
+
+	// #nosec G304 -- the path is created by the rendering service
+	return os.ReadFile(result.FilePath)
+}
+
+// reportPanels returns the panels of the dashboard in the order they are displayed, including the panels of
+// collapsed rows. When panel IDs are given, only those panels are returned.
+func reportPanels(dash *simplejson.Json, panelIDs []int64) []panelImage {
+	include := make(map[int64]bool, len(panelIDs))
+	for _, id := range panelIDs {
+		include[id] = true
+	}
+
+	var panels []panelImage
+	add := func(panel *simplejson.Json) {
+		id := panel.Get("id").MustInt64()
+		if len(include) > 0 && !include[id] {
+			return
+		}
+		panels = append(panels, panelImage{ID: id, Title: panel.Get("title").MustString()})
+	}
+
+	for _, panelObj := range dash.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(panelObj)
+		if panel.Get("type").MustString() == "row" {
+			for _, rowPanelObj := range panel.Get("panels").MustArray() {
+				add(simplejson.NewFromAny(rowPanelObj))
+			}
+			continue
+		}
+		add(panel)
+	}
+	return panels
+}
+
+// buildPDF assembles the panel images in a landscape A4 document with one page per panel.
+func buildPDF(title string, images []panelImage) ([]byte, error) {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, pageHeight := pdf.GetPageSize()
+	left, _, right, bottom := pdf.GetMargins()
+
+	for _, image := range images {
+		pdf.AddPage()
+		pdf.SetFont("Helvetica", "B", 16)
+		pdf.CellFormat(0, 10, tr(title), "", 1, "L", false, 0, "")
+		if image.Title != "" {
+			pdf.SetFont("Helvetica", "", 12)
+			pdf.CellFormat(0, 8, tr(image.Title), "", 1, "L", false, 0, "")
+		}
+
+		name := fmt.Sprintf("panel-%d", image.ID)
+		info := pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(image.Image))
+		if pdf.Err() {
+			return nil, pdf.Error()
+		}
+
+		// scale the image to fit the rest of the page, keeping its aspect ratio
+		y := pdf.GetY() + 2
+		maxWidth, maxHeight := pageWidth-left-right, pageHeight-y-bottom
+		width, height := info.Extent()
+		scale := math.Min(maxWidth/width, maxHeight/height)
+		pdf.ImageOptions(name, left, y, width*scale, height*scale, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
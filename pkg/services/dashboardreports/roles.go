@@ -0,0 +1,56 @@
+package dashboardreports
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const (
+	ActionRead   = "dashboards.reports:read"
+	ActionCreate = "dashboards.reports:create"
+	ActionWrite  = "dashboards.reports:write"
+	ActionDelete = "dashboards.reports:delete"
+)
+
+var (
+	ScopeProvider = accesscontrol.NewScopeProvider("dashboards.reports")
+	ScopeAll      = ScopeProvider.GetResourceAllScope()
+)
+
+var (
+	reportReaderRole = accesscontrol.RoleDTO{
+		Name:        "fixed:dashboards.reports:reader",
+		DisplayName: "Dashboard report reader",
+		Description: "List dashboard reports and read their history",
+		Group:       "Dashboard reports",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionRead, Scope: ScopeAll},
+		},
+	}
+
+	reportWriterRole = accesscontrol.RoleDTO{
+		Name:        "fixed:dashboards.reports:writer",
+		DisplayName: "Dashboard report writer",
+		Description: "Create, update, delete and send dashboard reports",
+		Group:       "Dashboard reports",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionRead, Scope: ScopeAll},
+			{Action: ActionCreate},
+			{Action: ActionWrite, Scope: ScopeAll},
+			{Action: ActionDelete, Scope: ScopeAll},
+		},
+	}
+)
+
+func declareFixedRoles(service accesscontrol.Service) error {
+	reportReader := accesscontrol.RoleRegistration{
+		Role:   reportReaderRole,
+		Grants: []string{string(org.RoleAdmin)},
+	}
+	reportWriter := accesscontrol.RoleRegistration{
+		Role:   reportWriterRole,
+		Grants: []string{string(org.RoleAdmin)},
+	}
+
+	return service.DeclareFixedRoles(reportReader, reportWriter)
+}
@@ -0,0 +1,407 @@
+package dashboardreports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	lockActionName = "send dashboard reports"
+	// leaseTTL is renewed while the reports are sent, a server that stopped releases the reports after it
+	leaseTTL = time.Minute
+)
+
+type serverLocker interface {
+	LeaseExecuteAndRelease(ctx context.Context, name string, ttl time.Duration, fn func(ctx context.Context, lease *serverlock.Lease)) error
+	ValidateFencingToken(ctx context.Context, name string, token int64) error
+}
+
+type Service struct {
+	cfg                 setting.DashboardReportsSettings
+	store               store
+	routeRegister       routing.RouteRegister
+	accessControl       accesscontrol.AccessControl
+	dashboardService    dashboards.DashboardService
+	renderService       rendering.Service
+	notificationService notifications.EmailSender
+	userService         user.Service
+	serverLock          serverLocker
+	log                 log.Logger
+	now                 func() time.Time
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, ac accesscontrol.AccessControl,
+	acService accesscontrol.Service, dashboardService dashboards.DashboardService, renderService rendering.Service,
+	notificationService notifications.Service, userService user.Service, serverLock *serverlock.ServerLockService,
+) (*Service, error) {
+	s := &Service{
+		cfg:                 cfg.DashboardReports,
+		store:               &dbStore{db: sqlStore},
+		routeRegister:       routeRegister,
+		accessControl:       ac,
+		dashboardService:    dashboardService,
+		renderService:       renderService,
+		notificationService: notificationService,
+		userService:         userService,
+		serverLock:          serverLock,
+		log:                 log.New("dashboard.reports"),
+		now:                 time.Now,
+	}
+
+	if !s.cfg.Enabled {
+		return s, nil
+	}
+
+	if err := declareFixedRoles(acService); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints()
+
+	return s, nil
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled
+}
+
+// Run periodically sends the reports that are due. Only one server of an HA setup sends
+// reports at a time, and each run of a report is claimed in the database before it is sent.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		s.sendDue(ctx)
+		s.deleteExpiredHistory(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) sendDue(ctx context.Context) {
+	err := s.serverLock.LeaseExecuteAndRelease(ctx, lockActionName, leaseTTL, func(ctx context.Context, lease *serverlock.Lease) {
+		now := s.now()
+		reports, err := s.store.ClaimDue(ctx, lease.Token(), now.UnixMilli(), func(r *Report) int64 {
+			next, err := nextRun(r.Schedule, r.Timezone, now)
+			if err != nil {
+				s.log.Error("Failed to compute next run of report", "report", r.UID, "error", err)
+				// retry in a day rather than on every check
+				return now.Add(24 * time.Hour).UnixMilli()
+			}
+			return next
+		})
+		if errors.Is(err, serverlock.ErrStaleFencingToken) {
+			s.log.Warn("Lost the lease to send reports before claiming them")
+			return
+		}
+		if err != nil {
+			s.log.Error("Failed to get due reports", "error", err)
+			return
+		}
+
+		for _, report := range reports {
+			if ctx.Err() != nil {
+				return
+			}
+			err := s.send(ctx, lease.Token(), report)
+			if errors.Is(err, serverlock.ErrStaleFencingToken) {
+				s.log.Warn("Lost the lease to send reports, the remaining reports are not sent", "report", report.UID)
+				return
+			}
+			if err != nil {
+				s.log.Warn("Failed to send report", "report", report.UID, "orgId", report.OrgID, "error", err)
+			}
+		}
+	})
+
+	var lockErr *serverlock.LeaseHeldError
+	if errors.As(err, &lockErr) {
+		s.log.Debug("Reports are being sent by another server")
+		return
+	}
+	if err != nil {
+		s.log.Error("Failed to acquire the lock to send reports", "error", err)
+	}
+}
+
+func (s *Service) deleteExpiredHistory(ctx context.Context) {
+	if s.cfg.HistoryRetention <= 0 {
+		return
+	}
+
+	affected, err := s.store.DeleteHistoryOlderThan(ctx, s.now().Add(-s.cfg.HistoryRetention).UnixMilli())
+	if err != nil {
+		s.log.Error("Failed to delete expired report history", "error", err)
+		return
+	}
+	s.log.Debug("Deleted expired report history", "count", affected)
+}
+
+// send renders and emails the report, and records the result in the history of the report. It returns
+// serverlock.ErrStaleFencingToken if the lease was lost before the report was sent, or before the history of
+// a failure was recorded. The history of a report that was sent is always recorded.
+func (s *Service) send(ctx context.Context, token int64, report *Report) error {
+	// the lease is checked right before the delivery, so that a server whose lease expired doesn't send
+	// the report again after the server that acquired the lease since
+	if token != noFencingToken {
+		if err := s.serverLock.ValidateFencingToken(ctx, lockActionName, token); err != nil {
+			return err
+		}
+	}
+
+	start := s.now()
+	err := s.deliver(ctx, report)
+
+	entry := &HistoryEntry{
+		OrgID:      report.OrgID,
+		ReportUID:  report.UID,
+		Status:     StatusSent,
+		Recipients: report.Recipients,
+		DurationMs: s.now().Sub(start).Milliseconds(),
+		Created:    start.UnixMilli(),
+	}
+	// the email was sent, so its history is recorded even if the lease was lost during the delivery
+	historyToken := noFencingToken
+	if err != nil {
+		entry.Status = StatusFailed
+		entry.Message = err.Error()
+		historyToken = token
+	}
+	if err := s.store.InsertHistory(ctx, historyToken, entry); err != nil {
+		if errors.Is(err, serverlock.ErrStaleFencingToken) {
+			return err
+		}
+		s.log.Error("Failed to save report history", "report", report.UID, "error", err)
+	}
+
+	return err
+}
+
+func (s *Service) Get(ctx context.Context, orgID int64, uid string) (*ReportDTO, error) {
+	report, err := s.store.Get(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	dto := report.toDTO()
+	return &dto, nil
+}
+
+func (s *Service) List(ctx context.Context, orgID int64) ([]ReportDTO, error) {
+	reports, err := s.store.List(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ReportDTO, 0, len(reports))
+	for _, r := range reports {
+		result = append(result, r.toDTO())
+	}
+	return result, nil
+}
+
+func (s *Service) Create(ctx context.Context, cmd CreateReportCommand) (*ReportDTO, error) {
+	spec, err := s.validate(ctx, cmd.OrgID, cmd.ReportSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	report := &Report{
+		UID:       util.GenerateShortUID(),
+		OrgID:     cmd.OrgID,
+		CreatedBy: cmd.CreatedBy,
+		RunAs:     cmd.CreatedBy,
+		Created:   now.UnixMilli(),
+		Updated:   now.UnixMilli(),
+	}
+	if err := applySpec(report, spec, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Insert(ctx, report); err != nil {
+		return nil, err
+	}
+	dto := report.toDTO()
+	return &dto, nil
+}
+
+func (s *Service) Update(ctx context.Context, cmd UpdateReportCommand) (*ReportDTO, error) {
+	report, err := s.store.Get(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	spec, err := s.validate(ctx, cmd.OrgID, cmd.ReportSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	report.Updated = now.UnixMilli()
+	// the dashboard access of the user updating the report is checked, so the report is now sent as this user
+	report.RunAs = cmd.UpdatedBy
+	if err := applySpec(report, spec, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.Update(ctx, report); err != nil {
+		return nil, err
+	}
+	dto := report.toDTO()
+	return &dto, nil
+}
+
+func (s *Service) Delete(ctx context.Context, orgID int64, uid string) error {
+	return s.store.Delete(ctx, orgID, uid)
+}
+
+// Send sends the report immediately, without changing its schedule.
+func (s *Service) Send(ctx context.Context, orgID int64, uid string) error {
+	report, err := s.store.Get(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, noFencingToken, report)
+}
+
+// GetHistory returns the history of a report, most recent first.
+func (s *Service) GetHistory(ctx context.Context, query GetHistoryQuery) ([]HistoryEntryDTO, error) {
+	if _, err := s.store.Get(ctx, query.OrgID, query.ReportUID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.store.GetHistory(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]HistoryEntryDTO, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, e.toDTO())
+	}
+	return result, nil
+}
+
+// validate checks the report spec and sets the default values of the optional fields.
+func (s *Service) validate(ctx context.Context, orgID int64, spec ReportSpec) (ReportSpec, error) {
+	if spec.Name == "" {
+		return spec, ErrMissingName
+	}
+	if spec.DashboardUID == "" {
+		return spec, ErrMissingDashboardUID
+	}
+
+	if spec.Timezone == "" {
+		spec.Timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(spec.Timezone); err != nil {
+		return spec, fmt.Errorf("%w: %s", ErrInvalidTimezone, err)
+	}
+	sched, err := cron.ParseStandard(spec.Schedule)
+	if err != nil {
+		return spec, fmt.Errorf("%w: %s", ErrInvalidSchedule, err)
+	}
+	// the due reports are only sent every CheckInterval, a shorter schedule would not be followed
+	if interval := minInterval(sched, s.now()); interval < s.cfg.CheckInterval {
+		return spec, fmt.Errorf("%w: the report must not be sent more often than every %s", ErrInvalidSchedule, s.cfg.CheckInterval)
+	}
+
+	if len(spec.Recipients) == 0 {
+		return spec, fmt.Errorf("%w: at least one recipient is required", ErrInvalidRecipients)
+	}
+	for _, r := range spec.Recipients {
+		if _, err := mail.ParseAddress(r); err != nil {
+			return spec, fmt.Errorf("%w: %q: %s", ErrInvalidRecipients, r, err)
+		}
+	}
+
+	switch spec.Format {
+	case "":
+		spec.Format = FormatPNG
+	case FormatPNG, FormatPDF:
+	default:
+		return spec, ErrInvalidFormat
+	}
+
+	if _, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: orgID, UID: spec.DashboardUID}); err != nil {
+		return spec, err
+	}
+
+	return spec, nil
+}
+
+func applySpec(report *Report, spec ReportSpec, now time.Time) error {
+	report.Name = spec.Name
+	report.DashboardUID = spec.DashboardUID
+	report.PanelIDs = spec.PanelIDs
+	report.Schedule = spec.Schedule
+	report.Timezone = spec.Timezone
+	report.Recipients = spec.Recipients
+	report.Message = spec.Message
+	report.TimeFrom = spec.TimeFrom
+	report.TimeTo = spec.TimeTo
+	report.Variables = spec.Variables
+	report.Format = spec.Format
+	report.Enabled = spec.Enabled
+
+	next, err := nextRun(report.Schedule, report.Timezone, now)
+	if err != nil {
+		return err
+	}
+	report.NextRun = next
+	return nil
+}
+
+// nextRun returns the unix timestamp in milliseconds of the first time after now matching the schedule
+// minInterval returns the shortest time between two of the next runs of the schedule
+func minInterval(sched cron.Schedule, from time.Time) time.Duration {
+	const runs = 100
+
+	interval := time.Duration(math.MaxInt64)
+	prev := sched.Next(from)
+	for i := 0; i < runs && !prev.IsZero(); i++ {
+		next := sched.Next(prev)
+		if next.IsZero() {
+			break
+		}
+		if d := next.Sub(prev); d < interval {
+			interval = d
+		}
+		prev = next
+	}
+	return interval
+}
+
+func nextRun(schedule string, timezone string, now time.Time) (int64, error) {
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidSchedule, err)
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidTimezone, err)
+	}
+	return sched.Next(now.In(loc)).UnixMilli(), nil
+}
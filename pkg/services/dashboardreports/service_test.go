@@ -0,0 +1,527 @@
+package dashboardreports
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestNextRun(t *testing.T) {
+	now := time.Date(2023, 6, 5, 10, 0, 0, 0, time.UTC) // a Monday
+
+	next, err := nextRun("0 9 * * 1", "UTC", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 6, 12, 9, 0, 0, 0, time.UTC).UnixMilli(), next)
+
+	// 9am in New York is 1pm UTC in June
+	next, err = nextRun("0 9 * * 1", "America/New_York", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 6, 5, 13, 0, 0, 0, time.UTC).UnixMilli(), next)
+
+	_, err = nextRun("every monday", "UTC", now)
+	require.ErrorIs(t, err, ErrInvalidSchedule)
+}
+
+func TestService_Create(t *testing.T) {
+	valid := ReportSpec{
+		Name:         "Weekly",
+		DashboardUID: "dash",
+		Schedule:     "0 9 * * 1",
+		Recipients:   []string{"ops@example.com"},
+		Enabled:      true,
+	}
+
+	t.Run("sets defaults and next run", func(t *testing.T) {
+		s, st, _ := setupTestService(t)
+
+		report, err := s.Create(context.Background(), CreateReportCommand{ReportSpec: valid, OrgID: 1, CreatedBy: 2})
+		require.NoError(t, err)
+		require.NotEmpty(t, report.UID)
+		require.Equal(t, "UTC", report.Timezone)
+		require.Equal(t, FormatPNG, report.Format)
+		require.Equal(t, int64(2), report.CreatedBy)
+		require.Equal(t, int64(2), report.RunAs)
+		require.Greater(t, report.NextRun, s.now().UnixMilli())
+		require.Len(t, st.reports, 1)
+	})
+
+	t.Run("can be sent as often as the reports are checked", func(t *testing.T) {
+		s, _, _ := setupTestService(t)
+		spec := valid
+
+		spec.Schedule = "* * * * *"
+		_, err := s.Create(context.Background(), CreateReportCommand{ReportSpec: spec, OrgID: 1})
+		require.NoError(t, err)
+
+		// every minute at 9am, and then the next day
+		spec.Schedule = "0,1 9 * * *"
+		s.cfg.CheckInterval = 5 * time.Minute
+		_, err = s.Create(context.Background(), CreateReportCommand{ReportSpec: spec, OrgID: 1})
+		require.ErrorIs(t, err, ErrInvalidSchedule)
+	})
+
+	t.Run("is sent as the user updating it", func(t *testing.T) {
+		s, _, _ := setupTestService(t)
+		created, err := s.Create(context.Background(), CreateReportCommand{ReportSpec: valid, OrgID: 1, CreatedBy: 2})
+		require.NoError(t, err)
+
+		updated, err := s.Update(context.Background(), UpdateReportCommand{ReportSpec: valid, UID: created.UID, OrgID: 1, UpdatedBy: 3})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), updated.CreatedBy)
+		require.Equal(t, int64(3), updated.RunAs)
+	})
+
+	invalid := map[string]struct {
+		modify func(*ReportSpec)
+		err    error
+	}{
+		"missing name":        {func(s *ReportSpec) { s.Name = "" }, ErrMissingName},
+		"missing dashboard":   {func(s *ReportSpec) { s.DashboardUID = "" }, ErrMissingDashboardUID},
+		"invalid schedule":    {func(s *ReportSpec) { s.Schedule = "* *" }, ErrInvalidSchedule},
+		"too short interval":  {func(s *ReportSpec) { s.Schedule = "@every 1s" }, ErrInvalidSchedule},
+		"invalid timezone":    {func(s *ReportSpec) { s.Timezone = "Mars/Olympus" }, ErrInvalidTimezone},
+		"no recipients":       {func(s *ReportSpec) { s.Recipients = nil }, ErrInvalidRecipients},
+		"invalid recipient":   {func(s *ReportSpec) { s.Recipients = []string{"not an email"} }, ErrInvalidRecipients},
+		"invalid format":      {func(s *ReportSpec) { s.Format = "docx" }, ErrInvalidFormat},
+		"dashboard not found": {func(s *ReportSpec) { s.DashboardUID = "missing" }, dashboards.ErrDashboardNotFound},
+	}
+	for name, tc := range invalid {
+		t.Run(name, func(t *testing.T) {
+			s, st, _ := setupTestService(t)
+			spec := valid
+			tc.modify(&spec)
+
+			_, err := s.Create(context.Background(), CreateReportCommand{ReportSpec: spec, OrgID: 1})
+			require.ErrorIs(t, err, tc.err)
+			require.Empty(t, st.reports)
+		})
+	}
+}
+
+func TestService_sendDue(t *testing.T) {
+	now := time.Date(2023, 6, 5, 9, 0, 0, 0, time.UTC)
+	newReport := func(format string) *Report {
+		return &Report{
+			UID:          "weekly",
+			OrgID:        1,
+			Name:         "Weekly",
+			DashboardUID: "dash",
+			Schedule:     "0 9 * * 1",
+			Timezone:     "UTC",
+			Recipients:   []string{"ops@example.com"},
+			Variables:    map[string][]string{"env": {"prod"}},
+			Format:       format,
+			Enabled:      true,
+			CreatedBy:    2,
+			RunAs:        2,
+			NextRun:      now.UnixMilli(),
+		}
+	}
+
+	t.Run("sends a png per panel and records the history", func(t *testing.T) {
+		s, st, ns := setupTestService(t)
+		s.now = func() time.Time { return now }
+		st.reports = []*Report{newReport(FormatPNG)}
+
+		s.sendDue(context.Background())
+
+		require.Equal(t, []string{"ops@example.com"}, ns.EmailSync.To)
+		require.Equal(t, emailTemplate, ns.EmailSync.Template)
+		require.Equal(t, "Weekly", ns.EmailSync.Data["Name"])
+		require.Len(t, ns.EmailSync.AttachedFiles, 2)
+		require.Equal(t, "production-overview-panel-1.png", ns.EmailSync.AttachedFiles[0].Name)
+		require.Equal(t, "production-overview-panel-3.png", ns.EmailSync.AttachedFiles[1].Name)
+
+		renderer := s.renderService.(*fakeRenderer)
+		require.Len(t, renderer.paths, 2)
+		require.Contains(t, renderer.paths[0], "d-solo/dash/production-overview?")
+		require.Contains(t, renderer.paths[0], "var-env=prod")
+		require.Contains(t, renderer.paths[0], "panelId=1")
+
+		require.Len(t, st.history, 1)
+		require.Equal(t, StatusSent, st.history[0].Status)
+		require.Equal(t, time.Date(2023, 6, 12, 9, 0, 0, 0, time.UTC).UnixMilli(), st.reports[0].NextRun)
+
+		// the report is not sent again before its next run
+		ns.EmailSync = notifications.SendEmailCommandSync{}
+		s.sendDue(context.Background())
+		require.Empty(t, ns.EmailSync.To)
+	})
+
+	t.Run("sends a single pdf", func(t *testing.T) {
+		s, st, ns := setupTestService(t)
+		s.now = func() time.Time { return now }
+		report := newReport(FormatPDF)
+		report.PanelIDs = []int64{3}
+		st.reports = []*Report{report}
+
+		s.sendDue(context.Background())
+
+		require.Len(t, ns.EmailSync.AttachedFiles, 1)
+		require.Equal(t, "production-overview.pdf", ns.EmailSync.AttachedFiles[0].Name)
+		require.True(t, bytes.HasPrefix(ns.EmailSync.AttachedFiles[0].Content, []byte("%PDF")))
+		require.Len(t, s.renderService.(*fakeRenderer).paths, 1)
+	})
+
+	t.Run("records failures in the history", func(t *testing.T) {
+		s, st, ns := setupTestService(t)
+		s.now = func() time.Time { return now }
+		st.reports = []*Report{newReport(FormatPNG)}
+		ns.ShouldError = errors.New("smtp not configured")
+
+		s.sendDue(context.Background())
+
+		require.Len(t, st.history, 1)
+		require.Equal(t, StatusFailed, st.history[0].Status)
+		require.Equal(t, "smtp not configured", st.history[0].Message)
+	})
+
+	t.Run("does nothing when another server holds the lock", func(t *testing.T) {
+		s, st, ns := setupTestService(t)
+		s.now = func() time.Time { return now }
+		s.serverLock = &fakeLocker{locked: true}
+		st.reports = []*Report{newReport(FormatPNG)}
+
+		s.sendDue(context.Background())
+
+		require.Empty(t, ns.EmailSync.To)
+		require.Empty(t, st.history)
+	})
+
+	t.Run("does nothing when the lease was lost", func(t *testing.T) {
+		s, st, ns := setupTestService(t)
+		s.now = func() time.Time { return now }
+		st.reports = []*Report{newReport(FormatPNG)}
+		st.staleToken = true
+
+		s.sendDue(context.Background())
+
+		require.Empty(t, ns.EmailSync.To)
+		require.Empty(t, st.history)
+		require.Equal(t, now.UnixMilli(), st.reports[0].NextRun)
+	})
+
+	t.Run("does not send the reports when the lease was lost after claiming them", func(t *testing.T) {
+		s, st, ns := setupTestService(t)
+		s.now = func() time.Time { return now }
+		s.serverLock = &fakeLocker{staleToken: true}
+		st.reports = []*Report{newReport(FormatPNG)}
+
+		s.sendDue(context.Background())
+
+		require.Empty(t, ns.EmailSync.To)
+		require.Empty(t, st.history)
+	})
+
+	t.Run("records the history of the sent reports when the lease was lost during the delivery", func(t *testing.T) {
+		s, st, ns := setupTestService(t)
+		s.now = func() time.Time { return now }
+		st.reports = []*Report{newReport(FormatPNG)}
+		st.staleHistoryToken = true
+
+		s.sendDue(context.Background())
+
+		require.NotEmpty(t, ns.EmailSync.To)
+		require.Len(t, st.history, 1)
+		require.Equal(t, StatusSent, st.history[0].Status)
+	})
+
+	t.Run("does not record the failures when the lease was lost during the delivery", func(t *testing.T) {
+		s, st, ns := setupTestService(t)
+		s.now = func() time.Time { return now }
+		st.reports = []*Report{newReport(FormatPNG)}
+		st.staleHistoryToken = true
+		ns.ShouldError = errors.New("smtp not configured")
+
+		s.sendDue(context.Background())
+
+		require.Empty(t, st.history)
+	})
+}
+
+func TestSendHandler(t *testing.T) {
+	send := func(t *testing.T, canReadDashboard bool) (int, *fakeStore, *notifications.NotificationServiceMock) {
+		s, st, ns := setupTestService(t)
+		s.accessControl = &actest.FakeAccessControl{ExpectedEvaluate: canReadDashboard}
+		st.reports = []*Report{{
+			UID:          "weekly",
+			OrgID:        1,
+			Name:         "Weekly",
+			DashboardUID: "dash",
+			Schedule:     "0 9 * * 1",
+			Timezone:     "UTC",
+			Recipients:   []string{"ops@example.com"},
+			Format:       FormatPDF,
+			Enabled:      true,
+			CreatedBy:    2,
+			RunAs:        2,
+		}}
+
+		req := web.SetURLParams(httptest.NewRequest(http.MethodPost, "/api/dashboard-reports/weekly/send", nil), map[string]string{":uid": "weekly"})
+		c := &contextmodel.ReqContext{
+			Context:      &web.Context{Req: req},
+			SignedInUser: &user.SignedInUser{UserID: 3, OrgID: 1, OrgRole: org.RoleViewer},
+		}
+		return s.sendHandler(c).Status(), st, ns
+	}
+
+	t.Run("sends the report when the user can read the dashboard", func(t *testing.T) {
+		status, st, ns := send(t, true)
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, []string{"ops@example.com"}, ns.EmailSync.To)
+		require.Len(t, st.history, 1)
+	})
+
+	t.Run("does not send the report when the user cannot read the dashboard", func(t *testing.T) {
+		status, st, ns := send(t, false)
+		require.Equal(t, http.StatusForbidden, status)
+		require.Empty(t, ns.EmailSync.To)
+		require.Empty(t, st.history)
+	})
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	st := &dbStore{db: sqlStore}
+	ctx := context.Background()
+
+	serverLock := serverlock.ProvideService(sqlStore, tracing.InitializeTracerForTest())
+	lease, err := serverLock.AcquireLease(ctx, lockActionName, time.Minute)
+	require.NoError(t, err)
+
+	due := &Report{UID: "due", OrgID: 1, Name: "Due", Recipients: []string{"a@example.com"}, Enabled: true, NextRun: 1000}
+	later := &Report{UID: "later", OrgID: 1, Name: "Later", Enabled: true, NextRun: 5000}
+	disabled := &Report{UID: "disabled", OrgID: 1, Name: "Disabled", NextRun: 1000}
+	for _, r := range []*Report{due, later, disabled} {
+		require.NoError(t, st.Insert(ctx, r))
+	}
+
+	report, err := st.Get(ctx, 1, "due")
+	require.NoError(t, err)
+	require.Equal(t, []string{"a@example.com"}, report.Recipients)
+
+	_, err = st.Get(ctx, 2, "due")
+	require.ErrorIs(t, err, ErrReportNotFound)
+
+	_, err = st.ClaimDue(ctx, lease.Token()-1, 2000, func(r *Report) int64 { return 10000 })
+	require.ErrorIs(t, err, serverlock.ErrStaleFencingToken)
+
+	claimed, err := st.ClaimDue(ctx, lease.Token(), 2000, func(r *Report) int64 { return 10000 })
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, "due", claimed[0].UID)
+	require.Equal(t, int64(10000), claimed[0].NextRun)
+
+	claimed, err = st.ClaimDue(ctx, lease.Token(), 2000, func(r *Report) int64 { return 10000 })
+	require.NoError(t, err)
+	require.Empty(t, claimed)
+
+	for _, created := range []int64{1000, 2000, 3000} {
+		require.NoError(t, st.InsertHistory(ctx, lease.Token(), &HistoryEntry{OrgID: 1, ReportUID: "due", Status: StatusSent, Created: created}))
+	}
+	require.NoError(t, lease.Release(ctx))
+	err = st.InsertHistory(ctx, lease.Token(), &HistoryEntry{OrgID: 1, ReportUID: "due", Status: StatusSent, Created: 4000})
+	require.ErrorIs(t, err, serverlock.ErrStaleFencingToken)
+	history, err := st.GetHistory(ctx, GetHistoryQuery{OrgID: 1, ReportUID: "due", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, int64(3000), history[0].Created)
+
+	deleted, err := st.DeleteHistoryOlderThan(ctx, 2500)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), deleted)
+
+	require.NoError(t, st.Delete(ctx, 1, "due"))
+	history, err = st.GetHistory(ctx, GetHistoryQuery{OrgID: 1, ReportUID: "due"})
+	require.NoError(t, err)
+	require.Empty(t, history)
+	require.ErrorIs(t, st.Delete(ctx, 1, "due"), ErrReportNotFound)
+}
+
+func setupTestService(t *testing.T) (*Service, *fakeStore, *notifications.NotificationServiceMock) {
+	t.Helper()
+
+	dash := &dashboards.Dashboard{
+		UID:   "dash",
+		OrgID: 1,
+		Title: "Production overview",
+		Slug:  "production-overview",
+		Data: simplejson.NewFromAny(map[string]any{
+			"time": map[string]any{"from": "now-7d", "to": "now"},
+			"panels": []any{
+				map[string]any{"id": 1, "type": "timeseries", "title": "Requests"},
+				map[string]any{"id": 2, "type": "row", "panels": []any{
+					map[string]any{"id": 3, "type": "stat", "title": "Errors"},
+				}},
+			},
+		}),
+	}
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool {
+		return q.UID == "dash"
+	})).Return(dash, nil).Maybe()
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardNotFound).Maybe()
+
+	st := &fakeStore{}
+	ns := notifications.MockNotificationService()
+	s := &Service{
+		cfg: setting.DashboardReportsSettings{
+			Enabled:       true,
+			CheckInterval: time.Minute,
+			RenderTimeout: time.Second,
+			Width:         1000,
+			Height:        500,
+		},
+		store:               st,
+		dashboardService:    dashboardService,
+		renderService:       &fakeRenderer{dir: t.TempDir()},
+		notificationService: ns,
+		userService: &usertest.FakeUserService{
+			ExpectedSignedInUser: &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleEditor},
+		},
+		serverLock: &fakeLocker{},
+		log:        log.NewNopLogger(),
+		now:        time.Now,
+	}
+	return s, st, ns
+}
+
+type fakeStore struct {
+	reports    []*Report
+	history    []HistoryEntry
+	staleToken bool
+	// staleHistoryToken loses the lease after the reports are claimed
+	staleHistoryToken bool
+}
+
+func (f *fakeStore) Get(_ context.Context, orgID int64, uid string) (*Report, error) {
+	for _, r := range f.reports {
+		if r.OrgID == orgID && r.UID == uid {
+			return r, nil
+		}
+	}
+	return nil, ErrReportNotFound
+}
+
+func (f *fakeStore) List(_ context.Context, _ int64) ([]*Report, error) {
+	return f.reports, nil
+}
+
+func (f *fakeStore) Insert(_ context.Context, report *Report) error {
+	f.reports = append(f.reports, report)
+	return nil
+}
+
+func (f *fakeStore) Update(_ context.Context, _ *Report) error {
+	return nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, _ int64, _ string) error {
+	return nil
+}
+
+func (f *fakeStore) ClaimDue(_ context.Context, _ int64, now int64, nextRun func(*Report) int64) ([]*Report, error) {
+	if f.staleToken {
+		return nil, serverlock.ErrStaleFencingToken
+	}
+	var claimed []*Report
+	for _, r := range f.reports {
+		if r.Enabled && r.NextRun <= now {
+			r.NextRun = nextRun(r)
+			claimed = append(claimed, r)
+		}
+	}
+	return claimed, nil
+}
+
+func (f *fakeStore) InsertHistory(_ context.Context, token int64, entry *HistoryEntry) error {
+	if (f.staleToken || f.staleHistoryToken) && token != noFencingToken {
+		return serverlock.ErrStaleFencingToken
+	}
+	f.history = append(f.history, *entry)
+	return nil
+}
+
+func (f *fakeStore) GetHistory(_ context.Context, _ GetHistoryQuery) ([]HistoryEntry, error) {
+	return f.history, nil
+}
+
+func (f *fakeStore) DeleteHistoryOlderThan(_ context.Context, _ int64) (int64, error) {
+	return 0, nil
+}
+
+type fakeLocker struct {
+	locked     bool
+	staleToken bool
+}
+
+func (f *fakeLocker) LeaseExecuteAndRelease(ctx context.Context, name string, _ time.Duration, fn func(ctx context.Context, lease *serverlock.Lease)) error {
+	if f.locked {
+		return &serverlock.LeaseHeldError{}
+	}
+	fn(ctx, &serverlock.Lease{})
+	return nil
+}
+
+func (f *fakeLocker) ValidateFencingToken(_ context.Context, _ string, _ int64) error {
+	if f.staleToken {
+		return serverlock.ErrStaleFencingToken
+	}
+	return nil
+}
+
+// fakeRenderer writes a small png for every render request
+type fakeRenderer struct {
+	rendering.Service
+	dir   string
+	paths []string
+}
+
+func (f *fakeRenderer) IsAvailable(_ context.Context) bool {
+	return true
+}
+
+func (f *fakeRenderer) Render(_ context.Context, opts rendering.Opts, _ rendering.Session) (*rendering.RenderResult, error) {
+	f.paths = append(f.paths, opts.Path)
+
+	file, err := os.CreateTemp(f.dir, "*.png")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	if err := png.Encode(file, image.NewRGBA(image.Rect(0, 0, opts.Width, opts.Height))); err != nil {
+		return nil, err
+	}
+	return &rendering.RenderResult{FilePath: filepath.Clean(file.Name())}, nil
+}
@@ -3,6 +3,7 @@ package dashboards
 import (
 	"context"
 
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	alertmodels "github.com/grafana/grafana/pkg/services/alerting/models"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/search/model"
@@ -29,6 +30,20 @@ type DashboardService interface {
 	CountInFolder(ctx context.Context, orgID int64, folderUID string, user *user.SignedInUser) (int64, error)
 }
 
+// SaveValidator validates the dashboards that are saved, imported or provisioned. A validator returning an error
+// prevents saving the dashboard.
+type SaveValidator interface {
+	ValidateSave(ctx context.Context, dashboard *Dashboard) error
+}
+
+// CreateHook is called for the dashboards created by saving, importing or provisioning.
+type CreateHook interface {
+	// BeforeCreate is called before a new dashboard is saved, and can change the dashboard.
+	BeforeCreate(ctx context.Context, dashboard *Dashboard) error
+	// CreatePermissions returns the permissions to set on a new dashboard in addition to the default permissions.
+	CreatePermissions(ctx context.Context, dashboard *Dashboard) ([]accesscontrol.SetResourcePermissionCommand, error)
+}
+
 // PluginService is a service for operating on plugin dashboards.
 type PluginService interface {
 	GetDashboardsByPluginID(ctx context.Context, query *GetDashboardsByPluginIDQuery) ([]*Dashboard, error)
@@ -255,6 +255,28 @@ func TestIntegrationDashboardDataAccess(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("Should delete the version retention policy of a deleted folder", func(t *testing.T) {
+		setup()
+		emptyFolder := insertTestDashboard(t, dashboardStore, "2 test dash folder", 1, 0, true, "prod", "webapp")
+		err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Exec("INSERT INTO dashboard_version_retention (org_id, folder_uid, versions_to_keep, daily_versions_days, updated) VALUES (?, ?, ?, ?, ?)",
+				1, emptyFolder.UID, 5, 0, time.Now())
+			return err
+		})
+		require.NoError(t, err)
+
+		err = dashboardStore.DeleteDashboard(context.Background(), &dashboards.DeleteDashboardCommand{ID: emptyFolder.ID, OrgID: 1})
+		require.NoError(t, err)
+
+		err = sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			exists, err := sess.Table("dashboard_version_retention").Where("folder_uid = ?", emptyFolder.UID).Exist()
+			require.NoError(t, err)
+			require.False(t, exists)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
 	t.Run("Should be not able to delete a dashboard if force delete rules is disabled", func(t *testing.T) {
 		setup()
 		deleteCmd := &dashboards.DeleteDashboardCommand{ID: savedFolder.ID, ForceDeleteFolderRules: false}
@@ -49,6 +49,8 @@ type DashboardServiceImpl struct {
 	folderPermissions    accesscontrol.FolderPermissionsService
 	dashboardPermissions accesscontrol.DashboardPermissionsService
 	ac                   accesscontrol.AccessControl
+	saveValidators       []dashboards.SaveValidator
+	createHooks          []dashboards.CreateHook
 }
 
 // This is the uber service that implements a three smaller services
@@ -81,6 +83,16 @@ func ProvideDashboardServiceImpl(
 	return dashSvc, nil
 }
 
+// RegisterSaveValidator adds a validator of the dashboards that are saved, imported or provisioned.
+func (dr *DashboardServiceImpl) RegisterSaveValidator(validator dashboards.SaveValidator) {
+	dr.saveValidators = append(dr.saveValidators, validator)
+}
+
+// RegisterCreateHook adds a hook called for the dashboards created by saving, importing or provisioning.
+func (dr *DashboardServiceImpl) RegisterCreateHook(hook dashboards.CreateHook) {
+	dr.createHooks = append(dr.createHooks, hook)
+}
+
 func (dr *DashboardServiceImpl) GetProvisionedDashboardData(ctx context.Context, name string) ([]*dashboards.DashboardProvisioning, error) {
 	return dr.dashboardStore.GetProvisionedDashboardData(ctx, name)
 }
@@ -124,6 +136,14 @@ func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, d
 		return nil, err
 	}
 
+	if !dash.IsFolder {
+		for _, validator := range dr.saveValidators {
+			if err := validator.ValidateSave(ctx, dash); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if shouldValidateAlerts {
 		dashAlertInfo := alerting.DashAlertInfo{Dash: dash, User: dto.User, OrgID: dash.OrgID}
 		if err := dr.dashAlertExtractor.ValidateAlerts(ctx, dashAlertInfo); err != nil {
@@ -136,6 +156,15 @@ func (dr *DashboardServiceImpl) BuildSaveDashboardCommand(ctx context.Context, d
 		return nil, err
 	}
 
+	// the dashboard has no ID once validated only when it does not overwrite an existing dashboard
+	if dash.ID == 0 && !dash.IsFolder {
+		for _, hook := range dr.createHooks {
+			if err := hook.BeforeCreate(ctx, dash); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if isParentFolderChanged {
 		// Check that the user is allowed to add a dashboard to the folder
 		guardian, err := guardian.NewByDashboard(ctx, dash, dto.OrgID, dto.User)
@@ -507,6 +536,16 @@ func (dr *DashboardServiceImpl) setDefaultPermissions(ctx context.Context, dto *
 		}...)
 	}
 
+	if !dash.IsFolder {
+		for _, hook := range dr.createHooks {
+			hookPermissions, err := hook.CreatePermissions(ctx, dash)
+			if err != nil {
+				return err
+			}
+			permissions = appendMissingPermissions(permissions, hookPermissions)
+		}
+	}
+
 	svc := dr.dashboardPermissions
 	if dash.IsFolder {
 		svc = dr.folderPermissions
@@ -520,6 +559,23 @@ func (dr *DashboardServiceImpl) setDefaultPermissions(ctx context.Context, dto *
 	return nil
 }
 
+// appendMissingPermissions appends the permissions of the users, teams and roles without permission.
+func appendMissingPermissions(permissions []accesscontrol.SetResourcePermissionCommand, other []accesscontrol.SetResourcePermissionCommand) []accesscontrol.SetResourcePermissionCommand {
+	for _, p := range other {
+		found := false
+		for _, existing := range permissions {
+			if existing.UserID == p.UserID && existing.TeamID == p.TeamID && existing.BuiltinRole == p.BuiltinRole {
+				found = true
+				break
+			}
+		}
+		if !found {
+			permissions = append(permissions, p)
+		}
+	}
+	return permissions
+}
+
 func (dr *DashboardServiceImpl) GetDashboard(ctx context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
 	return dr.dashboardStore.GetDashboard(ctx, query)
 }
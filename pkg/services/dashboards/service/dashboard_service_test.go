@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/appcontext"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/folder/foldertest"
@@ -103,6 +104,47 @@ func TestDashboardService(t *testing.T) {
 				require.Equal(t, err, dashboards.ErrDashboardCannotSaveProvisionedDashboard)
 			})
 
+			t.Run("Should return the error of the save validators", func(t *testing.T) {
+				validationErr := errors.New("lint failed")
+				service.RegisterSaveValidator(saveValidatorFunc(func(ctx context.Context, dash *dashboards.Dashboard) error {
+					if dash.Title == "Invalid" {
+						return validationErr
+					}
+					return nil
+				}))
+				t.Cleanup(func() { service.saveValidators = nil })
+
+				dto.Dashboard = dashboards.NewDashboard("Invalid")
+				dto.User = &user.SignedInUser{UserID: 1}
+				_, err := service.SaveDashboard(context.Background(), dto, false)
+				require.Equal(t, validationErr, err)
+
+				// folders are not validated
+				fakeStore.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything, mock.AnythingOfType("bool")).Return(true, nil).Once()
+				dto.Dashboard = dashboards.NewDashboardFolder("Invalid")
+				_, err = service.BuildSaveDashboardCommand(context.Background(), dto, false, false)
+				require.NoError(t, err)
+			})
+
+			t.Run("Should run the create hooks on new dashboards only", func(t *testing.T) {
+				service.RegisterCreateHook(&fakeCreateHook{})
+				t.Cleanup(func() { service.createHooks = nil })
+
+				fakeStore.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything, mock.AnythingOfType("bool")).Return(true, nil).Once()
+				dto.Dashboard = dashboards.NewDashboard("New")
+				dto.User = &user.SignedInUser{UserID: 1}
+				cmd, err := service.BuildSaveDashboardCommand(context.Background(), dto, false, false)
+				require.NoError(t, err)
+				require.Equal(t, "1m", cmd.Dashboard.Get("refresh").MustString())
+
+				fakeStore.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything, mock.AnythingOfType("bool")).Return(true, nil).Once()
+				dto.Dashboard = dashboards.NewDashboard("Existing")
+				dto.Dashboard.SetID(3)
+				cmd, err = service.BuildSaveDashboardCommand(context.Background(), dto, false, false)
+				require.NoError(t, err)
+				require.Empty(t, cmd.Dashboard.Get("refresh").MustString())
+			})
+
 			t.Run("Should not return validation error if dashboard is provisioned but UI updates allowed", func(t *testing.T) {
 				fakeStore.On("ValidateDashboardBeforeSave", mock.Anything, mock.Anything, mock.AnythingOfType("bool")).Return(true, nil).Once()
 				fakeStore.On("SaveDashboard", mock.Anything, mock.AnythingOfType("dashboards.SaveDashboardCommand")).Return(&dashboards.Dashboard{Data: simplejson.New()}, nil).Once()
@@ -286,3 +328,20 @@ func TestDashboardService(t *testing.T) {
 		})
 	})
 }
+
+type fakeCreateHook struct{}
+
+func (h *fakeCreateHook) BeforeCreate(ctx context.Context, dash *dashboards.Dashboard) error {
+	dash.Data.Set("refresh", "1m")
+	return nil
+}
+
+func (h *fakeCreateHook) CreatePermissions(ctx context.Context, dash *dashboards.Dashboard) ([]accesscontrol.SetResourcePermissionCommand, error) {
+	return nil, nil
+}
+
+type saveValidatorFunc func(ctx context.Context, dash *dashboards.Dashboard) error
+
+func (f saveValidatorFunc) ValidateSave(ctx context.Context, dash *dashboards.Dashboard) error {
+	return f(ctx, dash)
+}
@@ -0,0 +1,154 @@
+// Package variables replaces dashboard template variables in query models on the server,
+// for the features that execute the queries of a dashboard without the frontend.
+package variables
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// AllValue is the value of a variable with the All option selected
+const AllValue = "$__all"
+
+// variableRegex matches the $var, ${var}, ${var:format} and [[var]] variable syntaxes
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?::(\w+))?\}`)
+
+// CurrentValue returns the value currently selected for a variable of the dashboard templating list
+func CurrentValue(variable *simplejson.Json) []string {
+	current := variable.GetPath("current", "value")
+	if s, err := current.String(); err == nil {
+		return []string{s}
+	}
+	return current.MustStringArray()
+}
+
+// AllValues returns the values a variable takes when the All option is selected
+func AllValues(variable *simplejson.Json) []string {
+	if allValue := variable.Get("allValue").MustString(); allValue != "" {
+		return []string{allValue}
+	}
+
+	var values []string
+	for _, optionObj := range variable.Get("options").MustArray() {
+		option := simplejson.NewFromAny(optionObj)
+		if v := option.Get("value").MustString(); v != "" && v != AllValue {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// defaultFormatters format the variables without format in the queries of the data sources that don't use the glob
+// format for multiple values, the same way as their frontend.
+var defaultFormatters = map[string]func(value []string) string{
+	"prometheus": formatQuotedRegex,
+	"loki":       formatQuotedRegex,
+}
+
+// Interpolate replaces the variables in all the strings of the query model. Variables without a value are kept as is.
+// The variables without format are formatted with the default format of the data source type of the query.
+func Interpolate(v any, values map[string][]string, datasourceType string) any {
+	switch v := v.(type) {
+	case string:
+		return variableRegex.ReplaceAllStringFunc(v, func(match string) string {
+			groups := variableRegex.FindStringSubmatch(match)
+			name, format := groups[1], ""
+			if groups[2] != "" {
+				name, format = groups[2], groups[3]
+			} else if groups[4] != "" {
+				name, format = groups[4], groups[5]
+			}
+			value, ok := values[name]
+			if !ok {
+				return match
+			}
+			if formatter, ok := defaultFormatters[datasourceType]; ok && format == "" {
+				return formatter(value)
+			}
+			return FormatValue(value, format)
+		})
+	case map[string]any:
+		for k, item := range v {
+			v[k] = Interpolate(item, values, datasourceType)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = Interpolate(item, values, datasourceType)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// References returns the names of the variables used in all the strings of a value
+func References(v any) map[string]bool {
+	names := make(map[string]bool)
+	collectReferences(v, names)
+	return names
+}
+
+func collectReferences(v any, names map[string]bool) {
+	switch v := v.(type) {
+	case string:
+		for _, groups := range variableRegex.FindAllStringSubmatch(v, -1) {
+			for _, i := range []int{1, 2, 4} {
+				if groups[i] != "" {
+					names[groups[i]] = true
+				}
+			}
+		}
+	case map[string]any:
+		for _, item := range v {
+			collectReferences(item, names)
+		}
+	case []any:
+		for _, item := range v {
+			collectReferences(item, names)
+		}
+	}
+}
+
+// FormatValue formats the value of a variable with one of the formats supported by the frontend.
+// Multiple values use the glob format without format.
+func FormatValue(value []string, format string) string {
+	switch format {
+	case "csv":
+		return strings.Join(value, ",")
+	case "pipe":
+		return strings.Join(value, "|")
+	case "regex":
+		escaped := make([]string, 0, len(value))
+		for _, v := range value {
+			escaped = append(escaped, regexp.QuoteMeta(v))
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case "raw":
+		return strings.Join(value, ",")
+	}
+
+	if len(value) == 1 {
+		return value[0]
+	}
+	return "{" + strings.Join(value, ",") + "}"
+}
+
+// formatQuotedRegex matches multiple values with a regex in a quoted string of the query, like `=~"$var"`, so the
+// escaping backslashes are escaped too
+func formatQuotedRegex(value []string) string {
+	if len(value) == 1 {
+		return value[0]
+	}
+
+	escaped := make([]string, 0, len(value))
+	for _, v := range value {
+		escaped = append(escaped, strings.ReplaceAll(regexp.QuoteMeta(v), `\`, `\\`))
+	}
+	return "(" + strings.Join(escaped, "|") + ")"
+}
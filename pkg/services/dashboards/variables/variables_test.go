@@ -0,0 +1,55 @@
+package variables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestInterpolate(t *testing.T) {
+	values := map[string][]string{"host": {"a.1", "b"}, "dc": {"lga"}}
+
+	require.Equal(t, "{a.1,b} lga", Interpolate("$host $dc", values, "graphite"))
+	require.Equal(t, "(a\\.1|b) a.1,b a.1|b", Interpolate("${host:regex} ${host:csv} [[host:pipe]]", values, "graphite"))
+	require.Equal(t, "$__interval $unknown lgax", Interpolate("$__interval $unknown ${dc}x", values, "graphite"))
+	require.Equal(t, map[string]any{"a": []any{"lga", float64(1)}}, Interpolate(map[string]any{"a": []any{"$dc", float64(1)}}, values, "graphite"))
+}
+
+func TestInterpolate_datasourceDefaultFormat(t *testing.T) {
+	values := map[string][]string{"host": {"a.1", "b"}, "dc": {"lga"}}
+
+	require.Equal(t, `up{host=~"(a\\.1|b)", dc="lga"}`, Interpolate(`up{host=~"$host", dc="$dc"}`, values, "prometheus"))
+	require.Equal(t, `{host=~"(a\\.1|b)"}`, Interpolate(`{host=~"$host"}`, values, "loki"))
+	require.Equal(t, "a.1,b", Interpolate("${host:csv}", values, "prometheus"))
+	require.Equal(t, "{a.1,b}", Interpolate("$host", values, ""))
+}
+
+func TestReferences(t *testing.T) {
+	v := map[string]any{"expr": "up{host=~\"$host\"}", "legend": []any{"${dc:csv} [[env]]", float64(1)}}
+	require.Equal(t, map[string]bool{"host": true, "dc": true, "env": true}, References(v))
+	require.Empty(t, References("no variables"))
+}
+
+func TestCurrentValue(t *testing.T) {
+	single := simplejson.NewFromAny(map[string]any{"current": map[string]any{"value": "lga"}})
+	require.Equal(t, []string{"lga"}, CurrentValue(single))
+
+	multi := simplejson.NewFromAny(map[string]any{"current": map[string]any{"value": []any{"a", "b"}}})
+	require.Equal(t, []string{"a", "b"}, CurrentValue(multi))
+}
+
+func TestAllValues(t *testing.T) {
+	variable := simplejson.NewFromAny(map[string]any{
+		"options": []any{
+			map[string]any{"value": AllValue},
+			map[string]any{"value": "a"},
+			map[string]any{"value": "b"},
+		},
+	})
+	require.Equal(t, []string{"a", "b"}, AllValues(variable))
+
+	variable.Set("allValue", ".*")
+	require.Equal(t, []string{".*"}, AllValues(variable))
+}
@@ -5,3 +5,6 @@ import (
 )
 
 var ErrBaseNotFound = errutil.NotFound("dashboardsnapshots.not-found", errutil.WithPublicMessage("Snapshot not found"))
+
+// ErrInvalidTimeRange is returned when the time range of a captured snapshot can't be parsed
+var ErrInvalidTimeRange = errutil.BadRequest("dashboardsnapshots.invalid-time-range")
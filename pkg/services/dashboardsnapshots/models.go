@@ -1,9 +1,11 @@
 package dashboardsnapshots
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
@@ -79,6 +81,68 @@ type CreateDashboardSnapshotCommand struct {
 	DashboardEncrypted []byte `json:"-"`
 }
 
+// swagger:model
+type CreateDashboardSnapshotFromDashboardCommand struct {
+	// Snapshot name. Defaults to the dashboard title.
+	// required:false
+	Name string `json:"name"`
+	// When the snapshot should expire in seconds. Default is never to expire.
+	// required:false
+	// default:0
+	Expires int64 `json:"expires"`
+	// Save the snapshot on an external server rather than locally.
+	// required:false
+	// default: false
+	External bool `json:"external"`
+	// Start of the time range, in epoch milliseconds or relative using Grafana time units. Defaults to the dashboard time range.
+	// required:false
+	// example: now-1h
+	From string `json:"from"`
+	// End of the time range, in epoch milliseconds or relative using Grafana time units. Defaults to the dashboard time range.
+	// required:false
+	// example: now
+	To string `json:"to"`
+	// Values of the dashboard variables, by variable name. Variables that aren't set use their current value in the dashboard.
+	// required:false
+	Variables map[string]VariableValue `json:"variables"`
+}
+
+// VariableValue is the value of a dashboard variable. It can be set as a string or,
+// for multi-value variables, as an array of strings.
+type VariableValue []string
+
+func (v *VariableValue) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*v = VariableValue{single}
+		return nil
+	}
+
+	var multi []string
+	if err := json.Unmarshal(b, &multi); err != nil {
+		return err
+	}
+	*v = multi
+	return nil
+}
+
+// CaptureDashboardSnapshotCommand executes the queries of a dashboard to embed
+// their results in a snapshot.
+type CaptureDashboardSnapshotCommand struct {
+	Dashboard *simplejson.Json
+	User      identity.Requester
+	From      string
+	To        string
+	Variables map[string]VariableValue
+}
+
+type CaptureDashboardSnapshotResult struct {
+	// Dashboard is a copy of the dashboard with the query results stored in the snapshotData of the panels
+	Dashboard *simplejson.Json
+	// PanelErrors contains the error of every panel that failed to query, by panel ID
+	PanelErrors map[int64]string
+}
+
 type DeleteDashboardSnapshotCommand struct {
 	DeleteKey string `json:"-"`
 }
@@ -6,6 +6,7 @@ import (
 
 //go:generate mockery --name Service --structname MockService --inpackage --filename service_mock.go
 type Service interface {
+	CaptureDashboardSnapshot(context.Context, *CaptureDashboardSnapshotCommand) (*CaptureDashboardSnapshotResult, error)
 	CreateDashboardSnapshot(context.Context, *CreateDashboardSnapshotCommand) (*DashboardSnapshot, error)
 	DeleteDashboardSnapshot(context.Context, *DeleteDashboardSnapshotCommand) error
 	DeleteExpiredSnapshots(context.Context, *DeleteExpiredSnapshotsCommand) error
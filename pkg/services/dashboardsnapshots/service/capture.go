@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/dashboards/variables"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+const (
+	defaultSnapshotFrom = "now-6h"
+	defaultSnapshotTo   = "now"
+
+	// defaultMaxDataPoints is used for panels that don't define maxDataPoints, as the
+	// width of the panel in the browser is unknown
+	defaultMaxDataPoints = int64(1000)
+)
+
+var intervalCalculator = intervalv2.NewCalculator()
+
+// CaptureDashboardSnapshot executes the queries of every panel of the dashboard and returns a copy of
+// the dashboard with the results stored as snapshot data, the same way the frontend creates snapshots:
+// the panel queries are removed, and the variables and time range are fixed to the captured values.
+func (s *ServiceImpl) CaptureDashboardSnapshot(ctx context.Context, cmd *dashboardsnapshots.CaptureDashboardSnapshotCommand) (*dashboardsnapshots.CaptureDashboardSnapshotResult, error) {
+	raw, err := cmd.Dashboard.Encode()
+	if err != nil {
+		return nil, err
+	}
+	dash, err := simplejson.NewJson(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := cmd.From, cmd.To
+	if from == "" {
+		from = dash.GetPath("time", "from").MustString(defaultSnapshotFrom)
+	}
+	if to == "" {
+		to = dash.GetPath("time", "to").MustString(defaultSnapshotTo)
+	}
+	timeRange := legacydata.NewDataTimeRange(from, to)
+	fromTime, err := timeRange.ParseFrom()
+	if err != nil {
+		return nil, dashboardsnapshots.ErrInvalidTimeRange.Errorf("invalid time range from %q: %w", from, err)
+	}
+	toTime, err := timeRange.ParseTo()
+	if err != nil {
+		return nil, dashboardsnapshots.ErrInvalidTimeRange.Errorf("invalid time range to %q: %w", to, err)
+	}
+
+	values := resolveVariables(dash, cmd.Variables)
+
+	result := &dashboardsnapshots.CaptureDashboardSnapshotResult{
+		Dashboard:   dash,
+		PanelErrors: map[int64]string{},
+	}
+
+	for _, panel := range snapshotPanels(dash) {
+		panelID := panel.Get("id").MustInt64()
+
+		frames, err := s.queryPanel(ctx, cmd, panel, values, fromTime, toTime)
+		if err != nil {
+			result.PanelErrors[panelID] = err.Error()
+		}
+
+		panel.Set("snapshotData", frames)
+		panel.Set("targets", []any{})
+		panel.Set("links", []any{})
+		panel.Del("datasource")
+	}
+
+	dash.Set("time", map[string]any{
+		"from": fromTime.UTC().Format(time.RFC3339Nano),
+		"to":   toTime.UTC().Format(time.RFC3339Nano),
+	})
+	dash.Set("snapshot", map[string]any{
+		"timestamp": time.Now().UTC().Format(time.RFC3339Nano),
+	})
+
+	return result, nil
+}
+
+// queryPanel executes the queries of a panel and returns the resulting frames in the format of
+// the panel snapshotData. Errors of single queries are returned after all queries have run.
+func (s *ServiceImpl) queryPanel(ctx context.Context, cmd *dashboardsnapshots.CaptureDashboardSnapshotCommand, panel *simplejson.Json, values map[string][]string, from, to time.Time) ([]any, error) {
+	frames := []any{}
+
+	queries := panelQueries(panel, values)
+	if len(queries) == 0 {
+		return frames, nil
+	}
+
+	maxDataPoints := panel.Get("maxDataPoints").MustInt64(defaultMaxDataPoints)
+	minInterval, err := intervalv2.GetIntervalFrom(panel.Get("interval").MustString(), "", 0, time.Millisecond)
+	if err != nil {
+		return frames, err
+	}
+	interval := intervalCalculator.Calculate(backend.TimeRange{From: from, To: to}, minInterval, maxDataPoints)
+	for _, q := range queries {
+		q.Set("intervalMs", interval.Milliseconds())
+		q.Set("maxDataPoints", maxDataPoints)
+	}
+
+	res, err := s.queryService.QueryData(ctx, cmd.User, false, dtos.MetricRequest{
+		From:    strconv.FormatInt(from.UnixMilli(), 10),
+		To:      strconv.FormatInt(to.UnixMilli(), 10),
+		Queries: queries,
+	})
+	if err != nil {
+		return frames, err
+	}
+
+	var errs []string
+	for _, q := range queries {
+		refID := q.Get("refId").MustString()
+		dr, ok := res.Responses[refID]
+		if !ok {
+			continue
+		}
+		if dr.Error != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", refID, dr.Error))
+		}
+		for _, frame := range dr.Frames {
+			frames = append(frames, frameToSnapshotData(frame, refID))
+		}
+	}
+	if len(errs) > 0 {
+		return frames, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return frames, nil
+}
+
+// snapshotPanels returns the panels of the dashboard, including the panels of collapsed rows
+func snapshotPanels(dash *simplejson.Json) []*simplejson.Json {
+	var panels []*simplejson.Json
+	for _, panelObj := range dash.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(panelObj)
+		if panel.Get("type").MustString() == "row" {
+			for _, rowPanelObj := range panel.Get("panels").MustArray() {
+				panels = append(panels, simplejson.NewFromAny(rowPanelObj))
+			}
+			continue
+		}
+		panels = append(panels, panel)
+	}
+	return panels
+}
+
+// panelQueries returns the queries of the panel with the dashboard variables replaced
+func panelQueries(panel *simplejson.Json, values map[string][]string) []*simplejson.Json {
+	targets := panel.Get("targets").MustArray()
+
+	hasExpression := false
+	for _, targetObj := range targets {
+		if expr.NodeTypeFromDatasourceUID(datasourceUID(simplejson.NewFromAny(targetObj))) == expr.TypeCMDNode {
+			hasExpression = true
+		}
+	}
+
+	queries := make([]*simplejson.Json, 0, len(targets))
+	for _, targetObj := range targets {
+		target := simplejson.NewFromAny(targetObj)
+
+		// hidden queries are still needed when they are used by expressions, which remove them from the response
+		if !hasExpression && target.Get("hide").MustBool() {
+			continue
+		}
+
+		if _, ok := target.CheckGet("datasource"); !ok {
+			target.Set("datasource", panel.Get("datasource").Interface())
+		}
+
+		datasourceType := target.Get("datasource").Get("type").MustString()
+		queries = append(queries, simplejson.NewFromAny(variables.Interpolate(target.Interface(), values, datasourceType)))
+	}
+	return queries
+}
+
+func datasourceUID(query *simplejson.Json) string {
+	uid := query.Get("datasource").Get("uid").MustString()
+
+	// before 8.3 special types could be sent as datasource (expr)
+	if uid == "" {
+		uid = query.Get("datasource").MustString()
+	}
+
+	return uid
+}
+
+// resolveVariables returns the value of every variable of the dashboard, using the given values
+// before the current values stored in the dashboard. The variables of the dashboard are updated
+// to only contain the resolved value, as done by the frontend for snapshots.
+func resolveVariables(dash *simplejson.Json, values map[string]dashboardsnapshots.VariableValue) map[string][]string {
+	resolved := map[string][]string{}
+	for _, variableObj := range dash.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(variableObj)
+		name := variable.Get("name").MustString()
+		if name == "" {
+			continue
+		}
+
+		value, ok := values[name]
+		if !ok {
+			value = variables.CurrentValue(variable)
+		}
+		if len(value) == 1 && value[0] == variables.AllValue {
+			value = variables.AllValues(variable)
+		}
+		resolved[name] = value
+
+		multi := make([]any, 0, len(value))
+		for _, v := range value {
+			multi = append(multi, v)
+		}
+		current := map[string]any{
+			"text":  strings.Join(value, " + "),
+			"value": multi,
+		}
+		if len(value) == 1 {
+			current["text"] = value[0]
+			current["value"] = value[0]
+		}
+		variable.Set("current", current)
+		variable.Set("options", []any{current})
+		variable.Set("query", "")
+		variable.Set("refresh", 0)
+	}
+	return resolved
+}
+
+// frameToSnapshotData converts a frame to the DataFrameDTO format stored in the panel snapshotData
+func frameToSnapshotData(frame *data.Frame, refID string) map[string]any {
+	fields := make([]any, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		isTime := field.Type().Time()
+		values := make([]any, 0, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			value, ok := field.ConcreteAt(i)
+			if !ok {
+				values = append(values, nil)
+				continue
+			}
+			if t, ok := value.(time.Time); ok && isTime {
+				value = t.UnixMilli()
+			}
+			values = append(values, value)
+		}
+
+		dto := map[string]any{
+			"name":   field.Name,
+			"type":   snapshotFieldType(field.Type()),
+			"values": values,
+		}
+		if field.Config != nil {
+			dto["config"] = field.Config
+		} else {
+			dto["config"] = map[string]any{}
+		}
+		if len(field.Labels) > 0 {
+			dto["labels"] = field.Labels
+		}
+		fields = append(fields, dto)
+	}
+
+	dto := map[string]any{
+		"name":   frame.Name,
+		"refId":  refID,
+		"fields": fields,
+	}
+	if frame.Meta != nil {
+		dto["meta"] = frame.Meta
+	}
+	return dto
+}
+
+func snapshotFieldType(t data.FieldType) string {
+	switch {
+	case t.Time():
+		return "time"
+	case t.Numeric():
+		return "number"
+	case t == data.FieldTypeString || t == data.FieldTypeNullableString:
+		return "string"
+	case t == data.FieldTypeBool || t == data.FieldTypeNullableBool:
+		return "boolean"
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+)
+
+type fakeQueryService struct {
+	requests []dtos.MetricRequest
+	response func(req dtos.MetricRequest) (*backend.QueryDataResponse, error)
+}
+
+func (f *fakeQueryService) Run(ctx context.Context) error {
+	return nil
+}
+
+func (f *fakeQueryService) QueryData(ctx context.Context, user identity.Requester, skipDSCache bool, req dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+	f.requests = append(f.requests, req)
+	return f.response(req)
+}
+
+func TestCaptureDashboardSnapshot(t *testing.T) {
+	dashboard := func(t *testing.T) *simplejson.Json {
+		t.Helper()
+		dash, err := simplejson.NewJson([]byte(`{
+			"uid": "dash",
+			"time": {"from": "now-1h", "to": "now"},
+			"templating": {"list": [
+				{"name": "host", "query": "label_values(host)", "current": {"text": "a", "value": "a"}, "options": [{"value": "a"}, {"value": "b"}]},
+				{"name": "env", "current": {"value": ["prod"]}}
+			]},
+			"panels": [
+				{
+					"id": 1,
+					"datasource": {"uid": "prom", "type": "prometheus"},
+					"targets": [
+						{"refId": "A", "expr": "up{host=~\"$host\", env=\"${env}\"}"},
+						{"refId": "B", "expr": "hidden", "hide": true}
+					]
+				},
+				{"id": 2, "type": "row", "collapsed": true, "panels": [
+					{"id": 3, "targets": [{"refId": "A", "datasource": {"uid": "loki"}, "expr": "[[host]]"}]}
+				]},
+				{"id": 4, "type": "text"}
+			]
+		}`))
+		require.NoError(t, err)
+		return dash
+	}
+
+	t.Run("executes the queries of every panel and stores the frames", func(t *testing.T) {
+		qs := &fakeQueryService{response: func(req dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+			frame := data.NewFrame("up",
+				data.NewField("time", nil, []time.Time{time.UnixMilli(1000)}),
+				data.NewField("value", data.Labels{"host": "a"}, []*float64{nil}),
+			)
+			return &backend.QueryDataResponse{Responses: backend.Responses{"A": {Frames: data.Frames{frame}}}}, nil
+		}}
+		s := &ServiceImpl{queryService: qs}
+
+		dash := dashboard(t)
+		res, err := s.CaptureDashboardSnapshot(context.Background(), &dashboardsnapshots.CaptureDashboardSnapshotCommand{
+			Dashboard: dash,
+			From:      "1000",
+			To:        "61000",
+			Variables: map[string]dashboardsnapshots.VariableValue{"host": {"a", "b"}},
+		})
+		require.NoError(t, err)
+		require.Empty(t, res.PanelErrors)
+
+		require.Len(t, qs.requests, 2)
+		require.Equal(t, "1000", qs.requests[0].From)
+		require.Equal(t, "61000", qs.requests[0].To)
+		require.Len(t, qs.requests[0].Queries, 1)
+		require.Equal(t, `up{host=~"(a|b)", env="prod"}`, qs.requests[0].Queries[0].Get("expr").MustString())
+		require.Equal(t, "prom", qs.requests[0].Queries[0].GetPath("datasource", "uid").MustString())
+		require.Equal(t, int64(defaultMaxDataPoints), qs.requests[0].Queries[0].Get("maxDataPoints").MustInt64())
+		require.Equal(t, "{a,b}", qs.requests[1].Queries[0].Get("expr").MustString())
+
+		panel := res.Dashboard.Get("panels").GetIndex(0)
+		require.Empty(t, panel.Get("targets").MustArray())
+		snapshotData := panel.Get("snapshotData").GetIndex(0)
+		require.Equal(t, "A", snapshotData.Get("refId").MustString())
+		require.Equal(t, "time", snapshotData.Get("fields").GetIndex(0).Get("type").MustString())
+		require.Equal(t, []any{int64(1000)}, snapshotData.Get("fields").GetIndex(0).Get("values").MustArray())
+		require.Equal(t, "number", snapshotData.Get("fields").GetIndex(1).Get("type").MustString())
+		require.Equal(t, []any{nil}, snapshotData.Get("fields").GetIndex(1).Get("values").MustArray())
+
+		require.Equal(t, "1970-01-01T00:00:01Z", res.Dashboard.GetPath("time", "from").MustString())
+		require.Equal(t, []string{"a", "b"}, res.Dashboard.GetPath("templating", "list").GetIndex(0).GetPath("current", "value").MustStringArray())
+		require.Empty(t, res.Dashboard.GetPath("templating", "list").GetIndex(0).Get("query").MustString())
+
+		// the original dashboard is not modified
+		require.Len(t, dash.Get("panels").GetIndex(0).Get("targets").MustArray(), 2)
+	})
+
+	t.Run("returns the errors of the panels that failed to query", func(t *testing.T) {
+		qs := &fakeQueryService{response: func(req dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+			if req.Queries[0].GetPath("datasource", "uid").MustString() == "loki" {
+				return nil, errors.New("datasource not found")
+			}
+			return &backend.QueryDataResponse{Responses: backend.Responses{"A": {Error: errors.New("bad query")}}}, nil
+		}}
+		s := &ServiceImpl{queryService: qs}
+
+		res, err := s.CaptureDashboardSnapshot(context.Background(), &dashboardsnapshots.CaptureDashboardSnapshotCommand{Dashboard: dashboard(t)})
+		require.NoError(t, err)
+		require.Equal(t, map[int64]string{1: "A: bad query", 3: "datasource not found"}, res.PanelErrors)
+		require.Equal(t, `up{host=~"a", env="prod"}`, qs.requests[0].Queries[0].Get("expr").MustString())
+	})
+
+	t.Run("invalid time range is an error", func(t *testing.T) {
+		s := &ServiceImpl{queryService: &fakeQueryService{}}
+		_, err := s.CaptureDashboardSnapshot(context.Background(), &dashboardsnapshots.CaptureDashboardSnapshotCommand{Dashboard: dashboard(t), From: "yesterday"})
+		require.ErrorIs(t, err, dashboardsnapshots.ErrInvalidTimeRange)
+	})
+}
@@ -5,21 +5,24 @@ import (
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/secrets"
 )
 
 type ServiceImpl struct {
 	store          dashboardsnapshots.Store
 	secretsService secrets.Service
+	queryService   query.Service
 }
 
 // ServiceImpl implements the dashboardsnapshots Service interface
 var _ dashboardsnapshots.Service = (*ServiceImpl)(nil)
 
-func ProvideService(store dashboardsnapshots.Store, secretsService secrets.Service) *ServiceImpl {
+func ProvideService(store dashboardsnapshots.Store, secretsService secrets.Service, queryService query.Service) *ServiceImpl {
 	s := &ServiceImpl{
 		store:          store,
 		secretsService: secretsService,
+		queryService:   queryService,
 	}
 
 	return s
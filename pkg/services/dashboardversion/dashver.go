@@ -8,4 +8,7 @@ type Service interface {
 	Get(context.Context, *GetDashboardVersionQuery) (*DashboardVersionDTO, error)
 	DeleteExpired(context.Context, *DeleteExpiredVersionsCommand) error
 	List(context.Context, *ListDashboardVersionsQuery) ([]*DashboardVersionDTO, error)
+	ListRetentionPolicies(ctx context.Context, orgID int64) ([]*RetentionPolicy, error)
+	SetRetentionPolicy(context.Context, *SetRetentionPolicyCommand) (*RetentionPolicy, error)
+	DeleteRetentionPolicy(context.Context, *DeleteRetentionPolicyCommand) error
 }
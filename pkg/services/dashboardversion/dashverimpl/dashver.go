@@ -3,6 +3,7 @@ package dashverimpl
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -68,6 +69,14 @@ func (s *Service) DeleteExpired(ctx context.Context, cmd *dashver.DeleteExpiredV
 		versionsToKeep = 1
 	}
 
+	policies, err := s.store.ListRetentionPolicies(ctx, 0)
+	if err != nil {
+		return err
+	}
+	if len(policies) > 0 {
+		return s.deleteExpiredByPolicy(ctx, cmd, policies, versionsToKeep)
+	}
+
 	for batch := 0; batch < maxVersionDeletionBatches; batch++ {
 		versionIdsToDelete, batchErr := s.store.GetBatch(ctx, cmd, maxVersionsToDeletePerBatch, versionsToKeep)
 		if batchErr != nil {
@@ -92,6 +101,71 @@ func (s *Service) DeleteExpired(ctx context.Context, cmd *dashver.DeleteExpiredV
 	return nil
 }
 
+// deleteExpiredByPolicy deletes the versions of each dashboard that are not kept by the retention policy of its
+// folder, or else by the retention policy of its organization, or else by the configured number of versions.
+func (s *Service) deleteExpiredByPolicy(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, policies []*dashver.RetentionPolicy, versionsToKeep int) error {
+	policies = append(policies, &dashver.RetentionPolicy{VersionsToKeep: versionsToKeep})
+	now := time.Now()
+	remaining := maxVersionsToDeletePerBatch * maxVersionDeletionBatches
+
+	for _, policy := range policies {
+		versionIdsToDelete, err := s.store.ListExpiredVersions(ctx, policy, now, remaining)
+		if err != nil {
+			return err
+		}
+
+		for len(versionIdsToDelete) > 0 {
+			batch := versionIdsToDelete
+			if len(batch) > maxVersionsToDeletePerBatch {
+				batch = batch[:maxVersionsToDeletePerBatch]
+			}
+			deleted, err := s.store.DeleteBatch(ctx, cmd, batch)
+			if err != nil {
+				return err
+			}
+			cmd.DeletedRows += deleted
+			remaining -= len(batch)
+			versionIdsToDelete = versionIdsToDelete[len(batch):]
+		}
+
+		if remaining <= 0 {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *Service) ListRetentionPolicies(ctx context.Context, orgID int64) ([]*dashver.RetentionPolicy, error) {
+	return s.store.ListRetentionPolicies(ctx, orgID)
+}
+
+// SetRetentionPolicy creates or replaces the retention policy of a folder, or the default policy of the
+// organization when the folder is empty.
+func (s *Service) SetRetentionPolicy(ctx context.Context, cmd *dashver.SetRetentionPolicyCommand) (*dashver.RetentionPolicy, error) {
+	if cmd.VersionsToKeep < 1 {
+		return nil, dashver.ErrInvalidVersionsToKeep
+	}
+	if cmd.DailyVersionsDays < 0 {
+		return nil, dashver.ErrInvalidDailyVersionsToKeep
+	}
+
+	policy := &dashver.RetentionPolicy{
+		OrgID:             cmd.OrgID,
+		FolderUID:         cmd.FolderUID,
+		VersionsToKeep:    cmd.VersionsToKeep,
+		DailyVersionsDays: cmd.DailyVersionsDays,
+		Updated:           time.Now(),
+	}
+	if err := s.store.SaveRetentionPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *Service) DeleteRetentionPolicy(ctx context.Context, cmd *dashver.DeleteRetentionPolicyCommand) error {
+	return s.store.DeleteRetentionPolicy(ctx, cmd.OrgID, cmd.FolderUID)
+}
+
 // List all dashboard versions for the given dashboard ID.
 func (s *Service) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersionDTO, error) {
 	// Get the DashboardUID if not populated
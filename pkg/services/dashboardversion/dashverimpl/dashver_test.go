@@ -3,7 +3,9 @@ package dashverimpl
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -61,6 +63,61 @@ func TestDeleteExpiredVersions(t *testing.T) {
 	})
 }
 
+func TestDeleteExpiredVersionsByPolicy(t *testing.T) {
+	setting.DashboardVersionsToKeep = 3
+
+	dashboardVersionStore := newDashboardVersionStoreFake()
+	dashboardVersionStore.ExpectedPolicies = []*dashver.RetentionPolicy{
+		{OrgID: 1, FolderUID: "", VersionsToKeep: 8},
+		{OrgID: 1, FolderUID: "folder", VersionsToKeep: 2},
+	}
+	folderVersions := make([]any, 0, maxVersionsToDeletePerBatch+1)
+	for id := int64(1); id <= maxVersionsToDeletePerBatch+1; id++ {
+		folderVersions = append(folderVersions, id)
+	}
+	dashboardVersionStore.ExpectedExpiredVersions = map[string][]any{
+		"1/folder": folderVersions,
+		"1/":       {int64(201)},
+		"0/":       {int64(301), int64(302)},
+	}
+	dashboardVersionService := Service{store: dashboardVersionStore, dashSvc: dashboards.NewFakeDashboardService(t)}
+
+	err := dashboardVersionService.DeleteExpired(context.Background(), &dashver.DeleteExpiredVersionsCommand{})
+	require.NoError(t, err)
+	// the policies of the organizations, then the configured number of versions
+	require.Equal(t, []*dashver.RetentionPolicy{
+		{OrgID: 1, FolderUID: "", VersionsToKeep: 8},
+		{OrgID: 1, FolderUID: "folder", VersionsToKeep: 2},
+		{VersionsToKeep: 3},
+	}, dashboardVersionStore.ExpiredVersionsPolicies)
+	// the versions are deleted in batches
+	require.Len(t, dashboardVersionStore.DeletedBatches, 4)
+	require.Len(t, dashboardVersionStore.DeletedBatches[1], maxVersionsToDeletePerBatch)
+	require.Equal(t, []any{int64(301), int64(302)}, dashboardVersionStore.DeletedBatches[3])
+}
+
+func TestSetRetentionPolicy(t *testing.T) {
+	dashboardVersionService := Service{store: newDashboardVersionStoreFake()}
+
+	t.Run("Set a retention policy", func(t *testing.T) {
+		policy, err := dashboardVersionService.SetRetentionPolicy(context.Background(), &dashver.SetRetentionPolicyCommand{
+			OrgID: 1, FolderUID: "folder", VersionsToKeep: 5, DailyVersionsDays: 30,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "folder", policy.FolderUID)
+		require.Equal(t, 5, policy.VersionsToKeep)
+		require.Equal(t, 30, policy.DailyVersionsDays)
+	})
+
+	t.Run("Set an invalid retention policy", func(t *testing.T) {
+		_, err := dashboardVersionService.SetRetentionPolicy(context.Background(), &dashver.SetRetentionPolicyCommand{OrgID: 1})
+		require.ErrorIs(t, err, dashver.ErrInvalidVersionsToKeep)
+
+		_, err = dashboardVersionService.SetRetentionPolicy(context.Background(), &dashver.SetRetentionPolicyCommand{OrgID: 1, VersionsToKeep: 1, DailyVersionsDays: -1})
+		require.ErrorIs(t, err, dashver.ErrInvalidDailyVersionsToKeep)
+	})
+}
+
 func TestListDashboardVersions(t *testing.T) {
 	t.Run("List all versions for a given Dashboard ID", func(t *testing.T) {
 		dashboardVersionStore := newDashboardVersionStoreFake()
@@ -149,6 +206,10 @@ type FakeDashboardVersionStore struct {
 	ExptectedDeletedVersions int64
 	ExpectedVersions         []any
 	ExpectedListVersions     []*dashver.DashboardVersion
+	ExpectedPolicies         []*dashver.RetentionPolicy
+	ExpectedExpiredVersions  map[string][]any
+	ExpiredVersionsPolicies  []*dashver.RetentionPolicy
+	DeletedBatches           [][]any
 	ExpectedError            error
 }
 
@@ -165,9 +226,27 @@ func (f *FakeDashboardVersionStore) GetBatch(ctx context.Context, cmd *dashver.D
 }
 
 func (f *FakeDashboardVersionStore) DeleteBatch(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, versionIdsToDelete []any) (int64, error) {
+	f.DeletedBatches = append(f.DeletedBatches, versionIdsToDelete)
 	return f.ExptectedDeletedVersions, f.ExpectedError
 }
 
 func (f *FakeDashboardVersionStore) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersion, error) {
 	return f.ExpectedListVersions, f.ExpectedError
 }
+
+func (f *FakeDashboardVersionStore) ListRetentionPolicies(ctx context.Context, orgID int64) ([]*dashver.RetentionPolicy, error) {
+	return f.ExpectedPolicies, f.ExpectedError
+}
+
+func (f *FakeDashboardVersionStore) SaveRetentionPolicy(ctx context.Context, policy *dashver.RetentionPolicy) error {
+	return f.ExpectedError
+}
+
+func (f *FakeDashboardVersionStore) DeleteRetentionPolicy(ctx context.Context, orgID int64, folderUID string) error {
+	return f.ExpectedError
+}
+
+func (f *FakeDashboardVersionStore) ListExpiredVersions(ctx context.Context, policy *dashver.RetentionPolicy, now time.Time, limit int) ([]any, error) {
+	f.ExpiredVersionsPolicies = append(f.ExpiredVersionsPolicies, policy)
+	return f.ExpectedExpiredVersions[fmt.Sprintf("%d/%s", policy.OrgID, policy.FolderUID)], f.ExpectedError
+}
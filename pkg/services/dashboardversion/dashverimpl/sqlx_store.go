@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"strings"
+	"time"
 
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/sqlstore/session"
@@ -84,3 +85,64 @@ func (ss *sqlxStore) List(ctx context.Context, query *dashver.ListDashboardVersi
 	}
 	return dashboardVersion, nil
 }
+
+func (ss *sqlxStore) ListRetentionPolicies(ctx context.Context, orgID int64) ([]*dashver.RetentionPolicy, error) {
+	policies := make([]*dashver.RetentionPolicy, 0)
+	qr := `SELECT * FROM dashboard_version_retention`
+	args := []any{}
+	if orgID != 0 {
+		qr += ` WHERE org_id = ?`
+		args = append(args, orgID)
+	}
+	qr += ` ORDER BY org_id, folder_uid`
+	err := ss.sess.Select(ctx, &policies, qr, args...)
+	return policies, err
+}
+
+func (ss *sqlxStore) SaveRetentionPolicy(ctx context.Context, policy *dashver.RetentionPolicy) error {
+	return ss.sess.WithTransaction(ctx, func(tx *session.SessionTx) error {
+		var id int64
+		err := tx.Get(ctx, &id, `SELECT id FROM dashboard_version_retention WHERE org_id = ? AND folder_uid = ?`, policy.OrgID, policy.FolderUID)
+		if errors.Is(err, sql.ErrNoRows) {
+			policy.ID, err = tx.ExecWithReturningId(ctx,
+				`INSERT INTO dashboard_version_retention (org_id, folder_uid, versions_to_keep, daily_versions_days, updated) VALUES (?, ?, ?, ?, ?)`,
+				policy.OrgID, policy.FolderUID, policy.VersionsToKeep, policy.DailyVersionsDays, policy.Updated)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		policy.ID = id
+		_, err = tx.Exec(ctx, `UPDATE dashboard_version_retention SET versions_to_keep = ?, daily_versions_days = ?, updated = ? WHERE id = ?`,
+			policy.VersionsToKeep, policy.DailyVersionsDays, policy.Updated, policy.ID)
+		return err
+	})
+}
+
+func (ss *sqlxStore) DeleteRetentionPolicy(ctx context.Context, orgID int64, folderUID string) error {
+	res, err := ss.sess.Exec(ctx, `DELETE FROM dashboard_version_retention WHERE org_id = ? AND folder_uid = ?`, orgID, folderUID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return dashver.ErrRetentionPolicyNotFound
+	}
+	return nil
+}
+
+func (ss *sqlxStore) ListExpiredVersions(ctx context.Context, policy *dashver.RetentionPolicy, now time.Time, limit int) ([]any, error) {
+	var versionIds []int64
+	sql, args := expiredVersionsSQL(policy, now, limit)
+	if err := ss.sess.Select(ctx, &versionIds, sql, args...); err != nil {
+		return nil, err
+	}
+	ids := make([]any, 0, len(versionIds))
+	for _, id := range versionIds {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -2,6 +2,8 @@ package dashverimpl
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 )
@@ -11,4 +13,61 @@ type store interface {
 	GetBatch(context.Context, *dashver.DeleteExpiredVersionsCommand, int, int) ([]any, error)
 	DeleteBatch(context.Context, *dashver.DeleteExpiredVersionsCommand, []any) (int64, error)
 	List(context.Context, *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersion, error)
+	// ListRetentionPolicies returns the retention policies of an organization, or of all organizations when the
+	// organization is 0.
+	ListRetentionPolicies(ctx context.Context, orgID int64) ([]*dashver.RetentionPolicy, error)
+	SaveRetentionPolicy(context.Context, *dashver.RetentionPolicy) error
+	DeleteRetentionPolicy(ctx context.Context, orgID int64, folderUID string) error
+	// ListExpiredVersions returns the ids of at most limit versions that are not kept by the retention policy.
+	ListExpiredVersions(ctx context.Context, policy *dashver.RetentionPolicy, now time.Time, limit int) ([]any, error)
+}
+
+// expiredVersionsSQL returns the query of the ids of the versions not kept by a retention policy. The policy keeps
+// the most recent versions of each dashboard, and the last version of each day for its number of days.
+//
+// The policy of a folder applies to the dashboards of the folder, the policy of an organization to the dashboards
+// whose folder has no policy, and the policy without organization to the dashboards of the other organizations.
+func expiredVersionsSQL(policy *dashver.RetentionPolicy, now time.Time, limit int) (string, []any) {
+	versionsToKeep := policy.VersionsToKeep
+	if versionsToKeep < 1 {
+		versionsToKeep = 1
+	}
+
+	// the versions of a dashboard are numbered consecutively and the most recent ones are never deleted, so the
+	// versions to keep are the ones after the latest version minus the number of versions to keep. The latest
+	// version is computed once per dashboard, instead of counting the newer versions of every version.
+	sql := strings.Builder{}
+	args := []any{true}
+	sql.WriteString(`SELECT dv.id FROM dashboard_version dv
+	INNER JOIN (SELECT dashboard_id, MAX(version) AS max_version FROM dashboard_version GROUP BY dashboard_id) latest
+		ON latest.dashboard_id = dv.dashboard_id
+	INNER JOIN dashboard ON dashboard.id = dv.dashboard_id
+	LEFT JOIN dashboard folder ON folder.id = dashboard.folder_id AND folder.is_folder = ?`)
+
+	switch {
+	case policy.OrgID == 0:
+		sql.WriteString(` WHERE NOT EXISTS (SELECT 1 FROM dashboard_version_retention r
+			WHERE r.org_id = dashboard.org_id AND (r.folder_uid = '' OR r.folder_uid = folder.uid))`)
+	case policy.FolderUID == "":
+		sql.WriteString(` WHERE dashboard.org_id = ? AND NOT EXISTS (SELECT 1 FROM dashboard_version_retention r
+			WHERE r.org_id = dashboard.org_id AND r.folder_uid <> '' AND r.folder_uid = folder.uid)`)
+		args = append(args, policy.OrgID)
+	default:
+		sql.WriteString(` WHERE dashboard.org_id = ? AND folder.uid = ?`)
+		args = append(args, policy.OrgID, policy.FolderUID)
+	}
+
+	sql.WriteString(` AND dv.version <= latest.max_version - ?`)
+	args = append(args, versionsToKeep)
+
+	if policy.DailyVersionsDays > 0 {
+		// the version is older than the daily versions, or is not the last version of its day
+		sql.WriteString(` AND (dv.created <= ? OR EXISTS (SELECT 1 FROM dashboard_version later
+			WHERE later.dashboard_id = dv.dashboard_id AND later.version > dv.version AND DATE(later.created) = DATE(dv.created)))`)
+		args = append(args, now.AddDate(0, 0, -policy.DailyVersionsDays).UTC().Format("2006-01-02 15:04:05"))
+	}
+
+	sql.WriteString(` ORDER BY dv.id LIMIT ?`)
+	args = append(args, limit)
+	return sql.String(), args
 }
@@ -2,6 +2,8 @@ package dashverimpl
 
 import (
 	"context"
+	"sort"
+	"strconv"
 	"testing"
 	"time"
 
@@ -110,6 +112,117 @@ func testIntegrationGetDashboardVersion(t *testing.T, fn getStore) {
 		require.Nil(t, err)
 		assert.Equal(t, 2, len(res))
 	})
+
+	t.Run("List the versions expired by retention policies", func(t *testing.T) {
+		ctx := context.Background()
+		now := time.Date(2023, 6, 10, 18, 0, 0, 0, time.UTC)
+		created := []time.Time{
+			time.Date(2023, 5, 30, 12, 0, 0, 0, time.UTC),
+			time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC),
+			time.Date(2023, 6, 8, 20, 0, 0, 0, time.UTC),
+			time.Date(2023, 6, 9, 8, 0, 0, 0, time.UTC),
+			time.Date(2023, 6, 9, 20, 0, 0, 0, time.UTC),
+			time.Date(2023, 6, 10, 9, 0, 0, 0, time.UTC),
+			time.Date(2023, 6, 10, 17, 0, 0, 0, time.UTC),
+		}
+
+		// inserts versions 1 to 7 of the dashboard and returns the version of each id
+		insertVersions := func(dash *dashboards.Dashboard) map[int64]int {
+			versions := map[int64]int{}
+			err := ss.WithDbSession(ctx, func(sess *db.Session) error {
+				if _, err := sess.Exec("DELETE FROM dashboard_version WHERE dashboard_id = ?", dash.ID); err != nil {
+					return err
+				}
+				for i, c := range created {
+					v := &dashver.DashboardVersion{DashboardID: dash.ID, Version: i + 1, Created: c, Data: dash.Data}
+					if _, err := sess.Insert(v); err != nil {
+						return err
+					}
+					versions[v.ID] = v.Version
+				}
+				return nil
+			})
+			require.NoError(t, err)
+			return versions
+		}
+
+		// returns the expired versions of the dashboard
+		listExpired := func(policy *dashver.RetentionPolicy, versions map[int64]int) []int {
+			ids, err := dashVerStore.ListExpiredVersions(ctx, policy, now, 1000)
+			require.NoError(t, err)
+			expired := []int{}
+			for _, id := range ids {
+				var versionID int64
+				switch v := id.(type) {
+				case int64:
+					versionID = v
+				case []byte:
+					versionID, err = strconv.ParseInt(string(v), 10, 64)
+					require.NoError(t, err)
+				}
+				if version, ok := versions[versionID]; ok {
+					expired = append(expired, version)
+				}
+			}
+			sort.Sort(sort.Reverse(sort.IntSlice(expired)))
+			return expired
+		}
+
+		folder := insertTestDashboard(t, ss, "test folder 61", 3, 0, true)
+		inFolder := insertVersions(insertTestDashboard(t, ss, "test dash 61", 3, folder.ID, false))
+		inGeneral := insertVersions(insertTestDashboard(t, ss, "test dash 62", 3, 0, false))
+
+		// the most recent versions are kept
+		assert.Equal(t, []int{5, 4, 3, 2, 1}, listExpired(&dashver.RetentionPolicy{VersionsToKeep: 2}, inFolder))
+		// the last version of each day is kept for the number of days
+		assert.Equal(t, []int{6, 4, 2, 1}, listExpired(&dashver.RetentionPolicy{VersionsToKeep: 1, DailyVersionsDays: 3}, inFolder))
+		// at least one version is kept
+		assert.Equal(t, []int{6, 5, 4, 3, 2, 1}, listExpired(&dashver.RetentionPolicy{}, inFolder))
+
+		folderPolicy := &dashver.RetentionPolicy{OrgID: 3, FolderUID: folder.UID, VersionsToKeep: 2, Updated: now}
+		require.NoError(t, dashVerStore.SaveRetentionPolicy(ctx, folderPolicy))
+		assert.Equal(t, []int{5, 4, 3, 2, 1}, listExpired(folderPolicy, inFolder))
+		assert.Empty(t, listExpired(folderPolicy, inGeneral))
+
+		// the policy of the organization does not apply to the folder with a policy
+		orgPolicy := &dashver.RetentionPolicy{OrgID: 3, VersionsToKeep: 5, Updated: now}
+		assert.Empty(t, listExpired(orgPolicy, inFolder))
+		assert.Equal(t, []int{2, 1}, listExpired(orgPolicy, inGeneral))
+
+		// the configured number of versions applies to the organizations without policy
+		assert.Equal(t, []int{1}, listExpired(&dashver.RetentionPolicy{VersionsToKeep: 6}, inGeneral))
+		require.NoError(t, dashVerStore.SaveRetentionPolicy(ctx, orgPolicy))
+		assert.Empty(t, listExpired(&dashver.RetentionPolicy{VersionsToKeep: 6}, inGeneral))
+
+		require.NoError(t, dashVerStore.DeleteRetentionPolicy(ctx, 3, folder.UID))
+		require.NoError(t, dashVerStore.DeleteRetentionPolicy(ctx, 3, ""))
+	})
+
+	t.Run("Save, list and delete retention policies", func(t *testing.T) {
+		ctx := context.Background()
+		err := dashVerStore.SaveRetentionPolicy(ctx, &dashver.RetentionPolicy{OrgID: 1, FolderUID: "folder", VersionsToKeep: 5, Updated: time.Now()})
+		require.NoError(t, err)
+		err = dashVerStore.SaveRetentionPolicy(ctx, &dashver.RetentionPolicy{OrgID: 1, FolderUID: "folder", VersionsToKeep: 3, DailyVersionsDays: 7, Updated: time.Now()})
+		require.NoError(t, err)
+		err = dashVerStore.SaveRetentionPolicy(ctx, &dashver.RetentionPolicy{OrgID: 2, VersionsToKeep: 10, Updated: time.Now()})
+		require.NoError(t, err)
+
+		policies, err := dashVerStore.ListRetentionPolicies(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, policies, 1)
+		assert.Equal(t, "folder", policies[0].FolderUID)
+		assert.Equal(t, 3, policies[0].VersionsToKeep)
+		assert.Equal(t, 7, policies[0].DailyVersionsDays)
+
+		policies, err = dashVerStore.ListRetentionPolicies(ctx, 0)
+		require.NoError(t, err)
+		require.Len(t, policies, 2)
+
+		err = dashVerStore.DeleteRetentionPolicy(ctx, 1, "folder")
+		require.NoError(t, err)
+		err = dashVerStore.DeleteRetentionPolicy(ctx, 1, "folder")
+		require.ErrorIs(t, err, dashver.ErrRetentionPolicyNotFound)
+	})
 }
 
 func getDashboard(t *testing.T, sqlStore db.DB, dashboard *dashboards.Dashboard) error {
@@ -3,6 +3,7 @@ package dashverimpl
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
@@ -103,3 +104,53 @@ func (ss *sqlStore) List(ctx context.Context, query *dashver.ListDashboardVersio
 	}
 	return dashboardVersion, nil
 }
+
+func (ss *sqlStore) ListRetentionPolicies(ctx context.Context, orgID int64) ([]*dashver.RetentionPolicy, error) {
+	policies := make([]*dashver.RetentionPolicy, 0)
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if orgID != 0 {
+			sess.Where("org_id = ?", orgID)
+		}
+		return sess.Asc("org_id", "folder_uid").Find(&policies)
+	})
+	return policies, err
+}
+
+func (ss *sqlStore) SaveRetentionPolicy(ctx context.Context, policy *dashver.RetentionPolicy) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := dashver.RetentionPolicy{}
+		has, err := sess.Where("org_id = ? AND folder_uid = ?", policy.OrgID, policy.FolderUID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !has {
+			_, err = sess.Insert(policy)
+			return err
+		}
+		policy.ID = existing.ID
+		_, err = sess.ID(policy.ID).AllCols().Update(policy)
+		return err
+	})
+}
+
+func (ss *sqlStore) DeleteRetentionPolicy(ctx context.Context, orgID int64, folderUID string) error {
+	return ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND folder_uid = ?", orgID, folderUID).Delete(&dashver.RetentionPolicy{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return dashver.ErrRetentionPolicyNotFound
+		}
+		return nil
+	})
+}
+
+func (ss *sqlStore) ListExpiredVersions(ctx context.Context, policy *dashver.RetentionPolicy, now time.Time, limit int) ([]any, error) {
+	versionIds := make([]any, 0)
+	sql, args := expiredVersionsSQL(policy, now, limit)
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(sql, args...).Find(&versionIds)
+	})
+	return versionIds, err
+}
@@ -8,8 +8,11 @@ import (
 )
 
 var (
-	ErrDashboardVersionNotFound = errors.New("dashboard version not found")
-	ErrNoVersionsForDashboardID = errors.New("no dashboard versions found for the given DashboardId")
+	ErrDashboardVersionNotFound   = errors.New("dashboard version not found")
+	ErrNoVersionsForDashboardID   = errors.New("no dashboard versions found for the given DashboardId")
+	ErrRetentionPolicyNotFound    = errors.New("dashboard version retention policy not found")
+	ErrInvalidVersionsToKeep      = errors.New("versions to keep must be at least 1")
+	ErrInvalidDailyVersionsToKeep = errors.New("days of daily versions to keep cannot be negative")
 )
 
 // DashboardVersion represents a dashboard version in the database. Ideally this
@@ -94,3 +97,33 @@ type DashboardVersionMeta struct {
 	Data          *simplejson.Json `json:"data"`
 	CreatedBy     string           `json:"createdBy"`
 }
+
+// RetentionPolicy configures which versions are kept for the dashboards of a folder, or for the dashboards of an
+// organization whose folder has no policy when the FolderUID is empty. The dashboards without policy keep the
+// number of versions of the configuration.
+type RetentionPolicy struct {
+	ID        int64  `json:"-" xorm:"pk autoincr 'id'" db:"id"`
+	OrgID     int64  `json:"-" xorm:"org_id" db:"org_id"`
+	FolderUID string `json:"folderUid" xorm:"folder_uid" db:"folder_uid"`
+	// VersionsToKeep is the number of most recent versions kept per dashboard.
+	VersionsToKeep int `json:"versionsToKeep" xorm:"versions_to_keep" db:"versions_to_keep"`
+	// DailyVersionsDays is the number of days for which the last version of each day is also kept.
+	DailyVersionsDays int       `json:"dailyVersionsDays" xorm:"daily_versions_days" db:"daily_versions_days"`
+	Updated           time.Time `json:"updated" xorm:"updated" db:"updated"`
+}
+
+func (RetentionPolicy) TableName() string {
+	return "dashboard_version_retention"
+}
+
+type SetRetentionPolicyCommand struct {
+	OrgID             int64  `json:"-"`
+	FolderUID         string `json:"folderUid"`
+	VersionsToKeep    int    `json:"versionsToKeep"`
+	DailyVersionsDays int    `json:"dailyVersionsDays"`
+}
+
+type DeleteRetentionPolicyCommand struct {
+	OrgID     int64
+	FolderUID string
+}
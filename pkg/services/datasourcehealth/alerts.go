@@ -0,0 +1,59 @@
+package datasourcehealth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+// AlertName is the name of the alert sent when a data source stays unhealthy.
+const AlertName = "DatasourceUnhealthy"
+
+type alertSender interface {
+	Send(ctx context.Context, ds *datasources.DataSource, st DataSourceStatus, endsAt time.Time) error
+}
+
+type alertmanagerProvider interface {
+	AlertmanagerFor(orgID int64) (notifier.Alertmanager, error)
+}
+
+// alertmanagerSender sends data source health alerts to the Grafana Alertmanager
+// of the data source's organization, so they go through its notification policies.
+type alertmanagerSender struct {
+	moa    alertmanagerProvider
+	appURL string
+}
+
+func (a *alertmanagerSender) Send(_ context.Context, ds *datasources.DataSource, st DataSourceStatus, endsAt time.Time) error {
+	am, err := a.moa.AlertmanagerFor(ds.OrgID)
+	if err != nil {
+		return err
+	}
+
+	alert := models.PostableAlert{
+		Annotations: models.LabelSet{
+			"summary":     fmt.Sprintf("Data source %q is unhealthy", ds.Name),
+			"description": st.Message,
+		},
+		StartsAt: strfmt.DateTime(time.UnixMilli(st.UnhealthySince)),
+		EndsAt:   strfmt.DateTime(endsAt),
+		Alert: models.Alert{
+			Labels: models.LabelSet{
+				"alertname":       AlertName,
+				"datasource_uid":  ds.UID,
+				"datasource_name": ds.Name,
+				"datasource_type": ds.Type,
+			},
+			GeneratorURL: strfmt.URI(fmt.Sprintf("%sconnections/datasources/edit/%s", a.appURL, ds.UID)),
+		},
+	}
+
+	return am.PutAlerts(apimodels.PostableAlerts{PostableAlerts: []models.PostableAlert{alert}})
+}
@@ -0,0 +1,118 @@
+package datasourcehealth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	uidScope := datasources.ScopeProvider.GetResourceScopeUID(ac.Parameter(":uid"))
+	authorize := ac.Middleware(s.accessControl)
+
+	s.routeRegister.Get("/api/datasources/health/status", middleware.ReqSignedIn, authorize(ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.getStatusHandler))
+	s.routeRegister.Get("/api/datasources/uid/:uid/health/history", middleware.ReqSignedIn, authorize(ac.EvalPermission(datasources.ActionRead, uidScope)), routing.Wrap(s.getHistoryHandler))
+}
+
+// swagger:route GET /datasources/uid/{uid}/health/history datasources getDataSourceHealthHistory
+//
+// Get the health check history of a data source.
+//
+// Returns the results of the periodic health checks of the data source, most recent first.
+//
+// Responses:
+// 200: getDataSourceHealthHistoryResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) getHistoryHandler(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	ds, err := s.dataSourceService.GetDataSource(c.Req.Context(), &datasources.GetDataSourceQuery{UID: uid, OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		if errors.Is(err, datasources.ErrDataSourceNotFound) {
+			return response.Error(http.StatusNotFound, "Data source not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get data source", err)
+	}
+
+	checks, err := s.GetHistory(c.Req.Context(), GetHistoryQuery{
+		OrgID:         ds.OrgID,
+		DatasourceUID: ds.UID,
+		From:          c.QueryInt64("from"),
+		To:            c.QueryInt64("to"),
+		Limit:         c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get health history", err)
+	}
+
+	return response.JSON(http.StatusOK, HealthHistory{DatasourceUID: ds.UID, Checks: checks})
+}
+
+// swagger:route GET /datasources/health/status datasources getDataSourceHealthStatus
+//
+// Get the latest health of all data sources.
+//
+// Returns the result of the last periodic health check of every data source in the organization
+// that the user can read.
+//
+// Responses:
+// 200: getDataSourceHealthStatusResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+func (s *Service) getStatusHandler(c *contextmodel.ReqContext) response.Response {
+	status := s.GetStatus(c.SignedInUser.GetOrgID())
+
+	result := make([]DataSourceStatus, 0, len(status))
+	for _, st := range status {
+		evaluator := ac.EvalPermission(datasources.ActionRead, datasources.ScopeProvider.GetResourceScopeUID(st.DatasourceUID))
+		canRead, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to check data source permissions", err)
+		}
+		if canRead {
+			result = append(result, st)
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:parameters getDataSourceHealthHistory
+type GetDataSourceHealthHistoryParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// Unix timestamp in milliseconds of the oldest check to return.
+	// in:query
+	// required:false
+	From int64 `json:"from"`
+	// Unix timestamp in milliseconds of the newest check to return.
+	// in:query
+	// required:false
+	To int64 `json:"to"`
+	// Maximum number of checks to return, at most 1000.
+	// in:query
+	// required:false
+	// default:100
+	Limit int `json:"limit"`
+}
+
+// swagger:response getDataSourceHealthHistoryResponse
+type GetDataSourceHealthHistoryResponse struct {
+	// in:body
+	Body HealthHistory `json:"body"`
+}
+
+// swagger:response getDataSourceHealthStatusResponse
+type GetDataSourceHealthStatusResponse struct {
+	// in:body
+	Body []DataSourceStatus `json:"body"`
+}
@@ -0,0 +1,75 @@
+package datasourcehealth
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+const (
+	defaultHistoryLimit = 100
+	maxHistoryLimit     = 1000
+)
+
+type store interface {
+	Insert(ctx context.Context, check *HealthCheck) error
+	GetHistory(ctx context.Context, query GetHistoryQuery) ([]HealthCheck, error)
+	GetLatest(ctx context.Context) ([]HealthCheck, error)
+	DeleteOlderThan(ctx context.Context, created int64) (int64, error)
+}
+
+type dbStore struct {
+	db db.DB
+}
+
+func (s *dbStore) Insert(ctx context.Context, check *HealthCheck) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(check)
+		return err
+	})
+}
+
+// GetHistory returns the most recent health checks first.
+func (s *dbStore) GetHistory(ctx context.Context, query GetHistoryQuery) ([]HealthCheck, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultHistoryLimit
+	}
+
+	checks := make([]HealthCheck, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Where("org_id = ? AND datasource_uid = ?", query.OrgID, query.DatasourceUID)
+		if query.From > 0 {
+			sess.And("created >= ?", query.From)
+		}
+		if query.To > 0 {
+			sess.And("created <= ?", query.To)
+		}
+		return sess.Desc("created").Limit(query.Limit).Find(&checks)
+	})
+	return checks, err
+}
+
+// GetLatest returns the most recent health check of every data source.
+func (s *dbStore) GetLatest(ctx context.Context) ([]HealthCheck, error) {
+	checks := make([]HealthCheck, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(`SELECT h.* FROM data_source_health h
+			INNER JOIN (
+				SELECT org_id, datasource_uid, MAX(created) AS created FROM data_source_health GROUP BY org_id, datasource_uid
+			) latest ON h.org_id = latest.org_id AND h.datasource_uid = latest.datasource_uid AND h.created = latest.created`).Find(&checks)
+	})
+	return checks, err
+}
+
+func (s *dbStore) DeleteOlderThan(ctx context.Context, created int64) (int64, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM data_source_health WHERE created < ?", created)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -0,0 +1,62 @@
+package datasourcehealth
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubsystem = "datasource_health"
+)
+
+type metrics struct {
+	up       *prometheus.GaugeVec
+	latency  *prometheus.GaugeVec
+	failures *prometheus.CounterVec
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	labels := []string{"org_id", "datasource_uid", "datasource_type"}
+	return &metrics{
+		up: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "up",
+			Help:      "1 if the last health check of the data source succeeded, 0 otherwise",
+		}, labels),
+		latency: promauto.With(r).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "check_duration_seconds",
+			Help:      "Duration of the last health check of the data source",
+		}, labels),
+		failures: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubsystem,
+			Name:      "check_failures_total",
+			Help:      "A counter for failed data source health checks",
+		}, labels),
+	}
+}
+
+func (m *metrics) observe(orgID int64, uid, dsType string, healthy bool, seconds float64) {
+	labels := []string{strconv.FormatInt(orgID, 10), uid, dsType}
+	up := 0.0
+	if healthy {
+		up = 1
+	} else {
+		m.failures.WithLabelValues(labels...).Inc()
+	}
+	m.up.WithLabelValues(labels...).Set(up)
+	m.latency.WithLabelValues(labels...).Set(seconds)
+}
+
+func (m *metrics) forget(orgID int64, uid, dsType string) {
+	labels := []string{strconv.FormatInt(orgID, 10), uid, dsType}
+	m.up.DeleteLabelValues(labels...)
+	m.latency.DeleteLabelValues(labels...)
+	m.failures.DeleteLabelValues(labels...)
+}
@@ -0,0 +1,65 @@
+package datasourcehealth
+
+// HealthCheck is a single stored health check result.
+type HealthCheck struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	DatasourceUID string `xorm:"datasource_uid"`
+	Status        string `xorm:"status"`
+	LatencyMs     int64  `xorm:"latency_ms"`
+	Message       string `xorm:"message"`
+	Created       int64  `xorm:"'created'"`
+	// UnhealthySince and AlertFiring are the state of the data source after the check,
+	// read by the server that checks the data sources next.
+	UnhealthySince int64 `xorm:"unhealthy_since"`
+	AlertFiring    bool  `xorm:"alert_firing"`
+}
+
+func (HealthCheck) TableName() string {
+	return "data_source_health"
+}
+
+// HealthCheckDTO is the API representation of a health check result.
+type HealthCheckDTO struct {
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latencyMs"`
+	Message   string `json:"message"`
+	// Time is the unix timestamp of the check in milliseconds.
+	Time int64 `json:"time"`
+}
+
+// GetHistoryQuery selects the health checks of a single data source.
+type GetHistoryQuery struct {
+	OrgID         int64
+	DatasourceUID string
+	// From and To are unix timestamps in milliseconds. Zero means unbounded.
+	From  int64
+	To    int64
+	Limit int
+}
+
+// HealthHistory is the response of the health history API.
+type HealthHistory struct {
+	DatasourceUID string           `json:"datasourceUid"`
+	Checks        []HealthCheckDTO `json:"checks"`
+}
+
+// DataSourceStatus is the latest known health of a data source.
+type DataSourceStatus struct {
+	DatasourceUID  string `json:"datasourceUid"`
+	DatasourceName string `json:"datasourceName"`
+	DatasourceType string `json:"datasourceType"`
+	HealthCheckDTO
+	// UnhealthySince is the unix timestamp in milliseconds of the first failed check
+	// in the current run of failures, zero when healthy.
+	UnhealthySince int64 `json:"unhealthySince,omitempty"`
+}
+
+func (hc HealthCheck) toDTO() HealthCheckDTO {
+	return HealthCheckDTO{
+		Status:    hc.Status,
+		LatencyMs: hc.LatencyMs,
+		Message:   hc.Message,
+		Time:      hc.Created,
+	}
+}
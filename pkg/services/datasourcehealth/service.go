@@ -0,0 +1,459 @@
+package datasourcehealth
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type pluginContextProvider interface {
+	GetWithDataSource(ctx context.Context, pluginID string, user identity.Requester, ds *datasources.DataSource) (backend.PluginContext, error)
+}
+
+type leaser interface {
+	AcquireLease(ctx context.Context, name string, ttl time.Duration) (*serverlock.Lease, error)
+}
+
+// leaseName is the lease held by the server checking the data sources, the other servers
+// read the results of its checks from the database
+const leaseName = "datasource health checks"
+
+type dataSourceKey struct {
+	orgID int64
+	uid   string
+}
+
+type Service struct {
+	cfg               setting.DataSourceHealthSettings
+	store             store
+	routeRegister     routing.RouteRegister
+	accessControl     accesscontrol.AccessControl
+	dataSourceService datasources.DataSourceService
+	pluginClient      plugins.Client
+	pCtxProvider      pluginContextProvider
+	alerts            alertSender
+	serverLock        leaser
+	metrics           *metrics
+	log               log.Logger
+	now               func() time.Time
+
+	mtx    sync.RWMutex
+	status map[dataSourceKey]*DataSourceStatus
+	firing map[dataSourceKey]bool
+}
+
+func ProvideService(cfg *setting.Cfg, sqlStore db.DB, routeRegister routing.RouteRegister, ac accesscontrol.AccessControl,
+	dataSourceService datasources.DataSourceService, pluginClient plugins.Client, pCtxProvider *plugincontext.Provider,
+	ng *ngalert.AlertNG, registerer prometheus.Registerer, serverLock *serverlock.ServerLockService,
+) *Service {
+	s := &Service{
+		cfg:               cfg.DataSourceHealth,
+		store:             &dbStore{db: sqlStore},
+		routeRegister:     routeRegister,
+		accessControl:     ac,
+		dataSourceService: dataSourceService,
+		pluginClient:      pluginClient,
+		pCtxProvider:      pCtxProvider,
+		serverLock:        serverLock,
+		log:               log.New("datasource.health"),
+		now:               time.Now,
+		status:            map[dataSourceKey]*DataSourceStatus{},
+		firing:            map[dataSourceKey]bool{},
+	}
+
+	if !s.cfg.Enabled {
+		return s
+	}
+	if s.cfg.Interval <= 0 {
+		s.log.Error("Data source health checks are disabled, the check interval must be positive", "checkInterval", s.cfg.Interval)
+		s.cfg.Enabled = false
+		return s
+	}
+
+	s.metrics = newMetrics(registerer)
+	if s.cfg.AlertAfter > 0 && ng != nil && !ng.IsDisabled() {
+		s.alerts = &alertmanagerSender{moa: ng.MultiOrgAlertmanager, appURL: cfg.AppURL}
+	}
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *Service) IsDisabled() bool {
+	return !s.cfg.Enabled
+}
+
+// Run checks the data sources periodically. In an HA setup a single server holds the lease and checks
+// the data sources, the other servers refresh their status from the stored checks.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	var lease *serverlock.Lease
+	defer func() {
+		if lease != nil {
+			if err := lease.Release(context.Background()); err != nil {
+				s.log.Warn("Failed to release the health checks lease", "error", err)
+			}
+		}
+	}()
+
+	for {
+		held := s.holdLease(ctx, lease)
+		if held != nil && held != lease {
+			// the checks continue from the state stored by the previous holder, so that the alerts it fired are resolved
+			s.refreshStatus(ctx)
+		}
+		lease = held
+		if lease != nil {
+			s.checkWhileHolding(ctx, lease)
+			s.deleteExpired(ctx)
+		} else {
+			s.refreshStatus(ctx)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// GetHistory returns the stored health checks of a data source, most recent first. At most
+// maxHistoryLimit checks are returned.
+func (s *Service) GetHistory(ctx context.Context, query GetHistoryQuery) ([]HealthCheckDTO, error) {
+	switch {
+	case query.Limit <= 0:
+		query.Limit = defaultHistoryLimit
+	case query.Limit > maxHistoryLimit:
+		query.Limit = maxHistoryLimit
+	}
+
+	checks, err := s.store.GetHistory(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]HealthCheckDTO, 0, len(checks))
+	for _, c := range checks {
+		result = append(result, c.toDTO())
+	}
+	return result, nil
+}
+
+// GetStatus returns the latest known health of every checked data source in the organization.
+func (s *Service) GetStatus(orgID int64) []DataSourceStatus {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	result := make([]DataSourceStatus, 0)
+	for key, st := range s.status {
+		if key.orgID == orgID {
+			result = append(result, *st)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].DatasourceName < result[j].DatasourceName
+	})
+	return result
+}
+
+// holdLease renews the lease held by this server, or tries to acquire it. It returns nil when another
+// server holds the lease.
+func (s *Service) holdLease(ctx context.Context, lease *serverlock.Lease) *serverlock.Lease {
+	if lease != nil {
+		err := lease.Renew(ctx)
+		if err == nil {
+			return lease
+		}
+		s.log.Warn("Failed to renew the health checks lease", "error", err)
+	}
+
+	// the lease outlives a few intervals, so that another server takes over when this one stops
+	lease, err := s.serverLock.AcquireLease(ctx, leaseName, 3*s.cfg.Interval)
+	var heldErr *serverlock.LeaseHeldError
+	if errors.As(err, &heldErr) {
+		s.log.Debug("Data sources are checked by another server")
+		return nil
+	}
+	if err != nil {
+		s.log.Error("Failed to acquire the health checks lease", "error", err)
+		return nil
+	}
+	return lease
+}
+
+// refreshStatus updates the status with the latest checks stored by the server holding the lease
+func (s *Service) refreshStatus(ctx context.Context) {
+	dataSources, err := s.dataSourceService.GetAllDataSources(ctx, &datasources.GetAllDataSourcesQuery{})
+	if err != nil {
+		s.log.Error("Failed to list data sources", "error", err)
+		return
+	}
+	latest, err := s.store.GetLatest(ctx)
+	if err != nil {
+		s.log.Error("Failed to get the latest health checks", "error", err)
+		return
+	}
+
+	byKey := make(map[dataSourceKey]*datasources.DataSource, len(dataSources))
+	for _, ds := range dataSources {
+		byKey[dataSourceKey{orgID: ds.OrgID, uid: ds.UID}] = ds
+	}
+
+	seen := make(map[dataSourceKey]bool, len(latest))
+	for i := range latest {
+		check := &latest[i]
+		key := dataSourceKey{orgID: check.OrgID, uid: check.DatasourceUID}
+		ds, ok := byKey[key]
+		if !ok {
+			continue
+		}
+		seen[key] = true
+		s.updateStatus(ds, check, check.Status == backend.HealthStatusOk.String())
+		s.mtx.Lock()
+		s.firing[key] = check.AlertFiring
+		s.mtx.Unlock()
+	}
+
+	s.forgetDeleted(seen)
+}
+
+// checkWhileHolding checks the data sources and renews the lease while the checks run, so that the lease doesn't
+// expire before a long run ends and another server doesn't start the same checks. The checks are stopped when the
+// lease is lost.
+func (s *Service) checkWhileHolding(ctx context.Context, lease *serverlock.Lease) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		s.renewUntilDone(ctx, cancel, lease)
+	}()
+
+	s.checkAll(ctx)
+	cancel()
+	<-renewed
+}
+
+func (s *Service) renewUntilDone(ctx context.Context, cancel context.CancelFunc, lease *serverlock.Lease) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := lease.Renew(ctx)
+		if err == nil || ctx.Err() != nil {
+			continue
+		}
+		if errors.Is(err, serverlock.ErrLeaseLost) {
+			s.log.Warn("Lost the health checks lease, stopping the checks", "error", err)
+			cancel()
+			return
+		}
+		s.log.Warn("Failed to renew the health checks lease", "error", err)
+	}
+}
+
+// checkAll checks at most MaxConcurrentChecks data sources at the same time
+func (s *Service) checkAll(ctx context.Context) {
+	dataSources, err := s.dataSourceService.GetAllDataSources(ctx, &datasources.GetAllDataSourcesQuery{})
+	if err != nil {
+		s.log.Error("Failed to list data sources", "error", err)
+		return
+	}
+
+	concurrency := s.cfg.MaxConcurrentChecks
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	limit := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	seen := make(map[dataSourceKey]bool, len(dataSources))
+	for _, ds := range dataSources {
+		select {
+		case limit <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		seen[dataSourceKey{orgID: ds.OrgID, uid: ds.UID}] = true
+		wg.Add(1)
+		go func(ds *datasources.DataSource) {
+			defer func() {
+				<-limit
+				wg.Done()
+			}()
+			s.check(ctx, ds)
+		}(ds)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return
+	}
+	s.forgetDeleted(seen)
+}
+
+func (s *Service) check(ctx context.Context, ds *datasources.DataSource) {
+	checkCtx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	user := accesscontrol.BackgroundUser("datasource_health", ds.OrgID, org.RoleAdmin, []accesscontrol.Permission{
+		{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID(ds.UID)},
+	})
+	pCtx, err := s.pCtxProvider.GetWithDataSource(checkCtx, ds.Type, user, ds)
+	if err != nil {
+		if !errors.Is(err, plugincontext.ErrPluginNotFound) {
+			s.log.Warn("Failed to get plugin context", "datasourceUid", ds.UID, "error", err)
+		}
+		return
+	}
+
+	start := s.now()
+	resp, err := s.pluginClient.CheckHealth(checkCtx, &backend.CheckHealthRequest{PluginContext: pCtx, Headers: map[string]string{}})
+	latency := s.now().Sub(start)
+	if errors.Is(err, plugins.ErrMethodNotImplemented) || errors.Is(err, plugins.ErrPluginNotRegistered) {
+		// Frontend only data sources can't be checked from the backend.
+		return
+	}
+
+	result := &HealthCheck{
+		OrgID:         ds.OrgID,
+		DatasourceUID: ds.UID,
+		LatencyMs:     latency.Milliseconds(),
+		Created:       start.UnixMilli(),
+	}
+	switch {
+	case err != nil:
+		result.Status = backend.HealthStatusError.String()
+		result.Message = err.Error()
+	case resp == nil:
+		result.Status = backend.HealthStatusUnknown.String()
+	default:
+		result.Status = resp.Status.String()
+		result.Message = resp.Message
+	}
+
+	healthy := result.Status == backend.HealthStatusOk.String()
+	st := s.updateStatus(ds, result, healthy)
+	firing, wasFiring := s.updateFiring(ds, st)
+	// the state of the alert is stored with the check, so that the server checking next continues from it
+	result.UnhealthySince = st.UnhealthySince
+	result.AlertFiring = firing
+
+	if err := s.store.Insert(ctx, result); err != nil {
+		s.log.Error("Failed to store health check", "datasourceUid", ds.UID, "error", err)
+	}
+
+	s.metrics.observe(ds.OrgID, ds.UID, ds.Type, healthy, latency.Seconds())
+	if firing || wasFiring {
+		s.sendAlert(ctx, ds, st, firing)
+	}
+}
+
+func (s *Service) updateStatus(ds *datasources.DataSource, result *HealthCheck, healthy bool) DataSourceStatus {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	key := dataSourceKey{orgID: ds.OrgID, uid: ds.UID}
+	st := &DataSourceStatus{
+		DatasourceUID:  ds.UID,
+		DatasourceName: ds.Name,
+		DatasourceType: ds.Type,
+		HealthCheckDTO: result.toDTO(),
+	}
+	if !healthy {
+		st.UnhealthySince = result.UnhealthySince
+		if st.UnhealthySince == 0 {
+			st.UnhealthySince = result.Created
+			if prev, ok := s.status[key]; ok && prev.UnhealthySince != 0 {
+				st.UnhealthySince = prev.UnhealthySince
+			}
+		}
+	}
+	s.status[key] = st
+	return *st
+}
+
+// updateFiring returns whether the alert of the data source fires after the check, and whether it fired before
+func (s *Service) updateFiring(ds *datasources.DataSource, st DataSourceStatus) (bool, bool) {
+	if s.alerts == nil {
+		return false, false
+	}
+
+	key := dataSourceKey{orgID: ds.OrgID, uid: ds.UID}
+	firing := st.UnhealthySince != 0 && s.now().Sub(time.UnixMilli(st.UnhealthySince)) >= s.cfg.AlertAfter
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	wasFiring := s.firing[key]
+	s.firing[key] = firing
+	return firing, wasFiring
+}
+
+func (s *Service) sendAlert(ctx context.Context, ds *datasources.DataSource, st DataSourceStatus, firing bool) {
+	// While firing the alert is sent on every check with an end time a few intervals
+	// away, so that it resolves by itself if Grafana stops checking.
+	now := s.now()
+	endsAt := now.Add(3 * s.cfg.Interval)
+	if !firing {
+		endsAt = now
+	}
+	if err := s.alerts.Send(ctx, ds, st, endsAt); err != nil {
+		s.log.Warn("Failed to send data source health alert", "datasourceUid", ds.UID, "error", err)
+	}
+}
+
+func (s *Service) forgetDeleted(seen map[dataSourceKey]bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for key, st := range s.status {
+		if !seen[key] {
+			s.metrics.forget(key.orgID, key.uid, st.DatasourceType)
+			delete(s.status, key)
+			delete(s.firing, key)
+		}
+	}
+}
+
+func (s *Service) deleteExpired(ctx context.Context) {
+	if s.cfg.Retention <= 0 {
+		return
+	}
+
+	affected, err := s.store.DeleteOlderThan(ctx, s.now().Add(-s.cfg.Retention).UnixMilli())
+	if err != nil {
+		s.log.Error("Failed to delete expired health checks", "error", err)
+		return
+	}
+	s.log.Debug("Deleted expired health checks", "count", affected)
+}
@@ -0,0 +1,362 @@
+package datasourcehealth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datasourcefakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestService_checkAll(t *testing.T) {
+	ds := &datasources.DataSource{ID: 1, OrgID: 1, UID: "prom", Name: "Prometheus", Type: "prometheus"}
+
+	t.Run("stores results and reports status", func(t *testing.T) {
+		s, st, client, _ := setupTestService(t, ds)
+		client.result = &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "Data source is working"}
+
+		s.checkAll(context.Background())
+
+		require.Len(t, st.checks, 1)
+		require.Equal(t, "OK", st.checks[0].Status)
+		require.Equal(t, "prom", st.checks[0].DatasourceUID)
+
+		status := s.GetStatus(1)
+		require.Len(t, status, 1)
+		require.Equal(t, "Prometheus", status[0].DatasourceName)
+		require.Equal(t, int64(0), status[0].UnhealthySince)
+		require.Empty(t, s.GetStatus(2))
+	})
+
+	t.Run("plugin errors are stored as failed checks", func(t *testing.T) {
+		s, st, client, _ := setupTestService(t, ds)
+		client.err = errors.New("connection refused")
+
+		s.checkAll(context.Background())
+
+		require.Len(t, st.checks, 1)
+		require.Equal(t, "ERROR", st.checks[0].Status)
+		require.Equal(t, "connection refused", st.checks[0].Message)
+	})
+
+	t.Run("data sources without backend are skipped", func(t *testing.T) {
+		s, st, client, _ := setupTestService(t, ds)
+		client.err = plugins.ErrMethodNotImplemented
+
+		s.checkAll(context.Background())
+
+		require.Empty(t, st.checks)
+		require.Empty(t, s.GetStatus(1))
+	})
+
+	t.Run("alerts once unhealthy for long enough and resolves on recovery", func(t *testing.T) {
+		s, _, client, alerts := setupTestService(t, ds)
+		s.cfg.AlertAfter = 10 * time.Minute
+		now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		s.now = func() time.Time { return now }
+		client.result = &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: "bad credentials"}
+
+		s.checkAll(context.Background())
+		require.Empty(t, alerts.sent)
+
+		now = now.Add(10 * time.Minute)
+		s.checkAll(context.Background())
+		require.Len(t, alerts.sent, 1)
+		require.Equal(t, now.Add(-10*time.Minute).UnixMilli(), alerts.sent[0].status.UnhealthySince)
+		require.True(t, alerts.sent[0].endsAt.After(now))
+
+		now = now.Add(5 * time.Minute)
+		client.result = &backend.CheckHealthResult{Status: backend.HealthStatusOk}
+		s.checkAll(context.Background())
+		require.Len(t, alerts.sent, 2)
+		require.Equal(t, now, alerts.sent[1].endsAt)
+
+		now = now.Add(5 * time.Minute)
+		s.checkAll(context.Background())
+		require.Len(t, alerts.sent, 2)
+	})
+
+	t.Run("a server taking over the checks resolves the alert fired by the previous one", func(t *testing.T) {
+		previous, st, client, _ := setupTestService(t, ds)
+		previous.cfg.AlertAfter = 10 * time.Minute
+		now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		previous.now = func() time.Time { return now }
+		client.result = &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: "bad credentials"}
+
+		previous.checkAll(context.Background())
+		now = now.Add(10 * time.Minute)
+		previous.checkAll(context.Background())
+		last := st.checks[len(st.checks)-1]
+		require.True(t, last.AlertFiring)
+		require.Equal(t, now.Add(-10*time.Minute).UnixMilli(), last.UnhealthySince)
+
+		next, nextStore, nextClient, alerts := setupTestService(t, ds)
+		next.cfg.AlertAfter = 10 * time.Minute
+		next.now = func() time.Time { return now }
+		nextStore.latest = []HealthCheck{last}
+		next.refreshStatus(context.Background())
+		require.Equal(t, last.UnhealthySince, next.GetStatus(1)[0].UnhealthySince)
+
+		now = now.Add(time.Minute)
+		nextClient.result = &backend.CheckHealthResult{Status: backend.HealthStatusOk}
+		next.checkAll(context.Background())
+		require.Len(t, alerts.sent, 1)
+		require.Equal(t, now, alerts.sent[0].endsAt)
+		require.False(t, nextStore.checks[0].AlertFiring)
+	})
+
+	t.Run("deleted data sources are removed from the status", func(t *testing.T) {
+		s, _, client, _ := setupTestService(t, ds)
+		client.result = &backend.CheckHealthResult{Status: backend.HealthStatusOk}
+
+		s.checkAll(context.Background())
+		require.Len(t, s.GetStatus(1), 1)
+
+		s.dataSourceService.(*datasourcefakes.FakeDataSourceService).DataSources = nil
+		s.checkAll(context.Background())
+		require.Empty(t, s.GetStatus(1))
+	})
+
+	t.Run("checks every data source with bounded concurrency", func(t *testing.T) {
+		dataSources := []*datasources.DataSource{
+			ds,
+			{ID: 2, OrgID: 1, UID: "loki", Name: "Loki", Type: "loki"},
+			{ID: 3, OrgID: 1, UID: "tempo", Name: "Tempo", Type: "tempo"},
+		}
+		s, st, client, _ := setupTestService(t, dataSources...)
+		s.cfg.MaxConcurrentChecks = 2
+		client.result = &backend.CheckHealthResult{Status: backend.HealthStatusOk}
+
+		s.checkAll(context.Background())
+
+		require.Len(t, st.checks, 3)
+		require.Len(t, s.GetStatus(1), 3)
+	})
+}
+
+func TestService_GetHistory(t *testing.T) {
+	s, st, _, _ := setupTestService(t)
+
+	for limit, expected := range map[int]int{0: defaultHistoryLimit, 10: 10, 5000: maxHistoryLimit} {
+		_, err := s.GetHistory(context.Background(), GetHistoryQuery{OrgID: 1, DatasourceUID: "prom", Limit: limit})
+		require.NoError(t, err)
+		require.Equal(t, expected, st.historyQuery.Limit)
+	}
+}
+
+func TestService_getStatusHandler(t *testing.T) {
+	s, _, client, _ := setupTestService(t,
+		&datasources.DataSource{ID: 1, OrgID: 1, UID: "prom", Name: "Prometheus", Type: "prometheus"},
+		&datasources.DataSource{ID: 2, OrgID: 1, UID: "loki", Name: "Loki", Type: "loki"},
+	)
+	s.accessControl = acimpl.ProvideAccessControl(setting.NewCfg())
+	client.result = &backend.CheckHealthResult{Status: backend.HealthStatusOk}
+	s.checkAll(context.Background())
+
+	c := &contextmodel.ReqContext{
+		Context: &web.Context{Req: httptest.NewRequest(http.MethodGet, "/api/datasources/health/status", nil)},
+		SignedInUser: &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{
+			1: {datasources.ActionRead: {datasources.ScopeProvider.GetResourceScopeUID("loki")}},
+		}},
+	}
+	resp := s.getStatusHandler(c)
+	require.Equal(t, http.StatusOK, resp.Status())
+
+	var status []DataSourceStatus
+	require.NoError(t, json.Unmarshal(resp.Body(), &status))
+	require.Len(t, status, 1)
+	require.Equal(t, "loki", status[0].DatasourceUID)
+}
+
+func TestProvideService_invalidInterval(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.DataSourceHealth = setting.DataSourceHealthSettings{Enabled: true, Interval: 0}
+
+	s := ProvideService(cfg, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	require.True(t, s.IsDisabled())
+}
+
+func TestIntegrationService_lease(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ds := &datasources.DataSource{ID: 1, OrgID: 1, UID: "prom", Name: "Prometheus", Type: "prometheus"}
+	serverLock := serverlock.ProvideService(db.InitTestDB(t), tracing.InitializeTracerForTest())
+	ctx := context.Background()
+
+	leader, _, _, _ := setupTestService(t, ds)
+	leader.serverLock = serverLock
+	follower, st, _, _ := setupTestService(t, ds)
+	follower.serverLock = serverLock
+
+	lease := leader.holdLease(ctx, nil)
+	require.NotNil(t, lease)
+	require.Nil(t, follower.holdLease(ctx, nil))
+
+	renewed := leader.holdLease(ctx, lease)
+	require.Same(t, lease, renewed)
+
+	st.latest = []HealthCheck{{OrgID: 1, DatasourceUID: "prom", Status: "ERROR", Created: 1000}, {OrgID: 1, DatasourceUID: "deleted", Status: "OK"}}
+	follower.refreshStatus(ctx)
+	status := follower.GetStatus(1)
+	require.Len(t, status, 1)
+	require.Equal(t, "ERROR", status[0].Status)
+	require.Equal(t, int64(1000), status[0].UnhealthySince)
+
+	require.NoError(t, lease.Release(ctx))
+	require.NotNil(t, follower.holdLease(ctx, nil))
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	st := &dbStore{db: sqlStore}
+	ctx := context.Background()
+
+	for i, created := range []int64{1000, 2000, 3000} {
+		require.NoError(t, st.Insert(ctx, &HealthCheck{OrgID: 1, DatasourceUID: "prom", Status: "OK", LatencyMs: int64(i), Created: created}))
+	}
+	require.NoError(t, st.Insert(ctx, &HealthCheck{OrgID: 1, DatasourceUID: "other", Status: "ERROR", Created: 2000, UnhealthySince: 1000, AlertFiring: true}))
+
+	checks, err := st.GetHistory(ctx, GetHistoryQuery{OrgID: 1, DatasourceUID: "prom"})
+	require.NoError(t, err)
+	require.Len(t, checks, 3)
+	require.Equal(t, int64(3000), checks[0].Created)
+
+	checks, err = st.GetHistory(ctx, GetHistoryQuery{OrgID: 1, DatasourceUID: "prom", From: 1500, To: 2500})
+	require.NoError(t, err)
+	require.Len(t, checks, 1)
+	require.Equal(t, int64(2000), checks[0].Created)
+
+	checks, err = st.GetHistory(ctx, GetHistoryQuery{OrgID: 1, DatasourceUID: "prom", Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, checks, 2)
+
+	latest, err := st.GetLatest(ctx)
+	require.NoError(t, err)
+	require.Len(t, latest, 2)
+	for _, check := range latest {
+		if check.DatasourceUID == "prom" {
+			require.Equal(t, int64(3000), check.Created)
+		} else {
+			require.Equal(t, int64(1000), check.UnhealthySince)
+			require.True(t, check.AlertFiring)
+		}
+	}
+
+	deleted, err := st.DeleteOlderThan(ctx, 2500)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), deleted)
+
+	checks, err = st.GetHistory(ctx, GetHistoryQuery{OrgID: 1, DatasourceUID: "prom"})
+	require.NoError(t, err)
+	require.Len(t, checks, 1)
+}
+
+func setupTestService(t *testing.T, dataSources ...*datasources.DataSource) (*Service, *fakeStore, *fakePluginClient, *fakeAlertSender) {
+	t.Helper()
+
+	st := &fakeStore{}
+	client := &fakePluginClient{}
+	alerts := &fakeAlertSender{}
+	s := &Service{
+		cfg: setting.DataSourceHealthSettings{
+			Enabled:  true,
+			Interval: time.Minute,
+			Timeout:  time.Second,
+		},
+		store:             st,
+		dataSourceService: &datasourcefakes.FakeDataSourceService{DataSources: dataSources},
+		pluginClient:      client,
+		pCtxProvider:      &fakePluginContextProvider{},
+		alerts:            alerts,
+		metrics:           newMetrics(prometheus.NewRegistry()),
+		log:               log.NewNopLogger(),
+		now:               time.Now,
+		status:            map[dataSourceKey]*DataSourceStatus{},
+		firing:            map[dataSourceKey]bool{},
+	}
+	return s, st, client, alerts
+}
+
+type fakeStore struct {
+	mtx          sync.Mutex
+	checks       []HealthCheck
+	latest       []HealthCheck
+	historyQuery GetHistoryQuery
+}
+
+func (f *fakeStore) Insert(_ context.Context, check *HealthCheck) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.checks = append(f.checks, *check)
+	return nil
+}
+
+func (f *fakeStore) GetHistory(_ context.Context, query GetHistoryQuery) ([]HealthCheck, error) {
+	f.historyQuery = query
+	return f.checks, nil
+}
+
+func (f *fakeStore) GetLatest(_ context.Context) ([]HealthCheck, error) {
+	return f.latest, nil
+}
+
+func (f *fakeStore) DeleteOlderThan(_ context.Context, _ int64) (int64, error) {
+	return 0, nil
+}
+
+type fakePluginClient struct {
+	plugins.Client
+	result *backend.CheckHealthResult
+	err    error
+}
+
+func (f *fakePluginClient) CheckHealth(_ context.Context, _ *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	return f.result, f.err
+}
+
+type fakePluginContextProvider struct{}
+
+func (f *fakePluginContextProvider) GetWithDataSource(_ context.Context, pluginID string, _ identity.Requester, _ *datasources.DataSource) (backend.PluginContext, error) {
+	return backend.PluginContext{PluginID: pluginID}, nil
+}
+
+type sentAlert struct {
+	status DataSourceStatus
+	endsAt time.Time
+}
+
+type fakeAlertSender struct {
+	sent []sentAlert
+}
+
+func (f *fakeAlertSender) Send(_ context.Context, _ *datasources.DataSource, st DataSourceStatus, endsAt time.Time) error {
+	f.sent = append(f.sent, sentAlert{status: st, endsAt: endsAt})
+	return nil
+}
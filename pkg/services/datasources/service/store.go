@@ -16,8 +16,10 @@ import (
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -36,8 +38,9 @@ type Store interface {
 }
 
 type SqlStore struct {
-	db     db.DB
-	logger log.Logger
+	db       db.DB
+	logger   log.Logger
+	features featuremgmt.FeatureToggles
 }
 
 func CreateStore(db db.DB, logger log.Logger) *SqlStore {
@@ -149,6 +152,11 @@ func (ss *SqlStore) DeleteDataSource(ctx context.Context, cmd *datasources.Delet
 			}
 
 			cmd.DeletedDatasourcesCount, _ = result.RowsAffected()
+			if ss.emitEntityEvent() {
+				if err := store.InsertEntityEvent(sess, ds.UID, ds.OrgID, store.EntityTypeDatasource, store.EntityEventTypeDelete); err != nil {
+					return err
+				}
+			}
 
 			// Remove associated AccessControl permissions
 			if _, errDeletingPerms := sess.Exec("DELETE FROM permission WHERE scope=?",
@@ -278,6 +286,11 @@ func (ss *SqlStore) AddDataSource(ctx context.Context, cmd *datasources.AddDataS
 		if err := updateIsDefaultFlag(ds, sess); err != nil {
 			return err
 		}
+		if ss.emitEntityEvent() {
+			if err := store.InsertEntityEvent(sess, ds.UID, ds.OrgID, store.EntityTypeDatasource, store.EntityEventTypeCreate); err != nil {
+				return err
+			}
+		}
 
 		if cmd.UpdateSecretFn != nil {
 			if err := cmd.UpdateSecretFn(); err != nil {
@@ -297,6 +310,22 @@ func (ss *SqlStore) AddDataSource(ctx context.Context, cmd *datasources.AddDataS
 	})
 }
 
+// emitEntityEvent returns whether the changes of the datasources are saved as entity events, to update the search index.
+func (ss *SqlStore) emitEntityEvent() bool {
+	return ss.features != nil && ss.features.IsEnabled(featuremgmt.FlagPanelTitleSearch)
+}
+
+// emitUpdateEntityEvents saves the update of a datasource, and the deletion of its previous UID when it is changed.
+func emitUpdateEntityEvents(sess *db.Session, existing *datasources.DataSource, uid string) error {
+	if uid == "" || uid == existing.UID {
+		return store.InsertEntityEvent(sess, existing.UID, existing.OrgID, store.EntityTypeDatasource, store.EntityEventTypeUpdate)
+	}
+	if err := store.InsertEntityEvent(sess, existing.UID, existing.OrgID, store.EntityTypeDatasource, store.EntityEventTypeDelete); err != nil {
+		return err
+	}
+	return store.InsertEntityEvent(sess, uid, existing.OrgID, store.EntityTypeDatasource, store.EntityEventTypeUpdate)
+}
+
 func updateIsDefaultFlag(ds *datasources.DataSource, sess *db.Session) error {
 	// Handle is default flag
 	if ds.IsDefault {
@@ -351,6 +380,15 @@ func (ss *SqlStore) UpdateDataSource(ctx context.Context, cmd *datasources.Updat
 		// secure json data to the unified secrets table.
 		sess.MustCols("secure_json_data")
 
+		// the UID is not changed when it is empty
+		var existing *datasources.DataSource
+		if ss.emitEntityEvent() {
+			var err error
+			if existing, err = ss.getDataSource(ctx, &datasources.GetDataSourceQuery{ID: ds.ID, OrgID: ds.OrgID}, sess); err != nil {
+				return err
+			}
+		}
+
 		var updateSession *xorm.Session
 		if cmd.Version != 0 {
 			// the reason we allow cmd.version > db.version is make it possible for people to force
@@ -370,6 +408,12 @@ func (ss *SqlStore) UpdateDataSource(ctx context.Context, cmd *datasources.Updat
 			return datasources.ErrDataSourceUpdatingOldVersion
 		}
 
+		if existing != nil {
+			if err := emitUpdateEntityEvents(sess, existing, ds.UID); err != nil {
+				return err
+			}
+		}
+
 		err = updateIsDefaultFlag(ds, sess)
 
 		if cmd.UpdateSecretFn != nil {
@@ -14,6 +14,9 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/services/store"
 )
 
 func TestIntegrationDataAccess(t *testing.T) {
@@ -216,6 +219,68 @@ func TestIntegrationDataAccess(t *testing.T) {
 		})
 	})
 
+	t.Run("EntityEvents", func(t *testing.T) {
+		t.Run("saves an entity event for each change", func(t *testing.T) {
+			db := db.InitTestDB(t)
+			ss := SqlStore{db: db, features: featuremgmt.WithFeatures(featuremgmt.FlagPanelTitleSearch)}
+
+			addCmd := defaultAddDatasourceCommand
+			addCmd.UID = "nisse"
+			ds, err := ss.AddDataSource(context.Background(), &addCmd)
+			require.NoError(t, err)
+
+			updateCmd := defaultUpdateDatasourceCommand
+			updateCmd.ID = ds.ID
+			_, err = ss.UpdateDataSource(context.Background(), &updateCmd)
+			require.NoError(t, err)
+
+			updateCmd.UID = "nisse_updated"
+			_, err = ss.UpdateDataSource(context.Background(), &updateCmd)
+			require.NoError(t, err)
+
+			err = ss.DeleteDataSource(context.Background(), &datasources.DeleteDataSourceCommand{ID: ds.ID, OrgID: ds.OrgID})
+			require.NoError(t, err)
+
+			var events []*store.EntityEvent
+			err = db.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+				return sess.OrderBy("id").Find(&events)
+			})
+			require.NoError(t, err)
+
+			expected := []struct {
+				uid       string
+				eventType store.EntityEventType
+			}{
+				{"nisse", store.EntityEventTypeCreate},
+				{"nisse", store.EntityEventTypeUpdate},
+				{"nisse", store.EntityEventTypeDelete},
+				{"nisse_updated", store.EntityEventTypeUpdate},
+				{"nisse_updated", store.EntityEventTypeDelete},
+			}
+			require.Len(t, events, len(expected))
+			for i, e := range expected {
+				require.Equal(t, store.CreateDatabaseEntityId(e.uid, 10, store.EntityTypeDatasource), events[i].EntityId)
+				require.Equal(t, e.eventType, events[i].EventType)
+			}
+		})
+
+		t.Run("does not save entity events when the feature is disabled", func(t *testing.T) {
+			db := db.InitTestDB(t)
+			ds := initDatasource(db)
+			ss := SqlStore{db: db}
+
+			err := ss.DeleteDataSource(context.Background(), &datasources.DeleteDataSourceCommand{ID: ds.ID, OrgID: ds.OrgID})
+			require.NoError(t, err)
+
+			var events []*store.EntityEvent
+			err = db.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+				return sess.Find(&events)
+			})
+			require.NoError(t, err)
+			require.Empty(t, events)
+		})
+	})
+
 	t.Run("DeleteDataSourceById", func(t *testing.T) {
 		t.Run("can delete datasource", func(t *testing.T) {
 			db := db.InitTestDB(t)
@@ -0,0 +1,15 @@
+package foldersettings
+
+import (
+	"context"
+)
+
+type Service interface {
+	// Get returns the settings set on a folder. A folder without settings has empty settings.
+	Get(ctx context.Context, query *GetFolderSettingsQuery) (*FolderSettings, error)
+	// GetResolved returns the settings of a folder, with the settings it does not set inherited from its
+	// nearest parent folder setting them.
+	GetResolved(ctx context.Context, query *GetFolderSettingsQuery) (*ResolvedFolderSettings, error)
+	Save(ctx context.Context, cmd *SaveFolderSettingsCommand) (*FolderSettings, error)
+	Delete(ctx context.Context, cmd *DeleteFolderSettingsCommand) error
+}
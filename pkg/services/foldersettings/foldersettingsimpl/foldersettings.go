@@ -0,0 +1,274 @@
+package foldersettingsimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards/service"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/foldersettings"
+)
+
+type Service struct {
+	store            store
+	folderService    folder.Service
+	folderStore      folder.FolderStore
+	dashboardService dashboards.DashboardService
+}
+
+var _ foldersettings.Service = &Service{}
+var _ dashboards.CreateHook = &Service{}
+var _ dashboards.SaveValidator = &Service{}
+
+func ProvideService(db db.DB, folderService folder.Service, folderStore folder.FolderStore, dashboardService *dashboardservice.DashboardServiceImpl) foldersettings.Service {
+	s := &Service{
+		store:            &sqlStore{db: db},
+		folderService:    folderService,
+		folderStore:      folderStore,
+		dashboardService: dashboardService,
+	}
+	dashboardService.RegisterCreateHook(s)
+	dashboardService.RegisterSaveValidator(s)
+	return s
+}
+
+func (s *Service) Get(ctx context.Context, query *foldersettings.GetFolderSettingsQuery) (*foldersettings.FolderSettings, error) {
+	settings, err := s.store.Get(ctx, query.OrgID, query.FolderUID)
+	if err != nil {
+		return nil, err
+	}
+	if fs, ok := settings[query.FolderUID]; ok {
+		return fs, nil
+	}
+	return &foldersettings.FolderSettings{
+		OrgID:              query.OrgID,
+		FolderUID:          query.FolderUID,
+		RequiredTags:       []string{},
+		PermissionTemplate: []accesscontrol.SetResourcePermissionCommand{},
+	}, nil
+}
+
+func (s *Service) GetResolved(ctx context.Context, query *foldersettings.GetFolderSettingsQuery) (*foldersettings.ResolvedFolderSettings, error) {
+	parents, err := s.folderService.GetParents(ctx, folder.GetParentsQuery{UID: query.FolderUID, OrgID: query.OrgID})
+	if err != nil && !errors.Is(err, folder.ErrFolderNotFound) {
+		return nil, err
+	}
+
+	// the folder and its parents, from the nearest to the root folder
+	uids := make([]string, 0, len(parents)+1)
+	uids = append(uids, query.FolderUID)
+	for i := len(parents) - 1; i >= 0; i-- {
+		uids = append(uids, parents[i].UID)
+	}
+
+	settings, err := s.store.Get(ctx, query.OrgID, uids...)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := &foldersettings.ResolvedFolderSettings{
+		FolderUID:          query.FolderUID,
+		RequiredTags:       []string{},
+		PermissionTemplate: []accesscontrol.SetResourcePermissionCommand{},
+		Sources:            map[string]string{},
+	}
+	for _, uid := range uids {
+		fs, ok := settings[uid]
+		if !ok {
+			continue
+		}
+		if resolved.DefaultDatasourceUID == "" && fs.DefaultDatasourceUID != "" {
+			resolved.DefaultDatasourceUID = fs.DefaultDatasourceUID
+			resolved.Sources["defaultDatasourceUid"] = uid
+		}
+		if resolved.DefaultRefresh == "" && fs.DefaultRefresh != "" {
+			resolved.DefaultRefresh = fs.DefaultRefresh
+			resolved.Sources["defaultRefresh"] = uid
+		}
+		if resolved.Timezone == "" && fs.Timezone != "" {
+			resolved.Timezone = fs.Timezone
+			resolved.Sources["timezone"] = uid
+		}
+		if len(resolved.PermissionTemplate) == 0 && len(fs.PermissionTemplate) > 0 {
+			resolved.PermissionTemplate = fs.PermissionTemplate
+			resolved.Sources["permissionTemplate"] = uid
+		}
+		for _, tag := range fs.RequiredTags {
+			if !slices.Contains(resolved.RequiredTags, tag) {
+				resolved.RequiredTags = append(resolved.RequiredTags, tag)
+			}
+		}
+	}
+
+	return resolved, nil
+}
+
+func (s *Service) Save(ctx context.Context, cmd *foldersettings.SaveFolderSettingsCommand) (*foldersettings.FolderSettings, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	settings := &foldersettings.FolderSettings{
+		OrgID:                cmd.OrgID,
+		FolderUID:            cmd.FolderUID,
+		DefaultDatasourceUID: cmd.DefaultDatasourceUID,
+		DefaultRefresh:       cmd.DefaultRefresh,
+		Timezone:             cmd.Timezone,
+		RequiredTags:         cmd.RequiredTags,
+		PermissionTemplate:   cmd.PermissionTemplate,
+		ProvisionedChecksum:  cmd.ProvisionedChecksum,
+		Updated:              time.Now(),
+	}
+	if settings.PermissionTemplate == nil {
+		settings.PermissionTemplate = []accesscontrol.SetResourcePermissionCommand{}
+	}
+
+	if err := s.store.Save(ctx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (s *Service) Delete(ctx context.Context, cmd *foldersettings.DeleteFolderSettingsCommand) error {
+	return s.store.Delete(ctx, cmd.OrgID, cmd.FolderUID)
+}
+
+// BeforeCreate sets the resolved settings of the folder of a new dashboard as defaults of the dashboard.
+func (s *Service) BeforeCreate(ctx context.Context, dashboard *dashboards.Dashboard) error {
+	resolved, err := s.dashboardFolderSettings(ctx, dashboard)
+	if err != nil || resolved == nil {
+		return err
+	}
+
+	applyDefaults(dashboard.Data, resolved)
+	return nil
+}
+
+// CreatePermissions returns the permission template of the folder of a new dashboard.
+func (s *Service) CreatePermissions(ctx context.Context, dashboard *dashboards.Dashboard) ([]accesscontrol.SetResourcePermissionCommand, error) {
+	resolved, err := s.dashboardFolderSettings(ctx, dashboard)
+	if err != nil || resolved == nil {
+		return nil, err
+	}
+	return resolved.PermissionTemplate, nil
+}
+
+// ValidateSave rejects the updates of dashboards without the required tags of their folder. The tags are added to the
+// new dashboards by BeforeCreate.
+func (s *Service) ValidateSave(ctx context.Context, dashboard *dashboards.Dashboard) error {
+	if dashboard.FolderID == 0 {
+		return nil
+	}
+	if exists, err := s.dashboardExists(ctx, dashboard); err != nil || !exists {
+		return err
+	}
+
+	resolved, err := s.dashboardFolderSettings(ctx, dashboard)
+	if err != nil || resolved == nil {
+		return err
+	}
+
+	tags := dashboard.Data.Get("tags").MustStringArray()
+	missing := make([]string, 0)
+	for _, tag := range resolved.RequiredTags {
+		if !slices.Contains(tags, tag) {
+			missing = append(missing, tag)
+		}
+	}
+	if len(missing) > 0 {
+		return dashboards.DashboardErr{
+			Reason:     fmt.Sprintf("Dashboard is missing the tags required by its folder: %s", strings.Join(missing, ", ")),
+			StatusCode: 400,
+		}
+	}
+	return nil
+}
+
+// dashboardExists returns whether a saved dashboard replaces an existing dashboard, the ID of a dashboard
+// overwritten by UID is not set before it is validated.
+func (s *Service) dashboardExists(ctx context.Context, dashboard *dashboards.Dashboard) (bool, error) {
+	if dashboard.ID != 0 {
+		return true, nil
+	}
+	if dashboard.UID == "" {
+		return false, nil
+	}
+	_, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: dashboard.OrgID, UID: dashboard.UID})
+	if errors.Is(err, dashboards.ErrDashboardNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *Service) dashboardFolderSettings(ctx context.Context, dashboard *dashboards.Dashboard) (*foldersettings.ResolvedFolderSettings, error) {
+	if dashboard.FolderID == 0 {
+		return nil, nil
+	}
+
+	f, err := s.folderStore.GetFolderByID(ctx, dashboard.OrgID, dashboard.FolderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetResolved(ctx, &foldersettings.GetFolderSettingsQuery{OrgID: dashboard.OrgID, FolderUID: f.UID})
+}
+
+// applyDefaults sets the settings on the dashboard JSON where the dashboard does not set them, and adds the
+// required tags.
+func applyDefaults(data *simplejson.Json, resolved *foldersettings.ResolvedFolderSettings) {
+	if resolved.DefaultRefresh != "" && data.Get("refresh").MustString() == "" {
+		data.Set("refresh", resolved.DefaultRefresh)
+	}
+
+	if resolved.Timezone != "" && data.Get("timezone").MustString() == "" {
+		data.Set("timezone", resolved.Timezone)
+	}
+
+	if len(resolved.RequiredTags) > 0 {
+		tags := data.Get("tags").MustArray()
+		for _, tag := range resolved.RequiredTags {
+			if !slices.Contains(tags, any(tag)) {
+				tags = append(tags, tag)
+			}
+		}
+		data.Set("tags", tags)
+	}
+
+	if resolved.DefaultDatasourceUID != "" {
+		setDefaultDatasource(data.Get("panels").MustArray(), resolved.DefaultDatasourceUID)
+		for _, v := range data.Get("templating").Get("list").MustArray() {
+			variable, ok := v.(map[string]any)
+			if ok && variable["type"] == "query" && variable["datasource"] == nil {
+				variable["datasource"] = map[string]any{"uid": resolved.DefaultDatasourceUID}
+			}
+		}
+	}
+}
+
+// setDefaultDatasource sets the datasource of the panels with queries and without datasource, including the panels
+// of collapsed rows.
+func setDefaultDatasource(panels []any, uid string) {
+	for _, p := range panels {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		if panel["type"] == "row" {
+			if rowPanels, ok := panel["panels"].([]any); ok {
+				setDefaultDatasource(rowPanels, uid)
+			}
+			continue
+		}
+		if targets, ok := panel["targets"].([]any); ok && len(targets) > 0 && panel["datasource"] == nil {
+			panel["datasource"] = map[string]any{"uid": uid}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package foldersettingsimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/services/foldersettings"
+)
+
+func TestIntegrationFolderSettingsService(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	folderService := foldertest.NewFakeService()
+	folderStore := foldertest.NewFakeFolderStore(t)
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	s := &Service{
+		store:            &sqlStore{db: db.InitTestDB(t)},
+		folderService:    folderService,
+		folderStore:      folderStore,
+		dashboardService: dashboardService,
+	}
+
+	save := func(cmd foldersettings.SaveFolderSettingsCommand) {
+		t.Helper()
+		cmd.OrgID = 1
+		_, err := s.Save(ctx, &cmd)
+		require.NoError(t, err)
+	}
+	save(foldersettings.SaveFolderSettingsCommand{
+		FolderUID:            "root",
+		DefaultDatasourceUID: "prometheus",
+		DefaultRefresh:       "1m",
+		RequiredTags:         []string{"ops"},
+		PermissionTemplate:   []accesscontrol.SetResourcePermissionCommand{{BuiltinRole: "Viewer", Permission: "View"}},
+	})
+	save(foldersettings.SaveFolderSettingsCommand{
+		FolderUID:    "team",
+		Timezone:     "utc",
+		RequiredTags: []string{"team", "ops"},
+	})
+	save(foldersettings.SaveFolderSettingsCommand{
+		FolderUID:      "service",
+		DefaultRefresh: "30s",
+	})
+
+	t.Run("Get returns empty settings for a folder without settings", func(t *testing.T) {
+		settings, err := s.Get(ctx, &foldersettings.GetFolderSettingsQuery{OrgID: 1, FolderUID: "other"})
+		require.NoError(t, err)
+		require.Equal(t, "other", settings.FolderUID)
+		require.Empty(t, settings.DefaultRefresh)
+		require.Empty(t, settings.RequiredTags)
+	})
+
+	t.Run("Save replaces the settings of a folder", func(t *testing.T) {
+		save(foldersettings.SaveFolderSettingsCommand{FolderUID: "replaced", DefaultRefresh: "5m"})
+		save(foldersettings.SaveFolderSettingsCommand{FolderUID: "replaced", Timezone: "browser"})
+
+		settings, err := s.Get(ctx, &foldersettings.GetFolderSettingsQuery{OrgID: 1, FolderUID: "replaced"})
+		require.NoError(t, err)
+		require.Empty(t, settings.DefaultRefresh)
+		require.Equal(t, "browser", settings.Timezone)
+
+		require.NoError(t, s.Delete(ctx, &foldersettings.DeleteFolderSettingsCommand{OrgID: 1, FolderUID: "replaced"}))
+		settings, err = s.Get(ctx, &foldersettings.GetFolderSettingsQuery{OrgID: 1, FolderUID: "replaced"})
+		require.NoError(t, err)
+		require.Empty(t, settings.Timezone)
+	})
+
+	t.Run("Save keeps the provisioned checksum of the replaced settings", func(t *testing.T) {
+		save(foldersettings.SaveFolderSettingsCommand{FolderUID: "provisioned", DefaultRefresh: "5m", ProvisionedChecksum: "abc"})
+		save(foldersettings.SaveFolderSettingsCommand{FolderUID: "provisioned", DefaultRefresh: "1m"})
+
+		settings, err := s.Get(ctx, &foldersettings.GetFolderSettingsQuery{OrgID: 1, FolderUID: "provisioned"})
+		require.NoError(t, err)
+		require.Equal(t, "1m", settings.DefaultRefresh)
+		require.Equal(t, "abc", settings.ProvisionedChecksum)
+	})
+
+	t.Run("GetResolved resolves the settings through the parent folders", func(t *testing.T) {
+		folderService.ExpectedFolders = []*folder.Folder{{UID: "root"}, {UID: "team"}}
+		t.Cleanup(func() { folderService.ExpectedFolders = nil })
+
+		resolved, err := s.GetResolved(ctx, &foldersettings.GetFolderSettingsQuery{OrgID: 1, FolderUID: "service"})
+		require.NoError(t, err)
+		require.Equal(t, "prometheus", resolved.DefaultDatasourceUID)
+		require.Equal(t, "30s", resolved.DefaultRefresh)
+		require.Equal(t, "utc", resolved.Timezone)
+		require.Equal(t, []string{"team", "ops"}, resolved.RequiredTags)
+		require.Equal(t, []accesscontrol.SetResourcePermissionCommand{{BuiltinRole: "Viewer", Permission: "View"}}, resolved.PermissionTemplate)
+		require.Equal(t, map[string]string{
+			"defaultDatasourceUid": "root",
+			"defaultRefresh":       "service",
+			"timezone":             "team",
+			"permissionTemplate":   "root",
+		}, resolved.Sources)
+	})
+
+	t.Run("GetResolved returns the settings of the folder when nested folders are disabled", func(t *testing.T) {
+		resolved, err := s.GetResolved(ctx, &foldersettings.GetFolderSettingsQuery{OrgID: 1, FolderUID: "team"})
+		require.NoError(t, err)
+		require.Empty(t, resolved.DefaultDatasourceUID)
+		require.Equal(t, "utc", resolved.Timezone)
+		require.Empty(t, resolved.PermissionTemplate)
+	})
+
+	t.Run("Create hooks use the settings of the dashboard folder", func(t *testing.T) {
+		folderStore.On("GetFolderByID", mock.Anything, int64(1), int64(10)).Return(&folder.Folder{ID: 10, UID: "root"}, nil)
+
+		dash := dashboards.NewDashboardFromJson(simplejson.NewFromAny(map[string]any{"title": "Disk usage"}))
+		dash.OrgID = 1
+		dash.FolderID = 10
+		require.NoError(t, s.BeforeCreate(ctx, dash))
+		require.Equal(t, "1m", dash.Data.Get("refresh").MustString())
+		require.Equal(t, []string{"ops"}, dash.Data.Get("tags").MustStringArray())
+
+		permissions, err := s.CreatePermissions(ctx, dash)
+		require.NoError(t, err)
+		require.Equal(t, []accesscontrol.SetResourcePermissionCommand{{BuiltinRole: "Viewer", Permission: "View"}}, permissions)
+	})
+
+	t.Run("ValidateSave requires the tags of the folder on update", func(t *testing.T) {
+		folderStore.On("GetFolderByID", mock.Anything, int64(1), int64(11)).Return(&folder.Folder{ID: 11, UID: "team"}, nil)
+		dashboardService.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool {
+			return q.UID == "existing"
+		})).Return(&dashboards.Dashboard{ID: 2, UID: "existing"}, nil)
+		dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardNotFound)
+
+		newDashboard := func(tags ...any) *dashboards.Dashboard {
+			dash := dashboards.NewDashboardFromJson(simplejson.NewFromAny(map[string]any{"title": "Disk usage", "tags": tags}))
+			dash.OrgID = 1
+			dash.FolderID = 11
+			return dash
+		}
+
+		dash := newDashboard("ops")
+		dash.ID = 1
+		var dashboardErr dashboards.DashboardErr
+		require.ErrorAs(t, s.ValidateSave(ctx, dash), &dashboardErr)
+		require.Equal(t, 400, dashboardErr.StatusCode)
+		require.Contains(t, dashboardErr.Reason, "team")
+
+		dash = newDashboard("ops", "team")
+		dash.ID = 1
+		require.NoError(t, s.ValidateSave(ctx, dash))
+
+		// an existing dashboard overwritten by UID
+		dash = newDashboard()
+		dash.UID = "existing"
+		require.Error(t, s.ValidateSave(ctx, dash))
+
+		// the tags of a new dashboard are added by BeforeCreate
+		dash = newDashboard()
+		dash.UID = "new"
+		require.NoError(t, s.ValidateSave(ctx, dash))
+	})
+
+	t.Run("Create hooks do nothing for dashboards in the General folder", func(t *testing.T) {
+		dash := dashboards.NewDashboardFromJson(simplejson.NewFromAny(map[string]any{"title": "Home"}))
+		dash.OrgID = 1
+		require.NoError(t, s.BeforeCreate(ctx, dash))
+		require.Empty(t, dash.Data.Get("refresh").MustString())
+
+		permissions, err := s.CreatePermissions(ctx, dash)
+		require.NoError(t, err)
+		require.Empty(t, permissions)
+	})
+}
+
+func TestApplyDefaults(t *testing.T) {
+	data := simplejson.NewFromAny(map[string]any{
+		"refresh": "5s",
+		"tags":    []any{"ops"},
+		"panels": []any{
+			map[string]any{"type": "timeseries", "targets": []any{map[string]any{"refId": "A"}}},
+			map[string]any{"type": "stat", "datasource": map[string]any{"uid": "loki"}, "targets": []any{map[string]any{"refId": "A"}}},
+			map[string]any{"type": "text"},
+			map[string]any{"type": "row", "panels": []any{
+				map[string]any{"type": "table", "targets": []any{map[string]any{"refId": "A"}}},
+			}},
+		},
+		"templating": map[string]any{"list": []any{
+			map[string]any{"type": "query", "name": "host"},
+			map[string]any{"type": "custom", "name": "env"},
+		}},
+	})
+
+	applyDefaults(data, &foldersettings.ResolvedFolderSettings{
+		DefaultDatasourceUID: "prometheus",
+		DefaultRefresh:       "1m",
+		Timezone:             "utc",
+		RequiredTags:         []string{"ops", "team"},
+	})
+
+	require.Equal(t, "5s", data.Get("refresh").MustString())
+	require.Equal(t, "utc", data.Get("timezone").MustString())
+	require.Equal(t, []string{"ops", "team"}, data.Get("tags").MustStringArray())
+
+	panels := data.Get("panels")
+	require.Equal(t, "prometheus", panels.GetIndex(0).GetPath("datasource", "uid").MustString())
+	require.Equal(t, "loki", panels.GetIndex(1).GetPath("datasource", "uid").MustString())
+	require.Nil(t, panels.GetIndex(2).Get("datasource").Interface())
+	require.Equal(t, "prometheus", panels.GetIndex(3).Get("panels").GetIndex(0).GetPath("datasource", "uid").MustString())
+
+	variables := data.GetPath("templating", "list")
+	require.Equal(t, "prometheus", variables.GetIndex(0).GetPath("datasource", "uid").MustString())
+	require.Nil(t, variables.GetIndex(1).Get("datasource").Interface())
+}
@@ -0,0 +1,67 @@
+package foldersettingsimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/foldersettings"
+)
+
+type store interface {
+	// Get returns the settings of the folders with settings by folder UID.
+	Get(ctx context.Context, orgID int64, folderUIDs ...string) (map[string]*foldersettings.FolderSettings, error)
+	// Save creates or replaces the settings of a folder. The settings without provisioned checksum keep the checksum
+	// of the replaced settings.
+	Save(ctx context.Context, settings *foldersettings.FolderSettings) error
+	Delete(ctx context.Context, orgID int64, folderUID string) error
+}
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (ss *sqlStore) Get(ctx context.Context, orgID int64, folderUIDs ...string) (map[string]*foldersettings.FolderSettings, error) {
+	settings := make(map[string]*foldersettings.FolderSettings, len(folderUIDs))
+	if len(folderUIDs) == 0 {
+		return settings, nil
+	}
+
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		found := make([]*foldersettings.FolderSettings, 0)
+		if err := sess.Where("org_id = ?", orgID).In("folder_uid", folderUIDs).Find(&found); err != nil {
+			return err
+		}
+		for _, s := range found {
+			settings[s.FolderUID] = s
+		}
+		return nil
+	})
+	return settings, err
+}
+
+func (ss *sqlStore) Save(ctx context.Context, settings *foldersettings.FolderSettings) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := foldersettings.FolderSettings{}
+		has, err := sess.Where("org_id = ? AND folder_uid = ?", settings.OrgID, settings.FolderUID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !has {
+			_, err = sess.Insert(settings)
+			return err
+		}
+		settings.ID = existing.ID
+		if settings.ProvisionedChecksum == "" {
+			settings.ProvisionedChecksum = existing.ProvisionedChecksum
+		}
+		_, err = sess.ID(settings.ID).AllCols().Update(settings)
+		return err
+	})
+}
+
+func (ss *sqlStore) Delete(ctx context.Context, orgID int64, folderUID string) error {
+	return ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ? AND folder_uid = ?", orgID, folderUID).Delete(&foldersettings.FolderSettings{})
+		return err
+	})
+}
@@ -0,0 +1,38 @@
+package foldersettingstest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/foldersettings"
+)
+
+type FakeService struct {
+	ExpectedSettings         *foldersettings.FolderSettings
+	ExpectedResolvedSettings *foldersettings.ResolvedFolderSettings
+	ExpectedError            error
+
+	SavedCommands []*foldersettings.SaveFolderSettingsCommand
+}
+
+var _ foldersettings.Service = &FakeService{}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) Get(ctx context.Context, query *foldersettings.GetFolderSettingsQuery) (*foldersettings.FolderSettings, error) {
+	return f.ExpectedSettings, f.ExpectedError
+}
+
+func (f *FakeService) GetResolved(ctx context.Context, query *foldersettings.GetFolderSettingsQuery) (*foldersettings.ResolvedFolderSettings, error) {
+	return f.ExpectedResolvedSettings, f.ExpectedError
+}
+
+func (f *FakeService) Save(ctx context.Context, cmd *foldersettings.SaveFolderSettingsCommand) (*foldersettings.FolderSettings, error) {
+	f.SavedCommands = append(f.SavedCommands, cmd)
+	return f.ExpectedSettings, f.ExpectedError
+}
+
+func (f *FakeService) Delete(ctx context.Context, cmd *foldersettings.DeleteFolderSettingsCommand) error {
+	return f.ExpectedError
+}
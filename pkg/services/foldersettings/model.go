@@ -0,0 +1,147 @@
+package foldersettings
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	ErrInvalidRefresh            = errors.New("invalid default refresh interval")
+	ErrInvalidTimezone           = errors.New("invalid time zone")
+	ErrInvalidPermissionTemplate = errors.New("invalid permission template")
+)
+
+// FolderSettings are the settings of a folder inherited by its subfolders and used as defaults of the dashboards
+// created in the folder and its subfolders.
+type FolderSettings struct {
+	ID        int64  `xorm:"pk autoincr 'id'" json:"-"`
+	OrgID     int64  `xorm:"org_id" json:"-"`
+	FolderUID string `xorm:"folder_uid" json:"folderUid"`
+
+	// DefaultDatasourceUID is the datasource of the panels and variables without datasource.
+	DefaultDatasourceUID string `xorm:"default_datasource_uid" json:"defaultDatasourceUid"`
+	// DefaultRefresh is the refresh interval of the dashboards without refresh interval.
+	DefaultRefresh string `xorm:"default_refresh" json:"defaultRefresh"`
+	// Timezone is the time zone of the dashboards without time zone.
+	Timezone string `xorm:"timezone" json:"timezone"`
+	// RequiredTags are added to the tags of the dashboards.
+	RequiredTags []string `xorm:"required_tags" json:"requiredTags"`
+	// PermissionTemplate are the permissions set on the dashboards in addition to the permissions of the folder.
+	PermissionTemplate []accesscontrol.SetResourcePermissionCommand `xorm:"permission_template" json:"permissionTemplate"`
+	// ProvisionedChecksum is the checksum of the settings of the provisioning config last saved on the folder.
+	ProvisionedChecksum string `xorm:"provisioned_checksum" json:"-"`
+
+	Updated time.Time `json:"updated"`
+}
+
+func (s FolderSettings) TableName() string { return "folder_settings" }
+
+// ResolvedFolderSettings are the settings of a folder after inheritance. The required tags are the tags required
+// by the folder and all its parents, the other settings are set by the folder or its nearest parent setting them.
+type ResolvedFolderSettings struct {
+	FolderUID            string                                       `json:"folderUid"`
+	DefaultDatasourceUID string                                       `json:"defaultDatasourceUid"`
+	DefaultRefresh       string                                       `json:"defaultRefresh"`
+	Timezone             string                                       `json:"timezone"`
+	RequiredTags         []string                                     `json:"requiredTags"`
+	PermissionTemplate   []accesscontrol.SetResourcePermissionCommand `json:"permissionTemplate"`
+	// Sources are the UIDs of the folders the settings are inherited from, by setting name.
+	Sources map[string]string `json:"sources"`
+}
+
+//
+// COMMANDS
+//
+
+type SaveFolderSettingsCommand struct {
+	OrgID     int64  `json:"-"`
+	FolderUID string `json:"-"`
+
+	DefaultDatasourceUID string                                       `json:"defaultDatasourceUid"`
+	DefaultRefresh       string                                       `json:"defaultRefresh"`
+	Timezone             string                                       `json:"timezone"`
+	RequiredTags         []string                                     `json:"requiredTags"`
+	PermissionTemplate   []accesscontrol.SetResourcePermissionCommand `json:"permissionTemplate"`
+
+	// ProvisionedChecksum is set when the settings are saved by provisioning, the settings saved without it
+	// keep the checksum of the last provisioning.
+	ProvisionedChecksum string `json:"-"`
+}
+
+func (cmd *SaveFolderSettingsCommand) Validate() error {
+	if cmd.DefaultRefresh != "" {
+		refresh, err := gtime.ParseDuration(cmd.DefaultRefresh)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidRefresh, cmd.DefaultRefresh)
+		}
+		if minRefresh, err := gtime.ParseDuration(setting.MinRefreshInterval); err == nil && refresh < minRefresh {
+			return fmt.Errorf("%w: %s is less than the minimum refresh interval %s", ErrInvalidRefresh, cmd.DefaultRefresh, setting.MinRefreshInterval)
+		}
+	}
+
+	switch tz := cmd.Timezone; tz {
+	case "", "browser", "utc":
+	default:
+		if _, err := time.LoadLocation(tz); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidTimezone, tz)
+		}
+	}
+
+	for _, p := range cmd.PermissionTemplate {
+		targets := 0
+		if p.UserID != 0 {
+			targets++
+		}
+		if p.TeamID != 0 {
+			targets++
+		}
+		if p.BuiltinRole != "" {
+			if !org.RoleType(p.BuiltinRole).IsValid() {
+				return fmt.Errorf("%w: invalid role %s", ErrInvalidPermissionTemplate, p.BuiltinRole)
+			}
+			targets++
+		}
+		if targets != 1 {
+			return fmt.Errorf("%w: a permission applies to one user, team or role", ErrInvalidPermissionTemplate)
+		}
+
+		switch p.Permission {
+		case dashboards.PERMISSION_VIEW.String(), dashboards.PERMISSION_EDIT.String(), dashboards.PERMISSION_ADMIN.String():
+		default:
+			return fmt.Errorf("%w: invalid permission %s", ErrInvalidPermissionTemplate, p.Permission)
+		}
+	}
+
+	tags := make([]string, 0, len(cmd.RequiredTags))
+	for _, tag := range cmd.RequiredTags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	cmd.RequiredTags = tags
+
+	return nil
+}
+
+type DeleteFolderSettingsCommand struct {
+	OrgID     int64
+	FolderUID string
+}
+
+//
+// QUERIES
+//
+
+type GetFolderSettingsQuery struct {
+	OrgID     int64
+	FolderUID string
+}
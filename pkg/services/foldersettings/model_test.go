@@ -0,0 +1,61 @@
+package foldersettings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestValidateSaveFolderSettingsCommand(t *testing.T) {
+	oldRefreshInterval := setting.MinRefreshInterval
+	setting.MinRefreshInterval = "5s"
+	t.Cleanup(func() { setting.MinRefreshInterval = oldRefreshInterval })
+
+	tests := []struct {
+		name string
+		cmd  SaveFolderSettingsCommand
+		err  error
+	}{
+		{name: "empty settings", cmd: SaveFolderSettingsCommand{}},
+		{name: "valid settings", cmd: SaveFolderSettingsCommand{
+			DefaultRefresh:     "1m",
+			Timezone:           "Europe/Stockholm",
+			PermissionTemplate: []accesscontrol.SetResourcePermissionCommand{{TeamID: 1, Permission: "Edit"}},
+		}},
+		{name: "invalid refresh", cmd: SaveFolderSettingsCommand{DefaultRefresh: "often"}, err: ErrInvalidRefresh},
+		{name: "refresh below the minimum", cmd: SaveFolderSettingsCommand{DefaultRefresh: "1ms"}, err: ErrInvalidRefresh},
+		{name: "invalid timezone", cmd: SaveFolderSettingsCommand{Timezone: "Mars/Olympus_Mons"}, err: ErrInvalidTimezone},
+		{name: "permission without target", cmd: SaveFolderSettingsCommand{
+			PermissionTemplate: []accesscontrol.SetResourcePermissionCommand{{Permission: "View"}},
+		}, err: ErrInvalidPermissionTemplate},
+		{name: "permission with several targets", cmd: SaveFolderSettingsCommand{
+			PermissionTemplate: []accesscontrol.SetResourcePermissionCommand{{UserID: 1, BuiltinRole: "Viewer", Permission: "View"}},
+		}, err: ErrInvalidPermissionTemplate},
+		{name: "invalid role", cmd: SaveFolderSettingsCommand{
+			PermissionTemplate: []accesscontrol.SetResourcePermissionCommand{{BuiltinRole: "Guest", Permission: "View"}},
+		}, err: ErrInvalidPermissionTemplate},
+		{name: "invalid permission", cmd: SaveFolderSettingsCommand{
+			PermissionTemplate: []accesscontrol.SetResourcePermissionCommand{{UserID: 1, Permission: "Owner"}},
+		}, err: ErrInvalidPermissionTemplate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cmd.Validate()
+			if tt.err == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.err)
+		})
+	}
+
+	t.Run("required tags are trimmed", func(t *testing.T) {
+		cmd := SaveFolderSettingsCommand{RequiredTags: []string{" ops ", "", "team"}}
+		require.NoError(t, cmd.Validate())
+		require.Equal(t, []string{"ops", "team"}, cmd.RequiredTags)
+	})
+}
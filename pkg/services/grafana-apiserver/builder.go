@@ -0,0 +1,56 @@
+package grafanaapiserver
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/kube-openapi/pkg/common"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+// APIGroupBuilder is implemented by the services that serve an API group through the apiserver
+type APIGroupBuilder interface {
+	// Get the main group name
+	GetGroupVersion() schema.GroupVersion
+
+	// Add the kinds to the server scheme
+	InstallSchema(scheme *runtime.Scheme) error
+
+	// Build the group+version behavior
+	GetAPIGroupInfo(scheme *runtime.Scheme, codecs serializer.CodecFactory) (*genericapiserver.APIGroupInfo, error)
+
+	// Get OpenAPI definitions
+	GetOpenAPIDefinitions() common.GetOpenAPIDefinitions
+}
+
+// APIRegistrar is used by the services to register their API groups before the apiserver starts
+type APIRegistrar interface {
+	RegisterAPI(builder APIGroupBuilder)
+}
+
+// addOpenAPIDefinitions adds the definitions of the builders to the server config. The v3 definitions
+// are computed when the config is created, so they are computed again.
+func addOpenAPIDefinitions(config *genericapiserver.RecommendedConfig, builders []APIGroupBuilder) {
+	config.OpenAPIConfig.GetDefinitions = getOpenAPIDefinitions(config.OpenAPIConfig.GetDefinitions, builders)
+
+	v3 := config.OpenAPIV3Config
+	v3.GetDefinitions = getOpenAPIDefinitions(v3.GetDefinitions, builders)
+	v3.Definitions = v3.GetDefinitions(func(name string) spec.Ref {
+		defName, _ := v3.GetDefinitionName(name)
+		return spec.MustCreateRef("#/components/schemas/" + common.EscapeJsonPointer(defName))
+	})
+}
+
+// getOpenAPIDefinitions merges the definitions of the builders with the default ones
+func getOpenAPIDefinitions(defaults common.GetOpenAPIDefinitions, builders []APIGroupBuilder) common.GetOpenAPIDefinitions {
+	return func(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
+		defs := defaults(ref)
+		for _, b := range builders {
+			for k, v := range b.GetOpenAPIDefinitions()(ref) {
+				defs[k] = v
+			}
+		}
+		return defs
+	}
+}
@@ -0,0 +1,99 @@
+package entitystorage
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// Verb is an operation on a resource, the list and watch results are checked with VerbGet
+type Verb string
+
+const (
+	VerbGet    Verb = "get"
+	VerbCreate Verb = "create"
+	VerbUpdate Verb = "update"
+	VerbDelete Verb = "delete"
+)
+
+// ResourceAccess checks the permissions of the users on each resource. The permissions of the folders are
+// inherited, so the resources are checked with the scopes of their folder and of its parents.
+type ResourceAccess struct {
+	AccessControl accesscontrol.Service
+	Folders       folder.Service
+
+	// Evaluator returns the permission required for a verb on a resource, given the scopes of its folder
+	Evaluator func(verb Verb, name string, folderScopes []string) accesscontrol.Evaluator
+}
+
+// loadPermissions sets the permissions of the user in its org
+func (a *ResourceAccess) loadPermissions(ctx context.Context, u *user.SignedInUser) error {
+	if u.Permissions == nil {
+		u.Permissions = make(map[int64]map[string][]string)
+	}
+	if _, ok := u.Permissions[u.OrgID]; ok {
+		return nil
+	}
+	permissions, err := a.AccessControl.GetUserPermissions(ctx, u, accesscontrol.Options{ReloadCache: false})
+	if err != nil {
+		return err
+	}
+	u.Permissions[u.OrgID] = accesscontrol.GroupScopesByAction(permissions)
+	return nil
+}
+
+// folderScopes returns the scopes of a folder and of its parents, the resources without folder are in the general folder
+func (a *ResourceAccess) folderScopes(ctx context.Context, orgID int64, folderUID string) ([]string, error) {
+	if folderUID == "" {
+		folderUID = accesscontrol.GeneralFolderUID
+	}
+	scopes, err := dashboards.GetInheritedScopes(ctx, orgID, folderUID, a.Folders)
+	if err != nil {
+		return nil, err
+	}
+	return append(scopes, dashboards.ScopeFoldersProvider.GetResourceScopeUID(folderUID)), nil
+}
+
+// checker returns a function checking the permission of the user for a verb on the resources, the scopes of the
+// folders are resolved once per checker
+func (a *ResourceAccess) checker(ctx context.Context, u *user.SignedInUser, verb Verb) func(name string, folderUID string) (bool, error) {
+	scopesByFolder := map[string][]string{}
+	return func(name string, folderUID string) (bool, error) {
+		scopes, ok := scopesByFolder[folderUID]
+		if !ok {
+			var err error
+			if scopes, err = a.folderScopes(ctx, u.OrgID, folderUID); err != nil {
+				return false, err
+			}
+			scopesByFolder[folderUID] = scopes
+		}
+		return a.Evaluator(verb, name, scopes).Evaluate(u.Permissions[u.OrgID]), nil
+	}
+}
+
+// checker returns a function checking the permission of the user for a verb on the resources. Without
+// ResourceAccess, the permissions of the users can't be checked so nothing is allowed.
+func (s *Storage) checker(ctx context.Context, u *user.SignedInUser, verb Verb) func(name string, folderUID string) (bool, error) {
+	if s.Access == nil {
+		return func(string, string) (bool, error) { return false, nil }
+	}
+	return s.Access.checker(ctx, u, verb)
+}
+
+// authorize returns a forbidden error unless the user can do the verb on the resource in the folder
+func (s *Storage) authorize(ctx context.Context, u *user.SignedInUser, verb Verb, name string, folderUID string) error {
+	ok, err := s.checker(ctx, u, verb)(name, folderUID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return apierrors.NewForbidden(s.DefaultQualifiedResource, name, fmt.Errorf("%s is not allowed", verb))
+	}
+	return nil
+}
@@ -0,0 +1,517 @@
+package entitystorage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	genericregistry "k8s.io/apiserver/pkg/registry/generic/registry"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/storage/names"
+
+	"github.com/grafana/grafana/pkg/infra/appcontext"
+	"github.com/grafana/grafana/pkg/infra/grn"
+	grafanaapiserver "github.com/grafana/grafana/pkg/services/grafana-apiserver"
+	"github.com/grafana/grafana/pkg/services/store/entity"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// The entity fields that are not in the body are set as annotations
+const (
+	AnnotationFolder           = "grafana.app/folder"
+	AnnotationCreatedBy        = "grafana.app/createdBy"
+	AnnotationUpdatedBy        = "grafana.app/updatedBy"
+	AnnotationUpdatedTimestamp = "grafana.app/updatedTimestamp"
+)
+
+// The size of the pages read from the entity store, the lists with a higher limit return a continue token
+const maxListLimit = 1000
+
+var (
+	_ rest.Storage              = (*Storage)(nil)
+	_ rest.Scoper               = (*Storage)(nil)
+	_ rest.SingularNameProvider = (*Storage)(nil)
+	_ rest.Getter               = (*Storage)(nil)
+	_ rest.Lister               = (*Storage)(nil)
+	_ rest.Creater              = (*Storage)(nil)
+	_ rest.Updater              = (*Storage)(nil)
+	_ rest.GracefulDeleter      = (*Storage)(nil)
+	_ rest.Watcher              = (*Storage)(nil)
+)
+
+// Storage serves a resource from the entities of a kind in the entity store. The resource name is the entity
+// uid, the namespace is the org of the entity (see grafanaapiserver.NamespaceForOrg), and the resource version
+// is the entity resource version. The labels are read from the entity summary, and can not be changed directly.
+type Storage struct {
+	Store entity.EntityStoreServer
+
+	// The entity kind of the resource
+	Kind string
+
+	NewFunc     func() runtime.Object
+	NewListFunc func() runtime.Object
+
+	DefaultQualifiedResource  schema.GroupResource
+	SingularQualifiedResource schema.GroupResource
+
+	// ToObject returns the resource of an entity body, the storage sets the resource metadata
+	ToObject func(body []byte) (runtime.Object, error)
+
+	// ToBody returns the entity body of a resource
+	ToBody func(obj runtime.Object) ([]byte, error)
+
+	TableConvertor rest.TableConvertor
+
+	// Access checks the permissions of the users on each resource, all the requests are denied without it
+	Access *ResourceAccess
+}
+
+func (s *Storage) New() runtime.Object {
+	return s.NewFunc()
+}
+
+func (s *Storage) Destroy() {}
+
+func (s *Storage) NamespaceScoped() bool {
+	return true
+}
+
+func (s *Storage) GetSingularName() string {
+	return s.SingularQualifiedResource.Resource
+}
+
+func (s *Storage) NewList() runtime.Object {
+	return s.NewListFunc()
+}
+
+func (s *Storage) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	return s.TableConvertor.ConvertToTable(ctx, object, tableOptions)
+}
+
+func (s *Storage) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	ctx, u, err := s.userContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	obj, existing, err := s.read(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.authorize(ctx, u, VerbGet, name, existing.Folder); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (s *Storage) List(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	ctx, u, err := s.userContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items, rv, next, err := s.list(ctx, u, options)
+	if err != nil {
+		return nil, err
+	}
+
+	list := s.NewListFunc()
+	if err = meta.SetList(list, items); err != nil {
+		return nil, err
+	}
+	listAccessor, err := meta.ListAccessor(list)
+	if err != nil {
+		return nil, err
+	}
+	listAccessor.SetResourceVersion(strconv.FormatInt(rv, 10))
+	listAccessor.SetContinue(next)
+	return list, nil
+}
+
+func (s *Storage) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	ctx, u, err := s.userContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	if accessor.GetName() == "" && accessor.GetGenerateName() != "" {
+		accessor.SetName(names.SimpleNameGenerator.GenerateName(accessor.GetGenerateName()))
+	}
+	name := accessor.GetName()
+	if name == "" {
+		return nil, apierrors.NewBadRequest("the name of the resource is required")
+	}
+	if err = s.authorize(ctx, u, VerbCreate, name, accessor.GetAnnotations()[AnnotationFolder]); err != nil {
+		return nil, err
+	}
+	if createValidation != nil {
+		if err = createValidation(ctx, obj); err != nil {
+			return nil, err
+		}
+	}
+
+	existing, err := s.Store.Read(ctx, &entity.ReadEntityRequest{GRN: s.grn(name)})
+	if err != nil {
+		return nil, err
+	}
+	if existing.GRN != nil {
+		return nil, apierrors.NewAlreadyExists(s.DefaultQualifiedResource, name)
+	}
+	if len(options.DryRun) > 0 {
+		return obj, nil
+	}
+
+	if err = s.write(ctx, name, obj, ""); err != nil {
+		return nil, err
+	}
+	created, _, err := s.read(ctx, name)
+	return created, err
+}
+
+func (s *Storage) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	ctx, u, err := s.userContext(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	old, existing, err := s.read(ctx, name)
+	if err != nil && !(apierrors.IsNotFound(err) && forceAllowCreate) {
+		return nil, false, err
+	}
+	if existing != nil {
+		if err = s.authorize(ctx, u, VerbUpdate, name, existing.Folder); err != nil {
+			return nil, false, err
+		}
+	}
+
+	obj, err := objInfo.UpdatedObject(ctx, old)
+	if err != nil {
+		return nil, false, err
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, false, err
+	}
+	// the resources are created in their new folder
+	folderUID := accessor.GetAnnotations()[AnnotationFolder]
+	if existing == nil || folderUID != existing.Folder {
+		if err = s.authorize(ctx, u, VerbCreate, name, folderUID); err != nil {
+			return nil, false, err
+		}
+	}
+
+	previousVersion := ""
+	if existing == nil {
+		if createValidation != nil {
+			if err = createValidation(ctx, obj); err != nil {
+				return nil, false, err
+			}
+		}
+	} else {
+		rv := accessor.GetResourceVersion()
+		if rv != "" && rv != strconv.FormatInt(existing.ResourceVersion, 10) {
+			return nil, false, s.conflict(name)
+		}
+		if updateValidation != nil {
+			if err = updateValidation(ctx, obj, old); err != nil {
+				return nil, false, err
+			}
+		}
+		previousVersion = existing.Version
+	}
+	if len(options.DryRun) > 0 {
+		return obj, existing == nil, nil
+	}
+
+	if err = s.write(ctx, name, obj, previousVersion); err != nil {
+		return nil, false, err
+	}
+	updated, _, err := s.read(ctx, name)
+	return updated, existing == nil, err
+}
+
+func (s *Storage) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	ctx, u, err := s.userContext(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	old, existing, err := s.read(ctx, name)
+	if err != nil {
+		return nil, false, err
+	}
+	if err = s.authorize(ctx, u, VerbDelete, name, existing.Folder); err != nil {
+		return nil, false, err
+	}
+	if p := options.Preconditions; p != nil {
+		if p.UID != nil && *p.UID != s.uid(existing.GRN) {
+			return nil, false, apierrors.NewConflict(s.DefaultQualifiedResource, name,
+				fmt.Errorf("precondition failed: UID in precondition: %v, UID in object meta: %v", *p.UID, s.uid(existing.GRN)))
+		}
+		if p.ResourceVersion != nil && *p.ResourceVersion != strconv.FormatInt(existing.ResourceVersion, 10) {
+			return nil, false, s.conflict(name)
+		}
+	}
+	if deleteValidation != nil {
+		if err = deleteValidation(ctx, old); err != nil {
+			return nil, false, err
+		}
+	}
+	if len(options.DryRun) > 0 {
+		return old, true, nil
+	}
+
+	_, err = s.Store.Delete(ctx, &entity.DeleteEntityRequest{
+		GRN:             s.grn(name),
+		PreviousVersion: existing.Version,
+	})
+	if errors.Is(err, entity.ErrOptimisticLockFailed) {
+		return nil, false, s.conflict(name)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return old, true, nil
+}
+
+// userContext adds the grafana user of the request to the context used with the entity store, with the permissions
+// of the user when the resources are checked with access control
+func (s *Storage) userContext(ctx context.Context) (context.Context, *user.SignedInUser, error) {
+	u, err := grafanaapiserver.SignedInUserFrom(ctx, request.NamespaceValue(ctx))
+	if err != nil {
+		return nil, nil, apierrors.NewForbidden(s.DefaultQualifiedResource, "", err)
+	}
+	if s.Access != nil {
+		if err = s.Access.loadPermissions(ctx, u); err != nil {
+			return nil, nil, err
+		}
+	}
+	return appcontext.WithUser(ctx, u), u, nil
+}
+
+func (s *Storage) grn(name string) *grn.GRN {
+	return &grn.GRN{
+		ResourceKind:       s.Kind,
+		ResourceIdentifier: name,
+	}
+}
+
+func (s *Storage) uid(g *grn.GRN) types.UID {
+	return types.UID(g.ToGRNString())
+}
+
+func (s *Storage) conflict(name string) error {
+	return apierrors.NewConflict(s.DefaultQualifiedResource, name, errors.New(genericregistry.OptimisticLockErrorMsg))
+}
+
+// read returns the resource with its entity, or a not found error
+func (s *Storage) read(ctx context.Context, name string) (runtime.Object, *entity.Entity, error) {
+	e, err := s.Store.Read(ctx, &entity.ReadEntityRequest{
+		GRN:         s.grn(name),
+		WithBody:    true,
+		WithSummary: true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if e.GRN == nil {
+		return nil, nil, apierrors.NewNotFound(s.DefaultQualifiedResource, name)
+	}
+
+	summary := &entity.EntitySummary{}
+	if len(e.SummaryJson) > 0 {
+		if err = json.Unmarshal(e.SummaryJson, summary); err != nil {
+			return nil, nil, err
+		}
+	}
+	obj, err := s.newObject(e, summary.Labels)
+	return obj, e, err
+}
+
+// list returns the resources matching the options that the user can read, the resource version of the list and
+// the continue token of the next page. Without limit, all the pages are read.
+func (s *Storage) list(ctx context.Context, u *user.SignedInUser, options *metainternalversion.ListOptions) ([]runtime.Object, int64, string, error) {
+	limit := options.Limit
+	if limit <= 0 || limit > maxListLimit {
+		limit = maxListLimit
+	}
+	canRead := s.checker(ctx, u, VerbGet)
+
+	items := make([]runtime.Object, 0)
+	rv := int64(0)
+	next := options.Continue
+	for {
+		rsp, err := s.Store.Search(ctx, &entity.EntitySearchRequest{
+			Kind:          []string{s.Kind},
+			Labels:        requiredLabels(options.LabelSelector),
+			WithBody:      true,
+			WithLabels:    true,
+			Limit:         limit,
+			NextPageToken: next,
+		})
+		if err != nil {
+			return nil, 0, "", err
+		}
+		if rv == 0 {
+			rv = rsp.ResourceVersion // the changes after the first page are sent by the watches
+		}
+
+		for _, r := range rsp.Results {
+			ok, err := canRead(r.GRN.ResourceIdentifier, r.Folder)
+			if err != nil {
+				return nil, 0, "", err
+			}
+			if !ok {
+				continue
+			}
+			obj, err := s.newObject(&entity.Entity{
+				GRN:             r.GRN,
+				Version:         r.Version,
+				Folder:          r.Folder,
+				Body:            r.Body,
+				CreatedAt:       r.CreatedAt,
+				UpdatedAt:       r.UpdatedAt,
+				UpdatedBy:       r.UpdatedBy,
+				ResourceVersion: r.ResourceVersion,
+			}, r.Labels)
+			if err != nil {
+				return nil, 0, "", err
+			}
+			if matches(obj, options) {
+				items = append(items, obj)
+			}
+		}
+
+		next = rsp.NextPageToken
+		if next == "" || options.Limit > 0 {
+			return items, rv, next, nil
+		}
+	}
+}
+
+func (s *Storage) write(ctx context.Context, name string, obj runtime.Object, previousVersion string) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	body, err := s.ToBody(obj)
+	if err != nil {
+		return apierrors.NewBadRequest(err.Error())
+	}
+
+	_, err = s.Store.Write(ctx, &entity.WriteEntityRequest{
+		GRN:             s.grn(name),
+		Folder:          accessor.GetAnnotations()[AnnotationFolder],
+		Body:            body,
+		PreviousVersion: previousVersion,
+	})
+	if errors.Is(err, entity.ErrOptimisticLockFailed) {
+		return s.conflict(name)
+	}
+	return err
+}
+
+// newObject returns the resource of an entity, the body is empty for the deleted entities
+func (s *Storage) newObject(e *entity.Entity, lbls map[string]string) (runtime.Object, error) {
+	obj := s.NewFunc()
+	if len(e.Body) > 0 {
+		var err error
+		if obj, err = s.ToObject(e.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	accessor.SetName(e.GRN.ResourceIdentifier)
+	accessor.SetNamespace(grafanaapiserver.NamespaceForOrg(e.GRN.TenantID))
+	accessor.SetUID(s.uid(e.GRN))
+	accessor.SetResourceVersion(strconv.FormatInt(e.ResourceVersion, 10))
+	if generation, err := strconv.ParseInt(e.Version, 10, 64); err == nil {
+		accessor.SetGeneration(generation)
+	}
+	if e.CreatedAt > 0 {
+		accessor.SetCreationTimestamp(metav1.NewTime(time.UnixMilli(e.CreatedAt)))
+	}
+	if len(lbls) > 0 {
+		accessor.SetLabels(lbls)
+	}
+
+	annotations := map[string]string{}
+	if e.Folder != "" {
+		annotations[AnnotationFolder] = e.Folder
+	}
+	if e.CreatedBy != "" {
+		annotations[AnnotationCreatedBy] = e.CreatedBy
+	}
+	if e.UpdatedBy != "" {
+		annotations[AnnotationUpdatedBy] = e.UpdatedBy
+	}
+	if e.UpdatedAt > 0 {
+		annotations[AnnotationUpdatedTimestamp] = time.UnixMilli(e.UpdatedAt).UTC().Format(time.RFC3339)
+	}
+	if len(annotations) > 0 {
+		accessor.SetAnnotations(annotations)
+	}
+	return obj, nil
+}
+
+// requiredLabels returns the labels that must match, so the entity store can filter them. The other
+// requirements of the selector are checked by the storage.
+func requiredLabels(selector labels.Selector) map[string]string {
+	if selector == nil {
+		return nil
+	}
+	requirements, _ := selector.Requirements()
+	required := map[string]string{}
+	for _, r := range requirements {
+		switch r.Operator() {
+		case selection.Equals, selection.DoubleEquals:
+		case selection.In:
+			if r.Values().Len() != 1 {
+				continue
+			}
+		default:
+			continue
+		}
+		value, _ := r.Values().PopAny()
+		required[r.Key()] = value
+	}
+	return required
+}
+
+// matches checks the label and field selectors, the fields are the name and namespace
+func matches(obj runtime.Object, options *metainternalversion.ListOptions) bool {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return false
+	}
+	if options.LabelSelector != nil && !options.LabelSelector.Matches(labels.Set(accessor.GetLabels())) {
+		return false
+	}
+	if options.FieldSelector != nil && !options.FieldSelector.Matches(fields.Set{
+		"metadata.name":      accessor.GetName(),
+		"metadata.namespace": accessor.GetNamespace(),
+	}) {
+		return false
+	}
+	return true
+}
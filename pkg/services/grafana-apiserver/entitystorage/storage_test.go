@@ -0,0 +1,487 @@
+package entitystorage
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	k8suser "k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/grafana/grafana/pkg/apis/dashboard/v0alpha1"
+	"github.com/grafana/grafana/pkg/infra/appcontext"
+	"github.com/grafana/grafana/pkg/infra/grn"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/services/store/entity"
+)
+
+func TestStorage(t *testing.T) {
+	store := newFakeStore()
+	s := &Storage{
+		Store:                     store,
+		Kind:                      entity.StandardKindDashboard,
+		NewFunc:                   func() runtime.Object { return &v0alpha1.Dashboard{} },
+		NewListFunc:               func() runtime.Object { return &v0alpha1.DashboardList{} },
+		DefaultQualifiedResource:  v0alpha1.Resource("dashboards"),
+		SingularQualifiedResource: v0alpha1.Resource("dashboard"),
+		ToObject: func(body []byte) (runtime.Object, error) {
+			return &v0alpha1.Dashboard{Spec: body}, nil
+		},
+		ToBody: func(obj runtime.Object) ([]byte, error) {
+			return obj.(*v0alpha1.Dashboard).Spec, nil
+		},
+		Access: allowAllAccess(),
+	}
+	editor := requestContext("default", "1", "Editor")
+
+	create := func(t *testing.T, name string, spec string) *v0alpha1.Dashboard {
+		t.Helper()
+		obj, err := s.Create(editor, &v0alpha1.Dashboard{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{AnnotationFolder: "f1"},
+			},
+			Spec: json.RawMessage(spec),
+		}, nil, &metav1.CreateOptions{})
+		require.NoError(t, err)
+		return obj.(*v0alpha1.Dashboard)
+	}
+
+	a := create(t, "a", `{"title": "A", "tags": ["prod"]}`)
+	create(t, "b", `{"title": "B"}`)
+
+	t.Run("reads the entity metadata", func(t *testing.T) {
+		obj, err := s.Get(editor, "a", &metav1.GetOptions{})
+		require.NoError(t, err)
+		dash := obj.(*v0alpha1.Dashboard)
+		require.Equal(t, "default", dash.Namespace)
+		require.Equal(t, a.ResourceVersion, dash.ResourceVersion)
+		require.Equal(t, map[string]string{"prod": ""}, dash.Labels)
+		require.Equal(t, "f1", dash.Annotations[AnnotationFolder])
+		require.JSONEq(t, `{"title": "A", "tags": ["prod"]}`, string(dash.Spec))
+
+		_, err = s.Get(editor, "missing", &metav1.GetOptions{})
+		require.True(t, apierrors.IsNotFound(err))
+	})
+
+	t.Run("fails to create an existing resource", func(t *testing.T) {
+		_, err := s.Create(editor, &v0alpha1.Dashboard{
+			ObjectMeta: metav1.ObjectMeta{Name: "a"},
+			Spec:       json.RawMessage(`{}`),
+		}, nil, &metav1.CreateOptions{})
+		require.True(t, apierrors.IsAlreadyExists(err))
+	})
+
+	t.Run("checks the resource version of updates", func(t *testing.T) {
+		stale := a.DeepCopy()
+		a.Spec = json.RawMessage(`{"title": "A v2", "tags": ["prod"]}`)
+		obj, created, err := s.Update(editor, "a", rest.DefaultUpdatedObjectInfo(a), nil, nil, false, &metav1.UpdateOptions{})
+		require.NoError(t, err)
+		require.False(t, created)
+		updated := obj.(*v0alpha1.Dashboard)
+		require.NotEqual(t, a.ResourceVersion, updated.ResourceVersion)
+		require.Equal(t, int64(2), updated.Generation)
+
+		stale.Spec = json.RawMessage(`{"title": "A v3"}`)
+		_, _, err = s.Update(editor, "a", rest.DefaultUpdatedObjectInfo(stale), nil, nil, false, &metav1.UpdateOptions{})
+		require.True(t, apierrors.IsConflict(err))
+	})
+
+	t.Run("filters the list with label selectors", func(t *testing.T) {
+		obj, err := s.List(editor, &metainternalversion.ListOptions{})
+		require.NoError(t, err)
+		list := obj.(*v0alpha1.DashboardList)
+		require.Len(t, list.Items, 2)
+		require.Equal(t, strconv.FormatInt(store.rv, 10), list.ResourceVersion)
+
+		selector, err := labels.Parse("prod")
+		require.NoError(t, err)
+		obj, err = s.List(editor, &metainternalversion.ListOptions{LabelSelector: selector})
+		require.NoError(t, err)
+		list = obj.(*v0alpha1.DashboardList)
+		require.Len(t, list.Items, 1)
+		require.Equal(t, "a", list.Items[0].Name)
+
+		selector, err = labels.Parse("!prod")
+		require.NoError(t, err)
+		obj, err = s.List(editor, &metainternalversion.ListOptions{LabelSelector: selector})
+		require.NoError(t, err)
+		require.Equal(t, "b", obj.(*v0alpha1.DashboardList).Items[0].Name)
+	})
+
+	t.Run("only serves the org of the user", func(t *testing.T) {
+		_, err := s.Get(requestContext("org-2", "1", "Editor"), "b", &metav1.GetOptions{})
+		require.True(t, apierrors.IsForbidden(err))
+	})
+
+	t.Run("watches the changes after the list", func(t *testing.T) {
+		w, err := s.Watch(editor, &metainternalversion.ListOptions{})
+		require.NoError(t, err)
+		defer w.Stop()
+
+		events := nextEvents(t, w, 2)
+		require.Equal(t, watch.Added, events[0].Type)
+		require.Equal(t, watch.Added, events[1].Type)
+
+		create(t, "c", `{"title": "C"}`)
+		rv := "0"
+		_, _, err = s.Delete(editor, "b", nil, &metav1.DeleteOptions{
+			Preconditions: &metav1.Preconditions{ResourceVersion: &rv},
+		})
+		require.True(t, apierrors.IsConflict(err))
+		_, deleted, err := s.Delete(editor, "b", nil, &metav1.DeleteOptions{})
+		require.NoError(t, err)
+		require.True(t, deleted)
+
+		events = nextEvents(t, w, 2)
+		require.Equal(t, watch.Added, events[0].Type)
+		require.Equal(t, "c", events[0].Object.(*v0alpha1.Dashboard).Name)
+		require.Equal(t, watch.Deleted, events[1].Type)
+		require.Equal(t, "b", events[1].Object.(*v0alpha1.Dashboard).Name)
+	})
+}
+
+func TestStorageAccess(t *testing.T) {
+	store := newFakeStore()
+	newStorage := func(access *ResourceAccess) *Storage {
+		return &Storage{
+			Store:                     store,
+			Kind:                      entity.StandardKindDashboard,
+			NewFunc:                   func() runtime.Object { return &v0alpha1.Dashboard{} },
+			NewListFunc:               func() runtime.Object { return &v0alpha1.DashboardList{} },
+			DefaultQualifiedResource:  v0alpha1.Resource("dashboards"),
+			SingularQualifiedResource: v0alpha1.Resource("dashboard"),
+			ToObject: func(body []byte) (runtime.Object, error) {
+				return &v0alpha1.Dashboard{Spec: body}, nil
+			},
+			ToBody: func(obj runtime.Object) ([]byte, error) {
+				return obj.(*v0alpha1.Dashboard).Spec, nil
+			},
+			Access: access,
+		}
+	}
+	admin := newStorage(allowAllAccess())
+	s := newStorage(&ResourceAccess{
+		AccessControl: actest.FakeService{ExpectedPermissions: []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeFoldersProvider.GetResourceScopeUID("f1")},
+			{Action: dashboards.ActionDashboardsCreate, Scope: dashboards.ScopeFoldersProvider.GetResourceScopeUID("f1")},
+			{Action: dashboards.ActionDashboardsWrite, Scope: dashboards.ScopeDashboardsProvider.GetResourceScopeUID("a")},
+		}},
+		Folders:   foldertest.NewFakeService(),
+		Evaluator: dashboardEvaluator,
+	})
+	editor := requestContext("default", "1", "Editor")
+
+	newDashboard := func(name string, folder string) *v0alpha1.Dashboard {
+		return &v0alpha1.Dashboard{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{AnnotationFolder: folder},
+			},
+			Spec: json.RawMessage(`{"title": "` + name + `"}`),
+		}
+	}
+	for name, folder := range map[string]string{"a": "f1", "b": "f2", "c": "f1"} {
+		_, err := admin.Create(editor, newDashboard(name, folder), nil, &metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+
+	t.Run("reads the dashboards of the readable folders", func(t *testing.T) {
+		_, err := s.Get(editor, "a", &metav1.GetOptions{})
+		require.NoError(t, err)
+		_, err = s.Get(editor, "b", &metav1.GetOptions{})
+		require.True(t, apierrors.IsForbidden(err))
+
+		obj, err := s.List(editor, &metainternalversion.ListOptions{})
+		require.NoError(t, err)
+		list := obj.(*v0alpha1.DashboardList)
+		require.Len(t, list.Items, 2)
+		require.Equal(t, "a", list.Items[0].Name)
+		require.Equal(t, "c", list.Items[1].Name)
+	})
+
+	t.Run("lists the dashboards by pages", func(t *testing.T) {
+		obj, err := s.List(editor, &metainternalversion.ListOptions{Limit: 2})
+		require.NoError(t, err)
+		list := obj.(*v0alpha1.DashboardList)
+		require.Len(t, list.Items, 1) // b is filtered out
+		require.Equal(t, "a", list.Items[0].Name)
+		require.Equal(t, "c", list.Continue)
+
+		obj, err = s.List(editor, &metainternalversion.ListOptions{Limit: 2, Continue: list.Continue})
+		require.NoError(t, err)
+		list = obj.(*v0alpha1.DashboardList)
+		require.Len(t, list.Items, 1)
+		require.Equal(t, "c", list.Items[0].Name)
+		require.Empty(t, list.Continue)
+	})
+
+	t.Run("checks the permissions of the changes", func(t *testing.T) {
+		_, err := s.Create(editor, newDashboard("d", "f2"), nil, &metav1.CreateOptions{})
+		require.True(t, apierrors.IsForbidden(err))
+		_, err = s.Create(editor, newDashboard("d", "f1"), nil, &metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		_, _, err = s.Update(editor, "a", rest.DefaultUpdatedObjectInfo(newDashboard("a", "f1")), nil, nil, false, &metav1.UpdateOptions{})
+		require.NoError(t, err)
+		_, _, err = s.Update(editor, "a", rest.DefaultUpdatedObjectInfo(newDashboard("a", "f2")), nil, nil, false, &metav1.UpdateOptions{})
+		require.True(t, apierrors.IsForbidden(err))
+		_, _, err = s.Update(editor, "c", rest.DefaultUpdatedObjectInfo(newDashboard("c", "f1")), nil, nil, false, &metav1.UpdateOptions{})
+		require.True(t, apierrors.IsForbidden(err))
+
+		_, _, err = s.Delete(editor, "a", nil, &metav1.DeleteOptions{})
+		require.True(t, apierrors.IsForbidden(err))
+	})
+
+	t.Run("watches the changes of the readable dashboards", func(t *testing.T) {
+		w, err := s.Watch(editor, &metainternalversion.ListOptions{ResourceVersion: strconv.FormatInt(store.rv, 10)})
+		require.NoError(t, err)
+		defer w.Stop()
+
+		_, err = admin.Create(editor, newDashboard("e", "f2"), nil, &metav1.CreateOptions{})
+		require.NoError(t, err)
+		_, err = admin.Create(editor, newDashboard("f", "f1"), nil, &metav1.CreateOptions{})
+		require.NoError(t, err)
+
+		events := nextEvents(t, w, 1)
+		require.Equal(t, "f", events[0].Object.(*v0alpha1.Dashboard).Name)
+	})
+
+	t.Run("denies everything without resource access", func(t *testing.T) {
+		denied := newStorage(nil)
+		_, err := denied.Get(editor, "a", &metav1.GetOptions{})
+		require.True(t, apierrors.IsForbidden(err))
+
+		obj, err := denied.List(editor, &metainternalversion.ListOptions{})
+		require.NoError(t, err)
+		require.Empty(t, obj.(*v0alpha1.DashboardList).Items)
+
+		_, err = denied.Create(editor, newDashboard("g", "f1"), nil, &metav1.CreateOptions{})
+		require.True(t, apierrors.IsForbidden(err))
+	})
+}
+
+func dashboardEvaluator(verb Verb, name string, folderScopes []string) accesscontrol.Evaluator {
+	scopes := append([]string{dashboards.ScopeDashboardsProvider.GetResourceScopeUID(name)}, folderScopes...)
+	switch verb {
+	case VerbCreate:
+		return accesscontrol.EvalPermission(dashboards.ActionDashboardsCreate, folderScopes...)
+	case VerbUpdate:
+		return accesscontrol.EvalPermission(dashboards.ActionDashboardsWrite, scopes...)
+	case VerbDelete:
+		return accesscontrol.EvalPermission(dashboards.ActionDashboardsDelete, scopes...)
+	default:
+		return accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, scopes...)
+	}
+}
+
+// allowAllAccess grants every permission on the dashboards of all the folders
+func allowAllAccess() *ResourceAccess {
+	return &ResourceAccess{
+		AccessControl: actest.FakeService{ExpectedPermissions: []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeFoldersAll},
+			{Action: dashboards.ActionDashboardsCreate, Scope: dashboards.ScopeFoldersAll},
+			{Action: dashboards.ActionDashboardsWrite, Scope: dashboards.ScopeFoldersAll},
+			{Action: dashboards.ActionDashboardsDelete, Scope: dashboards.ScopeFoldersAll},
+		}},
+		Folders:   foldertest.NewFakeService(),
+		Evaluator: dashboardEvaluator,
+	}
+}
+
+func requestContext(namespace string, orgID string, role string) context.Context {
+	ctx := request.WithNamespace(context.Background(), namespace)
+	return request.WithUser(ctx, &k8suser.DefaultInfo{
+		Name:   "1",
+		Groups: []string{"grafana"},
+		Extra: map[string][]string{
+			"org-id":   {orgID},
+			"org-role": {role},
+			"user-id":  {"1"},
+			"login":    {"editor"},
+		},
+	})
+}
+
+func nextEvents(t *testing.T, w watch.Interface, count int) []watch.Event {
+	t.Helper()
+	var events []watch.Event
+	for len(events) < count {
+		select {
+		case e := <-w.ResultChan():
+			require.NotEqual(t, watch.Error, e.Type, "%v", e.Object)
+			events = append(events, e)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timeout waiting for events", "received %d of %d", len(events), count)
+		}
+	}
+	return events
+}
+
+// fakeStore keeps the dashboards of a single org in memory, the tags are the labels
+type fakeStore struct {
+	entity.UnimplementedEntityStoreServer
+
+	mu       sync.Mutex
+	rv       int64
+	entities map[string]*entity.Entity
+	labels   map[string]map[string]string
+	changes  []*entity.EntityWatchResponse
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		entities: map[string]*entity.Entity{},
+		labels:   map[string]map[string]string{},
+	}
+}
+
+func (f *fakeStore) Read(ctx context.Context, r *entity.ReadEntityRequest) (*entity.Entity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e, ok := f.entities[r.GRN.ResourceIdentifier]
+	if !ok {
+		return &entity.Entity{}, nil
+	}
+	out := proto.Clone(e).(*entity.Entity)
+	out.SummaryJson, _ = json.Marshal(&entity.EntitySummary{Labels: f.labels[e.GRN.ResourceIdentifier]})
+	return out, nil
+}
+
+func (f *fakeStore) Write(ctx context.Context, r *entity.WriteEntityRequest) (*entity.WriteEntityResponse, error) {
+	u := appcontext.MustUser(ctx)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	action := entity.EntityWatchResponse_CREATED
+	version := int64(1)
+	e, ok := f.entities[r.GRN.ResourceIdentifier]
+	if ok {
+		if r.PreviousVersion != "" && r.PreviousVersion != e.Version {
+			return nil, entity.ErrOptimisticLockFailed
+		}
+		action = entity.EntityWatchResponse_UPDATED
+		version, _ = strconv.ParseInt(e.Version, 10, 64)
+		version++
+	}
+
+	dash := struct {
+		Tags []string `json:"tags"`
+	}{}
+	if err := json.Unmarshal(r.Body, &dash); err != nil {
+		return nil, err
+	}
+	lbls := map[string]string{}
+	for _, tag := range dash.Tags {
+		lbls[tag] = ""
+	}
+
+	f.rv++
+	e = &entity.Entity{
+		GRN:             &grn.GRN{TenantID: u.OrgID, ResourceKind: r.GRN.ResourceKind, ResourceIdentifier: r.GRN.ResourceIdentifier},
+		Version:         strconv.FormatInt(version, 10),
+		Folder:          r.Folder,
+		Body:            r.Body,
+		CreatedAt:       time.Now().UnixMilli(),
+		ResourceVersion: f.rv,
+	}
+	f.entities[r.GRN.ResourceIdentifier] = e
+	f.labels[r.GRN.ResourceIdentifier] = lbls
+	f.changes = append(f.changes, &entity.EntityWatchResponse{Action: action, ResourceVersion: f.rv, Entity: []*entity.Entity{e}})
+	return &entity.WriteEntityResponse{GRN: e.GRN}, nil
+}
+
+func (f *fakeStore) Delete(ctx context.Context, r *entity.DeleteEntityRequest) (*entity.DeleteEntityResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e, ok := f.entities[r.GRN.ResourceIdentifier]
+	if !ok {
+		return &entity.DeleteEntityResponse{}, nil
+	}
+	if r.PreviousVersion != "" && r.PreviousVersion != e.Version {
+		return nil, entity.ErrOptimisticLockFailed
+	}
+	delete(f.entities, r.GRN.ResourceIdentifier)
+	f.rv++
+	f.changes = append(f.changes, &entity.EntityWatchResponse{
+		Action:          entity.EntityWatchResponse_DELETED,
+		ResourceVersion: f.rv,
+		Entity:          []*entity.Entity{{GRN: e.GRN, Version: e.Version}},
+	})
+	return &entity.DeleteEntityResponse{OK: true}, nil
+}
+
+func (f *fakeStore) Search(ctx context.Context, r *entity.EntitySearchRequest) (*entity.EntitySearchResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	uids := make([]string, 0, len(f.entities))
+	for uid := range f.entities {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	// the page token is the uid of the first entity of the page
+	rsp := &entity.EntitySearchResponse{ResourceVersion: f.rv}
+	for _, uid := range uids {
+		e := f.entities[uid]
+		if uid < r.NextPageToken || !labels.SelectorFromSet(r.Labels).Matches(labels.Set(f.labels[uid])) {
+			continue
+		}
+		if r.Limit > 0 && int64(len(rsp.Results)) == r.Limit {
+			rsp.NextPageToken = uid
+			break
+		}
+		rsp.Results = append(rsp.Results, &entity.EntitySearchResult{
+			GRN:             e.GRN,
+			Version:         e.Version,
+			Folder:          e.Folder,
+			Body:            e.Body,
+			Labels:          f.labels[uid],
+			ResourceVersion: e.ResourceVersion,
+		})
+	}
+	return rsp, nil
+}
+
+func (f *fakeStore) Watch(r *entity.EntityWatchRequest, srv entity.EntityStore_WatchServer) error {
+	rv := r.ResourceVersion
+	for {
+		f.mu.Lock()
+		var changes []*entity.EntityWatchResponse
+		for _, c := range f.changes {
+			if c.ResourceVersion > rv {
+				changes = append(changes, c)
+			}
+		}
+		f.mu.Unlock()
+
+		for _, c := range changes {
+			if err := srv.Send(c); err != nil {
+				return err
+			}
+			rv = c.ResourceVersion
+		}
+
+		select {
+		case <-srv.Context().Done():
+			return nil
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package entitystorage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"google.golang.org/grpc"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/grafana/grafana/pkg/services/store/entity"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// Watch streams the changes of the entity store. Without a resource version (or with "0") the existing
+// resources are sent first as added, then the changes after the list.
+func (s *Storage) Watch(ctx context.Context, options *metainternalversion.ListOptions) (watch.Interface, error) {
+	ctx, u, err := s.userContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rv := int64(0)
+	if options.ResourceVersion != "" && options.ResourceVersion != "0" {
+		rv, err = strconv.ParseInt(options.ResourceVersion, 10, 64)
+		if err != nil {
+			return nil, apierrors.NewBadRequest("invalid resource version: " + options.ResourceVersion)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	w := &watcher{
+		storage: s,
+		user:    u,
+		options: options,
+		ctx:     ctx,
+		cancel:  cancel,
+		result:  make(chan watch.Event),
+	}
+	go w.run(rv)
+	return w, nil
+}
+
+var (
+	_ watch.Interface                = (*watcher)(nil)
+	_ entity.EntityStore_WatchServer = (*watcher)(nil)
+)
+
+// watcher converts the responses of an entity store watch to events. It is used as the stream of
+// the watch, so only Context and Send are implemented.
+type watcher struct {
+	grpc.ServerStream
+
+	storage *Storage
+	user    *user.SignedInUser
+	options *metainternalversion.ListOptions
+	ctx     context.Context
+	cancel  context.CancelFunc
+	result  chan watch.Event
+}
+
+func (w *watcher) Stop() {
+	w.cancel()
+}
+
+func (w *watcher) ResultChan() <-chan watch.Event {
+	return w.result
+}
+
+func (w *watcher) Context() context.Context {
+	return w.ctx
+}
+
+func (w *watcher) run(rv int64) {
+	defer close(w.result)
+
+	if rv == 0 {
+		// the initial list is not paged
+		options := *w.options
+		options.Limit = 0
+		options.Continue = ""
+		items, listRV, _, err := w.storage.list(w.ctx, w.user, &options)
+		if err != nil {
+			w.sendError(err)
+			return
+		}
+		for _, obj := range items {
+			if err = w.send(watch.Event{Type: watch.Added, Object: obj}); err != nil {
+				return
+			}
+		}
+		rv = listRV
+	}
+
+	err := w.storage.Store.Watch(&entity.EntityWatchRequest{
+		Kind:            []string{w.storage.Kind},
+		Labels:          requiredLabels(w.options.LabelSelector),
+		WithBody:        true,
+		WithLabels:      true,
+		ResourceVersion: rv,
+		AllowBookmarks:  w.options.AllowWatchBookmarks,
+	}, w)
+	if err != nil && w.ctx.Err() == nil {
+		w.sendError(err)
+	}
+}
+
+// Send is called by the entity store for each change
+func (w *watcher) Send(rsp *entity.EntityWatchResponse) error {
+	if rsp.Action == entity.EntityWatchResponse_BOOKMARK {
+		obj := w.storage.NewFunc()
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			return err
+		}
+		accessor.SetResourceVersion(strconv.FormatInt(rsp.ResourceVersion, 10))
+		return w.send(watch.Event{Type: watch.Bookmark, Object: obj})
+	}
+
+	// the folders may be moved during the watch, their scopes are resolved for each change
+	canRead := w.storage.checker(w.ctx, w.user, VerbGet)
+	eventType := watch.Modified
+	switch rsp.Action {
+	case entity.EntityWatchResponse_CREATED:
+		eventType = watch.Added
+	case entity.EntityWatchResponse_DELETED:
+		eventType = watch.Deleted
+	}
+
+	for _, e := range rsp.Entity {
+		summary := &entity.EntitySummary{}
+		if len(e.SummaryJson) > 0 {
+			if err := json.Unmarshal(e.SummaryJson, summary); err != nil {
+				return err
+			}
+		}
+		// the resource version of the change, the entity has no resource version in the events
+		e.ResourceVersion = rsp.ResourceVersion
+
+		obj, err := w.storage.newObject(e, summary.Labels)
+		if err != nil {
+			return err
+		}
+		if !matches(obj, w.options) {
+			continue
+		}
+		ok, err := canRead(e.GRN.ResourceIdentifier, e.Folder)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err = w.send(watch.Event{Type: eventType, Object: obj}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *watcher) send(event watch.Event) error {
+	select {
+	case w.result <- event:
+		return nil
+	case <-w.ctx.Done():
+		return w.ctx.Err()
+	}
+}
+
+func (w *watcher) sendError(err error) {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) {
+		status = apierrors.NewInternalError(err)
+	}
+	obj := status.Status()
+	_ = w.send(watch.Event{Type: watch.Error, Object: &obj})
+}
@@ -0,0 +1,124 @@
+package grafanaapiserver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	k8suser "k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// The resources of the default org are in the default namespace, the other orgs use "org-{id}"
+const (
+	defaultNamespace   = "default"
+	orgNamespacePrefix = "org-"
+)
+
+// Extra values set by the /k8s proxy for the signed in user (see the X-Remote-Extra- headers)
+const (
+	extraOrgID        = "org-id"
+	extraOrgRole      = "org-role"
+	extraUserID       = "user-id"
+	extraLogin        = "login"
+	extraName         = "token-name"
+	extraGrafanaAdmin = "grafana-admin"
+	extraTeamID       = "team-id"
+)
+
+// NamespaceForOrg returns the namespace of the resources in an org
+func NamespaceForOrg(orgID int64) string {
+	if orgID == 1 {
+		return defaultNamespace
+	}
+	return orgNamespacePrefix + strconv.FormatInt(orgID, 10)
+}
+
+// OrgIDForNamespace returns the org of the resources in a namespace
+func OrgIDForNamespace(namespace string) (int64, error) {
+	if namespace == defaultNamespace {
+		return 1, nil
+	}
+	if id, ok := strings.CutPrefix(namespace, orgNamespacePrefix); ok {
+		orgID, err := strconv.ParseInt(id, 10, 64)
+		if err == nil && orgID > 0 {
+			return orgID, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid namespace %q, expected %q or %q", namespace, defaultNamespace, orgNamespacePrefix+"{orgId}")
+}
+
+// SignedInUserFrom returns the user of an apiserver request, in the org of the namespace. The privileged users
+// (the loopback client) are admins of every org; when the namespace is empty they use the default org.
+func SignedInUserFrom(ctx context.Context, namespace string) (*user.SignedInUser, error) {
+	info, ok := request.UserFrom(ctx)
+	if !ok {
+		return nil, fmt.Errorf("missing user in request")
+	}
+
+	orgID := int64(0)
+	if namespace != "" {
+		id, err := OrgIDForNamespace(namespace)
+		if err != nil {
+			return nil, err
+		}
+		orgID = id
+	}
+
+	for _, group := range info.GetGroups() {
+		if group == k8suser.SystemPrivilegedGroup {
+			if orgID == 0 {
+				orgID = 1
+			}
+			return &user.SignedInUser{
+				OrgID:          orgID,
+				OrgRole:        org.RoleAdmin,
+				Login:          info.GetName(),
+				IsGrafanaAdmin: true,
+			}, nil
+		}
+	}
+
+	extra := info.GetExtra()
+	userOrgID, err := strconv.ParseInt(extraValue(extra, extraOrgID), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("missing org in request")
+	}
+	if orgID != 0 && orgID != userOrgID {
+		return nil, fmt.Errorf("namespace %q is not in the org of the user", namespace)
+	}
+	userID, err := strconv.ParseInt(extraValue(extra, extraUserID), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("missing user id in request")
+	}
+	// the permissions granted to the teams of the user are loaded with its own permissions
+	teams := make([]int64, 0, len(extra[extraTeamID]))
+	for _, v := range extra[extraTeamID] {
+		teamID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid team id %q in request", v)
+		}
+		teams = append(teams, teamID)
+	}
+
+	return &user.SignedInUser{
+		UserID:         userID,
+		OrgID:          userOrgID,
+		OrgRole:        org.RoleType(extraValue(extra, extraOrgRole)),
+		Login:          extraValue(extra, extraLogin),
+		Name:           extraValue(extra, extraName),
+		IsGrafanaAdmin: extraValue(extra, extraGrafanaAdmin) == "true",
+		Teams:          teams,
+	}, nil
+}
+
+func extraValue(extra map[string][]string, key string) string {
+	if v := extra[key]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
@@ -0,0 +1,57 @@
+package grafanaapiserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	k8suser "k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+func TestSignedInUserFrom(t *testing.T) {
+	newContext := func(extra map[string][]string) context.Context {
+		return request.WithUser(context.Background(), &k8suser.DefaultInfo{
+			Name:   "1",
+			Groups: []string{"grafana"},
+			Extra:  extra,
+		})
+	}
+
+	t.Run("reads the user, its teams and its server admin flag from the extras", func(t *testing.T) {
+		u, err := SignedInUserFrom(newContext(map[string][]string{
+			extraOrgID:        {"2"},
+			extraOrgRole:      {"Editor"},
+			extraUserID:       {"7"},
+			extraLogin:        {"editor"},
+			extraGrafanaAdmin: {"true"},
+			extraTeamID:       {"3", "5"},
+		}), "org-2")
+		require.NoError(t, err)
+		require.Equal(t, int64(7), u.UserID)
+		require.Equal(t, int64(2), u.OrgID)
+		require.Equal(t, org.RoleEditor, u.OrgRole)
+		require.Equal(t, "editor", u.Login)
+		require.True(t, u.IsGrafanaAdmin)
+		require.Equal(t, []int64{3, 5}, u.Teams)
+	})
+
+	t.Run("rejects the namespaces of other orgs", func(t *testing.T) {
+		_, err := SignedInUserFrom(newContext(map[string][]string{
+			extraOrgID:  {"1"},
+			extraUserID: {"7"},
+		}), "org-2")
+		require.Error(t, err)
+	})
+
+	t.Run("rejects invalid team ids", func(t *testing.T) {
+		_, err := SignedInUserFrom(newContext(map[string][]string{
+			extraOrgID:  {"1"},
+			extraUserID: {"7"},
+			extraTeamID: {"team"},
+		}), "default")
+		require.Error(t, err)
+	})
+}
@@ -2,10 +2,13 @@ package grafanaapiserver
 
 import (
 	"context"
-	"crypto/x509"
+	"crypto/subtle"
 	"net"
+	"net/http"
 	"os"
 	"path"
+	"strconv"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/grafana/dskit/services"
@@ -18,6 +21,7 @@ import (
 	"k8s.io/apiserver/pkg/authentication/authenticator"
 	"k8s.io/apiserver/pkg/authentication/request/headerrequest"
 	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/responsewriter"
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	"k8s.io/apiserver/pkg/server/options"
 	"k8s.io/client-go/rest"
@@ -25,20 +29,34 @@ import (
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/klog/v2"
 
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/modules"
+	"github.com/grafana/grafana/pkg/registry"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 const (
 	DefaultAPIServerHost = "https://" + certgenerator.DefaultAPIServerIp + ":6443"
+
+	// proxyTokenHeader holds the token of the requests proxied by Grafana. The user headers are only trusted along
+	// with it, since any client connecting to the apiserver port can send them.
+	proxyTokenHeader = "X-Grafana-Proxy-Token"
 )
 
 var (
 	_ Service            = (*service)(nil)
 	_ RestConfigProvider = (*service)(nil)
+	_ APIRegistrar       = (*service)(nil)
 )
 
 type Service interface {
 	services.NamedService
+	registry.BackgroundService
+	registry.CanBeDisabled
 }
 
 type RestConfigProvider interface {
@@ -50,15 +68,24 @@ type service struct {
 
 	restConfig *rest.Config
 
+	features featuremgmt.FeatureToggles
+	builders []APIGroupBuilder
+	handler  http.Handler
+
+	// proxyToken authenticates the requests proxied by Grafana, it is generated when the service starts
+	proxyToken string
+
 	dataPath  string
 	stopCh    chan struct{}
 	stoppedCh chan error
 }
 
+// New creates the apiserver run as the grafana-apiserver module target, without API groups nor /k8s routes
 func New(dataPath string) (*service, error) {
 	s := &service{
-		dataPath: dataPath,
-		stopCh:   make(chan struct{}),
+		dataPath:  dataPath,
+		stopCh:    make(chan struct{}),
+		stoppedCh: make(chan error, 1),
 	}
 
 	s.BasicService = services.NewBasicService(s.start, s.running, nil).WithName(modules.GrafanaAPIServer)
@@ -66,15 +93,59 @@ func New(dataPath string) (*service, error) {
 	return s, nil
 }
 
+// ProvideService creates the apiserver run as a background service of Grafana, serving the registered API groups
+func ProvideService(cfg *setting.Cfg, features featuremgmt.FeatureToggles, rr routing.RouteRegister) (*service, error) {
+	s, err := New(path.Join(cfg.DataPath, "k8s"))
+	if err != nil {
+		return nil, err
+	}
+	s.features = features
+
+	// The apiserver is served under /k8s for the signed in users
+	rr.Group("/k8s", func(k8sRoute routing.RouteRegister) {
+		k8sRoute.Any("/", middleware.ReqSignedIn, s.proxy)
+		k8sRoute.Any("/*", middleware.ReqSignedIn, s.proxy)
+	})
+
+	return s, nil
+}
+
 func (s *service) GetRestConfig() *rest.Config {
 	return s.restConfig
 }
 
+func (s *service) IsDisabled() bool {
+	return !s.features.IsEnabled(featuremgmt.FlagGrafanaAPIServer)
+}
+
+// RegisterAPI adds an API group to the server. It must be called before the server starts
+func (s *service) RegisterAPI(builder APIGroupBuilder) {
+	s.builders = append(s.builders, builder)
+}
+
+// Run is called by the background service registry, and blocks until the server stops
+func (s *service) Run(ctx context.Context) error {
+	if err := s.StartAsync(ctx); err != nil {
+		return err
+	}
+	if err := s.AwaitRunning(ctx); err != nil {
+		return err
+	}
+	return s.AwaitTerminated(context.Background())
+}
+
 func (s *service) start(ctx context.Context) error {
 	logger := logr.New(newLogAdapter())
 	logger.V(9)
 	klog.SetLoggerWithOptions(logger, klog.ContextualLogger(true))
 
+	// The definition namer of the config is built from the scheme, so the kinds are added first
+	for _, b := range s.builders {
+		if err := b.InstallSchema(grafanaapiserver.Scheme); err != nil {
+			return err
+		}
+	}
+
 	o := grafanaapiserveroptions.NewGrafanaAPIServerOptions(os.Stdout, os.Stderr)
 	o.RecommendedOptions.SecureServing.BindPort = 6443
 	o.RecommendedOptions.Authentication.RemoteKubeConfigFileOptional = true
@@ -117,16 +188,17 @@ func (s *service) start(ctx context.Context) error {
 		return err
 	}
 
-	rootCert, err := certUtil.GetK8sCACert()
-	if err != nil {
-		return err
-	}
+	addOpenAPIDefinitions(serverConfig.GenericConfig, s.builders)
 
 	serverConfig.ExtraConfig.RESTOptionsGetter = filepath.NewRESTOptionsGetter(s.dataPath, unstructured.UnstructuredJSONScheme)
 	serverConfig.GenericConfig.RESTOptionsGetter = filepath.NewRESTOptionsGetter(s.dataPath, grafanaapiserver.Codecs.LegacyCodec(kindsv1.SchemeGroupVersion))
 	serverConfig.GenericConfig.Config.RESTOptionsGetter = filepath.NewRESTOptionsGetter(s.dataPath, grafanaapiserver.Codecs.LegacyCodec(kindsv1.SchemeGroupVersion))
 
-	authenticator, err := newAuthenticator(rootCert)
+	s.proxyToken, err = util.GetRandomString(32)
+	if err != nil {
+		return err
+	}
+	authenticator, err := newAuthenticator(s.proxyToken)
 	if err != nil {
 		return err
 	}
@@ -138,6 +210,19 @@ func (s *service) start(ctx context.Context) error {
 		return err
 	}
 
+	for _, b := range s.builders {
+		g, err := b.GetAPIGroupInfo(grafanaapiserver.Scheme, grafanaapiserver.Codecs)
+		if err != nil {
+			return err
+		}
+		if g == nil || len(g.PrioritizedVersions) < 1 {
+			continue
+		}
+		if err = server.GenericAPIServer.InstallAPIGroup(g); err != nil {
+			return err
+		}
+	}
+
 	s.restConfig = server.GenericAPIServer.LoopbackClientConfig
 	err = s.writeKubeConfiguration(s.restConfig)
 	if err != nil {
@@ -145,33 +230,7 @@ func (s *service) start(ctx context.Context) error {
 	}
 
 	prepared := server.GenericAPIServer.PrepareRun()
-
-	// TODO: not sure if we can still inject RouteRegister with the new module server setup
-	// Disabling the /k8s endpoint until we have a solution
-
-	/* handler := func(c *contextmodel.ReqContext) {
-		req := c.Req
-		req.URL.Path = strings.TrimPrefix(req.URL.Path, "/k8s")
-		if req.URL.Path == "" {
-			req.URL.Path = "/"
-		}
-		ctx := req.Context()
-		signedInUser := appcontext.MustUser(ctx)
-
-		req.Header.Set("X-Remote-User", strconv.FormatInt(signedInUser.UserID, 10))
-		req.Header.Set("X-Remote-Group", "grafana")
-		req.Header.Set("X-Remote-Extra-token-name", signedInUser.Name)
-		req.Header.Set("X-Remote-Extra-org-role", string(signedInUser.OrgRole))
-		req.Header.Set("X-Remote-Extra-org-id", strconv.FormatInt(signedInUser.OrgID, 10))
-		req.Header.Set("X-Remote-Extra-user-id", strconv.FormatInt(signedInUser.UserID, 10))
-
-		resp := responsewriter.WrapForHTTP1Or2(c.Resp)
-		prepared.GenericAPIServer.Handler.ServeHTTP(resp, req)
-	}
-	/* s.rr.Group("/k8s", func(k8sRoute routing.RouteRegister) {
-		k8sRoute.Any("/", middleware.ReqSignedIn, handler)
-		k8sRoute.Any("/*", middleware.ReqSignedIn, handler)
-	}) */
+	s.handler = prepared.GenericAPIServer.Handler
 
 	go func() {
 		s.stoppedCh <- prepared.Run(s.stopCh)
@@ -192,6 +251,44 @@ func (s *service) running(ctx context.Context) error {
 	return nil
 }
 
+// proxy serves the apiserver requests of the signed in user. The user is sent in the headers read by the
+// request header authenticator along with the proxy token, so the headers sent by the client are removed first.
+func (s *service) proxy(c *contextmodel.ReqContext) {
+	// the handler is set when the service starts
+	if s.State() != services.Running {
+		c.Resp.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	req := c.Req
+	req.URL.Path = strings.TrimPrefix(req.URL.Path, "/k8s")
+	if req.URL.Path == "" {
+		req.URL.Path = "/"
+	}
+	signedInUser := c.SignedInUser
+
+	for name := range req.Header {
+		if strings.HasPrefix(http.CanonicalHeaderKey(name), "X-Remote-") {
+			req.Header.Del(name)
+		}
+	}
+	req.Header.Set(proxyTokenHeader, s.proxyToken)
+	req.Header.Set("X-Remote-User", strconv.FormatInt(signedInUser.UserID, 10))
+	req.Header.Set("X-Remote-Group", "grafana")
+	req.Header.Set("X-Remote-Extra-token-name", signedInUser.Name)
+	req.Header.Set("X-Remote-Extra-login", signedInUser.Login)
+	req.Header.Set("X-Remote-Extra-org-role", string(signedInUser.OrgRole))
+	req.Header.Set("X-Remote-Extra-org-id", strconv.FormatInt(signedInUser.OrgID, 10))
+	req.Header.Set("X-Remote-Extra-user-id", strconv.FormatInt(signedInUser.UserID, 10))
+	req.Header.Set("X-Remote-Extra-grafana-admin", strconv.FormatBool(signedInUser.IsGrafanaAdmin))
+	for _, teamID := range signedInUser.Teams {
+		req.Header.Add("X-Remote-Extra-team-id", strconv.FormatInt(teamID, 10))
+	}
+
+	resp := responsewriter.WrapForHTTP1Or2(c.Resp)
+	s.handler.ServeHTTP(resp, req)
+}
+
 func (s *service) writeKubeConfiguration(restConfig *rest.Config) error {
 	clusters := make(map[string]*clientcmdapi.Cluster)
 	clusters["default-cluster"] = &clientcmdapi.Cluster{
@@ -222,7 +319,9 @@ func (s *service) writeKubeConfiguration(restConfig *rest.Config) error {
 	return clientcmd.WriteToFile(clientConfig, path.Join(s.dataPath, "grafana.kubeconfig"))
 }
 
-func newAuthenticator(cert *x509.Certificate) (authenticator.Request, error) {
+// newAuthenticator authenticates the user sent in the headers of the requests with the proxy token. The other
+// requests are not authenticated by it, the in-process clients use the loopback token of the apiserver instead.
+func newAuthenticator(proxyToken string) (authenticator.Request, error) {
 	reqHeaderOptions := options.RequestHeaderAuthenticationOptions{
 		UsernameHeaders:     []string{"X-Remote-User"},
 		GroupHeaders:        []string{"X-Remote-Group"},
@@ -238,5 +337,12 @@ func newAuthenticator(cert *x509.Certificate) (authenticator.Request, error) {
 		return nil, err
 	}
 
-	return requestHeaderAuthenticator, nil
+	return authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+		token := req.Header.Get(proxyTokenHeader)
+		req.Header.Del(proxyTokenHeader)
+		if token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(proxyToken)) != 1 {
+			return nil, false, nil
+		}
+		return requestHeaderAuthenticator.AuthenticateRequest(req)
+	}), nil
 }
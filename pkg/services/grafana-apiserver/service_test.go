@@ -0,0 +1,44 @@
+package grafanaapiserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticator(t *testing.T) {
+	a, err := newAuthenticator("token")
+	require.NoError(t, err)
+
+	newRequest := func(token string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/apis", nil)
+		req.Header.Set("X-Remote-User", "1")
+		req.Header.Set("X-Remote-Group", "grafana")
+		if token != "" {
+			req.Header.Set(proxyTokenHeader, token)
+		}
+		return req
+	}
+
+	t.Run("does not trust the user headers without the proxy token", func(t *testing.T) {
+		_, ok, err := a.AuthenticateRequest(newRequest(""))
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		_, ok, err = a.AuthenticateRequest(newRequest("other"))
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("authenticates the user of the proxied requests", func(t *testing.T) {
+		req := newRequest("token")
+		rsp, ok, err := a.AuthenticateRequest(req)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "1", rsp.User.GetName())
+		require.Equal(t, []string{"grafana"}, rsp.User.GetGroups())
+		require.Empty(t, req.Header.Get(proxyTokenHeader))
+	})
+}
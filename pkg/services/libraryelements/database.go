@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
@@ -171,7 +172,7 @@ func (l *LibraryElementService) createLibraryElement(c context.Context, signedIn
 			}
 			return err
 		}
-		return nil
+		return l.emitEntityEvent(session, element.OrgID, element.UID, element.Kind, store.EntityEventTypeCreate)
 	})
 
 	dto := model.LibraryElementDTO{
@@ -243,7 +244,7 @@ func (l *LibraryElementService) deleteLibraryElement(c context.Context, signedIn
 		}
 
 		elementID = element.ID
-		return nil
+		return l.emitEntityEvent(session, element.OrgID, element.UID, element.Kind, store.EntityEventTypeDelete)
 	})
 	return elementID, err
 }
@@ -580,6 +581,14 @@ func (l *LibraryElementService) patchLibraryElement(c context.Context, signedInU
 		} else if rowsAffected != 1 {
 			return model.ErrLibraryElementNotFound
 		}
+		if libraryElement.UID != uid {
+			if err := l.emitEntityEvent(session, libraryElement.OrgID, uid, libraryElement.Kind, store.EntityEventTypeDelete); err != nil {
+				return err
+			}
+		}
+		if err := l.emitEntityEvent(session, libraryElement.OrgID, libraryElement.UID, libraryElement.Kind, store.EntityEventTypeUpdate); err != nil {
+			return err
+		}
 
 		dto = model.LibraryElementDTO{
 			ID:          libraryElement.ID,
@@ -814,9 +823,11 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Conte
 		}
 
 		var elementIDs []struct {
-			ID int64 `xorm:"id"`
+			ID   int64  `xorm:"id"`
+			UID  string `xorm:"uid"`
+			Kind int64  `xorm:"kind"`
 		}
-		err = session.SQL("SELECT id from library_element WHERE folder_id=? AND org_id=?", folderID, signedInUser.GetOrgID()).Find(&elementIDs)
+		err = session.SQL("SELECT id, uid, kind from library_element WHERE folder_id=? AND org_id=?", folderID, signedInUser.GetOrgID()).Find(&elementIDs)
 		if err != nil {
 			return err
 		}
@@ -825,6 +836,9 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Conte
 			if err != nil {
 				return err
 			}
+			if err := l.emitEntityEvent(session, signedInUser.GetOrgID(), elementID.UID, elementID.Kind, store.EntityEventTypeDelete); err != nil {
+				return err
+			}
 		}
 		if _, err := session.Exec("DELETE FROM library_element WHERE folder_id=? AND org_id=?", folderID, signedInUser.GetOrgID()); err != nil {
 			return err
@@ -833,3 +847,11 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Conte
 		return nil
 	})
 }
+
+// emitEntityEvent saves the change of a library panel as an entity event, to update the search index.
+func (l *LibraryElementService) emitEntityEvent(session *db.Session, orgID int64, uid string, kind int64, eventType store.EntityEventType) error {
+	if kind != int64(model.PanelElement) || l.features == nil || !l.features.IsEnabled(featuremgmt.FlagPanelTitleSearch) {
+		return nil
+	}
+	return store.InsertEntityEvent(session, uid, orgID, store.EntityTypeLibraryPanel, eventType)
+}
@@ -287,6 +287,23 @@ func (fkv *FakeKVStore) GetAll(ctx context.Context, orgId int64, namespace strin
 	return nil, nil
 }
 
+func (fkv *FakeKVStore) SetWithTTL(ctx context.Context, orgId int64, namespace string, key string, value string, _ time.Duration) error {
+	return fkv.Set(ctx, orgId, namespace, key, value)
+}
+
+func (fkv *FakeKVStore) GetWithVersion(ctx context.Context, orgId int64, namespace string, key string) (string, int64, bool, error) {
+	v, ok, err := fkv.Get(ctx, orgId, namespace, key)
+	return v, 0, ok, err
+}
+
+func (fkv *FakeKVStore) CompareAndSwap(ctx context.Context, orgId int64, namespace string, key string, version int64, value string, _ time.Duration) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (fkv *FakeKVStore) Watch(ctx context.Context, orgId int64, namespace string, keyPrefix string) (<-chan kvstore.Change, error) {
+	return nil, errors.New("not implemented")
+}
+
 type fakeState struct {
 	data string
 }
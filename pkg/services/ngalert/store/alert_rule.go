@@ -10,11 +10,13 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/search/model"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
+	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/store/entity"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/util"
@@ -65,10 +67,23 @@ func (st DBstore) DeleteAlertRulesByUID(ctx context.Context, orgID int64, ruleUI
 			return err
 		}
 		logger.Debug("Deleted alert instances", "count", rows)
+
+		if st.emitEntityEvent() {
+			for _, uid := range ruleUID {
+				if err := store.InsertEntityEvent(sess, uid, orgID, store.EntityTypeAlertRule, store.EntityEventTypeDelete); err != nil {
+					return err
+				}
+			}
+		}
 		return nil
 	})
 }
 
+// emitEntityEvent returns whether the changes of the rules are saved as entity events, to update the search index.
+func (st DBstore) emitEntityEvent() bool {
+	return st.FeatureToggles != nil && st.FeatureToggles.IsEnabled(featuremgmt.FlagPanelTitleSearch)
+}
+
 // IncreaseVersionForAllRulesInNamespace Increases version for all rules that have specified namespace. Returns all rules that belong to the namespace
 func (st DBstore) IncreaseVersionForAllRulesInNamespace(ctx context.Context, orgID int64, namespaceUID string) ([]ngmodels.AlertRuleKeyWithVersionAndPauseStatus, error) {
 	var keys []ngmodels.AlertRuleKeyWithVersionAndPauseStatus
@@ -167,6 +182,11 @@ func (st DBstore) InsertAlertRules(ctx context.Context, rules []ngmodels.AlertRu
 					return fmt.Errorf("failed to create new rules: %w", err)
 				}
 				ids[newRules[i].UID] = newRules[i].ID
+				if st.emitEntityEvent() {
+					if err := store.InsertEntityEvent(sess, newRules[i].UID, newRules[i].OrgID, store.EntityTypeAlertRule, store.EntityEventTypeCreate); err != nil {
+						return err
+					}
+				}
 			}
 		}
 
@@ -208,6 +228,11 @@ func (st DBstore) UpdateAlertRules(ctx context.Context, rules []ngmodels.UpdateR
 				}
 				return fmt.Errorf("%w: alert rule UID %s version %d", ErrOptimisticLock, r.New.UID, r.New.Version)
 			}
+			if st.emitEntityEvent() {
+				if err := store.InsertEntityEvent(sess, r.New.UID, r.New.OrgID, store.EntityTypeAlertRule, store.EntityEventTypeUpdate); err != nil {
+					return err
+				}
+			}
 			parentVersion = r.Existing.Version
 			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
 				RuleOrgID:        r.New.OrgID,
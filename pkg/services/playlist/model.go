@@ -1,7 +1,14 @@
 package playlist
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/kinds/playlist"
 )
@@ -11,6 +18,8 @@ var (
 	ErrPlaylistNotFound                = errors.New("Playlist not found")
 	ErrPlaylistFailedGenerateUniqueUid = errors.New("failed to generate unique playlist UID")
 	ErrCommandValidationFailed         = errors.New("command missing required fields")
+	ErrInvalidItemInterval             = errors.New("invalid playlist item interval")
+	ErrInvalidItemSearch               = errors.New("invalid playlist item search")
 )
 
 // Playlist model
@@ -26,13 +35,115 @@ type PlaylistDTO = playlist.Spec
 type PlaylistItemDTO = playlist.Item
 type PlaylistItemType = playlist.ItemType
 
+const (
+	ItemTypeDashboardByID     = playlist.ItemTypeDashboardById
+	ItemTypeDashboardByTag    = playlist.ItemTypeDashboardByTag
+	ItemTypeDashboardByUID    = playlist.ItemTypeDashboardByUid
+	ItemTypeDashboardBySearch = playlist.ItemTypeDashboardBySearch
+)
+
 type PlaylistItem struct {
-	Id         int64  `db:"id"`
-	PlaylistId int64  `db:"playlist_id"`
-	Type       string `json:"type" db:"type"`
-	Value      string `json:"value" db:"value"`
-	Order      int    `json:"order" db:"order"`
-	Title      string `json:"title" db:"title"`
+	Id         int64                 `db:"id"`
+	PlaylistId int64                 `db:"playlist_id"`
+	Type       string                `json:"type" db:"type"`
+	Value      string                `json:"value" db:"value"`
+	Order      int                   `json:"order" db:"order"`
+	Title      string                `json:"title" db:"title"`
+	Interval   string                `json:"interval,omitempty" db:"interval"`
+	TimeFrom   string                `json:"timeFrom,omitempty" db:"time_from"`
+	TimeTo     string                `json:"timeTo,omitempty" db:"time_to"`
+	Variables  PlaylistItemVariables `json:"variables,omitempty" db:"variables"`
+}
+
+// Validate checks the interval of the item, and the search of dashboard_by_search items.
+func (i PlaylistItem) Validate() error {
+	if i.Interval != "" {
+		if _, err := gtime.ParseDuration(i.Interval); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidItemInterval, i.Interval)
+		}
+	}
+	if i.Type == string(ItemTypeDashboardBySearch) {
+		if _, err := ParseItemSearch(i.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PlaylistItemVariables are the template variable values of a playlist item, stored as JSON.
+type PlaylistItemVariables map[string]string
+
+func (v *PlaylistItemVariables) FromDB(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (v *PlaylistItemVariables) Scan(val any) error {
+	switch data := val.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return v.FromDB(data)
+	case string:
+		return v.FromDB([]byte(data))
+	default:
+		return fmt.Errorf("unsupported type: %T", data)
+	}
+}
+
+func (v PlaylistItemVariables) Value() (driver.Value, error) {
+	b, err := v.ToDB()
+	if b == nil || err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (v PlaylistItemVariables) ToDB() ([]byte, error) {
+	if len(v) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// ItemSearch is the search of a dashboard_by_search item. The dashboards are searched when the playlist is played.
+type ItemSearch struct {
+	Query      string
+	FolderUIDs []string
+	Tags       []string
+	Starred    bool
+}
+
+// ParseItemSearch parses the value of a dashboard_by_search item, a search query with the folderUIDs, tag, starred
+// and query parameters of the search API.
+func ParseItemSearch(value string) (ItemSearch, error) {
+	params, err := url.ParseQuery(value)
+	if err != nil {
+		return ItemSearch{}, fmt.Errorf("%w: %s", ErrInvalidItemSearch, err.Error())
+	}
+
+	search := ItemSearch{Query: params.Get("query"), Tags: params["tag"]}
+	for _, uids := range params["folderUIDs"] {
+		for _, uid := range strings.Split(uids, ",") {
+			if uid = strings.TrimSpace(uid); uid != "" {
+				search.FolderUIDs = append(search.FolderUIDs, uid)
+			}
+		}
+	}
+	switch params.Get("starred") {
+	case "", "false":
+	case "true":
+		search.Starred = true
+	default:
+		return ItemSearch{}, fmt.Errorf("%w: invalid starred value %q", ErrInvalidItemSearch, params.Get("starred"))
+	}
+
+	if search.Query == "" && len(search.FolderUIDs) == 0 && len(search.Tags) == 0 && !search.Starred {
+		return ItemSearch{}, fmt.Errorf("%w: no folder, tag, starred or query filter", ErrInvalidItemSearch)
+	}
+	return search, nil
 }
 
 type Playlists []*Playlist
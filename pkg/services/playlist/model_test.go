@@ -32,7 +32,7 @@ func TestPlaylistConversion(t *testing.T) {
 	require.NoError(t, err)
 	fmt.Printf("%s", string(out))
 	require.JSONEq(t, `{
-		"apiVersion": "v0-0-alpha",
+		"apiVersion": "v0-1-alpha",
 		"kind": "Playlist",
 		"metadata": {
 		  "name": "abc",
@@ -62,3 +62,27 @@ func TestPlaylistConversion(t *testing.T) {
 		}
 	  }`, string(out))
 }
+
+func TestParseItemSearch(t *testing.T) {
+	search, err := ParseItemSearch("folderUIDs=abc,def&folderUIDs=ghi&tag=prod&tag=web&starred=true&query=api")
+	require.NoError(t, err)
+	require.Equal(t, ItemSearch{
+		Query:      "api",
+		FolderUIDs: []string{"abc", "def", "ghi"},
+		Tags:       []string{"prod", "web"},
+		Starred:    true,
+	}, search)
+
+	_, err = ParseItemSearch("starred=yes")
+	require.ErrorIs(t, err, ErrInvalidItemSearch)
+
+	_, err = ParseItemSearch("")
+	require.ErrorIs(t, err, ErrInvalidItemSearch)
+}
+
+func TestValidatePlaylistItem(t *testing.T) {
+	require.NoError(t, PlaylistItem{Type: "dashboard_by_uid", Value: "abc", Interval: "1m"}.Validate())
+	require.NoError(t, PlaylistItem{Type: "dashboard_by_search", Value: "starred=true"}.Validate())
+	require.ErrorIs(t, PlaylistItem{Type: "dashboard_by_uid", Value: "abc", Interval: "soon"}.Validate(), ErrInvalidItemInterval)
+	require.ErrorIs(t, PlaylistItem{Type: "dashboard_by_search", Value: "%zz"}.Validate(), ErrInvalidItemSearch)
+}
@@ -67,7 +67,7 @@ func (s *entityStoreImpl) sync() {
 }
 
 func (s *entityStoreImpl) Create(ctx context.Context, cmd *playlist.CreatePlaylistCommand) (*playlist.Playlist, error) {
-	rsp, err := s.sqlimpl.store.Insert(ctx, cmd)
+	rsp, err := s.sqlimpl.Create(ctx, cmd)
 	if err == nil && rsp != nil {
 		body, err := json.Marshal(cmd)
 		if err != nil {
@@ -88,7 +88,7 @@ func (s *entityStoreImpl) Create(ctx context.Context, cmd *playlist.CreatePlayli
 }
 
 func (s *entityStoreImpl) Update(ctx context.Context, cmd *playlist.UpdatePlaylistCommand) (*playlist.PlaylistDTO, error) {
-	rsp, err := s.sqlimpl.store.Update(ctx, cmd)
+	rsp, err := s.sqlimpl.Update(ctx, cmd)
 	if err == nil {
 		body, err := json.Marshal(cmd)
 		if err != nil {
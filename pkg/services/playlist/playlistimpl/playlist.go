@@ -21,11 +21,13 @@ func ProvideService(db db.DB, toggles featuremgmt.FeatureToggles, objserver enti
 	// 🐢🐢🐢 pick the store
 	if toggles.IsEnabled(featuremgmt.FlagNewDBLibrary) { // hymmm not a registered feature flag
 		sqlstore = &sqlxStore{
-			sess: db.GetSqlxSession(),
+			sess:     db.GetSqlxSession(),
+			features: toggles,
 		}
 	} else {
 		sqlstore = &sqlStore{
-			db: db,
+			db:       db,
+			features: toggles,
 		}
 	}
 	svc := &Service{store: sqlstore}
@@ -45,10 +47,16 @@ func ProvideService(db db.DB, toggles featuremgmt.FeatureToggles, objserver enti
 }
 
 func (s *Service) Create(ctx context.Context, cmd *playlist.CreatePlaylistCommand) (*playlist.Playlist, error) {
+	if err := validateItems(cmd.Items); err != nil {
+		return nil, err
+	}
 	return s.store.Insert(ctx, cmd)
 }
 
 func (s *Service) Update(ctx context.Context, cmd *playlist.UpdatePlaylistCommand) (*playlist.PlaylistDTO, error) {
+	if err := validateItems(cmd.Items); err != nil {
+		return nil, err
+	}
 	return s.store.Update(ctx, cmd)
 }
 
@@ -78,6 +86,19 @@ func (s *Service) Get(ctx context.Context, q *playlist.GetPlaylistByUidQuery) (*
 		if title != "" {
 			items[i].Title = &title
 		}
+
+		if rawItems[i].Interval != "" {
+			items[i].Interval = &rawItems[i].Interval
+		}
+		if rawItems[i].TimeFrom != "" {
+			items[i].TimeFrom = &rawItems[i].TimeFrom
+		}
+		if rawItems[i].TimeTo != "" {
+			items[i].TimeTo = &rawItems[i].TimeTo
+		}
+		if len(rawItems[i].Variables) > 0 {
+			items[i].Variables = rawItems[i].Variables
+		}
 	}
 	return &playlist.PlaylistDTO{
 		Uid:      v.UID,
@@ -94,3 +115,12 @@ func (s *Service) Search(ctx context.Context, q *playlist.GetPlaylistsQuery) (pl
 func (s *Service) Delete(ctx context.Context, cmd *playlist.DeletePlaylistCommand) error {
 	return s.store.Delete(ctx, cmd)
 }
+
+func validateItems(items []playlist.PlaylistItem) error {
+	for _, item := range items {
+		if err := item.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,14 +4,28 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/playlist"
 	"github.com/grafana/grafana/pkg/services/sqlstore/session"
 	"github.com/grafana/grafana/pkg/services/star"
+	storesrv "github.com/grafana/grafana/pkg/services/store"
 )
 
 type sqlxStore struct {
-	sess *session.SessionDB
+	sess     *session.SessionDB
+	features featuremgmt.FeatureToggles
+}
+
+// emitEntityEvent saves the change of a playlist as an entity event, to update the search index.
+func (s *sqlxStore) emitEntityEvent(ctx context.Context, tx *session.SessionTx, uid string, orgId int64, eventType storesrv.EntityEventType) error {
+	if s.features == nil || !s.features.IsEnabled(featuremgmt.FlagPanelTitleSearch) {
+		return nil
+	}
+	_, err := tx.Exec(ctx, "INSERT INTO entity_event (event_type, entity_id, created) VALUES (?, ?, ?)",
+		eventType, storesrv.CreateDatabaseEntityId(uid, orgId, storesrv.EntityTypePlaylist), time.Now().Unix())
+	return err
 }
 
 func (s *sqlxStore) Insert(ctx context.Context, cmd *playlist.CreatePlaylistCommand) (*playlist.Playlist, error) {
@@ -46,15 +60,19 @@ func (s *sqlxStore) Insert(ctx context.Context, cmd *playlist.CreatePlaylistComm
 					Value:      item.Value,
 					Order:      order + 1,
 					Title:      item.Title,
+					Interval:   item.Interval,
+					TimeFrom:   item.TimeFrom,
+					TimeTo:     item.TimeTo,
+					Variables:  item.Variables,
 				})
 			}
-			query := `INSERT INTO playlist_item (playlist_id, type, value, title, "order") VALUES (:playlist_id, :type, :value, :title, :order)`
+			query := `INSERT INTO playlist_item (playlist_id, type, value, title, "order", "interval", time_from, time_to, variables) VALUES (:playlist_id, :type, :value, :title, :order, :interval, :time_from, :time_to, :variables)`
 			_, err = tx.NamedExec(ctx, query, playlistItems)
 			if err != nil {
 				return err
 			}
 		}
-		return nil
+		return s.emitEntityEvent(ctx, tx, p.UID, p.OrgId, storesrv.EntityEventTypeCreate)
 	})
 
 	return &p, err
@@ -98,11 +116,18 @@ func (s *sqlxStore) Update(ctx context.Context, cmd *playlist.UpdatePlaylistComm
 				Value:      item.Value,
 				Order:      index + 1,
 				Title:      item.Title,
+				Interval:   item.Interval,
+				TimeFrom:   item.TimeFrom,
+				TimeTo:     item.TimeTo,
+				Variables:  item.Variables,
 			})
 		}
-		query = `INSERT INTO playlist_item (playlist_id, type, value, title, "order") VALUES (:playlist_id, :type, :value, :title, :order)`
+		query = `INSERT INTO playlist_item (playlist_id, type, value, title, "order", "interval", time_from, time_to, variables) VALUES (:playlist_id, :type, :value, :title, :order, :interval, :time_from, :time_to, :variables)`
 		_, err = tx.NamedExec(ctx, query, playlistItems)
-		return err
+		if err != nil {
+			return err
+		}
+		return s.emitEntityEvent(ctx, tx, p.UID, p.OrgId, storesrv.EntityEventTypeUpdate)
 	})
 
 	return &dto, err
@@ -145,7 +170,7 @@ func (s *sqlxStore) Delete(ctx context.Context, cmd *playlist.DeletePlaylistComm
 		if _, err := tx.Exec(ctx, "DELETE FROM playlist_item WHERE playlist_id = ?", p.Id); err != nil {
 			return err
 		}
-		return nil
+		return s.emitEntityEvent(ctx, tx, cmd.UID, cmd.OrgId, storesrv.EntityEventTypeDelete)
 	})
 
 	return err
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 )
 
 func TestIntegrationSQLxPlaylistDataAccess(t *testing.T) {
@@ -14,3 +15,12 @@ func TestIntegrationSQLxPlaylistDataAccess(t *testing.T) {
 		return &sqlxStore{sess: ss.GetSqlxSession()}
 	})
 }
+
+func TestIntegrationSQLxPlaylistEntityEvents(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testIntegrationPlaylistEntityEvents(t, func(ss db.DB, features featuremgmt.FeatureToggles) store {
+		return &sqlxStore{sess: ss.GetSqlxSession(), features: features}
+	})
+}
@@ -2,16 +2,21 @@ package playlistimpl
 
 import (
 	"context"
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/playlist"
+	storesrv "github.com/grafana/grafana/pkg/services/store"
 )
 
 type getStore func(db.DB) store
 
+type getStoreWithFeatures func(db.DB, featuremgmt.FeatureToggles) store
+
 func testIntegrationPlaylistDataAccess(t *testing.T, fn getStore) {
 	t.Helper()
 
@@ -64,6 +69,34 @@ func testIntegrationPlaylistDataAccess(t *testing.T, fn getStore) {
 		})
 	})
 
+	t.Run("Can create playlist with item overrides", func(t *testing.T) {
+		items := []playlist.PlaylistItem{
+			{Value: "folderUIDs=abc&tag=prod", Type: "dashboard_by_search", Interval: "1m", TimeFrom: "now-1h", TimeTo: "now",
+				Variables: playlist.PlaylistItemVariables{"env": "prod"}},
+			{Value: "dash", Type: "dashboard_by_uid"},
+		}
+		cmd := playlist.CreatePlaylistCommand{Name: "Kiosk", Interval: "5m", OrgId: 1, Items: items}
+		p, err := playlistStore.Insert(context.Background(), &cmd)
+		require.NoError(t, err)
+
+		get := &playlist.GetPlaylistItemsByUidQuery{PlaylistUID: p.UID, OrgId: 1}
+		storedPlaylistItems, err := playlistStore.GetItems(context.Background(), get)
+		require.NoError(t, err)
+		require.Len(t, storedPlaylistItems, 2)
+		sort.Slice(storedPlaylistItems, func(i, j int) bool { return storedPlaylistItems[i].Order < storedPlaylistItems[j].Order })
+
+		stored := storedPlaylistItems[0]
+		require.Equal(t, "1m", stored.Interval)
+		require.Equal(t, "now-1h", stored.TimeFrom)
+		require.Equal(t, "now", stored.TimeTo)
+		require.Equal(t, playlist.PlaylistItemVariables{"env": "prod"}, stored.Variables)
+		require.Empty(t, storedPlaylistItems[1].Interval)
+		require.Empty(t, storedPlaylistItems[1].Variables)
+
+		err = playlistStore.Delete(context.Background(), &playlist.DeletePlaylistCommand{UID: p.UID, OrgId: 1})
+		require.NoError(t, err)
+	})
+
 	t.Run("Search playlist", func(t *testing.T) {
 		items := []playlist.PlaylistItem{
 			{Title: "graphite", Value: "graphite", Type: "dashboard_by_tag"},
@@ -124,3 +157,34 @@ func testIntegrationPlaylistDataAccess(t *testing.T, fn getStore) {
 		}
 	})
 }
+
+func testIntegrationPlaylistEntityEvents(t *testing.T, fn getStoreWithFeatures) {
+	t.Helper()
+
+	ss := db.InitTestDB(t)
+	playlistStore := fn(ss, featuremgmt.WithFeatures(featuremgmt.FlagPanelTitleSearch))
+
+	items := []playlist.PlaylistItem{{Title: "graphite", Value: "graphite", Type: "dashboard_by_tag"}}
+	cmd := playlist.CreatePlaylistCommand{Name: "NYC office", Interval: "10m", OrgId: 1, Items: items}
+	p, err := playlistStore.Insert(context.Background(), &cmd)
+	require.NoError(t, err)
+
+	_, err = playlistStore.Update(context.Background(), &playlist.UpdatePlaylistCommand{UID: p.UID, OrgId: 1, Name: "LA office", Interval: "5m", Items: items})
+	require.NoError(t, err)
+
+	err = playlistStore.Delete(context.Background(), &playlist.DeletePlaylistCommand{UID: p.UID, OrgId: 1})
+	require.NoError(t, err)
+
+	var events []*storesrv.EntityEvent
+	err = ss.WithDbSession(context.Background(), func(sess *db.Session) error {
+		return sess.OrderBy("id").Find(&events)
+	})
+	require.NoError(t, err)
+
+	entityID := storesrv.CreateDatabaseEntityId(p.UID, 1, storesrv.EntityTypePlaylist)
+	require.Len(t, events, 3)
+	for i, eventType := range []storesrv.EntityEventType{storesrv.EntityEventTypeCreate, storesrv.EntityEventTypeUpdate, storesrv.EntityEventTypeDelete} {
+		require.Equal(t, entityID, events[i].EntityId)
+		require.Equal(t, eventType, events[i].EventType)
+	}
+}
@@ -4,13 +4,24 @@ import (
 	"context"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/playlist"
 	"github.com/grafana/grafana/pkg/services/star"
+	storesrv "github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/util"
 )
 
 type sqlStore struct {
-	db db.DB
+	db       db.DB
+	features featuremgmt.FeatureToggles
+}
+
+// emitEntityEvent saves the change of a playlist as an entity event, to update the search index.
+func (s *sqlStore) emitEntityEvent(sess *db.Session, uid string, orgId int64, eventType storesrv.EntityEventType) error {
+	if s.features == nil || !s.features.IsEnabled(featuremgmt.FlagPanelTitleSearch) {
+		return nil
+	}
+	return storesrv.InsertEntityEvent(sess, uid, orgId, storesrv.EntityTypePlaylist, eventType)
 }
 
 func (s *sqlStore) Insert(ctx context.Context, cmd *playlist.CreatePlaylistCommand) (*playlist.Playlist, error) {
@@ -41,12 +52,19 @@ func (s *sqlStore) Insert(ctx context.Context, cmd *playlist.CreatePlaylistComma
 				Value:      item.Value,
 				Order:      order + 1,
 				Title:      item.Title,
+				Interval:   item.Interval,
+				TimeFrom:   item.TimeFrom,
+				TimeTo:     item.TimeTo,
+				Variables:  item.Variables,
 			})
 		}
 
 		_, err = sess.Insert(&playlistItems)
+		if err != nil {
+			return err
+		}
 
-		return err
+		return s.emitEntityEvent(sess, p.UID, p.OrgId, storesrv.EntityEventTypeCreate)
 	})
 	return &p, err
 }
@@ -95,11 +113,19 @@ func (s *sqlStore) Update(ctx context.Context, cmd *playlist.UpdatePlaylistComma
 				Value:      item.Value,
 				Order:      index + 1,
 				Title:      item.Title,
+				Interval:   item.Interval,
+				TimeFrom:   item.TimeFrom,
+				TimeTo:     item.TimeTo,
+				Variables:  item.Variables,
 			})
 		}
 
 		_, err = sess.Insert(&playlistItems)
-		return err
+		if err != nil {
+			return err
+		}
+
+		return s.emitEntityEvent(sess, p.UID, p.OrgId, storesrv.EntityEventTypeUpdate)
 	})
 	return &dto, err
 }
@@ -142,8 +168,11 @@ func (s *sqlStore) Delete(ctx context.Context, cmd *playlist.DeletePlaylistComma
 
 		var rawItemSQL = "DELETE FROM playlist_item WHERE playlist_id = ?"
 		_, err = sess.Exec(rawItemSQL, playlist.Id)
+		if err != nil {
+			return err
+		}
 
-		return err
+		return s.emitEntityEvent(sess, cmd.UID, cmd.OrgId, storesrv.EntityEventTypeDelete)
 	})
 }
 
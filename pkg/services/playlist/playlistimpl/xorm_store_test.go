@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
 )
 
 func TestIntegrationXormPlaylistDataAccess(t *testing.T) {
@@ -14,3 +15,12 @@ func TestIntegrationXormPlaylistDataAccess(t *testing.T) {
 		return &sqlStore{db: ss}
 	})
 }
+
+func TestIntegrationXormPlaylistEntityEvents(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	testIntegrationPlaylistEntityEvents(t, func(ss db.DB, features featuremgmt.FeatureToggles) store {
+		return &sqlStore{db: ss, features: features}
+	})
+}
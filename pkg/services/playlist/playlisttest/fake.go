@@ -6,6 +6,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/playlist"
 )
 
+var _ playlist.Service = &FakePlaylistService{}
+
 type FakePlaylistService struct {
 	ExpectedPlaylist      *playlist.Playlist
 	ExpectedPlaylistDTO   *playlist.PlaylistDTO
@@ -26,10 +28,14 @@ func (f *FakePlaylistService) Update(context.Context, *playlist.UpdatePlaylistCo
 	return f.ExpectedPlaylistDTO, f.ExpectedError
 }
 
-func (f *FakePlaylistService) Get(context.Context, *playlist.GetPlaylistByUidQuery) (*playlist.Playlist, error) {
+func (f *FakePlaylistService) GetWithoutItems(context.Context, *playlist.GetPlaylistByUidQuery) (*playlist.Playlist, error) {
 	return f.ExpectedPlaylist, f.ExpectedError
 }
 
+func (f *FakePlaylistService) Get(context.Context, *playlist.GetPlaylistByUidQuery) (*playlist.PlaylistDTO, error) {
+	return f.ExpectedPlaylistDTO, f.ExpectedError
+}
+
 func (f *FakePlaylistService) GetItems(context.Context, *playlist.GetPlaylistItemsByUidQuery) ([]playlist.PlaylistItem, error) {
 	return f.ExpectedPlaylistItems, f.ExpectedError
 }
@@ -2,6 +2,7 @@ package dashboards
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -11,10 +12,13 @@ import (
 	"sync"
 	"time"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/foldersettings"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -34,6 +38,8 @@ type FileReader struct {
 	dashboardProvisioningService dashboards.DashboardProvisioningService
 	dashboardStore               utils.DashboardStore
 	FoldersFromFilesStructure    bool
+	folderSettingsService        foldersettings.Service
+	folderSettings               *foldersettings.SaveFolderSettingsCommand
 
 	mux                     sync.RWMutex
 	usageTracker            *usageTracker
@@ -58,6 +64,14 @@ func NewDashboardFileReader(cfg *config, log log.Logger, service dashboards.Dash
 		return nil, fmt.Errorf("'folder' and 'folderUID' should be empty using 'foldersFromFilesStructure' option")
 	}
 
+	folderSettings, err := readFolderSettings(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if foldersFromFilesStructure && folderSettings != nil {
+		return nil, fmt.Errorf("'folderSettings' cannot be used with the 'foldersFromFilesStructure' option")
+	}
+
 	return &FileReader{
 		Cfg:                          cfg,
 		Path:                         path,
@@ -65,6 +79,7 @@ func NewDashboardFileReader(cfg *config, log log.Logger, service dashboards.Dash
 		dashboardProvisioningService: service,
 		dashboardStore:               dashboardStore,
 		FoldersFromFilesStructure:    foldersFromFilesStructure,
+		folderSettings:               folderSettings,
 		usageTracker:                 newUsageTracker(),
 	}, nil
 }
@@ -145,6 +160,11 @@ func (fr *FileReader) storeDashboardsInFolder(ctx context.Context, filesFoundOnD
 		return err
 	}
 
+	// save the folder settings first so that they apply to the dashboards created in the folder
+	if err := fr.saveFolderSettings(ctx, folderID); err != nil {
+		return err
+	}
+
 	// save dashboards based on json files
 	for path, fileInfo := range filesFoundOnDisk {
 		provisioningMetadata, err := fr.saveDashboard(ctx, path, folderID, fileInfo, dashboardRefs)
@@ -256,6 +276,9 @@ func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID i
 
 	if alreadyProvisioned {
 		dash.Dashboard.SetID(provisionedData.DashboardID)
+		if err := fr.addRequiredTags(ctx, dash.Dashboard, folderID); err != nil {
+			return provisioningMetadata, err
+		}
 	}
 
 	if !fr.isDatabaseAccessRestricted() {
@@ -336,6 +359,94 @@ func (fr *FileReader) getOrCreateFolderID(ctx context.Context, cfg *config, serv
 	return result.ID, nil
 }
 
+// readFolderSettings reads the settings of the provider folder from the config.
+func readFolderSettings(cfg *config) (*foldersettings.SaveFolderSettingsCommand, error) {
+	if len(cfg.FolderSettings) == 0 {
+		return nil, nil
+	}
+
+	if cfg.Folder == "" {
+		return nil, fmt.Errorf("'folderSettings' requires the 'folder' option")
+	}
+
+	data, err := json.Marshal(cfg.FolderSettings)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &foldersettings.SaveFolderSettingsCommand{}
+	if err := json.Unmarshal(data, cmd); err != nil {
+		return nil, fmt.Errorf("failed to read 'folderSettings': %w", err)
+	}
+	if err := cmd.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid 'folderSettings': %w", err)
+	}
+	return cmd, nil
+}
+
+// saveFolderSettings saves the settings of the config on the provider folder. The settings are saved only when they
+// changed in the config since they were last saved, so that the changes made in the UI are kept until then.
+func (fr *FileReader) saveFolderSettings(ctx context.Context, folderID int64) error {
+	if fr.folderSettings == nil || fr.folderSettingsService == nil || folderID == 0 {
+		return nil
+	}
+
+	f, err := fr.dashboardStore.GetDashboard(ctx, &dashboards.GetDashboardQuery{ID: folderID, OrgID: fr.Cfg.OrgID})
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(fr.folderSettings)
+	if err != nil {
+		return err
+	}
+	checkSum, err := util.Md5SumString(string(data))
+	if err != nil {
+		return err
+	}
+	current, err := fr.folderSettingsService.Get(ctx, &foldersettings.GetFolderSettingsQuery{OrgID: fr.Cfg.OrgID, FolderUID: f.UID})
+	if err != nil {
+		return err
+	}
+	if current != nil && current.ProvisionedChecksum == checkSum {
+		return nil
+	}
+
+	cmd := *fr.folderSettings
+	cmd.OrgID = fr.Cfg.OrgID
+	cmd.FolderUID = f.UID
+	cmd.ProvisionedChecksum = checkSum
+	if _, err := fr.folderSettingsService.Save(ctx, &cmd); err != nil {
+		return fmt.Errorf("failed to save folder settings: %w", err)
+	}
+	return nil
+}
+
+// addRequiredTags adds the required tags of the folder to an updated dashboard, the tags of the new dashboards are
+// added when they are created.
+func (fr *FileReader) addRequiredTags(ctx context.Context, dash *dashboards.Dashboard, folderID int64) error {
+	if fr.folderSettingsService == nil || folderID == 0 {
+		return nil
+	}
+
+	f, err := fr.dashboardStore.GetDashboard(ctx, &dashboards.GetDashboardQuery{ID: folderID, OrgID: fr.Cfg.OrgID})
+	if err != nil {
+		return err
+	}
+	resolved, err := fr.folderSettingsService.GetResolved(ctx, &foldersettings.GetFolderSettingsQuery{OrgID: fr.Cfg.OrgID, FolderUID: f.UID})
+	if err != nil || resolved == nil {
+		return err
+	}
+
+	tags := dash.Data.Get("tags").MustArray()
+	for _, tag := range resolved.RequiredTags {
+		if !slices.Contains(tags, any(tag)) {
+			tags = append(tags, tag)
+		}
+	}
+	dash.Data.Set("tags", tags)
+	return nil
+}
+
 func resolveSymlink(fileinfo os.FileInfo, path string) (os.FileInfo, error) {
 	checkFilepath, err := filepath.EvalSymlinks(path)
 	if path != checkFilepath {
@@ -14,6 +14,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/foldersettings"
+	"github.com/grafana/grafana/pkg/services/foldersettings/foldersettingstest"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -124,6 +126,52 @@ func TestDashboardFileReader(t *testing.T) {
 			require.NoError(t, err)
 		})
 
+		t.Run("Should save the folder settings before the dashboards", func(t *testing.T) {
+			setup()
+			cfg.Options["path"] = oneDashboard
+			cfg.Folder = "Team A"
+			cfg.FolderSettings = map[string]any{"defaultRefresh": "1m", "requiredTags": []any{"team-a"}}
+
+			fakeService.On("GetProvisionedDashboardData", mock.Anything, configName).Return(nil, nil).Once()
+			fakeService.On("SaveFolderForProvisionedDashboards", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 1}, nil).Once()
+			fakeService.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 2}, nil).Once()
+
+			folderSettingsService := foldersettingstest.NewFakeService()
+			reader, err := NewDashboardFileReader(cfg, logger, nil, &fakeFolderDashboardStore{folder: &dashboards.Dashboard{ID: 1, UID: "team-a", IsFolder: true}})
+			require.NoError(t, err)
+			reader.dashboardProvisioningService = fakeService
+			reader.folderSettingsService = folderSettingsService
+
+			err = reader.walkDisk(context.Background())
+			require.NoError(t, err)
+
+			require.Len(t, folderSettingsService.SavedCommands, 1)
+			saved := folderSettingsService.SavedCommands[0]
+			require.Equal(t, int64(1), saved.OrgID)
+			require.Equal(t, "team-a", saved.FolderUID)
+			require.Equal(t, "1m", saved.DefaultRefresh)
+			require.Equal(t, []string{"team-a"}, saved.RequiredTags)
+			require.NotEmpty(t, saved.ProvisionedChecksum)
+
+			// the settings are not saved again until they change in the config
+			folderSettingsService.ExpectedSettings = &foldersettings.FolderSettings{DefaultRefresh: "5m", ProvisionedChecksum: saved.ProvisionedChecksum}
+			fakeService.On("GetProvisionedDashboardData", mock.Anything, configName).Return(nil, nil).Once()
+			fakeService.On("SaveFolderForProvisionedDashboards", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 1}, nil).Once()
+			fakeService.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 2}, nil).Once()
+			require.NoError(t, reader.walkDisk(context.Background()))
+			require.Len(t, folderSettingsService.SavedCommands, 1)
+		})
+
+		t.Run("Invalid folder settings should return error", func(t *testing.T) {
+			setup()
+			cfg.Options["path"] = oneDashboard
+			cfg.Folder = "Team A"
+			cfg.FolderSettings = map[string]any{"timezone": "Mars/Olympus_Mons"}
+
+			_, err := NewDashboardFileReader(cfg, logger, nil, nil)
+			require.Error(t, err)
+		})
+
 		t.Run("Can read default dashboard and replace old version in database", func(t *testing.T) {
 			setup()
 			cfg.Options["path"] = oneDashboard
@@ -209,6 +257,40 @@ func TestDashboardFileReader(t *testing.T) {
 			require.NoError(t, err)
 		})
 
+		t.Run("Replaced dashboard keeps the required tags of the folder", func(t *testing.T) {
+			setup()
+			cfg.Options["path"] = oneDashboard
+			cfg.Folder = "Team A"
+			absPath, err := filepath.Abs(oneDashboard + "/dashboard1.json")
+			require.NoError(t, err)
+
+			provisionedDashboard := []*dashboards.DashboardProvisioning{
+				{Name: "Default", ExternalID: absPath, DashboardID: 2, CheckSum: "fakechecksum"},
+			}
+
+			var saved *dashboards.SaveDashboardDTO
+			fakeService.On("GetProvisionedDashboardData", mock.Anything, configName).Return(provisionedDashboard, nil).Once()
+			fakeService.On("SaveFolderForProvisionedDashboards", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 1}, nil).Once()
+			fakeService.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 2}, nil).Once().
+				Run(func(args mock.Arguments) {
+					saved = args.Get(1).(*dashboards.SaveDashboardDTO)
+				})
+
+			folderSettingsService := foldersettingstest.NewFakeService()
+			folderSettingsService.ExpectedResolvedSettings = &foldersettings.ResolvedFolderSettings{FolderUID: "team-a", RequiredTags: []string{"team-a"}}
+			reader, err := NewDashboardFileReader(cfg, logger, nil, &fakeFolderDashboardStore{folder: &dashboards.Dashboard{ID: 1, UID: "team-a", IsFolder: true}})
+			require.NoError(t, err)
+			reader.dashboardProvisioningService = fakeService
+			reader.folderSettingsService = folderSettingsService
+
+			err = reader.walkDisk(context.Background())
+			require.NoError(t, err)
+
+			require.NotNil(t, saved)
+			require.Equal(t, int64(2), saved.Dashboard.ID)
+			require.Contains(t, saved.Dashboard.Data.Get("tags").MustStringArray(), "team-a")
+		})
+
 		t.Run("Dashboard with newer timestamp and the same checksum will not replace imported dashboard", func(t *testing.T) {
 			setup()
 			cfg.Options["path"] = oneDashboard
@@ -515,3 +597,15 @@ type fakeDashboardStore struct{}
 func (fds *fakeDashboardStore) GetDashboard(_ context.Context, _ *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
 	return nil, dashboards.ErrDashboardNotFound
 }
+
+// fakeFolderDashboardStore finds the folder by ID only, so that the folder is created by title.
+type fakeFolderDashboardStore struct {
+	folder *dashboards.Dashboard
+}
+
+func (fds *fakeFolderDashboardStore) GetDashboard(_ context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+	if query.ID == fds.folder.ID {
+		return fds.folder, nil
+	}
+	return nil, dashboards.ErrDashboardNotFound
+}
@@ -0,0 +1,329 @@
+package datasources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+// ChangeType describes what provisioning would do to a single data source.
+type ChangeType string
+
+const (
+	ChangeTypeAdd    ChangeType = "add"
+	ChangeTypeUpdate ChangeType = "update"
+	ChangeTypeDelete ChangeType = "delete"
+)
+
+// PlanStore is the read only subset of Store needed to build a provisioning plan.
+type PlanStore interface {
+	GetDataSource(ctx context.Context, query *datasources.GetDataSourceQuery) (*datasources.DataSource, error)
+}
+
+// SecureValuesReader returns the secure fields currently stored for a data source.
+// Only the keys are used when building a plan, the values are never reported.
+type SecureValuesReader interface {
+	DecryptedValues(ctx context.Context, ds *datasources.DataSource) (map[string]string, error)
+}
+
+// FieldDiff is a single field that differs between the provisioning files and the database.
+type FieldDiff struct {
+	Field   string `json:"field"`
+	Current any    `json:"current"`
+	Desired any    `json:"desired"`
+}
+
+// Change is a single data source that would be added, updated or deleted by provisioning.
+type Change struct {
+	Type  ChangeType `json:"type"`
+	OrgID int64      `json:"orgId"`
+	Name  string     `json:"name"`
+	UID   string     `json:"uid,omitempty"`
+	// Version is the version currently stored in the database, zero for additions.
+	Version int         `json:"version,omitempty"`
+	Diff    []FieldDiff `json:"diff,omitempty"`
+}
+
+// Plan lists the changes provisioning would apply to the data_source table.
+type Plan struct {
+	Changes   []Change `json:"changes"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// HasChanges returns true if applying the provisioning files would modify the database.
+func (p *Plan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// Count returns the number of changes of the given type.
+func (p *Plan) Count(t ChangeType) int {
+	count := 0
+	for _, c := range p.Changes {
+		if c.Type == t {
+			count++
+		}
+	}
+	return count
+}
+
+// BuildPlan reads the provisioning files in configDirectory and compares them with the
+// data sources currently stored, without modifying anything. secrets may be nil in which
+// case secure fields are not compared.
+func BuildPlan(ctx context.Context, configDirectory string, store PlanStore, secrets SecureValuesReader, orgService org.Service) (*Plan, error) {
+	logger := log.New("provisioning.datasources")
+	cr := &configReader{log: logger, orgService: orgService}
+	cfgs, err := cr.readConfig(ctx, configDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	planner := &planner{
+		store:   store,
+		secrets: secrets,
+		removed: map[DataSourceMapKey]bool{},
+		added:   map[DataSourceMapKey]bool{},
+		planned: map[DataSourceMapKey]int{},
+	}
+	for _, cfg := range cfgs {
+		if err := planner.planConfig(ctx, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	return &planner.plan, nil
+}
+
+type planner struct {
+	store   PlanStore
+	secrets SecureValuesReader
+	plan    Plan
+
+	// removed and added track the state provisioning would leave behind so that files
+	// processed later see the effect of earlier ones, the same way applyChanges does.
+	removed map[DataSourceMapKey]bool
+	added   map[DataSourceMapKey]bool
+	// planned is the index in plan.Changes of the change planned for a data source, or -1 if it
+	// was unchanged. When several files provision the same data source the last one wins.
+	planned map[DataSourceMapKey]int
+}
+
+func (p *planner) get(ctx context.Context, key DataSourceMapKey) (*datasources.DataSource, error) {
+	if p.removed[key] {
+		return nil, nil
+	}
+
+	ds, err := p.store.GetDataSource(ctx, &datasources.GetDataSourceQuery{OrgID: key.OrgId, Name: key.Name})
+	if errors.Is(err, datasources.ErrDataSourceNotFound) {
+		return nil, nil
+	}
+	return ds, err
+}
+
+func (p *planner) planConfig(ctx context.Context, cfg *configs) error {
+	for _, del := range cfg.DeleteDatasources {
+		key := DataSourceMapKey{Name: del.Name, OrgId: del.OrgID}
+		// the changes planned by previous files are applied before the deletion, only the deletion is left
+		p.unplan(key)
+		if p.added[key] {
+			delete(p.added, key)
+			p.removed[key] = true
+			continue
+		}
+
+		existing, err := p.get(ctx, key)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			continue
+		}
+
+		p.removed[key] = true
+		p.plan.Changes = append(p.plan.Changes, Change{
+			Type:    ChangeTypeDelete,
+			OrgID:   existing.OrgID,
+			Name:    existing.Name,
+			UID:     existing.UID,
+			Version: existing.Version,
+		})
+	}
+
+	for _, ds := range cfg.Datasources {
+		key := DataSourceMapKey{Name: ds.Name, OrgId: ds.OrgID}
+		if p.added[key] {
+			// a previous file already adds it, it is added with the settings of this file.
+			cmd := createInsertCommand(ds)
+			p.plan.Changes[p.planned[key]].UID = cmd.UID
+			continue
+		}
+
+		existing, err := p.get(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			cmd := createInsertCommand(ds)
+			p.added[key] = true
+			delete(p.removed, key)
+			p.addChange(key, Change{
+				Type:  ChangeTypeAdd,
+				OrgID: cmd.OrgID,
+				Name:  cmd.Name,
+				UID:   cmd.UID,
+			})
+			continue
+		}
+
+		// a previous file may already update it, the stored data source ends up with the settings
+		// of this file so the update is computed against the database again.
+		p.unplan(key)
+
+		diff, err := p.diff(ctx, existing, ds)
+		if err != nil {
+			return err
+		}
+
+		if len(diff) == 0 {
+			p.plan.Unchanged++
+			p.planned[key] = -1
+			continue
+		}
+
+		p.addChange(key, Change{
+			Type:    ChangeTypeUpdate,
+			OrgID:   existing.OrgID,
+			Name:    existing.Name,
+			UID:     existing.UID,
+			Version: existing.Version,
+			Diff:    diff,
+		})
+	}
+
+	return nil
+}
+
+func (p *planner) addChange(key DataSourceMapKey, change Change) {
+	p.planned[key] = len(p.plan.Changes)
+	p.plan.Changes = append(p.plan.Changes, change)
+}
+
+// unplan removes the change previously planned for the data source
+func (p *planner) unplan(key DataSourceMapKey) {
+	i, ok := p.planned[key]
+	if !ok {
+		return
+	}
+	delete(p.planned, key)
+	if i < 0 {
+		p.plan.Unchanged--
+		return
+	}
+
+	p.plan.Changes = append(p.plan.Changes[:i], p.plan.Changes[i+1:]...)
+	for k, j := range p.planned {
+		if j > i {
+			p.planned[k] = j - 1
+		}
+	}
+}
+
+func (p *planner) diff(ctx context.Context, current *datasources.DataSource, desired *upsertDataSourceFromConfig) ([]FieldDiff, error) {
+	var diff []FieldDiff
+	add := func(field string, cur, des any) {
+		if !reflect.DeepEqual(cur, des) {
+			diff = append(diff, FieldDiff{Field: field, Current: cur, Desired: des})
+		}
+	}
+
+	// An empty UID in the provisioning file leaves the stored one untouched.
+	if desired.UID != "" {
+		add("uid", current.UID, desired.UID)
+	}
+	add("type", current.Type, desired.Type)
+	add("access", string(current.Access), desired.Access)
+	add("url", current.URL, desired.URL)
+	add("user", current.User, desired.User)
+	add("database", current.Database, desired.Database)
+	add("basicAuth", current.BasicAuth, desired.BasicAuth)
+	add("basicAuthUser", current.BasicAuthUser, desired.BasicAuthUser)
+	add("withCredentials", current.WithCredentials, desired.WithCredentials)
+	add("isDefault", current.IsDefault, desired.IsDefault)
+	add("editable", !current.ReadOnly, desired.Editable)
+	if desired.Version != 0 && desired.Version != current.Version {
+		add("version", current.Version, desired.Version)
+	}
+
+	currentJSON, err := normalizeJSONData(current)
+	if err != nil {
+		return nil, err
+	}
+	desiredJSON := createUpdateCommand(desired, current.ID).JsonData.MustMap()
+	desiredNormalized, err := normalizeJSON(desiredJSON)
+	if err != nil {
+		return nil, err
+	}
+	add("jsonData", currentJSON, desiredNormalized)
+
+	if p.secrets != nil {
+		values, err := p.secrets.DecryptedValues(ctx, current)
+		if err != nil {
+			return nil, err
+		}
+		// UpdateDataSource merges the secure fields, so only the fields that are not set yet are a change.
+		// The values are never compared.
+		merged := map[string]string{}
+		for k, v := range values {
+			merged[k] = v
+		}
+		for k, v := range desired.SecureJSONData {
+			merged[k] = v
+		}
+		if len(merged) != len(values) {
+			add("secureJsonFields", secureKeys(values), secureKeys(merged))
+		}
+	}
+
+	return diff, nil
+}
+
+// normalizeJSONData returns the data source JSON data in the shape it would have after
+// a round trip through the database so that it can be compared with the provisioning file.
+func normalizeJSONData(ds *datasources.DataSource) (map[string]any, error) {
+	if ds.JsonData == nil {
+		return map[string]any{}, nil
+	}
+	return normalizeJSON(ds.JsonData.MustMap())
+}
+
+func normalizeJSON(m map[string]any) (map[string]any, error) {
+	if m == nil {
+		return map[string]any{}, nil
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]any{}
+	if err := json.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func secureKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,158 @@
+package datasources
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+)
+
+const (
+	secureFields             = "testdata/secure-fields"
+	sameDatasourceInTwoFiles = "testdata/same-datasource-two-files"
+	updateThenDelete         = "testdata/update-then-delete"
+)
+
+func TestBuildPlan(t *testing.T) {
+	t.Run("no datasource in database should plan additions", func(t *testing.T) {
+		store := &spyStore{}
+		plan, err := BuildPlan(context.Background(), twoDatasourcesConfig, store, nil, &orgtest.FakeOrgService{})
+		require.NoError(t, err)
+
+		require.Len(t, plan.Changes, 2)
+		require.Equal(t, 2, plan.Count(ChangeTypeAdd))
+		require.Equal(t, "Graphite", plan.Changes[0].Name)
+		require.Equal(t, safeUIDFromName("Graphite"), plan.Changes[0].UID)
+		require.Empty(t, store.inserted)
+	})
+
+	t.Run("matching datasources should not be reported", func(t *testing.T) {
+		store := &spyStore{items: []*datasources.DataSource{
+			{ID: 1, OrgID: 1, Name: "Graphite", Type: "graphite", Access: "proxy", URL: "http://localhost:8080", ReadOnly: true, Version: 3},
+			{ID: 2, OrgID: 1, Name: "Prometheus", Type: "prometheus", Access: "proxy", URL: "http://localhost:9091", ReadOnly: true, Version: 7},
+		}}
+		plan, err := BuildPlan(context.Background(), twoDatasourcesConfig, store, nil, &orgtest.FakeOrgService{})
+		require.NoError(t, err)
+
+		require.Equal(t, 1, plan.Unchanged)
+		require.Len(t, plan.Changes, 1)
+		change := plan.Changes[0]
+		require.Equal(t, ChangeTypeUpdate, change.Type)
+		require.Equal(t, "Prometheus", change.Name)
+		require.Equal(t, 7, change.Version)
+		require.Equal(t, []FieldDiff{{Field: "url", Current: "http://localhost:9091", Desired: "http://localhost:9090"}}, change.Diff)
+		require.Empty(t, store.updated)
+	})
+
+	t.Run("deleted datasources should only be reported when they exist", func(t *testing.T) {
+		store := &spyStore{items: []*datasources.DataSource{
+			{ID: 1, OrgID: 1, Name: "old-graphite", UID: "old", Version: 2},
+		}}
+		plan, err := BuildPlan(context.Background(), twoDatasourcesConfigPurgeOthers, store, nil, &orgtest.FakeOrgService{})
+		require.NoError(t, err)
+
+		require.Equal(t, 1, plan.Count(ChangeTypeDelete))
+		require.Equal(t, 2, plan.Count(ChangeTypeAdd))
+		require.Equal(t, Change{Type: ChangeTypeDelete, OrgID: 1, Name: "old-graphite", UID: "old", Version: 2}, plan.Changes[0])
+		require.Empty(t, store.deleted)
+	})
+
+	t.Run("recreated datasource should be deleted and added", func(t *testing.T) {
+		store := &spyStore{items: []*datasources.DataSource{
+			{ID: 1, OrgID: 1, Name: "Test", UID: "test", Type: "type", Access: "proxy"},
+		}}
+		plan, err := BuildPlan(context.Background(), recreateOneDatasource, store, nil, &orgtest.FakeOrgService{})
+		require.NoError(t, err)
+
+		require.Len(t, plan.Changes, 2)
+		require.Equal(t, ChangeTypeDelete, plan.Changes[0].Type)
+		require.Equal(t, ChangeTypeAdd, plan.Changes[1].Type)
+	})
+
+	t.Run("the last file provisioning a datasource should win", func(t *testing.T) {
+		store := &spyStore{items: []*datasources.DataSource{
+			{ID: 1, OrgID: 1, Name: "Prometheus", Type: "prometheus", Access: "proxy", URL: "http://localhost:9090", ReadOnly: true},
+		}}
+		plan, err := BuildPlan(context.Background(), sameDatasourceInTwoFiles, store, nil, &orgtest.FakeOrgService{})
+		require.NoError(t, err)
+
+		require.Equal(t, 0, plan.Unchanged)
+		require.Len(t, plan.Changes, 1)
+		require.Equal(t, []FieldDiff{{Field: "url", Current: "http://localhost:9090", Desired: "http://localhost:9092"}}, plan.Changes[0].Diff)
+
+		store = &spyStore{items: []*datasources.DataSource{
+			{ID: 1, OrgID: 1, Name: "Prometheus", Type: "prometheus", Access: "proxy", URL: "http://localhost:9092", ReadOnly: true},
+		}}
+		plan, err = BuildPlan(context.Background(), sameDatasourceInTwoFiles, store, nil, &orgtest.FakeOrgService{})
+		require.NoError(t, err)
+		require.Equal(t, 1, plan.Unchanged)
+		require.Empty(t, plan.Changes)
+
+		plan, err = BuildPlan(context.Background(), sameDatasourceInTwoFiles, &spyStore{}, nil, &orgtest.FakeOrgService{})
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, 1)
+		require.Equal(t, ChangeTypeAdd, plan.Changes[0].Type)
+	})
+
+	t.Run("a datasource updated and then deleted by another file should only be deleted", func(t *testing.T) {
+		store := &spyStore{items: []*datasources.DataSource{
+			{ID: 1, OrgID: 1, Name: "Prometheus", UID: "prom", Type: "prometheus", Access: "proxy", URL: "http://localhost:9090", ReadOnly: true, Version: 4},
+		}}
+		plan, err := BuildPlan(context.Background(), updateThenDelete, store, nil, &orgtest.FakeOrgService{})
+		require.NoError(t, err)
+
+		require.Equal(t, 0, plan.Unchanged)
+		require.Equal(t, []Change{{Type: ChangeTypeDelete, OrgID: 1, Name: "Prometheus", UID: "prom", Version: 4}}, plan.Changes)
+
+		plan, err = BuildPlan(context.Background(), updateThenDelete, &spyStore{}, nil, &orgtest.FakeOrgService{})
+		require.NoError(t, err)
+		require.Empty(t, plan.Changes)
+	})
+
+	t.Run("should compare json data and secure field presence", func(t *testing.T) {
+		store := &spyStore{items: []*datasources.DataSource{
+			{
+				ID: 1, OrgID: 1, Name: "Prometheus", Type: "prometheus", Access: "proxy", URL: "http://localhost:9090", ReadOnly: true,
+				JsonData: simplejson.NewFromAny(map[string]any{"httpMethod": "GET"}),
+			},
+		}}
+		secrets := &fakeSecureValuesReader{values: map[string]string{"basicAuthPassword": "other"}}
+		plan, err := BuildPlan(context.Background(), secureFields, store, secrets, &orgtest.FakeOrgService{})
+		require.NoError(t, err)
+
+		require.Len(t, plan.Changes, 1)
+		require.Equal(t, []FieldDiff{
+			{Field: "jsonData", Current: map[string]any{"httpMethod": "GET"}, Desired: map[string]any{"httpMethod": "POST"}},
+			{Field: "secureJsonFields", Current: []string{"basicAuthPassword"}, Desired: []string{"basicAuthPassword", "httpHeaderValue1"}},
+		}, plan.Changes[0].Diff)
+	})
+
+	t.Run("should not report secure fields that are already set", func(t *testing.T) {
+		store := &spyStore{items: []*datasources.DataSource{
+			{
+				ID: 1, OrgID: 1, Name: "Prometheus", Type: "prometheus", Access: "proxy", URL: "http://localhost:9090", ReadOnly: true,
+				JsonData: simplejson.NewFromAny(map[string]any{"httpMethod": "POST"}),
+			},
+		}}
+		secrets := &fakeSecureValuesReader{values: map[string]string{
+			"basicAuthPassword": "other", "httpHeaderValue1": "other", "tlsClientKey": "key",
+		}}
+		plan, err := BuildPlan(context.Background(), secureFields, store, secrets, &orgtest.FakeOrgService{})
+		require.NoError(t, err)
+
+		require.False(t, plan.HasChanges())
+		require.Equal(t, 1, plan.Unchanged)
+	})
+}
+
+type fakeSecureValuesReader struct {
+	values map[string]string
+}
+
+func (f *fakeSecureValuesReader) DecryptedValues(ctx context.Context, ds *datasources.DataSource) (map[string]string, error) {
+	return f.values, nil
+}
@@ -0,0 +1,70 @@
+package provisioning
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+)
+
+var (
+	datasourceDriftGauge = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "provisioning",
+			Name:      "datasources_drift",
+			Help:      "Number of provisioned data sources that differ from the provisioning files, by pending change type",
+		},
+		[]string{"change"},
+	)
+	datasourceDriftChecksCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "grafana",
+			Subsystem: "provisioning",
+			Name:      "datasources_drift_checks_total",
+			Help:      "A counter for data source provisioning drift checks",
+		},
+		[]string{"result"},
+	)
+)
+
+// runDatasourceDriftChecker periodically compares the data source provisioning files with
+// the database and reports any divergence through logs and metrics.
+func (ps *ProvisioningServiceImpl) runDatasourceDriftChecker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ps.checkDatasourceDrift(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (ps *ProvisioningServiceImpl) checkDatasourceDrift(ctx context.Context) {
+	plan, err := ps.PlanDatasources(ctx)
+	if err != nil {
+		datasourceDriftChecksCounter.WithLabelValues("error").Inc()
+		ps.log.Error("Failed to check data source provisioning drift", "error", err)
+		return
+	}
+	datasourceDriftChecksCounter.WithLabelValues("success").Inc()
+
+	for _, t := range []datasources.ChangeType{datasources.ChangeTypeAdd, datasources.ChangeTypeUpdate, datasources.ChangeTypeDelete} {
+		datasourceDriftGauge.WithLabelValues(string(t)).Set(float64(plan.Count(t)))
+	}
+
+	for _, change := range plan.Changes {
+		fields := make([]string, 0, len(change.Diff))
+		for _, d := range change.Diff {
+			fields = append(fields, d.Field)
+		}
+		ps.log.Warn("Provisioned data source differs from provisioning files", "change", change.Type, "orgId", change.OrgID, "name", change.Name, "uid", change.UID, "version", change.Version, "fields", fields)
+	}
+}
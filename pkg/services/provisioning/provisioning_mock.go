@@ -1,10 +1,15 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []any
 	ProvisionDatasources                []any
+	PlanDatasources                     []any
 	ProvisionPlugins                    []any
 	ProvisionNotifications              []any
 	ProvisionDashboards                 []any
@@ -18,6 +23,7 @@ type ProvisioningServiceMock struct {
 	Calls                                   *Calls
 	RunInitProvisionersFunc                 func(ctx context.Context) error
 	ProvisionDatasourcesFunc                func(ctx context.Context) error
+	PlanDatasourcesFunc                     func(ctx context.Context) (*datasources.Plan, error)
 	ProvisionPluginsFunc                    func() error
 	ProvisionNotificationsFunc              func() error
 	ProvisionDashboardsFunc                 func() error
@@ -48,6 +54,14 @@ func (mock *ProvisioningServiceMock) ProvisionDatasources(ctx context.Context) e
 	return nil
 }
 
+func (mock *ProvisioningServiceMock) PlanDatasources(ctx context.Context) (*datasources.Plan, error) {
+	mock.Calls.PlanDatasources = append(mock.Calls.PlanDatasources, nil)
+	if mock.PlanDatasourcesFunc != nil {
+		return mock.PlanDatasourcesFunc(ctx)
+	}
+	return &datasources.Plan{}, nil
+}
+
 func (mock *ProvisioningServiceMock) ProvisionPlugins(ctx context.Context) error {
 	mock.Calls.ProvisionPlugins = append(mock.Calls.ProvisionPlugins, nil)
 	if mock.ProvisionPluginsFunc != nil {
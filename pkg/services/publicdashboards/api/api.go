@@ -85,6 +85,21 @@ func (api *Api) RegisterAPIEndpoints() {
 	api.RouteRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid",
 		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
 		routing.Wrap(api.DeletePublicDashboard))
+
+	// List access tokens of public dashboard
+	api.RouteRegister.Get("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/access-tokens",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.ListAccessTokens))
+
+	// Create access token for public dashboard
+	api.RouteRegister.Post("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/access-tokens",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.CreateAccessToken))
+
+	// Revoke access token of public dashboard
+	api.RouteRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/access-tokens/:accessToken",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.RevokeAccessToken))
 }
 
 // ListPublicDashboards Gets list of public dashboards by orgId
@@ -231,6 +246,88 @@ func (api *Api) DeletePublicDashboard(c *contextmodel.ReqContext) response.Respo
 	return response.JSON(http.StatusOK, nil)
 }
 
+// ListAccessTokens Gets the access tokens of a public dashboard
+// GET /api/dashboards/uid/:dashboardUid/public-dashboards/:uid/access-tokens
+func (api *Api) ListAccessTokens(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("ListAccessTokens: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("ListAccessTokens: invalid Uid %s", uid))
+	}
+
+	tokens, err := api.PublicDashboardService.FindAccessTokens(c.Req.Context(), c.OrgID, dashboardUid, uid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, tokens)
+}
+
+// CreateAccessToken Creates an additional access token for a public dashboard
+// POST /api/dashboards/uid/:dashboardUid/public-dashboards/:uid/access-tokens
+func (api *Api) CreateAccessToken(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("CreateAccessToken: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("CreateAccessToken: invalid Uid %s", uid))
+	}
+
+	tokenDTO := &AccessTokenDTO{}
+	if err := web.Bind(c.Req, tokenDTO); err != nil {
+		return response.Err(ErrBadRequest.Errorf("CreateAccessToken: bad request data %v", err))
+	}
+
+	// Always set the orgID and userID from the session
+	dto := &SaveAccessTokenDTO{
+		Uid:          uid,
+		DashboardUid: dashboardUid,
+		OrgID:        c.OrgID,
+		UserId:       c.UserID,
+		AccessToken:  tokenDTO,
+	}
+
+	token, err := api.PublicDashboardService.CreateAccessToken(c.Req.Context(), c.SignedInUser, dto)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, token)
+}
+
+// RevokeAccessToken Revokes an access token of a public dashboard
+// DELETE /api/dashboards/uid/:dashboardUid/public-dashboards/:uid/access-tokens/:accessToken
+func (api *Api) RevokeAccessToken(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("RevokeAccessToken: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("RevokeAccessToken: invalid Uid %s", uid))
+	}
+
+	accessToken := web.Params(c.Req)[":accessToken"]
+	if !validation.IsValidAccessToken(accessToken) {
+		return response.Err(ErrInvalidAccessToken.Errorf("RevokeAccessToken: invalid Access Token %s", accessToken))
+	}
+
+	err := api.PublicDashboardService.RevokeAccessToken(c.Req.Context(), c.OrgID, dashboardUid, uid, accessToken)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, nil)
+}
+
 // Copied from pkg/api/metrics.go
 func toJsonStreamingResponse(features *featuremgmt.FeatureManager, qdr *backend.QueryDataResponse) response.Response {
 	statusWhenError := http.StatusBadRequest
@@ -293,6 +293,92 @@ func TestAPIDeletePublicDashboard(t *testing.T) {
 	}
 }
 
+func TestAPIAccessTokens(t *testing.T) {
+	dashboardUid := "abc1234"
+	publicDashboardUid := "1234asdfasdf"
+	accessToken := "e71950f3e7054f1bbd6f0ca3df3ae52b"
+	basePath := fmt.Sprintf("/api/dashboards/uid/%s/public-dashboards/%s/access-tokens", dashboardUid, publicDashboardUid)
+
+	t.Run("User viewer cannot list access tokens", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		testServer := setupTestServer(t, setting.NewCfg(), featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userViewer)
+
+		response := callAPI(testServer, http.MethodGet, basePath, nil, t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+		service.AssertNotCalled(t, "FindAccessTokens")
+	})
+
+	t.Run("User admin can list access tokens", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("FindAccessTokens", mock.Anything, int64(1), dashboardUid, publicDashboardUid).
+			Return([]*AccessTokenListResponse{{AccessToken: accessToken, PublicDashboardUid: publicDashboardUid}}, nil)
+		testServer := setupTestServer(t, setting.NewCfg(), featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userAdmin)
+
+		response := callAPI(testServer, http.MethodGet, basePath, nil, t)
+		assert.Equal(t, http.StatusOK, response.Code)
+
+		var tokens []*AccessTokenListResponse
+		err := json.Unmarshal(response.Body.Bytes(), &tokens)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.Equal(t, accessToken, tokens[0].AccessToken)
+	})
+
+	t.Run("User admin can create access token", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("CreateAccessToken", mock.Anything, mock.Anything, mock.MatchedBy(func(dto *SaveAccessTokenDTO) bool {
+			return dto.Uid == publicDashboardUid && dto.DashboardUid == dashboardUid && dto.UserId == userAdmin.UserID && dto.AccessToken.ExpiresAt != nil
+		})).Return(&AccessToken{AccessToken: accessToken, PublicDashboardUid: publicDashboardUid}, nil)
+		testServer := setupTestServer(t, setting.NewCfg(), featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userAdmin)
+
+		response := callAPI(testServer, http.MethodPost, basePath, strings.NewReader(`{"expiresAt": "2030-01-01T00:00:00Z"}`), t)
+		assert.Equal(t, http.StatusOK, response.Code)
+
+		var token AccessToken
+		err := json.Unmarshal(response.Body.Bytes(), &token)
+		require.NoError(t, err)
+		assert.Equal(t, accessToken, token.AccessToken)
+	})
+
+	t.Run("Invalid expiry returns bad request", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("CreateAccessToken", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, ErrInvalidAccessTokenExpiry.Errorf(""))
+		testServer := setupTestServer(t, setting.NewCfg(), featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userAdmin)
+
+		response := callAPI(testServer, http.MethodPost, basePath, strings.NewReader(`{"expiresAt": "2020-01-01T00:00:00Z"}`), t)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+	})
+
+	t.Run("User admin can revoke access token", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("RevokeAccessToken", mock.Anything, int64(1), dashboardUid, publicDashboardUid, accessToken).Return(nil)
+		testServer := setupTestServer(t, setting.NewCfg(), featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userAdmin)
+
+		response := callAPI(testServer, http.MethodDelete, basePath+"/"+accessToken, nil, t)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("Revoking an unknown access token returns not found", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("RevokeAccessToken", mock.Anything, int64(1), dashboardUid, publicDashboardUid, accessToken).
+			Return(ErrAccessTokenNotFound.Errorf(""))
+		testServer := setupTestServer(t, setting.NewCfg(), featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userAdmin)
+
+		response := callAPI(testServer, http.MethodDelete, basePath+"/"+accessToken, nil, t)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+
+	t.Run("Invalid access token returns bad request", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		testServer := setupTestServer(t, setting.NewCfg(), featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards), service, nil, userAdmin)
+
+		response := callAPI(testServer, http.MethodDelete, basePath+"/inv@lid", nil, t)
+		assert.Equal(t, http.StatusBadRequest, response.Code)
+		service.AssertNotCalled(t, "RevokeAccessToken")
+	})
+}
+
 func TestAPIGetPublicDashboard(t *testing.T) {
 	pubdash := &PublicDashboard{IsEnabled: true}
 
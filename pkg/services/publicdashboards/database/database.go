@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -137,16 +138,21 @@ func (d *PublicDashboardStoreImpl) Find(ctx context.Context, uid string) (*Publi
 	return publicDashboard, nil
 }
 
-// FindByAccessToken Returns public dashboard by access token or nil if not found
+// FindByAccessToken Returns public dashboard by access token or nil if not found or if the access token expired
 func (d *PublicDashboardStoreImpl) FindByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, error) {
 	if accessToken == "" {
 		return nil, nil
 	}
 
 	var found bool
-	publicDashboard := &PublicDashboard{AccessToken: accessToken}
+	publicDashboard := &PublicDashboard{}
 	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
-		var err error
+		token, err := findValidAccessToken(sess, accessToken)
+		if err != nil || token == nil {
+			return err
+		}
+
+		publicDashboard.Uid = token.PublicDashboardUid
 		found, err = sess.Get(publicDashboard)
 		return err
 	})
@@ -208,13 +214,18 @@ func (d *PublicDashboardStoreImpl) ExistsEnabledByDashboardUid(ctx context.Conte
 	return hasPublicDashboard, err
 }
 
-// ExistsEnabledByAccessToken Responds true if the accessToken exists and the public dashboard is enabled
+// ExistsEnabledByAccessToken Responds true if the accessToken exists, has not expired and the public dashboard is enabled
 func (d *PublicDashboardStoreImpl) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
 	hasPublicDashboard := false
 	err := d.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
-		sql := "SELECT COUNT(*) FROM dashboard_public WHERE access_token=? AND is_enabled=true"
+		token, err := findValidAccessToken(dbSession, accessToken)
+		if err != nil || token == nil {
+			return err
+		}
+
+		sql := "SELECT COUNT(*) FROM dashboard_public WHERE uid=? AND is_enabled=true"
 
-		result, err := dbSession.SQL(sql, accessToken).Count()
+		result, err := dbSession.SQL(sql, token.PublicDashboardUid).Count()
 		if err != nil {
 			return err
 		}
@@ -226,18 +237,17 @@ func (d *PublicDashboardStoreImpl) ExistsEnabledByAccessToken(ctx context.Contex
 	return hasPublicDashboard, err
 }
 
-// GetOrgIdByAccessToken Returns the public dashboard OrgId if exists.
+// GetOrgIdByAccessToken Returns the public dashboard OrgId if the access token exists and has not expired.
 func (d *PublicDashboardStoreImpl) GetOrgIdByAccessToken(ctx context.Context, accessToken string) (int64, error) {
 	var orgId int64
 	err := d.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
-		sql := "SELECT org_id FROM dashboard_public WHERE access_token=?"
-
-		_, err := dbSession.SQL(sql, accessToken).Get(&orgId)
-		if err != nil {
+		token, err := findValidAccessToken(dbSession, accessToken)
+		if err != nil || token == nil {
 			return err
 		}
 
-		return err
+		orgId = token.OrgId
+		return nil
 	})
 
 	return orgId, err
@@ -250,9 +260,22 @@ func (d *PublicDashboardStoreImpl) Create(ctx context.Context, cmd SavePublicDas
 	}
 
 	var affectedRows int64
-	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		var err error
 		affectedRows, err = sess.UseBool("is_enabled").Insert(&cmd.PublicDashboard)
+		if err != nil {
+			return err
+		}
+
+		// the access token of the public dashboard is its first access token
+		_, err = sess.Insert(&AccessToken{
+			AccessToken:        cmd.PublicDashboard.AccessToken,
+			OrgId:              cmd.PublicDashboard.OrgId,
+			PublicDashboardUid: cmd.PublicDashboard.Uid,
+			ExpiresAt:          cmd.PublicDashboard.ExpiresAt,
+			CreatedBy:          cmd.PublicDashboard.CreatedBy,
+			CreatedAt:          cmd.PublicDashboard.CreatedAt,
+		})
 		return err
 	})
 
@@ -262,18 +285,24 @@ func (d *PublicDashboardStoreImpl) Create(ctx context.Context, cmd SavePublicDas
 // Updates existing public dashboard
 func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDashboardCommand) (int64, error) {
 	var affectedRows int64
-	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		timeSettingsJSON, err := json.Marshal(cmd.PublicDashboard.TimeSettings)
 		if err != nil {
 			return err
 		}
 
-		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
+		variablesJSON, err := json.Marshal(cmd.PublicDashboard.Variables)
+		if err != nil {
+			return err
+		}
+
+		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, variables = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
 			cmd.PublicDashboard.IsEnabled,
 			cmd.PublicDashboard.AnnotationsEnabled,
 			cmd.PublicDashboard.TimeSelectionEnabled,
 			cmd.PublicDashboard.Share,
 			string(timeSettingsJSON),
+			string(variablesJSON),
 			cmd.PublicDashboard.UpdatedBy,
 			cmd.PublicDashboard.UpdatedAt.UTC().Format("2006-01-02 15:04:05"),
 			cmd.PublicDashboard.Uid)
@@ -283,21 +312,100 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 		}
 
 		affectedRows, err = sqlResult.RowsAffected()
+		if err != nil || affectedRows == 0 || cmd.PublicDashboard.ExpiresAt == nil {
+			return err
+		}
 
+		_, err = sess.Where("public_dashboard_uid = ? AND access_token = ?", cmd.PublicDashboard.Uid, cmd.PublicDashboard.AccessToken).
+			Cols("expires_at").Update(&AccessToken{ExpiresAt: cmd.PublicDashboard.ExpiresAt})
 		return err
 	})
 
 	return affectedRows, err
 }
 
-// Deletes a public dashboard
+// Deletes a public dashboard and its access tokens
 func (d *PublicDashboardStoreImpl) Delete(ctx context.Context, uid string) (int64, error) {
 	dashboard := &PublicDashboard{Uid: uid}
 	var affectedRows int64
-	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		var err error
 		affectedRows, err = sess.Delete(dashboard)
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Exec("DELETE FROM dashboard_public_access_token WHERE public_dashboard_uid = ?", uid)
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// FindAccessTokens Returns the access tokens of a public dashboard, including the expired ones, with the login of their creator
+func (d *PublicDashboardStoreImpl) FindAccessTokens(ctx context.Context, orgId int64, uid string) ([]*AccessTokenListResponse, error) {
+	tokens := make([]*AccessTokenListResponse, 0)
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		sql := "SELECT t.access_token, t.public_dashboard_uid, t.expires_at, t.created_by, t.created_at, u.login AS created_by_login" +
+			" FROM dashboard_public_access_token t" +
+			" LEFT JOIN " + d.sqlStore.GetDialect().Quote("user") + " u ON u.id = t.created_by" +
+			" WHERE t.org_id = ? AND t.public_dashboard_uid = ?" +
+			" ORDER BY t.created_at, t.id"
+		return sess.SQL(sql, orgId, uid).Find(&tokens)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// CreateAccessToken Adds an access token to a public dashboard
+func (d *PublicDashboardStoreImpl) CreateAccessToken(ctx context.Context, token *AccessToken) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		affectedRows, err = sess.Insert(token)
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// DeleteAccessToken Deletes an access token of a public dashboard. When it is the access token of the public dashboard,
+// the oldest remaining access token, preferably not expired, replaces it. The last access token can't be deleted.
+func (d *PublicDashboardStoreImpl) DeleteAccessToken(ctx context.Context, uid string, accessToken string) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		affectedRows, err = sess.Where("public_dashboard_uid = ? AND access_token = ?", uid, accessToken).Delete(&AccessToken{})
+		if err != nil || affectedRows == 0 {
+			return err
+		}
+
+		isPrimary, err := sess.Table("dashboard_public").Where("uid = ? AND access_token = ?", uid, accessToken).Exist()
+		if err != nil || !isPrimary {
+			return err
+		}
+
+		remaining := make([]*AccessToken, 0)
+		if err := sess.Where("public_dashboard_uid = ?", uid).OrderBy("created_at, id").Find(&remaining); err != nil {
+			return err
+		}
+		if len(remaining) == 0 {
+			return ErrLastAccessToken.Errorf("DeleteAccessToken: the last access token of public dashboard %s can't be revoked", uid)
+		}
 
+		promoted := remaining[0]
+		now := time.Now()
+		for _, token := range remaining {
+			if !token.IsExpired(now) {
+				promoted = token
+				break
+			}
+		}
+
+		_, err = sess.Exec("UPDATE dashboard_public SET access_token = ? WHERE uid = ?", promoted.AccessToken, uid)
 		return err
 	})
 
@@ -321,6 +429,25 @@ func (d *PublicDashboardStoreImpl) FindByDashboardFolder(ctx context.Context, da
 	return pubdashes, nil
 }
 
+// findValidAccessToken returns the access token, or nil if it does not exist or expired
+func findValidAccessToken(sess *db.Session, accessToken string) (*AccessToken, error) {
+	if accessToken == "" {
+		return nil, nil
+	}
+
+	token := &AccessToken{}
+	found, err := sess.Where("access_token = ?", accessToken).Get(token)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	if token.IsExpired(time.Now()) {
+		return nil, nil
+	}
+
+	return token, nil
+}
+
 func (d *PublicDashboardStoreImpl) GetMetrics(ctx context.Context) (*Metrics, error) {
 	metrics := &Metrics{
 		TotalPublicDashboards: []*TotalPublicDashboard{},
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	dashboardsDB "github.com/grafana/grafana/pkg/services/dashboards/database"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/org"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/service"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
@@ -764,6 +765,219 @@ func TestIntegrationDelete(t *testing.T) {
 	})
 }
 
+func TestIntegrationAccessTokens(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	var sqlStore db.DB
+	var cfg *setting.Cfg
+	var dashboardStore dashboards.Store
+	var publicdashboardStore *PublicDashboardStoreImpl
+	var savedPublicDashboard *PublicDashboard
+	var err error
+
+	setup := func() {
+		sqlStore, cfg = db.InitTestDBwithCfg(t)
+		dashboardStore, err = dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, cfg), quotatest.New(false, nil))
+		require.NoError(t, err)
+		publicdashboardStore = ProvideStore(sqlStore, cfg, featuremgmt.WithFeatures())
+		savedDashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, true)
+		savedPublicDashboard = insertPublicDashboard(t, publicdashboardStore, savedDashboard.UID, savedDashboard.OrgID, true, PublicShareType)
+	}
+
+	insertAccessToken := func(t *testing.T, expiresAt *time.Time) string {
+		accessToken, err := service.GenerateAccessToken()
+		require.NoError(t, err)
+		affectedRows, err := publicdashboardStore.CreateAccessToken(context.Background(), &AccessToken{
+			AccessToken:        accessToken,
+			OrgId:              savedPublicDashboard.OrgId,
+			PublicDashboardUid: savedPublicDashboard.Uid,
+			ExpiresAt:          expiresAt,
+			CreatedBy:          1,
+			CreatedAt:          DefaultTime,
+		})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, affectedRows)
+		return accessToken
+	}
+
+	t.Run("Creating a public dashboard creates its first access token", func(t *testing.T) {
+		setup()
+
+		tokens, err := publicdashboardStore.FindAccessTokens(context.Background(), savedPublicDashboard.OrgId, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.Equal(t, savedPublicDashboard.AccessToken, tokens[0].AccessToken)
+		assert.Nil(t, tokens[0].ExpiresAt)
+	})
+
+	t.Run("Additional access tokens give access to the public dashboard", func(t *testing.T) {
+		setup()
+		expiresAt := DefaultTime.Add(time.Hour)
+		accessToken := insertAccessToken(t, &expiresAt)
+
+		pubdash, err := publicdashboardStore.FindByAccessToken(context.Background(), accessToken)
+		require.NoError(t, err)
+		require.NotNil(t, pubdash)
+		assert.Equal(t, savedPublicDashboard.Uid, pubdash.Uid)
+
+		exists, err := publicdashboardStore.ExistsEnabledByAccessToken(context.Background(), accessToken)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		tokens, err := publicdashboardStore.FindAccessTokens(context.Background(), savedPublicDashboard.OrgId, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+	})
+
+	t.Run("Expired access tokens give no access to the public dashboard", func(t *testing.T) {
+		setup()
+		expiresAt := DefaultTime.Add(-time.Hour)
+		accessToken := insertAccessToken(t, &expiresAt)
+
+		pubdash, err := publicdashboardStore.FindByAccessToken(context.Background(), accessToken)
+		require.NoError(t, err)
+		assert.Nil(t, pubdash)
+
+		exists, err := publicdashboardStore.ExistsEnabledByAccessToken(context.Background(), accessToken)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		orgId, err := publicdashboardStore.GetOrgIdByAccessToken(context.Background(), accessToken)
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, orgId)
+	})
+
+	t.Run("An expired access token of the public dashboard gives no access to it", func(t *testing.T) {
+		setup()
+		expiresAt := DefaultTime.Add(-time.Hour)
+		savedDashboard := insertTestDashboard(t, dashboardStore, "expiredDashie", 1, 0, true)
+		cmd := SavePublicDashboardCommand{
+			PublicDashboard: PublicDashboard{
+				Uid:          "expired-uid",
+				DashboardUid: savedDashboard.UID,
+				OrgId:        savedDashboard.OrgID,
+				IsEnabled:    true,
+				Share:        PublicShareType,
+				CreatedAt:    DefaultTime,
+				CreatedBy:    7,
+				AccessToken:  "expiredaccesstoken",
+				ExpiresAt:    &expiresAt,
+			},
+		}
+		_, err := publicdashboardStore.Create(context.Background(), cmd)
+		require.NoError(t, err)
+
+		pubdash, err := publicdashboardStore.FindByAccessToken(context.Background(), cmd.PublicDashboard.AccessToken)
+		require.NoError(t, err)
+		assert.Nil(t, pubdash)
+
+		exists, err := publicdashboardStore.ExistsEnabledByAccessToken(context.Background(), cmd.PublicDashboard.AccessToken)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("Updating a public dashboard changes the expiry of its access token", func(t *testing.T) {
+		setup()
+		expiresAt := DefaultTime.Add(-time.Hour)
+		cmd := SavePublicDashboardCommand{
+			PublicDashboard: PublicDashboard{
+				Uid:         savedPublicDashboard.Uid,
+				AccessToken: savedPublicDashboard.AccessToken,
+				IsEnabled:   true,
+				Share:       PublicShareType,
+				UpdatedAt:   DefaultTime,
+				UpdatedBy:   8,
+				ExpiresAt:   &expiresAt,
+			},
+		}
+		_, err := publicdashboardStore.Update(context.Background(), cmd)
+		require.NoError(t, err)
+
+		tokens, err := publicdashboardStore.FindAccessTokens(context.Background(), savedPublicDashboard.OrgId, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		require.NotNil(t, tokens[0].ExpiresAt)
+		assert.True(t, expiresAt.Equal(*tokens[0].ExpiresAt))
+
+		pubdash, err := publicdashboardStore.FindByAccessToken(context.Background(), savedPublicDashboard.AccessToken)
+		require.NoError(t, err)
+		assert.Nil(t, pubdash)
+	})
+
+	t.Run("Revoked access tokens give no access to the public dashboard", func(t *testing.T) {
+		setup()
+		accessToken := insertAccessToken(t, nil)
+
+		affectedRows, err := publicdashboardStore.DeleteAccessToken(context.Background(), savedPublicDashboard.Uid, accessToken)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, affectedRows)
+
+		pubdash, err := publicdashboardStore.FindByAccessToken(context.Background(), accessToken)
+		require.NoError(t, err)
+		assert.Nil(t, pubdash)
+
+		// the other access tokens remain valid
+		pubdash, err = publicdashboardStore.FindByAccessToken(context.Background(), savedPublicDashboard.AccessToken)
+		require.NoError(t, err)
+		assert.NotNil(t, pubdash)
+	})
+
+	t.Run("Revoking the access token of the public dashboard replaces it with another access token", func(t *testing.T) {
+		setup()
+		expiresAt := DefaultTime.Add(-time.Hour)
+		expiredAccessToken := insertAccessToken(t, &expiresAt)
+		validAccessToken := insertAccessToken(t, nil)
+
+		_, err := publicdashboardStore.DeleteAccessToken(context.Background(), savedPublicDashboard.Uid, savedPublicDashboard.AccessToken)
+		require.NoError(t, err)
+
+		// the valid access token is preferred to the expired one
+		pubdash, err := publicdashboardStore.Find(context.Background(), savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.Equal(t, validAccessToken, pubdash.AccessToken)
+
+		resp, err := publicdashboardStore.FindAllWithPagination(context.Background(), &PublicDashboardListQuery{
+			OrgID: savedPublicDashboard.OrgId,
+			User:  &user.SignedInUser{OrgRole: org.RoleAdmin},
+			Page:  1,
+			Limit: 50,
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.PublicDashboards, 1)
+		assert.Equal(t, validAccessToken, resp.PublicDashboards[0].AccessToken)
+
+		_, err = publicdashboardStore.DeleteAccessToken(context.Background(), savedPublicDashboard.Uid, validAccessToken)
+		require.NoError(t, err)
+		pubdash, err = publicdashboardStore.Find(context.Background(), savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.Equal(t, expiredAccessToken, pubdash.AccessToken)
+	})
+
+	t.Run("The last access token of a public dashboard can't be revoked", func(t *testing.T) {
+		setup()
+
+		_, err := publicdashboardStore.DeleteAccessToken(context.Background(), savedPublicDashboard.Uid, savedPublicDashboard.AccessToken)
+		require.ErrorIs(t, err, ErrLastAccessToken)
+
+		tokens, err := publicdashboardStore.FindAccessTokens(context.Background(), savedPublicDashboard.OrgId, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.Len(t, tokens, 1)
+	})
+
+	t.Run("Deleting a public dashboard deletes its access tokens", func(t *testing.T) {
+		setup()
+		_ = insertAccessToken(t, nil)
+
+		_, err := publicdashboardStore.Delete(context.Background(), savedPublicDashboard.Uid)
+		require.NoError(t, err)
+
+		tokens, err := publicdashboardStore.FindAccessTokens(context.Background(), savedPublicDashboard.OrgId, savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.Len(t, tokens, 0)
+	})
+}
+
 func TestGetDashboardByFolder(t *testing.T) {
 	t.Run("returns nil when dashboard is not a folder", func(t *testing.T) {
 		sqlStore, _ := db.InitTestDBwithCfg(t)
@@ -8,6 +8,7 @@ var (
 	ErrPublicDashboardNotFound = errutil.NotFound("publicdashboards.notFound", errutil.WithPublicMessage("Public dashboard not found"))
 	ErrDashboardNotFound       = errutil.NotFound("publicdashboards.dashboardNotFound", errutil.WithPublicMessage("Dashboard not found"))
 	ErrPanelNotFound           = errutil.NotFound("publicdashboards.panelNotFound", errutil.WithPublicMessage("Public dashboard panel not found"))
+	ErrAccessTokenNotFound     = errutil.NotFound("publicdashboards.accessTokenNotFound", errutil.WithPublicMessage("Access token not found"))
 
 	ErrBadRequest                          = errutil.BadRequest("publicdashboards.badRequest")
 	ErrPanelQueriesNotFound                = errutil.BadRequest("publicdashboards.panelQueriesNotFound", errutil.WithPublicMessage("Failed to extract queries from panel"))
@@ -23,6 +24,10 @@ var (
 	ErrDashboardIsPublic                   = errutil.BadRequest("publicdashboards.dashboardIsPublic", errutil.WithPublicMessage("Dashboard is already public"))
 	ErrPublicDashboardUidExists            = errutil.BadRequest("publicdashboards.uidExists", errutil.WithPublicMessage("Public Dashboard Uid already exists"))
 	ErrPublicDashboardAccessTokenExists    = errutil.BadRequest("publicdashboards.accessTokenExists", errutil.WithPublicMessage("Public Dashboard Access Token already exists"))
+	ErrInvalidVariables                    = errutil.BadRequest("publicdashboards.invalidVariables", errutil.WithPublicMessage("Invalid public dashboard variables"))
+	ErrVariableValueNotAllowed             = errutil.BadRequest("publicdashboards.variableValueNotAllowed", errutil.WithPublicMessage("Variable value not allowed"))
+	ErrInvalidAccessTokenExpiry            = errutil.BadRequest("publicdashboards.invalidAccessTokenExpiry", errutil.WithPublicMessage("Access token expiry must be in the future"))
+	ErrLastAccessToken                     = errutil.BadRequest("publicdashboards.lastAccessToken", errutil.WithPublicMessage("The last access token of a public dashboard can't be revoked, delete the public dashboard instead"))
 
 	ErrPublicDashboardNotEnabled = errutil.Forbidden("publicdashboards.notEnabled", errutil.WithPublicMessage("Public dashboard paused"))
 )
@@ -47,12 +47,18 @@ type PublicDashboard struct {
 	CreatedAt    time.Time `json:"createdAt" xorm:"created_at"`
 	UpdatedAt    time.Time `json:"updatedAt" xorm:"updated_at"`
 	//config fields
-	TimeSettings         *TimeSettings `json:"-" xorm:"time_settings"`
+	// TimeSettings overrides the time range of the dashboard when set
+	TimeSettings         *TimeSettings `json:"timeSettings" xorm:"time_settings"`
 	TimeSelectionEnabled bool          `json:"timeSelectionEnabled" xorm:"time_selection_enabled"`
 	IsEnabled            bool          `json:"isEnabled" xorm:"is_enabled"`
 	AnnotationsEnabled   bool          `json:"annotationsEnabled" xorm:"annotations_enabled"`
 	Share                ShareType     `json:"share" xorm:"share"`
 	Recipients           []EmailDTO    `json:"recipients,omitempty" xorm:"-"`
+	// Variables holds the values viewers can select for each variable of the dashboard, the first one being
+	// the default. The variables that are not listed keep the value saved in the dashboard.
+	Variables map[string][]string `json:"variables,omitempty" xorm:"variables"`
+	// ExpiresAt is the expiry of the access token, it is stored with the access token when the public dashboard is saved
+	ExpiresAt *time.Time `json:"expiresAt,omitempty" xorm:"-"`
 }
 
 type PublicDashboardDTO struct {
@@ -62,6 +68,11 @@ type PublicDashboardDTO struct {
 	IsEnabled            *bool     `json:"isEnabled"`
 	AnnotationsEnabled   *bool     `json:"annotationsEnabled"`
 	Share                ShareType `json:"share"`
+	// TimeSettings and Variables are left unchanged on update when not set
+	TimeSettings *TimeSettings       `json:"timeSettings"`
+	Variables    map[string][]string `json:"variables"`
+	// ExpiresAt is the time after which the access token is no longer valid, it is left unchanged on update when not set
+	ExpiresAt *time.Time `json:"expiresAt"`
 }
 
 type EmailDTO struct {
@@ -95,6 +106,42 @@ func (pd PublicDashboard) TableName() string {
 	return "dashboard_public"
 }
 
+// AccessToken grants access to a public dashboard. A public dashboard can have several access tokens,
+// which can expire and be revoked individually.
+type AccessToken struct {
+	Id                 int64      `json:"-" xorm:"pk autoincr 'id'"`
+	AccessToken        string     `json:"accessToken" xorm:"access_token"`
+	OrgId              int64      `json:"-" xorm:"org_id"`
+	PublicDashboardUid string     `json:"publicDashboardUid" xorm:"public_dashboard_uid"`
+	ExpiresAt          *time.Time `json:"expiresAt" xorm:"expires_at"`
+	CreatedBy          int64      `json:"createdBy" xorm:"created_by"`
+	CreatedAt          time.Time  `json:"createdAt" xorm:"created_at"`
+}
+
+func (at AccessToken) TableName() string {
+	return "dashboard_public_access_token"
+}
+
+// IsExpired returns true if the access token has an expiry before the given time
+func (at AccessToken) IsExpired(now time.Time) bool {
+	return at.ExpiresAt != nil && !at.ExpiresAt.After(now)
+}
+
+type AccessTokenListResponse struct {
+	AccessToken        string     `json:"accessToken" xorm:"access_token"`
+	PublicDashboardUid string     `json:"publicDashboardUid" xorm:"public_dashboard_uid"`
+	ExpiresAt          *time.Time `json:"expiresAt" xorm:"expires_at"`
+	IsExpired          bool       `json:"isExpired" xorm:"-"`
+	CreatedBy          int64      `json:"createdBy" xorm:"created_by"`
+	CreatedByLogin     string     `json:"createdByLogin" xorm:"created_by_login"`
+	CreatedAt          time.Time  `json:"createdAt" xorm:"created_at"`
+}
+
+type AccessTokenDTO struct {
+	// ExpiresAt is the time after which the access token is no longer valid. The token never expires when not set.
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
 type PublicDashboardListQuery struct {
 	OrgID  int64
 	Query  string
@@ -141,6 +188,15 @@ type SavePublicDashboardDTO struct {
 	PublicDashboard *PublicDashboardDTO
 }
 
+// DTO for transforming user input in the api
+type SaveAccessTokenDTO struct {
+	Uid          string
+	DashboardUid string
+	OrgID        int64
+	UserId       int64
+	AccessToken  *AccessTokenDTO
+}
+
 type TimeRangeDTO struct {
 	From     string
 	To       string
@@ -152,6 +208,8 @@ type PublicDashboardQueryDTO struct {
 	MaxDataPoints   int64
 	QueryCachingTTL int64
 	TimeRange       TimeRangeDTO
+	// Variables holds the values selected by the viewer, which must be allowed by the public dashboard
+	Variables map[string][]string
 }
 
 type AnnotationsQueryDTO struct {
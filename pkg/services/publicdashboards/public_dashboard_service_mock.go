@@ -49,6 +49,32 @@ func (_m *FakePublicDashboardService) Create(ctx context.Context, u *user.Signed
 	return r0, r1
 }
 
+// CreateAccessToken provides a mock function with given fields: ctx, u, dto
+func (_m *FakePublicDashboardService) CreateAccessToken(ctx context.Context, u *user.SignedInUser, dto *models.SaveAccessTokenDTO) (*models.AccessToken, error) {
+	ret := _m.Called(ctx, u, dto)
+
+	var r0 *models.AccessToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, *models.SaveAccessTokenDTO) (*models.AccessToken, error)); ok {
+		return rf(ctx, u, dto)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *user.SignedInUser, *models.SaveAccessTokenDTO) *models.AccessToken); ok {
+		r0 = rf(ctx, u, dto)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AccessToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *user.SignedInUser, *models.SaveAccessTokenDTO) error); ok {
+		r1 = rf(ctx, u, dto)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: ctx, uid
 func (_m *FakePublicDashboardService) Delete(ctx context.Context, uid string) error {
 	ret := _m.Called(ctx, uid)
@@ -151,6 +177,32 @@ func (_m *FakePublicDashboardService) Find(ctx context.Context, uid string) (*mo
 	return r0, r1
 }
 
+// FindAccessTokens provides a mock function with given fields: ctx, orgId, dashboardUid, uid
+func (_m *FakePublicDashboardService) FindAccessTokens(ctx context.Context, orgId int64, dashboardUid string, uid string) ([]*models.AccessTokenListResponse, error) {
+	ret := _m.Called(ctx, orgId, dashboardUid, uid)
+
+	var r0 []*models.AccessTokenListResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) ([]*models.AccessTokenListResponse, error)); ok {
+		return rf(ctx, orgId, dashboardUid, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) []*models.AccessTokenListResponse); ok {
+		r0 = rf(ctx, orgId, dashboardUid, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AccessTokenListResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string) error); ok {
+		r1 = rf(ctx, orgId, dashboardUid, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAllWithPagination provides a mock function with given fields: ctx, query
 func (_m *FakePublicDashboardService) FindAllWithPagination(ctx context.Context, query *models.PublicDashboardListQuery) (*models.PublicDashboardListResponseWithPagination, error) {
 	ret := _m.Called(ctx, query)
@@ -499,6 +551,20 @@ func (_m *FakePublicDashboardService) NewPublicDashboardUid(ctx context.Context)
 	return r0, r1
 }
 
+// RevokeAccessToken provides a mock function with given fields: ctx, orgId, dashboardUid, uid, accessToken
+func (_m *FakePublicDashboardService) RevokeAccessToken(ctx context.Context, orgId int64, dashboardUid string, uid string, accessToken string) error {
+	ret := _m.Called(ctx, orgId, dashboardUid, uid, accessToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, string) error); ok {
+		r0 = rf(ctx, orgId, dashboardUid, uid, accessToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: ctx, u, dto
 func (_m *FakePublicDashboardService) Update(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicDashboardDTO) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, u, dto)
@@ -40,6 +40,30 @@ func (_m *FakePublicDashboardStore) Create(ctx context.Context, cmd models.SaveP
 	return r0, r1
 }
 
+// CreateAccessToken provides a mock function with given fields: ctx, token
+func (_m *FakePublicDashboardStore) CreateAccessToken(ctx context.Context, token *models.AccessToken) (int64, error) {
+	ret := _m.Called(ctx, token)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AccessToken) (int64, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AccessToken) int64); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.AccessToken) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: ctx, uid
 func (_m *FakePublicDashboardStore) Delete(ctx context.Context, uid string) (int64, error) {
 	ret := _m.Called(ctx, uid)
@@ -64,6 +88,30 @@ func (_m *FakePublicDashboardStore) Delete(ctx context.Context, uid string) (int
 	return r0, r1
 }
 
+// DeleteAccessToken provides a mock function with given fields: ctx, uid, accessToken
+func (_m *FakePublicDashboardStore) DeleteAccessToken(ctx context.Context, uid string, accessToken string) (int64, error) {
+	ret := _m.Called(ctx, uid, accessToken)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (int64, error)); ok {
+		return rf(ctx, uid, accessToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) int64); ok {
+		r0 = rf(ctx, uid, accessToken)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, uid, accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExistsEnabledByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardStore) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -138,6 +186,32 @@ func (_m *FakePublicDashboardStore) Find(ctx context.Context, uid string) (*mode
 	return r0, r1
 }
 
+// FindAccessTokens provides a mock function with given fields: ctx, orgId, uid
+func (_m *FakePublicDashboardStore) FindAccessTokens(ctx context.Context, orgId int64, uid string) ([]*models.AccessTokenListResponse, error) {
+	ret := _m.Called(ctx, orgId, uid)
+
+	var r0 []*models.AccessTokenListResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) ([]*models.AccessTokenListResponse, error)); ok {
+		return rf(ctx, orgId, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) []*models.AccessTokenListResponse); ok {
+		r0 = rf(ctx, orgId, uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AccessTokenListResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, orgId, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAllWithPagination provides a mock function with given fields: ctx, query
 func (_m *FakePublicDashboardStore) FindAllWithPagination(ctx context.Context, query *models.PublicDashboardListQuery) (*models.PublicDashboardListResponseWithPagination, error) {
 	ret := _m.Called(ctx, query)
@@ -37,6 +37,10 @@ type Service interface {
 
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
+
+	FindAccessTokens(ctx context.Context, orgId int64, dashboardUid string, uid string) ([]*AccessTokenListResponse, error)
+	CreateAccessToken(ctx context.Context, u *user.SignedInUser, dto *SaveAccessTokenDTO) (*AccessToken, error)
+	RevokeAccessToken(ctx context.Context, orgId int64, dashboardUid string, uid string, accessToken string) error
 }
 
 // ServiceWrapper these methods have different behavior between OSS and Enterprise. The latter would call the OSS service first
@@ -63,4 +67,8 @@ type Store interface {
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
 	GetMetrics(ctx context.Context) (*Metrics, error)
+
+	FindAccessTokens(ctx context.Context, orgId int64, uid string) ([]*AccessTokenListResponse, error)
+	CreateAccessToken(ctx context.Context, token *AccessToken) (int64, error)
+	DeleteAccessToken(ctx context.Context, uid string, accessToken string) (int64, error)
 }
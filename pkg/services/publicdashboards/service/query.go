@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -12,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/variables"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
@@ -164,6 +166,13 @@ func (pd *PublicDashboardServiceImpl) buildMetricRequest(dashboard *dashboards.D
 
 	ts := buildTimeSettings(dashboard, reqDTO, publicDashboard)
 
+	// replace the variables in the queries with the values allowed by the public dashboard
+	values := resolveVariables(dashboard.Data, publicDashboard, reqDTO.Variables)
+	for i := range queries {
+		datasourceType := queries[i].Get("datasource").Get("type").MustString()
+		queries[i] = simplejson.NewFromAny(variables.Interpolate(queries[i].Interface(), values, datasourceType))
+	}
+
 	// determine safe resolution to query data at
 	safeInterval, safeResolution := pd.getSafeIntervalAndMaxDataPoints(reqDTO, ts)
 	for i := range queries {
@@ -363,12 +372,69 @@ func sanitizeData(data *simplejson.Json) {
 	}
 }
 
+// resolveVariables returns the values of the dashboard variables to use in the queries. The variables configured in the
+// public dashboard take the selected values, or the first allowed value, and the other variables keep the value saved
+// with the dashboard. The selected values are expected to have been validated.
+func resolveVariables(data *simplejson.Json, pd *models.PublicDashboard, selected map[string][]string) map[string][]string {
+	values := make(map[string][]string)
+	for _, variableObj := range data.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(variableObj)
+		name := variable.Get("name").MustString()
+
+		value := variables.CurrentValue(variable)
+		if allowed := pd.Variables[name]; len(allowed) > 0 {
+			value = allowed[:1]
+			if len(selected[name]) > 0 {
+				value = selected[name]
+			}
+		}
+
+		if len(value) == 1 && value[0] == variables.AllValue {
+			value = variables.AllValues(variable)
+		}
+		values[name] = value
+	}
+	return values
+}
+
+// restrictVariables changes the variables of the dashboard data so that viewers can only select the values allowed by
+// the public dashboard. The other variables are hidden and keep the value saved with the dashboard.
+func restrictVariables(data *simplejson.Json, pd *models.PublicDashboard) {
+	for _, variableObj := range data.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(variableObj)
+		allowed := pd.Variables[variable.Get("name").MustString()]
+		if len(allowed) == 0 {
+			current := variable.Get("current").Interface()
+			variable.Set("hide", 2)
+			variable.Set("query", "")
+			variable.Set("refresh", 0)
+			if current != nil {
+				variable.Set("options", []any{current})
+			}
+			continue
+		}
+
+		options := make([]any, 0, len(allowed))
+		for i, value := range allowed {
+			options = append(options, map[string]any{"text": value, "value": value, "selected": i == 0})
+		}
+		variable.Set("type", "custom")
+		variable.Set("query", strings.Join(allowed, ","))
+		variable.Set("options", options)
+		variable.Set("current", map[string]any{"text": allowed[0], "value": allowed[0]})
+		variable.Set("refresh", 0)
+		variable.Set("includeAll", false)
+		variable.Del("definition")
+		variable.Del("datasource")
+	}
+}
+
 // NewDataTimeRange declared to be able to stub this function in tests
 var NewDataTimeRange = legacydata.NewDataTimeRange
 
 // BuildTimeSettings build time settings object using selected values if enabled and are valid or dashboard default values
 func buildTimeSettings(d *dashboards.Dashboard, reqDTO models.PublicDashboardQueryDTO, pd *models.PublicDashboard) models.TimeSettings {
-	from, to, timezone := getTimeRangeValuesOrDefault(reqDTO, d, pd.TimeSelectionEnabled)
+	from, to, timezone := getTimeRangeValuesOrDefault(reqDTO, d, pd)
 
 	timeRange := NewDataTimeRange(from, to)
 
@@ -388,14 +454,20 @@ func buildTimeSettings(d *dashboards.Dashboard, reqDTO models.PublicDashboardQue
 	}
 }
 
-// returns from, to and timezone from the request if the timeSelection is enabled or the dashboard default values
-func getTimeRangeValuesOrDefault(reqDTO models.PublicDashboardQueryDTO, d *dashboards.Dashboard, timeSelectionEnabled bool) (string, string, *time.Location) {
+// returns from, to and timezone from the request if the timeSelection is enabled, or the time range override of the
+// public dashboard, or the dashboard default values
+func getTimeRangeValuesOrDefault(reqDTO models.PublicDashboardQueryDTO, d *dashboards.Dashboard, pd *models.PublicDashboard) (string, string, *time.Location) {
 	from := d.Data.GetPath("time", "from").MustString()
 	to := d.Data.GetPath("time", "to").MustString()
 	dashboardTimezone := d.Data.GetPath("timezone").MustString()
 
+	if pd.TimeSettings != nil && pd.TimeSettings.From != "" && pd.TimeSettings.To != "" {
+		from = pd.TimeSettings.From
+		to = pd.TimeSettings.To
+	}
+
 	// we use the values from the request if the time selection is enabled and the values are valid
-	if timeSelectionEnabled {
+	if pd.TimeSelectionEnabled {
 		if reqDTO.TimeRange.From != "" && reqDTO.TimeRange.To != "" {
 			from = reqDTO.TimeRange.From
 			to = reqDTO.TimeRange.To
@@ -1776,6 +1776,31 @@ func TestBuildTimeSettings(t *testing.T) {
 				To:   selectionToMs,
 			},
 		},
+		{
+			name:      "should use time range override of the public dashboard",
+			dashboard: &dashboards.Dashboard{Data: buildJsonDataWithTimeRange("now-1d/d", "now-1d/d", "Europe/Madrid")},
+			pubdash:   &PublicDashboard{TimeSelectionEnabled: false, TimeSettings: &TimeSettings{From: "now-1h", To: "now"}},
+			reqDTO:    PublicDashboardQueryDTO{},
+			want: TimeSettings{
+				From: strconv.FormatInt(fakeNow.Add(-time.Hour).UnixMilli(), 10),
+				To:   strconv.FormatInt(fakeNow.UnixMilli(), 10),
+			},
+		},
+		{
+			name:      "should use selected values over time range override if time selection is enabled",
+			dashboard: &dashboards.Dashboard{Data: defaultDashboardData},
+			pubdash:   &PublicDashboard{TimeSelectionEnabled: true, TimeSettings: &TimeSettings{From: "now-1h", To: "now"}},
+			reqDTO: PublicDashboardQueryDTO{
+				TimeRange: TimeRangeDTO{
+					From: selectionFromMs,
+					To:   selectionToMs,
+				},
+			},
+			want: TimeSettings{
+				From: selectionFromMs,
+				To:   selectionToMs,
+			},
+		},
 		{
 			name:      "should use default values if time selection is enabled but the time range is empty",
 			dashboard: &dashboards.Dashboard{Data: defaultDashboardData},
@@ -1797,6 +1822,77 @@ func TestBuildTimeSettings(t *testing.T) {
 	}
 }
 
+func TestResolveVariables(t *testing.T) {
+	data := simplejson.NewFromAny(map[string]any{
+		"templating": map[string]any{
+			"list": []any{
+				map[string]any{"name": "env", "current": map[string]any{"value": "dev"}},
+				map[string]any{"name": "host", "current": map[string]any{"value": []any{"a", "b"}}},
+				map[string]any{
+					"name":    "dc",
+					"current": map[string]any{"value": "$__all"},
+					"options": []any{map[string]any{"value": "$__all"}, map[string]any{"value": "lga"}, map[string]any{"value": "ams"}},
+				},
+			},
+		},
+	})
+	pubdash := &PublicDashboard{Variables: map[string][]string{"env": {"prod", "staging"}}}
+
+	t.Run("uses the first allowed value of configured variables and the dashboard value of the others", func(t *testing.T) {
+		values := resolveVariables(data, pubdash, nil)
+		assert.Equal(t, map[string][]string{"env": {"prod"}, "host": {"a", "b"}, "dc": {"lga", "ams"}}, values)
+	})
+
+	t.Run("uses the selected values of configured variables", func(t *testing.T) {
+		values := resolveVariables(data, pubdash, map[string][]string{"env": {"staging"}})
+		assert.Equal(t, []string{"staging"}, values["env"])
+	})
+}
+
+func TestRestrictVariables(t *testing.T) {
+	data := simplejson.NewFromAny(map[string]any{
+		"templating": map[string]any{
+			"list": []any{
+				map[string]any{
+					"name":       "env",
+					"type":       "query",
+					"query":      "label_values(env)",
+					"definition": "label_values(env)",
+					"datasource": map[string]any{"uid": "prom"},
+					"current":    map[string]any{"text": "dev", "value": "dev"},
+					"refresh":    1,
+				},
+				map[string]any{
+					"name":    "host",
+					"type":    "query",
+					"query":   "label_values(host)",
+					"current": map[string]any{"text": "a", "value": "a"},
+					"refresh": 1,
+				},
+			},
+		},
+	})
+
+	restrictVariables(data, &PublicDashboard{Variables: map[string][]string{"env": {"prod", "staging"}}})
+
+	env := data.GetPath("templating", "list").GetIndex(0)
+	assert.Equal(t, "custom", env.Get("type").MustString())
+	assert.Equal(t, "prod,staging", env.Get("query").MustString())
+	assert.Equal(t, "prod", env.GetPath("current", "value").MustString())
+	assert.Len(t, env.Get("options").MustArray(), 2)
+	assert.Equal(t, 0, env.Get("refresh").MustInt())
+	_, hasDefinition := env.CheckGet("definition")
+	assert.False(t, hasDefinition)
+	_, hasDatasource := env.CheckGet("datasource")
+	assert.False(t, hasDatasource)
+
+	host := data.GetPath("templating", "list").GetIndex(1)
+	assert.Equal(t, 2, host.Get("hide").MustInt())
+	assert.Equal(t, "", host.Get("query").MustString())
+	assert.Equal(t, "a", host.GetPath("current", "value").MustString())
+	assert.Len(t, host.Get("options").MustArray(), 1)
+}
+
 func groupQueriesByDataSource(t *testing.T, queries []*simplejson.Json) (result [][]*simplejson.Json) {
 	t.Helper()
 	byDataSource := make(map[string][]*simplejson.Json)
@@ -87,6 +87,10 @@ func (pd *PublicDashboardServiceImpl) GetPublicDashboardForView(ctx context.Cont
 		PublicDashboardEnabled: pubdash.IsEnabled,
 	}
 	dash.Data.Get("timepicker").Set("hidden", !pubdash.TimeSelectionEnabled)
+	if ts := pubdash.TimeSettings; ts != nil && ts.From != "" && ts.To != "" {
+		dash.Data.Set("time", map[string]any{"from": ts.From, "to": ts.To})
+	}
+	restrictVariables(dash.Data, pubdash)
 
 	sanitizeData(dash.Data)
 
@@ -176,7 +180,12 @@ func (pd *PublicDashboardServiceImpl) Create(ctx context.Context, u *user.Signed
 	}
 
 	// ensure dashboard exists
-	_, err = pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	dash, err := pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validation.ValidateVariables(dash, dto.PublicDashboard.Variables)
 	if err != nil {
 		return nil, err
 	}
@@ -227,7 +236,12 @@ func (pd *PublicDashboardServiceImpl) Update(ctx context.Context, u *user.Signed
 	}
 
 	// validate dashboard exists
-	_, err = pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	dash, err := pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validation.ValidateVariables(dash, dto.PublicDashboard.Variables)
 	if err != nil {
 		return nil, err
 	}
@@ -306,6 +320,104 @@ func (pd *PublicDashboardServiceImpl) NewPublicDashboardAccessToken(ctx context.
 	return "", ErrInternalServerError.Errorf("failed to generate a unique accessToken for public dashboard")
 }
 
+// FindAccessTokens Returns the access tokens of a public dashboard, with their expiry and creator
+func (pd *PublicDashboardServiceImpl) FindAccessTokens(ctx context.Context, orgId int64, dashboardUid string, uid string) ([]*AccessTokenListResponse, error) {
+	if _, err := pd.findForDashboard(ctx, orgId, dashboardUid, uid); err != nil {
+		return nil, err
+	}
+
+	tokens, err := pd.store.FindAccessTokens(ctx, orgId, uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("FindAccessTokens: failed to find access tokens of public dashboard %s: %w", uid, err)
+	}
+
+	now := time.Now()
+	for _, token := range tokens {
+		token.IsExpired = AccessToken{ExpiresAt: token.ExpiresAt}.IsExpired(now)
+	}
+
+	return tokens, nil
+}
+
+// CreateAccessToken Adds an access token to a public dashboard, giving another link to the same public dashboard
+func (pd *PublicDashboardServiceImpl) CreateAccessToken(ctx context.Context, u *user.SignedInUser, dto *SaveAccessTokenDTO) (*AccessToken, error) {
+	now := time.Now()
+	err := validation.ValidateAccessToken(dto.AccessToken, now)
+	if err != nil {
+		return nil, err
+	}
+
+	pubdash, err := pd.findForDashboard(ctx, dto.OrgID, dto.DashboardUid, dto.Uid)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := pd.NewPublicDashboardAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &AccessToken{
+		AccessToken:        accessToken,
+		OrgId:              pubdash.OrgId,
+		PublicDashboardUid: pubdash.Uid,
+		ExpiresAt:          dto.AccessToken.ExpiresAt,
+		CreatedBy:          dto.UserId,
+		CreatedAt:          now,
+	}
+
+	affectedRows, err := pd.store.CreateAccessToken(ctx, token)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("CreateAccessToken: failed to create access token for public dashboard %s: %w", pubdash.Uid, err)
+	} else if affectedRows == 0 {
+		return nil, ErrInternalServerError.Errorf("CreateAccessToken: failed to create access token for public dashboard %s. 0 rows changed, no error reported.", pubdash.Uid)
+	}
+
+	pd.log.Info("Public dashboard access token created", "publicDashboardUid", pubdash.Uid, "dashboardUid", pubdash.DashboardUid, "user", u.Login)
+
+	return token, nil
+}
+
+// RevokeAccessToken Deletes an access token of a public dashboard. The other access tokens remain valid, and the oldest
+// of them becomes the access token of the public dashboard when it is revoked.
+func (pd *PublicDashboardServiceImpl) RevokeAccessToken(ctx context.Context, orgId int64, dashboardUid string, uid string, accessToken string) error {
+	if _, err := pd.findForDashboard(ctx, orgId, dashboardUid, uid); err != nil {
+		return err
+	}
+
+	affectedRows, err := pd.store.DeleteAccessToken(ctx, uid, accessToken)
+	if errors.Is(err, ErrLastAccessToken) {
+		return err
+	}
+	if err != nil {
+		return ErrInternalServerError.Errorf("RevokeAccessToken: failed to delete access token of public dashboard %s: %w", uid, err)
+	}
+
+	if affectedRows == 0 {
+		return ErrAccessTokenNotFound.Errorf("RevokeAccessToken: access token not found for public dashboard %s", uid)
+	}
+
+	return nil
+}
+
+// findForDashboard Gets a public dashboard by uid and ensures it belongs to the dashboard
+func (pd *PublicDashboardServiceImpl) findForDashboard(ctx context.Context, orgId int64, dashboardUid string, uid string) (*PublicDashboard, error) {
+	pubdash, err := pd.store.Find(ctx, uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("findForDashboard: failed to find public dashboard by uid: %s: %w", uid, err)
+	}
+
+	if pubdash == nil || pubdash.OrgId != orgId {
+		return nil, ErrPublicDashboardNotFound.Errorf("findForDashboard: public dashboard not found by uid: %s", uid)
+	}
+
+	if pubdash.DashboardUid != dashboardUid {
+		return nil, ErrInvalidUid.Errorf("findForDashboard: the public dashboard does not belong to the dashboard")
+	}
+
+	return pubdash, nil
+}
+
 // FindAllWithPagination Returns a list of public dashboards by orgId, based on permissions and with pagination
 func (pd *PublicDashboardServiceImpl) FindAllWithPagination(ctx context.Context, query *PublicDashboardListQuery) (*PublicDashboardListResponseWithPagination, error) {
 	query.Offset = query.Limit * (query.Page - 1)
@@ -463,6 +575,11 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		share = PublicShareType
 	}
 
+	timeSettings := dto.PublicDashboard.TimeSettings
+	if timeSettings == nil {
+		timeSettings = &TimeSettings{}
+	}
+
 	now := time.Now()
 
 	return &PublicDashboard{
@@ -472,13 +589,15 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		IsEnabled:            isEnabled,
 		AnnotationsEnabled:   annotationsEnabled,
 		TimeSelectionEnabled: timeSelectionEnabled,
-		TimeSettings:         &TimeSettings{},
+		TimeSettings:         timeSettings,
+		Variables:            dto.PublicDashboard.Variables,
 		Share:                share,
 		CreatedBy:            dto.UserId,
 		CreatedAt:            now,
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            now,
 		AccessToken:          accessToken,
+		ExpiresAt:            dto.PublicDashboard.ExpiresAt,
 	}, nil
 }
 
@@ -493,15 +612,28 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		share = pd.Share
 	}
 
+	timeSettings := pubdashDTO.TimeSettings
+	if timeSettings == nil {
+		timeSettings = pd.TimeSettings
+	}
+
+	variables := pubdashDTO.Variables
+	if variables == nil {
+		variables = pd.Variables
+	}
+
 	return &PublicDashboard{
 		Uid:                  pd.Uid,
+		AccessToken:          pd.AccessToken,
 		IsEnabled:            isEnabled,
 		AnnotationsEnabled:   annotationsEnabled,
 		TimeSelectionEnabled: timeSelectionEnabled,
-		TimeSettings:         pd.TimeSettings,
+		TimeSettings:         timeSettings,
+		Variables:            variables,
 		Share:                share,
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            time.Now(),
+		ExpiresAt:            pubdashDTO.ExpiresAt,
 	}
 }
 
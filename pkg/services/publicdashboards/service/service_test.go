@@ -1104,6 +1104,42 @@ func TestUpdatePublicDashboard(t *testing.T) {
 		assert.NotEqual(t, &time.Time{}, updatedPubdash.UpdatedAt)
 	})
 
+	t.Run("Updating sets the expiry of the access token", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour).UTC().Round(time.Second)
+		dto := &SavePublicDashboardDTO{
+			DashboardUid: dashboard.UID,
+			UserId:       7,
+			OrgID:        dashboard.OrgID,
+			PublicDashboard: &PublicDashboardDTO{
+				ExpiresAt: &expiresAt,
+			},
+		}
+
+		savedPubdash, err := service.Create(context.Background(), SignedInUser, dto)
+		require.NoError(t, err)
+
+		tokens, err := service.FindAccessTokens(context.Background(), dashboard.OrgID, dashboard.UID, savedPubdash.Uid)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		require.NotNil(t, tokens[0].ExpiresAt)
+		assert.True(t, expiresAt.Equal(*tokens[0].ExpiresAt))
+
+		expiresAt = expiresAt.Add(time.Hour)
+		dto.Uid = savedPubdash.Uid
+		_, err = service.Update(context.Background(), SignedInUser, dto)
+		require.NoError(t, err)
+
+		tokens, err = service.FindAccessTokens(context.Background(), dashboard.OrgID, dashboard.UID, savedPubdash.Uid)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.True(t, expiresAt.Equal(*tokens[0].ExpiresAt))
+
+		expired := time.Now().Add(-time.Hour)
+		dto.PublicDashboard.ExpiresAt = &expired
+		_, err = service.Update(context.Background(), SignedInUser, dto)
+		require.ErrorIs(t, err, ErrInvalidAccessTokenExpiry)
+	})
+
 	t.Run("Updating set empty time settings", func(t *testing.T) {
 		isEnabled := true
 
@@ -1732,6 +1768,108 @@ func TestDeleteByDashboard(t *testing.T) {
 	})
 }
 
+func TestAccessTokens(t *testing.T) {
+	pubdash := &PublicDashboard{Uid: "pubdash-uid", DashboardUid: "dash-uid", OrgId: 1}
+	newService := func(store *FakePublicDashboardStore) *PublicDashboardServiceImpl {
+		return &PublicDashboardServiceImpl{
+			log:   log.New("test.logger"),
+			store: store,
+		}
+	}
+
+	t.Run("FindAccessTokens flags expired access tokens", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		expired := time.Now().Add(-time.Hour)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil)
+		store.On("FindAccessTokens", mock.Anything, int64(1), "pubdash-uid").Return([]*AccessTokenListResponse{
+			{AccessToken: "a"},
+			{AccessToken: "b", ExpiresAt: &expired},
+		}, nil)
+
+		tokens, err := newService(store).FindAccessTokens(context.Background(), 1, "dash-uid", "pubdash-uid")
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+		assert.False(t, tokens[0].IsExpired)
+		assert.True(t, tokens[1].IsExpired)
+	})
+
+	t.Run("FindAccessTokens fails when public dashboard belongs to another dashboard", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil)
+
+		_, err := newService(store).FindAccessTokens(context.Background(), 1, "another-dash-uid", "pubdash-uid")
+		assert.ErrorIs(t, err, ErrInvalidUid)
+	})
+
+	t.Run("FindAccessTokens fails when public dashboard belongs to another org", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil)
+
+		_, err := newService(store).FindAccessTokens(context.Background(), 2, "dash-uid", "pubdash-uid")
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+	})
+
+	t.Run("CreateAccessToken creates an access token with expiry", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		expiresAt := time.Now().Add(time.Hour)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil)
+		store.On("FindByAccessToken", mock.Anything, mock.Anything).Return(nil, nil)
+		store.On("CreateAccessToken", mock.Anything, mock.MatchedBy(func(token *AccessToken) bool {
+			return token.PublicDashboardUid == "pubdash-uid" && token.OrgId == 1 && token.CreatedBy == 1234 && token.ExpiresAt.Equal(expiresAt)
+		})).Return(int64(1), nil)
+
+		token, err := newService(store).CreateAccessToken(context.Background(), SignedInUser, &SaveAccessTokenDTO{
+			Uid:          "pubdash-uid",
+			DashboardUid: "dash-uid",
+			OrgID:        1,
+			UserId:       1234,
+			AccessToken:  &AccessTokenDTO{ExpiresAt: &expiresAt},
+		})
+		require.NoError(t, err)
+		assert.True(t, validation.IsValidAccessToken(token.AccessToken))
+	})
+
+	t.Run("CreateAccessToken fails when expiry is in the past", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		expiresAt := time.Now().Add(-time.Hour)
+
+		_, err := newService(store).CreateAccessToken(context.Background(), SignedInUser, &SaveAccessTokenDTO{
+			Uid:          "pubdash-uid",
+			DashboardUid: "dash-uid",
+			OrgID:        1,
+			AccessToken:  &AccessTokenDTO{ExpiresAt: &expiresAt},
+		})
+		assert.ErrorIs(t, err, ErrInvalidAccessTokenExpiry)
+	})
+
+	t.Run("RevokeAccessToken deletes the access token", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil)
+		store.On("DeleteAccessToken", mock.Anything, "pubdash-uid", "token").Return(int64(1), nil)
+
+		err := newService(store).RevokeAccessToken(context.Background(), 1, "dash-uid", "pubdash-uid", "token")
+		require.NoError(t, err)
+	})
+
+	t.Run("RevokeAccessToken fails when access token is not found", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil)
+		store.On("DeleteAccessToken", mock.Anything, "pubdash-uid", "token").Return(int64(0), nil)
+
+		err := newService(store).RevokeAccessToken(context.Background(), 1, "dash-uid", "pubdash-uid", "token")
+		assert.ErrorIs(t, err, ErrAccessTokenNotFound)
+	})
+
+	t.Run("RevokeAccessToken fails for the last access token", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdash-uid").Return(pubdash, nil)
+		store.On("DeleteAccessToken", mock.Anything, "pubdash-uid", "token").Return(int64(0), ErrLastAccessToken.Errorf("last token"))
+
+		err := newService(store).RevokeAccessToken(context.Background(), 1, "dash-uid", "pubdash-uid", "token")
+		assert.ErrorIs(t, err, ErrLastAccessToken)
+	})
+}
+
 func TestGenerateAccessToken(t *testing.T) {
 	accessToken, err := GenerateAccessToken()
 
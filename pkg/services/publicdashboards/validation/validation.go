@@ -1,7 +1,13 @@
 package validation
 
 import (
+	"time"
+
 	"github.com/google/uuid"
+	"golang.org/x/exp/slices"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
 	"github.com/grafana/grafana/pkg/util"
@@ -13,6 +19,48 @@ func ValidatePublicDashboard(dto *SavePublicDashboardDTO) error {
 		return ErrInvalidShareType.Errorf("ValidateSavePublicDashboard: invalid share type")
 	}
 
+	if ts := dto.PublicDashboard.TimeSettings; ts != nil && (ts.From != "" || ts.To != "") {
+		timeRange := legacydata.NewDataTimeRange(ts.From, ts.To)
+		if _, err := timeRange.ParseFrom(); err != nil {
+			return ErrInvalidTimeRange.Errorf("ValidateSavePublicDashboard: time settings from is invalid")
+		}
+		if _, err := timeRange.ParseTo(); err != nil {
+			return ErrInvalidTimeRange.Errorf("ValidateSavePublicDashboard: time settings to is invalid")
+		}
+	}
+
+	return ValidateAccessToken(&AccessTokenDTO{ExpiresAt: dto.PublicDashboard.ExpiresAt}, time.Now())
+}
+
+// ValidateVariables checks that the variables allowed by a public dashboard exist in the dashboard and have at least one value
+func ValidateVariables(dashboard *dashboards.Dashboard, variables map[string][]string) error {
+	if len(variables) == 0 {
+		return nil
+	}
+
+	names := make(map[string]bool)
+	for _, variableObj := range dashboard.Data.GetPath("templating", "list").MustArray() {
+		names[simplejson.NewFromAny(variableObj).Get("name").MustString()] = true
+	}
+
+	for name, values := range variables {
+		if !names[name] {
+			return ErrInvalidVariables.Errorf("ValidateVariables: variable %s not found in dashboard", name)
+		}
+		if len(values) == 0 {
+			return ErrInvalidVariables.Errorf("ValidateVariables: variable %s has no allowed value", name)
+		}
+	}
+
+	return nil
+}
+
+// ValidateAccessToken checks that the expiry of a new access token is in the future
+func ValidateAccessToken(dto *AccessTokenDTO, now time.Time) error {
+	if dto.ExpiresAt != nil && !dto.ExpiresAt.After(now) {
+		return ErrInvalidAccessTokenExpiry.Errorf("ValidateAccessToken: expiry %s is in the past", dto.ExpiresAt)
+	}
+
 	return nil
 }
 
@@ -38,6 +86,19 @@ func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO, pd *Public
 		}
 	}
 
+	// only the values allowed by the public dashboard can be selected
+	for name, values := range req.Variables {
+		allowed, ok := pd.Variables[name]
+		if !ok {
+			return ErrVariableValueNotAllowed.Errorf("ValidateQueryPublicDashboardRequest: variable %s can not be selected", name)
+		}
+		for _, v := range values {
+			if !slices.Contains(allowed, v) {
+				return ErrVariableValueNotAllowed.Errorf("ValidateQueryPublicDashboardRequest: value %s of variable %s is not allowed", v, name)
+			}
+		}
+	}
+
 	return nil
 }
 
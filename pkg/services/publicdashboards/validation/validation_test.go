@@ -2,7 +2,10 @@ package validation
 
 import (
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,6 +25,70 @@ func TestValidatePublicDashboard(t *testing.T) {
 		err := ValidatePublicDashboard(dto)
 		require.Error(t, err)
 	})
+
+	t.Run("Returns error when time settings are invalid", func(t *testing.T) {
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{TimeSettings: &TimeSettings{From: "now-1x", To: "now"}}}
+
+		err := ValidatePublicDashboard(dto)
+		require.ErrorIs(t, err, ErrInvalidTimeRange)
+	})
+
+	t.Run("Returns no error when time settings are valid", func(t *testing.T) {
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{TimeSettings: &TimeSettings{From: "now-7d", To: "now"}}}
+
+		err := ValidatePublicDashboard(dto)
+		require.NoError(t, err)
+	})
+
+	t.Run("Returns error when access token expiry is in the past", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Hour)
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{ExpiresAt: &expiresAt}}
+
+		err := ValidatePublicDashboard(dto)
+		require.ErrorIs(t, err, ErrInvalidAccessTokenExpiry)
+	})
+}
+
+func TestValidateVariables(t *testing.T) {
+	dashboard := &dashboards.Dashboard{Data: simplejson.NewFromAny(map[string]any{
+		"templating": map[string]any{
+			"list": []any{map[string]any{"name": "env"}},
+		},
+	})}
+
+	t.Run("Returns no error when variables exist in dashboard", func(t *testing.T) {
+		require.NoError(t, ValidateVariables(dashboard, map[string][]string{"env": {"prod", "dev"}}))
+		require.NoError(t, ValidateVariables(dashboard, nil))
+	})
+
+	t.Run("Returns error when variable does not exist in dashboard", func(t *testing.T) {
+		err := ValidateVariables(dashboard, map[string][]string{"host": {"a"}})
+		require.ErrorIs(t, err, ErrInvalidVariables)
+	})
+
+	t.Run("Returns error when variable has no allowed value", func(t *testing.T) {
+		err := ValidateVariables(dashboard, map[string][]string{"env": {}})
+		require.ErrorIs(t, err, ErrInvalidVariables)
+	})
+}
+
+func TestValidateAccessToken(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Returns no error when access token does not expire", func(t *testing.T) {
+		require.NoError(t, ValidateAccessToken(&AccessTokenDTO{}, now))
+	})
+
+	t.Run("Returns no error when expiry is in the future", func(t *testing.T) {
+		expiresAt := now.Add(time.Hour)
+		require.NoError(t, ValidateAccessToken(&AccessTokenDTO{ExpiresAt: &expiresAt}, now))
+	})
+
+	t.Run("Returns error when expiry is in the past", func(t *testing.T) {
+		expiresAt := now.Add(-time.Hour)
+		err := ValidateAccessToken(&AccessTokenDTO{ExpiresAt: &expiresAt}, now)
+		require.ErrorIs(t, err, ErrInvalidAccessTokenExpiry)
+	})
 }
 
 func TestValidateQueryPublicDashboardRequest(t *testing.T) {
@@ -129,6 +196,30 @@ func TestValidateQueryPublicDashboardRequest(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Returns no error when selected variable values are allowed",
+			args: args{
+				req: PublicDashboardQueryDTO{Variables: map[string][]string{"env": {"prod"}}},
+				pd:  &PublicDashboard{Variables: map[string][]string{"env": {"prod", "dev"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Returns error when selected variable value is not allowed",
+			args: args{
+				req: PublicDashboardQueryDTO{Variables: map[string][]string{"env": {"staging"}}},
+				pd:  &PublicDashboard{Variables: map[string][]string{"env": {"prod", "dev"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Returns error when selected variable is not configured",
+			args: args{
+				req: PublicDashboardQueryDTO{Variables: map[string][]string{"host": {"a"}}},
+				pd:  &PublicDashboard{Variables: map[string][]string{"env": {"prod"}}},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
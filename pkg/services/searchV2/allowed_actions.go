@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -54,7 +55,12 @@ type allowedActions struct {
 
 func (s *StandardSearchService) createAllowedActions(ctx context.Context, orgId int64, user *user.SignedInUser, references []entityReferences) ([][]allowedActions, error) {
 	uidsPerKind := make(map[entityKind][]string)
+	var alertRuleFolders []string
 	for _, refs := range references {
+		if refs.entityKind == entityKindAlertRule {
+			alertRuleFolders = append(alertRuleFolders, refs.location)
+		}
+
 		if _, ok := uidsPerKind[refs.entityKind]; !ok {
 			uidsPerKind[refs.entityKind] = []string{}
 		}
@@ -96,6 +102,10 @@ func (s *StandardSearchService) createAllowedActions(ctx context.Context, orgId
 		allowedActionsByUid[entKind] = s.getAllowedActionsByUid(ctx, user, orgId, prefix, uids)
 	}
 
+	if len(alertRuleFolders) > 0 {
+		allowedActionsByUid[entityKindAlertRule] = s.getAlertRuleAllowedActions(ctx, user, orgId, references, alertRuleFolders)
+	}
+
 	dsActionsByUid, ok := allowedActionsByUid[entityKindDatasource]
 	if !ok {
 		dsActionsByUid = make(map[string][]string)
@@ -166,9 +176,54 @@ func (s *StandardSearchService) getAllowedActionsByUid(ctx context.Context, user
 	return out
 }
 
+const alertRuleActionPrefix = "alert.rules:"
+
+// getAlertRuleAllowedActions returns the actions allowed on alert rules by UID. The permissions of alert rules are
+// scoped to their folder, and inherited from its parent folders.
+func (s *StandardSearchService) getAlertRuleAllowedActions(ctx context.Context, user *user.SignedInUser,
+	orgID int64, references []entityReferences, folderUIDs []string) map[string][]string {
+	parentUIDs := make(map[string][]string, len(folderUIDs))
+	uids := append([]string{}, folderUIDs...)
+	for _, folderUID := range folderUIDs {
+		if _, ok := parentUIDs[folderUID]; ok {
+			continue
+		}
+		scopes, err := dashboards.GetInheritedScopes(ctx, orgID, folderUID, s.folderService)
+		if err != nil {
+			s.logger.Debug("Could not retrieve inherited folder scopes:", "err", err)
+		}
+		parents := make([]string, 0, len(scopes))
+		for _, scope := range scopes {
+			parents = append(parents, strings.TrimPrefix(scope, dashboards.ScopeFoldersPrefix))
+		}
+		parentUIDs[folderUID] = parents
+		uids = append(uids, parents...)
+	}
+	actionsByFolder := s.getAllowedActionsByUid(ctx, user, orgID, dashboards.ScopeFoldersPrefix, uids)
+
+	out := make(map[string][]string)
+	for _, ref := range references {
+		if ref.entityKind != entityKindAlertRule {
+			continue
+		}
+		var actions []string
+		for _, folderUID := range append([]string{ref.location}, parentUIDs[ref.location]...) {
+			for _, action := range actionsByFolder[folderUID] {
+				if strings.HasPrefix(action, alertRuleActionPrefix) && !stringInSlice(action, actions) {
+					actions = append(actions, action)
+				}
+			}
+		}
+		sort.Strings(actions)
+		out[ref.uid] = actions
+	}
+	return out
+}
+
 type entityReferences struct {
 	entityKind entityKind
 	uid        string
+	location   string
 	dsUids     []string
 }
 
@@ -217,6 +272,20 @@ func getEntityReferences(resp *backend.DataResponse) ([]entityReferences, error)
 			return nil, errors.New("invalid value in uid field")
 		}
 
+		if entityKind(kind) == entityKindAlertRule {
+			// The permissions of alert rules are scoped to their folder.
+			location := ""
+			if locationField, idx := frame.FieldByName("location"); idx != -1 {
+				location, _ = locationField.At(i).(string)
+			}
+			out = append(out, entityReferences{
+				entityKind: entityKindAlertRule,
+				uid:        uid,
+				location:   location,
+			})
+			continue
+		}
+
 		if entityKind(kind) != entityKindDashboard {
 			out = append(out, entityReferences{
 				entityKind: entityKind(kind),
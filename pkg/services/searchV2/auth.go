@@ -7,6 +7,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/user"
 )
@@ -30,7 +31,29 @@ type simpleAuthService struct {
 
 func (a *simpleAuthService) GetDashboardReadFilter(ctx context.Context, orgID int64, user *user.SignedInUser) (ResourceFilter, error) {
 	canReadDashboard, canReadFolder := accesscontrol.Checker(user, dashboards.ActionDashboardsRead), accesscontrol.Checker(user, dashboards.ActionFoldersRead)
+	canReadAlertRule, canReadDatasource := accesscontrol.Checker(user, accesscontrol.ActionAlertingRuleRead), accesscontrol.Checker(user, datasources.ActionRead)
+	folderScopes := func(folderUID string) []string {
+		scopes, err := dashboards.GetInheritedScopes(ctx, orgID, folderUID, a.folderService)
+		if err != nil {
+			a.logger.Debug("Could not retrieve inherited folder scopes:", "err", err)
+		}
+		return append(scopes, dashboards.ScopeFoldersProvider.GetResourceScopeUID(folderUID))
+	}
 	return func(kind entityKind, uid, parent string) bool {
+		switch kind {
+		case entityKindAlertRule:
+			// The rules are readable with the permission to read the rules of their folder.
+			scopes := folderScopes(parent)
+			return canReadAlertRule(scopes...) && canReadFolder(scopes...)
+		case entityKindLibraryPanel:
+			return parent == folder.GeneralFolderUID || canReadFolder(folderScopes(parent)...)
+		case entityKindDatasource:
+			return canReadDatasource(datasources.ScopeProvider.GetResourceScopeUID(uid))
+		case entityKindPlaylist:
+			// Playlists are readable by all the users of the organization.
+			return true
+		}
+
 		if kind == entityKindFolder {
 			scopes, err := dashboards.GetInheritedScopes(ctx, orgID, uid, a.folderService)
 			if err != nil {
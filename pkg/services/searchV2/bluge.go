@@ -33,12 +33,14 @@ const (
 	documentFieldTransformer = "transformer"
 	documentFieldDSUID       = "ds_uid"
 	documentFieldDSType      = "ds_type"
+	documentFieldQuery       = "query"         // text of a panel query
+	documentFieldQueryDSType = "query_ds_type" // datasource type of the panel query at the same index
 	DocumentFieldCreatedAt   = "created_at"
 	DocumentFieldUpdatedAt   = "updated_at"
 )
 
-func initOrgIndex(dashboards []dashboard, logger log.Logger, extendDoc ExtendDashboardFunc) (*orgIndex, error) {
-	dashboardWriter, err := bluge.OpenWriter(bluge.InMemoryOnlyConfig())
+func initOrgIndex(config bluge.Config, dashboards []dashboard, logger log.Logger, extendDoc ExtendDashboardFunc) (*orgIndex, error) {
+	dashboardWriter, err := bluge.OpenWriter(config)
 	if err != nil {
 		return nil, fmt.Errorf("error opening writer: %v", err)
 	}
@@ -207,6 +209,8 @@ func getDashboardPanelDocs(dash dashboard, location string) []*bluge.Document {
 			AddField(bluge.NewKeywordField(documentFieldLocation, location).Aggregatable().StoreValue()).
 			AddField(bluge.NewKeywordField(documentFieldKind, string(entityKindPanel)).Aggregatable().StoreValue()) // likely want independent index for this
 
+		addQueryFields(doc, getPanelQueries(panel))
+
 		for _, ref := range panel.References {
 			switch ref.Family {
 			case entity.StandardKindDashboard:
@@ -238,6 +242,40 @@ func getDashboardPanelDocs(dash dashboard, location string) []*bluge.Document {
 	return docs
 }
 
+func getKindEntityDoc(e kindEntity) *bluge.Document {
+	doc := newSearchDocument(kindDocumentID(e.kind, e.uid), e.name, "", e.url).
+		AddField(bluge.NewKeywordField(documentFieldKind, string(e.kind)).Aggregatable().StoreValue())
+	if e.location != "" {
+		doc.AddField(bluge.NewKeywordField(documentFieldLocation, e.location).Aggregatable().StoreValue())
+	}
+	if !e.updated.IsZero() {
+		doc.AddField(bluge.NewDateTimeField(DocumentFieldUpdatedAt, e.updated).Sortable().StoreValue())
+	}
+	if e.panelType != "" {
+		doc.AddField(bluge.NewKeywordField(documentFieldPanelType, e.panelType).Aggregatable().StoreValue())
+	}
+	for _, tag := range e.tags {
+		doc.AddField(bluge.NewKeywordField(documentFieldTag, tag).
+			StoreValue().
+			Aggregatable().
+			SearchTermPositions())
+	}
+	for _, dsType := range e.dsTypes {
+		doc.AddField(bluge.NewKeywordField(documentFieldDSType, dsType).
+			StoreValue().
+			Aggregatable().
+			SearchTermPositions())
+	}
+	for _, dsUID := range e.dsUIDs {
+		doc.AddField(bluge.NewKeywordField(documentFieldDSUID, dsUID).
+			StoreValue().
+			Aggregatable().
+			SearchTermPositions())
+	}
+	addQueryFields(doc, e.queries)
+	return doc
+}
+
 // Names need to be indexed a few ways to support key features
 func newSearchDocument(uid string, name string, descr string, url string) *bluge.Document {
 	doc := bluge.NewDocument(uid)
@@ -365,6 +403,92 @@ func getDashboardLocation(index *orgIndex, dashboardUID string) (string, bool, e
 	return dashboardLocation, found, err
 }
 
+type indexedDashboard struct {
+	kind    entityKind
+	updated time.Time
+}
+
+// getIndexedDashboards returns the kind and update time of the dashboards and folders in the index, by UID.
+func getIndexedDashboards(index *orgIndex) (map[string]indexedDashboard, error) {
+	dashboards := make(map[string]indexedDashboard)
+
+	reader, cancel, err := index.readerForIndex(indexTypeDashboard)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	fullQuery := bluge.NewBooleanQuery()
+	fullQuery.AddShould(bluge.NewTermQuery(string(entityKindDashboard)).SetField(documentFieldKind))
+	fullQuery.AddShould(bluge.NewTermQuery(string(entityKindFolder)).SetField(documentFieldKind))
+	req := bluge.NewAllMatches(fullQuery)
+	documentMatchIterator, err := reader.Search(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	match, err := documentMatchIterator.Next()
+	for err == nil && match != nil {
+		var uid string
+		var doc indexedDashboard
+		err = match.VisitStoredFields(func(field string, value []byte) bool {
+			switch field {
+			case documentFieldUID:
+				uid = string(value)
+			case documentFieldKind:
+				doc.kind = entityKind(value)
+			case DocumentFieldUpdatedAt:
+				doc.updated, _ = bluge.DecodeDateTime(value)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		dashboards[uid] = doc
+		// load the next document match
+		match, err = documentMatchIterator.Next()
+	}
+	return dashboards, err
+}
+
+// getIndexedKindEntities returns the update time of the entities of a kind in the index, by UID.
+func getIndexedKindEntities(index *orgIndex, kind entityKind) (map[string]time.Time, error) {
+	entities := make(map[string]time.Time)
+
+	reader, cancel, err := index.readerForIndex(indexTypeDashboard)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	req := bluge.NewAllMatches(bluge.NewTermQuery(string(kind)).SetField(documentFieldKind))
+	documentMatchIterator, err := reader.Search(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	match, err := documentMatchIterator.Next()
+	for err == nil && match != nil {
+		var uid string
+		var updated time.Time
+		err = match.VisitStoredFields(func(field string, value []byte) bool {
+			switch field {
+			case documentFieldUID:
+				uid = uidFromDocumentID(kind, string(value))
+			case DocumentFieldUpdatedAt:
+				updated, _ = bluge.DecodeDateTime(value)
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		entities[uid] = updated
+		// load the next document match
+		match, err = documentMatchIterator.Next()
+	}
+	return entities, err
+}
+
 //nolint:gocyclo
 func doSearchQuery(
 	ctx context.Context,
@@ -408,6 +532,14 @@ func doSearchQuery(
 			bq.AddShould(bluge.NewTermQuery(v).
 				SetField(documentFieldUID).
 				SetBoost(float64(count - i)))
+			// The document IDs of the kinds stored outside the dashboard table are prefixed.
+			for _, k := range q.Kind {
+				if kind := entityKind(k); kind.isStoredOutsideDashboards() {
+					bq.AddShould(bluge.NewTermQuery(kindDocumentID(kind, v)).
+						SetField(documentFieldUID).
+						SetBoost(float64(count - i)))
+				}
+			}
 		}
 		fullQuery.AddMust(bq)
 		hasConstraints = true
@@ -429,6 +561,12 @@ func doSearchQuery(
 		hasConstraints = true
 	}
 
+	// Panel queries
+	if q.PanelQuery != "" {
+		fullQuery.AddMust(newPanelQueryQuery(q.PanelQuery))
+		hasConstraints = true
+	}
+
 	// Datasource
 	if q.Datasource != "" {
 		fullQuery.AddMust(bluge.NewTermQuery(q.Datasource).SetField(documentFieldDSUID))
@@ -508,6 +646,7 @@ func doSearchQuery(
 	fTags := data.NewFieldFromFieldType(data.FieldTypeNullableJSON, 0)
 	fDSUIDs := data.NewFieldFromFieldType(data.FieldTypeJSON, 0)
 	fExplain := data.NewFieldFromFieldType(data.FieldTypeNullableJSON, 0)
+	fQueryHighlight := data.NewFieldFromFieldType(data.FieldTypeNullableJSON, 0)
 
 	fScore.Name = "score"
 	fUID.Name = "uid"
@@ -524,11 +663,15 @@ func doSearchQuery(
 	fDSUIDs.Name = "ds_uid"
 	fTags.Name = "tags"
 	fExplain.Name = "explain"
+	fQueryHighlight.Name = "query_highlight"
 
 	frame := data.NewFrame("Query results", fKind, fUID, fName, fPType, fURL, fTags, fDSUIDs, fLocation)
 	if q.Explain {
 		frame.Fields = append(frame.Fields, fScore, fExplain)
 	}
+	if q.PanelQuery != "" {
+		frame.Fields = append(frame.Fields, fQueryHighlight)
+	}
 	frame.SetMeta(&data.FrameMeta{
 		Type:   "search-results",
 		Custom: header,
@@ -550,6 +693,7 @@ func doSearchQuery(
 		loc := ""
 		var dsUIDs []string
 		var tags []string
+		var queries, queryDSTypes []string
 
 		err = match.VisitStoredFields(func(field string, value []byte) bool {
 			switch field {
@@ -569,6 +713,10 @@ func doSearchQuery(
 				dsUIDs = append(dsUIDs, string(value))
 			case documentFieldTag:
 				tags = append(tags, string(value))
+			case documentFieldQuery:
+				queries = append(queries, string(value))
+			case documentFieldQueryDSType:
+				queryDSTypes = append(queryDSTypes, string(value))
 			default:
 				ext(field, value)
 			}
@@ -581,7 +729,7 @@ func doSearchQuery(
 		}
 
 		fKind.Append(kind)
-		fUID.Append(uid)
+		fUID.Append(uidFromDocumentID(entityKind(kind), uid))
 		fPType.Append(ptype)
 		fName.Append(name)
 		fURL.Append(url)
@@ -610,6 +758,26 @@ func doSearchQuery(
 		jsb := json.RawMessage(js)
 		fDSUIDs.Append(jsb)
 
+		if q.PanelQuery != "" {
+			var snippets []string
+			for i, query := range queries {
+				dsType := ""
+				if i < len(queryDSTypes) {
+					dsType = queryDSTypes[i]
+				}
+				if snippet := highlightPanelQuery(query, dsType, q.PanelQuery); snippet != "" {
+					snippets = append(snippets, snippet)
+				}
+			}
+			if len(snippets) > 0 {
+				js, _ := json.Marshal(snippets)
+				jsb := json.RawMessage(js)
+				fQueryHighlight.Append(&jsb)
+			} else {
+				fQueryHighlight.Append(nil)
+			}
+		}
+
 		if q.Explain {
 			if isMatchAllQuery {
 				fScore.Append(float64(fieldLen + q.From))
@@ -0,0 +1,147 @@
+package searchV2
+
+import (
+	"context"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/services/store"
+)
+
+type indexInconsistency string
+
+const (
+	indexInconsistencyMissing    indexInconsistency = "missing"
+	indexInconsistencyOutdated   indexInconsistency = "outdated"
+	indexInconsistencyUnexpected indexInconsistency = "unexpected"
+)
+
+type indexRepair struct {
+	uid    string
+	kind   store.EntityType
+	reason indexInconsistency
+}
+
+// checkOrgIndexConsistency compares the documents of the org index with the dashboard table and the tables of the
+// other kinds, and re-indexes the entities which are missing, outdated or deleted, for example because an entity
+// event was lost. It returns the number of re-indexed entities.
+func (i *searchIndex) checkOrgIndexConsistency(ctx context.Context, orgID int64) (int, error) {
+	index, ok := i.getOrgIndex(orgID)
+	if !ok {
+		return 0, nil
+	}
+
+	// Dashboards changed during the check may be re-indexed needlessly, which is harmless.
+	versions, err := i.loader.LoadDashboardVersions(ctx, orgID)
+	if err != nil {
+		return 0, err
+	}
+	indexed, err := getIndexedDashboards(index)
+	if err != nil {
+		return 0, err
+	}
+
+	var repairs []indexRepair
+	for uid, updated := range versions {
+		doc, ok := indexed[uid]
+		if !ok {
+			repairs = append(repairs, indexRepair{uid: uid, kind: store.EntityTypeDashboard, reason: indexInconsistencyMissing})
+		} else if doc.updated.Unix() != updated.Unix() {
+			repairs = append(repairs, indexRepair{uid: uid, kind: store.EntityTypeDashboard, reason: indexInconsistencyOutdated})
+		}
+	}
+	for uid, doc := range indexed {
+		if _, ok := versions[uid]; ok {
+			continue
+		}
+		kind := store.EntityTypeDashboard
+		if doc.kind == entityKindFolder {
+			kind = store.EntityTypeFolder
+		}
+		repairs = append(repairs, indexRepair{uid: uid, kind: kind, reason: indexInconsistencyUnexpected})
+	}
+
+	for entityType, kind := range kindsByEntityType {
+		kindRepairs, err := i.checkKindConsistency(ctx, orgID, index, entityType, kind)
+		if err != nil {
+			return 0, err
+		}
+		repairs = append(repairs, kindRepairs...)
+	}
+	sort.Slice(repairs, func(a, b int) bool {
+		if repairs[a].kind != repairs[b].kind {
+			return repairs[a].kind < repairs[b].kind
+		}
+		return repairs[a].uid < repairs[b].uid
+	})
+
+	for _, r := range repairs {
+		i.logger.Info("Re-indexing inconsistent entity", "orgId", orgID, "kind", r.kind, "uid", r.uid, "reason", r.reason)
+		dashboardIndexInconsistenciesCounter.With(prometheus.Labels{"reason": string(r.reason)}).Inc()
+
+		eventType := store.EntityEventTypeUpdate
+		if r.reason == indexInconsistencyUnexpected {
+			eventType = store.EntityEventTypeDelete
+		}
+		if err := i.applyEvent(ctx, orgID, r.kind, r.uid, eventType); err != nil {
+			return 0, err
+		}
+	}
+	return len(repairs), nil
+}
+
+// checkKindConsistency compares the entities of a kind stored outside the dashboard table with their documents.
+func (i *searchIndex) checkKindConsistency(ctx context.Context, orgID int64, index *orgIndex, entityType store.EntityType, kind entityKind) ([]indexRepair, error) {
+	loader, ok := i.kindLoaders[entityType]
+	if !ok {
+		return nil, nil
+	}
+	entities, err := loader.LoadEntities(ctx, orgID, "")
+	if err != nil {
+		return nil, err
+	}
+	indexed, err := getIndexedKindEntities(index, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	var repairs []indexRepair
+	stored := make(map[string]bool, len(entities))
+	for _, e := range entities {
+		stored[e.uid] = true
+		updated, ok := indexed[e.uid]
+		if !ok {
+			repairs = append(repairs, indexRepair{uid: e.uid, kind: entityType, reason: indexInconsistencyMissing})
+		} else if updated.Unix() != e.updated.Unix() {
+			repairs = append(repairs, indexRepair{uid: e.uid, kind: entityType, reason: indexInconsistencyOutdated})
+		}
+	}
+	for uid := range indexed {
+		if !stored[uid] {
+			repairs = append(repairs, indexRepair{uid: uid, kind: entityType, reason: indexInconsistencyUnexpected})
+		}
+	}
+	return repairs, nil
+}
+
+// checkIndexConsistency checks the consistency of the indexes of all organizations.
+func (i *searchIndex) checkIndexConsistency(ctx context.Context) {
+	i.mu.RLock()
+	orgIDs := make([]int64, 0, len(i.perOrgIndex))
+	for orgID := range i.perOrgIndex {
+		orgIDs = append(orgIDs, orgID)
+	}
+	i.mu.RUnlock()
+
+	for _, orgID := range orgIDs {
+		repaired, err := i.checkOrgIndexConsistency(ctx, orgID)
+		if err != nil {
+			i.logger.Error("Error checking search index consistency", "orgId", orgID, "error", err)
+			continue
+		}
+		if repaired > 0 {
+			i.logger.Warn("Search index was inconsistent with the database", "orgId", orgID, "repaired", repaired)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package searchV2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/store"
+	"github.com/grafana/grafana/pkg/services/store/entity"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestCheckOrgIndexConsistency(t *testing.T) {
+	updated := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	loader := &testDashboardLoader{dashboards: []dashboard{
+		{id: 1, uid: "1", updated: updated, summary: &entity.EntitySummary{Name: "test"}},
+		{id: 2, uid: "2", updated: updated, summary: &entity.EntitySummary{Name: "boom"}},
+		{id: 3, uid: "3", updated: updated, summary: &entity.EntitySummary{Name: "kept"}},
+	}}
+	index := newSearchIndex(loader, nil, &store.MockEntityEventsService{}, &NoopDocumentExtender{}, func(ctx context.Context, folderId int64) (string, error) { return "x", nil }, tracing.InitializeTracerForTest(), featuremgmt.WithFeatures(), setting.SearchSettings{})
+	_, err := index.buildOrgIndex(context.Background(), testOrgID)
+	require.NoError(t, err)
+
+	repaired, err := index.checkOrgIndexConsistency(context.Background(), testOrgID)
+	require.NoError(t, err)
+	require.Equal(t, 0, repaired)
+
+	// Change the database without entity events: delete "1", update "2" and create "4".
+	loader.dashboards = []dashboard{
+		{id: 2, uid: "2", updated: updated.Add(time.Minute), summary: &entity.EntitySummary{Name: "nginx"}},
+		{id: 3, uid: "3", updated: updated, summary: &entity.EntitySummary{Name: "kept"}},
+		{id: 4, uid: "4", updated: updated, summary: &entity.EntitySummary{Name: "created"}},
+	}
+
+	repaired, err = index.checkOrgIndexConsistency(context.Background(), testOrgID)
+	require.NoError(t, err)
+	require.Equal(t, 3, repaired)
+
+	orgIdx, ok := index.getOrgIndex(testOrgID)
+	require.True(t, ok)
+	dashboards, err := getIndexedDashboards(orgIdx)
+	require.NoError(t, err)
+	require.Len(t, dashboards, 3)
+	require.NotContains(t, dashboards, "1")
+	require.Equal(t, updated.Add(time.Minute).Unix(), dashboards["2"].updated.Unix())
+	require.Equal(t, entityKindDashboard, dashboards["4"].kind)
+
+	repaired, err = index.checkOrgIndexConsistency(context.Background(), testOrgID)
+	require.NoError(t, err)
+	require.Equal(t, 0, repaired)
+}
@@ -21,19 +21,27 @@ type PermissionFilter struct {
 type entityKind string
 
 const (
-	entityKindPanel      entityKind = entity.StandardKindPanel
-	entityKindDashboard  entityKind = entity.StandardKindDashboard
-	entityKindFolder     entityKind = entity.StandardKindFolder
-	entityKindDatasource entityKind = entity.StandardKindDataSource
-	entityKindQuery      entityKind = entity.StandardKindQuery
+	entityKindPanel        entityKind = entity.StandardKindPanel
+	entityKindDashboard    entityKind = entity.StandardKindDashboard
+	entityKindFolder       entityKind = entity.StandardKindFolder
+	entityKindDatasource   entityKind = entity.StandardKindDataSource
+	entityKindQuery        entityKind = entity.StandardKindQuery
+	entityKindAlertRule    entityKind = entity.StandardKindAlertRule
+	entityKindPlaylist     entityKind = entity.StandardKindPlaylist
+	entityKindLibraryPanel entityKind = entity.StandardKindLibraryPanel
 )
 
 func (r entityKind) IsValid() bool {
-	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder
+	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder || r.isStoredOutsideDashboards()
 }
 
 func (r entityKind) supportsAuthzCheck() bool {
-	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder
+	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder || r.isStoredOutsideDashboards()
+}
+
+// isStoredOutsideDashboards is true for the kinds indexed from their own table, see kindLoader.
+func (r entityKind) isStoredOutsideDashboards() bool {
+	return r == entityKindAlertRule || r == entityKindLibraryPanel || r == entityKindDatasource || r == entityKindPlaylist
 }
 
 var (
@@ -79,6 +87,10 @@ func (q *PermissionFilter) canAccess(kind entityKind, id, location string) bool
 		decision := q.filter(kind, id, location)
 		q.logAccessDecision(decision, kind, id, "resourceFilter")
 		return decision
+	case entityKindAlertRule, entityKindLibraryPanel, entityKindDatasource, entityKindPlaylist:
+		decision := q.filter(kind, uidFromDocumentID(kind, id), location)
+		q.logAccessDecision(decision, kind, id, "resourceFilter")
+		return decision
 	case entityKindPanel:
 		matches := panelIdFieldRegex.FindStringSubmatch(id)
 		submatchCount := len(matches)
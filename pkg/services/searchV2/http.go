@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -13,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/middleware"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 type SearchHTTPService interface {
@@ -29,6 +32,21 @@ func ProvideSearchHTTPService(search SearchService) SearchHTTPService {
 
 func (s *searchHTTPService) RegisterHTTPRoutes(storageRoute routing.RouteRegister) {
 	storageRoute.Post("/", middleware.ReqSignedIn, routing.Wrap(s.doQuery))
+	storageRoute.Post("/orgs/:orgId/rebuild", middleware.ReqGrafanaAdmin, routing.Wrap(s.rebuildOrgIndex))
+}
+
+// rebuildOrgIndex rebuilds the search index of an organization from scratch, for example if it is suspected to be
+// inconsistent with the database.
+func (s *searchHTTPService) rebuildOrgIndex(c *contextmodel.ReqContext) response.Response {
+	orgID, err := strconv.ParseInt(web.Params(c.Req)[":orgId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "orgId is invalid", err)
+	}
+
+	if err := s.search.RebuildOrgIndex(c.Req.Context(), orgID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to rebuild the search index", err)
+	}
+	return response.Success("Search index rebuilt")
 }
 
 func (s *searchHTTPService) doQuery(c *contextmodel.ReqContext) response.Response {
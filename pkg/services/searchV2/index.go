@@ -34,6 +34,9 @@ type dashboardLoader interface {
 	// return dashboard with specified UID or empty slice if not found (this is required
 	// to apply partial update).
 	LoadDashboards(ctx context.Context, orgID int64, dashboardUID string) ([]dashboard, error)
+	// LoadDashboardVersions returns the update time of all the dashboards and folders of the
+	// organization by UID, to check the consistency of the index.
+	LoadDashboardVersions(ctx context.Context, orgID int64) (map[string]time.Time, error)
 }
 
 type eventStore interface {
@@ -63,10 +66,16 @@ type dashboard struct {
 type buildSignal struct {
 	orgID int64
 	done  chan error
+	// rebuild is true to rebuild an existing index from scratch.
+	rebuild bool
 }
 
 type orgIndex struct {
 	writers map[indexType]*bluge.Writer
+	// path is the directory of a persisted index, empty if the index is in memory only.
+	path string
+	// lastEventID is the ID of the last entity event applied to a persisted index.
+	lastEventID int64
 }
 
 type indexType string
@@ -90,6 +99,7 @@ func (i *orgIndex) readerForIndex(idxType indexType) (*bluge.Reader, func(), err
 type searchIndex struct {
 	mu                      sync.RWMutex
 	loader                  dashboardLoader
+	kindLoaders             map[store.EntityType]kindLoader
 	perOrgIndex             map[int64]*orgIndex
 	initializedOrgs         map[int64]bool
 	initialIndexingComplete bool
@@ -105,9 +115,10 @@ type searchIndex struct {
 	settings                setting.SearchSettings
 }
 
-func newSearchIndex(dashLoader dashboardLoader, evStore eventStore, extender DocumentExtender, folderIDs folderUIDLookup, tracer tracing.Tracer, features featuremgmt.FeatureToggles, settings setting.SearchSettings) *searchIndex {
+func newSearchIndex(dashLoader dashboardLoader, kindLoaders map[store.EntityType]kindLoader, evStore eventStore, extender DocumentExtender, folderIDs folderUIDLookup, tracer tracing.Tracer, features featuremgmt.FeatureToggles, settings setting.SearchSettings) *searchIndex {
 	return &searchIndex{
 		loader:          dashLoader,
+		kindLoaders:     kindLoaders,
 		eventStore:      evStore,
 		perOrgIndex:     map[int64]*orgIndex{},
 		initializedOrgs: map[int64]bool{},
@@ -182,7 +193,7 @@ func (i *searchIndex) sync(ctx context.Context) error {
 }
 
 func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh chan struct{}) error {
-	i.logger.Info("Initializing SearchV2", "dashboardLoadingBatchSize", i.settings.DashboardLoadingBatchSize, "fullReindexInterval", i.settings.FullReindexInterval, "indexUpdateInterval", i.settings.IndexUpdateInterval)
+	i.logger.Info("Initializing SearchV2", "dashboardLoadingBatchSize", i.settings.DashboardLoadingBatchSize, "fullReindexInterval", i.settings.FullReindexInterval, "indexUpdateInterval", i.settings.IndexUpdateInterval, "indexPath", i.settings.IndexPath)
 	initialSetupCtx, initialSetupSpan := i.tracer.Start(ctx, "searchV2 initialSetup")
 
 	reIndexInterval := i.settings.FullReindexInterval
@@ -193,6 +204,16 @@ func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh c
 	partialUpdateTimer := time.NewTimer(partialUpdateInterval)
 	defer partialUpdateTimer.Stop()
 
+	// The consistency check is disabled when the interval is 0, receiving from a nil channel blocks forever.
+	consistencyCheckInterval := i.settings.ConsistencyCheckInterval
+	var consistencyCheckTimer *time.Timer
+	var consistencyCheckCh <-chan time.Time
+	if consistencyCheckInterval > 0 {
+		consistencyCheckTimer = time.NewTimer(consistencyCheckInterval)
+		defer consistencyCheckTimer.Stop()
+		consistencyCheckCh = consistencyCheckTimer.C
+	}
+
 	var lastEventID int64
 	lastEvent, err := i.eventStore.GetLastEvent(initialSetupCtx)
 	if err != nil {
@@ -203,11 +224,26 @@ func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh c
 		lastEventID = lastEvent.Id
 	}
 
-	err = i.buildInitialIndexes(initialSetupCtx, orgIDs)
+	resumedOrgIDs, resumedEventID, err := i.buildInitialIndexes(initialSetupCtx, orgIDs, lastEventID)
 	if err != nil {
 		initialSetupSpan.End()
 		return err
 	}
+	if len(resumedOrgIDs) > 0 {
+		// Apply the events which happened since the persisted indexes were saved. Then check the resumed
+		// indexes, since updates could be lost if Grafana stopped before they were flushed to disk.
+		lastEventID = i.applyIndexUpdates(initialSetupCtx, resumedEventID)
+		for _, orgID := range resumedOrgIDs {
+			repaired, err := i.checkOrgIndexConsistency(initialSetupCtx, orgID)
+			if err != nil {
+				initialSetupSpan.End()
+				return fmt.Errorf("can't check consistency of persisted search index for org %d: %w", orgID, err)
+			}
+			if repaired > 0 {
+				i.logger.Info("Repaired persisted search index", "orgId", orgID, "repaired", repaired)
+			}
+		}
+	}
 
 	// This semaphore channel allows limiting concurrent async re-indexing routines to 1.
 	asyncReIndexSemaphore := make(chan struct{}, 1)
@@ -215,6 +251,9 @@ func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh c
 	// Channel to handle signals about asynchronous full re-indexing completion.
 	reIndexDoneCh := make(chan int64, 1)
 
+	// Channel to handle signals about asynchronous consistency check completion.
+	consistencyCheckDoneCh := make(chan struct{}, 1)
+
 	i.initializationMutex.Lock()
 	i.initialIndexingComplete = true
 	i.initializationMutex.Unlock()
@@ -224,7 +263,8 @@ func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh c
 	for {
 		select {
 		case doneCh := <-i.syncCh:
-			// Executed on search read requests to make sure index is consistent.
+			// Executed on search read requests to make sure index is consistent. Since every replica
+			// applies the same entity events before reading, replicas in HA setups return the same results.
 			lastEventID = i.applyIndexUpdates(ctx, lastEventID)
 			close(doneCh)
 		case <-partialUpdateTimer.C:
@@ -243,7 +283,7 @@ func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh c
 			// When search read request meets new not-indexed org we build index for it.
 			i.mu.RLock()
 			_, ok := i.perOrgIndex[signal.orgID]
-			if ok {
+			if ok && !signal.rebuild {
 				span.End()
 				// Index for org already exists, do nothing.
 				i.mu.RUnlock()
@@ -300,23 +340,66 @@ func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh c
 				partialUpdateTimer.Reset(0)
 			}
 			fullReIndexTimer.Reset(reIndexInterval)
+		case <-consistencyCheckCh:
+			consistencyCheckCtx, span := i.tracer.Start(ctx, "searchV2 consistency check timer")
+			go func() {
+				defer span.End()
+				// The check re-indexes the inconsistent dashboards, which must not be lost by a full
+				// re-indexing in progress.
+				asyncReIndexSemaphore <- struct{}{}
+				defer func() { <-asyncReIndexSemaphore }()
+
+				i.checkIndexConsistency(consistencyCheckCtx)
+				consistencyCheckDoneCh <- struct{}{}
+			}()
+		case <-consistencyCheckDoneCh:
+			consistencyCheckTimer.Reset(consistencyCheckInterval)
 		case <-ctx.Done():
+			if i.persisted() {
+				i.closePersistedIndexes(lastEventID)
+			}
 			return ctx.Err()
 		}
 	}
 }
 
-func (i *searchIndex) buildInitialIndexes(ctx context.Context, orgIDs []int64) error {
+// buildInitialIndexes builds the indexes of the organizations, or resumes their persisted indexes. It returns the
+// resumed organizations, and the lowest ID of the last event applied to their indexes.
+func (i *searchIndex) buildInitialIndexes(ctx context.Context, orgIDs []int64, lastEventID int64) ([]int64, int64, error) {
 	started := time.Now()
-	i.logger.Info("Start building in-memory indexes")
+	i.logger.Info("Start building indexes")
+	var resumedOrgIDs []int64
+	resumedEventID := lastEventID
 	for _, orgID := range orgIDs {
+		if i.persisted() {
+			index, ok, err := i.openPersistedOrgIndex(orgID, lastEventID)
+			if err != nil {
+				i.logger.Warn("Can't open persisted search index, rebuilding it", "orgId", orgID, "error", err)
+			}
+			if ok {
+				i.mu.Lock()
+				i.perOrgIndex[orgID] = index
+				i.mu.Unlock()
+				i.initializationMutex.Lock()
+				i.initializedOrgs[orgID] = true
+				i.initializationMutex.Unlock()
+
+				i.logger.Info("Resumed persisted search index", "orgId", orgID, "lastEventId", index.lastEventID)
+				resumedOrgIDs = append(resumedOrgIDs, orgID)
+				if index.lastEventID < resumedEventID {
+					resumedEventID = index.lastEventID
+				}
+				continue
+			}
+		}
+
 		err := i.buildInitialIndex(ctx, orgID)
 		if err != nil {
-			return fmt.Errorf("can't build initial dashboard search index for org %d: %w", orgID, err)
+			return nil, 0, fmt.Errorf("can't build initial dashboard search index for org %d: %w", orgID, err)
 		}
 	}
-	i.logger.Info("Finish building in-memory indexes", "elapsed", time.Since(started))
-	return nil
+	i.logger.Info("Finish building indexes", "elapsed", time.Since(started), "resumedOrgs", len(resumedOrgIDs))
+	return resumedOrgIDs, resumedEventID, nil
 }
 
 func (i *searchIndex) buildInitialIndex(ctx context.Context, orgID int64) error {
@@ -479,6 +562,20 @@ func (i *searchIndex) buildOrgIndex(ctx context.Context, orgID int64) (int, erro
 	}()
 
 	i.logger.Info("Start building org index", "orgId", orgID)
+
+	var lastEventID int64
+	if i.persisted() {
+		// The events after the last one are applied to the new index later, so it's the checkpoint of
+		// the new index.
+		lastEvent, err := i.eventStore.GetLastEvent(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("error getting last entity event: %w", err)
+		}
+		if lastEvent != nil {
+			lastEventID = lastEvent.Id
+		}
+	}
+
 	dashboards, err := i.loader.LoadDashboards(ctx, orgID, "")
 	orgSearchIndexLoadTime := time.Since(started)
 
@@ -493,13 +590,24 @@ func (i *searchIndex) buildOrgIndex(ctx context.Context, orgID int64) (int, erro
 	initOrgIndexSpan.SetAttributes("org_id", orgID, attribute.Key("org_id").Int64(orgID))
 	initOrgIndexSpan.SetAttributes("dashboardCount", len(dashboards), attribute.Key("dashboardCount").Int(len(dashboards)))
 
-	index, err := initOrgIndex(dashboards, i.logger, dashboardExtender)
+	config, path, err := i.newOrgIndexConfig(orgID)
+	if err != nil {
+		initOrgIndexSpan.End()
+		return 0, fmt.Errorf("error creating index directory: %w", err)
+	}
+	index, err := initOrgIndex(config, dashboards, i.logger, dashboardExtender)
 
 	initOrgIndexSpan.End()
 
 	if err != nil {
+		if path != "" {
+			_ = os.RemoveAll(path)
+		}
 		return 0, fmt.Errorf("error initializing index: %w", err)
 	}
+	index.path = path
+	index.lastEventID = lastEventID
+	i.indexKindEntities(ctx, orgID, index)
 	orgSearchIndexTotalTime := time.Since(started)
 	orgSearchIndexBuildTime := orgSearchIndexTotalTime - orgSearchIndexLoadTime
 
@@ -517,6 +625,12 @@ func (i *searchIndex) buildOrgIndex(ctx context.Context, orgID int64) (int, erro
 		}
 	}
 	i.perOrgIndex[orgID] = index
+	if index.path != "" {
+		if err := i.saveCheckpoint(orgID, index); err != nil {
+			i.logger.Warn("Can't save search index checkpoint", "orgId", orgID, "error", err)
+		}
+		removeStaleIndexGenerations(i.orgIndexDir(orgID), index.path)
+	}
 	i.mu.Unlock()
 
 	i.initializationMutex.Lock()
@@ -534,6 +648,26 @@ func (i *searchIndex) buildOrgIndex(ctx context.Context, orgID int64) (int, erro
 	return len(dashboards), nil
 }
 
+// indexKindEntities adds the entities stored outside the dashboard table to a new org index. A kind which can't be
+// loaded is skipped, so that the dashboards are still searchable.
+func (i *searchIndex) indexKindEntities(ctx context.Context, orgID int64, index *orgIndex) {
+	writer := index.writerForIndex(indexTypeDashboard)
+	for entityType, loader := range i.kindLoaders {
+		entities, err := loader.LoadEntities(ctx, orgID, "")
+		if err != nil {
+			i.logger.Warn("Error loading entities to index", "orgId", orgID, "kind", entityType, "error", err)
+			continue
+		}
+		batch := bluge.NewBatch()
+		for _, e := range entities {
+			batch.Insert(getKindEntityDoc(e))
+		}
+		if err := writer.Batch(batch); err != nil {
+			i.logger.Warn("Error indexing entities", "orgId", orgID, "kind", entityType, "error", err)
+		}
+	}
+}
+
 func (i *searchIndex) getOrgIndex(orgID int64) (*orgIndex, bool) {
 	i.mu.RLock()
 	defer i.mu.RUnlock()
@@ -568,6 +702,22 @@ func (i *searchIndex) getOrCreateOrgIndex(ctx context.Context, orgID int64) (*or
 	return index, nil
 }
 
+func (i *searchIndex) rebuildOrgIndex(ctx context.Context, orgID int64) error {
+	doneIndexing := make(chan error, 1)
+	signal := buildSignal{orgID: orgID, done: doneIndexing, rebuild: true}
+	select {
+	case i.buildSignals <- signal:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-doneIndexing:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func (i *searchIndex) reIndexFromScratch(ctx context.Context) {
 	i.mu.RLock()
 	orgIDs := make([]int64, 0, len(i.perOrgIndex))
@@ -602,21 +752,37 @@ func (i *searchIndex) applyIndexUpdates(ctx context.Context, lastEventID int64)
 		return lastEventID
 	}
 	if len(events) == 0 {
+		reportIndexLag(nil)
 		return lastEventID
 	}
+	reportIndexLag(events)
 	started := time.Now()
-	for _, e := range events {
+	for idx, e := range events {
 		err := i.applyEventOnIndex(ctx, e)
 		if err != nil {
 			i.logger.Error("Can't apply event", "error", err)
+			reportIndexLag(events[idx:])
+			i.saveCheckpoints(lastEventID)
 			return lastEventID
 		}
 		lastEventID = e.Id
 	}
+	reportIndexLag(nil)
+	i.saveCheckpoints(lastEventID)
 	i.logger.Info("Index updates applied", i.withCtxData(ctx, "indexEventsAppliedElapsed", time.Since(started), "numEvents", len(events))...)
 	return lastEventID
 }
 
+// reportIndexLag updates the index lag metrics with the events not applied yet.
+func reportIndexLag(pending []*store.EntityEvent) {
+	dashboardIndexPendingEvents.Set(float64(len(pending)))
+	if len(pending) == 0 {
+		dashboardIndexLagSeconds.Set(0)
+		return
+	}
+	dashboardIndexLagSeconds.Set(time.Since(time.Unix(pending[0].Created, 0)).Seconds())
+}
+
 func (i *searchIndex) applyEventOnIndex(ctx context.Context, e *store.EntityEvent) error {
 	i.logger.Debug("Processing event", "event", e)
 
@@ -651,6 +817,10 @@ func (i *searchIndex) applyEvent(ctx context.Context, orgID int64, kind store.En
 	}
 	i.mu.Unlock()
 
+	if entityKind, ok := kindsByEntityType[kind]; ok {
+		return i.applyKindEvent(ctx, orgID, kind, entityKind, uid)
+	}
+
 	// Both dashboard and folder share same DB table.
 	dbDashboards, err := i.loader.LoadDashboards(ctx, orgID, uid)
 	if err != nil {
@@ -685,6 +855,34 @@ func (i *searchIndex) applyEvent(ctx context.Context, orgID int64, kind store.En
 	return nil
 }
 
+// applyKindEvent updates or removes the document of an entity stored outside the dashboard table.
+func (i *searchIndex) applyKindEvent(ctx context.Context, orgID int64, entityType store.EntityType, kind entityKind, uid string) error {
+	loader, ok := i.kindLoaders[entityType]
+	if !ok {
+		return nil
+	}
+	entities, err := loader.LoadEntities(ctx, orgID, uid)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	index, ok := i.perOrgIndex[orgID]
+	if !ok {
+		// Skip event for org not yet fully indexed.
+		return nil
+	}
+
+	writer := index.writerForIndex(indexTypeDashboard)
+	if len(entities) == 0 {
+		return writer.Delete(bluge.NewDocument(kindDocumentID(kind, uid)).ID())
+	}
+	doc := getKindEntityDoc(entities[0])
+	return writer.Update(doc.ID(), doc)
+}
+
 func (i *searchIndex) removeDashboard(_ context.Context, index *orgIndex, dashboardUID string) error {
 	dashboardLocation, ok, err := getDashboardLocation(index, dashboardUID)
 	if err != nil {
@@ -959,6 +1157,25 @@ func (l sqlDashboardLoader) LoadDashboards(ctx context.Context, orgID int64, das
 	return dashboards, err
 }
 
+func (l sqlDashboardLoader) LoadDashboardVersions(ctx context.Context, orgID int64) (map[string]time.Time, error) {
+	rows := make([]*dashboardQueryResult, 0)
+	err := l.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("dashboard").
+			Where("org_id = ?", orgID).
+			Cols("uid", "updated").
+			Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		versions[row.Uid] = row.Updated
+	}
+	return versions, nil
+}
+
 func newFolderIDLookup(sql db.DB) folderUIDLookup {
 	return func(ctx context.Context, folderID int64) (string, error) {
 		uid := ""
@@ -2,9 +2,11 @@ package searchV2
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/blugelabs/bluge"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -17,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/store/entity"
+	kdash "github.com/grafana/grafana/pkg/services/store/kind/dashboard"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -24,8 +27,24 @@ type testDashboardLoader struct {
 	dashboards []dashboard
 }
 
-func (t *testDashboardLoader) LoadDashboards(_ context.Context, _ int64, _ string) ([]dashboard, error) {
-	return t.dashboards, nil
+func (t *testDashboardLoader) LoadDashboards(_ context.Context, _ int64, dashboardUID string) ([]dashboard, error) {
+	if dashboardUID == "" {
+		return t.dashboards, nil
+	}
+	for _, dash := range t.dashboards {
+		if dash.uid == dashboardUID {
+			return []dashboard{dash}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (t *testDashboardLoader) LoadDashboardVersions(_ context.Context, _ int64) (map[string]time.Time, error) {
+	versions := make(map[string]time.Time, len(t.dashboards))
+	for _, dash := range t.dashboards {
+		versions[dash.uid] = dash.updated
+	}
+	return versions, nil
 }
 
 var testLogger = log.New("index-test-logger")
@@ -62,7 +81,7 @@ func initTestIndexFromDashesExtended(t *testing.T, dashboards []dashboard, exten
 	dashboardLoader := &testDashboardLoader{
 		dashboards: dashboards,
 	}
-	index := newSearchIndex(dashboardLoader, &store.MockEntityEventsService{}, extender, func(ctx context.Context, folderId int64) (string, error) { return "x", nil }, tracing.InitializeTracerForTest(), featuremgmt.WithFeatures(), setting.SearchSettings{})
+	index := newSearchIndex(dashboardLoader, nil, &store.MockEntityEventsService{}, extender, func(ctx context.Context, folderId int64) (string, error) { return "x", nil }, tracing.InitializeTracerForTest(), featuremgmt.WithFeatures(), setting.SearchSettings{})
 	require.NotNil(t, index)
 	numDashboards, err := index.buildOrgIndex(context.Background(), testOrgID)
 	require.NoError(t, err)
@@ -548,6 +567,72 @@ func TestDashboardIndex_Panels(t *testing.T) {
 	})
 }
 
+func newNestedPanelWithQueries(id, dashId int64, name string, queries ...kdash.PanelQuery) *entity.EntitySummary {
+	summary := newNestedPanel(id, dashId, name)
+	summary.Fields = map[string]any{"queries": queries}
+	return summary
+}
+
+var dashboardsWithPanelQueries = []dashboard{
+	{
+		id:  1,
+		uid: "1",
+		summary: &entity.EntitySummary{
+			Name: "My Dash",
+			Nested: []*entity.EntitySummary{
+				newNestedPanelWithQueries(1, 1, "Errors",
+					kdash.PanelQuery{DatasourceType: "prometheus", Text: `sum(rate(http_requests_total{job="api", status=~"5.."}[5m]))`}),
+				newNestedPanelWithQueries(2, 1, "Requests",
+					kdash.PanelQuery{DatasourceType: "prometheus", Text: `sum(rate(http_requests_total{job="web"}[5m]))`},
+					kdash.PanelQuery{DatasourceType: "__expr__", Text: "$A * 100"}),
+				newNestedPanelWithQueries(3, 1, "Orders",
+					kdash.PanelQuery{DatasourceType: "mysql", Text: "SELECT count(*) FROM shop.orders"}),
+			},
+		},
+	},
+}
+
+func TestDashboardIndex_PanelQueries(t *testing.T) {
+	index := initTestOrgIndexFromDashes(t, dashboardsWithPanelQueries)
+
+	search := func(t *testing.T, panelQuery string) map[string]string {
+		t.Helper()
+		resp := doSearchQuery(context.Background(), testLogger, index, testAllowAllFilter,
+			DashboardQuery{PanelQuery: panelQuery}, &NoopQueryExtender{}, "")
+		require.NoError(t, resp.Error)
+		nameField, _ := resp.Frames[0].FieldByName("name")
+		highlightField, _ := resp.Frames[0].FieldByName("query_highlight")
+		require.NotNil(t, highlightField)
+		highlights := make(map[string]string, nameField.Len())
+		for i := 0; i < nameField.Len(); i++ {
+			highlight := ""
+			if v, ok := highlightField.At(i).(*json.RawMessage); ok && v != nil {
+				highlight = string(*v)
+			}
+			highlights[nameField.At(i).(string)] = highlight
+		}
+		return highlights
+	}
+
+	t.Run("metric name", func(t *testing.T) {
+		highlights := search(t, "http_requests_total")
+		require.Len(t, highlights, 2)
+		require.Contains(t, highlights["Errors"], `\u003cmark\u003ehttp_requests_total\u003c/mark\u003e`)
+	})
+
+	t.Run("label matcher", func(t *testing.T) {
+		highlights := search(t, `job="web"`)
+		require.Len(t, highlights, 1)
+		require.Contains(t, highlights, "Requests")
+	})
+
+	t.Run("sql table", func(t *testing.T) {
+		highlights := search(t, "orders")
+		require.Len(t, highlights, 1)
+		require.Contains(t, highlights["Orders"], `shop.\u003cmark\u003eorders\u003c/mark\u003e`)
+	})
+}
+
 var punctuationSplitNgramDashboards = []dashboard{
 	{
 		id:  1,
@@ -0,0 +1,292 @@
+package searchV2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/store"
+	kdash "github.com/grafana/grafana/pkg/services/store/kind/dashboard"
+)
+
+// kindEntity is an entity indexed along with the dashboards, but stored in its own table, such as an alert rule.
+type kindEntity struct {
+	kind     entityKind
+	uid      string
+	name     string
+	url      string
+	location string // parent folder UID
+	updated  time.Time
+	// tags are the label keys of dashboards, or "key=value" for the labels of alert rules.
+	tags      []string
+	panelType string
+	dsUIDs    []string
+	dsTypes   []string
+	queries   []kdash.PanelQuery // text of the alert rule queries
+}
+
+// kindLoader loads the entities of a kind from its table.
+type kindLoader interface {
+	// LoadEntities returns all the entities of the organization if uid is empty, or the entity with
+	// the UID, or an empty slice if not found (to apply partial updates).
+	LoadEntities(ctx context.Context, orgID int64, uid string) ([]kindEntity, error)
+}
+
+// kindsByEntityType maps the types of entity events to the kinds stored outside the dashboard table.
+var kindsByEntityType = map[store.EntityType]entityKind{
+	store.EntityTypeAlertRule:    entityKindAlertRule,
+	store.EntityTypeLibraryPanel: entityKindLibraryPanel,
+	store.EntityTypeDatasource:   entityKindDatasource,
+	store.EntityTypePlaylist:     entityKindPlaylist,
+}
+
+func newSQLKindLoaders(sql db.DB) map[store.EntityType]kindLoader {
+	return map[store.EntityType]kindLoader{
+		store.EntityTypeAlertRule:    &sqlAlertRuleLoader{sql: sql},
+		store.EntityTypeLibraryPanel: &sqlLibraryPanelLoader{sql: sql},
+		store.EntityTypeDatasource:   &sqlDatasourceLoader{sql: sql},
+		store.EntityTypePlaylist:     &sqlPlaylistLoader{sql: sql},
+	}
+}
+
+// kindDocumentID returns the document ID of an entity stored outside the dashboard table. The kind prefix
+// prevents conflicts with the UIDs of dashboards, which can't contain a slash.
+func kindDocumentID(kind entityKind, uid string) string {
+	return string(kind) + "/" + uid
+}
+
+// uidFromDocumentID returns the UID of the entity of a document.
+func uidFromDocumentID(kind entityKind, id string) string {
+	if !kind.isStoredOutsideDashboards() {
+		return id
+	}
+	return strings.TrimPrefix(id, string(kind)+"/")
+}
+
+// expressionDatasourceUID is the UID of the datasource used by the server side expressions of alert rules.
+const expressionDatasourceUID = "__expr__"
+
+type sqlAlertRuleLoader struct {
+	sql db.DB
+}
+
+type alertRuleQueryResult struct {
+	UID          string    `xorm:"uid"`
+	Title        string    `xorm:"title"`
+	NamespaceUID string    `xorm:"namespace_uid"`
+	Labels       string    `xorm:"labels"`
+	Data         string    `xorm:"data"`
+	Updated      time.Time `xorm:"updated"`
+}
+
+func (l *sqlAlertRuleLoader) LoadEntities(ctx context.Context, orgID int64, uid string) ([]kindEntity, error) {
+	rows := make([]*alertRuleQueryResult, 0)
+	dsTypes := make(map[string]string)
+	err := l.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Table("alert_rule").Where("org_id = ?", orgID)
+		if uid != "" {
+			sess.Where("uid = ?", uid)
+		}
+		if err := sess.Cols("uid", "title", "namespace_uid", "labels", "data", "updated").Find(&rows); err != nil {
+			return err
+		}
+		// The type of the datasource is needed to analyze the text of the queries whose model doesn't have it.
+		datasources := make([]*datasourceTypeQueryResult, 0)
+		if err := sess.SQL("SELECT uid, type FROM data_source WHERE org_id = ?", orgID).Find(&datasources); err != nil {
+			return err
+		}
+		for _, ds := range datasources {
+			dsTypes[ds.UID] = ds.Type
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]kindEntity, 0, len(rows))
+	for _, row := range rows {
+		e := kindEntity{
+			kind:     entityKindAlertRule,
+			uid:      row.UID,
+			name:     row.Title,
+			url:      fmt.Sprintf("/alerting/grafana/%s/view", row.UID),
+			location: row.NamespaceUID,
+			updated:  row.Updated,
+		}
+
+		labels := map[string]string{}
+		if row.Labels != "" {
+			if err := json.Unmarshal([]byte(row.Labels), &labels); err != nil {
+				return nil, fmt.Errorf("invalid labels of alert rule %s: %w", row.UID, err)
+			}
+		}
+		for k, v := range labels {
+			e.tags = append(e.tags, k+"="+v)
+		}
+		sort.Strings(e.tags)
+
+		var queries []struct {
+			DatasourceUID string         `json:"datasourceUid"`
+			Model         map[string]any `json:"model"`
+		}
+		if err := json.Unmarshal([]byte(row.Data), &queries); err != nil {
+			return nil, fmt.Errorf("invalid queries of alert rule %s: %w", row.UID, err)
+		}
+		for _, q := range queries {
+			if q.DatasourceUID != "" && q.DatasourceUID != expressionDatasourceUID && !stringInSlice(q.DatasourceUID, e.dsUIDs) {
+				e.dsUIDs = append(e.dsUIDs, q.DatasourceUID)
+			}
+			if text := kdash.QueryText(q.Model); text != "" {
+				e.queries = append(e.queries, kdash.PanelQuery{Text: text, DatasourceType: alertQueryDatasourceType(q.Model, dsTypes[q.DatasourceUID])})
+			}
+		}
+		entities = append(entities, e)
+	}
+	return entities, nil
+}
+
+type datasourceTypeQueryResult struct {
+	UID  string `xorm:"uid"`
+	Type string `xorm:"type"`
+}
+
+// alertQueryDatasourceType returns the datasource type of the model of an alert rule query, or the type of
+// the datasource of the query if the model doesn't have it.
+func alertQueryDatasourceType(model map[string]any, dsType string) string {
+	if ds, ok := model["datasource"].(map[string]any); ok {
+		if t, ok := ds["type"].(string); ok && t != "" {
+			return t
+		}
+	}
+	return dsType
+}
+
+type sqlLibraryPanelLoader struct {
+	sql db.DB
+}
+
+type libraryPanelQueryResult struct {
+	UID       string    `xorm:"uid"`
+	Name      string    `xorm:"name"`
+	Type      string    `xorm:"type"`
+	FolderUID string    `xorm:"folder_uid"`
+	Updated   time.Time `xorm:"updated"`
+}
+
+func (l *sqlLibraryPanelLoader) LoadEntities(ctx context.Context, orgID int64, uid string) ([]kindEntity, error) {
+	rows := make([]*libraryPanelQueryResult, 0)
+	err := l.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		// Library variables are not indexed, only library panels.
+		sql := "SELECT le.uid, le.name, le.type, le.updated, f.uid AS folder_uid FROM library_element AS le " +
+			"LEFT JOIN dashboard AS f ON f.id = le.folder_id AND f.org_id = le.org_id " +
+			"WHERE le.org_id = ? AND le.kind = 1"
+		args := []any{orgID}
+		if uid != "" {
+			sql += " AND le.uid = ?"
+			args = append(args, uid)
+		}
+		return sess.SQL(sql, args...).Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]kindEntity, 0, len(rows))
+	for _, row := range rows {
+		location := row.FolderUID
+		if location == "" {
+			location = folder.GeneralFolderUID
+		}
+		entities = append(entities, kindEntity{
+			kind:      entityKindLibraryPanel,
+			uid:       row.UID,
+			name:      row.Name,
+			url:       "/library-panels",
+			location:  location,
+			updated:   row.Updated,
+			panelType: row.Type,
+		})
+	}
+	return entities, nil
+}
+
+type sqlDatasourceLoader struct {
+	sql db.DB
+}
+
+type datasourceQueryResult struct {
+	UID     string    `xorm:"uid"`
+	Name    string    `xorm:"name"`
+	Type    string    `xorm:"type"`
+	Updated time.Time `xorm:"updated"`
+}
+
+func (l *sqlDatasourceLoader) LoadEntities(ctx context.Context, orgID int64, uid string) ([]kindEntity, error) {
+	rows := make([]*datasourceQueryResult, 0)
+	err := l.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Table("data_source").Where("org_id = ?", orgID)
+		if uid != "" {
+			sess.Where("uid = ?", uid)
+		}
+		return sess.Cols("uid", "name", "type", "updated").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entities := make([]kindEntity, 0, len(rows))
+	for _, row := range rows {
+		// The datasource references itself, so that filtering by datasource also finds it.
+		entities = append(entities, kindEntity{
+			kind:    entityKindDatasource,
+			uid:     row.UID,
+			name:    row.Name,
+			url:     fmt.Sprintf("/datasources/edit/%s", row.UID),
+			updated: row.Updated,
+			dsUIDs:  []string{row.UID},
+			dsTypes: []string{row.Type},
+		})
+	}
+	return entities, nil
+}
+
+type sqlPlaylistLoader struct {
+	sql db.DB
+}
+
+type playlistQueryResult struct {
+	UID  string `xorm:"uid"`
+	Name string `xorm:"name"`
+}
+
+func (l *sqlPlaylistLoader) LoadEntities(ctx context.Context, orgID int64, uid string) ([]kindEntity, error) {
+	rows := make([]*playlistQueryResult, 0)
+	err := l.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Table("playlist").Where("org_id = ?", orgID)
+		if uid != "" {
+			sess.Where("uid = ?", uid)
+		}
+		return sess.Cols("uid", "name").Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Playlists have no update time, the consistency check only finds the missing and deleted ones.
+	entities := make([]kindEntity, 0, len(rows))
+	for _, row := range rows {
+		entities = append(entities, kindEntity{
+			kind: entityKindPlaylist,
+			uid:  row.UID,
+			name: row.Name,
+			url:  fmt.Sprintf("/playlists/play/%s", row.UID),
+		})
+	}
+	return entities, nil
+}
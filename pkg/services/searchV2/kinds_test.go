@@ -0,0 +1,294 @@
+package searchV2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/folder/foldertest"
+	"github.com/grafana/grafana/pkg/services/store"
+	"github.com/grafana/grafana/pkg/services/store/entity"
+	kdash "github.com/grafana/grafana/pkg/services/store/kind/dashboard"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type testKindLoader struct {
+	entities []kindEntity
+}
+
+func (t *testKindLoader) LoadEntities(_ context.Context, _ int64, uid string) ([]kindEntity, error) {
+	if uid == "" {
+		return t.entities, nil
+	}
+	for _, e := range t.entities {
+		if e.uid == uid {
+			return []kindEntity{e}, nil
+		}
+	}
+	return nil, nil
+}
+
+var testKindUpdated = time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func initTestIndexWithKinds(t *testing.T) (*searchIndex, map[store.EntityType]*testKindLoader) {
+	t.Helper()
+	loaders := map[store.EntityType]*testKindLoader{
+		store.EntityTypeAlertRule: {entities: []kindEntity{
+			{kind: entityKindAlertRule, uid: "1", name: "CPU usage", url: "/alerting/grafana/1/view", location: "folder-1", updated: testKindUpdated, tags: []string{"team=backend"}, dsUIDs: []string{"ds-1"},
+				queries: []kdash.PanelQuery{{DatasourceType: "prometheus", Text: `avg(node_load1{job="node"})`}}},
+		}},
+		store.EntityTypeLibraryPanel: {entities: []kindEntity{
+			{kind: entityKindLibraryPanel, uid: "1", name: "CPU graph", url: "/library-panels", location: "general", updated: testKindUpdated, panelType: "timeseries"},
+		}},
+		store.EntityTypeDatasource: {entities: []kindEntity{
+			{kind: entityKindDatasource, uid: "ds-1", name: "Prometheus", url: "/datasources/edit/ds-1", updated: testKindUpdated, dsUIDs: []string{"ds-1"}, dsTypes: []string{"prometheus"}},
+		}},
+		store.EntityTypePlaylist: {entities: []kindEntity{
+			{kind: entityKindPlaylist, uid: "1", name: "CPU wall", url: "/playlists/play/1"},
+		}},
+	}
+	kindLoaders := make(map[store.EntityType]kindLoader, len(loaders))
+	for entityType, loader := range loaders {
+		kindLoaders[entityType] = loader
+	}
+
+	dashboards := []dashboard{
+		{id: 1, uid: "1", updated: testKindUpdated, summary: &entity.EntitySummary{Name: "test"}},
+	}
+	index := newSearchIndex(&testDashboardLoader{dashboards: dashboards}, kindLoaders, &store.MockEntityEventsService{}, &NoopDocumentExtender{}, func(ctx context.Context, folderId int64) (string, error) { return "x", nil }, tracing.InitializeTracerForTest(), featuremgmt.WithFeatures(), setting.SearchSettings{})
+	_, err := index.buildOrgIndex(context.Background(), testOrgID)
+	require.NoError(t, err)
+	return index, loaders
+}
+
+func searchUIDsByKind(t *testing.T, index *orgIndex, filter ResourceFilter, query DashboardQuery) map[string][]string {
+	t.Helper()
+	resp := doSearchQuery(context.Background(), testLogger, index, filter, query, &NoopQueryExtender{}, "")
+	require.NoError(t, resp.Error)
+	kindField, _ := resp.Frames[0].FieldByName("kind")
+	uidField, _ := resp.Frames[0].FieldByName("uid")
+	uids := map[string][]string{}
+	for i := 0; i < kindField.Len(); i++ {
+		kind := kindField.At(i).(string)
+		uids[kind] = append(uids[kind], uidField.At(i).(string))
+	}
+	return uids
+}
+
+func TestKindIndex(t *testing.T) {
+	index, _ := initTestIndexWithKinds(t)
+	orgIdx, ok := index.getOrgIndex(testOrgID)
+	require.True(t, ok)
+
+	t.Run("query matches all kinds", func(t *testing.T) {
+		uids := searchUIDsByKind(t, orgIdx, testAllowAllFilter, DashboardQuery{Query: "cpu"})
+		require.Equal(t, map[string][]string{
+			string(entityKindAlertRule):    {"1"},
+			string(entityKindLibraryPanel): {"1"},
+			string(entityKindPlaylist):     {"1"},
+		}, uids)
+	})
+
+	t.Run("filter by kind", func(t *testing.T) {
+		uids := searchUIDsByKind(t, orgIdx, testAllowAllFilter, DashboardQuery{Kind: []string{string(entityKindAlertRule), string(entityKindDatasource)}})
+		require.Equal(t, map[string][]string{
+			string(entityKindAlertRule):  {"1"},
+			string(entityKindDatasource): {"ds-1"},
+		}, uids)
+	})
+
+	t.Run("filter by label and datasource", func(t *testing.T) {
+		uids := searchUIDsByKind(t, orgIdx, testAllowAllFilter, DashboardQuery{Tags: []string{"team=backend"}})
+		require.Equal(t, map[string][]string{string(entityKindAlertRule): {"1"}}, uids)
+
+		uids = searchUIDsByKind(t, orgIdx, testAllowAllFilter, DashboardQuery{Datasource: "ds-1"})
+		require.Equal(t, map[string][]string{
+			string(entityKindAlertRule):  {"1"},
+			string(entityKindDatasource): {"ds-1"},
+		}, uids)
+	})
+
+	t.Run("filter by query text", func(t *testing.T) {
+		uids := searchUIDsByKind(t, orgIdx, testAllowAllFilter, DashboardQuery{PanelQuery: `job="node"`})
+		require.Equal(t, map[string][]string{string(entityKindAlertRule): {"1"}}, uids)
+	})
+
+	t.Run("filter by uid", func(t *testing.T) {
+		uids := searchUIDsByKind(t, orgIdx, testAllowAllFilter, DashboardQuery{Kind: []string{string(entityKindPlaylist)}, UIDs: []string{"1"}})
+		require.Equal(t, map[string][]string{string(entityKindPlaylist): {"1"}}, uids)
+	})
+
+	t.Run("permission filter gets the uid and the folder", func(t *testing.T) {
+		filter := func(kind entityKind, uid, parent string) bool {
+			return kind == entityKindAlertRule && uid == "1" && parent == "folder-1"
+		}
+		uids := searchUIDsByKind(t, orgIdx, filter, DashboardQuery{Query: "cpu"})
+		require.Equal(t, map[string][]string{string(entityKindAlertRule): {"1"}}, uids)
+	})
+
+	t.Run("facets", func(t *testing.T) {
+		resp := doSearchQuery(context.Background(), testLogger, orgIdx, testAllowAllFilter, DashboardQuery{Facet: []FacetField{{Field: documentFieldKind}}}, &NoopQueryExtender{}, "")
+		require.NoError(t, resp.Error)
+		require.Len(t, resp.Frames, 2)
+		require.Equal(t, 5, resp.Frames[1].Rows())
+	})
+}
+
+func TestKindIndexUpdates(t *testing.T) {
+	index, loaders := initTestIndexWithKinds(t)
+	orgIdx, ok := index.getOrgIndex(testOrgID)
+	require.True(t, ok)
+
+	loaders[store.EntityTypeAlertRule].entities = []kindEntity{
+		{kind: entityKindAlertRule, uid: "1", name: "Memory usage", location: "folder-1", updated: testKindUpdated.Add(time.Minute)},
+	}
+	loaders[store.EntityTypePlaylist].entities = nil
+
+	err := index.applyEvent(context.Background(), testOrgID, store.EntityTypeAlertRule, "1", store.EntityEventTypeUpdate)
+	require.NoError(t, err)
+	err = index.applyEvent(context.Background(), testOrgID, store.EntityTypePlaylist, "1", store.EntityEventTypeDelete)
+	require.NoError(t, err)
+
+	uids := searchUIDsByKind(t, orgIdx, testAllowAllFilter, DashboardQuery{Query: "usage"})
+	require.Equal(t, map[string][]string{string(entityKindAlertRule): {"1"}}, uids)
+	uids = searchUIDsByKind(t, orgIdx, testAllowAllFilter, DashboardQuery{Kind: []string{string(entityKindPlaylist)}})
+	require.Empty(t, uids)
+
+	// A dashboard with the same UID is not affected.
+	dashboards, err := getIndexedDashboards(orgIdx)
+	require.NoError(t, err)
+	require.Contains(t, dashboards, "1")
+}
+
+func TestCheckKindConsistency(t *testing.T) {
+	index, loaders := initTestIndexWithKinds(t)
+	repaired, err := index.checkOrgIndexConsistency(context.Background(), testOrgID)
+	require.NoError(t, err)
+	require.Equal(t, 0, repaired)
+
+	// Change the tables without entity events.
+	loaders[store.EntityTypeDatasource].entities = []kindEntity{
+		{kind: entityKindDatasource, uid: "ds-1", name: "Prometheus", updated: testKindUpdated.Add(time.Minute)},
+		{kind: entityKindDatasource, uid: "ds-2", name: "Loki", updated: testKindUpdated},
+	}
+	loaders[store.EntityTypeLibraryPanel].entities = nil
+
+	repaired, err = index.checkOrgIndexConsistency(context.Background(), testOrgID)
+	require.NoError(t, err)
+	require.Equal(t, 3, repaired)
+
+	orgIdx, ok := index.getOrgIndex(testOrgID)
+	require.True(t, ok)
+	datasources, err := getIndexedKindEntities(orgIdx, entityKindDatasource)
+	require.NoError(t, err)
+	require.Equal(t, testKindUpdated.Add(time.Minute).Unix(), datasources["ds-1"].Unix())
+	require.Contains(t, datasources, "ds-2")
+	libraryPanels, err := getIndexedKindEntities(orgIdx, entityKindLibraryPanel)
+	require.NoError(t, err)
+	require.Empty(t, libraryPanels)
+
+	repaired, err = index.checkOrgIndexConsistency(context.Background(), testOrgID)
+	require.NoError(t, err)
+	require.Equal(t, 0, repaired)
+}
+
+func TestAllowedActionsForAlertRules(t *testing.T) {
+	references := []entityReferences{
+		{entityKind: entityKindAlertRule, uid: "rule-1", location: "folder-1"},
+		{entityKind: entityKindAlertRule, uid: "rule-2", location: "folder-2"},
+	}
+	signedInUser := &user.SignedInUser{Permissions: map[int64]map[string][]string{
+		orgId: {
+			"alert.rules:read":  {"folders:uid:folder-1", "folders:uid:folder-2"},
+			"alert.rules:write": {"folders:uid:folder-1"},
+			"folders:read":      {"folders:uid:folder-1"},
+		},
+	}}
+
+	actions, err := service(t).createAllowedActions(context.Background(), orgId, signedInUser, references)
+	require.NoError(t, err)
+	require.Equal(t, [][]allowedActions{
+		{{EntityKind: entityKindAlertRule, UID: "rule-1", Actions: []string{"alert.rules:read", "alert.rules:write"}}},
+		{{EntityKind: entityKindAlertRule, UID: "rule-2", Actions: []string{"alert.rules:read"}}},
+	}, actions)
+}
+
+func TestAllowedActionsForAlertRulesInheritedFromParentFolders(t *testing.T) {
+	references := []entityReferences{
+		{entityKind: entityKindAlertRule, uid: "rule-1", location: "subfolder"},
+	}
+	signedInUser := &user.SignedInUser{Permissions: map[int64]map[string][]string{
+		orgId: {
+			"alert.rules:read":  {"folders:uid:parent"},
+			"alert.rules:write": {"folders:uid:subfolder"},
+		},
+	}}
+
+	s := service(t)
+	s.folderService = &foldertest.FakeService{ExpectedFolders: []*folder.Folder{{UID: "parent"}}}
+	actions, err := s.createAllowedActions(context.Background(), orgId, signedInUser, references)
+	require.NoError(t, err)
+	require.Equal(t, [][]allowedActions{
+		{{EntityKind: entityKindAlertRule, UID: "rule-1", Actions: []string{"alert.rules:read", "alert.rules:write"}}},
+	}, actions)
+}
+
+func TestIntegrationSQLKindLoaders(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+		statements := []string{
+			`INSERT INTO dashboard (org_id, uid, title, slug, data, version, created, updated, is_folder, folder_id) VALUES (1, 'folder-1', 'Folder', 'folder', '{}', 1, '2023-05-01 10:00:00', '2023-05-01 10:00:00', 1, 0)`,
+			`INSERT INTO alert_rule (org_id, uid, title, condition, data, updated, interval_seconds, version, namespace_uid, rule_group, no_data_state, exec_err_state, labels) VALUES (1, 'rule-1', 'CPU usage', 'B', '[{"refId":"A","datasourceUid":"ds-1","model":{"expr":"rate(node_cpu_seconds_total[5m])"}},{"refId":"B","datasourceUid":"__expr__","model":{"type":"math","expression":"$A > 0.9","datasource":{"type":"__expr__","uid":"__expr__"}}}]', '2023-05-01 10:00:00', 60, 1, 'folder-1', 'group', 'NoData', 'Alerting', '{"team":"backend"}')`,
+			`INSERT INTO library_element (org_id, folder_id, uid, name, kind, type, description, model, version, created, created_by, updated, updated_by) VALUES (1, (SELECT id FROM dashboard WHERE uid = 'folder-1'), 'panel-1', 'CPU graph', 1, 'timeseries', '', '{}', 1, '2023-05-01 10:00:00', 1, '2023-05-01 10:00:00', 1)`,
+			`INSERT INTO library_element (org_id, folder_id, uid, name, kind, type, description, model, version, created, created_by, updated, updated_by) VALUES (1, 0, 'variable-1', 'Variable', 2, 'query', '', '{}', 1, '2023-05-01 10:00:00', 1, '2023-05-01 10:00:00', 1)`,
+			`INSERT INTO data_source (org_id, uid, name, type, access, url, basic_auth, is_default, version, created, updated) VALUES (1, 'ds-1', 'Prometheus', 'prometheus', 'proxy', '', 0, 0, 1, '2023-05-01 10:00:00', '2023-05-01 10:00:00')`,
+			`INSERT INTO playlist (org_id, uid, name, ` + "`interval`" + `) VALUES (1, 'playlist-1', 'CPU wall', '5m')`,
+		}
+		for _, statement := range statements {
+			if _, err := sess.Exec(statement); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	loaders := newSQLKindLoaders(sqlStore)
+
+	rules, err := loaders[store.EntityTypeAlertRule].LoadEntities(context.Background(), 1, "")
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, "CPU usage", rules[0].name)
+	require.Equal(t, "folder-1", rules[0].location)
+	require.Equal(t, []string{"team=backend"}, rules[0].tags)
+	require.Equal(t, []string{"ds-1"}, rules[0].dsUIDs)
+	require.Equal(t, []kdash.PanelQuery{
+		{DatasourceType: "prometheus", Text: "rate(node_cpu_seconds_total[5m])"},
+		{DatasourceType: "__expr__", Text: "$A > 0.9"},
+	}, rules[0].queries)
+
+	panels, err := loaders[store.EntityTypeLibraryPanel].LoadEntities(context.Background(), 1, "")
+	require.NoError(t, err)
+	require.Len(t, panels, 1)
+	require.Equal(t, "folder-1", panels[0].location)
+	require.Equal(t, "timeseries", panels[0].panelType)
+
+	datasources, err := loaders[store.EntityTypeDatasource].LoadEntities(context.Background(), 1, "ds-1")
+	require.NoError(t, err)
+	require.Len(t, datasources, 1)
+	require.Equal(t, []string{"prometheus"}, datasources[0].dsTypes)
+
+	playlists, err := loaders[store.EntityTypePlaylist].LoadEntities(context.Background(), 1, "missing")
+	require.NoError(t, err)
+	require.Empty(t, playlists)
+}
@@ -0,0 +1,185 @@
+package searchV2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blugelabs/bluge"
+
+	"github.com/grafana/grafana/pkg/services/store"
+)
+
+// indexFormatVersion must be increased when the documents of the index change, so that the persisted indexes
+// written by previous versions are rebuilt instead of being resumed.
+const indexFormatVersion = 4
+
+const (
+	checkpointFileName    = "checkpoint.json"
+	indexGenerationPrefix = "dashboard-"
+)
+
+// indexCheckpoint is saved next to a persisted org index. It references the directory of the current index, and
+// the ID of the last entity event applied to it, so that the index can be updated incrementally after a restart.
+type indexCheckpoint struct {
+	Version     int    `json:"version"`
+	Generation  string `json:"generation"`
+	LastEventID int64  `json:"lastEventId"`
+	Updated     int64  `json:"updated"`
+}
+
+func (i *searchIndex) persisted() bool {
+	return i.settings.IndexPath != ""
+}
+
+func (i *searchIndex) orgIndexDir(orgID int64) string {
+	return filepath.Join(i.settings.IndexPath, strconv.FormatInt(orgID, 10))
+}
+
+// newOrgIndexConfig returns the config of a new index for the organization. Persisted indexes are written to a new
+// directory, which replaces the directory of the current index once the new index is built.
+func (i *searchIndex) newOrgIndexConfig(orgID int64) (bluge.Config, string, error) {
+	if !i.persisted() {
+		return bluge.InMemoryOnlyConfig(), "", nil
+	}
+	path := filepath.Join(i.orgIndexDir(orgID), fmt.Sprintf("%s%d", indexGenerationPrefix, time.Now().UnixNano()))
+	if err := os.MkdirAll(path, 0750); err != nil {
+		return bluge.Config{}, "", err
+	}
+	return bluge.DefaultConfig(path), path, nil
+}
+
+// openPersistedOrgIndex opens the persisted index of the organization. It returns false if the index can't be
+// resumed: it doesn't exist, it was written by another version, or the events it misses may have been deleted from
+// the entity events table already.
+func (i *searchIndex) openPersistedOrgIndex(orgID int64, lastEventID int64) (*orgIndex, bool, error) {
+	dir := i.orgIndexDir(orgID)
+	checkpoint, err := readCheckpoint(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	if checkpoint.Version != indexFormatVersion {
+		i.logger.Info("Persisted search index has another version", "orgId", orgID, "version", checkpoint.Version)
+		return nil, false, nil
+	}
+	if checkpoint.LastEventID > lastEventID {
+		// The index was written with another database.
+		i.logger.Info("Persisted search index is ahead of the entity events", "orgId", orgID, "checkpoint", checkpoint.LastEventID, "lastEventId", lastEventID)
+		return nil, false, nil
+	}
+	// Keep a margin, since old events are deleted periodically.
+	missedEventsDeleted := time.Since(time.Unix(checkpoint.Updated, 0)) > store.EntityEventsRetention-time.Hour
+	if checkpoint.LastEventID < lastEventID && missedEventsDeleted {
+		i.logger.Info("Persisted search index is too old to be updated", "orgId", orgID, "updated", time.Unix(checkpoint.Updated, 0))
+		return nil, false, nil
+	}
+
+	path := filepath.Join(dir, checkpoint.Generation)
+	writer, err := bluge.OpenWriter(bluge.DefaultConfig(path))
+	if err != nil {
+		return nil, false, fmt.Errorf("error opening writer: %w", err)
+	}
+	removeStaleIndexGenerations(dir, path)
+
+	return &orgIndex{
+		writers: map[indexType]*bluge.Writer{
+			indexTypeDashboard: writer,
+		},
+		path:        path,
+		lastEventID: checkpoint.LastEventID,
+	}, true, nil
+}
+
+// saveCheckpoint records the directory and the last applied event of a persisted org index.
+func (i *searchIndex) saveCheckpoint(orgID int64, index *orgIndex) error {
+	dir := i.orgIndexDir(orgID)
+	b, err := json.Marshal(indexCheckpoint{
+		Version:     indexFormatVersion,
+		Generation:  filepath.Base(index.path),
+		LastEventID: index.lastEventID,
+		Updated:     time.Now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that a crash doesn't leave a partially written checkpoint.
+	tmp := filepath.Join(dir, checkpointFileName+".tmp")
+	if err := os.WriteFile(tmp, b, 0640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(dir, checkpointFileName))
+}
+
+// saveCheckpoints advances the checkpoints of the persisted org indexes to the last applied event.
+func (i *searchIndex) saveCheckpoints(lastEventID int64) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for orgID, index := range i.perOrgIndex {
+		if index.path == "" || index.lastEventID >= lastEventID {
+			continue
+		}
+		index.lastEventID = lastEventID
+		if err := i.saveCheckpoint(orgID, index); err != nil {
+			i.logger.Warn("Can't save search index checkpoint", "orgId", orgID, "error", err)
+		}
+	}
+}
+
+// closePersistedIndexes saves the checkpoints and closes the writers of the persisted org indexes, which flushes
+// them to disk.
+func (i *searchIndex) closePersistedIndexes(lastEventID int64) {
+	i.saveCheckpoints(lastEventID)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for orgID, index := range i.perOrgIndex {
+		if index.path == "" {
+			continue
+		}
+		for _, w := range index.writers {
+			if err := w.Close(); err != nil {
+				i.logger.Warn("Can't close search index writer", "orgId", orgID, "error", err)
+			}
+		}
+		delete(i.perOrgIndex, orgID)
+	}
+}
+
+func readCheckpoint(dir string) (*indexCheckpoint, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning since the path is built from the configuration.
+	b, err := os.ReadFile(filepath.Join(dir, checkpointFileName))
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := &indexCheckpoint{}
+	if err := json.Unmarshal(b, checkpoint); err != nil {
+		return nil, fmt.Errorf("error parsing search index checkpoint: %w", err)
+	}
+	return checkpoint, nil
+}
+
+// removeStaleIndexGenerations deletes the index directories of the organization other than the current one, such as
+// the directory of a replaced index, or of an index whose build was interrupted.
+func removeStaleIndexGenerations(dir string, current string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), indexGenerationPrefix) || entry.Name() == filepath.Base(current) {
+			continue
+		}
+		_ = os.RemoveAll(filepath.Join(dir, entry.Name()))
+	}
+}
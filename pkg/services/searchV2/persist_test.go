@@ -0,0 +1,118 @@
+package searchV2
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/store"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func newTestPersistedIndex(t *testing.T, indexPath string, lastEventID int64) *searchIndex {
+	t.Helper()
+	eventStore := &store.MockEntityEventsService{}
+	eventStore.On("GetLastEvent", mock.Anything).Return(&store.EntityEvent{Id: lastEventID}, nil)
+	return newSearchIndex(&testDashboardLoader{dashboards: testDashboards}, nil, eventStore, &NoopDocumentExtender{}, func(ctx context.Context, folderId int64) (string, error) { return "x", nil }, tracing.InitializeTracerForTest(), featuremgmt.WithFeatures(), setting.SearchSettings{IndexPath: indexPath})
+}
+
+func indexGenerations(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var generations []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			generations = append(generations, entry.Name())
+		}
+	}
+	return generations
+}
+
+func TestPersistedIndex(t *testing.T) {
+	indexPath := t.TempDir()
+	orgDir := filepath.Join(indexPath, "1")
+
+	index := newTestPersistedIndex(t, indexPath, 3)
+	_, err := index.buildOrgIndex(context.Background(), testOrgID)
+	require.NoError(t, err)
+
+	checkpoint, err := readCheckpoint(orgDir)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), checkpoint.LastEventID)
+	require.Equal(t, indexFormatVersion, checkpoint.Version)
+
+	// The checkpoint follows the applied events.
+	index.saveCheckpoints(5)
+	index.closePersistedIndexes(5)
+	checkpoint, err = readCheckpoint(orgDir)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), checkpoint.LastEventID)
+
+	t.Run("resume", func(t *testing.T) {
+		index := newTestPersistedIndex(t, indexPath, 7)
+		orgIdx, ok, err := index.openPersistedOrgIndex(testOrgID, 7)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, int64(5), orgIdx.lastEventID)
+
+		dashboards, err := getIndexedDashboards(orgIdx)
+		require.NoError(t, err)
+		require.Len(t, dashboards, 2)
+		require.Contains(t, dashboards, "1")
+		require.Contains(t, dashboards, "2")
+
+		for _, w := range orgIdx.writers {
+			require.NoError(t, w.Close())
+		}
+	})
+
+	t.Run("index ahead of the events is rebuilt", func(t *testing.T) {
+		index := newTestPersistedIndex(t, indexPath, 2)
+		_, ok, err := index.openPersistedOrgIndex(testOrgID, 2)
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("index older than the events retention is rebuilt if it misses events", func(t *testing.T) {
+		checkpoint.Updated = time.Now().Add(-2 * store.EntityEventsRetention).Unix()
+		b, err := json.Marshal(checkpoint)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(orgDir, checkpointFileName), b, 0640))
+
+		index := newTestPersistedIndex(t, indexPath, 6)
+		_, ok, err := index.openPersistedOrgIndex(testOrgID, 6)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		// Without new events, nothing was missed.
+		orgIdx, ok, err := index.openPersistedOrgIndex(testOrgID, 5)
+		require.NoError(t, err)
+		require.True(t, ok)
+		for _, w := range orgIdx.writers {
+			require.NoError(t, w.Close())
+		}
+	})
+
+	t.Run("rebuild replaces the persisted index", func(t *testing.T) {
+		index := newTestPersistedIndex(t, indexPath, 8)
+		_, err := index.buildOrgIndex(context.Background(), testOrgID)
+		require.NoError(t, err)
+		_, err = index.buildOrgIndex(context.Background(), testOrgID)
+		require.NoError(t, err)
+
+		checkpoint, err := readCheckpoint(orgDir)
+		require.NoError(t, err)
+		require.Equal(t, int64(8), checkpoint.LastEventID)
+		require.Equal(t, []string{checkpoint.Generation}, indexGenerations(t, orgDir))
+		index.closePersistedIndexes(8)
+	})
+}
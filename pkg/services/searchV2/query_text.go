@@ -0,0 +1,277 @@
+package searchV2
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/blugelabs/bluge"
+	"github.com/blugelabs/bluge/analysis"
+	"github.com/blugelabs/bluge/analysis/analyzer"
+	"github.com/blugelabs/bluge/analysis/token"
+
+	"github.com/grafana/grafana/pkg/services/store/entity"
+	kdash "github.com/grafana/grafana/pkg/services/store/kind/dashboard"
+)
+
+// The text of the panel queries is indexed with an analyzer suited to the language of their datasource, so that
+// searching for a metric name or a label matcher finds the panels using it.
+
+// promQLTokenRegex matches, in order of priority, a label matcher, a string literal and an identifier.
+var promQLTokenRegex = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!~|!=|=)\s*"((?:[^"\\]|\\.)*)"|"((?:[^"\\]|\\.)*)"|` + "`([^`]*)`" + `|[a-zA-Z_:][a-zA-Z0-9_:]*`)
+
+var wordRegex = regexp.MustCompile(`[\p{L}\p{N}_]+`)
+
+// promQLTokenizer splits PromQL and LogQL queries into metric names, function names, label names and label
+// matchers. The label matcher `job="api"` is a single `job=api` token, with the label name at the same position.
+// The words of the other string literals, such as the line filters of LogQL, are tokens too.
+type promQLTokenizer struct{}
+
+func (t *promQLTokenizer) Tokenize(input []byte) analysis.TokenStream {
+	var tokens analysis.TokenStream
+	for _, m := range promQLTokenRegex.FindAllSubmatchIndex(input, -1) {
+		switch {
+		case m[2] >= 0: // label matcher
+			name := input[m[2]:m[3]]
+			matcher := make([]byte, 0, m[1]-m[0])
+			matcher = append(append(append(matcher, name...), input[m[4]:m[5]]...), input[m[6]:m[7]]...)
+			tokens = append(tokens,
+				newQueryToken(name, m[2], m[3], 1),
+				newQueryToken(matcher, m[0], m[1], 0))
+		case m[8] >= 0: // double quoted string
+			tokens = append(tokens, wordTokens(input, m[8], m[9])...)
+		case m[10] >= 0: // raw string
+			tokens = append(tokens, wordTokens(input, m[10], m[11])...)
+		default:
+			tokens = append(tokens, newQueryToken(input[m[0]:m[1]], m[0], m[1], 1))
+		}
+	}
+	return tokens
+}
+
+func wordTokens(input []byte, start, end int) analysis.TokenStream {
+	var tokens analysis.TokenStream
+	for _, w := range wordRegex.FindAllIndex(input[start:end], -1) {
+		tokens = append(tokens, newQueryToken(input[start+w[0]:start+w[1]], start+w[0], start+w[1], 1))
+	}
+	return tokens
+}
+
+func newQueryToken(term []byte, start, end, positionIncr int) *analysis.Token {
+	return &analysis.Token{
+		Term:         term,
+		Start:        start,
+		End:          end,
+		PositionIncr: positionIncr,
+		Type:         analysis.AlphaNumeric,
+	}
+}
+
+// regexpQueryTokenizer emits a token for each match of the regex.
+type regexpQueryTokenizer struct {
+	r *regexp.Regexp
+}
+
+func (t *regexpQueryTokenizer) Tokenize(input []byte) analysis.TokenStream {
+	var tokens analysis.TokenStream
+	for _, m := range t.r.FindAllIndex(input, -1) {
+		tokens = append(tokens, newQueryToken(input[m[0]:m[1]], m[0], m[1], 1))
+	}
+	return tokens
+}
+
+// dottedNameFilter adds the parts of dotted names, such as `schema.table` in SQL or the nodes of a Graphite
+// metric path, at the position of the name.
+type dottedNameFilter struct{}
+
+func (f *dottedNameFilter) Filter(input analysis.TokenStream) analysis.TokenStream {
+	output := make(analysis.TokenStream, 0, len(input))
+	for _, t := range input {
+		output = append(output, t)
+		if bytes.IndexByte(t.Term, '.') < 0 {
+			continue
+		}
+		start := t.Start
+		for _, part := range bytes.Split(t.Term, []byte{'.'}) {
+			if len(part) > 0 {
+				output = append(output, newQueryToken(part, start, start+len(part), 0))
+			}
+			start += len(part) + 1
+		}
+	}
+	return output
+}
+
+var promQLAnalyzer = &analysis.Analyzer{
+	Tokenizer:    &promQLTokenizer{},
+	TokenFilters: []analysis.TokenFilter{token.NewLowerCaseFilter()},
+}
+
+var sqlAnalyzer = &analysis.Analyzer{
+	Tokenizer: &regexpQueryTokenizer{r: regexp.MustCompile(`[\p{L}_][\p{L}\p{N}_$]*(?:\.[\p{L}_][\p{L}\p{N}_$]*)*`)},
+	TokenFilters: []analysis.TokenFilter{
+		&dottedNameFilter{},
+		token.NewLowerCaseFilter(),
+	},
+}
+
+var graphiteAnalyzer = &analysis.Analyzer{
+	Tokenizer: &regexpQueryTokenizer{r: regexp.MustCompile(`[\p{L}\p{N}_\-*:{}\[\]]+(?:\.[\p{L}\p{N}_\-*:{}\[\]]+)*`)},
+	TokenFilters: []analysis.TokenFilter{
+		&dottedNameFilter{},
+		token.NewLowerCaseFilter(),
+	},
+}
+
+var standardQueryAnalyzer = analyzer.NewStandardAnalyzer()
+
+// queryAnalyzers are all the analyzers of the panel queries. The searched text is analyzed with each of them.
+var queryAnalyzers = []*analysis.Analyzer{promQLAnalyzer, sqlAnalyzer, graphiteAnalyzer, standardQueryAnalyzer}
+
+func queryAnalyzerForDatasourceType(dsType string) *analysis.Analyzer {
+	switch dsType {
+	case "prometheus", "loki":
+		return promQLAnalyzer
+	case "mysql", "postgres", "grafana-postgresql-datasource", "mssql":
+		return sqlAnalyzer
+	case "graphite":
+		return graphiteAnalyzer
+	default:
+		return standardQueryAnalyzer
+	}
+}
+
+// getPanelQueries returns the queries of a panel summary. They are decoded from JSON when the summary was.
+func getPanelQueries(panel *entity.EntitySummary) []kdash.PanelQuery {
+	switch queries := panel.Fields["queries"].(type) {
+	case []kdash.PanelQuery:
+		return queries
+	case []any:
+		var decoded []kdash.PanelQuery
+		if b, err := json.Marshal(queries); err == nil {
+			_ = json.Unmarshal(b, &decoded)
+		}
+		return decoded
+	default:
+		return nil
+	}
+}
+
+// addQueryFields indexes the text of the queries with the analyzer of their datasource type.
+func addQueryFields(doc *bluge.Document, queries []kdash.PanelQuery) {
+	for _, query := range queries {
+		doc.AddField(bluge.NewTextField(documentFieldQuery, query.Text).
+			WithAnalyzer(queryAnalyzerForDatasourceType(query.DatasourceType)).
+			StoreValue().
+			SearchTermPositions())
+		doc.AddField(bluge.NewStoredOnlyField(documentFieldQueryDSType, []byte(query.DatasourceType)))
+	}
+}
+
+// newPanelQueryQuery matches the documents with a query containing all the terms of the text.
+func newPanelQueryQuery(text string) bluge.Query {
+	bq := bluge.NewBooleanQuery()
+	for _, a := range queryAnalyzers {
+		bq.AddShould(bluge.NewMatchQuery(text).
+			SetField(documentFieldQuery).
+			SetAnalyzer(a).
+			SetOperator(bluge.MatchQueryOperatorAnd))
+	}
+	return bq
+}
+
+const (
+	maxQuerySnippetLength = 200
+	querySnippetContext   = 40
+)
+
+// highlightPanelQuery returns a snippet of the panel query, with the terms of the searched text marked with the
+// <mark> tag, or an empty string if the query doesn't contain any of them.
+func highlightPanelQuery(query string, dsType string, search string) string {
+	a := queryAnalyzerForDatasourceType(dsType)
+	terms := make(map[string]bool)
+	for _, t := range a.Analyze([]byte(search)) {
+		terms[string(t.Term)] = true
+	}
+
+	type span struct{ start, end int }
+	var spans []span
+	for _, t := range a.Analyze([]byte(query)) {
+		if terms[string(t.Term)] {
+			spans = append(spans, span{t.Start, t.End})
+		}
+	}
+	if len(spans) == 0 {
+		return ""
+	}
+	// Merge the overlapping spans, such as a label matcher and its label name.
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		if last := &merged[len(merged)-1]; s.start <= last.end {
+			if s.end > last.end {
+				last.end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	spans = merged
+
+	start, end := 0, len(query)
+	if end-start > maxQuerySnippetLength {
+		start = runeStart(query, spans[0].start-querySnippetContext)
+		end = runeStart(query, start+maxQuerySnippetLength)
+	}
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString("…")
+	}
+	pos := start
+	for _, s := range spans {
+		if s.start >= end {
+			break
+		}
+		if s.start > pos {
+			sb.WriteString(html.EscapeString(query[pos:s.start]))
+			pos = s.start
+		}
+		sb.WriteString("<mark>")
+		sb.WriteString(html.EscapeString(query[pos:min(s.end, end)]))
+		sb.WriteString("</mark>")
+		pos = min(s.end, end)
+	}
+	sb.WriteString(html.EscapeString(query[pos:end]))
+	if end < len(query) {
+		sb.WriteString("…")
+	}
+	return sb.String()
+}
+
+// runeStart returns the offset of the rune at the offset, bounded by the string.
+func runeStart(s string, offset int) int {
+	if offset <= 0 {
+		return 0
+	}
+	if offset >= len(s) {
+		return len(s)
+	}
+	for offset > 0 && !utf8.RuneStart(s[offset]) {
+		offset--
+	}
+	return offset
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,81 @@
+package searchV2
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func analyzeQuery(dsType string, query string) []string {
+	var terms []string
+	for _, t := range queryAnalyzerForDatasourceType(dsType).Analyze([]byte(query)) {
+		terms = append(terms, string(t.Term))
+	}
+	return terms
+}
+
+func TestQueryAnalyzers(t *testing.T) {
+	tests := []struct {
+		name   string
+		dsType string
+		query  string
+		want   []string
+	}{
+		{
+			name:   "promql",
+			dsType: "prometheus",
+			query:  `sum by (job) (rate(http_requests_total{job="api", status=~"5.."}[5m]))`,
+			want:   []string{"sum", "by", "job", "rate", "http_requests_total", "job", "job=api", "status", "status=~5..", "m"},
+		},
+		{
+			name:   "logql",
+			dsType: "loki",
+			query:  `{app="API"} |= "Connection refused"`,
+			want:   []string{"app", "app=api", "connection", "refused"},
+		},
+		{
+			name:   "sql",
+			dsType: "mysql",
+			query:  `SELECT count(*) FROM Orders.order_item`,
+			want:   []string{"select", "count", "from", "orders.order_item", "orders", "order_item"},
+		},
+		{
+			name:   "graphite",
+			dsType: "graphite",
+			query:  `aliasByNode(servers.web-*.cpu.load, 1)`,
+			want:   []string{"aliasbynode", "servers.web-*.cpu.load", "servers", "web-*", "cpu", "load", "1"},
+		},
+		{
+			name:   "other",
+			dsType: "influxdb",
+			query:  `SELECT mean("value") FROM "cpu"`,
+			want:   []string{"select", "mean", "value", "from", "cpu"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, analyzeQuery(tt.dsType, tt.query))
+		})
+	}
+}
+
+func TestHighlightPanelQuery(t *testing.T) {
+	query := `sum(rate(http_requests_total{job="api"}[5m])) / sum(rate(http_requests_total[5m]))`
+
+	require.Equal(t,
+		`sum(rate(<mark>http_requests_total</mark>{job=&#34;api&#34;}[5m])) / sum(rate(<mark>http_requests_total</mark>[5m]))`,
+		highlightPanelQuery(query, "prometheus", "http_requests_total"))
+	require.Equal(t,
+		`sum(rate(http_requests_total{<mark>job=&#34;api&#34;</mark>}[5m])) / sum(rate(http_requests_total[5m]))`,
+		highlightPanelQuery(query, "prometheus", `job="api"`))
+	require.Empty(t, highlightPanelQuery(query, "prometheus", "node_cpu_seconds_total"))
+
+	t.Run("long queries are cut around the first match", func(t *testing.T) {
+		long := "SELECT " + strings.Repeat("a, ", 100) + "total FROM orders " + strings.Repeat("WHERE b ", 50)
+		snippet := highlightPanelQuery(long, "mysql", "total")
+		require.True(t, strings.HasPrefix(snippet, "…"))
+		require.True(t, strings.HasSuffix(snippet, "…"))
+		require.Contains(t, snippet, "<mark>total</mark> FROM orders")
+	})
+}
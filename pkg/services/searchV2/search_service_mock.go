@@ -60,6 +60,20 @@ func (_m *MockSearchService) IsReady(ctx context.Context, orgId int64) IsSearchR
 	return r0
 }
 
+// RebuildOrgIndex provides a mock function with given fields: ctx, orgID
+func (_m *MockSearchService) RebuildOrgIndex(ctx context.Context, orgID int64) error {
+	ret := _m.Called(ctx, orgID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, orgID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // RegisterDashboardIndexExtender provides a mock function with given fields: ext
 func (_m *MockSearchService) RegisterDashboardIndexExtender(ext DashboardIndexExtender) {
 	_m.Called(ext)
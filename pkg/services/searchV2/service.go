@@ -58,6 +58,29 @@ var (
 			Namespace: namespace,
 			Subsystem: subsystem,
 		})
+	dashboardIndexPendingEvents = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "dashboard_index_pending_events",
+			Help:      "The number of entity events not applied to the dashboard search index yet",
+		})
+	dashboardIndexLagSeconds = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "dashboard_index_lag_seconds",
+			Help:      "The age of the oldest entity event not applied to the dashboard search index yet",
+		})
+	dashboardIndexInconsistenciesCounter = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      "dashboard_index_inconsistencies_total",
+			Help:      "A counter for dashboards and folders re-indexed because the dashboard search index was inconsistent with the database",
+		},
+		[]string{"reason"},
+	)
 )
 
 type StandardSearchService struct {
@@ -70,6 +93,8 @@ type StandardSearchService struct {
 	orgService  org.Service
 	userService user.Service
 
+	folderService folder.Service
+
 	logger         log.Logger
 	dashboardIndex *searchIndex
 	extender       DashboardIndexExtender
@@ -98,6 +123,7 @@ func ProvideService(cfg *setting.Cfg, sql db.DB, entityEventStore store.EntityEv
 		},
 		dashboardIndex: newSearchIndex(
 			newSQLDashboardLoader(sql, tracer, cfg.Search),
+			newSQLKindLoaders(sql),
 			entityEventStore,
 			extender.GetDocumentExtender(),
 			newFolderIDLookup(sql),
@@ -111,6 +137,8 @@ func ProvideService(cfg *setting.Cfg, sql db.DB, entityEventStore store.EntityEv
 		orgService:  orgService,
 		userService: userService,
 		features:    features,
+
+		folderService: folderService,
 	}
 	return s
 }
@@ -143,6 +171,13 @@ func (s *StandardSearchService) TriggerReIndex() {
 	}
 }
 
+func (s *StandardSearchService) RebuildOrgIndex(ctx context.Context, orgID int64) error {
+	if _, err := s.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: orgID}); err != nil {
+		return err
+	}
+	return s.dashboardIndex.rebuildOrgIndex(ctx, orgID)
+}
+
 func (s *StandardSearchService) RegisterDashboardIndexExtender(ext DashboardIndexExtender) {
 	s.extender = ext
 	s.dashboardIndex.extender = ext.GetDocumentExtender()
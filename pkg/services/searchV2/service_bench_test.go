@@ -76,7 +76,7 @@ func getSignedInUser(folderCount, dashboardsPerFolder int) *user.SignedInUser {
 
 // Runs initial indexing of search service
 func runSearchService(searchService *StandardSearchService) error {
-	if err := searchService.dashboardIndex.buildInitialIndexes(context.Background(), []int64{int64(1)}); err != nil {
+	if _, _, err := searchService.dashboardIndex.buildInitialIndexes(context.Background(), []int64{int64(1)}, 0); err != nil {
 		return err
 	}
 	searchService.dashboardIndex.initialIndexingComplete = true
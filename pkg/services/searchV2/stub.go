@@ -28,6 +28,11 @@ func (s *stubSearchService) TriggerReIndex() {
 	// noop.
 }
 
+func (s *stubSearchService) RebuildOrgIndex(_ context.Context, _ int64) error {
+	// noop.
+	return nil
+}
+
 func NewStubSearchService() SearchService {
 	return &stubSearchService{}
 }
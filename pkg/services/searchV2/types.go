@@ -23,6 +23,7 @@ type DashboardQuery struct {
 	Tags               []string     `json:"tags,omitempty"`
 	Kind               []string     `json:"kind,omitempty"`
 	PanelType          string       `json:"panel_type,omitempty"`
+	PanelQuery         string       `json:"panel_query,omitempty"` // text of the panel queries, such as a metric name
 	UIDs               []string     `json:"uid,omitempty"`
 	Explain            bool         `json:"explain,omitempty"`            // adds details on why document matched
 	WithAllowedActions bool         `json:"withAllowedActions,omitempty"` // adds allowed actions per entity
@@ -47,4 +48,6 @@ type SearchService interface {
 	IsReady(ctx context.Context, orgId int64) IsSearchReadyResponse
 	RegisterDashboardIndexExtender(ext DashboardIndexExtender)
 	TriggerReIndex()
+	// RebuildOrgIndex rebuilds the search index of the organization from scratch, and returns once it's done.
+	RebuildOrgIndex(ctx context.Context, orgID int64) error
 }
@@ -7,11 +7,16 @@ import (
 )
 
 var (
-	ErrShortURLBadRequest   = errutil.BadRequest("shorturl.bad-request")
-	ErrShortURLNotFound     = errutil.NotFound("shorturl.not-found")
-	ErrShortURLAbsolutePath = errutil.ValidationFailed("shorturl.absolute-path", errutil.WithPublicMessage("Path should be relative"))
-	ErrShortURLInvalidPath  = errutil.ValidationFailed("shorturl.invalid-path", errutil.WithPublicMessage("Invalid short URL path"))
-	ErrShortURLInternal     = errutil.Internal("shorturl.internal")
+	ErrShortURLBadRequest    = errutil.BadRequest("shorturl.bad-request")
+	ErrShortURLNotFound      = errutil.NotFound("shorturl.not-found")
+	ErrShortURLAbsolutePath  = errutil.ValidationFailed("shorturl.absolute-path", errutil.WithPublicMessage("Path should be relative"))
+	ErrShortURLInvalidPath   = errutil.ValidationFailed("shorturl.invalid-path", errutil.WithPublicMessage("Invalid short URL path"))
+	ErrShortURLInvalidUID    = errutil.ValidationFailed("shorturl.invalid-uid", errutil.WithPublicMessage("Invalid short URL slug"))
+	ErrShortURLInvalidExpiry = errutil.ValidationFailed("shorturl.invalid-expiry", errutil.WithPublicMessage("Short URL expiry should be in the future"))
+	ErrShortURLExists        = errutil.ValidationFailed("shorturl.exists", errutil.WithPublicMessage("A short URL with the same slug already exists"))
+	ErrShortURLExpired       = errutil.NotFound("shorturl.expired", errutil.WithPublicMessage("Short URL has expired"))
+	ErrShortURLAccessDenied  = errutil.Forbidden("shorturl.access-denied", errutil.WithPublicMessage("Access denied to short URL"))
+	ErrShortURLInternal      = errutil.Internal("shorturl.internal")
 )
 
 type ShortUrl struct {
@@ -22,6 +27,23 @@ type ShortUrl struct {
 	CreatedBy  int64
 	CreatedAt  int64
 	LastSeenAt int64
+	// ExpiresAt is the unix time after which the short URL no longer redirects. Zero means it never expires.
+	ExpiresAt int64
+	// Clicks is the number of times the short URL has been followed.
+	Clicks int64
+}
+
+// IsExpired returns true if the short URL has an expiry before the given time.
+func (s *ShortUrl) IsExpired(now time.Time) bool {
+	return s.ExpiresAt > 0 && s.ExpiresAt <= now.Unix()
+}
+
+type CreateShortURLCommand struct {
+	Path string
+	// UID is a custom slug for the short URL. A random UID is generated if it's empty.
+	UID string
+	// ExpiresAt is the optional expiry of the short URL.
+	ExpiresAt time.Time
 }
 
 type DeleteShortUrlCommand struct {
@@ -29,3 +51,59 @@ type DeleteShortUrlCommand struct {
 
 	NumDeleted int64
 }
+
+type DeleteShortURLByUIDCommand struct {
+	OrgID int64
+	UID   string
+	// CreatedBy restricts the deletion to a short URL of a user when set.
+	CreatedBy int64
+}
+
+// DeleteShortURLsCommand deletes the short URLs of an org that have not been followed since a given time, and the
+// expired short URLs.
+type DeleteShortURLsCommand struct {
+	OrgID int64
+	// CreatedBy restricts the deletion to the short URLs of a user when set.
+	CreatedBy    int64
+	NotSeenSince time.Time
+
+	NumDeleted int64
+}
+
+type SearchShortURLsQuery struct {
+	OrgID int64
+	// CreatedBy restricts the search to the short URLs of a user when set.
+	CreatedBy int64
+	// Query matches the UID or the path of the short URLs.
+	Query string
+	// Sort is one of created-desc (default), created-asc, clicks-desc, clicks-asc, last-seen-desc or last-seen-asc.
+	Sort  string
+	Page  int
+	Limit int
+}
+
+type ShortURLDTO struct {
+	UID            string     `json:"uid"`
+	URL            string     `json:"url"`
+	Path           string     `json:"path"`
+	CreatedBy      int64      `json:"createdBy"`
+	CreatedByLogin string     `json:"createdByLogin"`
+	Created        time.Time  `json:"created"`
+	LastSeen       *time.Time `json:"lastSeen,omitempty"`
+	Expires        *time.Time `json:"expires,omitempty"`
+	Expired        bool       `json:"expired"`
+	Clicks         int64      `json:"clicks"`
+}
+
+type SearchShortURLsResult struct {
+	TotalCount int64          `json:"totalCount"`
+	ShortURLs  []*ShortURLDTO `json:"shortUrls"`
+	Page       int            `json:"page"`
+	PerPage    int            `json:"perPage"`
+}
+
+// ShortURLWithCreator is a short URL with the login of the user who created it.
+type ShortURLWithCreator struct {
+	ShortUrl       `xorm:"extends"`
+	CreatedByLogin string `xorm:"created_by_login"`
+}
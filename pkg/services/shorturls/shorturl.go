@@ -8,7 +8,11 @@ import (
 
 type Service interface {
 	GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*ShortUrl, error)
-	CreateShortURL(ctx context.Context, user *user.SignedInUser, path string) (*ShortUrl, error)
+	CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *CreateShortURLCommand) (*ShortUrl, error)
+	// UpdateLastSeenAt records that the short URL has been followed.
 	UpdateLastSeenAt(ctx context.Context, shortURL *ShortUrl) error
+	SearchShortURLs(ctx context.Context, query *SearchShortURLsQuery) (*SearchShortURLsResult, error)
+	DeleteShortURL(ctx context.Context, cmd *DeleteShortURLByUIDCommand) error
+	DeleteShortURLs(ctx context.Context, cmd *DeleteShortURLsCommand) error
 	DeleteStaleShortURLs(ctx context.Context, cmd *DeleteShortUrlCommand) error
 }
@@ -9,11 +9,14 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
 	"github.com/teris-io/shortid"
 )
 
 var getTime = time.Now
 
+const defaultSearchLimit = 100
+
 type ShortURLService struct {
 	SQLStore store
 }
@@ -27,15 +30,22 @@ func ProvideService(db db.DB) *ShortURLService {
 }
 
 func (s ShortURLService) GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error) {
-	return s.SQLStore.Get(ctx, user, uid)
+	shortURL, err := s.SQLStore.Get(ctx, user, uid)
+	if err != nil {
+		return nil, err
+	}
+	if shortURL.IsExpired(getTime()) {
+		return nil, shorturls.ErrShortURLExpired.Errorf("short URL expired at %d", shortURL.ExpiresAt)
+	}
+	return shortURL, nil
 }
 
 func (s ShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *shorturls.ShortUrl) error {
 	return s.SQLStore.Update(ctx, shortURL)
 }
 
-func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, relPath string) (*shorturls.ShortUrl, error) {
-	relPath = strings.TrimSpace(relPath)
+func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
+	relPath := strings.TrimSpace(cmd.Path)
 
 	if path.IsAbs(relPath) {
 		return nil, shorturls.ErrShortURLAbsolutePath.Errorf("expected relative path: %s", relPath)
@@ -44,27 +54,95 @@ func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedIn
 		return nil, shorturls.ErrShortURLInvalidPath.Errorf("path cannot contain '../': %s", relPath)
 	}
 
-	uid, err := shortid.Generate()
-	if err != nil {
-		return nil, shorturls.ErrShortURLInternal.Errorf("failed to generate uid: %w", err)
+	now := getTime()
+	var expiresAt int64
+	if !cmd.ExpiresAt.IsZero() {
+		if !cmd.ExpiresAt.After(now) {
+			return nil, shorturls.ErrShortURLInvalidExpiry.Errorf("expiry is not in the future: %s", cmd.ExpiresAt)
+		}
+		expiresAt = cmd.ExpiresAt.Unix()
+	}
+
+	uid := strings.TrimSpace(cmd.UID)
+	if uid != "" {
+		if !util.IsValidShortUID(uid) || util.IsShortUIDTooLong(uid) {
+			return nil, shorturls.ErrShortURLInvalidUID.Errorf("invalid uid: %s", uid)
+		}
+	} else {
+		var err error
+		if uid, err = shortid.Generate(); err != nil {
+			return nil, shorturls.ErrShortURLInternal.Errorf("failed to generate uid: %w", err)
+		}
 	}
 
-	now := time.Now().Unix()
 	shortURL := shorturls.ShortUrl{
 		OrgId:     user.OrgID,
 		Uid:       uid,
 		Path:      relPath,
 		CreatedBy: user.UserID,
-		CreatedAt: now,
+		CreatedAt: now.Unix(),
+		ExpiresAt: expiresAt,
 	}
 
 	if err := s.SQLStore.Insert(ctx, &shortURL); err != nil {
+		if shorturls.ErrShortURLExists.Is(err) {
+			return nil, err
+		}
 		return nil, shorturls.ErrShortURLInternal.Errorf("failed to insert shorturl: %w", err)
 	}
 
 	return &shortURL, nil
 }
 
+func (s ShortURLService) SearchShortURLs(ctx context.Context, query *shorturls.SearchShortURLsQuery) (*shorturls.SearchShortURLsResult, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchLimit
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+
+	found, total, err := s.SQLStore.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	now := getTime()
+	result := &shorturls.SearchShortURLsResult{
+		TotalCount: total,
+		ShortURLs:  make([]*shorturls.ShortURLDTO, 0, len(found)),
+		Page:       query.Page,
+		PerPage:    query.Limit,
+	}
+	for _, s := range found {
+		dto := &shorturls.ShortURLDTO{
+			UID:            s.Uid,
+			Path:           s.Path,
+			CreatedBy:      s.CreatedBy,
+			CreatedByLogin: s.CreatedByLogin,
+			Created:        time.Unix(s.CreatedAt, 0),
+			Expired:        s.IsExpired(now),
+			Clicks:         s.Clicks,
+		}
+		if s.LastSeenAt > 0 {
+			dto.LastSeen = util.Pointer(time.Unix(s.LastSeenAt, 0))
+		}
+		if s.ExpiresAt > 0 {
+			dto.Expires = util.Pointer(time.Unix(s.ExpiresAt, 0))
+		}
+		result.ShortURLs = append(result.ShortURLs, dto)
+	}
+	return result, nil
+}
+
+func (s ShortURLService) DeleteShortURL(ctx context.Context, cmd *shorturls.DeleteShortURLByUIDCommand) error {
+	return s.SQLStore.DeleteByUID(ctx, cmd)
+}
+
+func (s ShortURLService) DeleteShortURLs(ctx context.Context, cmd *shorturls.DeleteShortURLsCommand) error {
+	return s.SQLStore.DeleteUnused(ctx, cmd)
+}
+
 func (s ShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error {
 	return s.SQLStore.Delete(ctx, cmd)
 }
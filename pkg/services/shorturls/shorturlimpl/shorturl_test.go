@@ -21,7 +21,7 @@ func TestShortURLService(t *testing.T) {
 
 		service := ShortURLService{SQLStore: &sqlStore{db: store}}
 
-		newShortURL, err := service.CreateShortURL(context.Background(), user, refPath)
+		newShortURL, err := service.CreateShortURL(context.Background(), user, &shorturls.CreateShortURLCommand{Path: refPath})
 		require.NoError(t, err)
 		require.NotNil(t, newShortURL)
 		require.NotEmpty(t, newShortURL.Uid)
@@ -51,7 +51,7 @@ func TestShortURLService(t *testing.T) {
 		})
 
 		t.Run("and stale short urls can be deleted", func(t *testing.T) {
-			staleShortURL, err := service.CreateShortURL(context.Background(), user, refPath)
+			staleShortURL, err := service.CreateShortURL(context.Background(), user, &shorturls.CreateShortURLCommand{Path: refPath})
 			require.NoError(t, err)
 			require.NotNil(t, staleShortURL)
 			require.NotEmpty(t, staleShortURL.Uid)
@@ -76,6 +76,102 @@ func TestShortURLService(t *testing.T) {
 		})
 	})
 
+	t.Run("User can create short URLs with a custom slug and expiry", func(t *testing.T) {
+		service := ShortURLService{SQLStore: &sqlStore{db: store}}
+		ctx := context.Background()
+
+		shortURL, err := service.CreateShortURL(ctx, user, &shorturls.CreateShortURLCommand{
+			Path:      "d/ops/ops",
+			UID:       "ops-board",
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		require.Equal(t, "ops-board", shortURL.Uid)
+		require.NotZero(t, shortURL.ExpiresAt)
+
+		_, err = service.CreateShortURL(ctx, user, &shorturls.CreateShortURLCommand{Path: "d/ops/ops", UID: "ops-board"})
+		require.True(t, shorturls.ErrShortURLExists.Is(err))
+
+		_, err = service.CreateShortURL(ctx, user, &shorturls.CreateShortURLCommand{Path: "d/ops/ops", UID: "ops/board"})
+		require.True(t, shorturls.ErrShortURLInvalidUID.Is(err))
+
+		_, err = service.CreateShortURL(ctx, user, &shorturls.CreateShortURLCommand{Path: "d/ops/ops", ExpiresAt: time.Now().Add(-time.Hour)})
+		require.True(t, shorturls.ErrShortURLInvalidExpiry.Is(err))
+
+		t.Run("and expired short URLs can't be looked up", func(t *testing.T) {
+			origGetTime := getTime
+			t.Cleanup(func() {
+				getTime = origGetTime
+			})
+			getTime = func() time.Time {
+				return time.Now().Add(2 * time.Hour)
+			}
+
+			_, err := service.GetShortURLByUID(ctx, user, "ops-board")
+			require.True(t, shorturls.ErrShortURLExpired.Is(err))
+		})
+	})
+
+	t.Run("Short URLs can be searched and deleted by owner", func(t *testing.T) {
+		service := ShortURLService{SQLStore: &sqlStore{db: db.InitTestDB(t)}}
+		ctx := context.Background()
+		otherUser := *user
+		otherUser.UserID = 2
+
+		first, err := service.CreateShortURL(ctx, user, &shorturls.CreateShortURLCommand{Path: "d/first/first", UID: "first"})
+		require.NoError(t, err)
+		_, err = service.CreateShortURL(ctx, &otherUser, &shorturls.CreateShortURLCommand{Path: "d/second/second", UID: "second"})
+		require.NoError(t, err)
+
+		require.NoError(t, service.UpdateLastSeenAt(ctx, first))
+		require.NoError(t, service.UpdateLastSeenAt(ctx, first))
+		require.Equal(t, int64(2), first.Clicks)
+
+		result, err := service.SearchShortURLs(ctx, &shorturls.SearchShortURLsQuery{Sort: "clicks-desc"})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), result.TotalCount)
+		require.Equal(t, "first", result.ShortURLs[0].UID)
+		require.Equal(t, int64(2), result.ShortURLs[0].Clicks)
+		require.NotNil(t, result.ShortURLs[0].LastSeen)
+
+		result, err = service.SearchShortURLs(ctx, &shorturls.SearchShortURLsQuery{CreatedBy: otherUser.UserID})
+		require.NoError(t, err)
+		require.Len(t, result.ShortURLs, 1)
+		require.Equal(t, "second", result.ShortURLs[0].UID)
+
+		result, err = service.SearchShortURLs(ctx, &shorturls.SearchShortURLsQuery{Query: "first/"})
+		require.NoError(t, err)
+		require.Len(t, result.ShortURLs, 1)
+		require.Equal(t, "first", result.ShortURLs[0].UID)
+
+		result, err = service.SearchShortURLs(ctx, &shorturls.SearchShortURLsQuery{Query: "fir_t"})
+		require.NoError(t, err)
+		require.Empty(t, result.ShortURLs)
+
+		result, err = service.SearchShortURLs(ctx, &shorturls.SearchShortURLsQuery{Query: "%"})
+		require.NoError(t, err)
+		require.Empty(t, result.ShortURLs)
+
+		_, err = service.SearchShortURLs(ctx, &shorturls.SearchShortURLsQuery{Sort: "title"})
+		require.True(t, shorturls.ErrShortURLBadRequest.Is(err))
+
+		err = service.DeleteShortURL(ctx, &shorturls.DeleteShortURLByUIDCommand{UID: "second", CreatedBy: user.UserID})
+		require.True(t, shorturls.ErrShortURLNotFound.Is(err))
+
+		err = service.DeleteShortURL(ctx, &shorturls.DeleteShortURLByUIDCommand{UID: "second", CreatedBy: otherUser.UserID})
+		require.NoError(t, err)
+
+		t.Run("and unused short URLs can be deleted", func(t *testing.T) {
+			cmd := shorturls.DeleteShortURLsCommand{NotSeenSince: time.Unix(first.LastSeenAt-1, 0)}
+			require.NoError(t, service.DeleteShortURLs(ctx, &cmd))
+			require.Equal(t, int64(0), cmd.NumDeleted)
+
+			cmd = shorturls.DeleteShortURLsCommand{NotSeenSince: time.Unix(first.LastSeenAt, 0)}
+			require.NoError(t, service.DeleteShortURLs(ctx, &cmd))
+			require.Equal(t, int64(1), cmd.NumDeleted)
+		})
+	})
+
 	t.Run("User cannot look up nonexistent short URLs", func(t *testing.T) {
 		service := ShortURLService{SQLStore: &sqlStore{db: store}}
 
@@ -1,7 +1,9 @@
 package shorturlimpl
 
 import (
+	"bytes"
 	"context"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/shorturls"
@@ -12,6 +14,9 @@ type store interface {
 	Get(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error)
 	Update(ctx context.Context, shortURL *shorturls.ShortUrl) error
 	Insert(ctx context.Context, shortURL *shorturls.ShortUrl) error
+	Search(ctx context.Context, query *shorturls.SearchShortURLsQuery) ([]*shorturls.ShortURLWithCreator, int64, error)
+	DeleteByUID(ctx context.Context, cmd *shorturls.DeleteShortURLByUIDCommand) error
+	DeleteUnused(ctx context.Context, cmd *shorturls.DeleteShortURLsCommand) error
 	Delete(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error
 }
 
@@ -19,6 +24,20 @@ type sqlStore struct {
 	db db.DB
 }
 
+// likeEscaper escapes the LIKE wildcards of a search query with the '!' escape
+// character, which unlike the backslash means the same for every database.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+var searchSortOrder = map[string]string{
+	"":               "short_url.created_at DESC",
+	"created-desc":   "short_url.created_at DESC",
+	"created-asc":    "short_url.created_at ASC",
+	"clicks-desc":    "short_url.clicks DESC",
+	"clicks-asc":     "short_url.clicks ASC",
+	"last-seen-desc": "short_url.last_seen_at DESC",
+	"last-seen-asc":  "short_url.last_seen_at ASC",
+}
+
 func (s sqlStore) Get(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error) {
 	var shortURL shorturls.ShortUrl
 	err := s.db.WithDbSession(ctx, func(dbSession *db.Session) error {
@@ -39,29 +58,126 @@ func (s sqlStore) Get(ctx context.Context, user *user.SignedInUser, uid string)
 	return &shortURL, nil
 }
 
+// Update sets the last seen time of the short URL and increments its clicks.
 func (s sqlStore) Update(ctx context.Context, shortURL *shorturls.ShortUrl) error {
 	shortURL.LastSeenAt = getTime().Unix()
 	return s.db.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
-		_, err := dbSession.ID(shortURL.Id).Update(shortURL)
+		_, err := dbSession.Exec("UPDATE short_url SET last_seen_at = ?, clicks = clicks + 1 WHERE id = ?", shortURL.LastSeenAt, shortURL.Id)
 		if err != nil {
 			return err
 		}
+		shortURL.Clicks++
 		return nil
 	})
 }
 
+// Insert saves a new short URL. The uid is unique per organization, a short URL with the same uid is reported with
+// ErrShortURLExists.
 func (s sqlStore) Insert(ctx context.Context, shortURL *shorturls.ShortUrl) error {
 	return s.db.WithDbSession(ctx, func(session *db.Session) error {
-		_, err := session.Insert(shortURL)
+		if _, err := session.Insert(shortURL); err != nil {
+			if s.db.GetDialect().IsUniqueConstraintViolation(err) {
+				return shorturls.ErrShortURLExists.Errorf("short URL %s already exists", shortURL.Uid)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+func (s sqlStore) Search(ctx context.Context, query *shorturls.SearchShortURLsQuery) ([]*shorturls.ShortURLWithCreator, int64, error) {
+	dialect := s.db.GetDialect()
+	found := make([]*shorturls.ShortURLWithCreator, 0)
+	var total int64
+
+	orderBy, ok := searchSortOrder[query.Sort]
+	if !ok {
+		return nil, 0, shorturls.ErrShortURLBadRequest.Errorf("invalid sort: %s", query.Sort)
+	}
+
+	err := s.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		var where bytes.Buffer
+		params := []any{query.OrgID}
+		where.WriteString("short_url.org_id = ?")
+		if query.CreatedBy != 0 {
+			where.WriteString(" AND short_url.created_by = ?")
+			params = append(params, query.CreatedBy)
+		}
+		if q := strings.TrimSpace(query.Query); q != "" {
+			where.WriteString(" AND (short_url.uid " + dialect.LikeStr() + " ? ESCAPE '!' OR short_url.path " + dialect.LikeStr() + " ? ESCAPE '!')")
+			pattern := "%" + likeEscaper.Replace(q) + "%"
+			params = append(params, pattern, pattern)
+		}
+
+		userTable := dialect.Quote("user")
+		sql := `SELECT short_url.*, ` + userTable + `.login AS created_by_login
+			FROM short_url
+			LEFT OUTER JOIN ` + userTable + ` ON ` + userTable + `.id = short_url.created_by
+			WHERE ` + where.String() + `
+			ORDER BY ` + orderBy + `, short_url.id ASC ` +
+			dialect.LimitOffset(int64(query.Limit), int64((query.Page-1)*query.Limit))
+		if err := dbSession.SQL(sql, params...).Find(&found); err != nil {
+			return err
+		}
+
+		var err error
+		total, err = dbSession.Table("short_url").Where(where.String(), params...).Count()
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return found, total, nil
+}
+
+func (s sqlStore) DeleteByUID(ctx context.Context, cmd *shorturls.DeleteShortURLByUIDCommand) error {
+	return s.db.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		rawSQL := "DELETE FROM short_url WHERE org_id = ? AND uid = ?"
+		params := []any{cmd.OrgID, cmd.UID}
+		if cmd.CreatedBy != 0 {
+			rawSQL += " AND created_by = ?"
+			params = append(params, cmd.CreatedBy)
+		}
+
+		result, err := session.Exec(append([]any{rawSQL}, params...)...)
+		if err != nil {
+			return err
+		}
+		if affected, err := result.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return shorturls.ErrShortURLNotFound.Errorf("short URL not found")
+		}
+		return nil
+	})
+}
+
+func (s sqlStore) DeleteUnused(ctx context.Context, cmd *shorturls.DeleteShortURLsCommand) error {
+	return s.db.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		rawSQL := `DELETE FROM short_url WHERE org_id = ?
+			AND ((expires_at > 0 AND expires_at <= ?) OR (COALESCE(NULLIF(last_seen_at, 0), created_at) <= ?))`
+		params := []any{cmd.OrgID, getTime().Unix(), cmd.NotSeenSince.Unix()}
+		if cmd.CreatedBy != 0 {
+			rawSQL += " AND created_by = ?"
+			params = append(params, cmd.CreatedBy)
+		}
+
+		result, err := session.Exec(append([]any{rawSQL}, params...)...)
+		if err != nil {
+			return err
+		}
+		cmd.NumDeleted, err = result.RowsAffected()
 		return err
 	})
 }
 
+// Delete deletes the short URLs created before the given time that have never been followed, and the expired
+// short URLs.
 func (s sqlStore) Delete(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error {
 	return s.db.WithTransactionalDbSession(ctx, func(session *db.Session) error {
-		var rawSql = "DELETE FROM short_url WHERE created_at <= ? AND (last_seen_at IS NULL OR last_seen_at = 0)"
+		var rawSql = "DELETE FROM short_url WHERE (created_at <= ? AND (last_seen_at IS NULL OR last_seen_at = 0)) OR (expires_at > 0 AND expires_at <= ?)"
 
-		if result, err := session.Exec(rawSql, cmd.OlderThan.Unix()); err != nil {
+		if result, err := session.Exec(rawSql, cmd.OlderThan.Unix(), getTime().Unix()); err != nil {
 			return err
 		} else if cmd.NumDeleted, err = result.RowsAffected(); err != nil {
 			return err
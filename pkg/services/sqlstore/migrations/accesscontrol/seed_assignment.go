@@ -51,6 +51,10 @@ func (m *seedAssignmentPrimaryKeyMigrator) SQL(dialect migrator.Dialect) string
 	return CodeMigrationSQL
 }
 
+func (m *seedAssignmentPrimaryKeyMigrator) AlteredTables() []string {
+	return []string{"seed_assignment"}
+}
+
 func (m *seedAssignmentPrimaryKeyMigrator) Exec(sess *xorm.Session, mig *migrator.Migrator) error {
 	driver := mig.Dialect.DriverName()
 
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAnnotationWebhookMigrations(mg *Migrator) {
+	annotationWebhookV1 := Table{
+		Name: "annotation_webhook",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "source", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "mapping", Type: DB_Text, Nullable: false},
+			{Name: "encrypted_secret", Type: DB_Blob, Nullable: true},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			// events are posted to the uid of the webhook without org
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create annotation_webhook table v1", NewAddTableMigration(annotationWebhookV1))
+	mg.AddMigration("add unique index annotation_webhook.uid", NewAddIndexMigration(annotationWebhookV1, annotationWebhookV1.Indices[0]))
+	mg.AddMigration("add index annotation_webhook.org_id", NewAddIndexMigration(annotationWebhookV1, annotationWebhookV1.Indices[1]))
+}
@@ -96,4 +96,34 @@ func addPublicDashboardMigration(mg *Migrator) {
 	mg.AddMigration("backfill empty share column fields with default of public", NewRawSQLMigration(
 		"UPDATE dashboard_public SET share='public' WHERE share=''",
 	))
+
+	mg.AddMigration("add variables column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "variables",
+		Type:     DB_Text,
+		Nullable: true,
+	}))
+
+	var dashboardPublicAccessTokenV1 = Table{
+		Name: "dashboard_public_access_token",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "access_token", Type: DB_NVarchar, Length: 32, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "expires_at", Type: DB_DateTime, Nullable: true},
+			{Name: "created_by", Type: DB_Int, Nullable: false},
+			{Name: "created_at", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"access_token"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "public_dashboard_uid"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public access token table v1", NewAddTableMigration(dashboardPublicAccessTokenV1))
+	addTableIndicesMigrations(mg, "v1", dashboardPublicAccessTokenV1)
+
+	mg.AddMigration("copy public dashboard access tokens to the access token table", NewRawSQLMigration(
+		"INSERT INTO dashboard_public_access_token (access_token, org_id, public_dashboard_uid, created_by, created_at) "+
+			"SELECT access_token, org_id, uid, created_by, created_at FROM dashboard_public"))
 }
@@ -0,0 +1,66 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addDashboardReportsMigrations(mg *Migrator) {
+	dashboardReportV1 := Table{
+		Name: "dashboard_report",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "panel_ids", Type: DB_Text, Nullable: true},
+			{Name: "schedule", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "timezone", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "recipients", Type: DB_Text, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: false},
+			{Name: "time_from", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "time_to", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "variables", Type: DB_Text, Nullable: true},
+			{Name: "format", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "created_by", Type: DB_BigInt, Nullable: false},
+			{Name: "next_run", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+			{Name: "updated", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"enabled", "next_run"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_report table v1", NewAddTableMigration(dashboardReportV1))
+	mg.AddMigration("add unique index dashboard_report.org_id-uid", NewAddIndexMigration(dashboardReportV1, dashboardReportV1.Indices[0]))
+	mg.AddMigration("add index dashboard_report.enabled-next_run", NewAddIndexMigration(dashboardReportV1, dashboardReportV1.Indices[1]))
+	mg.AddMigration("add run_as column to dashboard_report", NewAddColumnMigration(dashboardReportV1, &Column{
+		Name: "run_as", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+	mg.AddMigration("set dashboard_report.run_as to the creator", NewRawSQLMigration("UPDATE dashboard_report SET run_as = created_by"))
+
+	dashboardReportHistoryV1 := Table{
+		Name: "dashboard_report_history",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "report_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: false},
+			{Name: "recipients", Type: DB_Text, Nullable: false},
+			{Name: "duration_ms", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "report_uid", "created"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard_report_history table v1", NewAddTableMigration(dashboardReportHistoryV1))
+	mg.AddMigration("add index dashboard_report_history.org_id-report_uid-created", NewAddIndexMigration(dashboardReportHistoryV1, dashboardReportHistoryV1.Indices[0]))
+	mg.AddMigration("add index dashboard_report_history.created", NewAddIndexMigration(dashboardReportHistoryV1, dashboardReportHistoryV1.Indices[1]))
+}
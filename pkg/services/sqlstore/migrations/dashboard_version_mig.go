@@ -57,3 +57,24 @@ FROM dashboard;`
 	mg.AddMigration("alter dashboard_version.data to mediumtext v1", NewRawSQLMigration("").
 		Mysql("ALTER TABLE dashboard_version MODIFY data MEDIUMTEXT;"))
 }
+
+func addDashboardVersionRetentionMigrations(mg *Migrator) {
+	dashboardVersionRetentionV1 := Table{
+		Name: "dashboard_version_retention",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			// empty for the default policy of the organization
+			{Name: "folder_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "versions_to_keep", Type: DB_Int, Nullable: false},
+			{Name: "daily_versions_days", Type: DB_Int, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "folder_uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard_version_retention table v1", NewAddTableMigration(dashboardVersionRetentionV1))
+	mg.AddMigration("add unique index dashboard_version_retention.org_id_folder_uid", NewAddIndexMigration(dashboardVersionRetentionV1, dashboardVersionRetentionV1.Indices[0]))
+}
@@ -53,3 +53,29 @@ func folderv1() migrator.Table {
 		},
 	}
 }
+
+func addFolderSettingsMigrations(mg *migrator.Migrator) {
+	folderSettingsV1 := migrator.Table{
+		Name: "folder_settings",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "folder_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "default_datasource_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "default_refresh", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "timezone", Type: migrator.DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "required_tags", Type: migrator.DB_Text, Nullable: true},
+			{Name: "permission_template", Type: migrator.DB_Text, Nullable: true},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "folder_uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create folder_settings table v1", migrator.NewAddTableMigration(folderSettingsV1))
+	mg.AddMigration("add unique index folder_settings.org_id_folder_uid", migrator.NewAddIndexMigration(folderSettingsV1, folderSettingsV1.Indices[0]))
+	mg.AddMigration("add provisioned_checksum column to folder_settings", migrator.NewAddColumnMigration(folderSettingsV1, &migrator.Column{
+		Name: "provisioned_checksum", Type: migrator.DB_NVarchar, Length: 40, Nullable: true,
+	}))
+}
@@ -24,4 +24,34 @@ func addKVStoreMigrations(mg *Migrator) {
 	mg.AddMigration("create kv_store table v1", NewAddTableMigration(kvStoreV1))
 
 	mg.AddMigration("add index kv_store.org_id-namespace-key", NewAddIndexMigration(kvStoreV1, kvStoreV1.Indices[0]))
+
+	mg.AddMigration("add version column to kv_store", NewAddColumnMigration(kvStoreV1, &Column{
+		Name: "version", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("set version of existing kv_store items", NewRawSQLMigration("UPDATE kv_store SET version = 1"))
+
+	mg.AddMigration("add expires column to kv_store", NewAddColumnMigration(kvStoreV1, &Column{
+		Name: "expires", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	kvStoreChangeV1 := Table{
+		Name: "kv_store_change",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "namespace", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "key", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "version", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_BigInt, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create kv_store_change table v1", NewAddTableMigration(kvStoreChangeV1))
+
+	mg.AddMigration("add index kv_store_change.created", NewAddIndexMigration(kvStoreChangeV1, kvStoreChangeV1.Indices[0]))
 }
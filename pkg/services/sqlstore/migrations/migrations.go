@@ -91,6 +91,15 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	AddExternalAlertmanagerToDatasourceMigration(mg)
 
 	addFolderMigrations(mg)
+
+	addDataSourceHealthMigrations(mg)
+	addDashboardReportsMigrations(mg)
+	addAnnotationWebhookMigrations(mg)
+	addDashboardVersionRetentionMigrations(mg)
+	addPlaylistItemOverridesMigration(mg)
+	addFolderSettingsMigrations(mg)
+	addShortURLUsageMigrations(mg)
+
 	if mg.Cfg != nil && mg.Cfg.IsFeatureToggleEnabled != nil {
 		if mg.Cfg.IsFeatureToggleEnabled(featuremgmt.FlagExternalServiceAuth) {
 			oauthserver.AddMigration(mg)
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -60,6 +61,54 @@ func TestMigrations(t *testing.T) {
 	checkStepsAndDatabaseMatch(t, mg, expectedMigrations)
 }
 
+func TestMigrationPlanAndSchemaVerification(t *testing.T) {
+	x, err := xorm.NewEngine(SQLite, filepath.Join(t.TempDir(), "grafana.db"))
+	require.NoError(t, err)
+
+	mg := NewMigrator(x, &setting.Cfg{Raw: ini.Empty()})
+	migrations := &OSSMigrations{}
+	migrations.AddMigration(mg)
+
+	plan, err := mg.Plan()
+	require.NoError(t, err)
+	require.Len(t, plan, mg.MigrationsCount())
+	assert.Equal(t, "create migration_log table", plan[0].ID)
+	assert.Contains(t, plan[0].SQL, "CREATE TABLE IF NOT EXISTS `migration_log`")
+
+	// planning doesn't execute anything
+	exists, err := x.IsTableExist("migration_log")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.NoError(t, mg.Start(false, 0))
+
+	plan, err = mg.Plan()
+	require.NoError(t, err)
+	for _, m := range plan {
+		// only the migrations that aren't recorded in the log are left
+		assert.True(t, m.Code, m.ID)
+	}
+
+	drift, err := mg.VerifySchema()
+	require.NoError(t, err)
+	require.Empty(t, drift)
+
+	_, err = x.Exec("DROP INDEX `UQE_star_user_id_dashboard_id`")
+	require.NoError(t, err)
+	_, err = x.Exec("ALTER TABLE `star` ADD COLUMN `extra` TEXT NULL")
+	require.NoError(t, err)
+	_, err = x.Exec("CREATE TABLE `not_migrated` (`id` INTEGER PRIMARY KEY)")
+	require.NoError(t, err)
+
+	drift, err = mg.VerifySchema()
+	require.NoError(t, err)
+	assert.Equal(t, []SchemaDrift{
+		{Kind: UnexpectedTable, Table: "not_migrated"},
+		{Kind: MissingIndex, Table: "star", Name: "UQE_star_user_id_dashboard_id"},
+		{Kind: UnexpectedColumn, Table: "star", Name: "extra"},
+	}, drift)
+}
+
 func TestMigrationLock(t *testing.T) {
 	dbType := getDBType()
 	if dbType == SQLite {
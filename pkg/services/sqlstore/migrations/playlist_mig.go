@@ -63,3 +63,24 @@ func playlistV2() Table {
 		},
 	}
 }
+
+func addPlaylistItemOverridesMigration(mg *Migrator) {
+	playlistItemV2 := Table{Name: "playlist_item"}
+
+	// Per item interval, time range and template variables overriding the settings of the playlist and dashboards
+	mg.AddMigration("Add interval column to playlist_item", NewAddColumnMigration(playlistItemV2, &Column{
+		Name: "interval", Type: DB_NVarchar, Length: 255, Nullable: false, Default: "''",
+	}))
+
+	mg.AddMigration("Add time_from column to playlist_item", NewAddColumnMigration(playlistItemV2, &Column{
+		Name: "time_from", Type: DB_NVarchar, Length: 255, Nullable: false, Default: "''",
+	}))
+
+	mg.AddMigration("Add time_to column to playlist_item", NewAddColumnMigration(playlistItemV2, &Column{
+		Name: "time_to", Type: DB_NVarchar, Length: 255, Nullable: false, Default: "''",
+	}))
+
+	mg.AddMigration("Add variables column to playlist_item", NewAddColumnMigration(playlistItemV2, &Column{
+		Name: "variables", Type: DB_Text, Nullable: true,
+	}))
+}
@@ -29,3 +29,19 @@ func addShortURLMigrations(mg *Migrator) {
 		Mysql("ALTER TABLE short_url MODIFY created_by BIGINT;").
 		Postgres("ALTER TABLE short_url ALTER COLUMN created_by TYPE BIGINT;"))
 }
+
+func addShortURLUsageMigrations(mg *Migrator) {
+	shortURLV1 := Table{Name: "short_url"}
+
+	mg.AddMigration("add column expires_at to short_url table", NewAddColumnMigration(shortURLV1, &Column{
+		Name: "expires_at", Type: DB_BigInt, Nullable: true,
+	}))
+
+	mg.AddMigration("add column clicks to short_url table", NewAddColumnMigration(shortURLV1, &Column{
+		Name: "clicks", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add index short_url.org_id-created_by", NewAddIndexMigration(shortURLV1, &Index{
+		Cols: []string{"org_id", "created_by"},
+	}))
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
+	"xorm.io/core"
 	"xorm.io/xorm"
 )
 
@@ -73,6 +75,12 @@ type Dialect interface {
 	Unlock(LockCfg) error
 
 	GetDBName(string) (string, error)
+
+	// ConvertValue converts a value read from another database so that it can be written to the given column.
+	ConvertValue(col *core.Column, value any) any
+	// ResetSequenceSQL returns the statement that moves the sequence of an auto increment column past the greatest
+	// value of the column, or an empty string if the database keeps the sequence up to date on its own.
+	ResetSequenceSQL(tableName string, columnName string) string
 }
 
 type LockCfg struct {
@@ -344,3 +352,39 @@ func (b *BaseDialect) OrderBy(order string) string {
 func (b *BaseDialect) GetDBName(_ string) (string, error) {
 	return "", nil
 }
+
+func (b *BaseDialect) ConvertValue(col *core.Column, value any) any {
+	switch v := value.(type) {
+	case []byte:
+		// byte slices are written as binary strings by some drivers, so text columns get a string
+		if col.SQLType.IsText() {
+			return string(v)
+		}
+	case string:
+		if col.SQLType.IsBlob() {
+			return []byte(v)
+		}
+		if col.SQLType.Name == core.Bool || col.SQLType.Name == core.Boolean {
+			return v == "1" || strings.EqualFold(v, "true")
+		}
+	case int64:
+		// booleans are stored as integers by SQLite and MySQL
+		if col.SQLType.Name == core.Bool || col.SQLType.Name == core.Boolean {
+			return v != 0
+		}
+	case bool:
+		if col.SQLType.IsNumeric() && col.SQLType.Name != core.Bool && col.SQLType.Name != core.Boolean {
+			if v {
+				return 1
+			}
+			return 0
+		}
+	case time.Time:
+		return v.UTC()
+	}
+	return value
+}
+
+func (b *BaseDialect) ResetSequenceSQL(tableName string, columnName string) string {
+	return ""
+}
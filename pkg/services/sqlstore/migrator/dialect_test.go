@@ -0,0 +1,53 @@
+package migrator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"xorm.io/core"
+)
+
+func TestConvertValue(t *testing.T) {
+	textCol := &core.Column{SQLType: core.SQLType{Name: core.Text}}
+	blobCol := &core.Column{SQLType: core.SQLType{Name: core.Blob}}
+	boolCol := &core.Column{SQLType: core.SQLType{Name: core.Bool}}
+	intCol := &core.Column{SQLType: core.SQLType{Name: core.Int}}
+	loc := time.FixedZone("UTC+2", 2*60*60)
+
+	tests := []struct {
+		name     string
+		col      *core.Column
+		value    any
+		expected any
+	}{
+		{"bytes to text", textCol, []byte("hello"), "hello"},
+		{"bytes to blob", blobCol, []byte("hello"), []byte("hello")},
+		{"string to blob", blobCol, "hello", []byte("hello")},
+		{"string to text", textCol, "hello", "hello"},
+		{"integer to bool", boolCol, int64(1), true},
+		{"zero to bool", boolCol, int64(0), false},
+		{"string to bool", boolCol, "true", true},
+		{"bool to integer", intCol, true, 1},
+		{"bool to bool", boolCol, false, false},
+		{"integer to integer", intCol, int64(42), int64(42)},
+		{"time in UTC", textCol, time.Date(2023, 1, 2, 12, 0, 0, 0, loc), time.Date(2023, 1, 2, 10, 0, 0, 0, time.UTC)},
+		{"null", textCol, nil, nil},
+	}
+
+	for _, d := range []Dialect{NewSQLite3Dialect(), NewMysqlDialect(), NewPostgresDialect()} {
+		for _, tc := range tests {
+			t.Run(d.DriverName()+"/"+tc.name, func(t *testing.T) {
+				assert.Equal(t, tc.expected, d.ConvertValue(tc.col, tc.value))
+			})
+		}
+	}
+}
+
+func TestResetSequenceSQL(t *testing.T) {
+	assert.Equal(t, "", NewSQLite3Dialect().ResetSequenceSQL("user", "id"))
+	assert.Equal(t, "", NewMysqlDialect().ResetSequenceSQL("user", "id"))
+	assert.Equal(t,
+		`SELECT setval(pg_get_serial_sequence('"user"', 'id'), COALESCE((SELECT MAX("id") FROM "user"), 0) + 1, false);`,
+		NewPostgresDialect().ResetSequenceSQL("user", "id"))
+}
@@ -0,0 +1,40 @@
+package migrator
+
+// PlannedMigration is a migration that hasn't been executed yet, with the SQL it runs on the database of the migrator.
+type PlannedMigration struct {
+	ID  string `json:"id"`
+	SQL string `json:"sql"`
+	// Condition is the query deciding whether the migration runs, if any.
+	Condition     string `json:"condition,omitempty"`
+	ConditionArgs []any  `json:"conditionArgs,omitempty"`
+	// Code is set for migrations that run code. Their statements depend on the data, and SQL only describes them.
+	Code bool `json:"code"`
+}
+
+// Plan returns the migrations Start would execute, in order, without executing anything.
+func (mg *Migrator) Plan() ([]PlannedMigration, error) {
+	logMap, err := mg.GetMigrationLog()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := make([]PlannedMigration, 0)
+	for _, m := range mg.migrations {
+		if _, exists := logMap[m.Id()]; exists {
+			continue
+		}
+
+		planned := PlannedMigration{
+			ID:  m.Id(),
+			SQL: m.SQL(mg.Dialect),
+		}
+		if _, ok := m.(CodeMigration); ok {
+			planned.Code = true
+		}
+		if condition := m.GetCondition(); condition != nil {
+			planned.Condition, planned.ConditionArgs = condition.SQL(mg.Dialect)
+		}
+		plan = append(plan, planned)
+	}
+	return plan, nil
+}
@@ -213,6 +213,12 @@ func (db *PostgresDialect) PostInsertId(table string, sess *xorm.Session) error
 	return nil
 }
 
+// ResetSequenceSQL returns the statement that sets the next value of the sequence of the column after its greatest value.
+func (db *PostgresDialect) ResetSequenceSQL(tableName string, columnName string) string {
+	return fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE((SELECT MAX(%s) FROM %s), 0) + 1, false);",
+		db.Quote(tableName), columnName, db.Quote(columnName), db.Quote(tableName))
+}
+
 // UpsertSQL returns the upsert sql statement for PostgreSQL dialect
 func (db *PostgresDialect) UpsertSQL(tableName string, keyCols, updateCols []string) string {
 	str, _ := db.UpsertMultipleSQL(tableName, keyCols, updateCols, 1)
@@ -0,0 +1,192 @@
+package migrator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TableAlteringMigration is implemented by code migrations that change the schema of tables, which VerifySchema then
+// doesn't verify.
+type TableAlteringMigration interface {
+	AlteredTables() []string
+}
+
+var rawSQLTablePattern = regexp.MustCompile("(?i)(?:(?:ALTER|CREATE|DROP)\\s+TABLE(?:\\s+IF(?:\\s+NOT)?\\s+EXISTS)?|RENAME\\s+TO)\\s+[`\"]?(\\w+)")
+
+type SchemaDriftKind string
+
+const (
+	MissingTable     SchemaDriftKind = "missing-table"
+	UnexpectedTable  SchemaDriftKind = "unexpected-table"
+	MissingColumn    SchemaDriftKind = "missing-column"
+	UnexpectedColumn SchemaDriftKind = "unexpected-column"
+	MissingIndex     SchemaDriftKind = "missing-index"
+	UnexpectedIndex  SchemaDriftKind = "unexpected-index"
+)
+
+// SchemaDrift is a difference between the schema of the database and the schema the executed migrations create.
+type SchemaDrift struct {
+	Kind  SchemaDriftKind `json:"kind"`
+	Table string          `json:"table"`
+	// Name is the name of the column or index, if the drift is about one.
+	Name string `json:"name,omitempty"`
+}
+
+func (d SchemaDrift) String() string {
+	if d.Name == "" {
+		return fmt.Sprintf("%s: %s", d.Kind, d.Table)
+	}
+	return fmt.Sprintf("%s: %s.%s", d.Kind, d.Table, d.Name)
+}
+
+// expectedTable is a table as created by the migrations, with the lower cased names of its columns and indexes.
+type expectedTable struct {
+	name    string
+	columns map[string]string
+	indexes map[string]string
+}
+
+// VerifySchema compares the tables, columns and indexes of the database with the ones the migrations recorded in
+// the migration log create, and returns the differences. The tables changed by raw SQL migrations, or by code
+// migrations implementing TableAlteringMigration, aren't verified.
+func (mg *Migrator) VerifySchema() ([]SchemaDrift, error) {
+	logMap, err := mg.GetMigrationLog()
+	if err != nil {
+		return nil, err
+	}
+
+	expected, altered := mg.expectedSchema(logMap)
+
+	tables, err := mg.DBEngine.DBMetas()
+	if err != nil {
+		return nil, err
+	}
+
+	drift := make([]SchemaDrift, 0)
+	found := make(map[string]bool, len(tables))
+	for _, table := range tables {
+		if table.Name == "" {
+			continue
+		}
+		key := strings.ToLower(table.Name)
+		found[key] = true
+		if altered[key] {
+			continue
+		}
+
+		t, ok := expected[key]
+		if !ok {
+			drift = append(drift, SchemaDrift{Kind: UnexpectedTable, Table: table.Name})
+			continue
+		}
+
+		columns := make(map[string]bool)
+		for _, col := range table.Columns() {
+			columns[strings.ToLower(col.Name)] = true
+			if _, ok := t.columns[strings.ToLower(col.Name)]; !ok {
+				drift = append(drift, SchemaDrift{Kind: UnexpectedColumn, Table: t.name, Name: col.Name})
+			}
+		}
+		for key, name := range t.columns {
+			if !columns[key] {
+				drift = append(drift, SchemaDrift{Kind: MissingColumn, Table: t.name, Name: name})
+			}
+		}
+
+		indexes := make(map[string]bool)
+		for _, index := range table.Indexes {
+			name := index.Name
+			if index.IsRegular {
+				name = index.XName(table.Name)
+			}
+			indexes[strings.ToLower(name)] = true
+			if _, ok := t.indexes[strings.ToLower(name)]; !ok {
+				drift = append(drift, SchemaDrift{Kind: UnexpectedIndex, Table: t.name, Name: name})
+			}
+		}
+		for key, name := range t.indexes {
+			if !indexes[key] {
+				drift = append(drift, SchemaDrift{Kind: MissingIndex, Table: t.name, Name: name})
+			}
+		}
+	}
+
+	for key, t := range expected {
+		if !found[key] && !altered[key] {
+			drift = append(drift, SchemaDrift{Kind: MissingTable, Table: t.name})
+		}
+	}
+
+	sort.Slice(drift, func(i, j int) bool {
+		if drift[i].Table != drift[j].Table {
+			return drift[i].Table < drift[j].Table
+		}
+		if drift[i].Kind != drift[j].Kind {
+			return drift[i].Kind < drift[j].Kind
+		}
+		return drift[i].Name < drift[j].Name
+	})
+	return drift, nil
+}
+
+// expectedSchema replays the schema changes of the executed migrations, keyed by lower cased table name. It also
+// returns the lower cased names of the tables changed in ways it can't replay.
+func (mg *Migrator) expectedSchema(logMap map[string]MigrationLog) (map[string]*expectedTable, map[string]bool) {
+	tables := make(map[string]*expectedTable)
+	altered := make(map[string]bool)
+	get := func(name string) *expectedTable {
+		return tables[strings.ToLower(name)]
+	}
+
+	for _, m := range mg.migrations {
+		if _, ok := logMap[m.Id()]; !ok {
+			continue
+		}
+
+		switch m := m.(type) {
+		case *AddTableMigration:
+			t := &expectedTable{name: m.table.Name, columns: make(map[string]string), indexes: make(map[string]string)}
+			for _, col := range m.table.Columns {
+				t.columns[strings.ToLower(col.Name)] = col.Name
+			}
+			tables[strings.ToLower(m.table.Name)] = t
+		case *DropTableMigration:
+			delete(tables, strings.ToLower(m.tableName))
+		case *RenameTableMigration:
+			if t := get(m.oldName); t != nil {
+				delete(tables, strings.ToLower(m.oldName))
+				t.name = m.newName
+				tables[strings.ToLower(m.newName)] = t
+			}
+		case *AddColumnMigration:
+			if t := get(m.tableName); t != nil {
+				t.columns[strings.ToLower(m.column.Name)] = m.column.Name
+			}
+		case *RenameColumnMigration:
+			if t := get(m.table.Name); t != nil {
+				delete(t.columns, strings.ToLower(m.column.Name))
+				t.columns[strings.ToLower(m.newName)] = m.newName
+			}
+		case *AddIndexMigration:
+			if t := get(m.tableName); t != nil {
+				name := m.index.XName(m.tableName)
+				t.indexes[strings.ToLower(name)] = name
+			}
+		case *DropIndexMigration:
+			if t := get(m.tableName); t != nil {
+				delete(t.indexes, strings.ToLower(m.index.XName(m.tableName)))
+			}
+		case *RawSQLMigration:
+			for _, match := range rawSQLTablePattern.FindAllStringSubmatch(m.SQL(mg.Dialect), -1) {
+				altered[strings.ToLower(match[1])] = true
+			}
+		case TableAlteringMigration:
+			for _, name := range m.AlteredTables() {
+				altered[strings.ToLower(name)] = true
+			}
+		}
+	}
+	return tables, altered
+}
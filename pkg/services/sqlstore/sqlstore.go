@@ -95,6 +95,28 @@ func ProvideServiceForTests(cfg *setting.Cfg, migrations registry.DatabaseMigrat
 	return initTestDB(cfg, migrations, InitTestDBOpt{EnsureDefaultOrgAndUser: true})
 }
 
+// ProvideServiceWithoutMigrations connects to the database configured in cfg, without running the migrations or
+// creating the default org and admin user. It's used by tools inspecting the database.
+func ProvideServiceWithoutMigrations(cfg *setting.Cfg, migrations registry.DatabaseMigrator, tracer tracing.Tracer) (*SQLStore, error) {
+	xorm.DefaultPostgresSchema = ""
+	return newSQLStore(cfg, localcache.New(5*time.Minute, 10*time.Minute), nil, migrations, bus.ProvideBus(tracer), tracer,
+		InitTestDBOpt{EnsureDefaultOrgAndUser: false})
+}
+
+// ProvideServiceWithoutDefaults connects to the database configured in cfg and runs the migrations, without creating
+// the default org and admin user. It's used to prepare an empty database that data is copied to.
+func ProvideServiceWithoutDefaults(cfg *setting.Cfg, migrations registry.DatabaseMigrator, tracer tracing.Tracer) (*SQLStore, error) {
+	s, err := ProvideServiceWithoutMigrations(cfg, migrations, tracer)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Migrate(cfg.IsFeatureToggleEnabled(featuremgmt.FlagMigrationLocking)); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
 func newSQLStore(cfg *setting.Cfg, cacheService *localcache.CacheService, engine *xorm.Engine,
 	migrations registry.DatabaseMigrator, bus bus.Bus, tracer tracing.Tracer, opts ...InitTestDBOpt) (*SQLStore, error) {
 	ss := &SQLStore{
@@ -103,6 +103,11 @@ type StorageGitConfig struct {
 
 	// SECURE JSON :grimicing:
 	AccessToken string `json:"accessToken,omitempty"` // Simplest auth method for github
+
+	// Import the dashboards of the repository, and track the pull requests of the changes saved from the UI
+	SyncDashboards bool   `json:"syncDashboards,omitempty"`
+	OrgID          int64  `json:"orgId,omitempty"`         // organization of the imported dashboards, 1 by default
+	WebhookSecret  string `json:"webhookSecret,omitempty"` // secret of the push webhook, or $ENV_VAR
 }
 
 type StorageSQLConfig struct {
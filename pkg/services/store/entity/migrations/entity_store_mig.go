@@ -180,6 +180,40 @@ func initEntityTables(mg *migrator.Migrator) {
 		},
 	})
 
+	// Every write and delete, in commit order, so watchers can resume from a resource version
+	tables = append(tables, migrator.Table{
+		Name: "entity_change_log",
+		Columns: []*migrator.Column{
+			{Name: "resource_version", Type: migrator.DB_BigInt, Nullable: false, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "grn", Type: migrator.DB_NVarchar, Length: grnLength, Nullable: false},
+
+			// The entity identifier
+			{Name: "tenant_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "kind", Type: migrator.DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "folder", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+
+			// The change (the body is in `entity_history`)
+			{Name: "action", Type: migrator.DB_Int, Nullable: false}, // EntityWatchResponse_Action
+			{Name: "version", Type: migrator.DB_NVarchar, Length: 128, Nullable: false},
+			{Name: "size", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "etag", Type: migrator.DB_NVarchar, Length: 32, Nullable: false, IsLatin: true}, // md5(body)
+			{Name: "updated_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "updated_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+
+			// Summary data at the time of the change
+			{Name: "name", Type: migrator.DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: true}, // JSON object
+			{Name: "fields", Type: migrator.DB_Text, Nullable: true}, // JSON object
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"tenant_id", "resource_version"}},
+			{Cols: []string{"tenant_id", "updated_at"}},
+			{Cols: []string{"kind"}},
+			{Cols: []string{"folder"}},
+		},
+	})
+
 	// Initialize all tables
 	for t := range tables {
 		mg.AddMigration("drop table "+tables[t].Name, migrator.NewDropTableMigration(tables[t].Name))
@@ -189,6 +223,16 @@ func initEntityTables(mg *migrator.Migrator) {
 		}
 	}
 
+	// The resource version of the last change in `entity_change_log`
+	mg.AddMigration("add resource_version column to entity", migrator.NewAddColumnMigration(migrator.Table{Name: "entity"}, &migrator.Column{
+		Name: "resource_version", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	// When the change was written, to delete the changes older than the retention
+	mg.AddMigration("add created_at column to entity_change_log", migrator.NewAddColumnMigration(migrator.Table{Name: "entity_change_log"}, &migrator.Column{
+		Name: "created_at", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+
 	mg.AddMigration("set path collation on entity table", migrator.NewRawSQLMigration("").
 		// MySQL `utf8mb4_unicode_ci` collation is set in `mysql_dialect.go`
 		// SQLite uses a `BINARY` collation by default
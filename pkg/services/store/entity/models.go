@@ -6,6 +6,7 @@ package entity
 
 import (
 	"context"
+	"errors"
 )
 
 const (
@@ -64,6 +65,13 @@ const (
 	ExternalEntityReferenceRuntime_Transformer = "transformer"
 )
 
+// ErrOptimisticLockFailed is returned when the previous version of a write or delete is not the current one
+var ErrOptimisticLockFailed = errors.New("optimistic lock failed")
+
+// ErrResourceVersionExpired is returned when a watch starts at a resource version older than the changes kept in
+// the change log. The entities must be read again to get a current resource version.
+var ErrResourceVersionExpired = errors.New("resource version expired")
+
 // EntityKindInfo describes information needed from the object store
 // All non-raw types will have a schema that can be used to validate
 type EntityKindInfo struct {
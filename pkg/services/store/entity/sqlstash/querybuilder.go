@@ -7,6 +7,7 @@ type selectQuery struct {
 	from     string   // FROM object
 	limit    int64
 	oneExtra bool
+	orderBy  string // ORDER BY xyz
 
 	where []string
 	args  []any
@@ -62,6 +63,11 @@ func (q *selectQuery) toQuery() (string, []any) {
 		}
 	}
 
+	if q.orderBy != "" {
+		sb.WriteString(" ORDER BY ")
+		sb.WriteString(q.orderBy)
+	}
+
 	if q.limit > 0 || q.oneExtra {
 		limit := q.limit
 		if limit < 1 {
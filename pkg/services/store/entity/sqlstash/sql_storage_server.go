@@ -29,10 +29,12 @@ var _ entity.EntityStoreAdminServer = &sqlEntityServer{}
 
 func ProvideSQLEntityServer(db db.DB, cfg *setting.Cfg, grpcServerProvider grpcserver.Provider, kinds kind.KindRegistry, resolver resolver.EntityReferenceResolver) entity.EntityStoreServer {
 	entityServer := &sqlEntityServer{
-		sess:     db.GetSqlxSession(),
-		log:      log.New("sql-entity-server"),
-		kinds:    kinds,
-		resolver: resolver,
+		sess:              db.GetSqlxSession(),
+		log:               log.New("sql-entity-server"),
+		kinds:             kinds,
+		resolver:          resolver,
+		watchPollInterval: defaultWatchPollInterval,
+		watchBookmarks:    defaultWatchBookmarkInterval,
 	}
 	entity.RegisterEntityStoreServer(grpcServerProvider.GetServer(), entityServer)
 	return entityServer
@@ -43,6 +45,10 @@ type sqlEntityServer struct {
 	sess     *session.SessionDB
 	kinds    kind.KindRegistry
 	resolver resolver.EntityReferenceResolver
+
+	// How often watchers read the change log, and send bookmarks
+	watchPollInterval time.Duration
+	watchBookmarks    time.Duration
 }
 
 func getReadSelect(r *entity.ReadEntityRequest) string {
@@ -51,7 +57,8 @@ func getReadSelect(r *entity.ReadEntityRequest) string {
 		"version", "size", "etag", "errors", // errors are always returned
 		"created_at", "created_by",
 		"updated_at", "updated_by",
-		"origin", "origin_key", "origin_ts"}
+		"origin", "origin_key", "origin_ts",
+		"resource_version"}
 
 	if r.WithBody {
 		fields = append(fields, `body`)
@@ -75,6 +82,7 @@ func (s *sqlEntityServer) rowToReadEntityResponse(ctx context.Context, rows *sql
 		&raw.CreatedAt, &raw.CreatedBy,
 		&raw.UpdatedAt, &raw.UpdatedBy,
 		&raw.Origin.Source, &raw.Origin.Key, &raw.Origin.Time,
+		&raw.ResourceVersion,
 	}
 	if r.WithBody {
 		args = append(args, &raw.Body)
@@ -317,6 +325,7 @@ func (s *sqlEntityServer) AdminWrite(ctx context.Context, r *entity.AdminWriteEn
 	err = s.sess.WithTransaction(ctx, func(tx *session.SessionTx) error {
 		var versionInfo *entity.EntityVersionInfo
 		isUpdate := false
+		existed := false
 		if r.ClearHistory {
 			// Optionally keep the original creation time information
 			if createdAt < 1000 || createdBy == "" {
@@ -325,7 +334,7 @@ func (s *sqlEntityServer) AdminWrite(ctx context.Context, r *entity.AdminWriteEn
 					return err
 				}
 			}
-			_, err = doDelete(ctx, tx, grn)
+			existed, err = doDelete(ctx, tx, grn)
 			if err != nil {
 				return err
 			}
@@ -347,7 +356,7 @@ func (s *sqlEntityServer) AdminWrite(ctx context.Context, r *entity.AdminWriteEn
 		// Optimistic locking
 		if r.PreviousVersion != "" {
 			if r.PreviousVersion != versionInfo.Version {
-				return fmt.Errorf("optimistic lock failed")
+				return entity.ErrOptimisticLockFailed
 			}
 		}
 
@@ -453,7 +462,14 @@ func (s *sqlEntityServer) AdminWrite(ctx context.Context, r *entity.AdminWriteEn
 		if err == nil {
 			summary.folder = r.Folder
 			summary.parent_grn = grn
-			return s.writeSearchInfo(ctx, tx, oid, summary)
+			err = s.writeSearchInfo(ctx, tx, oid, summary)
+		}
+		if err == nil {
+			action := entity.EntityWatchResponse_CREATED
+			if isUpdate || existed {
+				action = entity.EntityWatchResponse_UPDATED
+			}
+			err = writeChangeLog(ctx, tx, oid, action, updatedAt, versionInfo.UpdatedBy)
 		}
 		return err
 	})
@@ -631,14 +647,98 @@ func (s *sqlEntityServer) Delete(ctx context.Context, r *entity.DeleteEntityRequ
 		return nil, err
 	}
 
+	modifier, err := appcontext.User(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	rsp := &entity.DeleteEntityResponse{}
 	err = s.sess.WithTransaction(ctx, func(tx *session.SessionTx) error {
+		if r.PreviousVersion != "" {
+			current, err := s.selectForUpdate(ctx, tx, grn2.ToGRNString())
+			if err != nil {
+				return err
+			}
+			if current.Version != r.PreviousVersion {
+				return entity.ErrOptimisticLockFailed
+			}
+		}
+
+		// Nothing is logged when the entity does not exist
+		err = writeChangeLog(ctx, tx, grn2.ToGRNString(), entity.EntityWatchResponse_DELETED, time.Now().UnixMilli(), store.GetUserIDString(modifier))
+		if err != nil {
+			return err
+		}
 		rsp.OK, err = doDelete(ctx, tx, grn2)
 		return err
 	})
 	return rsp, err
 }
 
+// writeChangeLog adds the current state of the entity to the log read by the watchers
+func writeChangeLog(ctx context.Context, tx *session.SessionTx, grn string, action entity.EntityWatchResponse_Action, updatedAt int64, updatedBy string) error {
+	rows, err := tx.Query(ctx, "SELECT tenant_id,kind,uid,folder,version,size,etag,name,labels,fields FROM entity WHERE grn=?", grn)
+	if err != nil {
+		return err
+	}
+	var (
+		tenantID, size                         int64
+		kind, uid, folder, version, etag, name string
+		labels, fields                         *string
+	)
+	found := rows.Next()
+	if found {
+		err = rows.Scan(&tenantID, &kind, &uid, &folder, &version, &size, &etag, &name, &labels, &fields)
+	}
+	errClose := rows.Close()
+	if err != nil {
+		return err
+	}
+	if errClose != nil || !found {
+		return errClose
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO entity_change_log ("+
+		"grn, tenant_id, kind, uid, folder, "+
+		"action, version, size, etag, updated_at, updated_by, "+
+		"name, labels, fields, created_at) "+
+		"VALUES (?, ?, ?, ?, ?, "+
+		" ?, ?, ?, ?, ?, ?, "+
+		" ?, ?, ?, ?)",
+		grn, tenantID, kind, uid, folder,
+		int32(action), version, size, etag, updatedAt, updatedBy,
+		name, labels, fields, time.Now().UnixMilli(),
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE entity SET resource_version=("+
+		"SELECT MAX(resource_version) FROM entity_change_log WHERE grn=?"+
+		") WHERE grn=?", grn, grn)
+	return err
+}
+
+// currentResourceVersion returns the resource version of the last change
+func (s *sqlEntityServer) currentResourceVersion(ctx context.Context) (int64, error) {
+	v, err := s.queryVersion(ctx, "SELECT MAX(resource_version) FROM entity_change_log")
+	return v.Int64, err
+}
+
+func (s *sqlEntityServer) queryVersion(ctx context.Context, query string, args ...any) (sql.NullInt64, error) {
+	v := sql.NullInt64{}
+	rows, err := s.sess.Query(ctx, query, args...)
+	if err != nil {
+		return v, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	if rows.Next() {
+		err = rows.Scan(&v)
+	}
+	return v, err
+}
+
 func doDelete(ctx context.Context, tx *session.SessionTx, grn2 *grn.GRN) (bool, error) {
 	str := grn2.ToGRNString()
 	results, err := tx.Exec(ctx, "DELETE FROM entity WHERE grn=?", str)
@@ -722,15 +822,16 @@ func (s *sqlEntityServer) Search(ctx context.Context, r *entity.EntitySearchRequ
 		return nil, fmt.Errorf("missing user in context")
 	}
 
-	if r.NextPageToken != "" || len(r.Sort) > 0 {
+	if len(r.Sort) > 0 {
 		return nil, fmt.Errorf("not yet supported")
 	}
 
 	fields := []string{
 		"grn", "tenant_id", "kind", "uid",
 		"version", "folder", "slug", "errors", // errors are always returned
-		"size", "updated_at", "updated_by",
+		"size", "created_at", "updated_at", "updated_by",
 		"name", "description", // basic summary
+		"resource_version",
 	}
 
 	if r.WithBody {
@@ -749,9 +850,14 @@ func (s *sqlEntityServer) Search(ctx context.Context, r *entity.EntitySearchRequ
 		from:     "entity", // the table
 		args:     []any{},
 		limit:    r.Limit,
-		oneExtra: true, // request one more than the limit (and show next token if it exists)
+		oneExtra: true,  // request one more than the limit (and show next token if it exists)
+		orderBy:  "grn", // the next page token is the grn of the first entity of the next page
 	}
 	entityQuery.addWhere("tenant_id", user.OrgID)
+	if r.NextPageToken != "" {
+		entityQuery.args = append(entityQuery.args, r.NextPageToken)
+		entityQuery.where = append(entityQuery.where, "grn>=?")
+	}
 
 	if len(r.Kind) > 0 {
 		entityQuery.addWhereIn("kind", r.Kind)
@@ -779,13 +885,21 @@ func (s *sqlEntityServer) Search(ctx context.Context, r *entity.EntitySearchRequ
 
 	query, args := entityQuery.toQuery()
 
+	// Changes after this version may be missing from the results
+	rv, err := s.currentResourceVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	rows, err := s.sess.Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer func() { _ = rows.Close() }()
 	oid := ""
-	rsp := &entity.EntitySearchResponse{}
+	rsp := &entity.EntitySearchResponse{
+		ResourceVersion: rv,
+	}
 	for rows.Next() {
 		result := &entity.EntitySearchResult{
 			GRN: &grn.GRN{},
@@ -795,8 +909,9 @@ func (s *sqlEntityServer) Search(ctx context.Context, r *entity.EntitySearchRequ
 		args := []any{
 			&oid, &result.GRN.TenantID, &result.GRN.ResourceKind, &result.GRN.ResourceIdentifier,
 			&result.Version, &result.Folder, &result.Slug, &summaryjson.errors,
-			&result.Size, &result.UpdatedAt, &result.UpdatedBy,
+			&result.Size, &result.CreatedAt, &result.UpdatedAt, &result.UpdatedBy,
 			&result.Name, &summaryjson.description,
+			&result.ResourceVersion,
 		}
 		if r.WithBody {
 			args = append(args, &result.Body)
@@ -815,7 +930,6 @@ func (s *sqlEntityServer) Search(ctx context.Context, r *entity.EntitySearchRequ
 
 		// found one more than requested
 		if int64(len(rsp.Results)) >= entityQuery.limit {
-			// TODO? should this encode start+offset?
 			rsp.NextPageToken = oid
 			break
 		}
@@ -845,7 +959,3 @@ func (s *sqlEntityServer) Search(ctx context.Context, r *entity.EntitySearchRequ
 
 	return rsp, err
 }
-
-func (s *sqlEntityServer) Watch(*entity.EntityWatchRequest, entity.EntityStore_WatchServer) error {
-	return fmt.Errorf("unimplemented")
-}
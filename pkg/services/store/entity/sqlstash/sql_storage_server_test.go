@@ -0,0 +1,67 @@
+package sqlstash
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/appcontext"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/grn"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/store/entity"
+	"github.com/grafana/grafana/pkg/services/store/entity/migrations"
+	"github.com/grafana/grafana/pkg/services/store/kind"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestIntegrationSQLEntitySearchPages(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	err := migrations.MigrateEntityStore(sqlStore, featuremgmt.WithFeatures(featuremgmt.FlagEntityStore))
+	require.NoError(t, err)
+
+	s := &sqlEntityServer{
+		sess:  sqlStore.GetSqlxSession(),
+		log:   log.New("sql-entity-server-test"),
+		kinds: kind.NewKindRegistry(),
+	}
+	ctx := appcontext.WithUser(context.Background(), &user.SignedInUser{UserID: 1, OrgID: 1, Login: "admin"})
+
+	for _, uid := range []string{"c", "a", "e", "b", "d"} {
+		_, err := s.Write(ctx, &entity.WriteEntityRequest{
+			GRN:  &grn.GRN{ResourceKind: entity.StandardKindDashboard, ResourceIdentifier: uid},
+			Body: []byte(`{"title": "` + uid + `"}`),
+		})
+		require.NoError(t, err)
+	}
+
+	var pages [][]string
+	token := ""
+	for {
+		rsp, err := s.Search(ctx, &entity.EntitySearchRequest{
+			Kind:          []string{entity.StandardKindDashboard},
+			Limit:         2,
+			NextPageToken: token,
+		})
+		require.NoError(t, err)
+
+		page := []string{}
+		for _, r := range rsp.Results {
+			page = append(page, r.GRN.ResourceIdentifier)
+		}
+		pages = append(pages, page)
+
+		token = rsp.NextPageToken
+		if token == "" {
+			break
+		}
+		require.Less(t, len(pages), 5, "too many pages")
+	}
+	require.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, pages)
+}
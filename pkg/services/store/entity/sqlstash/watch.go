@@ -0,0 +1,309 @@
+package sqlstash
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/appcontext"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/grn"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/store/entity"
+	"github.com/grafana/grafana/pkg/util/sequence"
+)
+
+const (
+	defaultWatchPollInterval     = time.Second
+	defaultWatchBookmarkInterval = time.Minute
+
+	// The change log is read in batches of this size
+	watchBatchSize = 100
+
+	// How long a missing resource version is waited for (see sequence.Gaps)
+	watchGapTimeout = 10 * time.Second
+
+	// The changes are kept in the change log for this long
+	changeLogRetention = time.Hour
+)
+
+// Watch streams the changes from the `entity_change_log` table, in the order of their resource version
+func (s *sqlEntityServer) Watch(r *entity.EntityWatchRequest, srv entity.EntityStore_WatchServer) error {
+	ctx := srv.Context()
+	user, err := appcontext.User(ctx)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("missing user in context")
+	}
+
+	w := &entityWatcher{
+		server:   s,
+		r:        r,
+		srv:      srv,
+		tenantID: user.OrgID,
+		gaps:     sequence.Gaps{Timeout: watchGapTimeout},
+	}
+	for _, g := range r.GRN {
+		g, err := s.validateGRN(ctx, g)
+		if err != nil {
+			return err
+		}
+		w.grns = append(w.grns, g.ToGRNString())
+	}
+
+	w.rv, err = w.startVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	poll := time.NewTicker(s.watchPollInterval)
+	defer poll.Stop()
+
+	var bookmarks <-chan time.Time
+	if r.AllowBookmarks {
+		ticker := time.NewTicker(s.watchBookmarks)
+		defer ticker.Stop()
+		bookmarks = ticker.C
+	}
+
+	for {
+		if err := w.poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil // the watch was closed while reading
+			}
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-poll.C:
+		case <-bookmarks:
+			err = srv.Send(&entity.EntityWatchResponse{
+				Timestamp:       time.Now().UnixMilli(),
+				Action:          entity.EntityWatchResponse_BOOKMARK,
+				ResourceVersion: w.rv,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+type entityWatcher struct {
+	server   *sqlEntityServer
+	r        *entity.EntityWatchRequest
+	srv      entity.EntityStore_WatchServer
+	tenantID int64
+	grns     []string
+
+	// the last resource version read
+	rv   int64
+	gaps sequence.Gaps
+}
+
+// startVersion returns the resource version after which the changes are sent
+func (w *entityWatcher) startVersion(ctx context.Context) (int64, error) {
+	if w.r.ResourceVersion > 0 {
+		oldest, err := w.server.queryVersion(ctx, "SELECT MIN(resource_version) FROM entity_change_log")
+		if err != nil {
+			return 0, err
+		}
+		if oldest.Valid && w.r.ResourceVersion < oldest.Int64 {
+			return 0, fmt.Errorf("%w: the oldest resource version is %d", entity.ErrResourceVersionExpired, oldest.Int64)
+		}
+		return w.r.ResourceVersion, nil
+	}
+
+	if w.r.Since > 0 {
+		first, err := w.server.queryVersion(ctx, "SELECT MIN(resource_version) FROM entity_change_log WHERE tenant_id=? AND updated_at>?", w.tenantID, w.r.Since)
+		if err != nil || first.Valid {
+			return first.Int64 - 1, err
+		}
+	}
+
+	return w.server.currentResourceVersion(ctx)
+}
+
+// poll sends the committed changes after the last resource version
+func (w *entityWatcher) poll(ctx context.Context) error {
+	for {
+		committed, more, err := w.committedVersion(ctx)
+		if err != nil {
+			return err
+		}
+		if committed > w.rv {
+			if err = w.sendChanges(ctx, committed); err != nil {
+				return err
+			}
+			w.rv = committed
+		}
+		if !more {
+			return nil
+		}
+	}
+}
+
+// committedVersion returns the resource version up to which all the changes are committed
+func (w *entityWatcher) committedVersion(ctx context.Context) (int64, bool, error) {
+	rows, err := w.server.sess.Query(ctx,
+		"SELECT resource_version FROM entity_change_log WHERE resource_version>? ORDER BY resource_version LIMIT ?",
+		w.rv, watchBatchSize)
+	if err != nil {
+		return 0, false, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	committed := w.rv
+	count := 0
+	for rows.Next() {
+		rv := int64(0)
+		if err = rows.Scan(&rv); err != nil {
+			return 0, false, err
+		}
+		count++
+		if !w.gaps.Next(committed, rv) {
+			return committed, false, nil
+		}
+		committed = rv
+	}
+	return committed, count == watchBatchSize, rows.Err()
+}
+
+// sendChanges sends the changes matching the request, up to the resource version
+func (w *entityWatcher) sendChanges(ctx context.Context, upTo int64) error {
+	r := w.r
+	fields := []string{
+		"c.resource_version", "c.action",
+		"c.tenant_id", "c.kind", "c.uid", "c.folder",
+		"c.version", "c.size", "c.etag", "c.updated_at", "c.updated_by",
+		"c.name", "c.labels", "c.fields",
+	}
+	from := "entity_change_log c"
+	if r.WithBody {
+		// deleted entities have no history
+		fields = append(fields, "h.body")
+		from += " LEFT JOIN entity_history h ON h.grn=c.grn AND h.version=c.version"
+	}
+
+	changeQuery := selectQuery{
+		fields:  fields,
+		from:    from,
+		where:   []string{"c.resource_version>?", "c.resource_version<=?"},
+		args:    []any{w.rv, upTo},
+		orderBy: "c.resource_version",
+	}
+	changeQuery.addWhere("c.tenant_id", w.tenantID)
+	if len(r.Kind) > 0 {
+		changeQuery.addWhereIn("c.kind", r.Kind)
+	}
+	if r.Folder != "" {
+		changeQuery.addWhere("c.folder", r.Folder)
+	}
+	if len(w.grns) > 0 {
+		changeQuery.addWhereIn("c.grn", w.grns)
+	}
+
+	query, args := changeQuery.toQuery()
+	rows, err := w.server.sess.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		rsp := &entity.EntityWatchResponse{}
+		action := int32(0)
+		raw := &entity.Entity{
+			GRN: &grn.GRN{},
+		}
+		summaryjson := summarySupport{}
+
+		args := []any{
+			&rsp.ResourceVersion, &action,
+			&raw.GRN.TenantID, &raw.GRN.ResourceKind, &raw.GRN.ResourceIdentifier, &raw.Folder,
+			&raw.Version, &raw.Size, &raw.ETag, &raw.UpdatedAt, &raw.UpdatedBy,
+			&summaryjson.name, &summaryjson.labels, &summaryjson.fields,
+		}
+		if r.WithBody {
+			args = append(args, &raw.Body)
+		}
+		if err = rows.Scan(args...); err != nil {
+			return err
+		}
+
+		labels := map[string]string{}
+		if summaryjson.labels != nil {
+			if err = json.Unmarshal([]byte(*summaryjson.labels), &labels); err != nil {
+				return err
+			}
+		}
+		if !matchesLabels(labels, r.Labels) {
+			continue
+		}
+
+		if r.WithLabels || r.WithFields {
+			if !r.WithLabels {
+				summaryjson.labels = nil
+			}
+			if !r.WithFields {
+				summaryjson.fields = nil
+			}
+			summary, err := summaryjson.toEntitySummary()
+			if err != nil {
+				return err
+			}
+			raw.SummaryJson, err = json.Marshal(summary)
+			if err != nil {
+				return err
+			}
+		}
+
+		rsp.Timestamp = time.Now().UnixMilli()
+		rsp.Action = entity.EntityWatchResponse_Action(action)
+		rsp.Entity = []*entity.Entity{raw}
+		if err = w.srv.Send(rsp); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// deleteOldChanges deletes the changes older than the retention from the change log
+func (s *sqlEntityServer) deleteOldChanges(ctx context.Context) (int64, error) {
+	// the last change is kept, it holds the current resource version
+	last, err := s.currentResourceVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+	res, err := s.sess.Exec(ctx, "DELETE FROM entity_change_log WHERE created_at < ? AND resource_version < ?",
+		time.Now().Add(-changeLogRetention).UnixMilli(), last)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeleteOldChanges deletes the changes older than the retention from the change log of the entity store.
+// It is called periodically by the cleanup service.
+func DeleteOldChanges(ctx context.Context, sqlStore db.DB) (int64, error) {
+	s := &sqlEntityServer{
+		sess: sqlStore.GetSqlxSession(),
+		log:  log.New("sql-entity-server"),
+	}
+	return s.deleteOldChanges(ctx)
+}
+
+// matchesLabels checks that the labels include all the required ones
+func matchesLabels(labels map[string]string, required map[string]string) bool {
+	for k, v := range required {
+		if val, ok := labels[k]; !ok || val != v {
+			return false
+		}
+	}
+	return true
+}
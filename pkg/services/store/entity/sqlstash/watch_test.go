@@ -0,0 +1,231 @@
+package sqlstash
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/grafana/pkg/infra/appcontext"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/grn"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/store/entity"
+	"github.com/grafana/grafana/pkg/services/store/entity/migrations"
+	"github.com/grafana/grafana/pkg/services/store/kind"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestIntegrationSQLEntityWatch(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	sqlStore := db.InitTestDB(t)
+	err := migrations.MigrateEntityStore(sqlStore, featuremgmt.WithFeatures(featuremgmt.FlagEntityStore))
+	require.NoError(t, err)
+
+	s := &sqlEntityServer{
+		sess:              sqlStore.GetSqlxSession(),
+		log:               log.New("sql-entity-server-test"),
+		kinds:             kind.NewKindRegistry(),
+		watchPollInterval: 10 * time.Millisecond,
+		watchBookmarks:    50 * time.Millisecond,
+	}
+	ctx := appcontext.WithUser(context.Background(), &user.SignedInUser{UserID: 1, OrgID: 1, Login: "admin"})
+
+	write := func(t *testing.T, uid string, folder string, body string) {
+		t.Helper()
+		_, err := s.Write(ctx, &entity.WriteEntityRequest{
+			GRN:    &grn.GRN{ResourceKind: entity.StandardKindDashboard, ResourceIdentifier: uid},
+			Folder: folder,
+			Body:   []byte(body),
+		})
+		require.NoError(t, err)
+	}
+
+	write(t, "a", "f1", `{"title": "A", "tags": ["prod"]}`)
+	write(t, "b", "f2", `{"title": "B"}`)
+
+	// Only the changes after the watch starts
+	all := newTestWatch(ctx, t, s, &entity.EntityWatchRequest{})
+	write(t, "a", "f1", `{"title": "A v2", "tags": ["prod"]}`)
+	_, err = s.Delete(ctx, &entity.DeleteEntityRequest{
+		GRN: &grn.GRN{ResourceKind: entity.StandardKindDashboard, ResourceIdentifier: "b"},
+	})
+	require.NoError(t, err)
+	write(t, "c", "f1", `{"title": "C"}`)
+
+	var updated *entity.EntityWatchResponse
+	t.Run("sends the changes in order", func(t *testing.T) {
+		events := all.next(t, 3)
+		require.Equal(t, []string{"UPDATED a", "DELETED b", "CREATED c"}, eventNames(events))
+		require.Less(t, events[0].ResourceVersion, events[1].ResourceVersion)
+		require.Less(t, events[1].ResourceVersion, events[2].ResourceVersion)
+		require.Equal(t, "2", events[0].Entity[0].Version)
+		require.Equal(t, "f2", events[1].Entity[0].Folder)
+		require.Equal(t, "user:1:admin", events[1].Entity[0].UpdatedBy)
+		require.Nil(t, events[0].Entity[0].Body)
+		updated = events[0]
+
+		// The entities keep the resource version of their last change
+		c, err := s.Read(ctx, &entity.ReadEntityRequest{
+			GRN: &grn.GRN{ResourceKind: entity.StandardKindDashboard, ResourceIdentifier: "c"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, events[2].ResourceVersion, c.ResourceVersion)
+
+		found, err := s.Search(ctx, &entity.EntitySearchRequest{Kind: []string{entity.StandardKindDashboard}})
+		require.NoError(t, err)
+		require.Equal(t, events[2].ResourceVersion, found.ResourceVersion)
+		require.Len(t, found.Results, 2)
+		for _, r := range found.Results {
+			if r.GRN.ResourceIdentifier == "a" {
+				require.Equal(t, updated.ResourceVersion, r.ResourceVersion)
+			}
+		}
+	})
+
+	t.Run("resumes after the resource version", func(t *testing.T) {
+		w := newTestWatch(ctx, t, s, &entity.EntityWatchRequest{ResourceVersion: updated.ResourceVersion})
+		require.Equal(t, []string{"DELETED b", "CREATED c"}, eventNames(w.next(t, 2)))
+	})
+
+	t.Run("filters by label with the summary", func(t *testing.T) {
+		w := newTestWatch(ctx, t, s, &entity.EntityWatchRequest{
+			Since:      1,
+			Labels:     map[string]string{"prod": ""},
+			WithLabels: true,
+		})
+		events := w.next(t, 2)
+		require.Equal(t, []string{"CREATED a", "UPDATED a"}, eventNames(events))
+		require.JSONEq(t, `{"name": "A v2", "labels": {"prod": ""}}`, string(events[1].Entity[0].SummaryJson))
+	})
+
+	t.Run("filters by folder and kind with the body", func(t *testing.T) {
+		w := newTestWatch(ctx, t, s, &entity.EntityWatchRequest{
+			Since:    1,
+			Folder:   "f1",
+			Kind:     []string{entity.StandardKindDashboard},
+			WithBody: true,
+		})
+		events := w.next(t, 3)
+		require.Equal(t, []string{"CREATED a", "UPDATED a", "CREATED c"}, eventNames(events))
+		require.Contains(t, string(events[0].Entity[0].Body), `"A"`)
+		require.Contains(t, string(events[1].Entity[0].Body), `"A v2"`)
+	})
+
+	t.Run("sends the deletes without body", func(t *testing.T) {
+		w := newTestWatch(ctx, t, s, &entity.EntityWatchRequest{
+			ResourceVersion: updated.ResourceVersion,
+			Folder:          "f2",
+			WithBody:        true,
+		})
+		events := w.next(t, 1)
+		require.Equal(t, []string{"DELETED b"}, eventNames(events))
+		require.Nil(t, events[0].Entity[0].Body)
+	})
+
+	t.Run("filters by GRN", func(t *testing.T) {
+		w := newTestWatch(ctx, t, s, &entity.EntityWatchRequest{
+			Since: 1,
+			GRN:   []*grn.GRN{{ResourceKind: entity.StandardKindDashboard, ResourceIdentifier: "c"}},
+		})
+		require.Equal(t, []string{"CREATED c"}, eventNames(w.next(t, 1)))
+	})
+
+	t.Run("sends bookmarks", func(t *testing.T) {
+		w := newTestWatch(ctx, t, s, &entity.EntityWatchRequest{
+			ResourceVersion: updated.ResourceVersion,
+			Kind:            []string{entity.StandardKindPlaylist},
+			AllowBookmarks:  true,
+		})
+		events := w.next(t, 1)
+		require.Equal(t, entity.EntityWatchResponse_BOOKMARK, events[0].Action)
+		require.Greater(t, events[0].ResourceVersion, updated.ResourceVersion+1)
+	})
+
+	t.Run("deletes the old changes and expires their resource versions", func(t *testing.T) {
+		last, err := s.currentResourceVersion(ctx)
+		require.NoError(t, err)
+		_, err = s.sess.Exec(ctx, "UPDATE entity_change_log SET created_at=?", time.Now().Add(-2*changeLogRetention).UnixMilli())
+		require.NoError(t, err)
+
+		deleted, err := s.deleteOldChanges(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(4), deleted)
+		oldest, err := s.queryVersion(ctx, "SELECT MIN(resource_version) FROM entity_change_log")
+		require.NoError(t, err)
+		require.Equal(t, last, oldest.Int64)
+
+		err = s.Watch(&entity.EntityWatchRequest{ResourceVersion: updated.ResourceVersion}, &testWatch{ctx: ctx})
+		require.ErrorIs(t, err, entity.ErrResourceVersionExpired)
+
+		w := newTestWatch(ctx, t, s, &entity.EntityWatchRequest{ResourceVersion: last})
+		write(t, "d", "f1", `{"title": "D"}`)
+		require.Equal(t, []string{"CREATED d"}, eventNames(w.next(t, 1)))
+	})
+}
+
+func eventNames(events []*entity.EntityWatchResponse) []string {
+	names := make([]string, 0, len(events))
+	for _, e := range events {
+		names = append(names, e.Action.String()+" "+e.Entity[0].GRN.ResourceIdentifier)
+	}
+	return names
+}
+
+// testWatch runs a watch until the end of the test, and collects its events
+type testWatch struct {
+	grpc.ServerStream
+	ctx    context.Context
+	events chan *entity.EntityWatchResponse
+}
+
+func newTestWatch(ctx context.Context, t *testing.T, s *sqlEntityServer, r *entity.EntityWatchRequest) *testWatch {
+	t.Helper()
+	ctx, cancel := context.WithCancel(ctx)
+	w := &testWatch{
+		ctx:    ctx,
+		events: make(chan *entity.EntityWatchResponse, 100),
+	}
+	done := make(chan error)
+	go func() {
+		done <- s.Watch(r, w)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	// wait for the start of the watch, so the following changes are sent
+	time.Sleep(20 * time.Millisecond)
+	return w
+}
+
+func (w *testWatch) Context() context.Context {
+	return w.ctx
+}
+
+func (w *testWatch) Send(rsp *entity.EntityWatchResponse) error {
+	w.events <- rsp
+	return nil
+}
+
+// next waits for the next events
+func (w *testWatch) next(t *testing.T, count int) []*entity.EntityWatchResponse {
+	t.Helper()
+	var events []*entity.EntityWatchResponse
+	for len(events) < count {
+		select {
+		case e := <-w.events:
+			events = append(events, e)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "timeout waiting for events", "received %d of %d", len(events), count)
+		}
+	}
+	return events
+}
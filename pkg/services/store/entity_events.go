@@ -27,6 +27,11 @@ const (
 	EntityTypeFolder    EntityType = "folder"
 	EntityTypeImage     EntityType = "image"
 	EntityTypeJSON      EntityType = "json"
+
+	EntityTypeAlertRule    EntityType = "alertrule"
+	EntityTypeLibraryPanel EntityType = "librarypanel"
+	EntityTypeDatasource   EntityType = "datasource"
+	EntityTypePlaylist     EntityType = "playlist"
 )
 
 // CreateDatabaseEntityId creates entityId for entities stored in the existing SQL tables
@@ -42,6 +47,20 @@ func CreateDatabaseEntityId(internalId any, orgId int64, entityType EntityType)
 	return fmt.Sprintf("database/%d/%s/%s", orgId, entityType, internalIdAsString)
 }
 
+// InsertEntityEvent inserts the event of a change of an entity stored in the existing SQL tables, in the session of the
+// change so that the event is saved only if the change is.
+func InsertEntityEvent(sess *db.Session, internalId any, orgId int64, entityType EntityType, eventType EntityEventType) error {
+	_, err := sess.Insert(&EntityEvent{
+		EventType: eventType,
+		EntityId:  CreateDatabaseEntityId(internalId, orgId, entityType),
+		Created:   time.Now().Unix(),
+	})
+	return err
+}
+
+// EntityEventsRetention is how long entity events are kept before they are deleted.
+const EntityEventsRetention = 24 * time.Hour
+
 type EntityEvent struct {
 	Id        int64
 	EventType EntityEventType
@@ -132,7 +151,7 @@ func (e *entityEventService) Run(ctx context.Context) error {
 		select {
 		case <-clean.C:
 			go func() {
-				err := e.deleteEventsOlderThan(context.Background(), 24*time.Hour)
+				err := e.deleteEventsOlderThan(context.Background(), EntityEventsRetention)
 				if err != nil {
 					e.log.Info("Failed to delete old entity events", "error", err)
 				}
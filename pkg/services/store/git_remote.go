@@ -0,0 +1,236 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type GitPullRequestStatus = string
+
+const (
+	GitPullRequestOpen   GitPullRequestStatus = "open"
+	GitPullRequestMerged GitPullRequestStatus = "merged"
+	GitPullRequestClosed GitPullRequestStatus = "closed"
+)
+
+// GitChange is a change saved from the UI in a branch of the remote repository, with a pull request to merge it
+type GitChange struct {
+	Path         string               `json:"path"` // path of the file within the storage
+	DashboardUID string               `json:"dashboardUID,omitempty"`
+	Title        string               `json:"title,omitempty"`
+	Base         string               `json:"base"`
+	Branch       string               `json:"branch"`
+	Hash         string               `json:"hash"`             // head commit of the branch
+	Number       int                  `json:"number,omitempty"` // only when the remote has pull requests
+	URL          string               `json:"url,omitempty"`
+	Status       GitPullRequestStatus `json:"status"`
+	Author       string               `json:"author,omitempty"`
+	Created      time.Time            `json:"created"`
+	Updated      time.Time            `json:"updated"`
+}
+
+// gitRemote writes to the remote repository of a git storage
+type gitRemote interface {
+	// commitFile commits the file of the request on top of the base branch, and pushes it to the branch.
+	// The branch is created when it is not the base branch. It returns the hash of the commit.
+	commitFile(ctx context.Context, base string, branch string, cmd *WriteValueRequest) (string, error)
+
+	// openPullRequest opens a pull request to merge the head branch in the base branch
+	openPullRequest(ctx context.Context, cmd makePRCommand) (number int, url string, err error)
+
+	// pullRequestStatus returns the current status of the pull request of a change
+	pullRequestStatus(ctx context.Context, change *GitChange) (GitPullRequestStatus, error)
+}
+
+var _ gitRemote = &plainGitRemote{}
+var _ gitRemote = &githubHelper{}
+
+// plainGitRemote writes to any git remote, such as a bare repository on disk. It has no pull requests: the branch of a
+// change is merged once its commit is in the base branch, and closed when it is deleted without being merged.
+type plainGitRemote struct {
+	url  string
+	auth transport.AuthMethod
+	repo *git.Repository // local clone, pulled by the storage
+}
+
+func newPlainGitRemote(url string, token string, repo *git.Repository) *plainGitRemote {
+	r := &plainGitRemote{
+		url:  url,
+		repo: repo,
+	}
+	if token != "" {
+		r.auth = &http.BasicAuth{
+			Username: "grafana", // anything but empty
+			Password: token,
+		}
+	}
+	return r
+}
+
+func (r *plainGitRemote) commitFile(ctx context.Context, base string, branch string, cmd *WriteValueRequest) (string, error) {
+	rel := strings.TrimPrefix(cmd.Path, "/")
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("invalid path: %s", cmd.Path)
+	}
+
+	// Commit in a scratch clone, so the working tree of the storage always matches the base branch
+	dir, err := os.MkdirTemp("", "grafana-git-")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	repo, err := git.PlainCloneContext(ctx, dir, false, &git.CloneOptions{
+		URL:           r.url,
+		Auth:          r.auth,
+		ReferenceName: plumbing.NewBranchReferenceName(base),
+		SingleBranch:  true,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	if branch != base {
+		err = w.Checkout(&git.CheckoutOptions{
+			Branch: plumbing.NewBranchReferenceName(branch),
+			Create: true,
+		})
+		if err != nil {
+			return "", fmt.Errorf("unable to create branch: %w", err)
+		}
+	}
+
+	fpath := filepath.Join(dir, filepath.FromSlash(rel))
+	if err = os.MkdirAll(filepath.Dir(fpath), 0750); err != nil {
+		return "", err
+	}
+	if err = os.WriteFile(fpath, cmd.Body, 0600); err != nil {
+		return "", err
+	}
+	if _, err = w.Add(filepath.ToSlash(rel)); err != nil {
+		return "", err
+	}
+
+	hash, err := w.Commit(gitCommitMessage(cmd), &git.CommitOptions{
+		Author: gitCommitSignature(cmd.User),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error creating commit: %w", err)
+	}
+
+	refspec := fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch)
+	err = repo.PushContext(ctx, &git.PushOptions{
+		Auth:     r.auth,
+		RefSpecs: []config.RefSpec{config.RefSpec(refspec)},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error pushing commit: %w", err)
+	}
+	return hash.String(), nil
+}
+
+func (r *plainGitRemote) openPullRequest(ctx context.Context, cmd makePRCommand) (int, string, error) {
+	// The branch is all there is to merge
+	return 0, "", nil
+}
+
+func (r *plainGitRemote) pullRequestStatus(ctx context.Context, change *GitChange) (GitPullRequestStatus, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{r.url},
+	})
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: r.auth})
+	if err != nil {
+		return "", err
+	}
+
+	branchExists := false
+	baseHash := plumbing.ZeroHash
+	for _, ref := range refs {
+		switch ref.Name() {
+		case plumbing.NewBranchReferenceName(change.Branch):
+			branchExists = true
+		case plumbing.NewBranchReferenceName(change.Base):
+			baseHash = ref.Hash()
+		}
+	}
+
+	merged, err := r.isMerged(plumbing.NewHash(change.Hash), baseHash)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case merged:
+		return GitPullRequestMerged, nil
+	case !branchExists:
+		return GitPullRequestClosed, nil
+	default:
+		return GitPullRequestOpen, nil
+	}
+}
+
+// isMerged checks if the commit is in the history of the base branch. The base branch must be pulled already.
+func (r *plainGitRemote) isMerged(hash plumbing.Hash, baseHash plumbing.Hash) (bool, error) {
+	if baseHash.IsZero() {
+		return false, nil
+	}
+	base, err := r.repo.CommitObject(baseHash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return false, nil // not pulled yet
+	}
+	if err != nil {
+		return false, err
+	}
+
+	commit, err := r.repo.CommitObject(hash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return false, nil // only in the branch
+	}
+	if err != nil {
+		return false, err
+	}
+	return commit.IsAncestor(base)
+}
+
+func gitCommitMessage(cmd *WriteValueRequest) string {
+	if cmd.Message == "" {
+		return "changes from grafana ui"
+	}
+	return cmd.Message
+}
+
+func gitCommitSignature(usr *user.SignedInUser) *object.Signature {
+	if usr == nil {
+		usr = &user.SignedInUser{}
+	}
+	return &object.Signature{
+		Name:  firstRealString(usr.Name, usr.Login, usr.Email, "?"),
+		Email: firstRealString(usr.Email, usr.Login, usr.Name, "?"),
+		When:  time.Now(),
+	}
+}
+
+func isGithubRemote(remote string) bool {
+	return strings.Contains(remote, "github.com")
+}
@@ -0,0 +1,478 @@
+package store
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	ErrInvalidWebhookSignature  = errors.New("invalid webhook signature")
+	ErrGitSyncedByAnotherServer = errors.New("the dashboards are synced by another server")
+)
+
+const (
+	gitSyncKVNamespace     = "storage.git"
+	defaultGitSyncInterval = time.Minute
+	gitChangeRetention     = 7 * 24 * time.Hour // for the merged and closed changes
+	gitProvisionerPrefix   = "git:"
+	gitSyncLeasePrefix     = "storage git sync "
+)
+
+type leaser interface {
+	AcquireLease(ctx context.Context, name string, ttl time.Duration) (*serverlock.Lease, error)
+}
+
+// GitSyncStatus is the result of a sync of the dashboards of a git storage
+type GitSyncStatus struct {
+	Commit   string    `json:"commit,omitempty"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Imported []string  `json:"imported,omitempty"` // files of the created or updated dashboards
+	Deleted  []string  `json:"deleted,omitempty"`  // files removed from the repository
+	Errors   []string  `json:"errors,omitempty"`
+}
+
+// gitDashboardSync keeps the dashboards table in sync with the dashboards of a git storage. The files are imported as
+// provisioned dashboards, so the provisioning record tells which file of which storage a dashboard comes from.
+// The changes saved from the UI go through a pull request, and are pending until it is merged and pulled.
+// In an HA setup a single server holds the lease of the storage and syncs the dashboards, the other servers only pull
+// the repository to serve its files.
+type gitDashboardSync struct {
+	root        *rootStorageGit
+	provisioner string
+	orgID       int64
+	interval    time.Duration
+	dashboards  dashboards.DashboardProvisioningService
+	changes     *kvstore.NamespacedKVStore // by branch
+	serverLock  leaser
+	log         log.Logger
+
+	mu       sync.Mutex // one sync at a time
+	lease    *serverlock.Lease
+	statusMu sync.RWMutex
+	status   *GitSyncStatus
+}
+
+func newGitDashboardSync(root *rootStorageGit, dashboardService dashboards.DashboardProvisioningService, kv kvstore.KVStore, serverLock leaser) *gitDashboardSync {
+	prefix := root.meta.Config.Prefix
+	orgID := root.settings.OrgID
+	if orgID == 0 {
+		orgID = 1
+	}
+
+	gs := &gitDashboardSync{
+		root:        root,
+		provisioner: gitProvisionerPrefix + prefix,
+		orgID:       orgID,
+		interval:    defaultGitSyncInterval,
+		dashboards:  dashboardService,
+		changes:     kvstore.WithNamespace(kv, orgID, gitSyncKVNamespace+"."+prefix),
+		serverLock:  serverLock,
+		log:         log.New("storage.git.sync", "prefix", prefix),
+	}
+	if root.settings.PullInterval != "" {
+		if interval, err := time.ParseDuration(root.settings.PullInterval); err == nil && interval > 0 {
+			gs.interval = interval
+		}
+	}
+	root.sync = gs
+	return gs
+}
+
+// run syncs the dashboards until the context is done
+func (gs *gitDashboardSync) run(ctx context.Context) {
+	ticker := time.NewTicker(gs.interval)
+	defer ticker.Stop()
+	defer gs.releaseLease()
+
+	for {
+		_, err := gs.sync(ctx)
+		if errors.Is(err, ErrGitSyncedByAnotherServer) {
+			err = gs.pull()
+		}
+		if err != nil {
+			gs.log.Warn("Failed to sync dashboards", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sync pulls the repository, imports the dashboards that changed, deletes the dashboards whose file was removed and
+// refreshes the status of the open pull requests. It returns ErrGitSyncedByAnotherServer if another server holds the
+// lease of the storage.
+func (gs *gitDashboardSync) sync(ctx context.Context) (*GitSyncStatus, error) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if gs.root.repo == nil {
+		return nil, fmt.Errorf("git repository not initialized")
+	}
+	if err := gs.holdLease(ctx); err != nil {
+		return nil, err
+	}
+
+	status := &GitSyncStatus{Started: time.Now()}
+	if err := gs.root.Sync(); err != nil {
+		return nil, err
+	}
+
+	head, err := gs.root.repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := gs.root.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, err
+	}
+	status.Commit = commit.Hash.String()
+
+	provisioned, err := gs.getProvisionedDashboards(ctx)
+	if err != nil {
+		return nil, err
+	}
+	files, err := gs.getDashboardFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	for path, file := range files {
+		imported, err := gs.importDashboard(ctx, path, file, provisioned[path], status.Commit, commit.Committer.When)
+		if err != nil {
+			status.Errors = append(status.Errors, fmt.Sprintf("%s: %s", path, err.Error()))
+			continue
+		}
+		if imported {
+			status.Imported = append(status.Imported, path)
+		}
+	}
+
+	for path, p := range provisioned {
+		if _, ok := files[path]; ok {
+			continue
+		}
+		if err := gs.dashboards.DeleteProvisionedDashboard(ctx, p.DashboardID, gs.orgID); err != nil {
+			status.Errors = append(status.Errors, fmt.Sprintf("%s: %s", path, err.Error()))
+			continue
+		}
+		status.Deleted = append(status.Deleted, path)
+	}
+
+	if err := gs.refreshChanges(ctx); err != nil {
+		status.Errors = append(status.Errors, "pull requests: "+err.Error())
+	}
+
+	sort.Strings(status.Imported)
+	sort.Strings(status.Deleted)
+	sort.Strings(status.Errors)
+	status.Finished = time.Now()
+
+	gs.statusMu.Lock()
+	gs.status = status
+	gs.statusMu.Unlock()
+
+	if len(status.Imported) > 0 || len(status.Deleted) > 0 {
+		gs.log.Info("Synced dashboards", "commit", status.Commit, "imported", len(status.Imported), "deleted", len(status.Deleted))
+	}
+	return status, nil
+}
+
+// pull only pulls the repository, for the servers that do not sync the dashboards
+func (gs *gitDashboardSync) pull() error {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if gs.root.repo == nil {
+		return fmt.Errorf("git repository not initialized")
+	}
+	return gs.root.Sync()
+}
+
+// holdLease renews the lease held by this server, or tries to acquire it. It must be called with the mutex held.
+func (gs *gitDashboardSync) holdLease(ctx context.Context) error {
+	if gs.lease != nil {
+		err := gs.lease.Renew(ctx)
+		if err == nil {
+			return nil
+		}
+		gs.log.Warn("Failed to renew the sync lease", "error", err)
+		gs.lease = nil
+	}
+
+	// the lease outlives a few intervals, so that another server takes over when this one stops
+	lease, err := gs.serverLock.AcquireLease(ctx, gitSyncLeasePrefix+gs.root.meta.Config.Prefix, 3*gs.interval)
+	var heldErr *serverlock.LeaseHeldError
+	if errors.As(err, &heldErr) {
+		return ErrGitSyncedByAnotherServer
+	}
+	if err != nil {
+		return err
+	}
+	gs.lease = lease
+	return nil
+}
+
+func (gs *gitDashboardSync) releaseLease() {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if gs.lease == nil {
+		return
+	}
+	if err := gs.lease.Release(context.Background()); err != nil {
+		gs.log.Warn("Failed to release the sync lease", "error", err)
+	}
+	gs.lease = nil
+}
+
+// lastStatus returns the status of the last sync, or nil before the first one
+func (gs *gitDashboardSync) lastStatus() *GitSyncStatus {
+	gs.statusMu.RLock()
+	defer gs.statusMu.RUnlock()
+	return gs.status
+}
+
+func (gs *gitDashboardSync) getProvisionedDashboards(ctx context.Context) (map[string]*dashboards.DashboardProvisioning, error) {
+	arr, err := gs.dashboards.GetProvisionedDashboardData(ctx, gs.provisioner)
+	if err != nil {
+		return nil, err
+	}
+
+	byPath := make(map[string]*dashboards.DashboardProvisioning, len(arr))
+	for _, p := range arr {
+		byPath[p.ExternalID] = p
+	}
+	return byPath, nil
+}
+
+// getDashboardFiles returns the JSON files of the storage, by their path within the storage
+func (gs *gitDashboardSync) getDashboardFiles() (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(gs.root.root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.EqualFold(filepath.Ext(file), ".json") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(gs.root.root, file)
+		if err != nil {
+			return err
+		}
+		files["/"+filepath.ToSlash(rel)] = file
+		return nil
+	})
+	return files, err
+}
+
+// importDashboard saves the dashboard of the file, unless it did not change since it was last imported
+func (gs *gitDashboardSync) importDashboard(ctx context.Context, path string, file string, provisioned *dashboards.DashboardProvisioning, commit string, updated time.Time) (bool, error) {
+	// nolint:gosec
+	// The file is within the clone of the repository
+	body, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+
+	checkSum, err := util.Md5SumString(string(body))
+	if err != nil {
+		return false, err
+	}
+	if provisioned != nil && provisioned.CheckSum == checkSum {
+		return false, nil
+	}
+
+	data, err := simplejson.NewJson(body)
+	if err != nil {
+		return false, err
+	}
+	dash := dashboards.NewDashboardFromJson(data)
+	if dash.Title == "" {
+		return false, dashboards.ErrDashboardTitleEmpty
+	}
+	dash.OrgID = gs.orgID
+	if dash.ID != 0 {
+		dash.Data.Set("id", nil)
+		dash.ID = 0
+	}
+	if provisioned != nil {
+		dash.SetID(provisioned.DashboardID)
+	}
+
+	_, err = gs.dashboards.SaveProvisionedDashboard(ctx, &dashboards.SaveDashboardDTO{
+		OrgID:     gs.orgID,
+		UpdatedAt: updated,
+		Message:   "Synced from git commit " + commit,
+		Overwrite: true,
+		Dashboard: dash,
+	}, &dashboards.DashboardProvisioning{
+		Name:       gs.provisioner,
+		ExternalID: path,
+		CheckSum:   checkSum,
+		Updated:    updated.Unix(),
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (gs *gitDashboardSync) trackChange(ctx context.Context, change *GitChange) error {
+	body, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	return gs.changes.Set(ctx, change.Branch, string(body))
+}
+
+// listChanges returns the tracked changes, oldest first
+func (gs *gitDashboardSync) listChanges(ctx context.Context) ([]*GitChange, error) {
+	keys, err := gs.changes.Keys(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*GitChange, 0, len(keys))
+	for _, key := range keys {
+		body, ok, err := gs.changes.Get(ctx, key.Key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		change := &GitChange{}
+		if err := json.Unmarshal([]byte(body), change); err != nil {
+			gs.log.Warn("Invalid tracked change", "branch", key.Key, "error", err)
+			continue
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Created.Equal(changes[j].Created) {
+			return changes[i].Branch < changes[j].Branch
+		}
+		return changes[i].Created.Before(changes[j].Created)
+	})
+	return changes, nil
+}
+
+// pendingChanges returns the changes with an open pull request, optionally only those of a dashboard
+func (gs *gitDashboardSync) pendingChanges(ctx context.Context, dashboardUID string) ([]*GitChange, error) {
+	changes, err := gs.listChanges(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]*GitChange, 0)
+	for _, change := range changes {
+		if change.Status != GitPullRequestOpen {
+			continue
+		}
+		if dashboardUID != "" && change.DashboardUID != dashboardUID {
+			continue
+		}
+		pending = append(pending, change)
+	}
+	return pending, nil
+}
+
+// refreshChanges updates the status of the open pull requests, and forgets the old merged and closed ones
+func (gs *gitDashboardSync) refreshChanges(ctx context.Context) error {
+	changes, err := gs.listChanges(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, change := range changes {
+		if change.Status != GitPullRequestOpen {
+			if time.Since(change.Updated) > gitChangeRetention {
+				if err := gs.changes.Del(ctx, change.Branch); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			continue
+		}
+		if gs.root.remote == nil {
+			continue
+		}
+
+		status, err := gs.root.remote.pullRequestStatus(ctx, change)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", change.Branch, err))
+			continue
+		}
+		if status == change.Status {
+			continue
+		}
+
+		gs.log.Info("Pull request status changed", "branch", change.Branch, "status", status)
+		change.Status = status
+		change.Updated = time.Now()
+		if err := gs.trackChange(ctx, change); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// verifyWebhook checks that the payload of a webhook is signed with its secret. GitHub and Gitea send the HMAC-SHA256
+// signature in the X-Hub-Signature-256 header, GitLab sends the secret itself in the X-Gitlab-Token header.
+func (gs *gitDashboardSync) verifyWebhook(header http.Header, body []byte) error {
+	secret, ok := lookupConfigSecret(gs.root.settings.WebhookSecret)
+	if !ok || secret == "" {
+		return ErrInvalidWebhookSignature
+	}
+
+	if token := header.Get("X-Gitlab-Token"); token != "" {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			return ErrInvalidWebhookSignature
+		}
+		return nil
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(header.Get("X-Hub-Signature-256"), "sha256="))
+	if err != nil || len(signature) == 0 {
+		return ErrInvalidWebhookSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
@@ -0,0 +1,417 @@
+package store
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestGitDashboardSync(t *testing.T) {
+	ctx := context.Background()
+	remote := newTestGitRemote(t)
+	remote.commit(t, map[string]string{
+		"/a.json":        `{"uid": "a", "title": "A"}`,
+		"/folder/b.json": `{"uid": "b", "title": "B"}`,
+		"/README.md":     "dashboards",
+	})
+
+	provisioning := newFakeGitProvisioning()
+	root := newGitStorage(RootStorageMeta{}, RootStorageConfig{
+		Prefix: "dashboards",
+		Git: &StorageGitConfig{
+			Remote:         remote.url,
+			Branch:         "master",
+			SyncDashboards: true,
+		},
+	}, filepath.Join(t.TempDir(), "cache"))
+	require.Empty(t, root.meta.Notice)
+	serverLock := serverlock.ProvideService(db.InitTestDB(t), tracing.InitializeTracerForTest())
+	gs := newGitDashboardSync(root, provisioning, kvstore.NewFakeKVStore(), serverLock)
+
+	t.Run("imports the dashboards with their provenance", func(t *testing.T) {
+		status, err := gs.sync(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"/a.json", "/folder/b.json"}, status.Imported)
+		require.Empty(t, status.Errors)
+		require.Equal(t, status, gs.lastStatus())
+
+		p := provisioning.byPath["/a.json"]
+		require.Equal(t, "git:dashboards", p.Name)
+		require.Equal(t, "a", provisioning.dashboards[p.DashboardID].UID)
+		require.Equal(t, "Synced from git commit "+status.Commit, provisioning.messages[p.DashboardID])
+
+		status, err = gs.sync(ctx)
+		require.NoError(t, err)
+		require.Empty(t, status.Imported)
+	})
+
+	t.Run("imports the changed dashboards and deletes the removed ones", func(t *testing.T) {
+		remote.commit(t, map[string]string{
+			"/a.json":        `{"uid": "a", "title": "A v2"}`,
+			"/folder/b.json": "",
+		})
+
+		status, err := gs.sync(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"/a.json"}, status.Imported)
+		require.Equal(t, []string{"/folder/b.json"}, status.Deleted)
+		require.Equal(t, "A v2", provisioning.dashboards[provisioning.byPath["/a.json"].DashboardID].Title)
+		require.Len(t, provisioning.byPath, 1)
+	})
+
+	t.Run("pushes the saves to the base branch", func(t *testing.T) {
+		rsp, err := root.Write(ctx, &WriteValueRequest{
+			User:     &user.SignedInUser{Login: "admin"},
+			Path:     "/c.json",
+			Body:     []byte(`{"uid": "c", "title": "C"}`),
+			Workflow: WriteValueWorkflow_Push,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 200, rsp.Code, rsp.Message)
+		require.Equal(t, "master", rsp.Branch)
+		require.Equal(t, "C", provisioning.dashboards[provisioning.byPath["/c.json"].DashboardID].Title)
+	})
+
+	t.Run("tracks the pull requests of the saves until they are merged", func(t *testing.T) {
+		rsp, err := root.Write(ctx, &WriteValueRequest{
+			User:     &user.SignedInUser{Login: "admin"},
+			Path:     "/a.json",
+			Body:     []byte(`{"uid": "a", "title": "A v3"}`),
+			Title:    "Update A",
+			Workflow: WriteValueWorkflow_PR,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 200, rsp.Code, rsp.Message)
+		require.True(t, rsp.Pending)
+
+		pending, err := gs.pendingChanges(ctx, "a")
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		require.Equal(t, rsp.Branch, pending[0].Branch)
+		require.Equal(t, rsp.Hash, pending[0].Hash)
+		require.Equal(t, "/a.json", pending[0].Path)
+		require.Equal(t, "admin", pending[0].Author)
+
+		// The dashboard is not changed until the pull request is merged
+		status, err := gs.sync(ctx)
+		require.NoError(t, err)
+		require.Empty(t, status.Imported)
+		pending, err = gs.pendingChanges(ctx, "")
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+
+		remote.merge(t, rsp.Branch)
+		status, err = gs.sync(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"/a.json"}, status.Imported)
+		require.Equal(t, "A v3", provisioning.dashboards[provisioning.byPath["/a.json"].DashboardID].Title)
+
+		pending, err = gs.pendingChanges(ctx, "")
+		require.NoError(t, err)
+		require.Empty(t, pending)
+		changes, err := gs.listChanges(ctx)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.Equal(t, GitPullRequestMerged, changes[0].Status)
+	})
+
+	t.Run("closes the pull requests whose branch is deleted", func(t *testing.T) {
+		rsp, err := root.Write(ctx, &WriteValueRequest{
+			User:     &user.SignedInUser{Login: "admin"},
+			Path:     "/c.json",
+			Body:     []byte(`{"uid": "c", "title": "C v2"}`),
+			Workflow: WriteValueWorkflow_PR,
+		})
+		require.NoError(t, err)
+		require.Equal(t, 200, rsp.Code, rsp.Message)
+
+		remote.deleteBranch(t, rsp.Branch)
+		_, err = gs.sync(ctx)
+		require.NoError(t, err)
+
+		pending, err := gs.pendingChanges(ctx, "c")
+		require.NoError(t, err)
+		require.Empty(t, pending)
+		changes, err := gs.listChanges(ctx)
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		require.Equal(t, GitPullRequestClosed, changes[1].Status)
+	})
+
+	t.Run("only the server holding the lease syncs the dashboards", func(t *testing.T) {
+		otherRoot := newGitStorage(RootStorageMeta{}, RootStorageConfig{
+			Prefix: "dashboards",
+			Git: &StorageGitConfig{
+				Remote:         remote.url,
+				Branch:         "master",
+				SyncDashboards: true,
+			},
+		}, filepath.Join(t.TempDir(), "cache"))
+		require.Empty(t, otherRoot.meta.Notice)
+		otherProvisioning := newFakeGitProvisioning()
+		other := newGitDashboardSync(otherRoot, otherProvisioning, kvstore.NewFakeKVStore(), serverLock)
+
+		_, err := other.sync(ctx)
+		require.ErrorIs(t, err, ErrGitSyncedByAnotherServer)
+		require.NoError(t, other.pull())
+		require.Empty(t, otherProvisioning.byPath)
+
+		gs.releaseLease()
+		status, err := other.sync(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"/a.json", "/c.json"}, status.Imported)
+
+		_, err = gs.sync(ctx)
+		require.ErrorIs(t, err, ErrGitSyncedByAnotherServer)
+		other.releaseLease()
+	})
+}
+
+func TestGitDashboardSyncWebhook(t *testing.T) {
+	gs := &gitDashboardSync{
+		root: &rootStorageGit{
+			settings: &StorageGitConfig{WebhookSecret: "secret"},
+		},
+	}
+	body := []byte(`{"ref": "refs/heads/main"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		err    error
+	}{
+		{
+			name:   "signature",
+			header: http.Header{"X-Hub-Signature-256": []string{"sha256=" + hex.EncodeToString(mac.Sum(nil))}},
+		},
+		{
+			name:   "invalid signature",
+			header: http.Header{"X-Hub-Signature-256": []string{"sha256=" + hex.EncodeToString([]byte("nope"))}},
+			err:    ErrInvalidWebhookSignature,
+		},
+		{
+			name:   "token",
+			header: http.Header{"X-Gitlab-Token": []string{"secret"}},
+		},
+		{
+			name:   "invalid token",
+			header: http.Header{"X-Gitlab-Token": []string{"nope"}},
+			err:    ErrInvalidWebhookSignature,
+		},
+		{
+			name:   "no signature",
+			header: http.Header{},
+			err:    ErrInvalidWebhookSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(t, gs.verifyWebhook(tt.header, body), tt.err)
+		})
+	}
+
+	t.Run("no secret", func(t *testing.T) {
+		gs.root.settings.WebhookSecret = ""
+		require.ErrorIs(t, gs.verifyWebhook(http.Header{"X-Gitlab-Token": []string{""}}, body), ErrInvalidWebhookSignature)
+	})
+}
+
+// testGitRemote is a bare repository, with a clone to push to it like another user would
+type testGitRemote struct {
+	url   string
+	clone *git.Repository
+	dir   string
+}
+
+func newTestGitRemote(t *testing.T) *testGitRemote {
+	t.Helper()
+
+	url := filepath.Join(t.TempDir(), "remote.git")
+	_, err := git.PlainInit(url, true)
+	require.NoError(t, err)
+
+	dir := filepath.Join(t.TempDir(), "clone")
+	clone, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	_, err = clone.CreateRemote(&config.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{url},
+	})
+	require.NoError(t, err)
+
+	return &testGitRemote{url: url, clone: clone, dir: dir}
+}
+
+// commit writes the files, or removes those without content, and pushes them to the master branch
+func (r *testGitRemote) commit(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	w, err := r.clone.Worktree()
+	require.NoError(t, err)
+	for path, content := range files {
+		fpath := filepath.Join(r.dir, filepath.FromSlash(path))
+		if content == "" {
+			_, err = w.Remove(path[1:])
+			require.NoError(t, err)
+			continue
+		}
+		require.NoError(t, os.MkdirAll(filepath.Dir(fpath), 0750))
+		require.NoError(t, os.WriteFile(fpath, []byte(content), 0600))
+		_, err = w.Add(path[1:])
+		require.NoError(t, err)
+	}
+
+	_, err = w.Commit("update", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	r.push(t, "refs/heads/master:refs/heads/master")
+}
+
+// merge fast-forwards the master branch to the branch
+func (r *testGitRemote) merge(t *testing.T, branch string) {
+	t.Helper()
+
+	err := r.clone.Fetch(&git.FetchOptions{
+		RefSpecs: []config.RefSpec{"+refs/heads/*:refs/remotes/origin/*"},
+	})
+	require.NoError(t, err)
+	r.push(t, fmt.Sprintf("refs/remotes/origin/%s:refs/heads/master", branch))
+}
+
+func (r *testGitRemote) deleteBranch(t *testing.T, branch string) {
+	t.Helper()
+	r.push(t, ":refs/heads/"+branch)
+}
+
+func (r *testGitRemote) push(t *testing.T, refspec string) {
+	t.Helper()
+	err := r.clone.Push(&git.PushOptions{
+		RefSpecs: []config.RefSpec{config.RefSpec(refspec)},
+	})
+	require.NoError(t, err)
+}
+
+// fakeGitProvisioning keeps the provisioned dashboards in memory
+type fakeGitProvisioning struct {
+	dashboards.DashboardProvisioningService
+
+	nextID     int64
+	byPath     map[string]*dashboards.DashboardProvisioning
+	dashboards map[int64]*dashboards.Dashboard
+	messages   map[int64]string
+}
+
+func newFakeGitProvisioning() *fakeGitProvisioning {
+	return &fakeGitProvisioning{
+		byPath:     make(map[string]*dashboards.DashboardProvisioning),
+		dashboards: make(map[int64]*dashboards.Dashboard),
+		messages:   make(map[int64]string),
+	}
+}
+
+func (f *fakeGitProvisioning) GetProvisionedDashboardData(ctx context.Context, name string) ([]*dashboards.DashboardProvisioning, error) {
+	var arr []*dashboards.DashboardProvisioning
+	for _, p := range f.byPath {
+		if p.Name == name {
+			arr = append(arr, p)
+		}
+	}
+	return arr, nil
+}
+
+func (f *fakeGitProvisioning) SaveProvisionedDashboard(ctx context.Context, dto *dashboards.SaveDashboardDTO, provisioning *dashboards.DashboardProvisioning) (*dashboards.Dashboard, error) {
+	dash := dto.Dashboard
+	if dash.ID == 0 {
+		f.nextID++
+		dash.ID = f.nextID
+	}
+	provisioning.DashboardID = dash.ID
+	f.byPath[provisioning.ExternalID] = provisioning
+	f.dashboards[dash.ID] = dash
+	f.messages[dash.ID] = dto.Message
+	return dash, nil
+}
+
+func (f *fakeGitProvisioning) DeleteProvisionedDashboard(ctx context.Context, dashboardID int64, orgID int64) error {
+	for path, p := range f.byPath {
+		if p.DashboardID == dashboardID {
+			delete(f.byPath, path)
+		}
+	}
+	delete(f.dashboards, dashboardID)
+	return nil
+}
+
+func TestGetGitChanges(t *testing.T) {
+	s := &standardStorageService{
+		gitSyncs: map[string]*gitDashboardSync{
+			"dashboards": {orgID: 1, changes: kvstore.WithNamespace(kvstore.NewFakeKVStore(), 1, gitSyncKVNamespace+".dashboards")},
+		},
+		accessControl: acimpl.ProvideAccessControl(setting.NewCfg()),
+	}
+
+	tests := []struct {
+		name         string
+		query        string
+		user         *user.SignedInUser
+		expectedCode int
+	}{
+		{
+			name:         "lists the changes of a dashboard the user can read",
+			query:        "?dashboardUID=a",
+			user:         &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: {dashboards.ActionDashboardsRead: {"dashboards:uid:a"}}}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "denies the changes of a dashboard the user can't read",
+			query:        "?dashboardUID=b",
+			user:         &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: {dashboards.ActionDashboardsRead: {"dashboards:uid:a"}}}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "denies the changes of all the dashboards to the users",
+			user:         &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: {dashboards.ActionDashboardsRead: {"dashboards:*"}}}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "lists the changes of all the dashboards to the Grafana admins",
+			user:         &user.SignedInUser{OrgID: 1, IsGrafanaAdmin: true},
+			expectedCode: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := web.SetURLParams(httptest.NewRequest(http.MethodGet, "/api/storage/git/dashboards/changes"+tt.query, nil), map[string]string{":prefix": "dashboards"})
+			c := &contextmodel.ReqContext{Context: &web.Context{Req: req}, SignedInUser: tt.user}
+			rsp := s.getGitChanges(c)
+			require.Equal(t, tt.expectedCode, rsp.Status())
+		})
+	}
+}
@@ -130,6 +130,50 @@ func (g *githubHelper) createPR(ctx context.Context, cmd makePRCommand) (*github
 	return g.client.PullRequests.Create(ctx, g.repoOwner, g.repoName, newPR)
 }
 
+func (g *githubHelper) commitFile(ctx context.Context, base string, branch string, cmd *WriteValueRequest) (string, error) {
+	var ref *github.Reference
+	var err error
+	if branch == base {
+		ref, _, err = g.getRef(ctx, base)
+	} else {
+		ref, _, err = g.createRef(ctx, base, branch)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to create branch: %w", err)
+	}
+
+	if cmd.Message == "" {
+		cmd.Message = gitCommitMessage(cmd)
+	}
+	if err = g.pushCommit(ctx, ref, cmd); err != nil {
+		return "", fmt.Errorf("error creating commit: %w", err)
+	}
+	return ref.GetObject().GetSHA(), nil
+}
+
+func (g *githubHelper) openPullRequest(ctx context.Context, cmd makePRCommand) (int, string, error) {
+	pr, _, err := g.createPR(ctx, cmd)
+	if err != nil {
+		return 0, "", err
+	}
+	return pr.GetNumber(), pr.GetHTMLURL(), nil
+}
+
+func (g *githubHelper) pullRequestStatus(ctx context.Context, change *GitChange) (GitPullRequestStatus, error) {
+	pr, _, err := g.client.PullRequests.Get(ctx, g.repoOwner, g.repoName, change.Number)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case pr.GetMerged():
+		return GitPullRequestMerged, nil
+	case pr.GetState() == "closed":
+		return GitPullRequestClosed, nil
+	default:
+		return GitPullRequestOpen, nil
+	}
+}
+
 // func (g *githubHelper) getPR(config *Config, prSubject string) (*github.PullRequest, error) {
 
 // 	opts := github.PullRequestListOptions{}
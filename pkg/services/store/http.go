@@ -1,6 +1,7 @@
 package store
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,7 +12,9 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -55,6 +58,15 @@ func (s *standardStorageService) RegisterHTTPRoutes(storageRoute routing.RouteRe
 	storageRoute.Post("/createFolder", reqGrafanaAdmin, routing.Wrap(s.doCreateFolder))
 	storageRoute.Post("/deleteFolder", reqGrafanaAdmin, routing.Wrap(s.doDeleteFolder))
 	storageRoute.Get("/config", reqGrafanaAdmin, routing.Wrap(s.getConfig))
+
+	// Dashboards synced with a git repository
+	storageRoute.Get("/git/:prefix/changes", routing.Wrap(s.getGitChanges))
+	storageRoute.Get("/git/:prefix/status", reqGrafanaAdmin, routing.Wrap(s.getGitSyncStatus))
+	storageRoute.Post("/git/:prefix/sync", reqGrafanaAdmin, routing.Wrap(s.doGitSync))
+}
+
+func (s *standardStorageService) RegisterWebhookRoutes(route routing.RouteRegister) {
+	route.Post("/api/storage/git/:prefix/webhook", routing.Wrap(s.doGitWebhook))
 }
 
 func (s *standardStorageService) doWrite(c *contextmodel.ReqContext) response.Response {
@@ -282,3 +294,104 @@ func (s *standardStorageService) getConfig(c *contextmodel.ReqContext) response.
 	}
 	return response.JSON(200, roots)
 }
+
+func (s *standardStorageService) getGitSync(c *contextmodel.ReqContext) (*gitDashboardSync, response.Response) {
+	gs, ok := s.gitSyncs[web.Params(c.Req)[":prefix"]]
+	if !ok || gs.orgID != c.OrgID {
+		return nil, response.Error(404, "storage is not synced with git", nil)
+	}
+	return gs, nil
+}
+
+// getGitChanges lists the changes saved from the UI whose pull request is not merged yet. The changes of a dashboard
+// are listed to the users who can read it, the changes of all the dashboards to the Grafana admins only.
+func (s *standardStorageService) getGitChanges(c *contextmodel.ReqContext) response.Response {
+	gs, rsp := s.getGitSync(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	dashboardUID := c.Query("dashboardUID")
+	if dashboardUID == "" {
+		if !c.SignedInUser.IsGrafanaAdmin {
+			return response.Error(403, "dashboardUID is required", nil)
+		}
+	} else {
+		evaluator := ac.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboardUID))
+		canRead, err := s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator)
+		if err != nil {
+			return response.Error(500, "failed to check the dashboard permissions", err)
+		}
+		if !canRead {
+			return response.Error(403, "access denied to the dashboard", nil)
+		}
+	}
+
+	changes, err := gs.pendingChanges(c.Req.Context(), dashboardUID)
+	if err != nil {
+		return response.Error(500, "failed to list changes", err)
+	}
+	return response.JSON(200, changes)
+}
+
+func (s *standardStorageService) getGitSyncStatus(c *contextmodel.ReqContext) response.Response {
+	gs, rsp := s.getGitSync(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	changes, err := gs.listChanges(c.Req.Context())
+	if err != nil {
+		return response.Error(500, "failed to list changes", err)
+	}
+	return response.JSON(200, map[string]any{
+		"status":  gs.lastStatus(),
+		"changes": changes,
+	})
+}
+
+func (s *standardStorageService) doGitSync(c *contextmodel.ReqContext) response.Response {
+	gs, rsp := s.getGitSync(c)
+	if rsp != nil {
+		return rsp
+	}
+
+	status, err := gs.sync(c.Req.Context())
+	if errors.Is(err, ErrGitSyncedByAnotherServer) {
+		return response.Error(409, err.Error(), nil)
+	}
+	if err != nil {
+		return response.Error(500, "failed to sync: "+err.Error(), err)
+	}
+	return response.JSON(200, status)
+}
+
+// doGitWebhook syncs the dashboards when the repository notifies a push or a pull request update
+func (s *standardStorageService) doGitWebhook(c *contextmodel.ReqContext) response.Response {
+	gs, ok := s.gitSyncs[web.Params(c.Req)[":prefix"]]
+	if !ok {
+		return response.Error(404, "storage is not synced with git", nil)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Req.Body, MAX_UPLOAD_SIZE))
+	if err != nil {
+		return response.Error(400, "failed to read payload", err)
+	}
+	if err := gs.verifyWebhook(c.Req.Header, body); err != nil {
+		return response.Error(401, err.Error(), nil)
+	}
+
+	// The sender of the webhook does not wait for the sync
+	go func() {
+		_, err := gs.sync(context.Background())
+		if errors.Is(err, ErrGitSyncedByAnotherServer) {
+			err = gs.pull()
+		}
+		if err != nil {
+			gs.log.Warn("Failed to sync dashboards after webhook", "error", err)
+		}
+	}()
+	return response.JSON(202, map[string]any{
+		"message": "Sync started",
+	})
+}
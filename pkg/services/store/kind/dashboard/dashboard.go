@@ -367,10 +367,22 @@ func readpanelInfo(iter *jsoniter.Iterator, lookup DatasourceLookup) panelInfo {
 
 	targets := newTargetInfo(lookup)
 
+	// The datasource of the panel may be after the targets.
+	var panelDatasource *DataSourceRef
+	var queries []string
+	var queryDatasources []*DataSourceRef
+	addTarget := func() {
+		text, ref := targets.addTarget(iter)
+		if text != "" {
+			queries = append(queries, text)
+			queryDatasources = append(queryDatasources, ref)
+		}
+	}
+
 	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
 		if iter.WhatIsNext() == jsoniter.NilValue {
 			if l1Field == "datasource" {
-				targets.addDatasource(iter)
+				panelDatasource = targets.addDatasource(iter)
 				continue
 			}
 
@@ -401,17 +413,17 @@ func readpanelInfo(iter *jsoniter.Iterator, lookup DatasourceLookup) panelInfo {
 			panel.LibraryPanel = v["uid"]
 
 		case "datasource":
-			targets.addDatasource(iter)
+			panelDatasource = targets.addDatasource(iter)
 
 		case "targets":
 			switch iter.WhatIsNext() {
 			case jsoniter.ArrayValue:
 				for iter.ReadArray() {
-					targets.addTarget(iter)
+					addTarget()
 				}
 			case jsoniter.ObjectValue:
 				for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
-					targets.addTarget(iter)
+					addTarget()
 				}
 			default:
 				iter.Skip()
@@ -451,5 +463,15 @@ func readpanelInfo(iter *jsoniter.Iterator, lookup DatasourceLookup) panelInfo {
 
 	panel.Datasource = targets.GetDatasourceInfo()
 
+	for i, text := range queries {
+		query := PanelQuery{Text: text}
+		if ref := queryDatasources[i]; ref != nil && ref.Type != "" {
+			query.DatasourceType = ref.Type
+		} else if panelDatasource != nil {
+			query.DatasourceType = panelDatasource.Type
+		}
+		panel.Queries = append(panel.Queries, query)
+	}
+
 	return panel
 }
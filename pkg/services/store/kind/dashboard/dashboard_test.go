@@ -44,6 +44,24 @@ func dsLookupForTests() DatasourceLookup {
 			Name:      "SQLite Grafana2",
 			IsDefault: false,
 		},
+		{
+			UID:       "prometheus-1",
+			Type:      "prometheus",
+			Name:      "Prometheus",
+			IsDefault: false,
+		},
+		{
+			UID:       "loki-1",
+			Type:      "loki",
+			Name:      "Loki",
+			IsDefault: false,
+		},
+		{
+			UID:       "mysql-1",
+			Type:      "mysql",
+			Name:      "MySQL",
+			IsDefault: false,
+		},
 		{
 			UID:       "default.uid",
 			Type:      "default.type",
@@ -70,6 +88,7 @@ func TestReadDashboard(t *testing.T) {
 		"mixed-datasource-with-variable",
 		"special-datasource-types",
 		"panels-without-datasources",
+		"panel-queries",
 	}
 
 	devdash := "../../../../../devenv/dev-dashboards/"
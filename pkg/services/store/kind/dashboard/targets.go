@@ -1,6 +1,8 @@
 package dashboard
 
 import (
+	"sort"
+
 	jsoniter "github.com/json-iterator/go"
 )
 
@@ -27,7 +29,8 @@ func (s *targetInfo) GetDatasourceInfo() []DataSourceRef {
 }
 
 // the node will either be string (name|uid) OR ref
-func (s *targetInfo) addDatasource(iter *jsoniter.Iterator) {
+// returns the reference, resolved if the datasource exists, or nil
+func (s *targetInfo) addDatasource(iter *jsoniter.Iterator) *DataSourceRef {
 	switch iter.WhatIsNext() {
 	case jsoniter.StringValue:
 		key := iter.ReadString()
@@ -36,28 +39,38 @@ func (s *targetInfo) addDatasource(iter *jsoniter.Iterator) {
 		if !isVariableRef(dsRef.UID) && !isSpecialDatasource(dsRef.UID) {
 			ds := s.lookup.ByRef(dsRef)
 			s.addRef(ds)
-		} else {
-			s.addRef(dsRef)
+			return ds
 		}
+		s.addRef(dsRef)
+		return dsRef
 
 	case jsoniter.NilValue:
-		s.addRef(s.lookup.ByRef(nil))
+		ds := s.lookup.ByRef(nil)
+		s.addRef(ds)
 		iter.Skip()
+		return ds
 
 	case jsoniter.ObjectValue:
 		ref := &DataSourceRef{}
 		iter.ReadVal(ref)
 
 		if !isVariableRef(ref.UID) && !isSpecialDatasource(ref.UID) {
-			s.addRef(s.lookup.ByRef(ref))
-		} else {
-			s.addRef(ref)
+			ds := s.lookup.ByRef(ref)
+			s.addRef(ds)
+			if ds == nil {
+				// keep the type of unknown datasources, such as expressions
+				return ref
+			}
+			return ds
 		}
+		s.addRef(ref)
+		return ref
 
 	default:
 		v := iter.Read()
 		logf("[Panel.datasource.unknown] %v\n", v)
 	}
+	return nil
 }
 
 func (s *targetInfo) addRef(ref *DataSourceRef) {
@@ -66,20 +79,57 @@ func (s *targetInfo) addRef(ref *DataSourceRef) {
 	}
 }
 
-func (s *targetInfo) addTarget(iter *jsoniter.Iterator) {
+// queryTextFields are the target fields holding the query text of the common datasources, such as the PromQL
+// expression of Prometheus, or the raw SQL of the SQL datasources.
+var queryTextFields = map[string]bool{
+	"expr":       true, // Prometheus, Loki
+	"rawSql":     true, // MySQL, PostgreSQL, Microsoft SQL Server
+	"target":     true, // Graphite
+	"query":      true, // InfluxDB, Elasticsearch and others
+	"expression": true, // Server side expressions
+}
+
+// QueryText returns the query text of a target decoded from JSON, such as the model of an alert rule query,
+// or an empty string. The fields are read in alphabetical order when several of them are set.
+func QueryText(target map[string]any) string {
+	fields := make([]string, 0, len(target))
+	for field := range target {
+		if queryTextFields[field] {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		if v, ok := target[field].(string); ok && v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// addTarget returns the query text of the target, and its datasource if it overrides the panel datasource.
+func (s *targetInfo) addTarget(iter *jsoniter.Iterator) (string, *DataSourceRef) {
+	var text string
+	var ref *DataSourceRef
 	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
-		switch l1Field {
-		case "datasource":
-			s.addDatasource(iter)
+		switch {
+		case l1Field == "datasource":
+			ref = s.addDatasource(iter)
 
-		case "refId":
+		case l1Field == "refId":
 			iter.Skip()
 
+		case queryTextFields[l1Field] && iter.WhatIsNext() == jsoniter.StringValue:
+			if v := iter.ReadString(); text == "" {
+				text = v
+			}
+
 		default:
 			v := iter.Read()
 			logf("[Panel.TARGET] %s=%v\n", l1Field, v)
 		}
 	}
+	return text, ref
 }
 
 func (s *targetInfo) addPanel(panel panelInfo) {
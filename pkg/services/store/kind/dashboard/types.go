@@ -9,10 +9,17 @@ type panelInfo struct {
 	LibraryPanel  string          `json:"libraryPanel,omitempty"` // UID of referenced library panel
 	Datasource    []DataSourceRef `json:"datasource,omitempty"`   // UIDs
 	Transformer   []string        `json:"transformer,omitempty"`  // ids of the transformation steps
+	Queries       []PanelQuery    `json:"queries,omitempty"`      // text of the targets
 	// Rows define panels as sub objects
 	Collapsed []panelInfo `json:"collapsed,omitempty"`
 }
 
+// PanelQuery is the text of a panel target, such as a PromQL expression, and the type of its datasource.
+type PanelQuery struct {
+	DatasourceType string `json:"datasourceType,omitempty"`
+	Text           string `json:"text"`
+}
+
 type dashboardInfo struct {
 	UID           string          `json:"uid,omitempty"`
 	ID            int64           `json:"id,omitempty"` // internal ID
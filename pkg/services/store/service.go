@@ -8,13 +8,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/registry"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -63,6 +67,9 @@ type StorageService interface {
 	// Register the HTTP
 	RegisterHTTPRoutes(routing.RouteRegister)
 
+	// Register the HTTP routes of the git webhooks, which are authenticated with the secret of the webhook
+	RegisterWebhookRoutes(routing.RouteRegister)
+
 	// List folder contents
 	List(ctx context.Context, user *user.SignedInUser, path string) (*StorageListFrame, error)
 
@@ -90,6 +97,9 @@ type standardStorageService struct {
 	authService  storageAuthService
 	quotaService quota.Service
 	systemUsers  SystemUsersFilterProvider
+	gitSyncs     map[string]*gitDashboardSync // by prefix
+
+	accessControl ac.AccessControl
 }
 
 func ProvideService(
@@ -98,6 +108,10 @@ func ProvideService(
 	cfg *setting.Cfg,
 	quotaService quota.Service,
 	systemUsersService SystemUsers,
+	dashboardProvisioningService dashboards.DashboardProvisioningService,
+	kvStore kvstore.KVStore,
+	serverLock *serverlock.ServerLockService,
+	accessControl ac.AccessControl,
 ) (StorageService, error) {
 	settings, err := LoadStorageConfig(cfg, features)
 	if err != nil {
@@ -150,6 +164,7 @@ func ProvideService(
 		}
 	}
 
+	gitSyncs := make(map[string]*gitDashboardSync)
 	for _, root := range settings.Roots {
 		if root.Prefix == "" {
 			grafanaStorageLogger.Warn("Invalid root configuration", "cfg", root)
@@ -164,6 +179,10 @@ func ProvideService(
 		}
 		if s != nil {
 			globalRoots = append(globalRoots, s)
+
+			if g, ok := s.(*rootStorageGit); ok && root.Git != nil && root.Git.SyncDashboards && g.meta.Ready {
+				gitSyncs[root.Prefix] = newGitDashboardSync(g, dashboardProvisioningService, kvStore, serverLock)
+			}
 		}
 	}
 
@@ -259,6 +278,8 @@ func ProvideService(
 	s := newStandardStorageService(sql, globalRoots, initializeOrgStorages, authService, cfg, systemUsersService)
 	s.quotaService = quotaService
 	s.cfg = settings
+	s.gitSyncs = gitSyncs
+	s.accessControl = accessControl
 
 	defaultLimits, err := readQuotaConfig(cfg)
 	if err != nil {
@@ -329,6 +350,16 @@ func newStandardStorageService(
 
 func (s *standardStorageService) Run(ctx context.Context) error {
 	grafanaStorageLogger.Info("Storage starting")
+
+	var wg sync.WaitGroup
+	for _, gs := range s.gitSyncs {
+		wg.Add(1)
+		go func(gs *gitDashboardSync) {
+			defer wg.Done()
+			gs.run(ctx)
+		}(gs)
+	}
+	wg.Wait()
 	return nil
 }
 
@@ -2,6 +2,7 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -10,12 +11,10 @@ import (
 	"time"
 
 	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"gocloud.dev/blob"
 
 	"github.com/grafana/grafana/pkg/infra/filestorage"
-	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -28,7 +27,8 @@ type rootStorageGit struct {
 	repo     *git.Repository
 	root     string // repostitory root
 
-	github *githubHelper
+	remote gitRemote
+	sync   *gitDashboardSync // when the dashboards are synced
 	meta   RootStorageMeta
 	store  filestorage.FileStorage
 }
@@ -130,35 +130,33 @@ func newGitStorage(meta RootStorageMeta, scfg RootStorageConfig, localWorkCache
 				meta.Ready = true // exists!
 				s.root = p
 
-				token := cfg.AccessToken
-				if strings.HasPrefix(token, "$") {
-					token = os.Getenv(token[1:])
-					if token == "" {
-						meta.Notice = append(meta.Notice, data.Notice{
-							Severity: data.NoticeSeverityError,
-							Text:     "Unable to find token environment variable: " + cfg.AccessToken,
-						})
-					}
+				token, ok := lookupConfigSecret(cfg.AccessToken)
+				if !ok {
+					meta.Notice = append(meta.Notice, data.Notice{
+						Severity: data.NoticeSeverityError,
+						Text:     "Unable to find token environment variable: " + cfg.AccessToken,
+					})
 				}
 
-				if token != "" {
-					s.github, err = newGithubHelper(context.Background(), cfg.Remote, token)
+				if !isGithubRemote(cfg.Remote) {
+					s.remote = newPlainGitRemote(cfg.Remote, token, repo)
+				} else if token != "" {
+					github, err := newGithubHelper(context.Background(), cfg.Remote, token)
 					if err != nil {
 						meta.Notice = append(meta.Notice, data.Notice{
 							Severity: data.NoticeSeverityError,
 							Text:     "error creating github client: " + err.Error(),
 						})
-						s.github = nil
 					} else {
-						ghrepo, _, err := s.github.getRepo(context.Background())
+						ghrepo, _, err := github.getRepo(context.Background())
 						if err != nil {
 							meta.Notice = append(meta.Notice, data.Notice{
 								Severity: data.NoticeSeverityError,
 								Text:     err.Error(),
 							})
-							s.github = nil
 						} else {
 							grafanaStorageLogger.Info("Default branch", "branch", *ghrepo.DefaultBranch)
+							s.remote = github
 						}
 					}
 				}
@@ -174,7 +172,7 @@ func newGitStorage(meta RootStorageMeta, scfg RootStorageConfig, localWorkCache
 					Severity: data.NoticeSeverityError,
 					Text:     "unable to pull: " + err.Error(),
 				})
-			} else if cfg.PullInterval != "" {
+			} else if cfg.PullInterval != "" && !cfg.SyncDashboards { // the sync polls the synced dashboards
 				t, err := time.ParseDuration(cfg.PullInterval)
 				if err != nil {
 					meta.Notice = append(meta.Notice, data.Notice{
@@ -226,9 +224,11 @@ func (s *rootStorageGit) Pull() error {
 }
 
 func (s *rootStorageGit) Write(ctx context.Context, cmd *WriteValueRequest) (*WriteValueResponse, error) {
-	if s.github == nil {
-		return nil, fmt.Errorf("github client not initialized")
+	if s.remote == nil {
+		return nil, fmt.Errorf("git remote not initialized")
 	}
+	storagePath := cmd.Path
+
 	// Write to the correct subfolder
 	if s.settings.Root != "" {
 		cmd.Path = s.settings.Root + cmd.Path
@@ -236,7 +236,7 @@ func (s *rootStorageGit) Write(ctx context.Context, cmd *WriteValueRequest) (*Wr
 
 	if cmd.Workflow == WriteValueWorkflow_PR {
 		prcmd := makePRCommand{
-			baseBranch: s.settings.Branch,
+			baseBranch: s.baseBranch(),
 			headBranch: fmt.Sprintf("grafana_ui_%d", time.Now().UnixMilli()),
 			title:      cmd.Title,
 			body:       cmd.Message,
@@ -245,17 +245,10 @@ func (s *rootStorageGit) Write(ctx context.Context, cmd *WriteValueRequest) (*Wr
 			Branch: prcmd.headBranch,
 		}
 
-		ref, _, err := s.github.createRef(ctx, prcmd.baseBranch, prcmd.headBranch)
+		hash, err := s.remote.commitFile(ctx, prcmd.baseBranch, prcmd.headBranch, cmd)
 		if err != nil {
 			res.Code = 500
-			res.Message = "unable to create branch"
-			return res, nil
-		}
-
-		err = s.github.pushCommit(ctx, ref, cmd)
-		if err != nil {
-			res.Code = 500
-			res.Message = fmt.Sprintf("error creating commit: %s", err.Error())
+			res.Message = err.Error()
 			return res, nil
 		}
 
@@ -266,7 +259,7 @@ func (s *rootStorageGit) Write(ctx context.Context, cmd *WriteValueRequest) (*Wr
 			prcmd.body = "Dashboard save: " + time.Now().String()
 		}
 
-		pr, _, err := s.github.createPR(ctx, prcmd)
+		number, url, err := s.remote.openPullRequest(ctx, prcmd)
 		if err != nil {
 			res.Code = 500
 			res.Message = "error creating PR: " + err.Error()
@@ -274,96 +267,70 @@ func (s *rootStorageGit) Write(ctx context.Context, cmd *WriteValueRequest) (*Wr
 		}
 
 		res.Code = 200
-		res.URL = pr.GetHTMLURL()
+		res.URL = url
 		res.Pending = true
-		res.Hash = *ref.Object.SHA
-		res.Branch = prcmd.headBranch
-		return res, nil
-	}
-
-	// Push to remote branch (save)
-	if cmd.Workflow == WriteValueWorkflow_Push || true {
-		res := &WriteValueResponse{
-			Branch: s.settings.Branch,
-		}
-		ref, _, err := s.github.getRef(ctx, s.settings.Branch)
-		if err != nil {
-			res.Code = 500
-			res.Message = "unable to create branch"
-			return res, nil
-		}
-		err = s.github.pushCommit(ctx, ref, cmd)
-		if err != nil {
-			res.Code = 500
-			res.Message = "error creating commit"
-			return res, nil
-		}
-		ref, _, _ = s.github.getRef(ctx, s.settings.Branch)
-		if ref != nil {
-			res.Hash = *ref.Object.SHA
-			res.URL = ref.GetURL()
-		}
-
-		err = s.Pull()
-		if err != nil {
-			res.Message = "error pulling: " + err.Error()
+		res.Hash = hash
+
+		if s.sync != nil {
+			now := time.Now()
+			err = s.sync.trackChange(ctx, &GitChange{
+				Path:         storagePath,
+				DashboardUID: getDashboardUID(cmd.Body),
+				Title:        prcmd.title,
+				Base:         prcmd.baseBranch,
+				Branch:       prcmd.headBranch,
+				Hash:         hash,
+				Number:       number,
+				URL:          url,
+				Status:       GitPullRequestOpen,
+				Author:       gitCommitSignature(cmd.User).Name,
+				Created:      now,
+				Updated:      now,
+			})
+			if err != nil {
+				grafanaStorageLogger.Warn("Failed to track pull request", "branch", prcmd.headBranch, "error", err)
+			}
 		}
-
-		res.Code = 200
 		return res, nil
 	}
 
-	rel := cmd.Path
-	if s.meta.Config.Git.Root != "" {
-		rel = filepath.Join(s.meta.Config.Git.Root, cmd.Path)
+	// Push to remote branch (save)
+	res := &WriteValueResponse{
+		Branch: s.baseBranch(),
 	}
-
-	fpath := filepath.Join(s.root, rel)
-	err := os.WriteFile(fpath, cmd.Body, 0644)
+	hash, err := s.remote.commitFile(ctx, res.Branch, res.Branch, cmd)
 	if err != nil {
-		return nil, err
+		res.Code = 500
+		res.Message = err.Error()
+		return res, nil
 	}
+	res.Hash = hash
 
-	w, err := s.repo.Worktree()
-	if err != nil {
-		return nil, err
+	if s.sync != nil {
+		_, err = s.sync.sync(ctx)
+	} else {
+		err = s.Sync()
 	}
-
-	// The file we just wrote
-	_, err = w.Add(rel)
 	if err != nil {
-		return nil, err
+		res.Message = "error pulling: " + err.Error()
 	}
 
-	msg := cmd.Message
-	if msg == "" {
-		msg = "changes from grafana ui"
-	}
-	usr := cmd.User
-	if usr == nil {
-		usr = &user.SignedInUser{}
-	}
+	res.Code = 200
+	return res, nil
+}
 
-	hash, err := w.Commit(msg, &git.CommitOptions{
-		Author: &object.Signature{
-			Name:  firstRealString(usr.Name, usr.Login, usr.Email, "?"),
-			Email: firstRealString(usr.Email, usr.Login, usr.Name, "?"),
-			When:  time.Now(),
-		},
-	})
-	if err != nil {
-		return nil, err
+// baseBranch returns the configured branch, or the branch of the clone
+func (s *rootStorageGit) baseBranch() string {
+	if s.settings.Branch != "" {
+		return s.settings.Branch
 	}
-
-	grafanaStorageLogger.Info("Made commit", "hash", hash)
-	// err = s.repo.Push(&git.PushOptions{
-	// 	InsecureSkipTLS: true,
-	// })
-
-	return &WriteValueResponse{
-		Hash:    hash.String(),
-		Message: "made commit",
-	}, nil
+	if s.repo != nil {
+		head, err := s.repo.Head()
+		if err == nil && head.Name().IsBranch() {
+			return head.Name().Short()
+		}
+	}
+	return "main"
 }
 
 func (s *rootStorageGit) Sync() error {
@@ -377,6 +344,23 @@ func (s *rootStorageGit) Sync() error {
 	return err
 }
 
+// lookupConfigSecret returns the secret of the configuration, or the value of the environment variable when it starts with $
+func lookupConfigSecret(v string) (string, bool) {
+	if strings.HasPrefix(v, "$") {
+		v = os.Getenv(v[1:])
+		return v, v != ""
+	}
+	return v, true
+}
+
+func getDashboardUID(body []byte) string {
+	dash := struct {
+		UID string `json:"uid"`
+	}{}
+	_ = json.Unmarshal(body, &dash)
+	return dash.UID
+}
+
 func firstRealString(vals ...string) string {
 	for _, v := range vals {
 		if v != "" {
@@ -389,7 +389,8 @@ type Cfg struct {
 	GrafanaJavascriptAgent GrafanaJavascriptAgent
 
 	// Data sources
-	DataSourceLimit int
+	DataSourceLimit                          int
+	DataSourceProvisioningDriftCheckInterval time.Duration
 
 	// SQL Data sources
 	SqlDatasourceMaxOpenConnsDefault    int
@@ -521,6 +522,10 @@ type Cfg struct {
 
 	Search SearchSettings
 
+	DataSourceHealth DataSourceHealthSettings
+
+	DashboardReports DashboardReportsSettings
+
 	SecureSocksDSProxy SecureSocksDSProxySettings
 
 	// SAML Auth
@@ -1184,7 +1189,9 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 	cfg.readSqlDataSourceSettings()
 
 	cfg.Storage = readStorageSettings(iniFile)
-	cfg.Search = readSearchSettings(iniFile)
+	cfg.Search = readSearchSettings(iniFile, cfg.DataPath)
+	cfg.DataSourceHealth = readDataSourceHealthSettings(iniFile)
+	cfg.DashboardReports = readDashboardReportsSettings(iniFile)
 
 	cfg.SecureSocksDSProxy, err = readSecureSocksDSProxySettings(iniFile)
 	if err != nil {
@@ -1956,6 +1963,7 @@ func (cfg *Cfg) GetContentDeliveryURL(prefix string) string {
 func (cfg *Cfg) readDataSourcesSettings() {
 	datasources := cfg.Raw.Section("datasources")
 	cfg.DataSourceLimit = datasources.Key("datasource_limit").MustInt(5000)
+	cfg.DataSourceProvisioningDriftCheckInterval = datasources.Key("provisioning_drift_check_interval").MustDuration(0)
 }
 
 func (cfg *Cfg) readSqlDataSourceSettings() {
@@ -0,0 +1,34 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type DashboardReportsSettings struct {
+	// Enabled turns on the scheduler sending dashboard reports by email.
+	Enabled bool
+	// CheckInterval is the time between two checks for reports that are due.
+	CheckInterval time.Duration
+	// RenderTimeout is the maximum time the rendering of a single panel may take.
+	RenderTimeout time.Duration
+	// Width and Height are the size in pixels of the rendered panels.
+	Width  int
+	Height int
+	// HistoryRetention is how long the history of sent reports is kept.
+	HistoryRetention time.Duration
+}
+
+func readDashboardReportsSettings(iniFile *ini.File) DashboardReportsSettings {
+	s := DashboardReportsSettings{}
+
+	section := iniFile.Section("dashboard_reports")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.CheckInterval = section.Key("check_interval").MustDuration(time.Minute)
+	s.RenderTimeout = section.Key("render_timeout").MustDuration(time.Minute)
+	s.Width = section.Key("image_width").MustInt(1000)
+	s.Height = section.Key("image_height").MustInt(500)
+	s.HistoryRetention = section.Key("history_retention").MustDuration(30 * 24 * time.Hour)
+	return s
+}
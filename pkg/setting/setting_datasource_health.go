@@ -0,0 +1,36 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type DataSourceHealthSettings struct {
+	// Enabled turns on periodic health checks for all data sources.
+	Enabled bool
+	// Interval is the time between two health checks of the same data source.
+	Interval time.Duration
+	// Timeout is the maximum time a single health check may take.
+	Timeout time.Duration
+	// MaxConcurrentChecks is the number of data sources checked at the same time.
+	MaxConcurrentChecks int
+	// Retention is how long health check results are kept.
+	Retention time.Duration
+	// AlertAfter is how long a data source must be unhealthy before an alert is sent
+	// to the Grafana Alertmanager of its organization. Zero disables alerts.
+	AlertAfter time.Duration
+}
+
+func readDataSourceHealthSettings(iniFile *ini.File) DataSourceHealthSettings {
+	s := DataSourceHealthSettings{}
+
+	section := iniFile.Section("datasource_health")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.Interval = section.Key("check_interval").MustDuration(5 * time.Minute)
+	s.Timeout = section.Key("check_timeout").MustDuration(30 * time.Second)
+	s.MaxConcurrentChecks = section.Key("max_concurrent_checks").MustInt(10)
+	s.Retention = section.Key("retention").MustDuration(7 * 24 * time.Hour)
+	s.AlertAfter = section.Key("alert_after").MustDuration(0)
+	return s
+}
@@ -10,14 +10,21 @@ type SearchSettings struct {
 	FullReindexInterval       time.Duration
 	IndexUpdateInterval       time.Duration
 	DashboardLoadingBatchSize int
+	// IndexPath is the directory where the search index is persisted, the index is kept in memory only if empty.
+	IndexPath                string
+	ConsistencyCheckInterval time.Duration
 }
 
-func readSearchSettings(iniFile *ini.File) SearchSettings {
+func readSearchSettings(iniFile *ini.File, dataPath string) SearchSettings {
 	s := SearchSettings{}
 
 	searchSection := iniFile.Section("search")
 	s.DashboardLoadingBatchSize = searchSection.Key("dashboard_loading_batch_size").MustInt(200)
 	s.FullReindexInterval = searchSection.Key("full_reindex_interval").MustDuration(5 * time.Minute)
 	s.IndexUpdateInterval = searchSection.Key("index_update_interval").MustDuration(10 * time.Second)
+	s.ConsistencyCheckInterval = searchSection.Key("consistency_check_interval").MustDuration(10 * time.Minute)
+	if indexPath := searchSection.Key("index_path").String(); indexPath != "" {
+		s.IndexPath = makeAbsolute(indexPath, dataPath)
+	}
 	return s
 }
@@ -0,0 +1,223 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Query languages returning tabular results
+const (
+	LanguageSQL  = "sql"
+	LanguagePPL  = "ppl"
+	LanguageESQL = "esql"
+)
+
+// TabularRequest represents a SQL, PPL or ES|QL query
+type TabularRequest struct {
+	Language string
+	Query    string
+	// Filter is an optional query DSL filter applied to the query. It is not supported by PPL.
+	Filter map[string]any
+	// FetchSize is the number of rows of each page of the results of SQL queries.
+	FetchSize int
+	// MaxRows limits the number of rows read by following the cursor of SQL queries, 0 reads all the pages.
+	MaxRows int
+}
+
+// TabularColumn represents a column of a tabular response
+type TabularColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// TabularResponse represents the result of a SQL, PPL or ES|QL query
+type TabularResponse struct {
+	Columns []TabularColumn
+	Rows    [][]any
+	// Truncated is true when the rows of a SQL query were limited to MaxRows
+	Truncated bool
+}
+
+// tabularResponse covers the response formats of the different endpoints:
+// _sql returns columns and rows, and a cursor when there are more pages, _query
+// returns columns and values and OpenSearch's _plugins/_ppl returns schema and datarows.
+type tabularResponse struct {
+	Columns  []TabularColumn `json:"columns"`
+	Rows     [][]any         `json:"rows"`
+	Values   [][]any         `json:"values"`
+	Schema   []TabularColumn `json:"schema"`
+	DataRows [][]any         `json:"datarows"`
+	Cursor   string          `json:"cursor"`
+	Error    json.RawMessage `json:"error"`
+}
+
+func (c *baseClientImpl) ExecuteTabularQuery(r *TabularRequest) (*TabularResponse, error) {
+	uriPath, uriQuery, body, err := encodeTabularRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	_, span := c.tracer.Start(c.ctx, "datasource.elasticsearch.queryData.executeTabularQuery")
+	span.SetAttributes("language", r.Language, attribute.Key("language").String(r.Language))
+	span.SetAttributes("url", c.ds.URL, attribute.Key("url").String(c.ds.URL))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	tr, err := c.executeTabularRequest(r.Language, uriPath, uriQuery, body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TabularResponse{Columns: tr.Columns, Rows: tr.Rows}
+	switch {
+	case len(tr.Schema) > 0:
+		result.Columns, result.Rows = tr.Schema, tr.DataRows
+	case tr.Values != nil:
+		result.Rows = tr.Values
+	}
+
+	// The next pages of SQL queries only have rows, the cursor is closed by Elasticsearch after the last page
+	for cursor := tr.Cursor; cursor != ""; cursor = tr.Cursor {
+		if r.MaxRows > 0 && len(result.Rows) >= r.MaxRows {
+			result.Truncated = true
+			c.closeTabularCursor(cursor)
+			break
+		}
+
+		if body, err = json.Marshal(map[string]any{"cursor": cursor}); err != nil {
+			return nil, err
+		}
+		if tr, err = c.executeTabularRequest(r.Language, uriPath, uriQuery, body); err != nil {
+			return nil, err
+		}
+		result.Rows = append(result.Rows, tr.Rows...)
+	}
+
+	if r.MaxRows > 0 && len(result.Rows) > r.MaxRows {
+		result.Rows = result.Rows[:r.MaxRows]
+		result.Truncated = true
+	}
+	return result, nil
+}
+
+func (c *baseClientImpl) executeTabularRequest(language, uriPath, uriQuery string, body []byte) (*tabularResponse, error) {
+	start := time.Now()
+	res, err := c.executeRequest(http.MethodPost, uriPath, uriQuery, "application/json", body)
+	if err != nil {
+		status := "error"
+		if errors.Is(err, context.Canceled) {
+			status = "cancelled"
+		}
+		c.logger.Error("Error received from Elasticsearch", "error", err, "status", status, "duration", time.Since(start), "stage", StageDatabaseRequest, "language", language)
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	c.logger.Info("Response received from Elasticsearch", "status", "ok", "statusCode", res.StatusCode, "contentLength", res.ContentLength, "duration", time.Since(start), "stage", StageDatabaseRequest, "language", language)
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var tr tabularResponse
+	if err := json.Unmarshal(resBody, &tr); err != nil {
+		if res.StatusCode/100 != 2 {
+			return nil, fmt.Errorf("request failed, status: %s", res.Status)
+		}
+		c.logger.Error("Failed to decode response from Elasticsearch", "error", err, "stage", StageParseResponse)
+		return nil, err
+	}
+
+	if res.StatusCode/100 != 2 || len(tr.Error) > 0 {
+		return nil, errors.New(tabularErrorReason(tr.Error, res.Status))
+	}
+	return &tr, nil
+}
+
+// closeTabularCursor releases the resources of a SQL cursor whose last pages are not read
+func (c *baseClientImpl) closeTabularCursor(cursor string) {
+	body, err := json.Marshal(map[string]any{"cursor": cursor})
+	if err != nil {
+		c.logger.Warn("Failed to close the SQL cursor", "error", err)
+		return
+	}
+
+	res, err := c.executeRequest(http.MethodPost, "_sql/close", "", "application/json", body)
+	if err != nil {
+		c.logger.Warn("Failed to close the SQL cursor", "error", err)
+		return
+	}
+	if err := res.Body.Close(); err != nil {
+		c.logger.Warn("Failed to close response body", "error", err)
+	}
+	if res.StatusCode/100 != 2 {
+		c.logger.Warn("Failed to close the SQL cursor", "status", res.Status)
+	}
+}
+
+func encodeTabularRequest(r *TabularRequest) (string, string, []byte, error) {
+	body := map[string]any{"query": r.Query}
+
+	var uriPath, uriQuery string
+	switch r.Language {
+	case LanguageSQL:
+		uriPath, uriQuery = "_sql", "format=json"
+		if r.FetchSize > 0 {
+			body["fetch_size"] = r.FetchSize
+		}
+	case LanguageESQL:
+		uriPath, uriQuery = "_query", "format=json"
+	case LanguagePPL:
+		uriPath = "_plugins/_ppl"
+	default:
+		return "", "", nil, fmt.Errorf("unsupported query language %q", r.Language)
+	}
+
+	if r.Filter != nil && r.Language != LanguagePPL {
+		body["filter"] = r.Filter
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return uriPath, uriQuery, b, nil
+}
+
+// tabularErrorReason extracts the error message from the error object returned
+// by Elasticsearch ({"reason": ...}) or OpenSearch ({"reason": ..., "details": ...}).
+func tabularErrorReason(raw json.RawMessage, status string) string {
+	var e struct {
+		Reason  string `json:"reason"`
+		Details string `json:"details"`
+	}
+	if len(raw) > 0 && json.Unmarshal(raw, &e) == nil && e.Reason != "" {
+		if e.Details != "" {
+			return e.Reason + ": " + e.Details
+		}
+		return e.Reason
+	}
+	var s string
+	if len(raw) > 0 && json.Unmarshal(raw, &s) == nil && s != "" {
+		return s
+	}
+	return fmt.Sprintf("request failed, status: %s", status)
+}
@@ -0,0 +1,182 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestClient_ExecuteTabularQuery(t *testing.T) {
+	filter := map[string]any{"range": map[string]any{"@timestamp": map[string]any{"gte": 1, "lte": 2}}}
+
+	tt := []struct {
+		name         string
+		request      *TabularRequest
+		statusCode   int
+		responseBody string
+		expectedPath string
+		expectedBody string
+		expected     *TabularResponse
+		expectedErr  string
+	}{
+		{
+			name:         "SQL query",
+			request:      &TabularRequest{Language: LanguageSQL, Query: "SELECT host FROM logs", Filter: filter, FetchSize: 500},
+			statusCode:   200,
+			responseBody: `{"columns": [{"name": "host", "type": "keyword"}], "rows": [["a"], ["b"]]}`,
+			expectedPath: "/_sql?format=json",
+			expectedBody: `{"query": "SELECT host FROM logs", "fetch_size": 500, "filter": {"range": {"@timestamp": {"gte": 1, "lte": 2}}}}`,
+			expected:     &TabularResponse{Columns: []TabularColumn{{Name: "host", Type: "keyword"}}, Rows: [][]any{{"a"}, {"b"}}},
+		},
+		{
+			name:         "ES|QL query",
+			request:      &TabularRequest{Language: LanguageESQL, Query: "FROM logs | STATS count()", Filter: filter},
+			statusCode:   200,
+			responseBody: `{"columns": [{"name": "count()", "type": "long"}], "values": [[3]]}`,
+			expectedPath: "/_query?format=json",
+			expectedBody: `{"query": "FROM logs | STATS count()", "filter": {"range": {"@timestamp": {"gte": 1, "lte": 2}}}}`,
+			expected:     &TabularResponse{Columns: []TabularColumn{{Name: "count()", Type: "long"}}, Rows: [][]any{{float64(3)}}},
+		},
+		{
+			name:         "PPL query ignores the filter",
+			request:      &TabularRequest{Language: LanguagePPL, Query: "source=logs | fields host", Filter: filter},
+			statusCode:   200,
+			responseBody: `{"schema": [{"name": "host", "type": "string"}], "datarows": [["a"]], "total": 1, "size": 1}`,
+			expectedPath: "/_plugins/_ppl",
+			expectedBody: `{"query": "source=logs | fields host"}`,
+			expected:     &TabularResponse{Columns: []TabularColumn{{Name: "host", Type: "string"}}, Rows: [][]any{{"a"}}},
+		},
+		{
+			name:         "Elasticsearch error",
+			request:      &TabularRequest{Language: LanguageSQL, Query: "SELECT"},
+			statusCode:   400,
+			responseBody: `{"error": {"type": "parsing_exception", "reason": "line 1:7: mismatched input '<EOF>'"}, "status": 400}`,
+			expectedPath: "/_sql?format=json",
+			expectedBody: `{"query": "SELECT"}`,
+			expectedErr:  "line 1:7: mismatched input '<EOF>'",
+		},
+		{
+			name:         "OpenSearch error",
+			request:      &TabularRequest{Language: LanguagePPL, Query: "source=missing"},
+			statusCode:   400,
+			responseBody: `{"error": {"reason": "Error occurred in OpenSearch engine", "details": "no such index [missing]"}, "status": 400}`,
+			expectedPath: "/_plugins/_ppl",
+			expectedBody: `{"query": "source=missing"}`,
+			expectedErr:  "Error occurred in OpenSearch engine: no such index [missing]",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			var request *http.Request
+			var requestBody []byte
+			ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				request = r
+				var err error
+				requestBody, err = io.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				rw.WriteHeader(tc.statusCode)
+				_, err = rw.Write([]byte(tc.responseBody))
+				require.NoError(t, err)
+			}))
+			t.Cleanup(ts.Close)
+
+			ds := DatasourceInfo{
+				URL:        ts.URL,
+				HTTPClient: ts.Client(),
+				Database:   "logs",
+			}
+			timeRange := backend.TimeRange{From: time.Now().Add(-time.Hour), To: time.Now()}
+			c, err := NewClient(context.Background(), &ds, timeRange, log.New("test", "test"), tracing.NewFakeTracer())
+			require.NoError(t, err)
+
+			res, err := c.ExecuteTabularQuery(tc.request)
+
+			require.NotNil(t, request)
+			assert.Equal(t, http.MethodPost, request.Method)
+			assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+			assert.Equal(t, tc.expectedPath, request.URL.RequestURI())
+			assert.JSONEq(t, tc.expectedBody, string(requestBody))
+
+			if tc.expectedErr != "" {
+				require.EqualError(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, res)
+		})
+	}
+
+	t.Run("SQL query follows the cursor", func(t *testing.T) {
+		var requestBodies []string
+		var closed string
+		ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			var page string
+			switch {
+			case r.URL.Path == "/_sql/close":
+				closed = string(body)
+				page = `{"succeeded": true}`
+			case len(requestBodies) == 0:
+				page = `{"columns": [{"name": "host", "type": "keyword"}], "rows": [["a"], ["b"]], "cursor": "c1"}`
+			case len(requestBodies) == 1:
+				page = `{"rows": [["c"], ["d"]], "cursor": "c2"}`
+			default:
+				page = `{"rows": [["e"]]}`
+			}
+			if r.URL.Path != "/_sql/close" {
+				requestBodies = append(requestBodies, string(body))
+			}
+			_, err = rw.Write([]byte(page))
+			require.NoError(t, err)
+		}))
+		t.Cleanup(ts.Close)
+
+		ds := DatasourceInfo{URL: ts.URL, HTTPClient: ts.Client(), Database: "logs"}
+		timeRange := backend.TimeRange{From: time.Now().Add(-time.Hour), To: time.Now()}
+		c, err := NewClient(context.Background(), &ds, timeRange, log.New("test", "test"), tracing.NewFakeTracer())
+		require.NoError(t, err)
+
+		res, err := c.ExecuteTabularQuery(&TabularRequest{Language: LanguageSQL, Query: "SELECT host FROM logs", FetchSize: 2})
+		require.NoError(t, err)
+		require.Equal(t, [][]any{{"a"}, {"b"}, {"c"}, {"d"}, {"e"}}, res.Rows)
+		require.False(t, res.Truncated)
+		require.Len(t, requestBodies, 3)
+		require.JSONEq(t, `{"cursor": "c1"}`, requestBodies[1])
+		require.JSONEq(t, `{"cursor": "c2"}`, requestBodies[2])
+		require.Empty(t, closed)
+
+		requestBodies = nil
+		res, err = c.ExecuteTabularQuery(&TabularRequest{Language: LanguageSQL, Query: "SELECT host FROM logs", FetchSize: 2, MaxRows: 3})
+		require.NoError(t, err)
+		require.Equal(t, [][]any{{"a"}, {"b"}, {"c"}}, res.Rows)
+		require.True(t, res.Truncated)
+		require.Len(t, requestBodies, 2)
+		require.JSONEq(t, `{"cursor": "c2"}`, closed)
+	})
+
+	t.Run("unsupported language", func(t *testing.T) {
+		_, _, _, err := encodeTabularRequest(&TabularRequest{Language: "kql", Query: "a"})
+		require.Error(t, err)
+	})
+
+	t.Run("request body is valid json", func(t *testing.T) {
+		_, _, body, err := encodeTabularRequest(&TabularRequest{Language: LanguageESQL, Query: `FROM "logs"`})
+		require.NoError(t, err)
+		require.True(t, json.Valid(body))
+	})
+}
@@ -48,10 +48,25 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return &backend.QueryDataResponse{}, err
 	}
 
-	ms := e.client.MultiSearch()
-
 	from := e.dataQueries[0].TimeRange.From.UnixNano() / int64(time.Millisecond)
 	to := e.dataQueries[0].TimeRange.To.UnixNano() / int64(time.Millisecond)
+
+	// SQL, PPL and ES|QL queries are sent to their own endpoints, one request per query
+	searchQueries := make([]*Query, 0, len(queries))
+	tabularResponses := backend.Responses{}
+	for _, q := range queries {
+		if isTabularQuery(q) {
+			tabularResponses[q.RefID] = e.executeTabularQuery(q, from, to)
+		} else {
+			searchQueries = append(searchQueries, q)
+		}
+	}
+	if len(searchQueries) == 0 {
+		return &backend.QueryDataResponse{Responses: tabularResponses}, nil
+	}
+	queries = searchQueries
+
+	ms := e.client.MultiSearch()
 	for _, q := range queries {
 		if err := e.processQuery(q, ms, from, to); err != nil {
 			mq, _ := json.Marshal(q)
@@ -73,7 +88,14 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return &backend.QueryDataResponse{}, err
 	}
 
-	return parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.logger, e.tracer)
+	result, err := parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.logger, e.tracer)
+	if err != nil {
+		return result, err
+	}
+	for refID, r := range tabularResponses {
+		result.Responses[refID] = r
+	}
+	return result, nil
 }
 
 func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64) error {
@@ -1764,6 +1764,9 @@ type fakeClient struct {
 	multiSearchError    error
 	builder             *es.MultiSearchRequestBuilder
 	multisearchRequests []*es.MultiSearchRequest
+	tabularResponse     *es.TabularResponse
+	tabularError        error
+	tabularRequests     []*es.TabularRequest
 }
 
 func newFakeClient() *fakeClient {
@@ -1789,6 +1792,11 @@ func (c *fakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSear
 	return c.multiSearchResponse, c.multiSearchError
 }
 
+func (c *fakeClient) ExecuteTabularQuery(r *es.TabularRequest) (*es.TabularResponse, error) {
+	c.tabularRequests = append(c.tabularRequests, r)
+	return c.tabularResponse, c.tabularError
+}
+
 func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	c.builder = es.NewMultiSearchRequestBuilder()
 	return c.builder
@@ -16,6 +16,10 @@ type Query struct {
 	IntervalMs    int64
 	RefID         string
 	MaxDataPoints int64
+	// QueryType is empty for Lucene queries, or the language of a tabular query (sql, ppl or esql)
+	QueryType string
+	// Format of tabular query results, either table or time_series
+	Format string
 }
 
 // BucketAgg represents a bucket aggregation of the time series query model of the datasource
@@ -102,5 +102,33 @@ func TestParseQuery(t *testing.T) {
 			require.Equal(t, q.BucketAggs[1].Settings.Get("min_doc_count").MustInt(), 0)
 			require.Equal(t, q.BucketAggs[1].Settings.Get("trimEdges").MustInt(), 0)
 		})
+
+		t.Run("Should be able to parse tabular query", func(t *testing.T) {
+			body := `{
+				"queryType": "esql",
+				"query": "FROM logs | STATS count() BY host",
+				"format": "time_series"
+			}`
+			dataQuery, err := newDataQuery(body)
+			require.NoError(t, err)
+			queries, err := parseQuery(dataQuery.Queries, log.New("test.logger"))
+			require.NoError(t, err)
+			require.Len(t, queries, 1)
+
+			q := queries[0]
+			require.Equal(t, "esql", q.QueryType)
+			require.Equal(t, "FROM logs | STATS count() BY host", q.RawQuery)
+			require.Equal(t, "time_series", q.Format)
+			require.Empty(t, q.Metrics)
+			require.Empty(t, q.BucketAggs)
+		})
+
+		t.Run("Should default tabular query format to table", func(t *testing.T) {
+			dataQuery, err := newDataQuery(`{"queryType": "sql", "query": "SELECT 1"}`)
+			require.NoError(t, err)
+			queries, err := parseQuery(dataQuery.Queries, log.New("test.logger"))
+			require.NoError(t, err)
+			require.Equal(t, "table", queries[0].Format)
+		})
 	})
 }
@@ -0,0 +1,290 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const (
+	tableFormat      = "table"
+	timeSeriesFormat = "time_series"
+
+	// tabularMaxRows limits the rows of SQL queries read by following their cursor,
+	// the frames of longer results have a warning notice.
+	tabularMaxRows = 10000
+)
+
+// Column types of the SQL, PPL and ES|QL responses
+var (
+	tabularTimeTypes = map[string]bool{
+		"date": true, "datetime": true, "date_nanos": true, "timestamp": true,
+	}
+	tabularNumberTypes = map[string]bool{
+		"byte": true, "short": true, "integer": true, "int": true, "long": true, "unsigned_long": true,
+		"float": true, "half_float": true, "scaled_float": true, "double": true, "counter_long": true,
+		"counter_integer": true, "counter_double": true,
+	}
+)
+
+var tabularTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+func isTabularQueryType(queryType string) bool {
+	switch queryType {
+	case es.LanguageSQL, es.LanguagePPL, es.LanguageESQL:
+		return true
+	default:
+		return false
+	}
+}
+
+func isTabularQuery(q *Query) bool {
+	return isTabularQueryType(q.QueryType)
+}
+
+// executeTabularQuery runs a SQL, PPL or ES|QL query and converts the result to a data frame.
+// SQL and ES|QL queries are filtered by the dashboard time range on the configured time field.
+// PPL doesn't support filters, so PPL queries have to use the $__from and $__to variables instead.
+func (e *elasticsearchDataQuery) executeTabularQuery(q *Query, from, to int64) backend.DataResponse {
+	if strings.TrimSpace(q.RawQuery) == "" {
+		return backend.DataResponse{Error: fmt.Errorf("invalid query, missing %s query", q.QueryType)}
+	}
+
+	timeField := e.client.GetConfiguredFields().TimeField
+	query := strings.NewReplacer(
+		"$__from", strconv.FormatInt(from, 10),
+		"$__to", strconv.FormatInt(to, 10),
+	).Replace(q.RawQuery)
+
+	req := &es.TabularRequest{
+		Language:  q.QueryType,
+		Query:     query,
+		FetchSize: defaultSize,
+		MaxRows:   tabularMaxRows,
+	}
+	if timeField != "" {
+		req.Filter = map[string]any{
+			"range": map[string]any{
+				timeField: map[string]any{
+					"gte":    from,
+					"lte":    to,
+					"format": es.DateFormatEpochMS,
+				},
+			},
+		}
+	}
+
+	res, err := e.client.ExecuteTabularQuery(req)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	frame, err := tabularResponseToFrame(res, q, timeField)
+	if err != nil {
+		e.logger.Error("Failed to convert tabular response", "error", err, "queryType", q.QueryType, "stage", es.StageParseResponse)
+		return backend.DataResponse{Error: err}
+	}
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+func tabularResponseToFrame(res *es.TabularResponse, q *Query, timeField string) (*data.Frame, error) {
+	rows := res.Rows
+	if q.Format == timeSeriesFormat {
+		timeIdx := -1
+		for i, col := range res.Columns {
+			if isTabularTimeColumn(col, timeField) {
+				timeIdx = i
+				break
+			}
+		}
+		if timeIdx == -1 {
+			return nil, fmt.Errorf("time series format requires a time column")
+		}
+
+		var err error
+		if rows, err = sortRowsByTime(rows, timeIdx); err != nil {
+			return nil, err
+		}
+	}
+
+	fields := make([]*data.Field, 0, len(res.Columns))
+	for i, col := range res.Columns {
+		field, err := tabularColumnToField(col, i, rows, timeField)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+
+	frame := data.NewFrame(q.RefID, fields...)
+	frame.Meta = &data.FrameMeta{
+		ExecutedQueryString:    q.RawQuery,
+		PreferredVisualization: data.VisTypeTable,
+	}
+	if res.Truncated {
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("The result is limited to the first %d rows", len(res.Rows)),
+		})
+	}
+
+	if q.Format != timeSeriesFormat {
+		return frame, nil
+	}
+
+	frame.Meta.PreferredVisualization = data.VisTypeGraph
+	if len(rows) > 0 && frame.TimeSeriesSchema().Type == data.TimeSeriesTypeLong {
+		return data.LongToWide(frame, nil)
+	}
+	return frame, nil
+}
+
+// isTabularTimeColumn returns true for date columns, and for the configured time field
+// when it is returned with a type that is not a date, like the strings of PPL.
+func isTabularTimeColumn(col es.TabularColumn, timeField string) bool {
+	typ := strings.ToLower(col.Type)
+	return tabularTimeTypes[typ] || (col.Name == timeField && !tabularNumberTypes[typ] && typ != "boolean")
+}
+
+func tabularColumnToField(col es.TabularColumn, idx int, rows [][]any, timeField string) (*data.Field, error) {
+	typ := strings.ToLower(col.Type)
+
+	if isTabularTimeColumn(col, timeField) {
+		values, err := tabularTimes(rows, idx)
+		if err == nil {
+			return data.NewField(col.Name, nil, values), nil
+		}
+		// The configured time field is only detected by name, so it may not contain dates.
+		if tabularTimeTypes[typ] {
+			return nil, fmt.Errorf("column %q: %w", col.Name, err)
+		}
+	}
+
+	switch {
+	case tabularNumberTypes[typ]:
+		values := make([]*float64, len(rows))
+		for i, row := range rows {
+			if v, ok := cell(row, idx).(float64); ok {
+				values[i] = &v
+			}
+		}
+		return data.NewField(col.Name, nil, values), nil
+	case typ == "boolean":
+		values := make([]*bool, len(rows))
+		for i, row := range rows {
+			if v, ok := cell(row, idx).(bool); ok {
+				values[i] = &v
+			}
+		}
+		return data.NewField(col.Name, nil, values), nil
+	default:
+		values := make([]*string, len(rows))
+		for i, row := range rows {
+			values[i] = tabularString(cell(row, idx))
+		}
+		return data.NewField(col.Name, nil, values), nil
+	}
+}
+
+func tabularTimes(rows [][]any, idx int) ([]*time.Time, error) {
+	values := make([]*time.Time, len(rows))
+	for i, row := range rows {
+		t, err := parseTabularTime(cell(row, idx))
+		if err != nil {
+			return nil, err
+		}
+		values[i] = t
+	}
+	return values, nil
+}
+
+func cell(row []any, idx int) any {
+	if idx < len(row) {
+		return row[idx]
+	}
+	return nil
+}
+
+func parseTabularTime(v any) (*time.Time, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		t := time.UnixMilli(int64(v)).UTC()
+		return &t, nil
+	case string:
+		for _, layout := range tabularTimeLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				t = t.UTC()
+				return &t, nil
+			}
+		}
+		return nil, fmt.Errorf("unable to parse time %q", v)
+	default:
+		return nil, fmt.Errorf("unexpected time value %v", v)
+	}
+}
+
+func tabularString(v any) *string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		s = strconv.FormatBool(v)
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			s = fmt.Sprint(v)
+		} else {
+			s = string(b)
+		}
+	}
+	return &s
+}
+
+// sortRowsByTime sorts the rows in ascending time order, which is required to convert
+// them to wide time series. Rows without time are dropped.
+func sortRowsByTime(rows [][]any, timeIdx int) ([][]any, error) {
+	type timedRow struct {
+		t   time.Time
+		row []any
+	}
+
+	timed := make([]timedRow, 0, len(rows))
+	for _, row := range rows {
+		t, err := parseTabularTime(cell(row, timeIdx))
+		if err != nil {
+			return nil, err
+		}
+		if t != nil {
+			timed = append(timed, timedRow{t: *t, row: row})
+		}
+	}
+	sort.SliceStable(timed, func(i, j int) bool {
+		return timed[i].t.Before(timed[j].t)
+	})
+
+	sorted := make([][]any, 0, len(timed))
+	for _, r := range timed {
+		sorted = append(sorted, r.row)
+	}
+	return sorted, nil
+}
@@ -0,0 +1,178 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func TestExecuteTabularQuery(t *testing.T) {
+	from := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 5, 1, 1, 0, 0, 0, time.UTC)
+
+	t.Run("SQL query is filtered by the time range", func(t *testing.T) {
+		c := newFakeClient()
+		c.tabularResponse = &es.TabularResponse{
+			Columns: []es.TabularColumn{{Name: "host", Type: "keyword"}, {Name: "c", Type: "long"}},
+			Rows:    [][]any{{"a", float64(1)}},
+		}
+
+		res, err := executeTabularTestQuery(c, `{"queryType": "sql", "query": "SELECT host, COUNT(*) AS c FROM logs GROUP BY host"}`, from, to)
+		require.NoError(t, err)
+		require.Empty(t, c.multisearchRequests)
+		require.Len(t, c.tabularRequests, 1)
+
+		req := c.tabularRequests[0]
+		require.Equal(t, es.LanguageSQL, req.Language)
+		require.Equal(t, tabularMaxRows, req.MaxRows)
+		require.Equal(t, map[string]any{
+			"range": map[string]any{
+				"@timestamp": map[string]any{"gte": from.UnixMilli(), "lte": to.UnixMilli(), "format": es.DateFormatEpochMS},
+			},
+		}, req.Filter)
+
+		frames := res.Responses["A"].Frames
+		require.Len(t, frames, 1)
+		require.Len(t, frames[0].Fields, 2)
+		require.Equal(t, data.FieldTypeNullableString, frames[0].Fields[0].Type())
+		require.Equal(t, data.FieldTypeNullableFloat64, frames[0].Fields[1].Type())
+	})
+
+	t.Run("PPL query time range variables are replaced", func(t *testing.T) {
+		c := newFakeClient()
+		c.tabularResponse = &es.TabularResponse{}
+
+		_, err := executeTabularTestQuery(c, `{"queryType": "ppl", "query": "source=logs | where @timestamp >= $__from and @timestamp <= $__to"}`, from, to)
+		require.NoError(t, err)
+		require.Len(t, c.tabularRequests, 1)
+		require.Equal(t, "source=logs | where @timestamp >= 1682899200000 and @timestamp <= 1682902800000", c.tabularRequests[0].Query)
+	})
+
+	t.Run("errors are returned per query", func(t *testing.T) {
+		c := newFakeClient()
+		c.tabularError = errors.New("verification_exception")
+
+		res, err := executeTabularTestQuery(c, `{"queryType": "esql", "query": "FROM logs"}`, from, to)
+		require.NoError(t, err)
+		require.EqualError(t, res.Responses["A"].Error, "verification_exception")
+	})
+
+	t.Run("empty query is an error", func(t *testing.T) {
+		c := newFakeClient()
+
+		res, err := executeTabularTestQuery(c, `{"queryType": "esql", "query": ""}`, from, to)
+		require.NoError(t, err)
+		require.Error(t, res.Responses["A"].Error)
+		require.Empty(t, c.tabularRequests)
+	})
+}
+
+func TestTabularResponseToFrame(t *testing.T) {
+	t.Run("detects time columns by type", func(t *testing.T) {
+		res := &es.TabularResponse{
+			Columns: []es.TabularColumn{{Name: "ts", Type: "date"}, {Name: "ok", Type: "boolean"}, {Name: "tags", Type: "object"}},
+			Rows: [][]any{
+				{"2023-05-01T00:00:00.000Z", true, map[string]any{"a": "b"}},
+				{nil, nil, nil},
+			},
+		}
+
+		frame, err := tabularResponseToFrame(res, &Query{RefID: "A", Format: tableFormat}, "@timestamp")
+		require.NoError(t, err)
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.Equal(t, time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), *frame.Fields[0].At(0).(*time.Time))
+		require.Nil(t, frame.Fields[0].At(1))
+		require.Equal(t, data.FieldTypeNullableBool, frame.Fields[1].Type())
+		require.Equal(t, `{"a":"b"}`, *frame.Fields[2].At(0).(*string))
+		require.Equal(t, data.VisTypeTable, string(frame.Meta.PreferredVisualization))
+	})
+
+	t.Run("warns when the rows are truncated", func(t *testing.T) {
+		res := &es.TabularResponse{
+			Columns:   []es.TabularColumn{{Name: "host", Type: "keyword"}},
+			Rows:      [][]any{{"a"}, {"b"}},
+			Truncated: true,
+		}
+
+		frame, err := tabularResponseToFrame(res, &Query{RefID: "A", Format: tableFormat}, "@timestamp")
+		require.NoError(t, err)
+		require.Equal(t, []data.Notice{{Severity: data.NoticeSeverityWarning, Text: "The result is limited to the first 2 rows"}}, frame.Meta.Notices)
+	})
+
+	t.Run("detects the configured time field by name", func(t *testing.T) {
+		res := &es.TabularResponse{
+			Columns: []es.TabularColumn{{Name: "@timestamp", Type: "string"}, {Name: "host", Type: "string"}},
+			Rows:    [][]any{{"2023-05-01 00:00:00", "a"}},
+		}
+
+		frame, err := tabularResponseToFrame(res, &Query{RefID: "A", Format: tableFormat}, "@timestamp")
+		require.NoError(t, err)
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[1].Type())
+	})
+
+	t.Run("keeps the configured time field as string when it doesn't contain dates", func(t *testing.T) {
+		res := &es.TabularResponse{
+			Columns: []es.TabularColumn{{Name: "@timestamp", Type: "keyword"}},
+			Rows:    [][]any{{"yesterday"}},
+		}
+
+		frame, err := tabularResponseToFrame(res, &Query{RefID: "A", Format: tableFormat}, "@timestamp")
+		require.NoError(t, err)
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[0].Type())
+	})
+
+	t.Run("converts time series to wide frames sorted by time", func(t *testing.T) {
+		res := &es.TabularResponse{
+			Columns: []es.TabularColumn{{Name: "bucket", Type: "date"}, {Name: "host", Type: "keyword"}, {Name: "c", Type: "long"}},
+			Rows: [][]any{
+				{"2023-05-01T00:01:00.000Z", "a", float64(3)},
+				{"2023-05-01T00:00:00.000Z", "a", float64(1)},
+				{"2023-05-01T00:00:00.000Z", "b", float64(2)},
+				{"2023-05-01T00:01:00.000Z", "b", float64(4)},
+			},
+		}
+
+		frame, err := tabularResponseToFrame(res, &Query{RefID: "A", Format: timeSeriesFormat}, "@timestamp")
+		require.NoError(t, err)
+		require.Equal(t, data.TimeSeriesTypeWide, frame.TimeSeriesSchema().Type)
+		require.Equal(t, "A", frame.Name)
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 2, frame.Fields[0].Len())
+		require.Equal(t, data.Labels{"host": "a"}, frame.Fields[1].Labels)
+		require.Equal(t, float64(1), *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, float64(3), *frame.Fields[1].At(1).(*float64))
+		require.Equal(t, data.VisTypeGraph, frame.Meta.PreferredVisualization)
+	})
+
+	t.Run("time series format requires a time column", func(t *testing.T) {
+		res := &es.TabularResponse{
+			Columns: []es.TabularColumn{{Name: "c", Type: "long"}},
+			Rows:    [][]any{{float64(1)}},
+		}
+
+		_, err := tabularResponseToFrame(res, &Query{RefID: "A", Format: timeSeriesFormat}, "@timestamp")
+		require.Error(t, err)
+	})
+}
+
+func executeTabularTestQuery(c es.Client, body string, from, to time.Time) (*backend.QueryDataResponse, error) {
+	queries := []backend.DataQuery{
+		{
+			RefID:     "A",
+			JSON:      json.RawMessage(body),
+			TimeRange: backend.TimeRange{From: from, To: to},
+		},
+	}
+	return newElasticsearchDataQuery(context.Background(), c, queries, log.New("test.logger"), tracing.NewFakeTracer()).execute()
+}
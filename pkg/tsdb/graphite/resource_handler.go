@@ -0,0 +1,217 @@
+package graphite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+const (
+	// metricsCacheTTL is used for the metric tree and tags, which change as new series are written.
+	metricsCacheTTL = time.Minute
+	// functionsCacheTTL is used for the functions list, which only changes when Graphite is upgraded.
+	functionsCacheTTL = time.Hour
+)
+
+// forwardedHeaders are the headers of the resource calls sent to Graphite. Grafana only sets them when
+// the data source forwards the OAuth identity or the cookies of the users.
+var forwardedHeaders = []string{
+	backend.OAuthIdentityTokenHeaderName,
+	backend.OAuthIdentityIDTokenHeaderName,
+	backend.CookiesHeaderName,
+}
+
+// Graphite 1.1.7 returns Infinity as a default value for some function parameters, which
+// is not valid JSON. See https://github.com/graphite-project/graphite-web/issues/2609
+var infinityDefault = regexp.MustCompile(`"default": ?Infinity`)
+
+type parseFn func(body []byte) (any, error)
+
+func (s *Service) newResourceMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics/find", s.handleResourceReq(metricsCacheTTL, parseJSON[[]MetricsFindResult]))
+	mux.HandleFunc("/metrics/expand", s.handleResourceReq(metricsCacheTTL, parseJSON[MetricsExpandResponse]))
+	mux.HandleFunc("/tags", s.handleResourceReq(metricsCacheTTL, parseJSON[[]TagInfo]))
+	mux.HandleFunc("/tags/", s.handleTagsReq())
+	mux.HandleFunc("/tags/findSeries", s.handleResourceReq(metricsCacheTTL, parseJSON[[]string]))
+	mux.HandleFunc("/tags/autoComplete/tags", s.handleResourceReq(metricsCacheTTL, parseJSON[[]string]))
+	mux.HandleFunc("/tags/autoComplete/values", s.handleResourceReq(metricsCacheTTL, parseJSON[[]string]))
+	mux.HandleFunc("/functions", s.handleResourceReq(functionsCacheTTL, parseFunctions))
+	return mux
+}
+
+// handleTagsReq parses the values of a tag, /tags/<tag>. The other paths under /tags/ that have no handler
+// are forwarded without being parsed.
+func (s *Service) handleTagsReq() http.HandlerFunc {
+	tagValues := s.handleResourceReq(metricsCacheTTL, parseJSON[TagValues])
+	other := s.handleResourceReq(metricsCacheTTL, parseRaw)
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if strings.Contains(strings.TrimPrefix(req.URL.Path, "/tags/"), "/") {
+			other(rw, req)
+			return
+		}
+		tagValues(rw, req)
+	}
+}
+
+// handleResourceReq forwards the request to the same path of the Graphite API, parses the
+// response and caches it per data source, unless the data source forwards the identity of the users.
+// Parameters are read from both the query string and a form encoded body, so requests built for the
+// data source proxy keep working.
+func (s *Service) handleResourceReq(ttl time.Duration, parse parseFn) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		logger := logger.FromContext(ctx)
+
+		if err := req.ParseForm(); err != nil {
+			writeResponse(rw, http.StatusBadRequest, fmt.Sprintf("failed to parse request: %v", err))
+			return
+		}
+
+		dsInfo, err := s.getDSInfo(ctx, httpadapter.PluginConfigFromContext(ctx))
+		if err != nil {
+			writeResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to get data source info: %v", err))
+			return
+		}
+
+		cacheKey := req.URL.Path + "?" + req.Form.Encode()
+		if !dsInfo.forwardsIdentity {
+			if cached, ok := dsInfo.resourceCache.Get(cacheKey); ok {
+				writeJSON(rw, cached.([]byte))
+				return
+			}
+		}
+
+		status, body, err := s.doResourceRequest(ctx, dsInfo, req.URL.Path, req.Form, req.Header)
+		if err != nil {
+			logger.Error("Graphite resource request failed", "path", req.URL.Path, "error", err)
+			writeResponse(rw, http.StatusBadGateway, fmt.Sprintf("graphite request failed: %v", err))
+			return
+		}
+		if status/100 != 2 {
+			logger.Info("Graphite resource request failed", "path", req.URL.Path, "status", status, "body", string(body))
+			writeResponse(rw, status, string(body))
+			return
+		}
+
+		result, err := parse(body)
+		if err != nil {
+			logger.Info("Failed to parse graphite response", "path", req.URL.Path, "error", err)
+			writeResponse(rw, http.StatusBadGateway, fmt.Sprintf("failed to parse graphite response: %v", err))
+			return
+		}
+
+		out, err := json.Marshal(result)
+		if err != nil {
+			writeResponse(rw, http.StatusInternalServerError, fmt.Sprintf("failed to marshal response: %v", err))
+			return
+		}
+
+		if !dsInfo.forwardsIdentity {
+			dsInfo.resourceCache.Set(cacheKey, out, ttl)
+		}
+		writeJSON(rw, out)
+	}
+}
+
+func (s *Service) doResourceRequest(ctx context.Context, dsInfo *datasourceInfo, resourcePath string, params url.Values, header http.Header) (int, []byte, error) {
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return 0, nil, err
+	}
+	u.Path = path.Join(u.Path, resourcePath)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for _, name := range forwardedHeaders {
+		if value := header.Get(name); value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+
+	ctx, span := s.tracer.Start(ctx, "graphite resource")
+	defer span.End()
+	span.SetAttributes("path", resourcePath, attribute.Key("path").String(resourcePath))
+	span.SetAttributes("datasource_id", dsInfo.Id, attribute.Key("datasource_id").Int64(dsInfo.Id))
+	s.tracer.Inject(ctx, req.Header, span)
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+	span.SetAttributes("graphite.response.code", res.StatusCode, attribute.Key("graphite.response.code").Int(res.StatusCode))
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return res.StatusCode, body, nil
+}
+
+func parseJSON[T any](body []byte) (any, error) {
+	var result T
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func parseRaw(body []byte) (any, error) {
+	if !json.Valid(body) {
+		return nil, errors.New("invalid JSON")
+	}
+	return json.RawMessage(body), nil
+}
+
+func parseFunctions(body []byte) (any, error) {
+	// 1e9999 is decoded as a json.Number and encoded back as is, so that the frontend reads it as Infinity.
+	body = infinityDefault.ReplaceAll(body, []byte(`"default": 1e9999`))
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var result map[string]FunctionDescription
+	if err := dec.Decode(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func writeJSON(rw http.ResponseWriter, body []byte) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	if _, err := rw.Write(body); err != nil {
+		logger.Error("Unable to write HTTP response", "error", err)
+	}
+}
+
+func writeResponse(rw http.ResponseWriter, code int, msg string) {
+	rw.WriteHeader(code)
+	if _, err := rw.Write([]byte(msg)); err != nil {
+		logger.Error("Unable to write HTTP response", "error", err)
+	}
+}
@@ -0,0 +1,249 @@
+package graphite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestCallResource(t *testing.T) {
+	t.Run("metrics/find is parsed and forwards form parameters", func(t *testing.T) {
+		graphite := setupResourceTest(t, func(rw http.ResponseWriter, req *http.Request) {
+			require.Equal(t, "/metrics/find", req.URL.Path)
+			require.Equal(t, "prod.*", req.URL.Query().Get("query"))
+			require.Equal(t, "-1h", req.URL.Query().Get("from"))
+			_, _ = rw.Write([]byte(`[
+				{"leaf": 0, "context": {}, "text": "servers", "expandable": 1, "id": "prod.servers", "allowChildren": 1},
+				{"leaf": true, "text": "up", "expandable": false, "id": "prod.up", "allowChildren": false}
+			]`))
+		})
+
+		resp := graphite.call(t, &backend.CallResourceRequest{
+			Method:  http.MethodPost,
+			Path:    "metrics/find",
+			URL:     "metrics/find?from=-1h",
+			Headers: map[string][]string{"Content-Type": {"application/x-www-form-urlencoded"}},
+			Body:    []byte("query=prod.*"),
+		})
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.Equal(t, 1, graphite.requests)
+
+		var result []MetricsFindResult
+		require.NoError(t, json.Unmarshal(resp.Body, &result))
+		require.Equal(t, []MetricsFindResult{
+			{Text: "servers", ID: "prod.servers", Expandable: true, AllowChildren: true},
+			{Text: "up", ID: "prod.up", Leaf: true},
+		}, result)
+	})
+
+	t.Run("metrics/expand is parsed", func(t *testing.T) {
+		graphite := setupResourceTest(t, func(rw http.ResponseWriter, req *http.Request) {
+			require.Equal(t, "/metrics/expand", req.URL.Path)
+			_, _ = rw.Write([]byte(`{"results": ["prod.servers.a", "prod.servers.b"]}`))
+		})
+
+		resp := graphite.call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "metrics/expand", URL: "metrics/expand?query=prod.servers.*"})
+		require.Equal(t, http.StatusOK, resp.Status)
+
+		var result MetricsExpandResponse
+		require.NoError(t, json.Unmarshal(resp.Body, &result))
+		require.Equal(t, []string{"prod.servers.a", "prod.servers.b"}, result.Results)
+	})
+
+	t.Run("tags endpoints are parsed", func(t *testing.T) {
+		graphite := setupResourceTest(t, func(rw http.ResponseWriter, req *http.Request) {
+			switch req.URL.Path {
+			case "/tags":
+				_, _ = rw.Write([]byte(`[{"tag": "name"}, {"tag": "server"}]`))
+			case "/tags/server":
+				_, _ = rw.Write([]byte(`{"tag": "server", "values": [{"count": 2, "value": "a"}]}`))
+			case "/tags/autoComplete/tags":
+				require.Equal(t, []string{"name=cpu", "dc=eu"}, req.URL.Query()["expr"])
+				_, _ = rw.Write([]byte(`["host", "server"]`))
+			case "/tags/autoComplete/values":
+				require.Equal(t, "server", req.URL.Query().Get("tag"))
+				_, _ = rw.Write([]byte(`["a", "b"]`))
+			case "/tags/findSeries":
+				require.Equal(t, "name=cpu", req.URL.Query().Get("expr"))
+				_, _ = rw.Write([]byte(`["cpu;server=a"]`))
+			case "/tags/server/other":
+				_, _ = rw.Write([]byte(`{"other": true}`))
+			default:
+				rw.WriteHeader(http.StatusNotFound)
+			}
+		})
+
+		resp := graphite.call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "tags", URL: "tags"})
+		var tags []TagInfo
+		require.NoError(t, json.Unmarshal(resp.Body, &tags))
+		require.Equal(t, []TagInfo{{Tag: "name"}, {Tag: "server"}}, tags)
+
+		resp = graphite.call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "tags/server", URL: "tags/server"})
+		var values TagValues
+		require.NoError(t, json.Unmarshal(resp.Body, &values))
+		require.Equal(t, TagValues{Tag: "server", Values: []TagValue{{Value: "a", Count: 2}}}, values)
+
+		resp = graphite.call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "tags/autoComplete/tags", URL: "tags/autoComplete/tags?expr=name%3Dcpu&expr=dc%3Deu"})
+		require.JSONEq(t, `["host", "server"]`, string(resp.Body))
+
+		resp = graphite.call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "tags/autoComplete/values", URL: "tags/autoComplete/values?tag=server"})
+		require.JSONEq(t, `["a", "b"]`, string(resp.Body))
+
+		resp = graphite.call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "tags/findSeries", URL: "tags/findSeries?expr=name%3Dcpu"})
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `["cpu;server=a"]`, string(resp.Body))
+
+		resp = graphite.call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "tags/server/other", URL: "tags/server/other"})
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.JSONEq(t, `{"other": true}`, string(resp.Body))
+	})
+
+	t.Run("functions with Infinity defaults are parsed", func(t *testing.T) {
+		graphite := setupResourceTest(t, func(rw http.ResponseWriter, req *http.Request) {
+			require.Equal(t, "/functions", req.URL.Path)
+			_, _ = rw.Write([]byte(`{
+				"removeAboveValue": {
+					"name": "removeAboveValue",
+					"function": "removeAboveValue(seriesList, n)",
+					"group": "Filter Data",
+					"params": [
+						{"name": "seriesList", "type": "seriesList", "required": true},
+						{"name": "n", "type": "float", "default": Infinity}
+					]
+				}
+			}`))
+		})
+
+		resp := graphite.call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "functions", URL: "functions"})
+		require.Equal(t, http.StatusOK, resp.Status)
+		require.Contains(t, string(resp.Body), `"default":1e9999`)
+
+		var result map[string]FunctionDescription
+		dec := json.NewDecoder(bytes.NewReader(resp.Body))
+		dec.UseNumber()
+		require.NoError(t, dec.Decode(&result))
+		fn := result["removeAboveValue"]
+		require.Equal(t, "Filter Data", fn.Group)
+		require.Len(t, fn.Params, 2)
+		require.True(t, fn.Params[0].Required)
+		require.Equal(t, json.Number("1e9999"), fn.Params[1].Default)
+	})
+
+	t.Run("responses are cached per path and parameters", func(t *testing.T) {
+		graphite := setupResourceTest(t, func(rw http.ResponseWriter, req *http.Request) {
+			_, _ = rw.Write([]byte(`["a"]`))
+		})
+
+		for i := 0; i < 3; i++ {
+			resp := graphite.call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "tags/autoComplete/tags", URL: "tags/autoComplete/tags?expr=a%3Db"})
+			require.Equal(t, http.StatusOK, resp.Status)
+		}
+		require.Equal(t, 1, graphite.requests)
+
+		graphite.call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "tags/autoComplete/tags", URL: "tags/autoComplete/tags?expr=a%3Dc"})
+		require.Equal(t, 2, graphite.requests)
+	})
+
+	t.Run("responses are not cached when the identity of the users is forwarded", func(t *testing.T) {
+		graphite := setupResourceTest(t, func(rw http.ResponseWriter, req *http.Request) {
+			_, _ = rw.Write([]byte(`["` + req.Header.Get("Authorization") + `", "` + req.Header.Get("Cookie") + `"]`))
+		})
+		graphite.jsonData = []byte(`{"oauthPassThru": true, "keepCookies": ["session"]}`)
+
+		for _, user := range []string{"alice", "bob"} {
+			resp := graphite.call(t, &backend.CallResourceRequest{
+				Method: http.MethodGet,
+				Path:   "tags/autoComplete/tags",
+				URL:    "tags/autoComplete/tags?expr=a%3Db",
+				Headers: map[string][]string{
+					"Authorization": {"Bearer " + user},
+					"Cookie":        {"session=" + user},
+				},
+			})
+			require.Equal(t, http.StatusOK, resp.Status)
+
+			var result []string
+			require.NoError(t, json.Unmarshal(resp.Body, &result))
+			require.Equal(t, []string{"Bearer " + user, "session=" + user}, result)
+		}
+		require.Equal(t, 2, graphite.requests)
+	})
+
+	t.Run("graphite errors are returned and not cached", func(t *testing.T) {
+		graphite := setupResourceTest(t, func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte("invalid expression"))
+		})
+
+		for i := 0; i < 2; i++ {
+			resp := graphite.call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "metrics/expand", URL: "metrics/expand?query=("})
+			require.Equal(t, http.StatusBadRequest, resp.Status)
+			require.Equal(t, "invalid expression", string(resp.Body))
+		}
+		require.Equal(t, 2, graphite.requests)
+	})
+
+	t.Run("invalid responses are reported", func(t *testing.T) {
+		graphite := setupResourceTest(t, func(rw http.ResponseWriter, req *http.Request) {
+			_, _ = rw.Write([]byte("<html></html>"))
+		})
+
+		resp := graphite.call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "metrics/find", URL: "metrics/find?query=*"})
+		require.Equal(t, http.StatusBadGateway, resp.Status)
+	})
+}
+
+type fakeGraphite struct {
+	service  *Service
+	url      string
+	jsonData []byte
+	requests int
+}
+
+func setupResourceTest(t *testing.T, handler http.HandlerFunc) *fakeGraphite {
+	t.Helper()
+
+	graphite := &fakeGraphite{
+		service: ProvideService(httpclient.NewProvider(), tracing.InitializeTracerForTest()),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		graphite.requests++
+		handler(rw, req)
+	}))
+	t.Cleanup(srv.Close)
+	graphite.url = srv.URL
+
+	return graphite
+}
+
+func (f *fakeGraphite) call(t *testing.T, req *backend.CallResourceRequest) *backend.CallResourceResponse {
+	t.Helper()
+
+	req.PluginContext = backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1, UID: "graphite", URL: f.url, JSONData: f.jsonData},
+	}
+
+	sender := &fakeSender{}
+	err := f.service.CallResource(context.Background(), req, sender)
+	require.NoError(t, err)
+	require.NotNil(t, sender.resp)
+	return sender.resp
+}
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
@@ -1,6 +1,10 @@
 package graphite
 
-import "github.com/grafana/grafana/pkg/tsdb/legacydata"
+import (
+	"encoding/json"
+
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
 
 type TargetResponseDTO struct {
 	Target     string                          `json:"target"`
@@ -8,3 +12,87 @@ type TargetResponseDTO struct {
 	// Graphite <=1.1.7 may return some tags as numbers requiring extra conversion. See https://github.com/grafana/grafana/issues/37614
 	Tags map[string]any `json:"tags"`
 }
+
+// MetricsFindResult is a node of the metric tree returned by the /metrics/find endpoint.
+type MetricsFindResult struct {
+	Text          string `json:"text"`
+	ID            string `json:"id"`
+	Leaf          bool   `json:"leaf"`
+	Expandable    bool   `json:"expandable"`
+	AllowChildren bool   `json:"allowChildren"`
+}
+
+// UnmarshalJSON accepts both booleans and the 0/1 integers graphite-web uses for the node flags.
+func (r *MetricsFindResult) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Text          string `json:"text"`
+		ID            string `json:"id"`
+		Leaf          any    `json:"leaf"`
+		Expandable    any    `json:"expandable"`
+		AllowChildren any    `json:"allowChildren"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	r.Text = raw.Text
+	r.ID = raw.ID
+	r.Leaf = toBool(raw.Leaf)
+	r.Expandable = toBool(raw.Expandable)
+	r.AllowChildren = toBool(raw.AllowChildren)
+	return nil
+}
+
+func toBool(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v == "1" || v == "true"
+	default:
+		return false
+	}
+}
+
+// MetricsExpandResponse is the response of the /metrics/expand endpoint.
+type MetricsExpandResponse struct {
+	Results []string `json:"results"`
+}
+
+// TagInfo is a single tag returned by the /tags endpoint.
+type TagInfo struct {
+	Tag string `json:"tag"`
+}
+
+// TagValues is the response of the /tags/<tag> endpoint.
+type TagValues struct {
+	Tag    string     `json:"tag"`
+	Values []TagValue `json:"values"`
+}
+
+type TagValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// FunctionDescription describes a function returned by the /functions endpoint.
+type FunctionDescription struct {
+	Name        string          `json:"name"`
+	Function    string          `json:"function,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Module      string          `json:"module,omitempty"`
+	Group       string          `json:"group,omitempty"`
+	Params      []FunctionParam `json:"params"`
+}
+
+type FunctionParam struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required,omitempty"`
+	Multiple    bool   `json:"multiple,omitempty"`
+	Default     any    `json:"default,omitempty"`
+	Options     []any  `json:"options,omitempty"`
+	Suggestions []any  `json:"suggestions,omitempty"`
+}
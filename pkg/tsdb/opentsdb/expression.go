@@ -0,0 +1,119 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// executeExpressionQuery sends an expression query to the /api/query/exp endpoint, available
+// since OpenTSDB 2.3. The time section of the request is built from the query time range and
+// the aggregation, downsampling and rate options of the query.
+func (s *Service) executeExpressionQuery(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, query backend.DataQuery, model *QueryModel) backend.DataResponse {
+	expReq := ExpressionRequest{
+		Time: ExpressionTime{
+			Start:      strconv.FormatInt(query.TimeRange.From.UnixMilli(), 10),
+			End:        strconv.FormatInt(query.TimeRange.To.UnixMilli(), 10),
+			Aggregator: model.Aggregator,
+			Rate:       model.ShouldComputeRate,
+		},
+		ExpressionQuery: *model.Expression,
+	}
+	if !model.DisableDownsampling {
+		expReq.Time.Downsampler = &Downsampler{
+			Interval:   downsampleInterval(query, model),
+			Aggregator: model.DownsampleAggregator,
+		}
+		if model.DownsampleFillPolicy != "none" {
+			expReq.Time.Downsampler.FillPolicy = &FillPolicy{Policy: model.DownsampleFillPolicy}
+		}
+	}
+
+	request, err := s.createRequestWithPath(ctx, logger, dsInfo, "api/query/exp", expReq)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	res, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	body, err := readResponse(logger, res)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	var expRes ExpressionResponse
+	if err := json.Unmarshal(body, &expRes); err != nil {
+		logger.Info("Failed to unmarshal opentsdb expression response", "error", err, "body", string(body))
+		return backend.DataResponse{Error: err}
+	}
+
+	frames, err := expressionOutputsToFrames(expRes.Outputs, query.RefID, model)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	return backend.DataResponse{Frames: frames}
+}
+
+// expressionOutputsToFrames returns one frame per series of each output. The rows of an output
+// contain the timestamp in milliseconds followed by a value for each series.
+func expressionOutputsToFrames(outputs []ExpressionOutputResult, refID string, model *QueryModel) (data.Frames, error) {
+	frames := data.Frames{}
+	for _, output := range outputs {
+		timeVector := make([]time.Time, 0, len(output.Dps))
+		for _, row := range output.Dps {
+			if len(row) == 0 || row[0] == nil {
+				return nil, fmt.Errorf("output %q contains a row without timestamp", output.ID)
+			}
+			timeVector = append(timeVector, time.UnixMilli(int64(*row[0])).UTC())
+		}
+
+		for _, meta := range output.Meta {
+			if meta.Index == 0 {
+				// the first column is the timestamp
+				continue
+			}
+
+			values := make([]*float64, 0, len(output.Dps))
+			for _, row := range output.Dps {
+				var value *float64
+				if meta.Index < len(row) {
+					value = row[meta.Index]
+				}
+				values = append(values, value)
+			}
+
+			name := output.ID
+			if len(meta.Metrics) > 0 {
+				name = meta.Metrics[0]
+			}
+
+			label := name
+			if output.Alias != "" {
+				label = replaceTagVariables(output.Alias, meta.CommonTags)
+			} else if model.Alias != "" {
+				label = replaceTagVariables(model.Alias, meta.CommonTags)
+			}
+
+			valueField := data.NewField("value", meta.CommonTags, values)
+			valueField.Config = &data.FieldConfig{DisplayNameFromDS: label}
+
+			frame := data.NewFrame(name,
+				data.NewField("time", nil, timeVector),
+				valueField)
+			frame.RefID = refID
+			frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesMulti}
+			frames = append(frames, frame)
+		}
+	}
+	return frames, nil
+}
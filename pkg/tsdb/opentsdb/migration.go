@@ -0,0 +1,178 @@
+package opentsdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	metricQueryType     = "metric"
+	expressionQueryType = "expression"
+
+	defaultAggregator = "avg"
+)
+
+var filterTypes = map[string]bool{
+	"literal_or":      true,
+	"iliteral_or":     true,
+	"not_literal_or":  true,
+	"not_iliteral_or": true,
+	"wildcard":        true,
+	"iwildcard":       true,
+	"regexp":          true,
+}
+
+// parseQueryModel reads the query model, migrating the loosely typed values saved by
+// older versions of the query editor: counter values and percentiles stored as strings,
+// tag values stored as numbers and filter groupBy flags stored as strings.
+func parseQueryModel(raw []byte) (*QueryModel, error) {
+	var m map[string]any
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+
+	for _, key := range []string{"counterMax", "counterResetValue"} {
+		if v, ok := m[key]; ok {
+			m[key] = toNumber(v)
+		}
+	}
+
+	if tags, ok := m["tags"].(map[string]any); ok {
+		for k, v := range tags {
+			tags[k] = toString(v)
+		}
+	} else {
+		delete(m, "tags")
+	}
+
+	if filters, ok := m["filters"].([]any); ok {
+		for _, f := range filters {
+			if filter, ok := f.(map[string]any); ok {
+				filter["groupBy"] = toBool(filter["groupBy"])
+				filter["filter"] = toString(filter["filter"])
+			}
+		}
+	} else {
+		delete(m, "filters")
+	}
+
+	if percentiles, ok := m["percentiles"].([]any); ok {
+		result := make([]any, 0, len(percentiles))
+		for _, p := range percentiles {
+			if n := toNumber(p); n != nil {
+				result = append(result, n)
+			}
+		}
+		m["percentiles"] = result
+	} else {
+		delete(m, "percentiles")
+	}
+
+	for _, key := range []string{"disableDownsampling", "shouldComputeRate", "isCounter", "explicitTags"} {
+		if v, ok := m[key]; ok {
+			m[key] = toBool(v)
+		}
+	}
+
+	migrated, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	model := &QueryModel{}
+	if err := json.Unmarshal(migrated, model); err != nil {
+		return nil, err
+	}
+
+	if model.QueryType == "" {
+		model.QueryType = metricQueryType
+	}
+	if model.Aggregator == "" {
+		model.Aggregator = defaultAggregator
+	}
+	if model.DownsampleAggregator == "" {
+		model.DownsampleAggregator = defaultAggregator
+	}
+	if model.DownsampleFillPolicy == "" {
+		model.DownsampleFillPolicy = "none"
+	}
+
+	return model, nil
+}
+
+func (m *QueryModel) validate() error {
+	switch m.QueryType {
+	case metricQueryType:
+		if m.Metric == "" {
+			return fmt.Errorf("query has no metric")
+		}
+		return validateFilters(m.Filters)
+	case expressionQueryType:
+		if m.Expression == nil || len(m.Expression.Metrics) == 0 {
+			return fmt.Errorf("expression query has no metrics")
+		}
+		for _, fs := range m.Expression.Filters {
+			if err := validateFilters(fs.Tags); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported query type %q", m.QueryType)
+	}
+}
+
+func validateFilters(filters []Filter) error {
+	for _, f := range filters {
+		if !filterTypes[f.Type] {
+			return fmt.Errorf("unsupported filter type %q for tag %q", f.Type, f.Tagk)
+		}
+		if f.Tagk == "" {
+			return fmt.Errorf("filter of type %q has no tag key", f.Type)
+		}
+	}
+	return nil
+}
+
+func toNumber(v any) any {
+	switch v := v.(type) {
+	case float64:
+		return v
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func toBool(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	case float64:
+		return v != 0
+	default:
+		return false
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -17,7 +19,6 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
-	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/setting"
@@ -38,6 +39,8 @@ func ProvideService(httpClientProvider httpclient.Provider) *Service {
 type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
+	// MsResolution is set when the data source is configured with millisecond resolution
+	MsResolution bool
 }
 
 type DsAccess string
@@ -54,9 +57,19 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		jsonData := struct {
+			TSDBResolution int `json:"tsdbResolution"`
+		}{}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:   client,
+			URL:          settings.URL,
+			MsResolution: jsonData.TSDBResolution == 2,
 		}
 
 		return model, nil
@@ -64,18 +77,68 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
-
 	logger := logger.FromContext(ctx)
 
-	q := req.Queries[0]
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
 
-	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
-	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+	result := backend.NewQueryDataResponse()
 
+	// Metric queries with the same time range are sent in a single request
+	batches := map[backend.TimeRange][]metricQuery{}
+	var order []backend.TimeRange
 	for _, query := range req.Queries {
-		metric := s.buildMetric(query)
-		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
+		model, err := parseQueryModel(query.JSON)
+		if err != nil {
+			result.Responses[query.RefID] = backend.DataResponse{Error: fmt.Errorf("failed to parse query: %w", err)}
+			continue
+		}
+		if err := model.validate(); err != nil {
+			result.Responses[query.RefID] = backend.DataResponse{Error: err}
+			continue
+		}
+
+		if model.QueryType == expressionQueryType {
+			result.Responses[query.RefID] = s.executeExpressionQuery(ctx, logger, dsInfo, query, model)
+			continue
+		}
+
+		if _, ok := batches[query.TimeRange]; !ok {
+			order = append(order, query.TimeRange)
+		}
+		batches[query.TimeRange] = append(batches[query.TimeRange], metricQuery{
+			refID:    query.RefID,
+			model:    model,
+			subQuery: s.buildMetric(query, model),
+		})
+	}
+
+	for _, tr := range order {
+		for refID, res := range s.executeMetricQueries(ctx, logger, dsInfo, tr, batches[tr]) {
+			result.Responses[refID] = res
+		}
+	}
+
+	return result, nil
+}
+
+type metricQuery struct {
+	refID    string
+	model    *QueryModel
+	subQuery SubQuery
+}
+
+func (s *Service) executeMetricQueries(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, tr backend.TimeRange, queries []metricQuery) backend.Responses {
+	tsdbQuery := OpenTsdbQuery{
+		Start:        tr.From.UnixNano() / int64(time.Millisecond),
+		End:          tr.To.UnixNano() / int64(time.Millisecond),
+		MsResolution: dsInfo.MsResolution,
+		ShowQuery:    true,
+	}
+	for _, q := range queries {
+		tsdbQuery.Queries = append(tsdbQuery.Queries, q.subQuery)
 	}
 
 	// TODO: Don't use global variable
@@ -83,42 +146,76 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		logger.Debug("OpenTsdb request", "params", tsdbQuery)
 	}
 
-	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
-	if err != nil {
-		return nil, err
+	responses := backend.Responses{}
+	setError := func(err error) backend.Responses {
+		for _, q := range queries {
+			responses[q.refID] = backend.DataResponse{Error: err}
+		}
+		return responses
 	}
 
 	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return setError(err)
 	}
 
 	res, err := dsInfo.HTTPClient.Do(request)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return setError(err)
 	}
 
-	defer func() {
-		err := res.Body.Close()
+	series, err := s.parseResponse(logger, res)
+	if err != nil {
+		return setError(err)
+	}
+
+	for _, q := range queries {
+		responses[q.refID] = backend.DataResponse{Frames: data.Frames{}}
+	}
+	for _, ts := range series {
+		idx := matchQuery(ts, queries)
+		if idx == -1 {
+			logger.Warn("Unable to find the query of a series", "metric", ts.Metric)
+			continue
+		}
+		q := queries[idx]
+
+		frame, err := seriesToFrame(ts, q, dsInfo.MsResolution)
 		if err != nil {
-			logger.Warn("failed to close response body", "error", err)
+			return setError(err)
 		}
-	}()
+		r := responses[q.refID]
+		r.Frames = append(r.Frames, frame)
+		responses[q.refID] = r
+	}
 
-	result, err := s.parseResponse(logger, res)
-	if err != nil {
-		return &backend.QueryDataResponse{}, err
+	return responses
+}
+
+// matchQuery returns the index of the query that returned the series. OpenTSDB 2.2+ includes
+// the query in the response, for older versions the series is matched by metric name.
+func matchQuery(ts OpenTsdbResponse, queries []metricQuery) int {
+	if ts.Query != nil && ts.Query.Index >= 0 && ts.Query.Index < len(queries) {
+		return ts.Query.Index
 	}
+	for i, q := range queries {
+		if q.subQuery.Metric == ts.Metric {
+			return i
+		}
+	}
+	return -1
+}
 
-	return result, nil
+func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, data any) (*http.Request, error) {
+	return s.createRequestWithPath(ctx, logger, dsInfo, "api/query", data)
 }
 
-func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, data OpenTsdbQuery) (*http.Request, error) {
+func (s *Service) createRequestWithPath(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, apiPath string, data any) (*http.Request, error) {
 	u, err := url.Parse(dsInfo.URL)
 	if err != nil {
 		return nil, err
 	}
-	u.Path = path.Join(u.Path, "api/query")
+	u.Path = path.Join(u.Path, apiPath)
 
 	postData, err := json.Marshal(data)
 	if err != nil {
@@ -136,9 +233,10 @@ func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *
 	return req, nil
 }
 
-func (s *Service) parseResponse(logger log.Logger, res *http.Response) (*backend.QueryDataResponse, error) {
-	resp := backend.NewQueryDataResponse()
+// OpenTSDB writes NaN values, for example with the nan fill policy, which are not valid JSON
+var nanValue = regexp.MustCompile(`([:\[,]\s*)NaN\b`)
 
+func readResponse(logger log.Logger, res *http.Response) ([]byte, error) {
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
 		return nil, err
@@ -151,9 +249,22 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response) (*backend
 
 	if res.StatusCode/100 != 2 {
 		logger.Info("Request failed", "status", res.Status, "body", string(body))
+		var errResp ErrorResponse
+		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
+			return nil, fmt.Errorf("request failed, status: %s: %s", res.Status, errResp.Error.Message)
+		}
 		return nil, fmt.Errorf("request failed, status: %s", res.Status)
 	}
 
+	return nanValue.ReplaceAll(body, []byte("${1}null")), nil
+}
+
+func (s *Service) parseResponse(logger log.Logger, res *http.Response) ([]OpenTsdbResponse, error) {
+	body, err := readResponse(logger, res)
+	if err != nil {
+		return nil, err
+	}
+
 	var responseData []OpenTsdbResponse
 	err = json.Unmarshal(body, &responseData)
 	if err != nil {
@@ -161,97 +272,165 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response) (*backend
 		return nil, err
 	}
 
-	frames := data.Frames{}
-	for _, val := range responseData {
-		timeVector := make([]time.Time, 0, len(val.DataPoints))
-		values := make([]float64, 0, len(val.DataPoints))
-		name := val.Metric
-		tags := val.Tags
+	return responseData, nil
+}
 
-		for timeString, value := range val.DataPoints {
-			timestamp, err := strconv.ParseInt(timeString, 10, 64)
-			if err != nil {
-				logger.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
-				return nil, err
-			}
+func seriesToFrame(ts OpenTsdbResponse, q metricQuery, msResolution bool) (*data.Frame, error) {
+	timestamps := make([]int64, 0, len(ts.DataPoints))
+	for timeString := range ts.DataPoints {
+		timestamp, err := strconv.ParseInt(timeString, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse opentsdb timestamp %q: %w", timeString, err)
+		}
+		timestamps = append(timestamps, timestamp)
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	timeVector := make([]time.Time, 0, len(timestamps))
+	values := make([]*float64, 0, len(timestamps))
+	for _, timestamp := range timestamps {
+		value := ts.DataPoints[strconv.FormatInt(timestamp, 10)]
+		if msResolution {
+			timeVector = append(timeVector, time.UnixMilli(timestamp).UTC())
+		} else {
 			timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
-			values = append(values, value)
 		}
-		frames = append(frames, data.NewFrame(name,
-			data.NewField("time", nil, timeVector),
-			data.NewField("value", tags, values)))
-	}
-	result := resp.Responses["A"]
-	result.Frames = frames
-	resp.Responses["A"] = result
-	return resp, nil
-}
+		values = append(values, value)
+	}
 
-func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
-	metric := make(map[string]any)
+	valueField := data.NewField("value", ts.Tags, values)
+	valueField.Config = &data.FieldConfig{DisplayNameFromDS: metricLabel(ts.Metric, ts.Tags, q.model)}
 
-	model, err := simplejson.NewJson(query.JSON)
-	if err != nil {
-		return nil
-	}
+	frame := data.NewFrame(ts.Metric,
+		data.NewField("time", nil, timeVector),
+		valueField)
+	frame.RefID = q.refID
+	frame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesMulti}
+	return frame, nil
+}
 
-	// Setting metric and aggregator
-	metric["metric"] = model.Get("metric").MustString()
-	metric["aggregator"] = model.Get("aggregator").MustString()
+// metricLabel returns the name of a series the same way the query editor used to: the alias with
+// $tag_<key> replaced by the tag values, or the metric followed by the tags used to group by.
+func metricLabel(metric string, tags map[string]string, model *QueryModel) string {
+	if model.Alias != "" {
+		return replaceTagVariables(model.Alias, tags)
+	}
 
-	// Setting downsampling options
-	disableDownsampling := model.Get("disableDownsampling").MustBool()
-	if !disableDownsampling {
-		downsampleInterval := model.Get("downsampleInterval").MustString()
-		if downsampleInterval == "" {
-			downsampleInterval = "1m" // default value for blank
+	groupBy := map[string]bool{}
+	if len(model.Filters) > 0 {
+		for _, f := range model.Filters {
+			groupBy[f.Tagk] = true
 		}
-		downsample := downsampleInterval + "-" + model.Get("downsampleAggregator").MustString()
-		if model.Get("downsampleFillPolicy").MustString() != "none" {
-			metric["downsample"] = downsample + "-" + model.Get("downsampleFillPolicy").MustString()
-		} else {
-			metric["downsample"] = downsample
+	} else {
+		for k := range model.Tags {
+			groupBy[k] = true
 		}
 	}
 
-	// Setting rate options
-	if model.Get("shouldComputeRate").MustBool() {
-		metric["rate"] = true
-		rateOptions := make(map[string]any)
-		rateOptions["counter"] = model.Get("isCounter").MustBool()
-
-		counterMax, counterMaxCheck := model.CheckGet("counterMax")
-		if counterMaxCheck {
-			rateOptions["counterMax"] = counterMax.MustFloat64()
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		if groupBy[k] {
+			keys = append(keys, k)
 		}
+	}
+	if len(keys) == 0 {
+		return metric
+	}
+	sort.Strings(keys)
 
-		resetValue, resetValueCheck := model.CheckGet("counterResetValue")
-		if resetValueCheck {
-			rateOptions["resetValue"] = resetValue.MustFloat64()
-		}
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+tags[k])
+	}
+	return metric + "{" + strings.Join(pairs, ", ") + "}"
+}
+
+var tagVariable = regexp.MustCompile(`\$tag_(\w+)|\[\[tag_(\w+)\]\]|\$\{tag_(\w+)\}`)
 
-		if !counterMaxCheck && (!resetValueCheck || resetValue.MustFloat64() == 0) {
-			rateOptions["dropResets"] = true
+func replaceTagVariables(alias string, tags map[string]string) string {
+	return tagVariable.ReplaceAllStringFunc(alias, func(match string) string {
+		groups := tagVariable.FindStringSubmatch(match)
+		for _, key := range groups[1:] {
+			if key == "" {
+				continue
+			}
+			if value, ok := tags[key]; ok {
+				return value
+			}
 		}
+		return match
+	})
+}
+
+func (s *Service) buildMetric(query backend.DataQuery, model *QueryModel) SubQuery {
+	metric := SubQuery{
+		Metric:       model.Metric,
+		Aggregator:   model.Aggregator,
+		ExplicitTags: model.ExplicitTags,
+		Percentiles:  model.Percentiles,
+	}
 
-		metric["rateOptions"] = rateOptions
+	// Setting downsampling options
+	if !model.DisableDownsampling {
+		metric.Downsample = downsampleInterval(query, model) + "-" + model.DownsampleAggregator
+		if model.DownsampleFillPolicy != "none" {
+			metric.Downsample += "-" + model.DownsampleFillPolicy
+		}
 	}
 
-	// Setting tags
-	tags, tagsCheck := model.CheckGet("tags")
-	if tagsCheck && len(tags.MustMap()) > 0 {
-		metric["tags"] = tags.MustMap()
+	// Setting rate options
+	if model.ShouldComputeRate {
+		metric.Rate = true
+		metric.RateOptions = &RateOptions{
+			Counter:    model.IsCounter,
+			CounterMax: model.CounterMax,
+			ResetValue: model.CounterResetValue,
+		}
+		if model.CounterMax == nil && (model.CounterResetValue == nil || *model.CounterResetValue == 0) {
+			metric.RateOptions.DropResets = true
+		}
 	}
 
-	// Setting filters
-	filters, filtersCheck := model.CheckGet("filters")
-	if filtersCheck && len(filters.MustArray()) > 0 {
-		metric["filters"] = filters.MustArray()
+	// Filters replace tags, they can't be used together
+	if len(model.Filters) > 0 {
+		metric.Filters = model.Filters
+	} else if len(model.Tags) > 0 {
+		metric.Tags = model.Tags
 	}
 
 	return metric
 }
 
+// autoDownsampleInterval downsamples the query with the interval calculated for the panel
+const autoDownsampleInterval = "auto"
+
+// downsampleInterval returns the downsample interval of the query, 1m when it is not set.
+func downsampleInterval(query backend.DataQuery, model *QueryModel) string {
+	switch model.DownsampleInterval {
+	case "":
+		return "1m" // default value for blank
+	case autoDownsampleInterval:
+		return formatInterval(query.Interval)
+	default:
+		return model.DownsampleInterval
+	}
+}
+
+func formatInterval(interval time.Duration) string {
+	switch {
+	case interval <= 0:
+		return "1m" // default value for blank
+	case interval%time.Hour == 0:
+		return fmt.Sprintf("%dh", interval/time.Hour)
+	case interval%time.Minute == 0:
+		return fmt.Sprintf("%dm", interval/time.Minute)
+	case interval%time.Second == 0:
+		return fmt.Sprintf("%ds", interval/time.Second)
+	default:
+		return fmt.Sprintf("%dms", interval.Milliseconds())
+	}
+}
+
 func (s *Service) getDSInfo(ctx context.Context, pluginCtx backend.PluginContext) (*datasourceInfo, error) {
 	i, err := s.im.Get(ctx, pluginCtx)
 	if err != nil {
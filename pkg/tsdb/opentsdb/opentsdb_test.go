@@ -2,8 +2,10 @@ package opentsdb
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -13,6 +15,8 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
 )
 
 func TestOpenTsdbExecutor(t *testing.T) {
@@ -44,6 +48,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			{
 				"metric": "test",
 				"dps": {
+					"1405544147": null,
 					"1405544146": 50.0
 				},
 				"tags" : {
@@ -56,23 +61,45 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		testFrame := data.NewFrame("test",
 			data.NewField("time", nil, []time.Time{
 				time.Date(2014, 7, 16, 20, 55, 46, 0, time.UTC),
+				time.Date(2014, 7, 16, 20, 55, 47, 0, time.UTC),
 			}),
-			data.NewField("value", map[string]string{"env": "prod", "app": "grafana"}, []float64{
-				50}),
+			data.NewField("value", map[string]string{"env": "prod", "app": "grafana"}, []*float64{
+				pointer(50), nil}).SetConfig(&data.FieldConfig{DisplayNameFromDS: "test{app=grafana}"}),
 		)
+		testFrame.RefID = "A"
+		testFrame.Meta = &data.FrameMeta{Type: data.FrameTypeTimeSeriesMulti}
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp)
+		series, err := service.parseResponse(logger, &resp)
 		require.NoError(t, err)
+		require.Len(t, series, 1)
 
-		frame := result.Responses["A"]
+		frame, err := seriesToFrame(series[0], metricQuery{refID: "A", model: &QueryModel{Tags: map[string]string{"app": "*"}}}, false)
+		require.NoError(t, err)
 
-		if diff := cmp.Diff(testFrame, frame.Frames[0], data.FrameTestCompareOptions()...); diff != "" {
+		if diff := cmp.Diff(testFrame, frame, data.FrameTestCompareOptions()...); diff != "" {
 			t.Errorf("Result mismatch (-want +got):\n%s", diff)
 		}
 	})
 
+	t.Run("Parse response should handle NaN values", func(t *testing.T) {
+		response := `[{"metric": "test", "dps": {"1405544146": NaN}, "tags": {}}]`
+
+		resp := http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(response))}
+		series, err := service.parseResponse(logger, &resp)
+		require.NoError(t, err)
+		require.Nil(t, series[0].DataPoints["1405544146"])
+	})
+
+	t.Run("Parse response should return the OpenTSDB error", func(t *testing.T) {
+		response := `{"error": {"code": 400, "message": "No such name for 'metrics': 'foo'"}}`
+
+		resp := http.Response{StatusCode: 400, Status: "400 Bad Request", Body: io.NopCloser(strings.NewReader(response))}
+		_, err := service.parseResponse(logger, &resp)
+		require.EqualError(t, err, "request failed, status: 400 Bad Request: No such name for 'metrics': 'foo'")
+	})
+
 	t.Run("Build metric with downsampling enabled", func(t *testing.T) {
 		query := backend.DataQuery{
 			JSON: []byte(`
@@ -87,12 +114,36 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, mustParseQueryModel(t, query.JSON))
+
+		require.Equal(t, SubQuery{
+			Metric:     "cpu.average.percent",
+			Aggregator: "avg",
+			Downsample: "1m-avg",
+		}, metric)
+	})
+
+	t.Run("Build metric with auto downsampling uses the query interval", func(t *testing.T) {
+		query := backend.DataQuery{
+			Interval: 30 * time.Second,
+			JSON: []byte(`
+					{
+						"metric": "cpu.average.percent",
+						"aggregator": "avg",
+						"downsampleInterval": "auto",
+						"downsampleAggregator": "max"
+					}`,
+			),
+		}
+
+		metric := service.buildMetric(query, mustParseQueryModel(t, query.JSON))
+
+		require.Equal(t, "30s-max", metric.Downsample)
 
-		require.Len(t, metric, 3)
-		require.Equal(t, "cpu.average.percent", metric["metric"])
-		require.Equal(t, "avg", metric["aggregator"])
-		require.Equal(t, "1m-avg", metric["downsample"])
+		query.JSON = []byte(`{"metric": "cpu.average.percent", "aggregator": "avg", "downsampleAggregator": "max"}`)
+		metric = service.buildMetric(query, mustParseQueryModel(t, query.JSON))
+
+		require.Equal(t, "1m-max", metric.Downsample)
 	})
 
 	t.Run("Build metric with downsampling disabled", func(t *testing.T) {
@@ -109,11 +160,12 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, mustParseQueryModel(t, query.JSON))
 
-		require.Len(t, metric, 2)
-		require.Equal(t, "cpu.average.percent", metric["metric"])
-		require.Equal(t, "avg", metric["aggregator"])
+		require.Equal(t, SubQuery{
+			Metric:     "cpu.average.percent",
+			Aggregator: "avg",
+		}, metric)
 	})
 
 	t.Run("Build metric with downsampling enabled with params", func(t *testing.T) {
@@ -130,12 +182,13 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, mustParseQueryModel(t, query.JSON))
 
-		require.Len(t, metric, 3)
-		require.Equal(t, "cpu.average.percent", metric["metric"])
-		require.Equal(t, "avg", metric["aggregator"])
-		require.Equal(t, "5m-sum-null", metric["downsample"])
+		require.Equal(t, SubQuery{
+			Metric:     "cpu.average.percent",
+			Aggregator: "avg",
+			Downsample: "5m-sum-null",
+		}, metric)
 	})
 
 	t.Run("Build metric with tags with downsampling disabled", func(t *testing.T) {
@@ -156,18 +209,13 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
-
-		require.Len(t, metric, 3)
-		require.Equal(t, "cpu.average.percent", metric["metric"])
-		require.Equal(t, "avg", metric["aggregator"])
-		require.Nil(t, metric["downsample"])
+		metric := service.buildMetric(query, mustParseQueryModel(t, query.JSON))
 
-		metricTags := metric["tags"].(map[string]any)
-		require.Len(t, metricTags, 2)
-		require.Equal(t, "prod", metricTags["env"])
-		require.Equal(t, "grafana", metricTags["app"])
-		require.Nil(t, metricTags["ip"])
+		require.Equal(t, SubQuery{
+			Metric:     "cpu.average.percent",
+			Aggregator: "avg",
+			Tags:       map[string]string{"env": "prod", "app": "grafana"},
+		}, metric)
 	})
 
 	t.Run("Build metric with rate enabled but counter disabled", func(t *testing.T) {
@@ -187,20 +235,15 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, mustParseQueryModel(t, query.JSON))
 
-		require.Len(t, metric, 5)
-		require.Equal(t, "cpu.average.percent", metric["metric"])
-		require.Equal(t, "avg", metric["aggregator"])
-
-		metricTags := metric["tags"].(map[string]any)
-		require.Len(t, metricTags, 2)
-		require.Equal(t, "prod", metricTags["env"])
-		require.Equal(t, "grafana", metricTags["app"])
-		require.Nil(t, metricTags["ip"])
-
-		require.True(t, metric["rate"].(bool))
-		require.False(t, metric["rateOptions"].(map[string]any)["counter"].(bool))
+		require.Equal(t, SubQuery{
+			Metric:      "cpu.average.percent",
+			Aggregator:  "avg",
+			Tags:        map[string]string{"env": "prod", "app": "grafana"},
+			Rate:        true,
+			RateOptions: &RateOptions{Counter: false, DropResets: true},
+		}, metric)
 	})
 
 	t.Run("Build metric with rate and counter enabled", func(t *testing.T) {
@@ -222,23 +265,219 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric := service.buildMetric(query, mustParseQueryModel(t, query.JSON))
+
+		require.Equal(t, SubQuery{
+			Metric:      "cpu.average.percent",
+			Aggregator:  "avg",
+			Tags:        map[string]string{"env": "prod", "app": "grafana"},
+			Rate:        true,
+			RateOptions: &RateOptions{Counter: true, CounterMax: pointer(45), ResetValue: pointer(60)},
+		}, metric)
+	})
+
+	t.Run("Build metric with filters, explicit tags and percentiles", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`
+					{
+						"metric": "http.latency",
+						"aggregator": "sum",
+						"disableDownsampling": true,
+						"explicitTags": true,
+						"percentiles": [99.9, "95"],
+						"tags": {"ignored": "because of filters"},
+						"filters": [
+							{"type": "literal_or", "tagk": "host", "filter": "web01|web02", "groupBy": true},
+							{"type": "not_literal_or", "tagk": "dc", "filter": "lga", "groupBy": false},
+							{"type": "wildcard", "tagk": "app", "filter": "api*", "groupBy": "true"},
+							{"type": "regexp", "tagk": "env", "filter": "prod.*", "groupBy": false}
+						]
+					}`,
+			),
+		}
+
+		metric := service.buildMetric(query, mustParseQueryModel(t, query.JSON))
+
+		require.Equal(t, SubQuery{
+			Metric:       "http.latency",
+			Aggregator:   "sum",
+			ExplicitTags: true,
+			Percentiles:  []float64{99.9, 95},
+			Filters: []Filter{
+				{Type: "literal_or", Tagk: "host", Filter: "web01|web02", GroupBy: true},
+				{Type: "not_literal_or", Tagk: "dc", Filter: "lga"},
+				{Type: "wildcard", Tagk: "app", Filter: "api*", GroupBy: true},
+				{Type: "regexp", Tagk: "env", Filter: "prod.*"},
+			},
+		}, metric)
+	})
+}
+
+func TestParseQueryModel(t *testing.T) {
+	t.Run("migrates values saved as strings by older query editors", func(t *testing.T) {
+		model := mustParseQueryModel(t, []byte(`{
+			"metric": "cpu",
+			"shouldComputeRate": true,
+			"counterMax": "100",
+			"counterResetValue": "",
+			"tags": {"port": 8080}
+		}`))
+
+		require.Equal(t, "metric", model.QueryType)
+		require.Equal(t, "avg", model.Aggregator)
+		require.Equal(t, "avg", model.DownsampleAggregator)
+		require.Equal(t, "none", model.DownsampleFillPolicy)
+		require.Equal(t, pointer(100), model.CounterMax)
+		require.Nil(t, model.CounterResetValue)
+		require.Equal(t, map[string]string{"port": "8080"}, model.Tags)
+	})
+
+	t.Run("validates filters", func(t *testing.T) {
+		model := mustParseQueryModel(t, []byte(`{"metric": "cpu", "filters": [{"type": "fuzzy", "tagk": "host", "filter": "a"}]}`))
+		require.EqualError(t, model.validate(), `unsupported filter type "fuzzy" for tag "host"`)
+	})
+
+	t.Run("requires a metric", func(t *testing.T) {
+		model := mustParseQueryModel(t, []byte(`{"aggregator": "sum"}`))
+		require.Error(t, model.validate())
+	})
+}
+
+func TestQueryData(t *testing.T) {
+	timeRange := backend.TimeRange{
+		From: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2023, 1, 1, 1, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("sends metric queries in a single request and splits the series by query", func(t *testing.T) {
+		var requests []OpenTsdbQuery
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/query", r.URL.Path)
+			var q OpenTsdbQuery
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&q))
+			requests = append(requests, q)
+			_, _ = rw.Write([]byte(`[
+				{"metric": "cpu", "tags": {"host": "a"}, "dps": {"1672531200000": 1}, "query": {"index": 0}},
+				{"metric": "cpu", "tags": {"host": "b"}, "dps": {"1672531200000": 2}, "query": {"index": 0}},
+				{"metric": "mem", "tags": {"host": "a"}, "dps": {"1672531200000": 3}, "query": {"index": 1}}
+			]`))
+		}))
+		t.Cleanup(srv.Close)
+
+		s := newTestService(t)
+		res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: testPluginContext(srv.URL, `{"tsdbResolution": 2}`),
+			Queries: []backend.DataQuery{
+				{RefID: "A", TimeRange: timeRange, JSON: []byte(`{"metric": "cpu", "alias": "cpu $tag_host", "disableDownsampling": true}`)},
+				{RefID: "B", TimeRange: timeRange, JSON: []byte(`{"metric": "mem", "disableDownsampling": true}`)},
+				{RefID: "C", TimeRange: timeRange, JSON: []byte(`{"metric": "cpu", "filters": [{"type": "glob", "tagk": "host"}]}`)},
+			},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, requests, 1)
+		require.Len(t, requests[0].Queries, 2)
+		require.True(t, requests[0].MsResolution)
+		require.True(t, requests[0].ShowQuery)
+		require.Equal(t, timeRange.From.UnixMilli(), requests[0].Start)
+
+		frames := res.Responses["A"].Frames
+		require.Len(t, frames, 2)
+		require.Equal(t, "cpu a", frames[0].Fields[1].Config.DisplayNameFromDS)
+		require.Equal(t, "cpu b", frames[1].Fields[1].Config.DisplayNameFromDS)
+		require.Equal(t, time.UnixMilli(1672531200000).UTC(), frames[0].Fields[0].At(0))
 
-		require.Len(t, metric, 5)
-		require.Equal(t, "cpu.average.percent", metric["metric"])
-		require.Equal(t, "avg", metric["aggregator"])
+		require.Len(t, res.Responses["B"].Frames, 1)
+		require.Equal(t, data.Labels{"host": "a"}, res.Responses["B"].Frames[0].Fields[1].Labels)
+
+		require.Error(t, res.Responses["C"].Error)
+	})
 
-		metricTags := metric["tags"].(map[string]any)
-		require.Len(t, metricTags, 2)
-		require.Equal(t, "prod", metricTags["env"])
-		require.Equal(t, "grafana", metricTags["app"])
-		require.Nil(t, metricTags["ip"])
+	t.Run("sends expression queries to the expression endpoint", func(t *testing.T) {
+		var request ExpressionRequest
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/query/exp", r.URL.Path)
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			_, _ = rw.Write([]byte(`{"outputs": [{
+				"id": "e",
+				"alias": "ratio $tag_host",
+				"dps": [[1672531200000, 0.5, 0.25], [1672531260000, NaN, 0.75]],
+				"meta": [
+					{"index": 0, "metrics": ["timestamp"]},
+					{"index": 1, "metrics": ["a", "b"], "commonTags": {"host": "web01"}},
+					{"index": 2, "metrics": ["a", "b"], "commonTags": {"host": "web02"}}
+				]
+			}]}`))
+		}))
+		t.Cleanup(srv.Close)
+
+		s := newTestService(t)
+		res, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: testPluginContext(srv.URL, `{}`),
+			Queries: []backend.DataQuery{
+				{RefID: "A", TimeRange: timeRange, Interval: time.Minute, JSON: []byte(`{
+					"queryType": "expression",
+					"aggregator": "sum",
+					"downsampleAggregator": "avg",
+					"downsampleFillPolicy": "nan",
+					"expression": {
+						"filters": [{"id": "f1", "tags": [{"type": "wildcard", "tagk": "host", "filter": "*", "groupBy": true}]}],
+						"metrics": [
+							{"id": "a", "metric": "errors", "filter": "f1"},
+							{"id": "b", "metric": "requests", "filter": "f1"}
+						],
+						"expressions": [{"id": "e", "expr": "a / b"}],
+						"outputs": [{"id": "e", "alias": "ratio $tag_host"}]
+					}
+				}`)},
+			},
+		})
+		require.NoError(t, err)
 
-		require.True(t, metric["rate"].(bool))
-		metricRateOptions := metric["rateOptions"].(map[string]any)
-		require.Len(t, metricRateOptions, 3)
-		require.True(t, metricRateOptions["counter"].(bool))
-		require.Equal(t, float64(45), metricRateOptions["counterMax"])
-		require.Equal(t, float64(60), metricRateOptions["resetValue"])
+		require.Equal(t, "1672531200000", request.Time.Start)
+		require.Equal(t, "sum", request.Time.Aggregator)
+		require.Equal(t, &Downsampler{Interval: "1m", Aggregator: "avg", FillPolicy: &FillPolicy{Policy: "nan"}}, request.Time.Downsampler)
+		require.Len(t, request.Metrics, 2)
+		require.Equal(t, "a / b", request.Expressions[0].Expr)
+
+		require.NoError(t, res.Responses["A"].Error)
+		frames := res.Responses["A"].Frames
+		require.Len(t, frames, 2)
+		require.Equal(t, "ratio web01", frames[0].Fields[1].Config.DisplayNameFromDS)
+		require.Equal(t, data.Labels{"host": "web02"}, frames[1].Fields[1].Labels)
+		require.Equal(t, 2, frames[0].Fields[1].Len())
+		require.Nil(t, frames[0].Fields[1].At(1))
+		require.Equal(t, 0.75, *frames[1].Fields[1].At(1).(*float64))
 	})
 }
+
+func TestMetricLabel(t *testing.T) {
+	tags := map[string]string{"host": "web01", "dc": "lga"}
+
+	require.Equal(t, "cpu", metricLabel("cpu", tags, &QueryModel{}))
+	require.Equal(t, "cpu{dc=lga, host=web01}", metricLabel("cpu", tags, &QueryModel{Tags: map[string]string{"host": "*", "dc": "*"}}))
+	require.Equal(t, "cpu{host=web01}", metricLabel("cpu", tags, &QueryModel{Filters: []Filter{{Type: "wildcard", Tagk: "host"}}}))
+	require.Equal(t, "web01 in lga $tag_missing", metricLabel("cpu", tags, &QueryModel{Alias: "$tag_host in [[tag_dc]] $tag_missing"}))
+}
+
+func mustParseQueryModel(t *testing.T, raw []byte) *QueryModel {
+	t.Helper()
+	model, err := parseQueryModel(raw)
+	require.NoError(t, err)
+	return model
+}
+
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+	return ProvideService(httpclient.NewProvider())
+}
+
+func testPluginContext(url string, jsonData string) backend.PluginContext {
+	return backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1, URL: url, JSONData: []byte(jsonData)},
+	}
+}
+
+func pointer(f float64) *float64 {
+	return &f
+}
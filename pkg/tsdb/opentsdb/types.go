@@ -1,13 +1,162 @@
 package opentsdb
 
+// OpenTsdbQuery is the body of a request to the /api/query endpoint
 type OpenTsdbQuery struct {
-	Start   int64            `json:"start"`
-	End     int64            `json:"end"`
-	Queries []map[string]any `json:"queries"`
+	Start        int64      `json:"start"`
+	End          int64      `json:"end"`
+	Queries      []SubQuery `json:"queries"`
+	MsResolution bool       `json:"msResolution,omitempty"`
+	ShowQuery    bool       `json:"showQuery,omitempty"`
 }
 
+// SubQuery is a single metric query of a request to the /api/query endpoint
+type SubQuery struct {
+	Metric       string            `json:"metric"`
+	Aggregator   string            `json:"aggregator"`
+	Downsample   string            `json:"downsample,omitempty"`
+	Rate         bool              `json:"rate,omitempty"`
+	RateOptions  *RateOptions      `json:"rateOptions,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	Filters      []Filter          `json:"filters,omitempty"`
+	ExplicitTags bool              `json:"explicitTags,omitempty"`
+	Percentiles  []float64         `json:"percentiles,omitempty"`
+}
+
+type RateOptions struct {
+	Counter    bool     `json:"counter"`
+	CounterMax *float64 `json:"counterMax,omitempty"`
+	ResetValue *float64 `json:"resetValue,omitempty"`
+	DropResets bool     `json:"dropResets,omitempty"`
+}
+
+// Filter is a tag filter, see http://opentsdb.net/docs/build/html/user_guide/query/filters.html
+type Filter struct {
+	Type    string `json:"type"`
+	Tagk    string `json:"tagk"`
+	Filter  string `json:"filter"`
+	GroupBy bool   `json:"groupBy"`
+}
+
+// OpenTsdbResponse is a single series returned by the /api/query endpoint
 type OpenTsdbResponse struct {
-	Metric     string             `json:"metric"`
-	Tags       map[string]string  `json:"tags"`
-	DataPoints map[string]float64 `json:"dps"`
+	Metric        string              `json:"metric"`
+	Tags          map[string]string   `json:"tags"`
+	AggregateTags []string            `json:"aggregateTags"`
+	DataPoints    map[string]*float64 `json:"dps"`
+	// Query is only returned when showQuery is set
+	Query *struct {
+		Index int `json:"index"`
+	} `json:"query"`
+}
+
+// ErrorResponse is returned by OpenTSDB when a request fails
+type ErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// QueryModel is the query model stored in dashboards and alert rules
+type QueryModel struct {
+	QueryType string `json:"queryType"`
+
+	Metric     string `json:"metric"`
+	Aggregator string `json:"aggregator"`
+	Alias      string `json:"alias"`
+
+	DisableDownsampling  bool   `json:"disableDownsampling"`
+	DownsampleInterval   string `json:"downsampleInterval"`
+	DownsampleAggregator string `json:"downsampleAggregator"`
+	DownsampleFillPolicy string `json:"downsampleFillPolicy"`
+
+	ShouldComputeRate bool     `json:"shouldComputeRate"`
+	IsCounter         bool     `json:"isCounter"`
+	CounterMax        *float64 `json:"counterMax"`
+	CounterResetValue *float64 `json:"counterResetValue"`
+
+	Tags         map[string]string `json:"tags"`
+	Filters      []Filter          `json:"filters"`
+	ExplicitTags bool              `json:"explicitTags"`
+	Percentiles  []float64         `json:"percentiles"`
+
+	// Expression is used by expression queries, sent to the /api/query/exp endpoint
+	Expression *ExpressionQuery `json:"expression"`
+}
+
+// ExpressionQuery is the part of a request to the /api/query/exp endpoint defined by the user
+type ExpressionQuery struct {
+	Filters     []ExpressionFilterSet `json:"filters,omitempty"`
+	Metrics     []ExpressionMetric    `json:"metrics"`
+	Expressions []Expression          `json:"expressions,omitempty"`
+	Outputs     []ExpressionOutput    `json:"outputs,omitempty"`
+}
+
+type ExpressionFilterSet struct {
+	ID   string   `json:"id"`
+	Tags []Filter `json:"tags"`
+}
+
+type ExpressionMetric struct {
+	ID         string      `json:"id"`
+	Metric     string      `json:"metric"`
+	Filter     string      `json:"filter,omitempty"`
+	Aggregator string      `json:"aggregator,omitempty"`
+	FillPolicy *FillPolicy `json:"fillPolicy,omitempty"`
+}
+
+type Expression struct {
+	ID   string `json:"id"`
+	Expr string `json:"expr"`
+}
+
+type ExpressionOutput struct {
+	ID    string `json:"id"`
+	Alias string `json:"alias,omitempty"`
+}
+
+type FillPolicy struct {
+	Policy string   `json:"policy"`
+	Value  *float64 `json:"value,omitempty"`
+}
+
+// ExpressionRequest is the body of a request to the /api/query/exp endpoint
+type ExpressionRequest struct {
+	Time ExpressionTime `json:"time"`
+	ExpressionQuery
+}
+
+type ExpressionTime struct {
+	Start       string       `json:"start"`
+	End         string       `json:"end"`
+	Aggregator  string       `json:"aggregator"`
+	Downsampler *Downsampler `json:"downsampler,omitempty"`
+	Rate        bool         `json:"rate,omitempty"`
+}
+
+type Downsampler struct {
+	Interval   string      `json:"interval"`
+	Aggregator string      `json:"aggregator"`
+	FillPolicy *FillPolicy `json:"fillPolicy,omitempty"`
+}
+
+// ExpressionResponse is the response of the /api/query/exp endpoint
+type ExpressionResponse struct {
+	Outputs []ExpressionOutputResult `json:"outputs"`
+}
+
+// ExpressionOutputResult contains the series of an output as rows of a timestamp
+// followed by one value per series, described by Meta.
+type ExpressionOutputResult struct {
+	ID    string                 `json:"id"`
+	Alias string                 `json:"alias"`
+	Dps   [][]*float64           `json:"dps"`
+	Meta  []ExpressionSeriesMeta `json:"meta"`
+}
+
+type ExpressionSeriesMeta struct {
+	Index          int               `json:"index"`
+	Metrics        []string          `json:"metrics"`
+	CommonTags     map[string]string `json:"commonTags"`
+	AggregatedTags []string          `json:"aggregatedTags"`
 }
@@ -0,0 +1,29 @@
+package sequence
+
+import "time"
+
+// Gaps tracks the missing ids of a sequence read by polling, like the ids of a change log. The ids are allocated
+// when the transactions insert their row, so a transaction may still commit an id lower than the ids already
+// visible. A missing id is waited for until it has been missing for the timeout, the transaction is then
+// considered rolled back.
+type Gaps struct {
+	Timeout time.Duration
+
+	// the first missing id, and when it was found missing
+	gap  int64
+	seen time.Time
+}
+
+// Next reports whether the id can be read after the last one: it follows the last id, or the ids in between
+// have been missing for longer than the timeout.
+func (g *Gaps) Next(last int64, id int64) bool {
+	if id == last+1 {
+		return true
+	}
+	if g.gap != last+1 {
+		g.gap = last + 1
+		g.seen = time.Now()
+		return false
+	}
+	return time.Since(g.seen) > g.Timeout
+}
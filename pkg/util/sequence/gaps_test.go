@@ -0,0 +1,22 @@
+package sequence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGaps(t *testing.T) {
+	g := &Gaps{Timeout: 50 * time.Millisecond}
+
+	require.True(t, g.Next(1, 2))
+	require.False(t, g.Next(2, 4), "3 may not be committed yet")
+	require.False(t, g.Next(2, 4))
+
+	time.Sleep(60 * time.Millisecond)
+	require.True(t, g.Next(2, 4), "3 was rolled back")
+
+	// a new gap is waited for again
+	require.False(t, g.Next(4, 6))
+}
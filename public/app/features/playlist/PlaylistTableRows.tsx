@@ -43,6 +43,14 @@ export const PlaylistTableRows = ({ items, onDelete }: Props) => {
       } else {
         info.push(<span key="info">&nbsp; {pluralize('dashboard', item.dashboards.length, true)}</span>);
       }
+    } else if (item.type === 'dashboard_by_search') {
+      info.push(<span key="search">{item.value}</span>);
+      if (!first) {
+        icon = 'exclamation-triangle';
+        info.push(<span key="info">&nbsp; No dashboards found</span>);
+      } else {
+        info.push(<span key="info">&nbsp; {pluralize('dashboard', item.dashboards.length, true)}</span>);
+      }
     } else if (first) {
       info.push(
         item.dashboards.length > 1 ? (
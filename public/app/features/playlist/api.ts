@@ -9,6 +9,7 @@ import { GrafanaQuery, GrafanaQueryType } from 'app/plugins/datasource/grafana/t
 import { dispatch } from 'app/store/store';
 
 import { DashboardQueryResult, getGrafanaSearcher, SearchQuery } from '../search/service';
+import { DashboardSearchHit } from '../search/types';
 
 import { Playlist, PlaylistItem } from './types';
 
@@ -63,6 +64,16 @@ export async function loadDashboards(items: PlaylistItem[]): Promise<PlaylistIte
     return [];
   }
 
+  // The search items are resolved with the search API, the other items with the grafana searcher
+  const searchItems = items.filter((item) => item.type === 'dashboard_by_search');
+  if (searchItems.length) {
+    const otherItems = await loadDashboards(items.filter((item) => item.type !== 'dashboard_by_search'));
+    const searched = await Promise.all(searchItems.map(searchDashboards));
+    return items.map((item) =>
+      item.type === 'dashboard_by_search' ? searched[searchItems.indexOf(item)] : otherItems.shift()!
+    );
+  }
+
   const targets: GrafanaQuery[] = [];
   for (const item of items) {
     const query: SearchQuery = {
@@ -114,6 +125,27 @@ export async function loadDashboards(items: PlaylistItem[]): Promise<PlaylistIte
   });
 }
 
+/** The value of a dashboard_by_search item holds the folderUIDs, tag, starred and query parameters of the search API */
+async function searchDashboards(item: PlaylistItem): Promise<PlaylistItem> {
+  const hits = await getBackendSrv().get<DashboardSearchHit[]>(`/api/search?${item.value}`, {
+    type: 'dash-db',
+    limit: 1000,
+  });
+  const dashboards: DashboardQueryResult[] = hits.map((hit) => ({
+    kind: 'dashboard',
+    name: hit.title,
+    uid: hit.uid,
+    url: hit.url,
+    panel_type: '',
+    tags: hit.tags,
+    location: hit.folderUid || 'general',
+    ds_uid: [],
+    score: 0,
+    explain: {},
+  }));
+  return { ...item, dashboards };
+}
+
 export function getDefaultPlaylist(): Playlist {
   return { items: [], interval: '5m', name: '', uid: '' };
 }
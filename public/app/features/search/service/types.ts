@@ -16,6 +16,7 @@ export interface SearchQuery {
   tags?: string[];
   kind?: string[];
   panel_type?: string;
+  panel_query?: string; // text of the panel queries
   uid?: string[];
   facet?: FacetField[];
   explain?: boolean;
@@ -36,6 +37,7 @@ export interface DashboardQueryResult {
   tags: string[];
   location: string; // url that can be split
   ds_uid: string[];
+  query_highlight?: string[]; // snippets of the panel queries matching panel_query
 
   // debugging fields
   score: number;
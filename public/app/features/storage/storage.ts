@@ -2,7 +2,7 @@ import { DataFrame, dataFrameFromJSON, DataFrameJSON, getDisplayProcessor } from
 import { config, getBackendSrv } from '@grafana/runtime';
 import { backendSrv } from 'app/core/services/backend_srv';
 
-import { UploadReponse, StorageInfo, ItemOptions, WriteValueRequest, WriteValueResponse } from './types';
+import { UploadReponse, StorageInfo, ItemOptions, WriteValueRequest, WriteValueResponse, GitChange } from './types';
 
 // Likely should be built into the search interface!
 export interface GrafanaStorage {
@@ -20,6 +20,9 @@ export interface GrafanaStorage {
 
   /** Saves dashboards */
   write: (path: string, options: WriteValueRequest) => Promise<WriteValueResponse>;
+
+  /** Changes of a git storage with an open pull request */
+  getPendingChanges: (prefix: string, dashboardUID?: string) => Promise<GitChange[]>;
 }
 
 class SimpleStorage implements GrafanaStorage {
@@ -125,6 +128,10 @@ class SimpleStorage implements GrafanaStorage {
   async getOptions(path: string) {
     return getBackendSrv().get<ItemOptions>(`/api/storage/options/${path}`);
   }
+
+  async getPendingChanges(prefix: string, dashboardUID?: string) {
+    return getBackendSrv().get<GitChange[]>(`/api/storage/git/${prefix}/changes`, { dashboardUID });
+  }
 }
 
 export function filenameAlreadyExists(folderName: string, fileNames: string[]) {
@@ -39,7 +39,11 @@ export interface StorageConfig {
     branch: string;
     root: string;
     requirePullRequest: boolean;
+    pullInterval?: string;
     accessToken: string;
+    syncDashboards?: boolean;
+    orgId?: number;
+    webhookSecret?: string;
   };
   sql?: {};
 }
@@ -68,6 +72,22 @@ export interface WriteValueResponse {
   size?: number;
 }
 
+/** A change saved in a branch of a git storage, waiting for its pull request to be merged */
+export interface GitChange {
+  path: string;
+  dashboardUID?: string;
+  title?: string;
+  base: string;
+  branch: string;
+  hash: string;
+  number?: number;
+  url?: string;
+  status: 'open' | 'merged' | 'closed';
+  author?: string;
+  created: string;
+  updated: string;
+}
+
 export interface ItemOptions {
   path: string;
   workflows: Array<SelectableValue<WorkflowID>>;
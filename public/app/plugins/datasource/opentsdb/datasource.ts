@@ -520,7 +520,9 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
     }
 
     if (!target.disableDownsampling) {
-      let interval = this.templateSrv.replace(target.downsampleInterval || options.interval);
+      // auto is used by the backend to downsample with the panel interval, the default of the panel queries
+      const downsampleInterval = target.downsampleInterval === 'auto' ? '' : target.downsampleInterval;
+      let interval = this.templateSrv.replace(downsampleInterval || options.interval);
 
       if (interval.match(/\.[0-9]+s/)) {
         interval = parseFloat(interval) * 1000 + 'ms';